@@ -3,92 +3,165 @@ package main
 import (
 	"fmt"
 	"log"
+	neturl "net/url"
 	"os"
 	"strings"
+	"time"
 
 	"multi-chain-wallet/internal/api"
+	"multi-chain-wallet/internal/api/middleware"
 	"multi-chain-wallet/internal/config"
 	"multi-chain-wallet/internal/routes"
 	"multi-chain-wallet/internal/service"
 	"multi-chain-wallet/internal/storage"
 	"multi-chain-wallet/internal/wallet"
-	"multi-chain-wallet/internal/wallet/bsc"
+	"multi-chain-wallet/internal/wallet/bitcoin"
+	"multi-chain-wallet/internal/wallet/cosmos"
 	"multi-chain-wallet/internal/wallet/ethereum"
-	"multi-chain-wallet/internal/wallet/polygon"
-	"multi-chain-wallet/internal/wallet/sepolia"
+	"multi-chain-wallet/internal/wallet/solana"
+	"multi-chain-wallet/internal/wallet/tron"
 )
 
 func main() {
 	// 加载配置
 	log.Printf("正在加载.env配置文件...")
-	if err := config.LoadConfig(); err != nil {
+	cfg, err := config.LoadConfig(".env")
+	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
 	// 如果命令行中包含-port参数，则覆盖配置文件中的端口
 	if port := getPortFromArgs(); port > 0 {
-		config.SetServerPort(fmt.Sprintf("%d", port))
+		cfg.Server.Port = fmt.Sprintf("%d", port)
 	}
 
 	// 打印配置信息
-	logConfig()
+	logConfig(cfg)
 
-	log.Printf("多链钱包服务 v0.1")
-	log.Printf("支持链: Ethereum, BSC, Polygon")
-	log.Printf("服务端口: %s", config.GetServerPort())
-	log.Printf("数据库配置: %s:%s/%s", config.GetDBHost(), config.GetDBPort(), config.GetDBName())
-	log.Printf("使用RPC: ETH=%s, BSC=%s, POLYGON=%s, SEPOLIA=%s",
-		trimRPCURL(config.GetEthereumRPC()),
-		trimRPCURL(config.GetBSCRPC()),
-		trimRPCURL(config.GetPolygonRPC()),
-		trimRPCURL(config.GetSepoliaRPC()))
-
-	// 初始化数据库
-	log.Printf("正在连接MySQL数据库: %s:%s/%s", config.GetDBHost(), config.GetDBPort(), config.GetDBName())
-	if err := storage.InitDB(
-		config.GetDBHost(),
-		config.GetDBPort(),
-		config.GetDBUser(),
-		config.GetDBPassword(),
-		config.GetDBName(),
-	); err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
+	// 初始化数据库，开发模式使用内存数据库
+	if cfg.DevMode {
+		log.Printf("开发模式：使用内存数据库")
+		if err := storage.InitMemoryDB(); err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
+	} else {
+		log.Printf("正在连接MySQL数据库: %s:%s/%s", cfg.Database.Host, cfg.Database.Port, cfg.Database.DBName)
+		if err := storage.InitDB(
+			cfg.Database.Host,
+			cfg.Database.Port,
+			cfg.Database.User,
+			cfg.Database.Password,
+			cfg.Database.DBName,
+		); err != nil {
+			log.Fatalf("Failed to initialize database: %v", err)
+		}
 	}
 	log.Printf("数据库初始化成功")
 
 	// 初始化钱包管理器
 	walletManager := wallet.NewManager()
 
-	// 注册支持的钱包类型
-	walletManager.RegisterWallet(wallet.ChainTypeETH, ethereum.NewWallet)
-	walletManager.RegisterWallet(wallet.ChainTypeBSC, bsc.NewWallet)
-	walletManager.RegisterWallet(wallet.ChainTypePolygon, polygon.NewWallet)
-	walletManager.RegisterWallet(wallet.ChainTypeSepolia, sepolia.NewWallet)
+	// 按链注册表为每条链创建钱包
+	walletManager.RegisterFactory(wallet.ChainKindEVM, ethereum.NewFactory(cfg.Wallet.EncryptionKey))
+	walletManager.RegisterFactory(wallet.ChainKindSimulated, ethereum.NewSimulatedFactory(cfg.Wallet.EncryptionKey))
+	walletManager.RegisterFactory(wallet.ChainKindBitcoin, bitcoin.NewFactory(cfg.Wallet.EncryptionKey))
+	walletManager.RegisterFactory(wallet.ChainKindSolana, solana.NewFactory(cfg.Wallet.EncryptionKey))
+	walletManager.RegisterFactory(wallet.ChainKindTron, tron.NewFactory(cfg.Wallet.EncryptionKey))
+	walletManager.RegisterFactory(wallet.ChainKindCosmos, cosmos.NewFactory(cfg.Wallet.EncryptionKey))
+	if err := walletManager.LoadChains(toChainInfos(cfg.Chains)); err != nil {
+		log.Fatalf("Failed to load chains: %v", err)
+	}
+
+	if cfg.DevMode {
+		logDevAccounts()
+	}
 
 	// 日志输出支持的链类型
 	log.Printf("应用支持的链: %v", walletManager.GetSupportedChains())
 
-	// 初始化交易存储
-	txStorage := storage.NewMySQLTransactionStorage()
-
-	// 初始化订单存储
+	// 初始化存储
+	walletStorage := &storage.MySQLWalletStorage{}
+	txStorage := &storage.MySQLTransactionStorage{}
 	orderStorage := storage.NewMySQLOrderStorage()
 
-	// 初始化必要的表
-	if err := txStorage.InitTransactionTable(); err != nil {
-		log.Fatalf("Failed to initialize transaction table: %v", err)
+	if err := orderStorage.InitOrderTable(); err != nil {
+		log.Fatalf("Failed to initialize order table: %v", err)
 	}
 
-	if err := txStorage.InitBridgeTransactionTable(); err != nil {
-		log.Fatalf("Failed to initialize bridge transaction table: %v", err)
+	// 初始化授权存储
+	allowanceStorage := storage.NewMySQLAllowanceStorage()
+	if err := allowanceStorage.InitAllowanceTables(); err != nil {
+		log.Fatalf("Failed to initialize allowance tables: %v", err)
 	}
 
-	if err := orderStorage.InitOrderTable(); err != nil {
-		log.Fatalf("Failed to initialize order table: %v", err)
+	// 初始化离线签名包存储
+	offlineStorage := storage.NewMySQLOfflineStorage()
+	if err := offlineStorage.InitOfflineTables(); err != nil {
+		log.Fatalf("Failed to initialize offline bundle table: %v", err)
+	}
+
+	// 初始化Safe多签交易存储
+	safeStorage := storage.NewMySQLSafeStorage()
+	if err := safeStorage.InitSafeTables(); err != nil {
+		log.Fatalf("Failed to initialize safe tables: %v", err)
+	}
+
+	// 初始化UserOperation存储
+	userOpStorage := storage.NewMySQLUserOperationStorage()
+	if err := userOpStorage.InitUserOperationTable(); err != nil {
+		log.Fatalf("Failed to initialize user operation table: %v", err)
+	}
+
+	// 初始化元交易中继存储
+	relayStorage := storage.NewMySQLRelayStorage()
+	if err := relayStorage.InitRelayTables(); err != nil {
+		log.Fatalf("Failed to initialize relay tables: %v", err)
+	}
+
+	// 初始化会话密钥存储
+	sessionStorage := storage.NewMySQLSessionStorage()
+	if err := sessionStorage.InitSessionTable(); err != nil {
+		log.Fatalf("Failed to initialize session table: %v", err)
+	}
+
+	// 初始化支出策略存储
+	policyStorage := storage.NewMySQLPolicyStorage()
+	if err := policyStorage.InitPolicyTables(); err != nil {
+		log.Fatalf("Failed to initialize policy tables: %v", err)
+	}
+
+	// 初始化交易审批存储
+	txApprovalStorage := storage.NewMySQLTxApprovalStorage()
+	if err := txApprovalStorage.InitTxApprovalTables(); err != nil {
+		log.Fatalf("Failed to initialize transaction approval tables: %v", err)
 	}
 
 	// 初始化钱包服务
-	walletService := service.NewWalletService(walletManager, txStorage)
+	walletService := service.NewWalletService(walletManager, walletStorage, txStorage)
+
+	// 初始化支出策略服务，所有签名在WalletService中按钱包策略校验
+	policyChangeDelay, err := time.ParseDuration(cfg.Policy.ChangeDelay)
+	if err != nil {
+		log.Fatalf("Invalid POLICY_CHANGE_DELAY: %s", cfg.Policy.ChangeDelay)
+	}
+	policyService := service.NewPolicyService(walletService, policyStorage, policyChangeDelay)
+	walletService.SetPolicyService(policyService)
+
+	// 初始化交易审批服务，设置了审批规则的钱包签名和发送前须获得批准
+	txApprovalService := service.NewTxApprovalService(walletService, txApprovalStorage)
+	walletService.SetTxApprovalService(txApprovalService)
+	txApprovalService.Start()
+
+	// 初始化地址筛查服务，配置了名单时创建和发送交易前筛查涉及的地址
+	screeningInterval, err := time.ParseDuration(cfg.Screening.ReloadInterval)
+	if err != nil {
+		log.Fatalf("Invalid SCREENING_RELOAD_INTERVAL: %s", cfg.Screening.ReloadInterval)
+	}
+	screeningService := service.NewScreeningService(toScreeningLists(cfg.Screening.Lists), screeningInterval)
+	if len(cfg.Screening.Lists) > 0 {
+		walletService.SetScreeningService(screeningService)
+		screeningService.Start()
+	}
 
 	// 初始化跨链服务
 	bridgeService := service.NewBridgeService(walletService, txStorage)
@@ -102,16 +175,49 @@ func main() {
 	// 启动调度器服务
 	schedulerService.Start()
 
-	// 创建HTTP服务器
+	// 初始化授权扫描服务
+	approvalService := service.NewApprovalService(walletService, allowanceStorage)
+	approvalService.Start()
+
+	// 初始化离线签名服务
+	offlineService := service.NewOfflineService(walletService, offlineStorage)
+
+	// 初始化Safe多签服务
+	safeService := service.NewSafeService(walletService, safeStorage)
+
+	// 初始化智能账户服务
+	smartAccountService := service.NewSmartAccountService(walletService, userOpStorage)
+
+	// 初始化元交易中继服务
+	relayerService := service.NewRelayerService(walletService, relayStorage, toRelayerConfigs(cfg.Chains))
+
+	// 初始化会话密钥服务
+	sessionService := service.NewSessionService(walletService, sessionStorage)
+
+	// 创建HTTP服务器，管理接口使用ADMIN_API_TOKEN认证
 	server := api.NewServer(walletService, walletManager)
+	adminAuth := middleware.AdminAuth(cfg.Admin.Token)
+	if cfg.Admin.Token == "" {
+		log.Printf("Warning: ADMIN_API_TOKEN is not set, admin endpoints are disabled")
+	}
 
 	// 注册处理器
 	server.RegisterHandler(routes.NewWalletRoutes(walletService, walletManager))
+	server.RegisterHandler(routes.NewChainRoutes(walletManager))
 	server.RegisterHandler(routes.NewBridgeRoutes(bridgeService))
 	server.RegisterHandler(routes.NewDEXRoutes(dexService))
+	server.RegisterHandler(routes.NewApprovalRoutes(approvalService))
+	server.RegisterHandler(routes.NewOfflineRoutes(offlineService))
+	server.RegisterHandler(routes.NewSafeRoutes(safeService))
+	server.RegisterHandler(routes.NewSmartAccountRoutes(smartAccountService))
+	server.RegisterHandler(routes.NewRelayerRoutes(relayerService, adminAuth))
+	server.RegisterHandler(routes.NewSessionRoutes(sessionService))
+	server.RegisterHandler(routes.NewPolicyRoutes(policyService, adminAuth))
+	server.RegisterHandler(routes.NewTxApprovalRoutes(txApprovalService, adminAuth))
+	server.RegisterHandler(routes.NewScreeningRoutes(screeningService, adminAuth))
 
 	// 启动HTTP服务器
-	addr := fmt.Sprintf(":%s", config.GetServerPort())
+	addr := fmt.Sprintf(":%s", cfg.Server.Port)
 	log.Printf("服务器启动于 %s，支持的链类型: %v", addr, walletManager.GetSupportedChains())
 	log.Printf("已启用DEX功能：支持集中流动性AMM交易与限价订单")
 	if err := server.Run(addr); err != nil {
@@ -119,11 +225,80 @@ func main() {
 	}
 }
 
+// toChainInfos 将配置中的链注册表转换为钱包管理器使用的链信息
+func toChainInfos(chains []config.ChainConfig) []wallet.ChainInfo {
+	infos := make([]wallet.ChainInfo, 0, len(chains))
+	for _, chain := range chains {
+		infos = append(infos, wallet.ChainInfo{
+			ChainType:      wallet.ChainType(chain.ChainType),
+			Kind:           wallet.ChainKind(chain.Kind),
+			Name:           chain.Name,
+			ChainID:        chain.ChainID,
+			RPCURLs:        chain.RPCURLs,
+			NativeSymbol:   chain.NativeSymbol,
+			NativeDecimals: chain.NativeDecimals,
+			ExplorerURL:    chain.ExplorerURL,
+			EIP1559:        chain.EIP1559,
+			Network:        chain.Network,
+			AddressType:    chain.AddressType,
+			Bech32Prefix:   chain.Bech32Prefix,
+			CoinType:       chain.CoinType,
+			Denom:          chain.Denom,
+			GasPrice:       chain.GasPrice,
+			BundlerURL:     chain.BundlerURL,
+		})
+	}
+	return infos
+}
+
+// toScreeningLists 将配置中的名单转换为地址筛查服务使用的名单
+func toScreeningLists(lists []config.ScreeningListConfig) []service.ScreeningList {
+	result := make([]service.ScreeningList, 0, len(lists))
+	for _, list := range lists {
+		result = append(result, service.ScreeningList{Path: list.Path, Action: list.Action})
+	}
+	return result
+}
+
+// toRelayerConfigs 收集配置了转发合约和中继钱包的链
+func toRelayerConfigs(chains []config.ChainConfig) map[wallet.ChainType]service.RelayerConfig {
+	relayers := make(map[wallet.ChainType]service.RelayerConfig)
+	for _, chain := range chains {
+		if chain.Forwarder == "" || chain.RelayerWallet == "" {
+			continue
+		}
+		relayers[wallet.ChainType(chain.ChainType)] = service.RelayerConfig{
+			Forwarder: chain.Forwarder,
+			WalletID:  chain.RelayerWallet,
+		}
+	}
+	return relayers
+}
+
+// logDevAccounts 打印开发模式的预置账户，可通过私钥导入接口导入后直接发送交易
+func logDevAccounts() {
+	accounts, err := ethereum.DevAccounts()
+	if err != nil {
+		log.Printf("Failed to derive dev accounts: %v", err)
+		return
+	}
+
+	log.Printf("开发模式预置账户（助记词: %s）:", ethereum.DevMnemonic)
+	for i, account := range accounts {
+		log.Printf("  (%d) %s 私钥: %s", i, account.Address, account.PrivateKey)
+	}
+}
+
 // 处理RPC URL，用于显示时隐藏API密钥
 func trimRPCURL(url string) string {
 	if url == "" {
 		return "未配置"
 	}
+	// 隐藏URL中的认证信息（如bitcoind的用户名密码）
+	if parsed, err := neturl.Parse(url); err == nil && parsed.User != nil {
+		parsed.User = neturl.User("***")
+		url = parsed.String()
+	}
 	// 隐藏API密钥
 	if strings.Contains(url, "infura.io") || strings.Contains(url, "alchemyapi.io") {
 		parts := strings.Split(url, "/")
@@ -145,6 +320,16 @@ func getPortFromArgs() int {
 	return 0
 }
 
-func logConfig() {
-	// Implementation of logConfig function
+// logConfig 打印配置信息
+func logConfig(cfg *config.Config) {
+	log.Printf("多链钱包服务 v0.1")
+	log.Printf("服务端口: %s", cfg.Server.Port)
+	log.Printf("数据库配置: %s:%s/%s", cfg.Database.Host, cfg.Database.Port, cfg.Database.DBName)
+	for _, chain := range cfg.Chains {
+		rpcURLs := make([]string, 0, len(chain.RPCURLs))
+		for _, rpcURL := range chain.RPCURLs {
+			rpcURLs = append(rpcURLs, trimRPCURL(rpcURL))
+		}
+		log.Printf("链 %s (chainId=%d, %s): RPC=%v", chain.ChainType, chain.ChainID, chain.NativeSymbol, rpcURLs)
+	}
 }
@@ -0,0 +1,55 @@
+// bundler 本地ERC-4337 bundler，用于在部署了EntryPoint v0.7的开发链（如anvil分叉网络）上测试智能账户
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"multi-chain-wallet/internal/wallet/ethereum"
+)
+
+func main() {
+	listen := flag.String("listen", "127.0.0.1:4337", "监听地址")
+	rpcURL := flag.String("rpc", "http://127.0.0.1:8545", "链节点RPC地址")
+	flag.Parse()
+
+	// 支付handleOps交易gas的账户私钥（hex）
+	key, err := ethereum.ParsePrivateKey(os.Getenv("BUNDLER_KEY"))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "bundler: BUNDLER_KEY must be a hex private key")
+		os.Exit(2)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := ethclient.DialContext(ctx, *rpcURL)
+	if err != nil {
+		log.Fatalf("bundler: %v", err)
+	}
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		log.Fatalf("bundler: %v", err)
+	}
+
+	bundler, err := ethereum.NewLocalBundler(client, chainID, key)
+	if err != nil {
+		log.Fatalf("bundler: %v", err)
+	}
+
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           bundler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("bundler: listening on %s, chain %s, beneficiary %s", *listen, chainID, bundler.Address().Hex())
+	if err := server.ListenAndServe(); err != nil {
+		log.Fatalf("bundler: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+// mpcnode 门限签名节点，保存一个密钥分片，由钱包服务协调参与密钥生成和签名
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"multi-chain-wallet/internal/wallet/mpc"
+)
+
+func main() {
+	listen := flag.String("listen", "127.0.0.1:9101", "监听地址")
+	dataDir := flag.String("data", "mpcnode-data", "身份密钥和密钥分片的保存目录")
+	peers := flag.String("peers", "", "允许参与密钥生成的节点身份公钥，逗号分隔（含本节点），为空时不限制")
+	flag.Parse()
+
+	// 分片加密密钥，每个节点应使用不同的值
+	encryptionKey := os.Getenv("MPC_NODE_KEY")
+	if encryptionKey == "" {
+		fmt.Fprintln(os.Stderr, "mpcnode: MPC_NODE_KEY is required")
+		os.Exit(2)
+	}
+
+	node, err := mpc.NewNode(*dataDir, encryptionKey)
+	if err != nil {
+		log.Fatalf("mpcnode: %v", err)
+	}
+	if *peers != "" {
+		var identityKeys []hexutil.Bytes
+		for _, peer := range strings.Split(*peers, ",") {
+			key, err := hexutil.Decode(strings.TrimSpace(peer))
+			if err != nil {
+				log.Fatalf("mpcnode: invalid peer identity key %q: %v", peer, err)
+			}
+			identityKeys = append(identityKeys, key)
+		}
+		node.SetTrustedPeers(identityKeys)
+	}
+
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           node.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("mpcnode: listening on %s, identity 0x%x", *listen, node.Info().IdentityKey)
+	if err := server.ListenAndServe(); err != nil {
+		log.Fatalf("mpcnode: %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+// walletctl 离线钱包工具，在隔离网络的设备上用本地keystore签名服务端导出的离线签名包
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"multi-chain-wallet/internal/offline"
+	"multi-chain-wallet/internal/wallet/ethereum"
+)
+
+const usage = `用法:
+  walletctl sign -keystore <keystore.json> (-bundle <bundle.json> | -ur <frames.txt>) [选项]
+
+选项:
+  -password-file <file>  keystore密码文件，未指定时读取WALLETCTL_PASSWORD环境变量或从标准输入读取
+  -out <file>            签名结果JSON输出文件，默认输出到标准输出
+  -ur-out <file>         签名结果的UR帧输出文件，每行一帧，用于生成动态二维码
+  -yes                   跳过交易确认
+`
+
+// 确认和密码输入共用同一个缓冲读取器，避免前一次读取多读的内容丢失
+var stdin = bufio.NewReader(os.Stdin)
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "sign" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err := runSign(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "walletctl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runSign 读取离线签名包，展示并校验交易意图后签名
+func runSign(args []string) error {
+	fs := flag.NewFlagSet("sign", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	keystorePath := fs.String("keystore", "", "")
+	passwordFile := fs.String("password-file", "", "")
+	bundlePath := fs.String("bundle", "", "")
+	urPath := fs.String("ur", "", "")
+	outPath := fs.String("out", "", "")
+	urOutPath := fs.String("ur-out", "", "")
+	yes := fs.Bool("yes", false, "")
+	fs.Parse(args)
+
+	if *keystorePath == "" || (*bundlePath == "") == (*urPath == "") {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	bundle, err := readBundle(*bundlePath, *urPath)
+	if err != nil {
+		return err
+	}
+	if err := bundle.Validate(); err != nil {
+		return err
+	}
+
+	// 校验Payload与意图一致，避免展示的内容与实际签名的交易不符
+	decoded, err := ethereum.DecodeRawTransaction(bundle.Tx.Payload, bundle.ChainID)
+	if err != nil {
+		return err
+	}
+	decoded.From = bundle.Tx.From
+	if err := offline.CheckIntent(bundle.Intent, decoded, false); err != nil {
+		return err
+	}
+
+	printIntent(bundle)
+	if !*yes && !confirm("确认签名该交易? [y/N] ") {
+		return errors.New("signing cancelled")
+	}
+
+	keyJSON, err := os.ReadFile(*keystorePath)
+	if err != nil {
+		return fmt.Errorf("failed to read keystore: %v", err)
+	}
+	password, err := readPassword(*passwordFile)
+	if err != nil {
+		return err
+	}
+	key, err := keystore.DecryptKey(keyJSON, password)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt keystore: %v", err)
+	}
+	if !strings.EqualFold(key.Address.Hex(), bundle.Tx.From) {
+		return fmt.Errorf("keystore address %s does not match transaction sender %s", key.Address.Hex(), bundle.Tx.From)
+	}
+
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(bundle.Tx.Payload); err != nil {
+		return fmt.Errorf("failed to decode transaction: %v", err)
+	}
+	signedTx, err := types.SignTx(&tx, types.LatestSignerForChainID(bundle.ChainID), key.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign transaction: %v", err)
+	}
+	rawTx, err := signedTx.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode signed transaction: %v", err)
+	}
+
+	// 签名后再次解码，确认签名者与意图一致
+	decoded, err = ethereum.DecodeRawTransaction(rawTx, bundle.ChainID)
+	if err != nil {
+		return err
+	}
+	if err := offline.CheckIntent(bundle.Intent, decoded, true); err != nil {
+		return err
+	}
+
+	signedJSON, err := json.MarshalIndent(&offline.SignedBundle{
+		BundleID:  bundle.ID,
+		ChainType: bundle.ChainType,
+		RawTx:     rawTx,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if *outPath != "" {
+		if err := os.WriteFile(*outPath, signedJSON, 0600); err != nil {
+			return fmt.Errorf("failed to write signed bundle: %v", err)
+		}
+	} else {
+		fmt.Println(string(signedJSON))
+	}
+	if *urOutPath != "" {
+		frames := offline.EncodeUR(signedJSON, offline.DefaultMaxFragmentLen)
+		if err := os.WriteFile(*urOutPath, []byte(strings.Join(frames, "\n")+"\n"), 0600); err != nil {
+			return fmt.Errorf("failed to write ur frames: %v", err)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "已签名交易 %s\n", decoded.Hash)
+	return nil
+}
+
+// readBundle 从JSON文件或UR帧文件（每行一帧）读取离线签名包
+func readBundle(bundlePath, urPath string) (*offline.Bundle, error) {
+	var data []byte
+	if bundlePath != "" {
+		content, err := os.ReadFile(bundlePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundle: %v", err)
+		}
+		data = content
+	} else {
+		content, err := os.ReadFile(urPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ur frames: %v", err)
+		}
+		var frames []string
+		for _, line := range strings.Split(string(content), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				frames = append(frames, line)
+			}
+		}
+		if data, err = offline.DecodeUR(frames); err != nil {
+			return nil, err
+		}
+	}
+
+	var bundle offline.Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %v", err)
+	}
+	return &bundle, nil
+}
+
+// printIntent 展示待签名交易的意图
+func printIntent(bundle *offline.Bundle) {
+	intent := bundle.Intent
+	fmt.Fprintf(os.Stderr, "离线签名包 %s\n", bundle.ID)
+	fmt.Fprintf(os.Stderr, "  链:       %s (chainId=%s)\n", bundle.ChainType, bundle.ChainID)
+	fmt.Fprintf(os.Stderr, "  发送方:   %s\n", intent.From)
+	if bundle.Tx.Token != "" {
+		fmt.Fprintf(os.Stderr, "  代币:     %s\n", bundle.Tx.Token)
+		fmt.Fprintf(os.Stderr, "  收款方:   %s\n", bundle.Tx.To)
+		fmt.Fprintf(os.Stderr, "  数量:     %s\n", bundle.Tx.Value)
+	} else {
+		fmt.Fprintf(os.Stderr, "  收款方:   %s\n", intent.To)
+		fmt.Fprintf(os.Stderr, "  金额:     %s wei\n", intent.Value)
+	}
+	fmt.Fprintf(os.Stderr, "  nonce:    %d\n", intent.Nonce)
+	if intent.Fee != nil {
+		fmt.Fprintf(os.Stderr, "  gasLimit: %d\n", intent.Fee.GasLimit)
+		if intent.Fee.GasFeeCap != nil {
+			fmt.Fprintf(os.Stderr, "  gasFeeCap/gasTipCap: %s/%s wei\n", intent.Fee.GasFeeCap, intent.Fee.GasTipCap)
+		} else {
+			fmt.Fprintf(os.Stderr, "  gasPrice: %s wei\n", intent.Fee.GasPrice)
+		}
+		fmt.Fprintf(os.Stderr, "  最高手续费: %s wei\n", intent.Fee.MaxAmount)
+	}
+	if intent.Method != "" {
+		fmt.Fprintf(os.Stderr, "  方法:     %s\n", intent.Method)
+	}
+}
+
+// readPassword 按密码文件、环境变量、标准输入的顺序读取keystore密码
+func readPassword(passwordFile string) (string, error) {
+	if passwordFile != "" {
+		content, err := os.ReadFile(passwordFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read password file: %v", err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+	if password, ok := os.LookupEnv("WALLETCTL_PASSWORD"); ok {
+		return password, nil
+	}
+
+	fmt.Fprint(os.Stderr, "keystore密码: ")
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read password: %v", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func confirm(prompt string) bool {
+	fmt.Fprint(os.Stderr, prompt)
+	line, _ := stdin.ReadString('\n')
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes"
+}
@@ -11,6 +11,8 @@ import {
   CreateTransactionRequest,
   SignTransactionRequest,
   SendTransactionRequest,
+  UnsignedTx,
+  SignedTx,
 } from '../types';
 
 // 定义API响应格式
@@ -28,12 +30,12 @@ interface CreateWalletResponse {
 
 // 交易响应
 interface TxResponse {
-  tx: string;
+  tx: UnsignedTx;
 }
 
 // 签名交易响应
 interface SignedTxResponse {
-  signed_tx: string;
+  signed_tx: SignedTx;
 }
 
 // 发送交易响应
@@ -119,6 +121,36 @@ export const importWalletFromPrivateKey = async (chainType: ChainType, privateKe
   }
 };
 
+// 注册远程签名钱包（私钥由Clef兼容的签名服务托管）
+export const importRemoteSignerWallet = async (chainType: ChainType, signerUrl: string, address: string): Promise<string> => {
+  try {
+    const response = await api.post<any, CreateWalletResponse>('/wallets/import/remote', {
+      chainType: chainType.toString(),
+      signerUrl,
+      address,
+    });
+    return response.wallet_id;
+  } catch (error) {
+    console.error('Failed to import remote signer wallet:', error);
+    throw error;
+  }
+};
+
+// 创建门限签名钱包（私钥以t-of-n分片保存在多个签名节点上）
+export const createThresholdWallet = async (chainType: ChainType, nodes: string[], threshold: number): Promise<string> => {
+  try {
+    const response = await api.post<any, CreateWalletResponse>('/wallets/create/threshold', {
+      chainType: chainType.toString(),
+      nodes,
+      threshold,
+    });
+    return response.wallet_id;
+  } catch (error) {
+    console.error('Failed to create threshold wallet:', error);
+    throw error;
+  }
+};
+
 // 获取钱包信息
 export const getWalletInfo = async (walletId: string): Promise<Wallet> => {
   try {
@@ -190,7 +222,7 @@ export const createTransaction = async (
   amount: string,
   chainType: ChainType,
   data?: string
-): Promise<string> => {
+): Promise<UnsignedTx> => {
   try {
     const response = await api.post<any, TxResponse>('/wallets/tx/create', {
       from,
@@ -207,7 +239,7 @@ export const createTransaction = async (
 };
 
 // 签名交易
-export const signTransaction = async (walletId: string, tx: string, chainType: ChainType): Promise<string> => {
+export const signTransaction = async (walletId: string, tx: UnsignedTx, chainType: ChainType): Promise<SignedTx> => {
   try {
     const response = await api.post<any, SignedTxResponse>('/wallets/tx/sign', {
       wallet_id: walletId,
@@ -222,7 +254,7 @@ export const signTransaction = async (walletId: string, tx: string, chainType: C
 };
 
 // 发送交易
-export const sendTransaction = async (walletId: string, signedTx: string, chainType: ChainType): Promise<string> => {
+export const sendTransaction = async (walletId: string, signedTx: SignedTx, chainType: ChainType): Promise<string> => {
   try {
     const response = await api.post<any, TxHashResponse>('/wallets/tx/send', {
       wallet_id: walletId,
@@ -121,17 +121,53 @@ export interface CreateTransactionRequest {
   data?: string;
 }
 
+// 交易费用，金额为最小单位
+export interface TxFee {
+  gasLimit?: number;
+  gasPrice?: number;
+  gasTipCap?: number;
+  gasFeeCap?: number;
+  amount?: number;
+  maxAmount?: number;
+}
+
+// 待签名交易，payload为链特定的base64数据，原样传给签名接口
+export interface UnsignedTx {
+  chainType: ChainType;
+  from: string;
+  to: string;
+  value: number;
+  token?: string;
+  nonce?: number;
+  fee?: TxFee;
+  payload: string;
+  details?: unknown;
+}
+
+// 已签名交易，payload为可广播的原始交易
+export interface SignedTx {
+  chainType: ChainType;
+  from: string;
+  to: string;
+  value: number;
+  token?: string;
+  nonce?: number;
+  fee?: TxFee;
+  hash: string;
+  payload: string;
+}
+
 // 签名交易请求
 export interface SignTransactionRequest {
   wallet_id: string;
-  tx: string;
+  tx: UnsignedTx;
   chain_type: ChainType;
 }
 
 // 发送交易请求
 export interface SendTransactionRequest {
   wallet_id: string;
-  signed_tx: string;
+  signed_tx: SignedTx;
   chain_type: ChainType;
 }
 
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/response"
+	"multi-chain-wallet/internal/service"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// ApprovalHandler 代币授权处理器
+type ApprovalHandler struct {
+	approvalService *service.ApprovalService
+}
+
+// NewApprovalHandler 创建代币授权处理器
+func NewApprovalHandler(approvalService *service.ApprovalService) *ApprovalHandler {
+	return &ApprovalHandler{
+		approvalService: approvalService,
+	}
+}
+
+// Register 注册路由
+func (h *ApprovalHandler) Register(router *gin.Engine) {
+	approvalGroup := router.Group("/api/v1/approvals")
+	{
+		approvalGroup.GET("/:walletId", h.ListAllowances)
+		approvalGroup.POST("/scan", h.ScanWallet)
+		approvalGroup.POST("/revoke", h.BuildRevokeTransactions)
+	}
+}
+
+type scanApprovalsRequest struct {
+	WalletID string `json:"walletId" binding:"required"`
+}
+
+type revokeApprovalsRequest struct {
+	WalletID  string                `json:"walletId" binding:"required"`
+	Approvals []wallet.TokenSpender `json:"approvals"` // 为空时撤销全部授权
+}
+
+// ListAllowances 列出钱包的授权
+func (h *ApprovalHandler) ListAllowances(c *gin.Context) {
+	walletID := c.Param("walletId")
+	if walletID == "" {
+		response.BadRequest(c, "Wallet ID is required")
+		return
+	}
+
+	allowances, err := h.approvalService.ListAllowances(walletID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"allowances": allowances,
+	})
+}
+
+// ScanWallet 立即扫描钱包授权
+func (h *ApprovalHandler) ScanWallet(c *gin.Context) {
+	var req scanApprovalsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	if err := h.approvalService.ScanWallet(ctx, req.WalletID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	allowances, err := h.approvalService.ListAllowances(req.WalletID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"allowances": allowances,
+	})
+}
+
+// BuildRevokeTransactions 构建批量撤销授权交易，返回的交易需经/tx/sign签名后发送
+func (h *ApprovalHandler) BuildRevokeTransactions(c *gin.Context) {
+	var req revokeApprovalsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	txs, err := h.approvalService.BuildRevokeTransactions(ctx, req.WalletID, req.Approvals)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	txList := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		txList = append(txList, string(tx))
+	}
+
+	response.Success(c, gin.H{
+		"txs": txList,
+	})
+}
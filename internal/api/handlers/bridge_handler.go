@@ -26,9 +26,10 @@ func NewBridgeHandler(bridgeService *service.BridgeService) *BridgeHandler {
 
 // bridgeTransactionRequest 跨链交易请求
 type bridgeTransactionRequest struct {
+	WalletID        string `json:"walletId" binding:"required"`
 	FromChainType   string `json:"fromChainType" binding:"required"`
 	ToChainType     string `json:"toChainType" binding:"required"`
-	FromAddress     string `json:"fromAddress" binding:"required"`
+	FromAddress     string `json:"fromAddress,omitempty"` // 可省略，为钱包地址
 	ToAddress       string `json:"toAddress" binding:"required"`
 	Amount          string `json:"amount" binding:"required"`
 	TokenAddress    string `json:"tokenAddress,omitempty"`
@@ -73,6 +74,7 @@ func (h *BridgeHandler) CrossChainTransfer(c *gin.Context) {
 
 	// 创建跨链交易请求
 	bridgeTx := &service.BridgeTransaction{
+		WalletID:        req.WalletID,
 		FromChainType:   fromChainType,
 		ToChainType:     toChainType,
 		FromAddress:     req.FromAddress,
@@ -85,7 +87,7 @@ func (h *BridgeHandler) CrossChainTransfer(c *gin.Context) {
 	// 执行跨链转账
 	txHash, err := h.bridgeService.CrossChainTransfer(ctx, bridgeTx)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -109,7 +111,7 @@ func (h *BridgeHandler) GetBridgeTransactionStatus(c *gin.Context) {
 	// 获取交易状态
 	status, err := h.bridgeService.GetBridgeTransactionStatus(ctx, txHash)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -129,7 +131,7 @@ func (h *BridgeHandler) GetBridgeTransactionHistory(c *gin.Context) {
 	// 获取交易历史
 	history, err := h.bridgeService.GetBridgeTransactionHistory(address)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
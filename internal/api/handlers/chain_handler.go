@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/response"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// ChainHandler 链注册表处理器
+type ChainHandler struct {
+	walletManager *wallet.Manager
+}
+
+// NewChainHandler 创建链注册表处理器
+func NewChainHandler(walletManager *wallet.Manager) *ChainHandler {
+	return &ChainHandler{
+		walletManager: walletManager,
+	}
+}
+
+// Register 注册路由
+func (h *ChainHandler) Register(router *gin.Engine) {
+	chainGroup := router.Group("/api/v1/chains")
+	{
+		chainGroup.GET("", h.ListChains)
+		chainGroup.GET("/status", h.ListChainStatus)
+		chainGroup.GET("/:chainType", h.GetChain)
+		chainGroup.GET("/:chainType/status", h.GetChainStatus)
+		chainGroup.GET("/:chainType/rpc", h.GetRPCHealth)
+	}
+}
+
+// ListChains 列出所有已注册的链
+func (h *ChainHandler) ListChains(c *gin.Context) {
+	response.Success(c, gin.H{
+		"chains": h.walletManager.ListChains(),
+	})
+}
+
+// GetChain 获取单条链的信息
+func (h *ChainHandler) GetChain(c *gin.Context) {
+	chain, exists := h.walletManager.GetChainInfo(wallet.ChainType(c.Param("chainType")))
+	if !exists {
+		response.NotFound(c, "Chain not found")
+		return
+	}
+
+	response.Success(c, chain)
+}
+
+// ListChainStatus 列出所有链的连接状态
+func (h *ChainHandler) ListChainStatus(c *gin.Context) {
+	response.Success(c, gin.H{
+		"chains": h.walletManager.ListChainStatus(),
+	})
+}
+
+// GetChainStatus 获取单条链的连接状态
+func (h *ChainHandler) GetChainStatus(c *gin.Context) {
+	status, exists := h.walletManager.GetChainStatus(wallet.ChainType(c.Param("chainType")))
+	if !exists {
+		response.NotFound(c, "Chain not found")
+		return
+	}
+
+	response.Success(c, status)
+}
+
+// GetRPCHealth 获取链的RPC节点健康状况
+func (h *ChainHandler) GetRPCHealth(c *gin.Context) {
+	chainType := wallet.ChainType(c.Param("chainType"))
+	walletImpl, exists := h.walletManager.GetWallet(chainType)
+	if !exists {
+		response.NotFound(c, "Chain not found")
+		return
+	}
+
+	endpoints := []wallet.RPCEndpointHealth{}
+	if reporter, ok := walletImpl.(wallet.RPCHealthReporter); ok {
+		if health := reporter.RPCHealth(); health != nil {
+			endpoints = health
+		}
+	}
+
+	response.Success(c, gin.H{
+		"chainType": chainType,
+		"endpoints": endpoints,
+	})
+}
@@ -91,7 +91,7 @@ func (h *DEXHandler) GetQuote(c *gin.Context) {
 	// 获取最佳路径
 	route, err := h.dexService.FindBestRoute(ctx, wallet.ChainType(req.ChainType), req.FromToken, req.ToToken, amount)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -135,7 +135,7 @@ func (h *DEXHandler) Swap(c *gin.Context) {
 	// 执行兑换
 	txHash, err := h.dexService.Swap(ctx, req.WalletID, wallet.ChainType(req.ChainType), req.FromToken, req.ToToken, amount, minReceived)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -172,7 +172,7 @@ func (h *DEXHandler) PlaceLimitOrder(c *gin.Context) {
 	// 创建限价订单
 	orderID, err := h.dexService.PlaceLimitOrder(ctx, req.WalletID, wallet.ChainType(req.ChainType), req.FromToken, req.ToToken, amount, limitPrice)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -196,7 +196,7 @@ func (h *DEXHandler) CancelLimitOrder(c *gin.Context) {
 	// 取消限价订单
 	txHash, err := h.dexService.CancelLimitOrder(ctx, req.WalletID, wallet.ChainType(req.ChainType), req.OrderID)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -220,7 +220,7 @@ func (h *DEXHandler) GetOrderStatus(c *gin.Context) {
 	// 获取订单状态
 	status, err := h.dexService.GetOrderStatus(ctx, orderID)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -247,7 +247,7 @@ func (h *DEXHandler) GetOrders(c *gin.Context) {
 	// 获取用户订单
 	orders, err := h.dexService.GetOrdersByWallet(ctx, walletID, limit, offset)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/response"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// respondError 按错误类型返回响应：钱包或交易不存在返回404，链、远程签名服务或bundler不可用返回503，链不支持的操作、余额不足和无效交易返回400，
+// 代付额度不足、超出授权范围、违反支出策略、未获审批和地址命中拒绝名单返回403，其余返回500
+func respondError(c *gin.Context, err error) {
+	if errors.Is(err, wallet.ErrWalletNotFound) || errors.Is(err, wallet.ErrTransactionNotFound) {
+		response.NotFound(c, err.Error())
+		return
+	}
+	if errors.Is(err, wallet.ErrChainUnavailable) || errors.Is(err, wallet.ErrSignerUnavailable) ||
+		errors.Is(err, wallet.ErrBundlerUnavailable) {
+		response.ServiceUnavailable(c, err.Error())
+		return
+	}
+	if errors.Is(err, wallet.ErrOperationNotSupported) || errors.Is(err, wallet.ErrInsufficientFunds) ||
+		errors.Is(err, wallet.ErrInvalidTransaction) || errors.Is(err, wallet.ErrChainIDMismatch) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if errors.Is(err, wallet.ErrBudgetExceeded) || errors.Is(err, wallet.ErrPermissionDenied) ||
+		errors.Is(err, wallet.ErrPolicyViolation) || errors.Is(err, wallet.ErrApprovalRequired) ||
+		errors.Is(err, wallet.ErrAddressBlocked) {
+		response.Forbidden(c, err.Error())
+		return
+	}
+	response.InternalServerError(c, err.Error())
+}
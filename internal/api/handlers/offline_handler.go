@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/response"
+	"multi-chain-wallet/internal/offline"
+	"multi-chain-wallet/internal/service"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// OfflineHandler 离线签名处理器
+type OfflineHandler struct {
+	offlineService *service.OfflineService
+}
+
+// NewOfflineHandler 创建离线签名处理器
+func NewOfflineHandler(offlineService *service.OfflineService) *OfflineHandler {
+	return &OfflineHandler{
+		offlineService: offlineService,
+	}
+}
+
+// Register 注册路由
+func (h *OfflineHandler) Register(router *gin.Engine) {
+	offlineGroup := router.Group("/api/v1/offline")
+	{
+		offlineGroup.POST("/prepare", h.PrepareBundle)
+		offlineGroup.GET("/:bundleId", h.GetBundle)
+		offlineGroup.POST("/submit", h.SubmitSigned)
+	}
+}
+
+// submitSignedRequest 导入离线签名结果，signed和ur二选一
+type submitSignedRequest struct {
+	Signed *offline.SignedBundle `json:"signed,omitempty"`
+	UR     []string              `json:"ur,omitempty"` // 扫描得到的UR帧，顺序任意
+}
+
+// bundleResponse 离线签名包响应，ur为动态二维码的各帧内容
+type bundleResponse struct {
+	Bundle *offline.Bundle `json:"bundle"`
+	UR     []string        `json:"ur"`
+	Status string          `json:"status,omitempty"`
+	TxHash string          `json:"txHash,omitempty"`
+}
+
+// PrepareBundle 创建交易并导出离线签名包
+func (h *OfflineHandler) PrepareBundle(c *gin.Context) {
+	var req createTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	amount, ok := new(big.Int).SetString(req.Amount, 10)
+	if !ok {
+		response.BadRequest(c, "Invalid amount format")
+		return
+	}
+
+	var data []byte
+	if req.Data != "" {
+		data = []byte(req.Data)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	bundle, err := h.offlineService.PrepareBundle(ctx, wallet.ChainType(req.ChainType), req.From, req.To, req.TokenAddress, amount, data)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	bundleJSON, err := json.Marshal(bundle)
+	if err != nil {
+		response.InternalServerError(c, err.Error())
+		return
+	}
+
+	response.Success(c, &bundleResponse{
+		Bundle: bundle,
+		UR:     offline.EncodeUR(bundleJSON, offline.DefaultMaxFragmentLen),
+	})
+}
+
+// GetBundle 获取离线签名包，用于重新展示二维码或查询广播状态
+func (h *OfflineHandler) GetBundle(c *gin.Context) {
+	bundleID := c.Param("bundleId")
+	if bundleID == "" {
+		response.BadRequest(c, "Bundle ID is required")
+		return
+	}
+
+	bundle, record, err := h.offlineService.GetBundle(bundleID)
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, &bundleResponse{
+		Bundle: bundle,
+		UR:     offline.EncodeUR([]byte(record.Bundle), offline.DefaultMaxFragmentLen),
+		Status: record.Status,
+		TxHash: record.TxHash,
+	})
+}
+
+// SubmitSigned 导入离线签名结果并广播
+func (h *OfflineHandler) SubmitSigned(c *gin.Context) {
+	var req submitSignedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	signed := req.Signed
+	if len(req.UR) > 0 {
+		data, err := offline.DecodeUR(req.UR)
+		if err != nil {
+			response.BadRequest(c, "Invalid UR: "+err.Error())
+			return
+		}
+		signed = &offline.SignedBundle{}
+		if err := json.Unmarshal(data, signed); err != nil {
+			response.BadRequest(c, "Invalid signed bundle")
+			return
+		}
+	}
+	if signed == nil || signed.BundleID == "" || len(signed.RawTx) == 0 {
+		response.BadRequest(c, "Signed bundle is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	txHash, err := h.offlineService.SubmitSigned(ctx, signed)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"tx_hash": txHash,
+	})
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/response"
+	"multi-chain-wallet/internal/service"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// PolicyHandler 钱包支出策略处理器
+type PolicyHandler struct {
+	policyService *service.PolicyService
+	adminAuth     gin.HandlerFunc
+}
+
+// NewPolicyHandler 创建钱包支出策略处理器，设置和删除策略须通过adminAuth认证
+func NewPolicyHandler(policyService *service.PolicyService, adminAuth gin.HandlerFunc) *PolicyHandler {
+	return &PolicyHandler{
+		policyService: policyService,
+		adminAuth:     adminAuth,
+	}
+}
+
+// Register 注册路由
+func (h *PolicyHandler) Register(router *gin.Engine) {
+	policyGroup := router.Group("/api/v1/policies")
+	{
+		policyGroup.GET("/:walletId", h.GetPolicy)
+		policyGroup.PUT("/:walletId", h.adminAuth, h.SetPolicy)
+		policyGroup.DELETE("/:walletId", h.adminAuth, h.DeletePolicy)
+		policyGroup.POST("/:walletId/evaluate", h.EvaluateTransaction)
+	}
+}
+
+// evaluatePolicyRequest 试算交易的请求
+type evaluatePolicyRequest struct {
+	ChainType string             `json:"chainType" binding:"required"`
+	Tx        *wallet.UnsignedTx `json:"tx" binding:"required"` // /tx/create返回的交易
+}
+
+// GetPolicy 获取钱包的策略及滚动24小时内的额度使用情况
+func (h *PolicyHandler) GetPolicy(c *gin.Context) {
+	policy, err := h.policyService.GetPolicy(c.Param("walletId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if policy == nil {
+		response.NotFound(c, "Policy not found")
+		return
+	}
+
+	response.Success(c, policy)
+}
+
+// SetPolicy 设置或替换钱包的策略，放宽的策略延迟生效
+func (h *PolicyHandler) SetPolicy(c *gin.Context) {
+	var req service.SpendingPolicy
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	policy, err := h.policyService.SetPolicy(c.Param("walletId"), &req)
+	if err != nil {
+		if errors.Is(err, wallet.ErrWalletNotFound) {
+			response.NotFound(c, err.Error())
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, policy)
+}
+
+// DeletePolicy 删除钱包的策略，延迟生效时返回带待生效变更的策略
+func (h *PolicyHandler) DeletePolicy(c *gin.Context) {
+	policy, err := h.policyService.DeletePolicy(c.Param("walletId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if policy != nil {
+		response.Success(c, policy)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"walletId": c.Param("walletId"),
+	})
+}
+
+// EvaluateTransaction 试算交易是否符合策略，不签名
+func (h *PolicyHandler) EvaluateTransaction(c *gin.Context) {
+	var req evaluatePolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	decision, err := h.policyService.EvaluateTransaction(ctx, wallet.ChainType(req.ChainType), c.Param("walletId"), req.Tx)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, decision)
+}
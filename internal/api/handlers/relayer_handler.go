@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/response"
+	"multi-chain-wallet/internal/service"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// RelayerHandler EIP-2771元交易中继处理器
+type RelayerHandler struct {
+	relayerService *service.RelayerService
+	adminAuth      gin.HandlerFunc // 设置代付额度须通过管理接口认证
+}
+
+// NewRelayerHandler 创建元交易中继处理器
+func NewRelayerHandler(relayerService *service.RelayerService, adminAuth gin.HandlerFunc) *RelayerHandler {
+	return &RelayerHandler{
+		relayerService: relayerService,
+		adminAuth:      adminAuth,
+	}
+}
+
+// Register 注册路由
+func (h *RelayerHandler) Register(router *gin.Engine) {
+	relayGroup := router.Group("/api/v1/relay")
+	{
+		relayGroup.POST("/prepare", h.PrepareRequest)
+		relayGroup.POST("/submit", h.SubmitRequest)
+		relayGroup.GET("/transactions/:id", h.GetTransaction)
+		relayGroup.GET("/users/:address/transactions", h.ListTransactions)
+		relayGroup.GET("/users/:address/budget", h.GetBudget)
+		relayGroup.PUT("/users/:address/budget", h.adminAuth, h.SetBudget)
+	}
+}
+
+// forwardRequestBody ForwardRequest，value和nonce为十进制字符串，nonce、gas和deadline为空时由服务端补全
+type forwardRequestBody struct {
+	From     string `json:"from"`
+	To       string `json:"to" binding:"required"`
+	Value    string `json:"value,omitempty"`
+	Gas      uint64 `json:"gas,omitempty"`
+	Nonce    string `json:"nonce,omitempty"`
+	Deadline uint64 `json:"deadline,omitempty"`
+	Data     string `json:"data,omitempty"`
+}
+
+// prepareRelayRequest 构造待签名ForwardRequest的请求
+type prepareRelayRequest struct {
+	ChainType string             `json:"chainType" binding:"required"`
+	Request   forwardRequestBody `json:"request" binding:"required"`
+}
+
+// submitRelayRequest 提交ForwardRequest的请求。提供signature时request须为prepare返回的完整请求；
+// 提供walletId时由托管钱包签名，chainType取钱包所在链
+type submitRelayRequest struct {
+	ChainType string             `json:"chainType,omitempty"`
+	WalletID  string             `json:"walletId,omitempty"`
+	Request   forwardRequestBody `json:"request" binding:"required"`
+	Signature string             `json:"signature,omitempty"`
+}
+
+// setBudgetRequest 设置用户每日代付额度的请求，dailyLimit为wei的十进制字符串
+type setBudgetRequest struct {
+	ChainType  string `json:"chainType" binding:"required"`
+	DailyLimit string `json:"dailyLimit" binding:"required"`
+}
+
+// PrepareRequest 补全ForwardRequest并返回待用户签名的EIP-712数据
+func (h *RelayerHandler) PrepareRequest(c *gin.Context) {
+	var req prepareRelayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+	if req.Request.From == "" {
+		response.BadRequest(c, "request.from is required")
+		return
+	}
+	forwardRequest, ok := parseForwardRequest(&req.Request)
+	if !ok {
+		response.BadRequest(c, "Invalid amount format")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	prepared, err := h.relayerService.PrepareRequest(ctx, wallet.ChainType(req.ChainType), forwardRequest)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, prepared)
+}
+
+// SubmitRequest 校验签名和代付额度后由中继钱包提交ForwardRequest
+func (h *RelayerHandler) SubmitRequest(c *gin.Context) {
+	var req submitRelayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+	if req.WalletID == "" && (req.ChainType == "" || req.Signature == "" || req.Request.From == "") {
+		response.BadRequest(c, "walletId or chainType, request.from and signature are required")
+		return
+	}
+	forwardRequest, ok := parseForwardRequest(&req.Request)
+	if !ok {
+		response.BadRequest(c, "Invalid amount format")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var result *service.RelayedTransaction
+	var err error
+	if req.WalletID != "" {
+		result, err = h.relayerService.RelayFromWallet(ctx, req.WalletID, forwardRequest)
+	} else {
+		signature, decodeErr := hexutil.Decode(req.Signature)
+		if decodeErr != nil {
+			response.BadRequest(c, "Invalid signature format")
+			return
+		}
+		result, err = h.relayerService.Relay(ctx, wallet.ChainType(req.ChainType), forwardRequest, signature)
+	}
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetTransaction 获取中继交易及上链状态
+func (h *RelayerHandler) GetTransaction(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := h.relayerService.GetTransaction(ctx, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, wallet.ErrChainUnavailable) {
+			respondError(c, err)
+			return
+		}
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// ListTransactions 获取用户的中继交易
+func (h *RelayerHandler) ListTransactions(c *gin.Context) {
+	chainType := wallet.ChainType(c.Query("chainType"))
+	if chainType == "" {
+		response.BadRequest(c, "Chain type is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	results, err := h.relayerService.ListTransactions(ctx, chainType, c.Param("address"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"transactions": results,
+	})
+}
+
+// GetBudget 获取用户当日的代付额度使用情况
+func (h *RelayerHandler) GetBudget(c *gin.Context) {
+	chainType := wallet.ChainType(c.Query("chainType"))
+	if chainType == "" {
+		response.BadRequest(c, "Chain type is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	budget, err := h.relayerService.GetBudget(ctx, chainType, c.Param("address"))
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, budget)
+}
+
+// SetBudget 单独设置用户的每日代付额度
+func (h *RelayerHandler) SetBudget(c *gin.Context) {
+	var req setBudgetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+	dailyLimit, ok := parseOptionalBigInt(req.DailyLimit)
+	if !ok || dailyLimit == nil {
+		response.BadRequest(c, "Invalid amount format")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	budget, err := h.relayerService.SetBudget(ctx, wallet.ChainType(req.ChainType), c.Param("address"), dailyLimit)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, budget)
+}
+
+// parseForwardRequest 解析ForwardRequest中的十进制数值
+func parseForwardRequest(body *forwardRequestBody) (*wallet.ForwardRequest, bool) {
+	value, ok1 := parseOptionalBigInt(body.Value)
+	nonce, ok2 := parseOptionalBigInt(body.Nonce)
+	if !ok1 || !ok2 {
+		return nil, false
+	}
+
+	return &wallet.ForwardRequest{
+		From:     body.From,
+		To:       body.To,
+		Value:    value,
+		Gas:      body.Gas,
+		Nonce:    nonce,
+		Deadline: body.Deadline,
+		Data:     body.Data,
+	}, true
+}
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/response"
+	"multi-chain-wallet/internal/service"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// SafeHandler Safe多签处理器
+type SafeHandler struct {
+	safeService *service.SafeService
+}
+
+// NewSafeHandler 创建Safe多签处理器
+func NewSafeHandler(safeService *service.SafeService) *SafeHandler {
+	return &SafeHandler{
+		safeService: safeService,
+	}
+}
+
+// Register 注册路由
+func (h *SafeHandler) Register(router *gin.Engine) {
+	safeGroup := router.Group("/api/v1/safe")
+	{
+		safeGroup.POST("/deploy", h.DeploySafe)
+		safeGroup.GET("/:address", h.GetSafeInfo)
+		safeGroup.GET("/:address/transactions", h.ListTransactions)
+		safeGroup.POST("/:address/transactions", h.ProposeTransaction)
+		safeGroup.GET("/tx/:safeTxHash", h.GetTransaction)
+		safeGroup.POST("/tx/:safeTxHash/confirm", h.ConfirmTransaction)
+		safeGroup.POST("/tx/:safeTxHash/execute", h.ExecuteTransaction)
+	}
+}
+
+// deploySafeRequest 部署Safe请求，部署交易由walletId对应的钱包发送
+type deploySafeRequest struct {
+	WalletID  string   `json:"walletId" binding:"required"`
+	Owners    []string `json:"owners" binding:"required"`
+	Threshold int      `json:"threshold" binding:"required"`
+	SaltNonce string   `json:"saltNonce,omitempty"` // 十进制，相同参数部署多个Safe时区分地址
+}
+
+// proposeSafeTxRequest 创建SafeTx提案请求，金额类字段为十进制字符串
+type proposeSafeTxRequest struct {
+	ChainType      string  `json:"chainType" binding:"required"`
+	To             string  `json:"to" binding:"required"`
+	Value          string  `json:"value,omitempty"`
+	Data           string  `json:"data,omitempty"` // 0x开头的calldata
+	Operation      uint8   `json:"operation,omitempty"`
+	SafeTxGas      string  `json:"safeTxGas,omitempty"`
+	BaseGas        string  `json:"baseGas,omitempty"`
+	GasPrice       string  `json:"gasPrice,omitempty"`
+	GasToken       string  `json:"gasToken,omitempty"`
+	RefundReceiver string  `json:"refundReceiver,omitempty"`
+	Nonce          *uint64 `json:"nonce,omitempty"` // 为空时自动分配
+}
+
+// safeWalletRequest 使用托管钱包签名或执行SafeTx的请求
+type safeWalletRequest struct {
+	WalletID string `json:"walletId" binding:"required"`
+}
+
+// DeploySafe 部署Safe
+func (h *SafeHandler) DeploySafe(c *gin.Context) {
+	var req deploySafeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	saltNonce, ok := parseOptionalBigInt(req.SaltNonce)
+	if !ok {
+		response.BadRequest(c, "Invalid salt nonce format")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	deployment, err := h.safeService.DeploySafe(ctx, req.WalletID, req.Owners, req.Threshold, saltNonce)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, deployment)
+}
+
+// GetSafeInfo 查询Safe的所有者、门限和nonce
+func (h *SafeHandler) GetSafeInfo(c *gin.Context) {
+	chainType := wallet.ChainType(c.Query("chainType"))
+	if chainType == "" {
+		response.BadRequest(c, "Chain type is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	info, err := h.safeService.GetSafeInfo(ctx, chainType, c.Param("address"))
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, info)
+}
+
+// ListTransactions 列出Safe的交易提案
+func (h *SafeHandler) ListTransactions(c *gin.Context) {
+	chainType := wallet.ChainType(c.Query("chainType"))
+	if chainType == "" {
+		response.BadRequest(c, "Chain type is required")
+		return
+	}
+
+	proposals, err := h.safeService.ListTransactions(chainType, c.Param("address"))
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"transactions": proposals,
+	})
+}
+
+// ProposeTransaction 创建SafeTx提案，返回safeTxHash和EIP-712签名数据
+func (h *SafeHandler) ProposeTransaction(c *gin.Context) {
+	var req proposeSafeTxRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	value, ok1 := parseOptionalBigInt(req.Value)
+	safeTxGas, ok2 := parseOptionalBigInt(req.SafeTxGas)
+	baseGas, ok3 := parseOptionalBigInt(req.BaseGas)
+	gasPrice, ok4 := parseOptionalBigInt(req.GasPrice)
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		response.BadRequest(c, "Invalid amount format")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	proposal, err := h.safeService.ProposeTransaction(ctx, wallet.ChainType(req.ChainType), &wallet.SafeTransaction{
+		Safe:           c.Param("address"),
+		To:             req.To,
+		Value:          value,
+		Data:           req.Data,
+		Operation:      req.Operation,
+		SafeTxGas:      safeTxGas,
+		BaseGas:        baseGas,
+		GasPrice:       gasPrice,
+		GasToken:       req.GasToken,
+		RefundReceiver: req.RefundReceiver,
+	}, req.Nonce)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, proposal)
+}
+
+// GetTransaction 获取SafeTx提案及已收集的签名
+func (h *SafeHandler) GetTransaction(c *gin.Context) {
+	proposal, err := h.safeService.GetTransaction(c.Param("safeTxHash"))
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, proposal)
+}
+
+// ConfirmTransaction 使用托管的所有者钱包签名SafeTx
+func (h *SafeHandler) ConfirmTransaction(c *gin.Context) {
+	var req safeWalletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	proposal, err := h.safeService.ConfirmTransaction(ctx, c.Param("safeTxHash"), req.WalletID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, proposal)
+}
+
+// ExecuteTransaction 签名达到门限后提交execTransaction
+func (h *SafeHandler) ExecuteTransaction(c *gin.Context) {
+	var req safeWalletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	txHash, err := h.safeService.ExecuteTransaction(ctx, c.Param("safeTxHash"), req.WalletID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"tx_hash": txHash,
+	})
+}
+
+// parseOptionalBigInt 解析十进制字符串，空字符串返回nil
+func parseOptionalBigInt(value string) (*big.Int, bool) {
+	if value == "" {
+		return nil, true
+	}
+	return new(big.Int).SetString(value, 10)
+}
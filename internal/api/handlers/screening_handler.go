@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/response"
+	"multi-chain-wallet/internal/service"
+)
+
+// ScreeningHandler 地址筛查处理器
+type ScreeningHandler struct {
+	screeningService *service.ScreeningService
+	adminAuth        gin.HandlerFunc
+}
+
+// NewScreeningHandler 创建地址筛查处理器，重新加载名单须通过adminAuth认证
+func NewScreeningHandler(screeningService *service.ScreeningService, adminAuth gin.HandlerFunc) *ScreeningHandler {
+	return &ScreeningHandler{
+		screeningService: screeningService,
+		adminAuth:        adminAuth,
+	}
+}
+
+// Register 注册路由
+func (h *ScreeningHandler) Register(router *gin.Engine) {
+	screeningGroup := router.Group("/api/v1/screening")
+	{
+		screeningGroup.POST("/check", h.CheckAddresses)
+		screeningGroup.GET("/lists", h.ListLists)
+		screeningGroup.POST("/reload", h.adminAuth, h.ReloadLists)
+	}
+}
+
+// checkAddressesRequest 筛查地址的请求
+type checkAddressesRequest struct {
+	Addresses []string `json:"addresses" binding:"required,min=1"`
+}
+
+// CheckAddresses 按已加载的名单筛查地址，只返回结果不拒绝请求
+func (h *ScreeningHandler) CheckAddresses(c *gin.Context) {
+	var req checkAddressesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	response.Success(c, h.screeningService.Screen(req.Addresses))
+}
+
+// ListLists 获取各名单的加载情况
+func (h *ScreeningHandler) ListLists(c *gin.Context) {
+	response.Success(c, gin.H{
+		"lists": h.screeningService.ListStatus(),
+	})
+}
+
+// ReloadLists 立即重新加载名单，不等待定时任务
+func (h *ScreeningHandler) ReloadLists(c *gin.Context) {
+	response.Success(c, gin.H{
+		"lists": h.screeningService.Reload(),
+	})
+}
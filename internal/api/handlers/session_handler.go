@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/response"
+	"multi-chain-wallet/internal/service"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// SessionHandler 会话密钥处理器
+type SessionHandler struct {
+	sessionService *service.SessionService
+}
+
+// NewSessionHandler 创建会话密钥处理器
+func NewSessionHandler(sessionService *service.SessionService) *SessionHandler {
+	return &SessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// Register 注册路由
+func (h *SessionHandler) Register(router *gin.Engine) {
+	sessionGroup := router.Group("/api/v1/sessions")
+	{
+		sessionGroup.POST("", h.CreateSession)
+		sessionGroup.GET("", h.ListSessions)
+		sessionGroup.GET("/:id", h.GetSession)
+		sessionGroup.POST("/:id/revoke", h.RevokeSession)
+		sessionGroup.POST("/sign", h.SignTransaction)
+	}
+}
+
+// createSessionRequest 创建会话请求。金额为原生代币最小单位的十进制字符串，为空表示不限；
+// expiresAt和expiresIn（秒）二选一
+type createSessionRequest struct {
+	WalletID      string                 `json:"walletId" binding:"required"`
+	Label         string                 `json:"label,omitempty"`
+	Scopes        []service.SessionScope `json:"scopes" binding:"required"`
+	MaxValuePerTx string                 `json:"maxValuePerTx,omitempty"`
+	TotalBudget   string                 `json:"totalBudget,omitempty"`
+	ExpiresAt     int64                  `json:"expiresAt,omitempty"`
+	ExpiresIn     int64                  `json:"expiresIn,omitempty"`
+}
+
+// sessionSignRequest 会话签名请求
+type sessionSignRequest struct {
+	SessionToken string             `json:"sessionToken" binding:"required"`
+	Tx           *wallet.UnsignedTx `json:"tx" binding:"required"` // /tx/create返回的交易
+}
+
+// CreateSession 为钱包签发会话，响应中的token只返回这一次
+func (h *SessionHandler) CreateSession(c *gin.Context) {
+	var req createSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+	maxValuePerTx, ok1 := parseOptionalBigInt(req.MaxValuePerTx)
+	totalBudget, ok2 := parseOptionalBigInt(req.TotalBudget)
+	if !ok1 || !ok2 {
+		response.BadRequest(c, "Invalid amount format")
+		return
+	}
+	expiresAt := req.ExpiresAt
+	if expiresAt == 0 && req.ExpiresIn > 0 {
+		expiresAt = time.Now().Unix() + req.ExpiresIn
+	}
+	if expiresAt == 0 {
+		response.BadRequest(c, "expiresAt or expiresIn is required")
+		return
+	}
+
+	session, err := h.sessionService.CreateSession(&service.SessionParams{
+		WalletID:      req.WalletID,
+		Label:         req.Label,
+		Scopes:        req.Scopes,
+		MaxValuePerTx: maxValuePerTx,
+		TotalBudget:   totalBudget,
+		ExpiresAt:     expiresAt,
+	})
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, session)
+}
+
+// ListSessions 列出钱包的会话
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	walletID := c.Query("walletId")
+	if walletID == "" {
+		response.BadRequest(c, "Wallet ID is required")
+		return
+	}
+
+	sessions, err := h.sessionService.ListSessions(walletID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"sessions": sessions,
+	})
+}
+
+// GetSession 获取会话及使用情况
+func (h *SessionHandler) GetSession(c *gin.Context) {
+	session, err := h.sessionService.GetSession(c.Param("id"))
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, session)
+}
+
+// RevokeSession 吊销会话
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	session, err := h.sessionService.RevokeSession(c.Param("id"))
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, session)
+}
+
+// SignTransaction 用会话令牌签名交易，超出会话授权范围时返回403及原因
+func (h *SessionHandler) SignTransaction(c *gin.Context) {
+	var req sessionSignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	signedTx, err := h.sessionService.SignTransaction(ctx, req.SessionToken, req.Tx)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"signed_tx": signedTx,
+	})
+}
@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/response"
+	"multi-chain-wallet/internal/service"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// SmartAccountHandler ERC-4337智能账户处理器
+type SmartAccountHandler struct {
+	smartAccountService *service.SmartAccountService
+}
+
+// NewSmartAccountHandler 创建智能账户处理器
+func NewSmartAccountHandler(smartAccountService *service.SmartAccountService) *SmartAccountHandler {
+	return &SmartAccountHandler{
+		smartAccountService: smartAccountService,
+	}
+}
+
+// Register 注册路由
+func (h *SmartAccountHandler) Register(router *gin.Engine) {
+	accountGroup := router.Group("/api/v1/smart-accounts")
+	{
+		accountGroup.GET("/:walletId", h.GetAccount)
+		accountGroup.GET("/:walletId/userops", h.ListUserOperations)
+		accountGroup.POST("/userops/build", h.BuildUserOperation)
+		accountGroup.POST("/userops/send", h.SendUserOperation)
+		accountGroup.GET("/userops/:userOpHash", h.GetUserOperation)
+	}
+}
+
+// smartAccountCallRequest 智能账户执行的调用，value为十进制字符串
+type smartAccountCallRequest struct {
+	To    string `json:"to" binding:"required"`
+	Value string `json:"value,omitempty"`
+	Data  string `json:"data,omitempty"`
+}
+
+// paymasterRequest 代付gas的paymaster，gas限制为空时由bundler估算
+type paymasterRequest struct {
+	Address              string `json:"address" binding:"required"`
+	Data                 string `json:"data,omitempty"`
+	VerificationGasLimit string `json:"verificationGasLimit,omitempty"`
+	PostOpGasLimit       string `json:"postOpGasLimit,omitempty"`
+}
+
+// buildUserOperationRequest 构建UserOperation请求，salt区分同一所有者的多个智能账户
+type buildUserOperationRequest struct {
+	WalletID  string                    `json:"walletId" binding:"required"`
+	Salt      string                    `json:"salt,omitempty"`
+	Calls     []smartAccountCallRequest `json:"calls" binding:"required"`
+	Paymaster *paymasterRequest         `json:"paymaster,omitempty"`
+}
+
+// sendUserOperationRequest 签名并提交UserOperation请求。userOperation为构建接口的返回值，
+// 为空时按calls和paymaster重新构建
+type sendUserOperationRequest struct {
+	WalletID      string                    `json:"walletId" binding:"required"`
+	Salt          string                    `json:"salt,omitempty"`
+	UserOperation *wallet.UserOperation     `json:"userOperation,omitempty"`
+	Calls         []smartAccountCallRequest `json:"calls,omitempty"`
+	Paymaster     *paymasterRequest         `json:"paymaster,omitempty"`
+}
+
+// GetAccount 获取托管钱包的智能账户地址及部署状态
+func (h *SmartAccountHandler) GetAccount(c *gin.Context) {
+	salt, ok := parseOptionalBigInt(c.Query("salt"))
+	if !ok {
+		response.BadRequest(c, "Invalid salt format")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	account, err := h.smartAccountService.GetAccount(ctx, c.Param("walletId"), salt)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, account)
+}
+
+// ListUserOperations 获取智能账户提交过的UserOperation
+func (h *SmartAccountHandler) ListUserOperations(c *gin.Context) {
+	salt, ok := parseOptionalBigInt(c.Query("salt"))
+	if !ok {
+		response.BadRequest(c, "Invalid salt format")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	results, err := h.smartAccountService.ListUserOperations(ctx, c.Param("walletId"), salt)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"userOperations": results,
+	})
+}
+
+// BuildUserOperation 构建UserOperation并估算gas，返回未签名的UserOperation和userOpHash
+func (h *SmartAccountHandler) BuildUserOperation(c *gin.Context) {
+	var req buildUserOperationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	salt, calls, paymaster, ok := parseUserOperationParams(req.Salt, req.Calls, req.Paymaster)
+	if !ok {
+		response.BadRequest(c, "Invalid amount format")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := h.smartAccountService.BuildUserOperation(ctx, req.WalletID, salt, calls, paymaster)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// SendUserOperation 使用所有者钱包签名UserOperation并提交到bundler
+func (h *SmartAccountHandler) SendUserOperation(c *gin.Context) {
+	var req sendUserOperationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+	if req.UserOperation == nil && len(req.Calls) == 0 {
+		response.BadRequest(c, "userOperation or calls is required")
+		return
+	}
+
+	salt, calls, paymaster, ok := parseUserOperationParams(req.Salt, req.Calls, req.Paymaster)
+	if !ok {
+		response.BadRequest(c, "Invalid amount format")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	op := req.UserOperation
+	if op == nil {
+		built, err := h.smartAccountService.BuildUserOperation(ctx, req.WalletID, salt, calls, paymaster)
+		if err != nil {
+			if errors.Is(err, wallet.ErrUnsupportedChain) {
+				response.BadRequest(c, "Unsupported chain type")
+				return
+			}
+			respondError(c, err)
+			return
+		}
+		op = built.UserOperation
+	}
+
+	result, err := h.smartAccountService.SendUserOperation(ctx, req.WalletID, salt, op)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetUserOperation 获取UserOperation及执行结果
+func (h *SmartAccountHandler) GetUserOperation(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := h.smartAccountService.GetUserOperation(ctx, c.Param("userOpHash"))
+	if err != nil {
+		if errors.Is(err, wallet.ErrBundlerUnavailable) || errors.Is(err, wallet.ErrInvalidTransaction) {
+			respondError(c, err)
+			return
+		}
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// parseUserOperationParams 解析salt、调用和paymaster中的十进制数值
+func parseUserOperationParams(saltValue string, callRequests []smartAccountCallRequest, paymasterReq *paymasterRequest) (*big.Int, []wallet.SmartAccountCall, *wallet.Paymaster, bool) {
+	salt, ok := parseOptionalBigInt(saltValue)
+	if !ok {
+		return nil, nil, nil, false
+	}
+
+	calls := make([]wallet.SmartAccountCall, 0, len(callRequests))
+	for _, call := range callRequests {
+		value, ok := parseOptionalBigInt(call.Value)
+		if !ok {
+			return nil, nil, nil, false
+		}
+		calls = append(calls, wallet.SmartAccountCall{To: call.To, Value: value, Data: call.Data})
+	}
+
+	var paymaster *wallet.Paymaster
+	if paymasterReq != nil {
+		verificationGasLimit, ok1 := parseOptionalBigInt(paymasterReq.VerificationGasLimit)
+		postOpGasLimit, ok2 := parseOptionalBigInt(paymasterReq.PostOpGasLimit)
+		if !ok1 || !ok2 {
+			return nil, nil, nil, false
+		}
+		paymaster = &wallet.Paymaster{
+			Address:              paymasterReq.Address,
+			Data:                 paymasterReq.Data,
+			VerificationGasLimit: verificationGasLimit,
+			PostOpGasLimit:       postOpGasLimit,
+		}
+	}
+
+	return salt, calls, paymaster, true
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/response"
+	"multi-chain-wallet/internal/service"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// TxApprovalHandler 交易审批处理器
+type TxApprovalHandler struct {
+	txApprovalService *service.TxApprovalService
+	adminAuth         gin.HandlerFunc
+}
+
+// NewTxApprovalHandler 创建交易审批处理器，设置和删除审批规则须通过adminAuth认证
+func NewTxApprovalHandler(txApprovalService *service.TxApprovalService, adminAuth gin.HandlerFunc) *TxApprovalHandler {
+	return &TxApprovalHandler{
+		txApprovalService: txApprovalService,
+		adminAuth:         adminAuth,
+	}
+}
+
+// Register 注册路由
+func (h *TxApprovalHandler) Register(router *gin.Engine) {
+	approvalGroup := router.Group("/api/v1/tx-approvals")
+	{
+		approvalGroup.PUT("/wallets/:walletId/rule", h.adminAuth, h.SetRule)
+		approvalGroup.GET("/wallets/:walletId/rule", h.GetRule)
+		approvalGroup.DELETE("/wallets/:walletId/rule", h.adminAuth, h.DeleteRule)
+		approvalGroup.GET("/wallets/:walletId/requests", h.ListRequests)
+		approvalGroup.GET("/wallets/:walletId/audit", h.ListAudit)
+		approvalGroup.GET("/requests/:id", h.GetRequest)
+		approvalGroup.POST("/requests/:id/approve", h.Approve)
+		approvalGroup.POST("/requests/:id/reject", h.Reject)
+	}
+}
+
+// setTxApprovalRuleRequest 设置审批规则的请求，ttl为审批请求的有效期（秒）
+type setTxApprovalRuleRequest struct {
+	Approvers []string `json:"approvers" binding:"required"`
+	Threshold int      `json:"threshold" binding:"required"`
+	TTL       int64    `json:"ttl,omitempty"`
+}
+
+// txApprovalVoteRequest 审批人表态的请求
+type txApprovalVoteRequest struct {
+	ApproverToken string `json:"approverToken" binding:"required"`
+	Comment       string `json:"comment,omitempty"`
+}
+
+// SetRule 设置钱包的审批规则，已有规则时创建须经现有审批人批准的变更请求。新签发的审批令牌只在响应中返回这一次
+func (h *TxApprovalHandler) SetRule(c *gin.Context) {
+	var req setTxApprovalRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	change, err := h.txApprovalService.SetRule(c.Param("walletId"), &service.TxApprovalRuleParams{
+		Approvers: req.Approvers,
+		Threshold: req.Threshold,
+		TTL:       req.TTL,
+	})
+	if err != nil {
+		if errors.Is(err, wallet.ErrWalletNotFound) {
+			response.NotFound(c, err.Error())
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, change)
+}
+
+// GetRule 获取钱包的审批规则
+func (h *TxApprovalHandler) GetRule(c *gin.Context) {
+	rule, err := h.txApprovalService.GetRule(c.Param("walletId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if rule == nil {
+		response.NotFound(c, "Approval rule not found")
+		return
+	}
+
+	response.Success(c, rule)
+}
+
+// DeleteRule 创建删除钱包审批规则的变更请求，经现有审批人批准后生效
+func (h *TxApprovalHandler) DeleteRule(c *gin.Context) {
+	request, err := h.txApprovalService.DeleteRule(c.Param("walletId"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if request == nil {
+		response.NotFound(c, "Approval rule not found")
+		return
+	}
+
+	response.Success(c, request)
+}
+
+// ListRequests 列出钱包的审批请求，可按status过滤
+func (h *TxApprovalHandler) ListRequests(c *gin.Context) {
+	requests, err := h.txApprovalService.ListRequests(c.Param("walletId"), c.Query("status"))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"requests": requests,
+	})
+}
+
+// ListAudit 列出钱包的审批审计日志，since为Unix时间戳
+func (h *TxApprovalHandler) ListAudit(c *gin.Context) {
+	var since int64
+	if value := c.Query("since"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			response.BadRequest(c, "Invalid since")
+			return
+		}
+		since = parsed
+	}
+
+	entries, err := h.txApprovalService.ListAudit(c.Param("walletId"), since)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"audit": entries,
+	})
+}
+
+// GetRequest 获取审批请求及其表态和审计日志
+func (h *TxApprovalHandler) GetRequest(c *gin.Context) {
+	request, err := h.txApprovalService.GetRequest(c.Param("id"))
+	if err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, request)
+}
+
+// Approve 审批人批准请求
+func (h *TxApprovalHandler) Approve(c *gin.Context) {
+	h.vote(c, h.txApprovalService.Approve)
+}
+
+// Reject 审批人拒绝请求
+func (h *TxApprovalHandler) Reject(c *gin.Context) {
+	h.vote(c, h.txApprovalService.Reject)
+}
+
+func (h *TxApprovalHandler) vote(c *gin.Context, vote func(id string, token string, comment string) (*service.TxApprovalRequest, error)) {
+	var req txApprovalVoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	if _, err := h.txApprovalService.GetRequest(c.Param("id")); err != nil {
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	request, err := vote(c.Param("id"), req.ApproverToken, req.Comment)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, request)
+}
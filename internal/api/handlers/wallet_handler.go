@@ -2,10 +2,14 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/gin-gonic/gin"
 
 	"multi-chain-wallet/internal/api/response"
@@ -55,6 +59,20 @@ type importPrivateKeyRequest struct {
 	PrivateKey string `json:"privateKey" binding:"required"`
 }
 
+// importRemoteSignerRequest 注册远程签名钱包请求
+type importRemoteSignerRequest struct {
+	ChainType string `json:"chainType" binding:"required"`
+	SignerURL string `json:"signerUrl" binding:"required"` // Clef兼容签名服务的HTTP地址或IPC路径
+	Address   string `json:"address" binding:"required"`
+}
+
+// createThresholdWalletRequest 创建门限签名钱包请求
+type createThresholdWalletRequest struct {
+	ChainType string   `json:"chainType" binding:"required"`
+	Nodes     []string `json:"nodes" binding:"required"` // 签名节点地址
+	Threshold int      `json:"threshold" binding:"required"`
+}
+
 // importWalletRequest 导入钱包请求
 type importWalletRequest struct {
 	ChainType  string `json:"chainType" binding:"required"`
@@ -87,27 +105,88 @@ type tokenBalanceResponse struct {
 	Decimals     int    `json:"decimals,omitempty"`
 }
 
+// batchBalanceRequest 批量余额查询请求
+type batchBalanceRequest struct {
+	ChainType string   `json:"chainType" binding:"required"`
+	Addresses []string `json:"addresses" binding:"required,min=1,max=100"`
+	Tokens    []string `json:"tokens" binding:"max=100"`
+}
+
+// addressBalancesResponse 单个地址的余额响应，查询失败的余额为空字符串
+type addressBalancesResponse struct {
+	Address  string            `json:"address"`
+	Balance  string            `json:"balance"`
+	Currency string            `json:"currency"`
+	Tokens   map[string]string `json:"tokens"`
+}
+
 // createTransactionRequest 创建交易请求
 type createTransactionRequest struct {
-	From      string `json:"from" binding:"required"`
-	To        string `json:"to" binding:"required"`
-	Amount    string `json:"amount" binding:"required"`
-	Data      string `json:"data,omitempty"`
-	ChainType string `json:"chainType" binding:"required"`
+	From           string `json:"from" binding:"required"`
+	To             string `json:"to" binding:"required"`
+	Amount         string `json:"amount" binding:"required"`
+	Data           string `json:"data,omitempty"`
+	TokenAddress   string `json:"tokenAddress,omitempty"` // 非空时创建代币转账，amount为代币最小单位
+	ChainType      string `json:"chainType" binding:"required"`
+	RequesterToken string `json:"requesterToken,omitempty"` // 发起人的审批令牌，钱包要求审批时据此记录发起人，发起人不能审批自己的请求
+	Note           string `json:"note,omitempty"`           // 给审批人的说明
 }
 
 // signTransactionRequest 签名交易请求
 type signTransactionRequest struct {
-	WalletID  string `json:"walletId" binding:"required"`
-	Tx        string `json:"tx" binding:"required"` // JSON 字符串
-	ChainType string `json:"chainType" binding:"required"`
+	WalletID  string             `json:"walletId" binding:"required"`
+	Tx        *wallet.UnsignedTx `json:"tx" binding:"required"` // /tx/create返回的交易
+	ChainType string             `json:"chainType" binding:"required"`
 }
 
 // sendTransactionRequest 发送交易请求
 type sendTransactionRequest struct {
+	WalletID  string           `json:"walletId" binding:"required"`
+	ChainType string           `json:"chainType" binding:"required"`
+	SignedTx  *wallet.SignedTx `json:"signedTx" binding:"required"` // /tx/sign返回的交易
+}
+
+// rawTransactionRequest 原始交易广播/解码请求
+type rawTransactionRequest struct {
+	ChainType string `json:"chainType" binding:"required"`
+	RawTx     string `json:"rawTx" binding:"required"` // 0x开头的RLP编码交易
+}
+
+// signMessageRequest 消息签名请求
+type signMessageRequest struct {
 	WalletID  string `json:"walletId" binding:"required"`
 	ChainType string `json:"chainType" binding:"required"`
-	SignedTx  string `json:"signedTx" binding:"required"`
+	Message   string `json:"message" binding:"required"` // 0x开头按十六进制解析，否则按UTF-8文本
+}
+
+// signTypedDataRequest 结构化数据签名请求
+type signTypedDataRequest struct {
+	WalletID  string          `json:"walletId" binding:"required"`
+	ChainType string          `json:"chainType" binding:"required"`
+	TypedData json.RawMessage `json:"typedData" binding:"required"` // eth_signTypedData_v4格式
+}
+
+// typedDataPreview 结构化数据预览，便于调用方确认签名内容
+type typedDataPreview struct {
+	Domain      map[string]interface{} `json:"domain"`
+	PrimaryType string                 `json:"primaryType"`
+	Message     map[string]interface{} `json:"message"`
+}
+
+// signatureResponse 签名响应
+type signatureResponse struct {
+	Address   string            `json:"address"`
+	Signature string            `json:"signature"`
+	Preview   *typedDataPreview `json:"preview,omitempty"`
+}
+
+// verifySignatureRequest 签名校验请求，message和typedData二选一
+type verifySignatureRequest struct {
+	ChainType      string          `json:"chainType" binding:"required"`
+	Message        string          `json:"message,omitempty"`
+	TypedData      json.RawMessage `json:"typedData,omitempty"`
+	Signature      string          `json:"signature" binding:"required"`
+	ExpectedSigner string          `json:"expectedSigner,omitempty"`
 }
 
 // transactionResponse 交易响应
@@ -127,34 +206,27 @@ type transactionHistoryResponse struct {
 	Timestamp int64  `json:"timestamp"`
 }
 
-// getChainSymbol 获取链的代币符号
-func getChainSymbol(chainType wallet.ChainType) string {
-	switch chainType {
-	case wallet.ChainTypeETH:
-		return "ETH"
-	case wallet.ChainTypeBSC:
-		return "BNB"
-	case wallet.ChainTypePolygon:
-		return "MATIC"
-	case wallet.ChainTypeSepolia:
-		return "SEP"
-	default:
+// getChainSymbol 从链注册表获取链的原生代币符号
+func (h *WalletHandler) getChainSymbol(chainType wallet.ChainType) string {
+	chain, exists := h.walletManager.GetChainInfo(chainType)
+	if !exists {
 		return "UNKNOWN"
 	}
+	return chain.NativeSymbol
 }
 
-// isValidChainType 检查链类型是否有效
-func isValidChainType(chainType wallet.ChainType) bool {
-	fmt.Printf("Validating chain type: %s (type: %T)\n", chainType, chainType)
-	fmt.Printf("Known chain types: ETH=%s, BSC=%s, Polygon=%s, Sepolia=%s\n",
-		wallet.ChainTypeETH, wallet.ChainTypeBSC, wallet.ChainTypePolygon, wallet.ChainTypeSepolia)
+// isValidChainType 检查链类型是否在链注册表中
+func (h *WalletHandler) isValidChainType(chainType wallet.ChainType) bool {
+	_, exists := h.walletManager.GetChainInfo(chainType)
+	return exists
+}
 
-	switch chainType {
-	case wallet.ChainTypeETH, wallet.ChainTypeBSC, wallet.ChainTypePolygon, wallet.ChainTypeSepolia:
-		return true
-	default:
-		return false
+// decodeMessage 与personal_sign保持一致：0x开头的十六进制视为原始字节，否则按UTF-8文本
+func decodeMessage(message string) ([]byte, error) {
+	if strings.HasPrefix(message, "0x") {
+		return hexutil.Decode(message)
 	}
+	return []byte(message), nil
 }
 
 // CreateWallet 创建钱包
@@ -189,7 +261,7 @@ func (h *WalletHandler) CreateWallet(c *gin.Context) {
 		fmt.Printf("Handler: Chain type %s is supported by this wallet manager\n", chainType)
 	}
 
-	if !isValidChainType(chainType) {
+	if !h.isValidChainType(chainType) {
 		fmt.Printf("Invalid chain type: %s\n", chainType)
 		response.BadRequest(c, "Unsupported chain type")
 		return
@@ -201,7 +273,7 @@ func (h *WalletHandler) CreateWallet(c *gin.Context) {
 	walletID, err := h.walletService.CreateWallet(chainType)
 	if err != nil {
 		fmt.Printf("Error creating wallet: %v\n", err)
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -211,7 +283,7 @@ func (h *WalletHandler) CreateWallet(c *gin.Context) {
 	walletInfo, err := h.walletService.GetWalletInfo(walletID)
 	if err != nil {
 		fmt.Printf("Error getting wallet info: %v\n", err)
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -234,13 +306,13 @@ func (h *WalletHandler) ImportWalletFromMnemonic(c *gin.Context) {
 	chainType := wallet.ChainType(req.ChainType)
 	walletID, err := h.walletService.ImportWalletFromMnemonic(chainType, req.Mnemonic)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
 	walletInfo, err := h.walletService.GetWalletInfo(walletID)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -261,13 +333,80 @@ func (h *WalletHandler) ImportWalletFromPrivateKey(c *gin.Context) {
 	chainType := wallet.ChainType(req.ChainType)
 	walletID, err := h.walletService.ImportWalletFromPrivateKey(chainType, req.PrivateKey)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
+		return
+	}
+
+	walletInfo, err := h.walletService.GetWalletInfo(walletID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, createWalletResponse{
+		WalletID: walletID,
+		Address:  walletInfo.Address,
+	})
+}
+
+// CreateThresholdWallet 在签名节点上生成门限密钥并创建钱包
+func (h *WalletHandler) CreateThresholdWallet(c *gin.Context) {
+	var req createThresholdWalletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	// 密钥生成需要各节点生成Paillier密钥，耗时较长
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	walletID, err := h.walletService.CreateThresholdWallet(ctx, wallet.ChainType(req.ChainType), req.Nodes, req.Threshold)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	walletInfo, err := h.walletService.GetWalletInfo(walletID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, createWalletResponse{
+		WalletID: walletID,
+		Address:  walletInfo.Address,
+	})
+}
+
+// ImportRemoteSigner 注册由远程签名服务托管私钥的钱包
+func (h *WalletHandler) ImportRemoteSigner(c *gin.Context) {
+	var req importRemoteSignerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	walletID, err := h.walletService.ImportRemoteSignerWallet(ctx, wallet.ChainType(req.ChainType), req.SignerURL, req.Address)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
 		return
 	}
 
 	walletInfo, err := h.walletService.GetWalletInfo(walletID)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -305,14 +444,14 @@ func (h *WalletHandler) ImportWallet(c *gin.Context) {
 	}
 
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
 	// 获取钱包信息
 	walletInfo, err := h.walletService.GetWalletInfo(walletID)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -343,7 +482,7 @@ func (h *WalletHandler) GetWalletInfo(c *gin.Context) {
 func (h *WalletHandler) ListWallets(c *gin.Context) {
 	wallets, err := h.walletService.ListWallets()
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -371,13 +510,13 @@ func (h *WalletHandler) GetBalance(c *gin.Context) {
 
 	balance, err := h.walletService.GetBalance(ctx, chainType, address)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
 	response.Success(c, balanceResponse{
 		Balance:  balance.String(),
-		Currency: getChainSymbol(chainType),
+		Currency: h.getChainSymbol(chainType),
 	})
 }
 
@@ -392,7 +531,7 @@ func (h *WalletHandler) GetTokenBalance(c *gin.Context) {
 
 	balance, err := h.walletService.GetTokenBalance(ctx, chainType, address, tokenAddress)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -402,6 +541,78 @@ func (h *WalletHandler) GetTokenBalance(c *gin.Context) {
 	})
 }
 
+// GetBalances 批量获取原生代币和代币余额
+func (h *WalletHandler) GetBalances(c *gin.Context) {
+	var req batchBalanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	chainType := wallet.ChainType(req.ChainType)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	balances, err := h.walletService.GetBalances(ctx, chainType, req.Addresses, req.Tokens)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	result := make([]addressBalancesResponse, 0, len(balances))
+	for _, b := range balances {
+		entry := addressBalancesResponse{
+			Address:  b.Address,
+			Currency: h.getChainSymbol(chainType),
+			Tokens:   make(map[string]string, len(b.Tokens)),
+		}
+		if b.Native != nil {
+			entry.Balance = b.Native.String()
+		}
+		for token, balance := range b.Tokens {
+			if balance != nil {
+				entry.Tokens[token] = balance.String()
+			} else {
+				entry.Tokens[token] = ""
+			}
+		}
+		result = append(result, entry)
+	}
+
+	response.Success(c, result)
+}
+
+// ListUTXOs 列出地址的未花费输出（比特币）
+func (h *WalletHandler) ListUTXOs(c *gin.Context) {
+	address := c.Param("address")
+	chainType := wallet.ChainType(c.Query("chainType"))
+
+	// scantxoutset需要扫描整个UTXO集，超时时间较长
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	utxos, err := h.walletService.ListUTXOs(ctx, chainType, address)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		if errors.Is(err, wallet.ErrOperationNotSupported) {
+			response.BadRequest(c, "Chain does not use UTXOs")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, utxos)
+}
+
 // CreateTransaction 创建交易
 func (h *WalletHandler) CreateTransaction(c *gin.Context) {
 	var req createTransactionRequest
@@ -431,22 +642,48 @@ func (h *WalletHandler) CreateTransaction(c *gin.Context) {
 		return
 	}
 
-	// 创建交易，wallet接口要求data参数为[]byte
-	var data []byte
-	if req.Data != "" {
-		data = []byte(req.Data)
+	var tx *wallet.UnsignedTx
+	var err error
+	if req.TokenAddress != "" {
+		// 代币转账
+		tx, err = h.walletService.CreateTokenTransaction(ctx, chainType, req.From, req.To, req.TokenAddress, amount)
+	} else {
+		// 创建交易，wallet接口要求data参数为[]byte
+		var data []byte
+		if req.Data != "" {
+			data = []byte(req.Data)
+		}
+		tx, err = walletImpl.CreateTransaction(ctx, req.From, req.To, amount, data)
+	}
+	if err != nil {
+		respondError(c, err)
+		return
 	}
 
-	// 创建交易
-	tx, err := walletImpl.CreateTransaction(ctx, req.From, req.To, amount, data)
+	// 筛查收发地址、代币及calldata中的收款人，命中拒绝名单时不创建
+	screening, err := h.walletService.ScreenTransaction(chainType, req.From, req.To, req.TokenAddress, tx.Payload, false)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
-	response.Success(c, gin.H{
-		"tx": string(tx),
-	})
+	// 钱包要求审批时同时创建审批请求，批准后才能签名
+	approval, err := h.walletService.RequestApproval(chainType, req.From, tx, req.RequesterToken, req.Note)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	result := gin.H{
+		"tx": tx,
+	}
+	if screening != nil {
+		result["screening"] = screening
+	}
+	if approval != nil {
+		result["approval"] = approval
+	}
+	response.Success(c, result)
 }
 
 // SignTransaction 签名交易
@@ -464,22 +701,19 @@ func (h *WalletHandler) SignTransaction(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// 获取对应链的钱包实现
-	walletImpl, ok := h.walletService.GetWalletByChainType(chainType)
-	if !ok {
-		response.BadRequest(c, "Unsupported chain type")
-		return
-	}
-
-	// 签名交易
-	signedTx, err := walletImpl.SignTransaction(ctx, req.WalletID, []byte(req.Tx))
+	// 签名交易，经过钱包服务以执行支出策略
+	signedTx, err := h.walletService.SignTransaction(ctx, chainType, req.WalletID, req.Tx)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
 		return
 	}
 
 	response.Success(c, gin.H{
-		"signed_tx": string(signedTx),
+		"signed_tx": signedTx,
 	})
 }
 
@@ -498,17 +732,15 @@ func (h *WalletHandler) SendTransaction(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// 获取对应链的钱包实现
-	walletImpl, ok := h.walletService.GetWalletByChainType(chainType)
-	if !ok {
+	if _, ok := h.walletService.GetWalletByChainType(chainType); !ok {
 		response.BadRequest(c, "Unsupported chain type")
 		return
 	}
 
-	// 发送交易
-	txHash, err := walletImpl.SendTransaction(ctx, []byte(req.SignedTx))
+	// 发送交易并保存交易记录
+	txHash, err := h.walletService.SendTransaction(ctx, chainType, req.SignedTx)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -517,6 +749,194 @@ func (h *WalletHandler) SendTransaction(c *gin.Context) {
 	})
 }
 
+// BroadcastRawTransaction 广播外部签名的原始交易
+func (h *WalletHandler) BroadcastRawTransaction(c *gin.Context) {
+	var req rawTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	rawTx, err := hexutil.Decode(req.RawTx)
+	if err != nil {
+		response.BadRequest(c, "Invalid raw transaction hex")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	txHash, err := h.walletService.BroadcastRawTransaction(ctx, wallet.ChainType(req.ChainType), rawTx)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"tx_hash": txHash,
+	})
+}
+
+// DecodeRawTransaction 解码原始交易，返回发送方、nonce、费用和calldata
+func (h *WalletHandler) DecodeRawTransaction(c *gin.Context) {
+	var req rawTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	rawTx, err := hexutil.Decode(req.RawTx)
+	if err != nil {
+		response.BadRequest(c, "Invalid raw transaction hex")
+		return
+	}
+
+	decoded, err := h.walletService.DecodeRawTransaction(wallet.ChainType(req.ChainType), rawTx)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, decoded)
+}
+
+// SignMessage 签名消息（EIP-191 personal_sign）
+func (h *WalletHandler) SignMessage(c *gin.Context) {
+	var req signMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	message, err := decodeMessage(req.Message)
+	if err != nil {
+		response.BadRequest(c, "Invalid hex message")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	chainType := wallet.ChainType(req.ChainType)
+	signature, err := h.walletService.SignMessage(ctx, chainType, req.WalletID, message)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	address, _ := h.walletManager.GetAddress(req.WalletID)
+
+	response.Success(c, signatureResponse{
+		Address:   address,
+		Signature: hexutil.Encode(signature),
+	})
+}
+
+// SignTypedData 签名结构化数据（EIP-712 v4）
+func (h *WalletHandler) SignTypedData(c *gin.Context) {
+	var req signTypedDataRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	// 解析域和消息用于预览
+	var preview typedDataPreview
+	if err := json.Unmarshal(req.TypedData, &preview); err != nil {
+		response.BadRequest(c, "Invalid typed data format")
+		return
+	}
+	if preview.PrimaryType == "" || preview.Domain == nil || preview.Message == nil {
+		response.BadRequest(c, "Typed data must contain domain, primaryType and message")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	chainType := wallet.ChainType(req.ChainType)
+	signature, err := h.walletService.SignTypedData(ctx, chainType, req.WalletID, req.TypedData)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		if errors.Is(err, wallet.ErrChainIDMismatch) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	address, _ := h.walletManager.GetAddress(req.WalletID)
+
+	response.Success(c, signatureResponse{
+		Address:   address,
+		Signature: hexutil.Encode(signature),
+		Preview:   &preview,
+	})
+}
+
+// VerifySignature 校验签名并恢复签名地址，支持ERC-1271合约钱包
+func (h *WalletHandler) VerifySignature(c *gin.Context) {
+	var req verifySignatureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	if (req.Message == "") == (len(req.TypedData) == 0) {
+		response.BadRequest(c, "Exactly one of message or typedData must be provided")
+		return
+	}
+
+	signature, err := hexutil.Decode(req.Signature)
+	if err != nil {
+		response.BadRequest(c, "Invalid signature format")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	chainType := wallet.ChainType(req.ChainType)
+
+	var result *wallet.SignatureVerification
+	if req.Message != "" {
+		message, decodeErr := decodeMessage(req.Message)
+		if decodeErr != nil {
+			response.BadRequest(c, "Invalid hex message")
+			return
+		}
+		result, err = h.walletService.VerifyMessage(ctx, chainType, req.ExpectedSigner, message, signature)
+	} else {
+		result, err = h.walletService.VerifyTypedData(ctx, chainType, req.ExpectedSigner, req.TypedData, signature)
+	}
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
 // GetTransactionStatus 获取交易状态
 func (h *WalletHandler) GetTransactionStatus(c *gin.Context) {
 	var req struct {
@@ -545,7 +965,7 @@ func (h *WalletHandler) GetTransactionStatus(c *gin.Context) {
 	// 获取交易状态
 	status, err := walletImpl.GetTransactionStatus(ctx, req.TxHash)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -554,6 +974,33 @@ func (h *WalletHandler) GetTransactionStatus(c *gin.Context) {
 	})
 }
 
+// ScreenIncomingTransaction 筛查转入本服务所管理钱包的转账并保存筛查结果，发送方命中block名单时返回403，调用方据此拒绝入账
+func (h *WalletHandler) ScreenIncomingTransaction(c *gin.Context) {
+	var req struct {
+		ChainType string `json:"chainType" binding:"required"`
+		TxHash    string `json:"txHash" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request format")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	transfer, err := h.walletService.ScreenIncomingTransaction(ctx, wallet.ChainType(req.ChainType), req.TxHash)
+	if err != nil {
+		if errors.Is(err, wallet.ErrUnsupportedChain) {
+			response.BadRequest(c, "Unsupported chain type")
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response.Success(c, transfer)
+}
+
 // GetTransactionHistory 获取交易历史
 func (h *WalletHandler) GetTransactionHistory(c *gin.Context) {
 	var req struct {
@@ -570,7 +1017,7 @@ func (h *WalletHandler) GetTransactionHistory(c *gin.Context) {
 	// 获取交易历史
 	history, err := h.walletService.GetTransactionHistory(req.WalletID)
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		respondError(c, err)
 		return
 	}
 
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/response"
+)
+
+// AdminAuth 管理接口认证，请求须带Authorization: Bearer <token>。token为空时管理接口不可用
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			response.Forbidden(c, "Admin API is disabled")
+			c.Abort()
+			return
+		}
+
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			response.Unauthorized(c, "Invalid admin token")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
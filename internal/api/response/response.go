@@ -66,3 +66,12 @@ func InternalServerError(c *gin.Context, message string) {
 		Data:    nil,
 	})
 }
+
+// ServiceUnavailable 服务暂时不可用响应
+func ServiceUnavailable(c *gin.Context, message string) {
+	c.JSON(http.StatusServiceUnavailable, Response{
+		Code:    503,
+		Message: message,
+		Data:    nil,
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -27,6 +28,8 @@ type Config struct {
 		BSC      string
 		Polygon  string
 		Solana   string
+		Tron     string
+		Cosmos   string
 		Sepolia  string
 	}
 
@@ -37,6 +40,60 @@ type Config struct {
 		Password string
 		DBName   string
 	}
+
+	// 管理接口配置
+	Admin struct {
+		// 调用管理接口（如设置代付额度、支出策略、审批规则，重新加载筛查名单）的令牌，为空时管理接口不可用
+		Token string
+	}
+
+	// 支出策略配置
+	Policy struct {
+		// 放宽或删除策略的生效延迟
+		ChangeDelay string
+	}
+
+	// 地址筛查配置
+	Screening struct {
+		// 名单文件
+		Lists []ScreeningListConfig
+		// 重新加载名单的间隔
+		ReloadInterval string
+	}
+
+	// 链注册表，每个条目对应一条链
+	Chains []ChainConfig
+
+	// 开发模式：使用内存数据库和进程内模拟链，无需任何外部服务
+	DevMode bool
+}
+
+// ChainConfig 单条链的配置
+type ChainConfig struct {
+	ChainType      string   `json:"chainType"`      // 链标识，即API中的chainType
+	Kind           string   `json:"kind"`           // 实现类别，默认为evm
+	Name           string   `json:"name"`           // 展示名称
+	ChainID        uint64   `json:"chainId"`        // EVM链ID
+	RPCURLs        []string `json:"rpcUrls"`        // 支持${ENV}形式引用环境变量
+	NativeSymbol   string   `json:"nativeSymbol"`   // 原生代币符号
+	NativeDecimals int      `json:"nativeDecimals"` // 原生代币精度
+	ExplorerURL    string   `json:"explorerUrl"`    // 区块浏览器地址
+	EIP1559        bool     `json:"eip1559"`        // 是否支持EIP-1559交易
+	Network        string   `json:"network"`        // 非EVM链的网络，如比特币的testnet3，Cosmos链的chain-id
+	AddressType    string   `json:"addressType"`    // 非EVM链的默认地址类型，如比特币的p2wpkh
+	Bech32Prefix   string   `json:"bech32Prefix"`   // Cosmos链的地址前缀
+	CoinType       uint32   `json:"coinType"`       // Cosmos链的BIP44币种
+	Denom          string   `json:"denom"`          // Cosmos链的原生代币denom
+	GasPrice       float64  `json:"gasPrice"`       // Cosmos链的gas价格
+	BundlerURL     string   `json:"bundlerUrl"`     // ERC-4337 bundler地址，支持${ENV}形式引用环境变量
+	Forwarder      string   `json:"forwarder"`      // EIP-2771可信转发合约（ERC2771Forwarder）地址，支持${ENV}形式引用环境变量
+	RelayerWallet  string   `json:"relayerWallet"`  // 代付gas的中继钱包ID，支持${ENV}形式引用环境变量
+}
+
+// ScreeningListConfig 地址名单文件及命中时的处理方式
+type ScreeningListConfig struct {
+	Path   string
+	Action string // block拒绝交易，flag放行并标记
 }
 
 // LoadConfig 从.env文件加载配置
@@ -60,6 +117,9 @@ func LoadConfig(envPath string) (*Config, error) {
 	config.RPC.BSC = getEnvOrDefault("BSC_RPC_URL", "https://data-seed-prebsc-1-s1.binance.org:8545")
 	config.RPC.Polygon = getEnvOrDefault("POLYGON_RPC_URL", "https://rpc-mumbai.maticvigil.com")
 	config.RPC.Sepolia = getEnvOrDefault("SEPOLIA_RPC_URL", "https://sepolia.infura.io/v3/YOUR_KEY")
+	config.RPC.Solana = getEnvOrDefault("SOLANA_RPC_URL", "https://api.devnet.solana.com")
+	config.RPC.Tron = getEnvOrDefault("TRON_RPC_URL", "https://nile.trongrid.io")
+	config.RPC.Cosmos = getEnvOrDefault("COSMOS_REST_URL", "https://cosmos-rest.publicnode.com")
 
 	// 从环境变量加载数据库配置
 	config.Database.Host = getEnvOrDefault("DB_HOST", "localhost")
@@ -68,9 +128,210 @@ func LoadConfig(envPath string) (*Config, error) {
 	config.Database.Password = getEnvOrDefault("DB_PASSWORD", "root")
 	config.Database.DBName = getEnvOrDefault("DB_NAME", "multi_chain_wallet")
 
+	// 从环境变量加载管理接口令牌
+	config.Admin.Token = os.Getenv("ADMIN_API_TOKEN")
+
+	// 从环境变量加载支出策略配置
+	config.Policy.ChangeDelay = getEnvOrDefault("POLICY_CHANGE_DELAY", "24h")
+
+	// 从环境变量加载地址筛查配置，名单之间用逗号分隔，可加block:或flag:前缀，默认block
+	config.Screening.Lists = parseScreeningLists(os.Getenv("SCREENING_LISTS"))
+	config.Screening.ReloadInterval = getEnvOrDefault("SCREENING_RELOAD_INTERVAL", "1h")
+
+	// 开发模式只启用模拟链
+	config.DevMode = getEnvOrDefault("DEV_MODE", "false") == "true"
+	if config.DevMode {
+		config.Chains = devChains()
+		return config, nil
+	}
+
+	// 从链注册表文件加载链配置，文件不存在时使用内置默认链
+	chains, err := loadChains(getEnvOrDefault("CHAIN_REGISTRY_PATH", "config/chains.json"))
+	if err != nil {
+		return nil, err
+	}
+	if chains == nil {
+		chains = defaultChains(config)
+	}
+	config.Chains = chains
+
 	return config, nil
 }
 
+// parseScreeningLists 解析SCREENING_LISTS，如block:data/ofac_sdn.txt,flag:data/internal.csv
+func parseScreeningLists(value string) []ScreeningListConfig {
+	var lists []ScreeningListConfig
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		list := ScreeningListConfig{Path: item, Action: "block"}
+		if action, path, ok := strings.Cut(item, ":"); ok && (action == "block" || action == "flag") {
+			list.Path = path
+			list.Action = action
+		}
+		lists = append(lists, list)
+	}
+	return lists
+}
+
+// loadChains 读取链注册表文件，文件不存在时返回nil
+func loadChains(path string) ([]ChainConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("无法读取链注册表: %v", err)
+	}
+
+	var chains []ChainConfig
+	if err := json.Unmarshal(data, &chains); err != nil {
+		return nil, fmt.Errorf("链注册表格式错误: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	for i := range chains {
+		chain := &chains[i]
+		if chain.ChainType == "" {
+			return nil, fmt.Errorf("链注册表第%d项缺少chainType", i+1)
+		}
+		if seen[chain.ChainType] {
+			return nil, fmt.Errorf("链注册表中chainType重复: %s", chain.ChainType)
+		}
+		seen[chain.ChainType] = true
+
+		if chain.Kind == "" {
+			chain.Kind = "evm"
+		}
+		if chain.NativeDecimals == 0 {
+			chain.NativeDecimals = 18
+			switch chain.Kind {
+			case "bitcoin":
+				chain.NativeDecimals = 8
+			case "solana":
+				chain.NativeDecimals = 9
+			case "tron", "cosmos":
+				chain.NativeDecimals = 6
+			}
+		}
+
+		// 展开RPC地址中的环境变量，API密钥可以只放在.env里
+		rpcURLs := make([]string, 0, len(chain.RPCURLs))
+		for _, rpcURL := range chain.RPCURLs {
+			if expanded := os.ExpandEnv(rpcURL); expanded != "" {
+				rpcURLs = append(rpcURLs, expanded)
+			}
+		}
+		if len(rpcURLs) == 0 && chain.Kind != "simulated" {
+			return nil, fmt.Errorf("链%s未配置RPC地址", chain.ChainType)
+		}
+		chain.RPCURLs = rpcURLs
+		chain.BundlerURL = os.ExpandEnv(chain.BundlerURL)
+		chain.Forwarder = os.ExpandEnv(chain.Forwarder)
+		chain.RelayerWallet = os.ExpandEnv(chain.RelayerWallet)
+	}
+
+	return chains, nil
+}
+
+// defaultChains 未提供链注册表时的默认链，RPC地址来自环境变量
+func defaultChains(config *Config) []ChainConfig {
+	return []ChainConfig{
+		{
+			ChainType:      "ethereum",
+			Kind:           "evm",
+			Name:           "Ethereum",
+			ChainID:        1,
+			RPCURLs:        []string{config.RPC.Ethereum},
+			NativeSymbol:   "ETH",
+			NativeDecimals: 18,
+			ExplorerURL:    "https://etherscan.io",
+			EIP1559:        true,
+		},
+		{
+			ChainType:      "bsc",
+			Kind:           "evm",
+			Name:           "BNB Smart Chain",
+			ChainID:        56,
+			RPCURLs:        []string{config.RPC.BSC},
+			NativeSymbol:   "BNB",
+			NativeDecimals: 18,
+			ExplorerURL:    "https://bscscan.com",
+		},
+		{
+			ChainType:      "polygon",
+			Kind:           "evm",
+			Name:           "Polygon",
+			ChainID:        137,
+			RPCURLs:        []string{config.RPC.Polygon},
+			NativeSymbol:   "MATIC",
+			NativeDecimals: 18,
+			ExplorerURL:    "https://polygonscan.com",
+			EIP1559:        true,
+		},
+		{
+			ChainType:      "sepolia",
+			Kind:           "evm",
+			Name:           "Sepolia",
+			ChainID:        11155111,
+			RPCURLs:        []string{config.RPC.Sepolia},
+			NativeSymbol:   "SEP",
+			NativeDecimals: 18,
+			ExplorerURL:    "https://sepolia.etherscan.io",
+			EIP1559:        true,
+		},
+		{
+			ChainType:      "solana",
+			Kind:           "solana",
+			Name:           "Solana Devnet",
+			RPCURLs:        []string{config.RPC.Solana},
+			NativeSymbol:   "SOL",
+			NativeDecimals: 9,
+			ExplorerURL:    "https://explorer.solana.com/?cluster=devnet",
+		},
+		{
+			ChainType:      "tron",
+			Kind:           "tron",
+			Name:           "Tron Nile",
+			RPCURLs:        []string{config.RPC.Tron},
+			NativeSymbol:   "TRX",
+			NativeDecimals: 6,
+			ExplorerURL:    "https://nile.tronscan.org",
+		},
+		{
+			ChainType:      "cosmoshub",
+			Kind:           "cosmos",
+			Name:           "Cosmos Hub",
+			Network:        "cosmoshub-4",
+			RPCURLs:        []string{config.RPC.Cosmos},
+			NativeSymbol:   "ATOM",
+			NativeDecimals: 6,
+			ExplorerURL:    "https://www.mintscan.io/cosmos",
+			Bech32Prefix:   "cosmos",
+			CoinType:       118,
+			Denom:          "uatom",
+			GasPrice:       0.005,
+		},
+	}
+}
+
+// devChains 开发模式使用的模拟链，链ID固定为1337
+func devChains() []ChainConfig {
+	return []ChainConfig{
+		{
+			ChainType:      "dev",
+			Kind:           "simulated",
+			Name:           "Dev (simulated)",
+			ChainID:        1337,
+			NativeSymbol:   "ETH",
+			NativeDecimals: 18,
+			EIP1559:        true,
+		},
+	}
+}
+
 // SaveConfig 保存配置到文件
 func SaveConfig(config *Config, configPath string) error {
 	configJSON, err := json.MarshalIndent(config, "", "  ")
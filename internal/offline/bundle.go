@@ -0,0 +1,134 @@
+package offline
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// BundleVersion 离线签名包格式版本
+const BundleVersion = 1
+
+// UR类型，离线签名包和签名结果均以JSON形式放在bytes类型中传输
+const URType = "bytes"
+
+// Bundle 离线签名包，由服务端生成后导出到离线设备签名。
+// Intent为服务端解码的交易意图，离线设备签名前据此展示并校验Payload
+type Bundle struct {
+	Version   int                `json:"version"`
+	ID        string             `json:"id"`
+	ChainType wallet.ChainType   `json:"chainType"`
+	ChainID   *big.Int           `json:"chainId"`
+	Tx        *wallet.UnsignedTx `json:"tx"`
+	Intent    *wallet.DecodedTx  `json:"intent"`
+	CreatedAt int64              `json:"createdAt"`
+}
+
+// SignedBundle 离线设备返回的签名结果
+type SignedBundle struct {
+	BundleID  string           `json:"bundleId"`
+	ChainType wallet.ChainType `json:"chainType"`
+	RawTx     hexutil.Bytes    `json:"rawTx"` // 已签名的原始交易
+}
+
+// NewBundle 创建离线签名包，intent的发送方取自待签名交易
+func NewBundle(id string, chainID *big.Int, tx *wallet.UnsignedTx, intent *wallet.DecodedTx) *Bundle {
+	intent.From = tx.From
+	// 未签名交易的哈希与广播后的哈希不同，不放入意图避免误用
+	intent.Hash = ""
+	intent.Signed = false
+	return &Bundle{
+		Version:   BundleVersion,
+		ID:        id,
+		ChainType: tx.ChainType,
+		ChainID:   chainID,
+		Tx:        tx,
+		Intent:    intent,
+		CreatedAt: time.Now().Unix(),
+	}
+}
+
+// Validate 校验签名包的格式版本和必需字段
+func (b *Bundle) Validate() error {
+	if b.Version != BundleVersion {
+		return fmt.Errorf("unsupported bundle version %d", b.Version)
+	}
+	if b.ID == "" || b.ChainID == nil || b.Intent == nil {
+		return fmt.Errorf("%w: incomplete offline bundle", wallet.ErrInvalidTransaction)
+	}
+	return b.Tx.CheckChain(b.ChainType)
+}
+
+// CheckIntent 校验解码后的交易与签名包的意图一致，signed为true时要求交易已签名且签名者为意图中的发送方
+func CheckIntent(intent *wallet.DecodedTx, decoded *wallet.DecodedTx, signed bool) error {
+	if signed {
+		if !decoded.Signed {
+			return fmt.Errorf("%w: transaction is not signed", wallet.ErrInvalidTransaction)
+		}
+		if !strings.EqualFold(decoded.From, intent.From) {
+			return mismatch("signer", intent.From, decoded.From)
+		}
+	}
+
+	if decoded.Type != intent.Type {
+		return mismatch("type", intent.Type, decoded.Type)
+	}
+	if !equalBig(decoded.ChainID, intent.ChainID) {
+		// 未签名的legacy交易没有链ID，签名后按EIP-155带上链ID
+		if intent.ChainID != nil || !signed {
+			return mismatch("chain id", intent.ChainID, decoded.ChainID)
+		}
+	}
+	if !strings.EqualFold(decoded.To, intent.To) {
+		return mismatch("recipient", intent.To, decoded.To)
+	}
+	if decoded.Nonce != intent.Nonce {
+		return mismatch("nonce", intent.Nonce, decoded.Nonce)
+	}
+	if !equalBig(decoded.Value, intent.Value) {
+		return mismatch("value", intent.Value, decoded.Value)
+	}
+	if !strings.EqualFold(decoded.Data, intent.Data) {
+		return mismatch("data", intent.Data, decoded.Data)
+	}
+	return checkFee(intent.Fee, decoded.Fee)
+}
+
+// checkFee 校验gas上限和gas价格与意图一致
+func checkFee(expected, actual *wallet.TxFee) error {
+	if expected == nil || actual == nil {
+		if expected != actual {
+			return fmt.Errorf("%w: fee does not match prepared intent", wallet.ErrInvalidTransaction)
+		}
+		return nil
+	}
+	if actual.GasLimit != expected.GasLimit {
+		return mismatch("gas limit", expected.GasLimit, actual.GasLimit)
+	}
+	if !equalBig(actual.GasPrice, expected.GasPrice) {
+		return mismatch("gas price", expected.GasPrice, actual.GasPrice)
+	}
+	if !equalBig(actual.GasTipCap, expected.GasTipCap) {
+		return mismatch("gas tip cap", expected.GasTipCap, actual.GasTipCap)
+	}
+	if !equalBig(actual.GasFeeCap, expected.GasFeeCap) {
+		return mismatch("gas fee cap", expected.GasFeeCap, actual.GasFeeCap)
+	}
+	return nil
+}
+
+func mismatch(field string, expected, actual interface{}) error {
+	return fmt.Errorf("%w: %s %v does not match prepared intent %v", wallet.ErrInvalidTransaction, field, actual, expected)
+}
+
+func equalBig(a, b *big.Int) bool {
+	if a == nil || b == nil {
+		return a == b || (a == nil && b.Sign() == 0) || (b == nil && a.Sign() == 0)
+	}
+	return a.Cmp(b) == 0
+}
@@ -0,0 +1,289 @@
+package offline
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"strings"
+)
+
+// 单帧二维码承载的最大分片字节数，过大时二维码密度过高难以扫描
+const DefaultMaxFragmentLen = 200
+
+// bytewords 标准词表（BCR-2020-012），最小编码取每个词的首尾字母
+var bytewords = strings.Fields(`
+able acid also apex aqua arch atom aunt away axis back bald barn belt beta bias
+blue body brag brew bulb buzz calm cash cats chef city claw code cola cook cost
+crux curl cusp cyan dark data days deli dice diet door down draw drop drum dull
+duty each easy echo edge epic even exam exit eyes fact fair fern figs film fish
+fizz flap flew flux foxy free frog fuel fund gala game gear gems gift girl glow
+good gray grim guru gush gyro half hang hard hawk heat help high hill holy hope
+horn huts iced idea idle inch inky into iris iron item jade jazz join jolt jowl
+judo jugs jump junk jury keep keno kept keys kick kiln king kite kiwi knob lamb
+lava lazy leaf legs liar limp lion list logo loud love luau luck lung main many
+math maze memo menu meow mild mint miss monk nail navy need news next noon note
+numb obey oboe omit onyx open oval owls paid part peck play plus poem pool pose
+puff puma purr quad quiz race ramp real redo rich road rock roof ruby ruin runs
+rust safe saga scar sets silk skew slot soap solo song stub surf swan taco task
+taxi tent tied time tiny toil tomb toys trip tuna twin ugly undo unit urge user
+vast very veto vial vibe view visa void vows wall wand warm wasp wave waxy webs
+what when whiz wolf work yank yawn yell yoga yurt zaps zero zest zinc zone zoom`)
+
+// minimalBytewords 最小编码到字节值的映射
+var minimalBytewords = func() map[string]byte {
+	m := make(map[string]byte, len(bytewords))
+	for i, word := range bytewords {
+		m[word[:1]+word[3:]] = byte(i)
+	}
+	return m
+}()
+
+// EncodeUR 将数据编码为UR（类型bytes），超过maxFragmentLen时拆分为多帧用于动态二维码，
+// 多帧按序号循环播放，接收方收齐全部分片后即可还原
+func EncodeUR(data []byte, maxFragmentLen int) []string {
+	if maxFragmentLen <= 0 {
+		maxFragmentLen = DefaultMaxFragmentLen
+	}
+
+	message := cborBytes(data)
+	if len(message) <= maxFragmentLen {
+		return []string{"ur:" + URType + "/" + encodeBytewords(message)}
+	}
+
+	seqLen := (len(message) + maxFragmentLen - 1) / maxFragmentLen
+	fragmentLen := (len(message) + seqLen - 1) / seqLen
+	checksum := crc32.ChecksumIEEE(message)
+
+	parts := make([]string, 0, seqLen)
+	for i := 0; i < seqLen; i++ {
+		// 最后一个分片补零到统一长度，解码时按messageLen截断
+		fragment := make([]byte, fragmentLen)
+		start := i * fragmentLen
+		end := start + fragmentLen
+		if end > len(message) {
+			end = len(message)
+		}
+		copy(fragment, message[start:end])
+
+		part := cborHeader(4, 5)
+		part = append(part, cborHeader(0, uint64(i+1))...)
+		part = append(part, cborHeader(0, uint64(seqLen))...)
+		part = append(part, cborHeader(0, uint64(len(message)))...)
+		part = append(part, cborHeader(0, uint64(checksum))...)
+		part = append(part, cborBytes(fragment)...)
+
+		parts = append(parts, fmt.Sprintf("ur:%s/%d-%d/%s", URType, i+1, seqLen, encodeBytewords(part)))
+	}
+	return parts
+}
+
+// DecodeUR 从单帧或多帧UR还原数据，多帧时parts顺序任意，但必须恰好包含每个分片一次。
+// 分片头中的分片数和消息长度来自未认证的输入，按实际收到的分片校验后才分配内存
+func DecodeUR(parts []string) ([]byte, error) {
+	if len(parts) == 0 {
+		return nil, errors.New("no ur parts")
+	}
+
+	var (
+		fragments   map[int][]byte
+		seqLen      int
+		messageLen  int
+		checksum    uint32
+		fragmentLen int
+	)
+	for _, part := range parts {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if !strings.HasPrefix(part, "ur:") {
+			return nil, fmt.Errorf("invalid ur: %q", part)
+		}
+		components := strings.Split(part[len("ur:"):], "/")
+		if components[0] != URType {
+			return nil, fmt.Errorf("unsupported ur type %q", components[0])
+		}
+
+		switch len(components) {
+		case 2:
+			if len(parts) != 1 {
+				return nil, errors.New("single-part ur mixed with other parts")
+			}
+			message, err := decodeBytewords(components[1])
+			if err != nil {
+				return nil, err
+			}
+			return decodeCBORBytes(message)
+		case 3:
+			body, err := decodeBytewords(components[2])
+			if err != nil {
+				return nil, err
+			}
+			seqNum, n, length, sum, fragment, err := decodePart(body)
+			if err != nil {
+				return nil, err
+			}
+			if fragments == nil {
+				// 分片数不能超过收到的帧数，消息长度必须落在最后一个分片内
+				if n < 1 || n > len(parts) {
+					return nil, fmt.Errorf("ur declares %d parts, got %d", n, len(parts))
+				}
+				if len(fragment) == 0 || length > n*len(fragment) || length <= (n-1)*len(fragment) {
+					return nil, fmt.Errorf("ur message length %d does not match %d parts of %d bytes", length, n, len(fragment))
+				}
+				fragments = make(map[int][]byte, n)
+				seqLen, messageLen, checksum, fragmentLen = n, length, sum, len(fragment)
+			} else if n != seqLen || length != messageLen || sum != checksum || len(fragment) != fragmentLen {
+				return nil, errors.New("ur parts belong to different messages")
+			}
+			if seqNum < 1 || seqNum > seqLen {
+				return nil, fmt.Errorf("ur part %d out of range", seqNum)
+			}
+			if _, ok := fragments[seqNum]; ok {
+				return nil, fmt.Errorf("duplicate ur part %d", seqNum)
+			}
+			fragments[seqNum] = fragment
+		default:
+			return nil, fmt.Errorf("invalid ur: %q", part)
+		}
+	}
+
+	message := make([]byte, 0, seqLen*fragmentLen)
+	for i := 1; i <= seqLen; i++ {
+		fragment, ok := fragments[i]
+		if !ok {
+			return nil, fmt.Errorf("missing ur part %d of %d", i, seqLen)
+		}
+		message = append(message, fragment...)
+	}
+	message = message[:messageLen]
+	if crc32.ChecksumIEEE(message) != checksum {
+		return nil, errors.New("ur message checksum mismatch")
+	}
+	return decodeCBORBytes(message)
+}
+
+// decodePart 解析多帧UR的分片：[seqNum, seqLen, messageLen, checksum, fragment]
+func decodePart(body []byte) (seqNum, seqLen, messageLen int, checksum uint32, fragment []byte, err error) {
+	major, n, rest, err := readCBORHeader(body)
+	if err != nil || major != 4 || n != 5 {
+		return 0, 0, 0, 0, nil, errors.New("invalid ur part")
+	}
+
+	var values [4]uint64
+	for i := range values {
+		if major, values[i], rest, err = readCBORHeader(rest); err != nil || major != 0 {
+			return 0, 0, 0, 0, nil, errors.New("invalid ur part")
+		}
+	}
+	// 序号、分片数和长度转为int前限制范围，避免溢出为负数
+	for _, v := range values[:3] {
+		if v > math.MaxInt32 {
+			return 0, 0, 0, 0, nil, errors.New("invalid ur part")
+		}
+	}
+	if values[3] > math.MaxUint32 {
+		return 0, 0, 0, 0, nil, errors.New("invalid ur part")
+	}
+	if fragment, err = decodeCBORBytes(rest); err != nil {
+		return 0, 0, 0, 0, nil, err
+	}
+	return int(values[0]), int(values[1]), int(values[2]), uint32(values[3]), fragment, nil
+}
+
+// encodeBytewords 最小编码，末尾附加大端CRC32校验
+func encodeBytewords(data []byte) string {
+	data = binary.BigEndian.AppendUint32(append([]byte(nil), data...), crc32.ChecksumIEEE(data))
+
+	var sb strings.Builder
+	sb.Grow(len(data) * 2)
+	for _, b := range data {
+		word := bytewords[b]
+		sb.WriteByte(word[0])
+		sb.WriteByte(word[3])
+	}
+	return sb.String()
+}
+
+func decodeBytewords(s string) ([]byte, error) {
+	if len(s)%2 != 0 || len(s) < 10 {
+		return nil, errors.New("invalid bytewords length")
+	}
+
+	data := make([]byte, len(s)/2)
+	for i := range data {
+		b, ok := minimalBytewords[s[2*i:2*i+2]]
+		if !ok {
+			return nil, fmt.Errorf("invalid byteword %q", s[2*i:2*i+2])
+		}
+		data[i] = b
+	}
+
+	body, sum := data[:len(data)-4], data[len(data)-4:]
+	if binary.BigEndian.Uint32(sum) != crc32.ChecksumIEEE(body) {
+		return nil, errors.New("bytewords checksum mismatch")
+	}
+	return body, nil
+}
+
+// cborHeader 编码CBOR的类型和长度头
+func cborHeader(major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return []byte{major | byte(n)}
+	case n <= 0xff:
+		return []byte{major | 24, byte(n)}
+	case n <= 0xffff:
+		return binary.BigEndian.AppendUint16([]byte{major | 25}, uint16(n))
+	case n <= 0xffffffff:
+		return binary.BigEndian.AppendUint32([]byte{major | 26}, uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64([]byte{major | 27}, n)
+	}
+}
+
+func cborBytes(data []byte) []byte {
+	return append(cborHeader(2, uint64(len(data))), data...)
+}
+
+func readCBORHeader(data []byte) (major byte, n uint64, rest []byte, err error) {
+	if len(data) == 0 {
+		return 0, 0, nil, errors.New("unexpected end of cbor data")
+	}
+	major, info := data[0]>>5, data[0]&0x1f
+	data = data[1:]
+
+	var size int
+	switch {
+	case info < 24:
+		return major, uint64(info), data, nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, 0, nil, errors.New("unsupported cbor encoding")
+	}
+	if len(data) < size {
+		return 0, 0, nil, errors.New("unexpected end of cbor data")
+	}
+	for _, b := range data[:size] {
+		n = n<<8 | uint64(b)
+	}
+	return major, n, data[size:], nil
+}
+
+func decodeCBORBytes(data []byte) ([]byte, error) {
+	major, n, rest, err := readCBORHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if major != 2 || uint64(len(rest)) != n {
+		return nil, errors.New("ur payload is not a cbor byte string")
+	}
+	return rest, nil
+}
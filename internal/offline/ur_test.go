@@ -0,0 +1,104 @@
+package offline
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"strings"
+	"testing"
+)
+
+func randomBytes(t *testing.T, n int) []byte {
+	data := make([]byte, n)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestURRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int
+		wantParts int
+	}{
+		{name: "single part", size: 100, wantParts: 1},
+		{name: "multi part", size: 1000, wantParts: 6},
+		{name: "padded last fragment", size: 401, wantParts: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := randomBytes(t, tt.size)
+			parts := EncodeUR(data, DefaultMaxFragmentLen)
+			if len(parts) != tt.wantParts {
+				t.Fatalf("expected %d parts, got %d", tt.wantParts, len(parts))
+			}
+
+			// 扫码顺序任意，大小写不影响
+			reversed := make([]string, len(parts))
+			for i, part := range parts {
+				reversed[len(parts)-1-i] = strings.ToUpper(part)
+			}
+			for _, frames := range [][]string{parts, reversed} {
+				decoded, err := DecodeUR(frames)
+				if err != nil {
+					t.Fatalf("DecodeUR: %v", err)
+				}
+				if !bytes.Equal(decoded, data) {
+					t.Fatal("decoded data does not match")
+				}
+			}
+		})
+	}
+}
+
+// forgedPart 用任意分片头构造一帧
+func forgedPart(seqNum, seqLen, messageLen uint64, checksum uint32, fragment []byte) string {
+	part := cborHeader(4, 5)
+	part = append(part, cborHeader(0, seqNum)...)
+	part = append(part, cborHeader(0, seqLen)...)
+	part = append(part, cborHeader(0, messageLen)...)
+	part = append(part, cborHeader(0, uint64(checksum))...)
+	part = append(part, cborBytes(fragment)...)
+	return fmt.Sprintf("ur:%s/%d-%d/%s", URType, seqNum, seqLen, encodeBytewords(part))
+}
+
+func TestDecodeURRejectsMalformedInput(t *testing.T) {
+	parts := EncodeUR(randomBytes(t, 1000), DefaultMaxFragmentLen)
+	other := EncodeUR(randomBytes(t, 1000), DefaultMaxFragmentLen)
+	fragment := make([]byte, 10)
+	sum := crc32.ChecksumIEEE(fragment)
+	corrupted := parts[0][:len(parts[0])-2] + "ae"
+	if corrupted == parts[0] {
+		corrupted = parts[0][:len(parts[0])-2] + "ad"
+	}
+
+	tests := []struct {
+		name  string
+		parts []string
+	}{
+		{name: "no parts"},
+		{name: "missing part", parts: parts[1:]},
+		{name: "duplicate part", parts: append([]string{parts[0]}, parts[:len(parts)-1]...)},
+		{name: "parts from different messages", parts: append(append([]string{}, parts[:3]...), other[3:]...)},
+		{name: "single part mixed with multi part", parts: append([]string{EncodeUR([]byte("x"), 0)[0]}, parts...)},
+		{name: "huge message length", parts: []string{forgedPart(1, 1, math.MaxInt32, sum, fragment)}},
+		{name: "message length overflows int", parts: []string{forgedPart(1, 1, math.MaxUint64, sum, fragment)}},
+		{name: "huge part count", parts: []string{forgedPart(1, math.MaxInt32, 10, sum, fragment)}},
+		{name: "message shorter than parts", parts: []string{forgedPart(1, 2, 5, sum, fragment), forgedPart(2, 2, 5, sum, fragment)}},
+		{name: "empty fragment", parts: []string{forgedPart(1, 1, 0, 0, nil)}},
+		{name: "part out of range", parts: []string{forgedPart(2, 1, 10, sum, fragment)}},
+		{name: "message checksum mismatch", parts: []string{forgedPart(1, 1, 10, sum+1, fragment)}},
+		{name: "bytewords checksum mismatch", parts: []string{corrupted}},
+		{name: "wrong type", parts: []string{strings.Replace(parts[0], "ur:"+URType, "ur:crypto-psbt", 1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeUR(tt.parts); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/handlers"
+	"multi-chain-wallet/internal/service"
+)
+
+// ApprovalRoutes 代币授权路由
+type ApprovalRoutes struct {
+	approvalHandler *handlers.ApprovalHandler
+}
+
+// NewApprovalRoutes 创建代币授权路由
+func NewApprovalRoutes(approvalService *service.ApprovalService) *ApprovalRoutes {
+	return &ApprovalRoutes{
+		approvalHandler: handlers.NewApprovalHandler(approvalService),
+	}
+}
+
+// Register 注册路由
+func (r *ApprovalRoutes) Register(router *gin.Engine) {
+	r.approvalHandler.Register(router)
+}
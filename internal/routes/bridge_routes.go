@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/handlers"
+	"multi-chain-wallet/internal/service"
+)
+
+// BridgeRoutes 跨链路由
+type BridgeRoutes struct {
+	bridgeHandler *handlers.BridgeHandler
+}
+
+// NewBridgeRoutes 创建跨链路由
+func NewBridgeRoutes(bridgeService *service.BridgeService) *BridgeRoutes {
+	return &BridgeRoutes{
+		bridgeHandler: handlers.NewBridgeHandler(bridgeService),
+	}
+}
+
+// Register 注册路由
+func (r *BridgeRoutes) Register(router *gin.Engine) {
+	bridgeGroup := router.Group("/api/v1/bridge")
+	{
+		bridgeGroup.POST("/transfer", r.bridgeHandler.CrossChainTransfer)
+		bridgeGroup.GET("/status/:hash", r.bridgeHandler.GetBridgeTransactionStatus)
+		bridgeGroup.GET("/history", r.bridgeHandler.GetBridgeTransactionHistory)
+	}
+}
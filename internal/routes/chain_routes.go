@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/handlers"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// ChainRoutes 链注册表路由
+type ChainRoutes struct {
+	chainHandler *handlers.ChainHandler
+}
+
+// NewChainRoutes 创建链注册表路由
+func NewChainRoutes(walletManager *wallet.Manager) *ChainRoutes {
+	return &ChainRoutes{
+		chainHandler: handlers.NewChainHandler(walletManager),
+	}
+}
+
+// Register 注册路由
+func (r *ChainRoutes) Register(router *gin.Engine) {
+	r.chainHandler.Register(router)
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/handlers"
+	"multi-chain-wallet/internal/service"
+)
+
+// OfflineRoutes 离线签名路由
+type OfflineRoutes struct {
+	offlineHandler *handlers.OfflineHandler
+}
+
+// NewOfflineRoutes 创建离线签名路由
+func NewOfflineRoutes(offlineService *service.OfflineService) *OfflineRoutes {
+	return &OfflineRoutes{
+		offlineHandler: handlers.NewOfflineHandler(offlineService),
+	}
+}
+
+// Register 注册路由
+func (r *OfflineRoutes) Register(router *gin.Engine) {
+	r.offlineHandler.Register(router)
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/handlers"
+	"multi-chain-wallet/internal/service"
+)
+
+// PolicyRoutes 钱包支出策略路由
+type PolicyRoutes struct {
+	policyHandler *handlers.PolicyHandler
+}
+
+// NewPolicyRoutes 创建钱包支出策略路由
+func NewPolicyRoutes(policyService *service.PolicyService, adminAuth gin.HandlerFunc) *PolicyRoutes {
+	return &PolicyRoutes{
+		policyHandler: handlers.NewPolicyHandler(policyService, adminAuth),
+	}
+}
+
+// Register 注册路由
+func (r *PolicyRoutes) Register(router *gin.Engine) {
+	r.policyHandler.Register(router)
+}
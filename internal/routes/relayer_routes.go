@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/handlers"
+	"multi-chain-wallet/internal/service"
+)
+
+// RelayerRoutes 元交易中继路由
+type RelayerRoutes struct {
+	relayerHandler *handlers.RelayerHandler
+}
+
+// NewRelayerRoutes 创建元交易中继路由，adminAuth为管理接口的认证中间件
+func NewRelayerRoutes(relayerService *service.RelayerService, adminAuth gin.HandlerFunc) *RelayerRoutes {
+	return &RelayerRoutes{
+		relayerHandler: handlers.NewRelayerHandler(relayerService, adminAuth),
+	}
+}
+
+// Register 注册路由
+func (r *RelayerRoutes) Register(router *gin.Engine) {
+	r.relayerHandler.Register(router)
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/handlers"
+	"multi-chain-wallet/internal/service"
+)
+
+// SafeRoutes Safe多签路由
+type SafeRoutes struct {
+	safeHandler *handlers.SafeHandler
+}
+
+// NewSafeRoutes 创建Safe多签路由
+func NewSafeRoutes(safeService *service.SafeService) *SafeRoutes {
+	return &SafeRoutes{
+		safeHandler: handlers.NewSafeHandler(safeService),
+	}
+}
+
+// Register 注册路由
+func (r *SafeRoutes) Register(router *gin.Engine) {
+	r.safeHandler.Register(router)
+}
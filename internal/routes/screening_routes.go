@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/handlers"
+	"multi-chain-wallet/internal/service"
+)
+
+// ScreeningRoutes 地址筛查路由
+type ScreeningRoutes struct {
+	screeningHandler *handlers.ScreeningHandler
+}
+
+// NewScreeningRoutes 创建地址筛查路由
+func NewScreeningRoutes(screeningService *service.ScreeningService, adminAuth gin.HandlerFunc) *ScreeningRoutes {
+	return &ScreeningRoutes{
+		screeningHandler: handlers.NewScreeningHandler(screeningService, adminAuth),
+	}
+}
+
+// Register 注册路由
+func (r *ScreeningRoutes) Register(router *gin.Engine) {
+	r.screeningHandler.Register(router)
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/handlers"
+	"multi-chain-wallet/internal/service"
+)
+
+// SessionRoutes 会话密钥路由
+type SessionRoutes struct {
+	sessionHandler *handlers.SessionHandler
+}
+
+// NewSessionRoutes 创建会话密钥路由
+func NewSessionRoutes(sessionService *service.SessionService) *SessionRoutes {
+	return &SessionRoutes{
+		sessionHandler: handlers.NewSessionHandler(sessionService),
+	}
+}
+
+// Register 注册路由
+func (r *SessionRoutes) Register(router *gin.Engine) {
+	r.sessionHandler.Register(router)
+}
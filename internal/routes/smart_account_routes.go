@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/handlers"
+	"multi-chain-wallet/internal/service"
+)
+
+// SmartAccountRoutes 智能账户路由
+type SmartAccountRoutes struct {
+	smartAccountHandler *handlers.SmartAccountHandler
+}
+
+// NewSmartAccountRoutes 创建智能账户路由
+func NewSmartAccountRoutes(smartAccountService *service.SmartAccountService) *SmartAccountRoutes {
+	return &SmartAccountRoutes{
+		smartAccountHandler: handlers.NewSmartAccountHandler(smartAccountService),
+	}
+}
+
+// Register 注册路由
+func (r *SmartAccountRoutes) Register(router *gin.Engine) {
+	r.smartAccountHandler.Register(router)
+}
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"multi-chain-wallet/internal/api/handlers"
+	"multi-chain-wallet/internal/service"
+)
+
+// TxApprovalRoutes 交易审批路由
+type TxApprovalRoutes struct {
+	txApprovalHandler *handlers.TxApprovalHandler
+}
+
+// NewTxApprovalRoutes 创建交易审批路由
+func NewTxApprovalRoutes(txApprovalService *service.TxApprovalService, adminAuth gin.HandlerFunc) *TxApprovalRoutes {
+	return &TxApprovalRoutes{
+		txApprovalHandler: handlers.NewTxApprovalHandler(txApprovalService, adminAuth),
+	}
+}
+
+// Register 注册路由
+func (r *TxApprovalRoutes) Register(router *gin.Engine) {
+	r.txApprovalHandler.Register(router)
+}
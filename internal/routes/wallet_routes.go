@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"multi-chain-wallet/internal/api/handlers"
+	"multi-chain-wallet/internal/api/middleware"
 	"multi-chain-wallet/internal/service"
 	"multi-chain-wallet/internal/wallet"
 )
@@ -36,21 +37,38 @@ func (r *WalletRoutes) Register(router *gin.Engine) {
 	{
 		// 钱包管理
 		walletGroup.POST("/create", r.walletHandler.CreateWallet)
+		walletGroup.POST("/create/threshold", r.walletHandler.CreateThresholdWallet)
 		walletGroup.POST("/import", r.walletHandler.ImportWallet)
 		walletGroup.POST("/import/mnemonic", r.walletHandler.ImportWalletFromMnemonic)
 		walletGroup.POST("/import/privatekey", r.walletHandler.ImportWalletFromPrivateKey)
+		walletGroup.POST("/import/remote", r.walletHandler.ImportRemoteSigner)
 		walletGroup.GET("/info/:id", r.walletHandler.GetWalletInfo)
 		walletGroup.GET("/list", r.walletHandler.ListWallets)
 
 		// 余额查询
 		walletGroup.GET("/balance/:address", r.walletHandler.GetBalance)
 		walletGroup.GET("/token/:address/:tokenAddress", r.walletHandler.GetTokenBalance)
+		walletGroup.POST("/balances", r.walletHandler.GetBalances)
+		walletGroup.GET("/utxos/:address", r.walletHandler.ListUTXOs)
 
 		// 交易管理
 		walletGroup.POST("/tx/create", r.walletHandler.CreateTransaction)
 		walletGroup.POST("/tx/sign", r.walletHandler.SignTransaction)
 		walletGroup.POST("/tx/send", r.walletHandler.SendTransaction)
+		walletGroup.POST("/tx/broadcast", r.walletHandler.BroadcastRawTransaction)
+		walletGroup.POST("/tx/decode", r.walletHandler.DecodeRawTransaction)
 		walletGroup.POST("/tx/status", r.walletHandler.GetTransactionStatus)
 		walletGroup.POST("/tx/history", r.walletHandler.GetTransactionHistory)
+		walletGroup.POST("/tx/incoming/screen", r.walletHandler.ScreenIncomingTransaction)
+
+		// 签名校验
+		walletGroup.POST("/verify", r.walletHandler.VerifySignature)
+
+		// 消息签名，需要认证
+		signGroup := walletGroup.Group("/sign", middleware.Auth())
+		{
+			signGroup.POST("/message", r.walletHandler.SignMessage)
+			signGroup.POST("/typed-data", r.walletHandler.SignTypedData)
+		}
 	}
 }
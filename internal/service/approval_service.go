@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"multi-chain-wallet/internal/storage"
+	"multi-chain-wallet/internal/wallet"
+	"multi-chain-wallet/internal/wallet/ethereum"
+)
+
+// 授权风险标记
+const (
+	RiskUnlimited      = "unlimited"       // 无限额授权
+	RiskEOASpender     = "eoa_spender"     // 被授权方是普通账户，常见于钓鱼授权
+	RiskUnknownSpender = "unknown_spender" // 被授权方不在已知合约列表中
+)
+
+const (
+	// 首次扫描时回溯的区块数
+	approvalScanLookback uint64 = 200000
+	// 单次eth_getLogs查询的区块跨度，避免超出RPC节点限制
+	approvalScanChunk uint64 = 5000
+)
+
+// 视为无限额授权的阈值（2^255），兼容部分代币把MaxUint256授权减扣后的情况
+var unlimitedAllowanceThreshold = new(big.Int).Lsh(big.NewInt(1), 255)
+
+// ApprovalService 代币授权扫描与撤销服务
+type ApprovalService struct {
+	walletService    *WalletService
+	allowanceStorage *storage.MySQLAllowanceStorage
+	stopChan         chan struct{}
+}
+
+// NewApprovalService 创建授权服务
+func NewApprovalService(walletService *WalletService, allowanceStorage *storage.MySQLAllowanceStorage) *ApprovalService {
+	return &ApprovalService{
+		walletService:    walletService,
+		allowanceStorage: allowanceStorage,
+		stopChan:         make(chan struct{}),
+	}
+}
+
+// AllowanceInfo 授权信息
+type AllowanceInfo struct {
+	ChainType   string   `json:"chainType"`
+	Owner       string   `json:"owner"`
+	Token       string   `json:"token"`
+	Spender     string   `json:"spender"`
+	Allowance   string   `json:"allowance"`
+	RiskFlags   []string `json:"riskFlags"`
+	BlockNumber uint64   `json:"blockNumber"`
+	TxHash      string   `json:"txHash"`
+}
+
+// Start 启动定时扫描任务，启动时先扫描一次
+func (s *ApprovalService) Start() {
+	go func() {
+		s.scanAllWallets()
+
+		ticker := time.NewTicker(10 * time.Minute)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stopChan:
+				return
+			case <-ticker.C:
+				s.scanAllWallets()
+			}
+		}
+	}()
+
+	log.Println("Approval scan job started")
+}
+
+// Stop 停止定时扫描任务
+func (s *ApprovalService) Stop() {
+	close(s.stopChan)
+	log.Println("Approval scan job stopped")
+}
+
+// scanAllWallets 扫描所有钱包
+func (s *ApprovalService) scanAllWallets() {
+	wallets, err := s.walletService.ListWallets()
+	if err != nil {
+		log.Printf("Failed to list wallets for approval scan: %v", err)
+		return
+	}
+
+	for _, w := range wallets {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		if err := s.ScanWallet(ctx, w.ID); err != nil {
+			log.Printf("Failed to scan approvals for wallet %s: %v", w.ID, err)
+		}
+		cancel()
+	}
+}
+
+// getScanner 获取支持授权扫描的钱包实现
+func (s *ApprovalService) getScanner(chainType wallet.ChainType) (wallet.ApprovalScanner, bool) {
+	walletImpl, ok := s.walletService.GetWalletByChainType(chainType)
+	if !ok {
+		return nil, false
+	}
+	scanner, ok := walletImpl.(wallet.ApprovalScanner)
+	return scanner, ok
+}
+
+// ScanWallet 从Approval事件重建钱包当前的授权
+func (s *ApprovalService) ScanWallet(ctx context.Context, walletID string) error {
+	walletInfo, err := s.walletService.GetWalletInfo(walletID)
+	if err != nil {
+		return err
+	}
+
+	scanner, ok := s.getScanner(walletInfo.ChainType)
+	if !ok {
+		// 非EVM链没有ERC20授权
+		return nil
+	}
+
+	chainType := string(walletInfo.ChainType)
+	owner := walletInfo.Address
+
+	latest, err := scanner.LatestBlock(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 从上次扫描位置继续，首次扫描回溯固定区块数
+	var fromBlock uint64
+	cursor, err := s.allowanceStorage.GetScanCursor(walletID, chainType, owner)
+	if err != nil {
+		return fmt.Errorf("failed to get scan cursor: %v", err)
+	}
+	if cursor != nil {
+		fromBlock = cursor.LastBlock + 1
+	} else if latest > approvalScanLookback {
+		fromBlock = latest - approvalScanLookback
+	}
+
+	for start := fromBlock; start <= latest; start += approvalScanChunk {
+		end := start + approvalScanChunk - 1
+		if end > latest {
+			end = latest
+		}
+
+		events, err := scanner.GetApprovalEvents(ctx, owner, start, end)
+		if err != nil {
+			return err
+		}
+
+		if err := s.applyApprovalEvents(ctx, scanner, walletID, chainType, owner, events); err != nil {
+			return err
+		}
+
+		if err := s.allowanceStorage.SaveScanCursor(walletID, chainType, owner, end); err != nil {
+			return fmt.Errorf("failed to save scan cursor: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// applyApprovalEvents 按(token, spender)取最后一次事件，并以链上allowance为准更新记录
+func (s *ApprovalService) applyApprovalEvents(ctx context.Context, scanner wallet.ApprovalScanner, walletID, chainType, owner string, events []wallet.ApprovalEvent) error {
+	latestEvents := make(map[string]wallet.ApprovalEvent)
+	for _, event := range events {
+		key := strings.ToLower(event.Token + ":" + event.Spender)
+		prev, exists := latestEvents[key]
+		if !exists || event.BlockNumber > prev.BlockNumber ||
+			(event.BlockNumber == prev.BlockNumber && event.LogIndex > prev.LogIndex) {
+			latestEvents[key] = event
+		}
+	}
+
+	for _, event := range latestEvents {
+		// transferFrom会减少授权但不一定产生Approval事件，所以以当前allowance为准
+		allowance, err := scanner.GetAllowance(ctx, event.Token, owner, event.Spender)
+		if err != nil {
+			log.Printf("Warning: Failed to get allowance for %s/%s, using event value: %v", event.Token, event.Spender, err)
+			allowance = event.Value
+		}
+
+		record := &storage.TokenAllowance{
+			WalletID:    walletID,
+			ChainType:   chainType,
+			Owner:       owner,
+			Token:       event.Token,
+			Spender:     event.Spender,
+			Allowance:   allowance.String(),
+			RiskFlags:   strings.Join(s.assessRisk(ctx, scanner, wallet.ChainType(chainType), event.Spender, allowance), ","),
+			BlockNumber: event.BlockNumber,
+			TxHash:      event.TxHash,
+		}
+
+		if err := s.allowanceStorage.SaveAllowance(record); err != nil {
+			return fmt.Errorf("failed to save allowance: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// assessRisk 评估授权风险
+func (s *ApprovalService) assessRisk(ctx context.Context, scanner wallet.ApprovalScanner, chainType wallet.ChainType, spender string, allowance *big.Int) []string {
+	flags := []string{}
+	if allowance.Sign() == 0 {
+		return flags
+	}
+
+	if allowance.Cmp(unlimitedAllowanceThreshold) >= 0 {
+		flags = append(flags, RiskUnlimited)
+	}
+
+	if isContract, err := scanner.IsContract(ctx, spender); err == nil && !isContract {
+		flags = append(flags, RiskEOASpender)
+	}
+
+	if !isKnownSpender(chainType, spender) {
+		flags = append(flags, RiskUnknownSpender)
+	}
+
+	return flags
+}
+
+// isKnownSpender 判断被授权方是否为已知合约（Permit2、DEX路由）
+func isKnownSpender(chainType wallet.ChainType, spender string) bool {
+	if strings.EqualFold(spender, ethereum.Permit2Address) {
+		return true
+	}
+	if router, ok := getDEXRouter(chainType); ok && strings.EqualFold(spender, router) {
+		return true
+	}
+	return false
+}
+
+// ListAllowances 列出钱包的非零授权及风险标记
+func (s *ApprovalService) ListAllowances(walletID string) ([]*AllowanceInfo, error) {
+	records, err := s.allowanceStorage.GetAllowancesByWallet(walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get allowances: %v", err)
+	}
+
+	allowances := make([]*AllowanceInfo, 0, len(records))
+	for _, record := range records {
+		flags := []string{}
+		if record.RiskFlags != "" {
+			flags = strings.Split(record.RiskFlags, ",")
+		}
+		allowances = append(allowances, &AllowanceInfo{
+			ChainType:   record.ChainType,
+			Owner:       record.Owner,
+			Token:       record.Token,
+			Spender:     record.Spender,
+			Allowance:   record.Allowance,
+			RiskFlags:   flags,
+			BlockNumber: record.BlockNumber,
+			TxHash:      record.TxHash,
+		})
+	}
+
+	return allowances, nil
+}
+
+// BuildRevokeTransactions 构建批量撤销授权交易，approvals为空时撤销钱包全部非零授权
+func (s *ApprovalService) BuildRevokeTransactions(ctx context.Context, walletID string, approvals []wallet.TokenSpender) ([][]byte, error) {
+	walletInfo, err := s.walletService.GetWalletInfo(walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	scanner, ok := s.getScanner(walletInfo.ChainType)
+	if !ok {
+		return nil, wallet.ErrUnsupportedChain
+	}
+
+	if len(approvals) == 0 {
+		records, err := s.allowanceStorage.GetAllowancesByWallet(walletID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get allowances: %v", err)
+		}
+		for _, record := range records {
+			approvals = append(approvals, wallet.TokenSpender{Token: record.Token, Spender: record.Spender})
+		}
+	}
+
+	if len(approvals) == 0 {
+		return [][]byte{}, nil
+	}
+
+	return scanner.BuildRevokeTransactions(ctx, walletInfo.Address, approvals)
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"multi-chain-wallet/internal/storage"
+	"multi-chain-wallet/internal/wallet"
+	"multi-chain-wallet/internal/wallet/ethereum"
+)
+
+// fakeApprovalScanner 按预设的事件和链上状态响应的授权扫描器
+type fakeApprovalScanner struct {
+	wallet.Wallet
+	chainType  wallet.ChainType
+	latest     uint64
+	events     []wallet.ApprovalEvent
+	allowances map[string]*big.Int // token:spender -> 链上授权，不存在时查询失败
+	contracts  map[string]bool
+	ranges     [][2]uint64
+	revoked    []wallet.TokenSpender
+}
+
+func (f *fakeApprovalScanner) ChainType() wallet.ChainType { return f.chainType }
+
+func (f *fakeApprovalScanner) LatestBlock(ctx context.Context) (uint64, error) { return f.latest, nil }
+
+func (f *fakeApprovalScanner) GetApprovalEvents(ctx context.Context, owner string, fromBlock uint64, toBlock uint64) ([]wallet.ApprovalEvent, error) {
+	f.ranges = append(f.ranges, [2]uint64{fromBlock, toBlock})
+	var events []wallet.ApprovalEvent
+	for _, event := range f.events {
+		if event.BlockNumber >= fromBlock && event.BlockNumber <= toBlock {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (f *fakeApprovalScanner) GetAllowance(ctx context.Context, tokenAddress string, owner string, spender string) (*big.Int, error) {
+	allowance, ok := f.allowances[tokenAddress+":"+spender]
+	if !ok {
+		return nil, errors.New("rpc unavailable")
+	}
+	return allowance, nil
+}
+
+func (f *fakeApprovalScanner) IsContract(ctx context.Context, address string) (bool, error) {
+	return f.contracts[address], nil
+}
+
+func (f *fakeApprovalScanner) BuildRevokeTransactions(ctx context.Context, owner string, approvals []wallet.TokenSpender) ([][]byte, error) {
+	f.revoked = append(f.revoked, approvals...)
+	txs := make([][]byte, len(approvals))
+	for i := range approvals {
+		txs[i] = []byte{byte(i)}
+	}
+	return txs, nil
+}
+
+// newTestApprovalService 创建使用fakeApprovalScanner的授权服务和一个钱包
+func newTestApprovalService(t *testing.T, scanner *fakeApprovalScanner) (*ApprovalService, string) {
+	t.Helper()
+	scanner.chainType = wallet.ChainType("scan-" + uuid.New().String()[:8])
+	walletService := newTestWalletService(t)
+	walletService.GetWalletManager().RegisterWallet(scanner)
+
+	allowanceStorage := storage.NewMySQLAllowanceStorage()
+	if err := allowanceStorage.InitAllowanceTables(); err != nil {
+		t.Fatal(err)
+	}
+	walletID, _ := saveTestWallet(t, scanner.chainType)
+	return NewApprovalService(walletService, allowanceStorage), walletID
+}
+
+func TestScanWalletStoresCurrentAllowances(t *testing.T) {
+	const (
+		token   = "0x00000000000000000000000000000000000000t1"
+		spender = "0x00000000000000000000000000000000000000s1"
+	)
+
+	tests := []struct {
+		name       string
+		events     []wallet.ApprovalEvent
+		allowances map[string]*big.Int
+		want       string // 空表示不应列出
+	}{
+		{
+			// 同一被授权方取最后一次事件，额度以链上为准
+			name: "latest event and on-chain allowance",
+			events: []wallet.ApprovalEvent{
+				{Token: token, Spender: spender, Value: big.NewInt(100), BlockNumber: 10},
+				{Token: token, Spender: spender, Value: big.NewInt(300), BlockNumber: 20, LogIndex: 1},
+				{Token: token, Spender: spender, Value: big.NewInt(200), BlockNumber: 20},
+			},
+			allowances: map[string]*big.Int{token + ":" + spender: big.NewInt(250)},
+			want:       "250",
+		},
+		{
+			name:   "event value when allowance query fails",
+			events: []wallet.ApprovalEvent{{Token: token, Spender: spender, Value: big.NewInt(70), BlockNumber: 10}},
+			want:   "70",
+		},
+		{
+			// 已撤销或被transferFrom用完的授权不列出
+			name:       "zero allowance",
+			events:     []wallet.ApprovalEvent{{Token: token, Spender: spender, Value: big.NewInt(70), BlockNumber: 10}},
+			allowances: map[string]*big.Int{token + ":" + spender: big.NewInt(0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &fakeApprovalScanner{latest: 100, events: tt.events, allowances: tt.allowances}
+			s, walletID := newTestApprovalService(t, scanner)
+
+			if err := s.ScanWallet(context.Background(), walletID); err != nil {
+				t.Fatalf("ScanWallet: %v", err)
+			}
+			allowances, err := s.ListAllowances(walletID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == "" {
+				if len(allowances) != 0 {
+					t.Fatalf("expected no allowances, got %+v", allowances)
+				}
+				return
+			}
+			if len(allowances) != 1 || allowances[0].Allowance != tt.want {
+				t.Fatalf("expected one allowance of %s, got %+v", tt.want, allowances)
+			}
+		})
+	}
+}
+
+func TestAssessRisk(t *testing.T) {
+	const spender = "0x00000000000000000000000000000000000000s1"
+	unlimited := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+	tests := []struct {
+		name      string
+		spender   string
+		allowance *big.Int
+		contract  bool
+		want      []string
+	}{
+		{name: "unlimited to unknown EOA", spender: spender, allowance: unlimited, want: []string{RiskUnlimited, RiskEOASpender, RiskUnknownSpender}},
+		{name: "limited to unknown contract", spender: spender, allowance: big.NewInt(1), contract: true, want: []string{RiskUnknownSpender}},
+		{name: "unlimited to Permit2", spender: ethereum.Permit2Address, allowance: unlimited, contract: true, want: []string{RiskUnlimited}},
+		{name: "zero", spender: spender, allowance: big.NewInt(0), want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := &fakeApprovalScanner{contracts: map[string]bool{tt.spender: tt.contract}}
+			s := &ApprovalService{}
+			got := s.assessRisk(context.Background(), scanner, wallet.ChainTypeETH, tt.spender, tt.allowance)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// 首次扫描分段回溯，之后从上次的区块继续
+func TestScanWalletResumesFromCursor(t *testing.T) {
+	scanner := &fakeApprovalScanner{latest: 12000}
+	s, walletID := newTestApprovalService(t, scanner)
+
+	if err := s.ScanWallet(context.Background(), walletID); err != nil {
+		t.Fatalf("ScanWallet: %v", err)
+	}
+	want := [][2]uint64{{0, 4999}, {5000, 9999}, {10000, 12000}}
+	if !reflect.DeepEqual(scanner.ranges, want) {
+		t.Fatalf("expected ranges %v, got %v", want, scanner.ranges)
+	}
+
+	scanner.ranges = nil
+	scanner.latest = 12005
+	if err := s.ScanWallet(context.Background(), walletID); err != nil {
+		t.Fatalf("ScanWallet: %v", err)
+	}
+	if want := [][2]uint64{{12001, 12005}}; !reflect.DeepEqual(scanner.ranges, want) {
+		t.Fatalf("expected ranges %v, got %v", want, scanner.ranges)
+	}
+}
+
+// 未指定要撤销的授权时撤销钱包全部非零授权
+func TestBuildRevokeTransactionsDefaultsToStoredAllowances(t *testing.T) {
+	scanner := &fakeApprovalScanner{
+		latest: 100,
+		events: []wallet.ApprovalEvent{
+			{Token: "0xt1", Spender: "0xs1", Value: big.NewInt(1), BlockNumber: 1},
+			{Token: "0xt2", Spender: "0xs2", Value: big.NewInt(1), BlockNumber: 2},
+		},
+		allowances: map[string]*big.Int{"0xt1:0xs1": big.NewInt(5), "0xt2:0xs2": big.NewInt(0)},
+	}
+	s, walletID := newTestApprovalService(t, scanner)
+	if err := s.ScanWallet(context.Background(), walletID); err != nil {
+		t.Fatalf("ScanWallet: %v", err)
+	}
+
+	txs, err := s.BuildRevokeTransactions(context.Background(), walletID, nil)
+	if err != nil {
+		t.Fatalf("BuildRevokeTransactions: %v", err)
+	}
+	if len(txs) != 1 || len(scanner.revoked) != 1 || !strings.EqualFold(scanner.revoked[0].Token, "0xt1") {
+		t.Fatalf("expected only the non-zero allowance to be revoked, got %+v", scanner.revoked)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"strings"
 	"time"
 
 	"multi-chain-wallet/internal/storage"
@@ -16,18 +17,22 @@ import (
 type BridgeService struct {
 	walletService *WalletService
 	txStorage     storage.TransactionStorage
+	buildSourceTx func(ctx context.Context, tx *BridgeTransaction) (*wallet.UnsignedTx, error) // 创建源链交易
 }
 
 // NewBridgeService 创建跨链桥服务
 func NewBridgeService(walletService *WalletService, txStorage storage.TransactionStorage) *BridgeService {
-	return &BridgeService{
+	s := &BridgeService{
 		walletService: walletService,
 		txStorage:     txStorage,
 	}
+	s.buildSourceTx = s.createSourceTransaction
+	return s
 }
 
 // BridgeTransaction 跨链交易请求
 type BridgeTransaction struct {
+	WalletID        string // 签名源链交易的钱包，按该钱包的支出策略和审批规则校验
 	FromChainType   wallet.ChainType
 	ToChainType     wallet.ChainType
 	FromAddress     string
@@ -37,18 +42,35 @@ type BridgeTransaction struct {
 	IsTokenTransfer bool   // 是否是代币跨链
 }
 
-// CrossChainTransfer 执行跨链转账
+// CrossChainTransfer 执行跨链转账，发送方为WalletID对应钱包的地址
 func (s *BridgeService) CrossChainTransfer(ctx context.Context, tx *BridgeTransaction) (string, error) {
+	walletInfo, err := s.walletService.GetWalletInfo(tx.WalletID)
+	if err != nil {
+		return "", wallet.ErrWalletNotFound
+	}
+	if walletInfo.ChainType != tx.FromChainType {
+		return "", fmt.Errorf("%w: wallet %s is on %s, not %s", wallet.ErrInvalidTransaction, tx.WalletID, walletInfo.ChainType, tx.FromChainType)
+	}
+	if tx.FromAddress != "" && !strings.EqualFold(tx.FromAddress, walletInfo.Address) {
+		return "", fmt.Errorf("%w: fromAddress does not belong to wallet %s", wallet.ErrInvalidTransaction, tx.WalletID)
+	}
+	tx.FromAddress = walletInfo.Address
+
+	// 筛查发送方、目标链收款方和代币，命中拒绝名单时不发起跨链
+	screening, err := s.walletService.ScreenAddresses(tx.FromAddress, tx.ToAddress, tx.TokenAddress)
+	if err != nil {
+		return "", err
+	}
+
 	// 1. 检查源链余额
 	var balance *big.Int
-	var err error
 	if tx.IsTokenTransfer {
 		balance, err = s.walletService.GetTokenBalance(ctx, tx.FromChainType, tx.FromAddress, tx.TokenAddress)
 	} else {
 		balance, err = s.walletService.GetBalance(ctx, tx.FromChainType, tx.FromAddress)
 	}
 	if err != nil {
-		return "", fmt.Errorf("failed to get balance: %v", err)
+		return "", fmt.Errorf("failed to get balance: %w", err)
 	}
 
 	if balance.Cmp(tx.Amount) < 0 {
@@ -56,28 +78,21 @@ func (s *BridgeService) CrossChainTransfer(ctx context.Context, tx *BridgeTransa
 	}
 
 	// 2. 创建源链交易
-	var sourceTx []byte
-	if tx.IsTokenTransfer {
-		// 创建代币跨链交易
-		sourceTx, err = s.createTokenBridgeTransaction(ctx, tx)
-	} else {
-		// 创建原生代币跨链交易
-		sourceTx, err = s.createNativeBridgeTransaction(ctx, tx)
-	}
+	sourceTx, err := s.buildSourceTx(ctx, tx)
 	if err != nil {
 		return "", fmt.Errorf("failed to create bridge transaction: %v", err)
 	}
 
 	// 3. 签名源链交易
-	signedTx, err := s.walletService.SignTransaction(ctx, tx.FromChainType, tx.FromAddress, sourceTx)
+	signedTx, err := s.walletService.SignTransaction(ctx, tx.FromChainType, tx.WalletID, sourceTx)
 	if err != nil {
-		return "", fmt.Errorf("failed to sign transaction: %v", err)
+		return "", fmt.Errorf("failed to sign transaction: %w", err)
 	}
 
 	// 4. 发送源链交易
 	txHash, err := s.walletService.SendTransaction(ctx, tx.FromChainType, signedTx)
 	if err != nil {
-		return "", fmt.Errorf("failed to send transaction: %v", err)
+		return "", fmt.Errorf("failed to send transaction: %w", err)
 	}
 
 	// 5. 保存跨链交易记录
@@ -94,6 +109,7 @@ func (s *BridgeService) CrossChainTransfer(ctx context.Context, tx *BridgeTransa
 		Status:          string(wallet.TxPending),
 		CreateTime:      time.Now().Unix(),
 	}
+	bridgeTx.ScreeningStatus, bridgeTx.Screening = encodeScreening(screening)
 
 	if err := s.txStorage.SaveBridgeTransaction(bridgeTx); err != nil {
 		return "", fmt.Errorf("failed to save bridge transaction: %v", err)
@@ -113,7 +129,7 @@ func (s *BridgeService) GetBridgeTransactionStatus(ctx context.Context, txHash s
 	// 2. 获取源链交易状态
 	sourceStatus, err := s.walletService.GetTransactionStatus(ctx, wallet.ChainType(bridgeTx.FromChainType), bridgeTx.SourceTxHash)
 	if err != nil {
-		return "", fmt.Errorf("failed to get source transaction status: %v", err)
+		return "", fmt.Errorf("failed to get source transaction status: %w", err)
 	}
 
 	// 3. 如果源链交易已确认,检查目标链交易状态
@@ -135,8 +151,18 @@ func (s *BridgeService) GetBridgeTransactionHistory(address string) ([]*storage.
 	return s.txStorage.GetBridgeTransactionsByAddress(address)
 }
 
+// createSourceTransaction 按是否代币跨链创建源链交易
+func (s *BridgeService) createSourceTransaction(ctx context.Context, tx *BridgeTransaction) (*wallet.UnsignedTx, error) {
+	if tx.IsTokenTransfer {
+		// 创建代币跨链交易
+		return s.createTokenBridgeTransaction(ctx, tx)
+	}
+	// 创建原生代币跨链交易
+	return s.createNativeBridgeTransaction(ctx, tx)
+}
+
 // createTokenBridgeTransaction 创建代币跨链交易
-func (s *BridgeService) createTokenBridgeTransaction(ctx context.Context, tx *BridgeTransaction) ([]byte, error) {
+func (s *BridgeService) createTokenBridgeTransaction(ctx context.Context, tx *BridgeTransaction) (*wallet.UnsignedTx, error) {
 	// 这里需要实现代币跨链交易的具体逻辑
 	// 1. 调用源链的跨链合约
 	// 2. 生成目标链的交易数据
@@ -144,7 +170,7 @@ func (s *BridgeService) createTokenBridgeTransaction(ctx context.Context, tx *Br
 }
 
 // createNativeBridgeTransaction 创建原生代币跨链交易
-func (s *BridgeService) createNativeBridgeTransaction(ctx context.Context, tx *BridgeTransaction) ([]byte, error) {
+func (s *BridgeService) createNativeBridgeTransaction(ctx context.Context, tx *BridgeTransaction) (*wallet.UnsignedTx, error) {
 	// 这里需要实现原生代币跨链交易的具体逻辑
 	// 1. 调用源链的跨链合约
 	// 2. 生成目标链的交易数据
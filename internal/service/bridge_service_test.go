@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"multi-chain-wallet/internal/storage"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// 跨链转账按发送钱包的支出策略校验，超出限额时不签名也不发送
+func TestBridgeTransferBlockedByPolicyLimit(t *testing.T) {
+	walletService := newTestWalletService(t)
+	policyService := newTestPolicyService(t, walletService)
+	walletID, address := importDevAccount(t, walletService, 0)
+	if _, err := policyService.SetPolicy(walletID, &SpendingPolicy{
+		Limits: []AssetLimit{{Asset: PolicyAssetNative, PerTx: "1000"}},
+	}); err != nil {
+		t.Fatalf("SetPolicy: %v", err)
+	}
+
+	// 模拟链上没有跨链合约，源链交易为转给桥地址的原生代币转账
+	bridge := NewBridgeService(walletService, &storage.MySQLTransactionStorage{})
+	bridge.buildSourceTx = func(ctx context.Context, tx *BridgeTransaction) (*wallet.UnsignedTx, error) {
+		return walletService.GetWalletManager().CreateTransaction(ctx, tx.FromChainType, tx.FromAddress,
+			"0x000000000000000000000000000000000000b41d", tx.Amount, nil)
+	}
+	transfer := func(amount int64) (string, error) {
+		return bridge.CrossChainTransfer(context.Background(), &BridgeTransaction{
+			WalletID:      walletID,
+			FromChainType: testChainType,
+			ToChainType:   testChainType,
+			ToAddress:     address,
+			Amount:        big.NewInt(amount),
+		})
+	}
+
+	if _, err := transfer(1001); !errors.Is(err, wallet.ErrPolicyViolation) {
+		t.Fatalf("expected ErrPolicyViolation, got %v", err)
+	}
+	history, err := bridge.GetBridgeTransactionHistory(address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 0 {
+		t.Fatalf("expected no bridge transaction to be recorded, got %d", len(history))
+	}
+
+	txHash, err := transfer(1000)
+	if err != nil {
+		t.Fatalf("CrossChainTransfer: %v", err)
+	}
+	if txHash == "" {
+		t.Fatal("expected a source transaction hash")
+	}
+}
@@ -18,6 +18,7 @@ type DEXService struct {
 	walletService    *WalletService
 	txStorage        *storage.MySQLTransactionStorage
 	orderStorage     *storage.MySQLOrderStorage
+	permitService    *PermitService
 	routeCache       sync.Map // 缓存交易路径计算结果
 	pendingOrdersMux sync.Mutex
 	pendingOrders    map[string]*Order // 待处理的订单
@@ -44,6 +45,7 @@ type Order struct {
 type SwapRoute struct {
 	Path      []string // 代币路径
 	Pools     []string // 池地址
+	FeeTiers  []uint32 // 各池的费率档位（百万分之一）
 	AmountIn  *big.Int // 输入金额
 	AmountOut *big.Int // 预期输出金额
 	Impact    float64  // 价格影响
@@ -57,6 +59,7 @@ func NewDEXService(walletService *WalletService, txStorage *storage.MySQLTransac
 		walletService: walletService,
 		txStorage:     txStorage,
 		orderStorage:  orderStorage,
+		permitService: NewPermitService(walletService),
 		pendingOrders: make(map[string]*Order),
 		workerPool:    make(chan struct{}, 50), // 最多50个并发处理
 	}
@@ -113,9 +116,16 @@ func (s *DEXService) calculateOptimalRoute(ctx context.Context, chainType wallet
 	// 4. 计算价格影响和手续费
 	impact, fee := calculatePriceImpactAndFee(pools, path, amount, amountOut)
 
+	// 池信息未带费率时按0.3%档位
+	feeTiers := make([]uint32, len(path)-1)
+	for i := range feeTiers {
+		feeTiers[i] = defaultV3FeeTier
+	}
+
 	return &SwapRoute{
 		Path:      path,
 		Pools:     poolPath,
+		FeeTiers:  feeTiers,
 		AmountIn:  amount,
 		AmountOut: amountOut,
 		Impact:    impact,
@@ -129,7 +139,7 @@ func (s *DEXService) Swap(ctx context.Context, walletID string, chainType wallet
 	// 1. 检查余额
 	balance, err := s.walletService.GetTokenBalance(ctx, chainType, walletID, fromToken)
 	if err != nil {
-		return "", fmt.Errorf("failed to get token balance: %v", err)
+		return "", fmt.Errorf("failed to get token balance: %w", err)
 	}
 
 	if balance.Cmp(amount) < 0 {
@@ -147,25 +157,36 @@ func (s *DEXService) Swap(ctx context.Context, walletID string, chainType wallet
 		return "", fmt.Errorf("output amount too low, expected at least %s", minReceived.String())
 	}
 
-	// 3. 创建交易
-	tx, err := s.createSwapTransaction(ctx, chainType, walletID, route, minReceived)
+	// 筛查交易的代币和途经的池，命中拒绝名单时不交易
+	if _, err := s.walletService.ScreenAddresses(append([]string{fromToken, toToken}, route.Pools...)...); err != nil {
+		return "", err
+	}
+
+	// 3. 为路由合约签名Permit2授权，与兑换在同一笔交易中提交
+	permit, err := s.preparePermit(ctx, chainType, walletID, fromToken, amount)
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare permit: %w", err)
+	}
+
+	// 4. 创建交易
+	tx, err := s.createSwapTransaction(ctx, chainType, walletID, route, minReceived, permit)
 	if err != nil {
 		return "", fmt.Errorf("failed to create swap transaction: %v", err)
 	}
 
-	// 4. 签名交易
+	// 5. 签名交易
 	signedTx, err := s.walletService.SignTransaction(ctx, chainType, walletID, tx)
 	if err != nil {
-		return "", fmt.Errorf("failed to sign transaction: %v", err)
+		return "", fmt.Errorf("failed to sign transaction: %w", err)
 	}
 
-	// 5. 发送交易
+	// 6. 发送交易
 	txHash, err := s.walletService.SendTransaction(ctx, chainType, signedTx)
 	if err != nil {
-		return "", fmt.Errorf("failed to send transaction: %v", err)
+		return "", fmt.Errorf("failed to send transaction: %w", err)
 	}
 
-	// 6. 保存交易记录
+	// 7. 保存交易记录
 	order := &storage.Order{
 		ID:          uuid.New().String(),
 		WalletID:    walletID,
@@ -186,18 +207,33 @@ func (s *DEXService) Swap(ctx context.Context, walletID string, chainType wallet
 	return txHash, nil
 }
 
+// preparePermit 为DEX路由合约签名Permit2授权。Universal Router只通过Permit2扣款，不支持EIP-2612
+func (s *DEXService) preparePermit(ctx context.Context, chainType wallet.ChainType, walletID string, fromToken string, amount *big.Int) (*PermitSignature, error) {
+	router, ok := getDEXRouter(chainType)
+	if !ok {
+		return nil, wallet.ErrOperationNotSupported
+	}
+
+	return s.permitService.PreparePermit2(ctx, chainType, walletID, fromToken, router, amount)
+}
+
 // PlaceLimitOrder 创建限价订单
 func (s *DEXService) PlaceLimitOrder(ctx context.Context, walletID string, chainType wallet.ChainType, fromToken, toToken string, amount, limitPrice *big.Int) (string, error) {
 	// 1. 检查余额
 	balance, err := s.walletService.GetTokenBalance(ctx, chainType, walletID, fromToken)
 	if err != nil {
-		return "", fmt.Errorf("failed to get token balance: %v", err)
+		return "", fmt.Errorf("failed to get token balance: %w", err)
 	}
 
 	if balance.Cmp(amount) < 0 {
 		return "", fmt.Errorf("insufficient balance")
 	}
 
+	// 筛查交易的代币，命中拒绝名单时不下单
+	if _, err := s.walletService.ScreenAddresses(fromToken, toToken); err != nil {
+		return "", err
+	}
+
 	// 2. 计算tick
 	tick := calculateTick(fromToken, toToken, limitPrice)
 
@@ -210,13 +246,13 @@ func (s *DEXService) PlaceLimitOrder(ctx context.Context, walletID string, chain
 	// 4. 签名交易
 	signedTx, err := s.walletService.SignTransaction(ctx, chainType, walletID, tx)
 	if err != nil {
-		return "", fmt.Errorf("failed to sign transaction: %v", err)
+		return "", fmt.Errorf("failed to sign transaction: %w", err)
 	}
 
 	// 5. 发送交易
 	txHash, err := s.walletService.SendTransaction(ctx, chainType, signedTx)
 	if err != nil {
-		return "", fmt.Errorf("failed to send transaction: %v", err)
+		return "", fmt.Errorf("failed to send transaction: %w", err)
 	}
 
 	// 6. 保存订单记录
@@ -282,13 +318,13 @@ func (s *DEXService) CancelLimitOrder(ctx context.Context, walletID string, chai
 	// 3. 签名交易
 	signedTx, err := s.walletService.SignTransaction(ctx, chainType, walletID, tx)
 	if err != nil {
-		return "", fmt.Errorf("failed to sign transaction: %v", err)
+		return "", fmt.Errorf("failed to sign transaction: %w", err)
 	}
 
 	// 4. 发送交易
 	txHash, err := s.walletService.SendTransaction(ctx, chainType, signedTx)
 	if err != nil {
-		return "", fmt.Errorf("failed to send transaction: %v", err)
+		return "", fmt.Errorf("failed to send transaction: %w", err)
 	}
 
 	// 5. 更新订单状态
@@ -319,7 +355,7 @@ func (s *DEXService) GetOrderStatus(ctx context.Context, orderID string) (string
 	// 检查链上状态
 	status, err := s.walletService.GetTransactionStatus(ctx, wallet.ChainType(order.ChainType), order.TxHash)
 	if err != nil {
-		return "", fmt.Errorf("failed to get transaction status: %v", err)
+		return "", fmt.Errorf("failed to get transaction status: %w", err)
 	}
 
 	// 更新订单状态
@@ -425,13 +461,13 @@ func (s *DEXService) executeOrder(ctx context.Context, order *Order) error {
 	// 签名交易
 	signedTx, err := s.walletService.SignTransaction(ctx, order.ChainType, order.WalletID, tx)
 	if err != nil {
-		return fmt.Errorf("failed to sign transaction: %v", err)
+		return fmt.Errorf("failed to sign transaction: %w", err)
 	}
 
 	// 发送交易
 	txHash, err := s.walletService.SendTransaction(ctx, order.ChainType, signedTx)
 	if err != nil {
-		return fmt.Errorf("failed to send transaction: %v", err)
+		return fmt.Errorf("failed to send transaction: %w", err)
 	}
 
 	// 更新订单状态
@@ -458,6 +494,19 @@ func (s *DEXService) getDEXPools(ctx context.Context, chainType wallet.ChainType
 	return []interface{}{}, nil
 }
 
+// defaultV3FeeTier Uniswap V3默认费率档位（0.3%）
+const defaultV3FeeTier = 3000
+
+// getDEXRouter 获取链上DEX路由合约地址（Uniswap Universal Router）
+func getDEXRouter(chainType wallet.ChainType) (string, bool) {
+	routers := map[wallet.ChainType]string{
+		wallet.ChainTypeETH:     "0x3fC91A3afd70395Cd496C647d5a6CC9D4B2b7FAD",
+		wallet.ChainTypePolygon: "0x3fC91A3afd70395Cd496C647d5a6CC9D4B2b7FAD",
+	}
+	router, ok := routers[chainType]
+	return router, ok
+}
+
 func buildTokenGraph(pools []interface{}) map[string]map[string]interface{} {
 	// 构建代币交易图
 	return make(map[string]map[string]interface{})
@@ -488,24 +537,52 @@ func calculatePrice(amountIn, amountOut *big.Int, fromDecimals, toDecimals int)
 	return big.NewInt(0)
 }
 
-// 以下是实际交易创建函数的模拟实现，实际应用中需要根据具体DEX协议生成交易数据
+// createSwapTransaction 创建Universal Router兑换交易，permit不为空时以PERMIT2_PERMIT命令附在兑换之前
+func (s *DEXService) createSwapTransaction(ctx context.Context, chainType wallet.ChainType, walletID string, route *SwapRoute, minReceived *big.Int, permit *PermitSignature) (*wallet.UnsignedTx, error) {
+	router, ok := getDEXRouter(chainType)
+	if !ok {
+		return nil, wallet.ErrOperationNotSupported
+	}
+	walletImpl, ok := s.walletService.GetWalletByChainType(chainType)
+	if !ok {
+		return nil, wallet.ErrUnsupportedChain
+	}
+	routerWallet, ok := walletImpl.(wallet.SwapRouterWallet)
+	if !ok {
+		return nil, wallet.ErrOperationNotSupported
+	}
+	from, err := s.walletService.GetWalletManager().GetAddress(walletID)
+	if err != nil {
+		return nil, err
+	}
 
-func (s *DEXService) createSwapTransaction(ctx context.Context, chainType wallet.ChainType, walletID string, route *SwapRoute, minReceived *big.Int) ([]byte, error) {
-	// 创建兑换交易
-	return []byte{}, nil
+	swap := &wallet.RouterSwap{
+		Path:         route.Path,
+		FeeTiers:     route.FeeTiers,
+		AmountIn:     route.AmountIn,
+		AmountOutMin: minReceived,
+		Deadline:     big.NewInt(time.Now().Add(defaultPermitTTL).Unix()),
+	}
+	if permit != nil {
+		swap.Permit2TypedData = permit.TypedData
+		swap.Permit2Signature = permit.Signature
+	}
+	return routerWallet.BuildRouterSwap(ctx, from, router, swap)
 }
 
-func (s *DEXService) createLimitOrderTransaction(ctx context.Context, chainType wallet.ChainType, walletID string, fromToken, toToken string, amount, limitPrice *big.Int, tick int64) ([]byte, error) {
+// 以下是实际交易创建函数的模拟实现，实际应用中需要根据具体DEX协议生成交易数据
+
+func (s *DEXService) createLimitOrderTransaction(ctx context.Context, chainType wallet.ChainType, walletID string, fromToken, toToken string, amount, limitPrice *big.Int, tick int64) (*wallet.UnsignedTx, error) {
 	// 创建限价订单交易
-	return []byte{}, nil
+	return &wallet.UnsignedTx{ChainType: chainType}, nil
 }
 
-func (s *DEXService) createCancelOrderTransaction(ctx context.Context, chainType wallet.ChainType, walletID string, orderID string) ([]byte, error) {
+func (s *DEXService) createCancelOrderTransaction(ctx context.Context, chainType wallet.ChainType, walletID string, orderID string) (*wallet.UnsignedTx, error) {
 	// 创建取消订单交易
-	return []byte{}, nil
+	return &wallet.UnsignedTx{ChainType: chainType}, nil
 }
 
-func (s *DEXService) createExecuteOrderTransaction(ctx context.Context, order *Order) ([]byte, error) {
+func (s *DEXService) createExecuteOrderTransaction(ctx context.Context, order *Order) (*wallet.UnsignedTx, error) {
 	// 创建执行订单交易
-	return []byte{}, nil
+	return &wallet.UnsignedTx{ChainType: order.ChainType}, nil
 }
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/google/uuid"
+
+	"multi-chain-wallet/internal/offline"
+	"multi-chain-wallet/internal/storage"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// OfflineService 离线签名服务：生成待签名交易包供冷钱包签名，导入签名结果后校验并广播
+type OfflineService struct {
+	walletService  *WalletService
+	offlineStorage *storage.MySQLOfflineStorage
+}
+
+// NewOfflineService 创建离线签名服务
+func NewOfflineService(walletService *WalletService, offlineStorage *storage.MySQLOfflineStorage) *OfflineService {
+	return &OfflineService{
+		walletService:  walletService,
+		offlineStorage: offlineStorage,
+	}
+}
+
+// PrepareBundle 创建待签名交易并生成离线签名包，tokenAddress非空时为代币转账。
+// 仅支持可解码原始交易的链（EVM），以便离线设备和导入时校验交易意图
+func (s *OfflineService) PrepareBundle(ctx context.Context, chainType wallet.ChainType, from string, to string, tokenAddress string, amount *big.Int, data []byte) (*offline.Bundle, error) {
+	chainInfo, ok := s.walletService.walletManager.GetChainInfo(chainType)
+	if !ok {
+		return nil, wallet.ErrUnsupportedChain
+	}
+
+	var (
+		tx  *wallet.UnsignedTx
+		err error
+	)
+	if tokenAddress != "" {
+		tx, err = s.walletService.CreateTokenTransaction(ctx, chainType, from, to, tokenAddress, amount)
+	} else {
+		tx, err = s.walletService.CreateTransaction(ctx, chainType, from, to, amount, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	intent, err := s.walletService.DecodeRawTransaction(chainType, tx.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := offline.NewBundle(uuid.New().String(), new(big.Int).SetUint64(chainInfo.ChainID), tx, intent)
+	bundleJSON, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize offline bundle: %v", err)
+	}
+
+	if err := s.offlineStorage.SaveOfflineBundle(&storage.OfflineBundle{
+		ID:         bundle.ID,
+		ChainType:  string(chainType),
+		From:       tx.From,
+		Bundle:     string(bundleJSON),
+		Status:     storage.OfflineBundlePrepared,
+		CreateTime: bundle.CreatedAt,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save offline bundle: %v", err)
+	}
+
+	return bundle, nil
+}
+
+// GetBundle 获取离线签名包及其状态
+func (s *OfflineService) GetBundle(bundleID string) (*offline.Bundle, *storage.OfflineBundle, error) {
+	record, err := s.offlineStorage.GetOfflineBundle(bundleID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("offline bundle not found: %v", err)
+	}
+
+	var bundle offline.Bundle
+	if err := json.Unmarshal([]byte(record.Bundle), &bundle); err != nil {
+		return nil, nil, fmt.Errorf("failed to deserialize offline bundle: %v", err)
+	}
+	return &bundle, record, nil
+}
+
+// SubmitSigned 导入离线签名结果，校验签名者和交易内容与签名包意图一致后通过SendTransaction广播
+func (s *OfflineService) SubmitSigned(ctx context.Context, signed *offline.SignedBundle) (string, error) {
+	bundle, record, err := s.GetBundle(signed.BundleID)
+	if err != nil {
+		return "", err
+	}
+	if record.Status != storage.OfflineBundlePrepared {
+		return "", fmt.Errorf("%w: offline bundle %s already broadcast as %s", wallet.ErrInvalidTransaction, bundle.ID, record.TxHash)
+	}
+	if signed.ChainType != "" && signed.ChainType != bundle.ChainType {
+		return "", fmt.Errorf("%w: signed transaction is for chain %s, bundle is for %s", wallet.ErrInvalidTransaction, signed.ChainType, bundle.ChainType)
+	}
+
+	decoded, err := s.walletService.DecodeRawTransaction(bundle.ChainType, signed.RawTx)
+	if err != nil {
+		return "", err
+	}
+	if err := offline.CheckIntent(bundle.Intent, decoded, true); err != nil {
+		return "", err
+	}
+
+	// 沿用签名包中的交易信息记账，代币转账记录实际收款人和代币数量
+	txHash, err := s.walletService.SendTransaction(ctx, bundle.ChainType, bundle.Tx.Signed(signed.RawTx, decoded.Hash))
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.offlineStorage.MarkOfflineBundleBroadcast(bundle.ID, txHash); err != nil {
+		return "", fmt.Errorf("failed to update offline bundle: %v", err)
+	}
+	return txHash, nil
+}
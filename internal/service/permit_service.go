@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// 链下授权类型
+const (
+	PermitKindEIP2612      = "eip2612"
+	PermitKindPermit2      = "permit2-single"
+	PermitKindPermit2Batch = "permit2-batch"
+)
+
+// 签名授权默认有效期
+const defaultPermitTTL = 30 * time.Minute
+
+// PermitService 链下签名授权服务，用EIP-2612/Permit2签名替代链上approve交易
+type PermitService struct {
+	walletService *WalletService
+}
+
+// NewPermitService 创建链下签名授权服务
+func NewPermitService(walletService *WalletService) *PermitService {
+	return &PermitService{
+		walletService: walletService,
+	}
+}
+
+// PermitSignature 签名后的授权
+type PermitSignature struct {
+	Kind      string          `json:"kind"`
+	Owner     string          `json:"owner"`
+	Spender   string          `json:"spender"`
+	Token     string          `json:"token,omitempty"`
+	Value     *big.Int        `json:"value,omitempty"`
+	Deadline  *big.Int        `json:"deadline"`
+	TypedData json.RawMessage `json:"typedData"`
+	Signature []byte          `json:"signature"`
+}
+
+// VRS 拆分签名为permit(owner, spender, value, deadline, v, r, s)所需的参数
+func (p *PermitSignature) VRS() (uint8, [32]byte, [32]byte) {
+	var r, s [32]byte
+	copy(r[:], p.Signature[:32])
+	copy(s[:], p.Signature[32:64])
+	return p.Signature[64], r, s
+}
+
+// getPermitWallet 获取支持链下授权的钱包实现
+func (s *PermitService) getPermitWallet(chainType wallet.ChainType) (wallet.PermitWallet, error) {
+	walletImpl, ok := s.walletService.GetWalletByChainType(chainType)
+	if !ok {
+		return nil, wallet.ErrUnsupportedChain
+	}
+
+	permitWallet, ok := walletImpl.(wallet.PermitWallet)
+	if !ok {
+		return nil, wallet.ErrPermitNotSupported
+	}
+
+	return permitWallet, nil
+}
+
+// GetPermitSupport 检测代币的链下授权支持情况
+func (s *PermitService) GetPermitSupport(ctx context.Context, chainType wallet.ChainType, walletID string, tokenAddress string) (*wallet.PermitSupport, error) {
+	permitWallet, err := s.getPermitWallet(chainType)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := s.walletService.GetWalletManager().GetAddress(walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	return permitWallet.GetPermitSupport(ctx, tokenAddress, owner)
+}
+
+// SignPermit 构造并签名EIP-2612 Permit
+func (s *PermitService) SignPermit(ctx context.Context, chainType wallet.ChainType, walletID string, tokenAddress string, spender string, value *big.Int, deadline *big.Int) (*PermitSignature, error) {
+	permitWallet, err := s.getPermitWallet(chainType)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := s.walletService.GetWalletManager().GetAddress(walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	typedData, err := permitWallet.BuildPermitTypedData(ctx, tokenAddress, owner, spender, value, deadline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build permit: %v", err)
+	}
+
+	signature, err := s.walletService.SignTypedData(ctx, chainType, walletID, typedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign permit: %v", err)
+	}
+
+	return &PermitSignature{
+		Kind:      PermitKindEIP2612,
+		Owner:     owner,
+		Spender:   spender,
+		Token:     tokenAddress,
+		Value:     value,
+		Deadline:  deadline,
+		TypedData: typedData,
+		Signature: signature,
+	}, nil
+}
+
+// SignPermit2 构造并签名Permit2授权，details只有一个时为PermitSingle，否则为PermitBatch
+func (s *PermitService) SignPermit2(ctx context.Context, chainType wallet.ChainType, walletID string, spender string, details []wallet.Permit2Details, sigDeadline *big.Int) (*PermitSignature, error) {
+	permitWallet, err := s.getPermitWallet(chainType)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := s.walletService.GetWalletManager().GetAddress(walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	typedData, err := permitWallet.BuildPermit2TypedData(ctx, owner, spender, details, sigDeadline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build permit2: %v", err)
+	}
+
+	signature, err := s.walletService.SignTypedData(ctx, chainType, walletID, typedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign permit2: %v", err)
+	}
+
+	permit := &PermitSignature{
+		Kind:      PermitKindPermit2,
+		Owner:     owner,
+		Spender:   spender,
+		Deadline:  sigDeadline,
+		TypedData: typedData,
+		Signature: signature,
+	}
+	if len(details) > 1 {
+		permit.Kind = PermitKindPermit2Batch
+	} else {
+		permit.Token = details[0].Token
+		permit.Value = details[0].Amount
+	}
+
+	return permit, nil
+}
+
+// PreparePermit2 为spender签名Permit2 PermitSingle。代币须已approve给Permit2合约，否则返回ErrInvalidTransaction
+func (s *PermitService) PreparePermit2(ctx context.Context, chainType wallet.ChainType, walletID string, tokenAddress string, spender string, amount *big.Int) (*PermitSignature, error) {
+	support, err := s.GetPermitSupport(ctx, chainType, walletID, tokenAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	permit2Allowance, ok := new(big.Int).SetString(support.Permit2Allowance, 10)
+	if !ok || permit2Allowance.Cmp(amount) < 0 {
+		return nil, fmt.Errorf("%w: token %s is not approved to Permit2, approve Permit2 first", wallet.ErrInvalidTransaction, tokenAddress)
+	}
+
+	deadline := time.Now().Add(defaultPermitTTL).Unix()
+	details := []wallet.Permit2Details{{
+		Token:      tokenAddress,
+		Amount:     amount,
+		Expiration: uint64(deadline),
+	}}
+	return s.SignPermit2(ctx, chainType, walletID, spender, details, big.NewInt(deadline))
+}
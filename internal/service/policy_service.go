@@ -0,0 +1,1069 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"multi-chain-wallet/internal/storage"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// 策略中表示原生代币的资产名
+const PolicyAssetNative = "native"
+
+// 每日限额按滚动24小时计算
+const policyDailyWindow = 24 * time.Hour
+
+// 计入代币支出的ERC20方法：transfer(address,uint256)、transferFrom(address,address,uint256)、approve(address,uint256)
+const (
+	erc20TransferSelector     = "0xa9059cbb"
+	erc20TransferFromSelector = "0x23b872dd"
+	erc20ApproveSelector      = "0x095ea7b3"
+)
+
+// 时间窗口中的星期写法
+var policyWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// PolicyService 钱包支出策略服务。WalletService签名交易、EIP-712数据和UserOperation前都经过这里，
+// 钱包DEX、跨链、Safe、中继和会话密钥的签名因此受同一套策略约束。未设置策略的钱包不受限制。
+// 收紧策略立即生效，放宽或删除策略在changeDelay之后才生效
+type PolicyService struct {
+	walletService *WalletService
+	policyStorage *storage.MySQLPolicyStorage
+	changeDelay   time.Duration
+	mu            sync.Mutex             // 保护locks
+	locks         map[string]*sync.Mutex // 每个钱包一把锁，校验限额和预留支出时加锁，避免并发签名超出每日限额
+}
+
+// NewPolicyService 创建支出策略服务，放宽策略的变更在changeDelay之后生效
+func NewPolicyService(walletService *WalletService, policyStorage *storage.MySQLPolicyStorage, changeDelay time.Duration) *PolicyService {
+	return &PolicyService{
+		walletService: walletService,
+		policyStorage: policyStorage,
+		changeDelay:   changeDelay,
+		locks:         make(map[string]*sync.Mutex),
+	}
+}
+
+// SpendingPolicy 钱包的支出策略，各项为空表示不限制
+type SpendingPolicy struct {
+	Limits            []AssetLimit     `json:"limits,omitempty"`
+	AllowDestinations []string         `json:"allowDestinations,omitempty"` // 收款方白名单，包括原生代币收款人、代币收款人和被授权方
+	DenyDestinations  []string         `json:"denyDestinations,omitempty"`  // 黑名单，交易涉及的任一地址（含调用的合约）命中即拒绝
+	AllowContracts    []SessionScope   `json:"allowContracts,omitempty"`    // 合约调用白名单，设置后所有带calldata的调用（含代币转账）和合约部署都须在其中
+	TimeWindows       []PolicyTimeSpan `json:"timeWindows,omitempty"`       // 允许签名的时间段，满足任一即可
+}
+
+// AssetLimit 单个资产的限额，Asset为native或代币地址，金额为最小单位的十进制字符串，为空表示不限
+type AssetLimit struct {
+	Asset string `json:"asset"`
+	PerTx string `json:"perTx,omitempty"`
+	Daily string `json:"daily,omitempty"` // 滚动24小时内的累计上限
+}
+
+// PolicyTimeSpan 允许签名的时间段，[Start, End)，Start晚于End时跨越午夜。Days为mon到sun，为空表示每天
+type PolicyTimeSpan struct {
+	Days     []string `json:"days,omitempty"`
+	Start    string   `json:"start"` // HH:MM
+	End      string   `json:"end"`
+	Timezone string   `json:"timezone,omitempty"` // IANA时区，默认UTC
+}
+
+// WalletPolicy 钱包策略及当前额度使用情况
+type WalletPolicy struct {
+	WalletID   string               `json:"walletId"`
+	Policy     *SpendingPolicy      `json:"policy"`
+	Pending    *PendingPolicyChange `json:"pending,omitempty"`
+	Usage      []*AssetUsage        `json:"usage"`
+	CreateTime int64                `json:"createTime"`
+	UpdatedAt  int64                `json:"updatedAt"`
+}
+
+// PendingPolicyChange 尚未生效的放宽或删除策略的变更
+type PendingPolicyChange struct {
+	Policy      *SpendingPolicy `json:"policy,omitempty"`
+	Delete      bool            `json:"delete,omitempty"`
+	EffectiveAt int64           `json:"effectiveAt"`
+}
+
+// AssetUsage 资产在滚动24小时内的支出，Daily和Remaining在未设置每日限额时为空
+type AssetUsage struct {
+	Asset     string `json:"asset"`
+	Used      string `json:"used"`
+	Daily     string `json:"daily,omitempty"`
+	Remaining string `json:"remaining,omitempty"`
+}
+
+// PolicyDecision 策略校验结果，Reason说明拒绝原因
+type PolicyDecision struct {
+	Allowed bool              `json:"allowed"`
+	Reason  string            `json:"reason,omitempty"`
+	Spends  map[string]string `json:"spends,omitempty"` // 本次签名计入限额的各资产金额
+}
+
+// policyIntent 从待签名数据中解析出的支出意图
+type policyIntent struct {
+	calls        []policyCall // 带calldata的合约调用，智能账户的批量调用有多个
+	deploy       bool
+	destinations []string            // 收款人和被授权方
+	spends       map[string]*big.Int // 资产到金额
+}
+
+// policyCall 调用的合约及函数选择器
+type policyCall struct {
+	contract string
+	method   string
+}
+
+func (i *policyIntent) addDestination(address string) {
+	if address != "" {
+		i.destinations = append(i.destinations, address)
+	}
+}
+
+// GetPolicy 获取钱包的策略、待生效的变更及滚动24小时内的额度使用情况，未设置策略时返回nil
+func (s *PolicyService) GetPolicy(walletID string) (*WalletPolicy, error) {
+	record, err := s.getPolicyRecord(walletID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, nil
+	}
+
+	policy, err := decodePolicy(record.Document)
+	if err != nil {
+		return nil, err
+	}
+	used, err := s.dailyUsage(walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &WalletPolicy{
+		WalletID:   record.WalletID,
+		Policy:     policy,
+		Usage:      policyUsage(policy, used),
+		CreateTime: record.CreateTime,
+		UpdatedAt:  record.UpdatedAt.Unix(),
+	}
+	if record.PendingAt != 0 {
+		result.Pending = &PendingPolicyChange{Delete: record.PendingDelete, EffectiveAt: record.PendingAt}
+		if !record.PendingDelete {
+			if result.Pending.Policy, err = decodePolicy(record.Pending); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// SetPolicy 校验并保存钱包的策略，滚动窗口内已记录的支出继续计入限额。新策略不比现有策略宽松时立即生效，
+// 并取消待生效的变更；否则在changeDelay之后生效，期间仍按现有策略校验
+func (s *PolicyService) SetPolicy(walletID string, policy *SpendingPolicy) (*WalletPolicy, error) {
+	if _, err := s.walletService.GetWalletInfo(walletID); err != nil {
+		return nil, wallet.ErrWalletNotFound
+	}
+	normalized, err := normalizePolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+	document, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize policy: %v", err)
+	}
+
+	record, err := s.getPolicyRecord(walletID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		record = &storage.WalletPolicy{
+			WalletID:   walletID,
+			CreateTime: time.Now().Unix(),
+		}
+	}
+
+	tightens := record.Document == ""
+	if !tightens {
+		current, err := decodePolicy(record.Document)
+		if err != nil {
+			return nil, err
+		}
+		tightens = policyTightens(current, normalized)
+	}
+	if tightens || s.changeDelay <= 0 {
+		record.Document = string(document)
+		record.Pending, record.PendingDelete, record.PendingAt = "", false, 0
+	} else {
+		record.Pending, record.PendingDelete, record.PendingAt = string(document), false, time.Now().Add(s.changeDelay).Unix()
+	}
+	if err := s.policyStorage.SavePolicy(record); err != nil {
+		return nil, fmt.Errorf("failed to save policy: %v", err)
+	}
+
+	return s.GetPolicy(walletID)
+}
+
+// DeletePolicy 删除钱包的策略，在changeDelay之后生效，之后该钱包的签名不再受限。
+// 立即删除时返回nil，否则返回带待生效变更的策略
+func (s *PolicyService) DeletePolicy(walletID string) (*WalletPolicy, error) {
+	record, err := s.getPolicyRecord(walletID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, nil
+	}
+	if s.changeDelay <= 0 {
+		if err := s.policyStorage.DeletePolicy(walletID); err != nil {
+			return nil, fmt.Errorf("failed to delete policy: %v", err)
+		}
+		return nil, nil
+	}
+
+	record.Pending, record.PendingDelete, record.PendingAt = "", true, time.Now().Add(s.changeDelay).Unix()
+	if err := s.policyStorage.SavePolicy(record); err != nil {
+		return nil, fmt.Errorf("failed to save policy: %v", err)
+	}
+	return s.GetPolicy(walletID)
+}
+
+// getPolicyRecord 获取钱包的策略记录，待生效的变更到期时先应用，未设置策略时返回nil
+func (s *PolicyService) getPolicyRecord(walletID string) (*storage.WalletPolicy, error) {
+	record, err := s.policyStorage.GetPolicy(walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policy: %v", err)
+	}
+	if record == nil || record.PendingAt == 0 || time.Now().Unix() < record.PendingAt {
+		return record, nil
+	}
+
+	if record.PendingDelete {
+		if err := s.policyStorage.DeletePolicy(walletID); err != nil {
+			return nil, fmt.Errorf("failed to delete policy: %v", err)
+		}
+		return nil, nil
+	}
+	record.Document = record.Pending
+	record.Pending, record.PendingAt = "", 0
+	if err := s.policyStorage.SavePolicy(record); err != nil {
+		return nil, fmt.Errorf("failed to save policy: %v", err)
+	}
+	return record, nil
+}
+
+// EvaluateTransaction 试算交易是否符合策略，不签名也不记账
+func (s *PolicyService) EvaluateTransaction(ctx context.Context, chainType wallet.ChainType, walletID string, tx *wallet.UnsignedTx) (*PolicyDecision, error) {
+	if err := tx.CheckChain(chainType); err != nil {
+		return nil, fmt.Errorf("%w: %v", wallet.ErrInvalidTransaction, err)
+	}
+	policy, err := s.loadPolicy(walletID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return &PolicyDecision{Allowed: true}, nil
+	}
+	intent, err := s.transactionIntent(chainType, tx)
+	if err != nil {
+		return nil, err
+	}
+	return s.decide(walletID, policy, intent)
+}
+
+// EnforceTransaction 交易符合策略时调用sign签名，并把支出计入每日限额；不符合时返回ErrPolicyViolation及原因
+func (s *PolicyService) EnforceTransaction(ctx context.Context, chainType wallet.ChainType, walletID string, tx *wallet.UnsignedTx, sign func() (*wallet.SignedTx, error)) (*wallet.SignedTx, error) {
+	policy, err := s.loadPolicy(walletID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return sign()
+	}
+	if err := tx.CheckChain(chainType); err != nil {
+		return nil, fmt.Errorf("%w: %v", wallet.ErrInvalidTransaction, err)
+	}
+	intent, err := s.transactionIntent(chainType, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var signedTx *wallet.SignedTx
+	err = s.enforce(walletID, policy, intent, func() (string, error) {
+		signedTx, err = sign()
+		if err != nil {
+			return "", err
+		}
+		return signedTx.Hash, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return signedTx, nil
+}
+
+// EnforceTypedData EIP-712数据符合策略时调用sign签名。EIP-2612 Permit和Permit2授权按approve处理，
+// EIP-2771 ForwardRequest和Safe的SafeTx按其中的调用处理；设置了策略时其他类型的数据无法校验，按违反策略拒绝
+func (s *PolicyService) EnforceTypedData(ctx context.Context, walletID string, typedDataJSON []byte, sign func() ([]byte, error)) ([]byte, error) {
+	policy, err := s.loadPolicy(walletID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return sign()
+	}
+	intent, err := typedDataIntent(typedDataJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	var signature []byte
+	err = s.enforce(walletID, policy, intent, func() (string, error) {
+		signature, err = sign()
+		if err != nil {
+			return "", err
+		}
+		sum := sha256.Sum256(typedDataJSON)
+		return "eip712:" + hex.EncodeToString(sum[:]), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return signature, nil
+}
+
+// EnforceUserOperation UserOperation中智能账户执行的调用符合策略时调用sign签名，各调用按callIntent解析后合并计入限额。
+// 设置了策略时，callData为空或无法由decodeCalls解析的UserOperation无法校验，按违反策略拒绝
+func (s *PolicyService) EnforceUserOperation(ctx context.Context, walletID string, userOpHash string, op *wallet.UserOperation, decodeCalls func(*wallet.UserOperation) ([]wallet.SmartAccountCall, error), sign func() (*wallet.UserOperation, error)) (*wallet.UserOperation, error) {
+	policy, err := s.loadPolicy(walletID)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return sign()
+	}
+	calls, err := decodeCalls(op)
+	if err != nil {
+		return nil, fmt.Errorf("%w: user operation callData cannot be checked: %v", wallet.ErrPolicyViolation, err)
+	}
+	if len(calls) == 0 {
+		return nil, fmt.Errorf("%w: user operation has no calls to check", wallet.ErrPolicyViolation)
+	}
+	intent, err := userOperationIntent(calls)
+	if err != nil {
+		return nil, err
+	}
+
+	var signed *wallet.UserOperation
+	err = s.enforce(walletID, policy, intent, func() (string, error) {
+		signed, err = sign()
+		if err != nil {
+			return "", err
+		}
+		return "userop:" + userOpHash, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
+
+// enforce 在钱包锁内按策略校验并预留支出，锁外调用sign签名。sign返回记账用的键，签名成功后预留改记到该键下，
+// 失败时释放预留；记账失败时不返回签名，避免绕过每日限额
+func (s *PolicyService) enforce(walletID string, policy *SpendingPolicy, intent *policyIntent, sign func() (string, error)) error {
+	reservation, err := s.reserve(walletID, policy, intent)
+	if err != nil {
+		return err
+	}
+
+	key, err := sign()
+	if err != nil {
+		if releaseErr := s.policyStorage.DeletePolicySpends(walletID, reservation); releaseErr != nil {
+			return fmt.Errorf("%v (failed to release policy spends: %v)", err, releaseErr)
+		}
+		return err
+	}
+	if err := s.policyStorage.CommitPolicySpends(walletID, reservation, key); err != nil {
+		return fmt.Errorf("failed to record policy spends: %v", err)
+	}
+	return nil
+}
+
+// reserve 在钱包锁内校验策略，通过后以预留键记录支出，返回预留键
+func (s *PolicyService) reserve(walletID string, policy *SpendingPolicy, intent *policyIntent) (string, error) {
+	lock := s.walletLock(walletID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := s.authorize(walletID, policy, intent); err != nil {
+		return "", err
+	}
+	reservation := "reserved:" + uuid.New().String()
+	if err := s.recordSpends(walletID, reservation, intent); err != nil {
+		return "", err
+	}
+	return reservation, nil
+}
+
+// walletLock 获取钱包的锁
+func (s *PolicyService) walletLock(walletID string) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, ok := s.locks[walletID]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.locks[walletID] = lock
+	}
+	return lock
+}
+
+// authorize 按策略校验，拒绝时返回带原因的ErrPolicyViolation
+func (s *PolicyService) authorize(walletID string, policy *SpendingPolicy, intent *policyIntent) error {
+	decision, err := s.decide(walletID, policy, intent)
+	if err != nil {
+		return err
+	}
+	if !decision.Allowed {
+		return fmt.Errorf("%w: %s", wallet.ErrPolicyViolation, decision.Reason)
+	}
+	return nil
+}
+
+// decide 依次校验时间窗口、合约部署、黑名单、收款方白名单、合约调用白名单和限额
+func (s *PolicyService) decide(walletID string, policy *SpendingPolicy, intent *policyIntent) (*PolicyDecision, error) {
+	decision := &PolicyDecision{Spends: make(map[string]string)}
+	for asset, amount := range intent.spends {
+		decision.Spends[asset] = amount.String()
+	}
+	deny := func(format string, args ...interface{}) (*PolicyDecision, error) {
+		decision.Reason = fmt.Sprintf(format, args...)
+		return decision, nil
+	}
+
+	if len(policy.TimeWindows) > 0 && !inTimeWindows(policy.TimeWindows, time.Now()) {
+		return deny("signing is only allowed within %s", describeTimeWindows(policy.TimeWindows))
+	}
+	if intent.deploy && len(policy.AllowContracts) > 0 {
+		return deny("contract deployment is not allowed when a contract allowlist is set")
+	}
+
+	var touched []string
+	for _, call := range intent.calls {
+		touched = append(touched, call.contract)
+	}
+	touched = append(touched, intent.destinations...)
+	for _, address := range touched {
+		if containsFold(policy.DenyDestinations, address) {
+			return deny("address %s is on the deny list", address)
+		}
+	}
+	if len(policy.AllowDestinations) > 0 {
+		for _, address := range intent.destinations {
+			if !containsFold(policy.AllowDestinations, address) {
+				return deny("destination %s is not on the allow list", address)
+			}
+		}
+	}
+	if len(policy.AllowContracts) > 0 {
+		for _, call := range intent.calls {
+			scope, ok := findScope(policy.AllowContracts, call.contract)
+			if !ok {
+				return deny("contract %s is not on the contract allow list", call.contract)
+			}
+			if len(scope.Methods) > 0 && !containsFold(scope.Methods, call.method) {
+				return deny("method %s on contract %s is not allowed", call.method, call.contract)
+			}
+		}
+	}
+
+	if len(policy.Limits) > 0 && len(intent.spends) > 0 {
+		used, err := s.dailyUsage(walletID)
+		if err != nil {
+			return nil, err
+		}
+		for _, asset := range sortedAssets(intent.spends) {
+			amount := intent.spends[asset]
+			limit, ok := findAssetLimit(policy.Limits, asset)
+			if !ok {
+				continue
+			}
+			if limit.PerTx != "" {
+				perTx, _ := new(big.Int).SetString(limit.PerTx, 10)
+				if amount.Cmp(perTx) > 0 {
+					return deny("%s amount %s exceeds per-transaction limit %s", asset, amount, perTx)
+				}
+			}
+			if limit.Daily != "" {
+				daily, _ := new(big.Int).SetString(limit.Daily, 10)
+				spent := new(big.Int)
+				if used[asset] != nil {
+					spent.Set(used[asset])
+				}
+				if new(big.Int).Add(spent, amount).Cmp(daily) > 0 {
+					remaining := new(big.Int).Sub(daily, spent)
+					if remaining.Sign() < 0 {
+						remaining.SetInt64(0)
+					}
+					return deny("%s amount %s exceeds remaining daily limit %s (limit %s, spent %s in the last 24h)",
+						asset, amount, remaining, daily, spent)
+				}
+			}
+		}
+	}
+
+	decision.Allowed = true
+	return decision, nil
+}
+
+// transactionIntent 解析交易的支出，以实际要签名的Payload为准，不使用UnsignedTx中调用方填写的收款方、金额和代币。
+// 无法解码Payload的交易不签名
+func (s *PolicyService) transactionIntent(chainType wallet.ChainType, tx *wallet.UnsignedTx) (*policyIntent, error) {
+	walletImpl, ok := s.walletService.GetWalletByChainType(chainType)
+	if !ok {
+		return nil, wallet.ErrUnsupportedChain
+	}
+
+	switch w := walletImpl.(type) {
+	case wallet.RawTransactionWallet:
+		decoded, err := w.DecodeRawTransaction(tx.Payload)
+		if err != nil {
+			return nil, err
+		}
+		if decoded.Signed {
+			return nil, fmt.Errorf("%w: transaction is already signed", wallet.ErrInvalidTransaction)
+		}
+		return callIntent(decoded.To, decoded.Value, decoded.Data)
+	case wallet.PayloadWallet:
+		transfers, err := w.DecodePayload(tx.Payload)
+		if err != nil {
+			return nil, err
+		}
+		intent := &policyIntent{spends: make(map[string]*big.Int)}
+		for _, transfer := range transfers {
+			intent.addDestination(transfer.To)
+			asset := PolicyAssetNative
+			if transfer.Token != "" {
+				asset = normalizeAsset(transfer.Token)
+			}
+			addSpend(intent.spends, asset, transfer.Value)
+		}
+		return intent, nil
+	default:
+		return nil, fmt.Errorf("%w: cannot decode %s transactions", wallet.ErrInvalidTransaction, chainType)
+	}
+}
+
+// callIntent 解析EVM调用：value计入原生代币，ERC20的transfer、transferFrom和approve计入代币
+func callIntent(to string, value *big.Int, data string) (*policyIntent, error) {
+	intent := &policyIntent{spends: make(map[string]*big.Int)}
+	addSpend(intent.spends, PolicyAssetNative, value)
+	if to == "" {
+		intent.deploy = true
+		return intent, nil
+	}
+	// 不带calldata的是转账，收款方受收款方名单约束；合约调用随附的value由合约调用白名单约束
+	if data == "" || data == "0x" {
+		intent.destinations = append(intent.destinations, to)
+		return intent, nil
+	}
+
+	calldata, err := hex.DecodeString(strings.TrimPrefix(data, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid calldata", wallet.ErrInvalidTransaction)
+	}
+	if len(calldata) < 4 {
+		intent.calls = append(intent.calls, policyCall{contract: to, method: "0x" + hex.EncodeToString(calldata)})
+		return intent, nil
+	}
+	method := "0x" + hex.EncodeToString(calldata[:4])
+	intent.calls = append(intent.calls, policyCall{contract: to, method: method})
+
+	args := calldata[4:]
+	word := func(i int) []byte { return args[i*32 : (i+1)*32] }
+	address := func(i int) string { return "0x" + hex.EncodeToString(word(i)[12:]) }
+	token := normalizeAsset(to)
+	switch method {
+	case erc20TransferSelector, erc20ApproveSelector:
+		if len(args) < 64 {
+			return nil, fmt.Errorf("%w: truncated %s calldata", wallet.ErrInvalidTransaction, method)
+		}
+		intent.destinations = append(intent.destinations, address(0))
+		addSpend(intent.spends, token, new(big.Int).SetBytes(word(1)))
+	case erc20TransferFromSelector:
+		if len(args) < 96 {
+			return nil, fmt.Errorf("%w: truncated %s calldata", wallet.ErrInvalidTransaction, method)
+		}
+		intent.destinations = append(intent.destinations, address(1))
+		addSpend(intent.spends, token, new(big.Int).SetBytes(word(2)))
+	}
+	return intent, nil
+}
+
+// userOperationIntent 合并智能账户各调用的支出意图
+func userOperationIntent(calls []wallet.SmartAccountCall) (*policyIntent, error) {
+	intent := &policyIntent{spends: make(map[string]*big.Int)}
+	for _, call := range calls {
+		callIntent, err := callIntent(call.To, call.Value, call.Data)
+		if err != nil {
+			return nil, err
+		}
+		intent.calls = append(intent.calls, callIntent.calls...)
+		intent.deploy = intent.deploy || callIntent.deploy
+		intent.destinations = append(intent.destinations, callIntent.destinations...)
+		for asset, amount := range callIntent.spends {
+			addSpend(intent.spends, asset, amount)
+		}
+	}
+	return intent, nil
+}
+
+// typedDataIntent 解析EIP-712数据中的授权或调用，无法解析的类型返回ErrPolicyViolation
+func typedDataIntent(typedDataJSON []byte) (*policyIntent, error) {
+	var typedData struct {
+		PrimaryType string                 `json:"primaryType"`
+		Domain      map[string]interface{} `json:"domain"`
+		Message     map[string]interface{} `json:"message"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(typedDataJSON))
+	decoder.UseNumber()
+	if err := decoder.Decode(&typedData); err != nil {
+		return nil, fmt.Errorf("%w: invalid typed data: %v", wallet.ErrInvalidTransaction, err)
+	}
+
+	intent := &policyIntent{spends: make(map[string]*big.Int)}
+	message := typedData.Message
+	switch typedData.PrimaryType {
+	case "Permit":
+		// EIP-2612，代币为domain中的verifyingContract
+		token, _ := typedData.Domain["verifyingContract"].(string)
+		spender, _ := message["spender"].(string)
+		intent.addDestination(spender)
+		addSpend(intent.spends, normalizeAsset(token), typedDataNumber(message["value"]))
+	case "PermitSingle", "PermitBatch":
+		spender, _ := message["spender"].(string)
+		intent.addDestination(spender)
+		details := []interface{}{message["details"]}
+		if batch, ok := message["details"].([]interface{}); ok {
+			details = batch
+		}
+		for _, item := range details {
+			detail, _ := item.(map[string]interface{})
+			token, _ := detail["token"].(string)
+			addSpend(intent.spends, normalizeAsset(token), typedDataNumber(detail["amount"]))
+		}
+	case "ForwardRequest":
+		to, _ := message["to"].(string)
+		data, _ := message["data"].(string)
+		return callIntent(to, typedDataNumber(message["value"]), data)
+	case "SafeTx":
+		// 资金从Safe转出，按签名钱包的策略约束其所有者能批准的调用；DELEGATECALL无法判断实际效果
+		operation := typedDataNumber(message["operation"])
+		if operation == nil || operation.Sign() != 0 {
+			return nil, fmt.Errorf("%w: SafeTx with operation other than CALL cannot be checked", wallet.ErrPolicyViolation)
+		}
+		to, _ := message["to"].(string)
+		data, _ := message["data"].(string)
+		return callIntent(to, typedDataNumber(message["value"]), data)
+	default:
+		return nil, fmt.Errorf("%w: typed data %q cannot be checked against the policy", wallet.ErrPolicyViolation, typedData.PrimaryType)
+	}
+	return intent, nil
+}
+
+// loadPolicy 获取钱包的策略，未设置时返回nil
+func (s *PolicyService) loadPolicy(walletID string) (*SpendingPolicy, error) {
+	record, err := s.getPolicyRecord(walletID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, nil
+	}
+	return decodePolicy(record.Document)
+}
+
+// dailyUsage 汇总钱包在滚动24小时内各资产的支出
+func (s *PolicyService) dailyUsage(walletID string) (map[string]*big.Int, error) {
+	since := time.Now().Add(-policyDailyWindow).Unix()
+	spends, err := s.policyStorage.ListPolicySpends(walletID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policy spends: %v", err)
+	}
+
+	used := make(map[string]*big.Int)
+	for _, spend := range spends {
+		amount, ok := new(big.Int).SetString(spend.Amount, 10)
+		if !ok {
+			continue
+		}
+		addSpend(used, spend.Asset, amount)
+	}
+	return used, nil
+}
+
+// recordSpends 记录已签名交易的各资产支出
+func (s *PolicyService) recordSpends(walletID string, txHash string, intent *policyIntent) error {
+	if len(intent.spends) == 0 {
+		return nil
+	}
+	now := time.Now().Unix()
+	spends := make([]*storage.PolicySpend, 0, len(intent.spends))
+	for _, asset := range sortedAssets(intent.spends) {
+		spends = append(spends, &storage.PolicySpend{
+			WalletID:   walletID,
+			Asset:      asset,
+			TxHash:     txHash,
+			Amount:     intent.spends[asset].String(),
+			CreateTime: now,
+		})
+	}
+	if err := s.policyStorage.SavePolicySpends(spends); err != nil {
+		return fmt.Errorf("failed to record policy spends: %v", err)
+	}
+	return nil
+}
+
+// normalizePolicy 校验策略，金额和时间统一格式，选择器统一为小写
+func normalizePolicy(policy *SpendingPolicy) (*SpendingPolicy, error) {
+	normalized := &SpendingPolicy{
+		AllowDestinations: policy.AllowDestinations,
+		DenyDestinations:  policy.DenyDestinations,
+	}
+
+	seen := make(map[string]bool)
+	for _, limit := range policy.Limits {
+		if limit.Asset == "" {
+			return nil, fmt.Errorf("%w: limit asset is required, use %q for the native coin", wallet.ErrInvalidTransaction, PolicyAssetNative)
+		}
+		asset := normalizeAsset(limit.Asset)
+		if seen[asset] {
+			return nil, fmt.Errorf("%w: duplicate limit for asset %s", wallet.ErrInvalidTransaction, limit.Asset)
+		}
+		seen[asset] = true
+		for _, amount := range []string{limit.PerTx, limit.Daily} {
+			if amount == "" {
+				continue
+			}
+			if v, ok := new(big.Int).SetString(amount, 10); !ok || v.Sign() < 0 {
+				return nil, fmt.Errorf("%w: invalid limit amount %s for asset %s", wallet.ErrInvalidTransaction, amount, limit.Asset)
+			}
+		}
+		normalized.Limits = append(normalized.Limits, AssetLimit{Asset: asset, PerTx: limit.PerTx, Daily: limit.Daily})
+	}
+
+	for _, address := range append(append([]string{}, policy.AllowDestinations...), policy.DenyDestinations...) {
+		if strings.TrimSpace(address) == "" {
+			return nil, fmt.Errorf("%w: empty address in destination list", wallet.ErrInvalidTransaction)
+		}
+	}
+
+	if len(policy.AllowContracts) > 0 {
+		scopes, err := normalizeScopes(policy.AllowContracts)
+		if err != nil {
+			return nil, err
+		}
+		normalized.AllowContracts = scopes
+	}
+
+	for _, window := range policy.TimeWindows {
+		if _, err := parseClock(window.Start); err != nil {
+			return nil, err
+		}
+		if _, err := parseClock(window.End); err != nil {
+			return nil, err
+		}
+		if window.Start == window.End {
+			return nil, fmt.Errorf("%w: time window start and end must differ", wallet.ErrInvalidTransaction)
+		}
+		if window.Timezone != "" {
+			if _, err := time.LoadLocation(window.Timezone); err != nil {
+				return nil, fmt.Errorf("%w: unknown timezone %s", wallet.ErrInvalidTransaction, window.Timezone)
+			}
+		}
+		days := make([]string, 0, len(window.Days))
+		for _, day := range window.Days {
+			day = strings.ToLower(day)
+			if _, ok := policyWeekdays[day]; !ok {
+				return nil, fmt.Errorf("%w: invalid day %s, expected mon to sun", wallet.ErrInvalidTransaction, day)
+			}
+			days = append(days, day)
+		}
+		normalized.TimeWindows = append(normalized.TimeWindows, PolicyTimeSpan{
+			Days:     days,
+			Start:    window.Start,
+			End:      window.End,
+			Timezone: window.Timezone,
+		})
+	}
+
+	return normalized, nil
+}
+
+// policyTightens next是否不比current宽松：保留current的每项限额且不提高，收款方和合约白名单、时间窗口只缩小，黑名单只扩大。
+// 无法判断的变更按放宽处理
+func policyTightens(current *SpendingPolicy, next *SpendingPolicy) bool {
+	for _, limit := range current.Limits {
+		nextLimit, ok := findAssetLimit(next.Limits, limit.Asset)
+		if !ok || !amountTightens(limit.PerTx, nextLimit.PerTx) || !amountTightens(limit.Daily, nextLimit.Daily) {
+			return false
+		}
+	}
+
+	if len(current.AllowDestinations) > 0 {
+		if len(next.AllowDestinations) == 0 {
+			return false
+		}
+		for _, address := range next.AllowDestinations {
+			if !containsFold(current.AllowDestinations, address) {
+				return false
+			}
+		}
+	}
+	for _, address := range current.DenyDestinations {
+		if !containsFold(next.DenyDestinations, address) {
+			return false
+		}
+	}
+
+	if len(current.AllowContracts) > 0 {
+		if len(next.AllowContracts) == 0 {
+			return false
+		}
+		for _, scope := range next.AllowContracts {
+			currentScope, ok := findScope(current.AllowContracts, scope.Contract)
+			if !ok {
+				return false
+			}
+			if len(currentScope.Methods) == 0 {
+				continue
+			}
+			if len(scope.Methods) == 0 {
+				return false
+			}
+			for _, method := range scope.Methods {
+				if !containsFold(currentScope.Methods, method) {
+					return false
+				}
+			}
+		}
+	}
+
+	if len(current.TimeWindows) > 0 {
+		if len(next.TimeWindows) == 0 {
+			return false
+		}
+		for _, window := range next.TimeWindows {
+			if !containsTimeWindow(current.TimeWindows, window) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// amountTightens 新上限不高于现有上限，现有上限为空表示不限
+func amountTightens(current string, next string) bool {
+	if current == "" {
+		return true
+	}
+	if next == "" {
+		return false
+	}
+	currentAmount, _ := new(big.Int).SetString(current, 10)
+	nextAmount, _ := new(big.Int).SetString(next, 10)
+	return nextAmount.Cmp(currentAmount) <= 0
+}
+
+func containsTimeWindow(windows []PolicyTimeSpan, window PolicyTimeSpan) bool {
+	for _, w := range windows {
+		if w.Start == window.Start && w.End == window.End && w.Timezone == window.Timezone &&
+			strings.Join(w.Days, ",") == strings.Join(window.Days, ",") {
+			return true
+		}
+	}
+	return false
+}
+
+func decodePolicy(document string) (*SpendingPolicy, error) {
+	var policy SpendingPolicy
+	if err := json.Unmarshal([]byte(document), &policy); err != nil {
+		return nil, fmt.Errorf("failed to decode policy: %v", err)
+	}
+	return &policy, nil
+}
+
+// policyUsage 列出有限额或有支出的资产的使用情况
+func policyUsage(policy *SpendingPolicy, used map[string]*big.Int) []*AssetUsage {
+	assets := make(map[string]*big.Int)
+	for asset, amount := range used {
+		assets[asset] = amount
+	}
+	for _, limit := range policy.Limits {
+		if assets[limit.Asset] == nil {
+			assets[limit.Asset] = new(big.Int)
+		}
+	}
+
+	usage := make([]*AssetUsage, 0, len(assets))
+	for _, asset := range sortedAssets(assets) {
+		item := &AssetUsage{Asset: asset, Used: assets[asset].String()}
+		if limit, ok := findAssetLimit(policy.Limits, asset); ok && limit.Daily != "" {
+			daily, _ := new(big.Int).SetString(limit.Daily, 10)
+			remaining := new(big.Int).Sub(daily, assets[asset])
+			if remaining.Sign() < 0 {
+				remaining.SetInt64(0)
+			}
+			item.Daily = limit.Daily
+			item.Remaining = remaining.String()
+		}
+		usage = append(usage, item)
+	}
+	return usage
+}
+
+func findAssetLimit(limits []AssetLimit, asset string) (AssetLimit, bool) {
+	for _, limit := range limits {
+		if strings.EqualFold(limit.Asset, asset) {
+			return limit, true
+		}
+	}
+	return AssetLimit{}, false
+}
+
+// normalizeAsset EVM地址统一为小写，其他链的代币标识大小写敏感，保持原样
+func normalizeAsset(asset string) string {
+	if strings.EqualFold(asset, PolicyAssetNative) {
+		return PolicyAssetNative
+	}
+	if strings.HasPrefix(asset, "0x") || strings.HasPrefix(asset, "0X") {
+		return strings.ToLower(asset)
+	}
+	return asset
+}
+
+func addSpend(spends map[string]*big.Int, asset string, amount *big.Int) {
+	if amount == nil || amount.Sign() <= 0 {
+		return
+	}
+	if spends[asset] == nil {
+		spends[asset] = new(big.Int)
+	}
+	spends[asset].Add(spends[asset], amount)
+}
+
+func sortedAssets(spends map[string]*big.Int) []string {
+	assets := make([]string, 0, len(spends))
+	for asset := range spends {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+	return assets
+}
+
+// typedDataNumber 解析EIP-712消息中的整数，兼容JSON数字、十进制和0x十六进制字符串
+func typedDataNumber(v interface{}) *big.Int {
+	var s string
+	switch value := v.(type) {
+	case json.Number:
+		s = value.String()
+	case string:
+		s = value
+	default:
+		return nil
+	}
+	if strings.HasPrefix(s, "0x") {
+		n, ok := new(big.Int).SetString(s[2:], 16)
+		if !ok {
+			return nil
+		}
+		return n
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil
+	}
+	return n
+}
+
+// parseClock 解析HH:MM，返回当天的分钟数
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid time %q, expected HH:MM", wallet.ErrInvalidTransaction, clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// inTimeWindows now是否落在任一时间段内，跨午夜的时间段按开始那天匹配星期
+func inTimeWindows(windows []PolicyTimeSpan, now time.Time) bool {
+	for _, window := range windows {
+		location := time.UTC
+		if window.Timezone != "" {
+			if loc, err := time.LoadLocation(window.Timezone); err == nil {
+				location = loc
+			}
+		}
+		local := now.In(location)
+		start, _ := parseClock(window.Start)
+		end, _ := parseClock(window.End)
+		minute := local.Hour()*60 + local.Minute()
+
+		day := local.Weekday()
+		var inside bool
+		if start < end {
+			inside = minute >= start && minute < end
+		} else if minute >= start {
+			inside = true
+		} else if minute < end {
+			inside = true
+			day = local.AddDate(0, 0, -1).Weekday()
+		}
+		if inside && matchesWeekday(window.Days, day) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesWeekday(days []string, day time.Weekday) bool {
+	if len(days) == 0 {
+		return true
+	}
+	for _, d := range days {
+		if policyWeekdays[d] == day {
+			return true
+		}
+	}
+	return false
+}
+
+func describeTimeWindows(windows []PolicyTimeSpan) string {
+	parts := make([]string, 0, len(windows))
+	for _, window := range windows {
+		part := window.Start + "-" + window.End
+		if len(window.Days) > 0 {
+			part = strings.Join(window.Days, ",") + " " + part
+		}
+		timezone := window.Timezone
+		if timezone == "" {
+			timezone = "UTC"
+		}
+		parts = append(parts, part+" "+timezone)
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"multi-chain-wallet/internal/storage"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// newTestPolicyService 创建挂载在钱包服务上的策略服务，策略变更立即生效
+func newTestPolicyService(t *testing.T, walletService *WalletService) *PolicyService {
+	t.Helper()
+	policyStorage := storage.NewMySQLPolicyStorage()
+	if err := policyStorage.InitPolicyTables(); err != nil {
+		t.Fatal(err)
+	}
+	policyService := NewPolicyService(walletService, policyStorage, 0)
+	walletService.SetPolicyService(policyService)
+	return policyService
+}
+
+// 设置了策略时，callData为空或无法解析的UserOperation不签名
+func TestPolicyRejectsUncheckableUserOperation(t *testing.T) {
+	walletService := newTestWalletService(t)
+	policyService := newTestPolicyService(t, walletService)
+	walletID, _ := importDevAccount(t, walletService, 0)
+	if _, err := policyService.SetPolicy(walletID, &SpendingPolicy{
+		Limits: []AssetLimit{{Asset: PolicyAssetNative, PerTx: "1000"}},
+	}); err != nil {
+		t.Fatalf("SetPolicy: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		decodeCalls func(*wallet.UserOperation) ([]wallet.SmartAccountCall, error)
+	}{
+		{name: "empty callData", decodeCalls: func(*wallet.UserOperation) ([]wallet.SmartAccountCall, error) { return nil, nil }},
+		{name: "undecodable callData", decodeCalls: func(*wallet.UserOperation) ([]wallet.SmartAccountCall, error) {
+			return nil, errors.New("unsupported smart account method 0x12345678")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signed := false
+			_, err := policyService.EnforceUserOperation(context.Background(), walletID, "0x01", &wallet.UserOperation{}, tt.decodeCalls,
+				func() (*wallet.UserOperation, error) {
+					signed = true
+					return &wallet.UserOperation{}, nil
+				})
+			if !errors.Is(err, wallet.ErrPolicyViolation) || signed {
+				t.Fatalf("expected ErrPolicyViolation without signing, got %v (signed %v)", err, signed)
+			}
+		})
+	}
+}
+
+// 放宽和删除策略延迟生效，收紧立即生效并取消待生效的变更
+func TestPolicyLooseningChangeIsDelayed(t *testing.T) {
+	walletService := newTestWalletService(t)
+	policyService := newTestPolicyService(t, walletService)
+	policyService.changeDelay = time.Hour
+	walletID, _ := importDevAccount(t, walletService, 0)
+	limit := func(perTx string) *SpendingPolicy {
+		return &SpendingPolicy{Limits: []AssetLimit{{Asset: PolicyAssetNative, PerTx: perTx}}}
+	}
+	if _, err := policyService.SetPolicy(walletID, limit("1000")); err != nil {
+		t.Fatalf("SetPolicy: %v", err)
+	}
+
+	got, err := policyService.SetPolicy(walletID, limit("2000"))
+	if err != nil {
+		t.Fatalf("SetPolicy: %v", err)
+	}
+	if got.Policy.Limits[0].PerTx != "1000" || got.Pending == nil || got.Pending.Policy.Limits[0].PerTx != "2000" {
+		t.Fatalf("expected the raised limit to be pending, got %+v", got)
+	}
+	got, err = policyService.SetPolicy(walletID, limit("500"))
+	if err != nil {
+		t.Fatalf("SetPolicy: %v", err)
+	}
+	if got.Policy.Limits[0].PerTx != "500" || got.Pending != nil {
+		t.Fatalf("expected the lowered limit to apply immediately, got %+v", got)
+	}
+	got, err = policyService.SetPolicy(walletID, &SpendingPolicy{})
+	if err != nil {
+		t.Fatalf("SetPolicy: %v", err)
+	}
+	if got.Policy.Limits[0].PerTx != "500" || got.Pending == nil {
+		t.Fatalf("expected removing the limit to be pending, got %+v", got)
+	}
+
+	got, err = policyService.DeletePolicy(walletID)
+	if err != nil {
+		t.Fatalf("DeletePolicy: %v", err)
+	}
+	if got == nil || got.Pending == nil || !got.Pending.Delete {
+		t.Fatalf("expected the deletion to be pending, got %+v", got)
+	}
+	record, err := policyService.policyStorage.GetPolicy(walletID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record.PendingAt = time.Now().Add(-time.Second).Unix()
+	if err := policyService.policyStorage.SavePolicy(record); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := policyService.GetPolicy(walletID); err != nil || got != nil {
+		t.Fatalf("expected the policy to be deleted once the delay passed, got %+v, %v", got, err)
+	}
+}
+
+// 设置了策略时，SafeTx按其中的调用校验，DELEGATECALL和无法识别的EIP-712类型不签名
+func TestPolicyChecksTypedData(t *testing.T) {
+	walletService := newTestWalletService(t)
+	policyService := newTestPolicyService(t, walletService)
+	walletID, _ := importDevAccount(t, walletService, 0)
+	if _, err := policyService.SetPolicy(walletID, &SpendingPolicy{
+		Limits: []AssetLimit{{Asset: PolicyAssetNative, PerTx: "1000"}},
+	}); err != nil {
+		t.Fatalf("SetPolicy: %v", err)
+	}
+	walletImpl, _ := walletService.GetWalletByChainType(testChainType)
+	safeTypedData := func(value int64, operation uint8) []byte {
+		typedData, _, err := walletImpl.(wallet.SafeWallet).BuildSafeTxTypedData(&wallet.SafeTransaction{
+			Safe:      "0x0000000000000000000000000000000000005afe",
+			To:        "0x000000000000000000000000000000000000dEaD",
+			Value:     big.NewInt(value),
+			Operation: operation,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return typedData
+	}
+
+	tests := []struct {
+		name      string
+		typedData []byte
+		wantErr   bool
+	}{
+		{name: "SafeTx within limit", typedData: safeTypedData(1000, 0)},
+		{name: "SafeTx over limit", typedData: safeTypedData(1001, 0), wantErr: true},
+		{name: "SafeTx delegatecall", typedData: safeTypedData(0, 1), wantErr: true},
+		{name: "unknown type", wantErr: true, typedData: []byte(`{"types":{"EIP712Domain":[{"name":"name","type":"string"}],` +
+			`"Mail":[{"name":"contents","type":"string"}]},"primaryType":"Mail","domain":{"name":"test"},"message":{"contents":"hi"}}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := walletService.SignTypedData(context.Background(), testChainType, walletID, tt.typedData)
+			if tt.wantErr && !errors.Is(err, wallet.ErrPolicyViolation) {
+				t.Fatalf("expected ErrPolicyViolation, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("SignTypedData: %v", err)
+			}
+		})
+	}
+}
+
+// 并发签名不超出每日限额，签名失败时释放预留的额度
+func TestPolicyConcurrentSigningStaysWithinDailyLimit(t *testing.T) {
+	walletService := newTestWalletService(t)
+	policyService := newTestPolicyService(t, walletService)
+	walletID, address := importDevAccount(t, walletService, 0)
+	if _, err := policyService.SetPolicy(walletID, &SpendingPolicy{
+		Limits: []AssetLimit{{Asset: PolicyAssetNative, Daily: "1000"}},
+	}); err != nil {
+		t.Fatalf("SetPolicy: %v", err)
+	}
+	ctx := context.Background()
+
+	failed := errors.New("signer unavailable")
+	if _, err := policyService.EnforceTypedData(ctx, walletID, safeTxTypedDataJSON(900), func() ([]byte, error) {
+		return nil, failed
+	}); !errors.Is(err, failed) {
+		t.Fatalf("expected the signing error, got %v", err)
+	}
+
+	// 任意两笔之和不超过限额，任意三笔之和超过限额
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	signed, denied := 0, 0
+	for i := 0; i < 5; i++ {
+		tx, err := walletService.GetWalletManager().CreateTransaction(ctx, testChainType, address,
+			"0x000000000000000000000000000000000000dEaD", big.NewInt(int64(400+i)), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := walletService.SignTransaction(ctx, testChainType, walletID, tx)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				signed++
+			case errors.Is(err, wallet.ErrPolicyViolation):
+				denied++
+			default:
+				t.Errorf("SignTransaction: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if signed != 2 || denied != 3 {
+		t.Fatalf("expected 2 signed and 3 denied, got %d and %d", signed, denied)
+	}
+}
+
+// safeTxTypedDataJSON 转出value的SafeTx签名数据
+func safeTxTypedDataJSON(value int64) []byte {
+	return []byte(`{"primaryType":"SafeTx","message":{"to":"0x000000000000000000000000000000000000dEaD","value":"` +
+		big.NewInt(value).String() + `","data":"0x","operation":"0"}}`)
+}
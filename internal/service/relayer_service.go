@@ -0,0 +1,450 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"multi-chain-wallet/internal/storage"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// 代付额度的统计窗口
+const relayBudgetWindow = 24 * time.Hour
+
+// RelayerConfig 单条链的元交易中继配置
+type RelayerConfig struct {
+	Forwarder string // 可信转发合约地址
+	WalletID  string // 提交交易并支付gas的中继钱包
+}
+
+// RelayerService EIP-2771元交易中继服务：校验用户签名的ForwardRequest，检查用户的代付额度后
+// 由中继钱包通过可信转发合约提交，交易按原始发送方记账。只为单独设置了额度的用户代付
+type RelayerService struct {
+	walletService *WalletService
+	relayStorage  *storage.MySQLRelayStorage
+	relayers      map[wallet.ChainType]RelayerConfig
+	mu            sync.Mutex // 检查额度和预留时加锁，避免并发请求超出额度
+}
+
+// NewRelayerService 创建元交易中继服务
+func NewRelayerService(walletService *WalletService, relayStorage *storage.MySQLRelayStorage, relayers map[wallet.ChainType]RelayerConfig) *RelayerService {
+	return &RelayerService{
+		walletService: walletService,
+		relayStorage:  relayStorage,
+		relayers:      relayers,
+	}
+}
+
+// ForwardRequestPreparation 待用户签名的ForwardRequest
+type ForwardRequestPreparation struct {
+	ChainType   wallet.ChainType       `json:"chainType"`
+	Forwarder   string                 `json:"forwarder"`
+	Request     *wallet.ForwardRequest `json:"request"`
+	TypedData   json.RawMessage        `json:"typedData"`
+	RequestHash string                 `json:"requestHash"`
+}
+
+// RelayedTransaction 中继交易及其上链状态
+type RelayedTransaction struct {
+	ID          string                 `json:"id"`
+	ChainType   wallet.ChainType       `json:"chainType"`
+	User        string                 `json:"user"`
+	Target      string                 `json:"target"`
+	Forwarder   string                 `json:"forwarder"`
+	Relayer     string                 `json:"relayer"`
+	RequestHash string                 `json:"requestHash"`
+	Request     *wallet.ForwardRequest `json:"request"`
+	TxHash      string                 `json:"txHash"`
+	Status      string                 `json:"status"`
+	Cost        string                 `json:"cost"`
+	CreateTime  int64                  `json:"createTime"`
+}
+
+// SponsorshipBudget 用户在统计窗口内的代付额度使用情况，金额单位为wei
+type SponsorshipBudget struct {
+	ChainType   wallet.ChainType `json:"chainType"`
+	User        string           `json:"user"`
+	DailyLimit  string           `json:"dailyLimit"`
+	Spent       string           `json:"spent"`
+	Remaining   string           `json:"remaining"`
+	Custom      bool             `json:"custom"` // 是否设置了额度，未设置的用户额度为0
+	WindowStart int64            `json:"windowStart"`
+}
+
+// getRelayer 获取链的转发合约配置和支持EIP-2771的钱包实现
+func (s *RelayerService) getRelayer(chainType wallet.ChainType) (wallet.ForwarderWallet, RelayerConfig, error) {
+	walletImpl, ok := s.walletService.GetWalletByChainType(chainType)
+	if !ok {
+		return nil, RelayerConfig{}, wallet.ErrUnsupportedChain
+	}
+
+	forwarderWallet, ok := walletImpl.(wallet.ForwarderWallet)
+	if !ok {
+		return nil, RelayerConfig{}, wallet.ErrOperationNotSupported
+	}
+	config, ok := s.relayers[chainType]
+	if !ok {
+		return nil, RelayerConfig{}, fmt.Errorf("%w: no relayer configured for %s", wallet.ErrOperationNotSupported, chainType)
+	}
+
+	return forwarderWallet, config, nil
+}
+
+// PrepareRequest 补全nonce、gas和有效期，返回待用户签名的EIP-712数据
+func (s *RelayerService) PrepareRequest(ctx context.Context, chainType wallet.ChainType, req *wallet.ForwardRequest) (*ForwardRequestPreparation, error) {
+	forwarderWallet, config, err := s.getRelayer(chainType)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRelayValue(req); err != nil {
+		return nil, err
+	}
+
+	filled, typedData, hash, err := forwarderWallet.BuildForwardRequestTypedData(ctx, config.Forwarder, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ForwardRequestPreparation{
+		ChainType:   chainType,
+		Forwarder:   config.Forwarder,
+		Request:     filled,
+		TypedData:   typedData,
+		RequestHash: hash,
+	}, nil
+}
+
+// RelayFromWallet 用托管钱包签名ForwardRequest后提交，用于没有原生代币的托管钱包
+func (s *RelayerService) RelayFromWallet(ctx context.Context, walletID string, req *wallet.ForwardRequest) (*RelayedTransaction, error) {
+	walletInfo, err := s.walletService.GetWalletInfo(walletID)
+	if err != nil {
+		return nil, err
+	}
+	req.From = walletInfo.Address
+
+	prepared, err := s.PrepareRequest(ctx, walletInfo.ChainType, req)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := s.walletService.SignTypedData(ctx, walletInfo.ChainType, walletID, prepared.TypedData)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Relay(ctx, walletInfo.ChainType, prepared.Request, signature)
+}
+
+// Relay 校验用户签名的ForwardRequest并筛查涉及的地址，在代付额度内由中继钱包提交。额度按手续费上限预留，上链后按实际费用结算。
+// 预留时写入中继交易记录，同一请求只能预留一次；签名或广播失败时删除记录释放额度
+func (s *RelayerService) Relay(ctx context.Context, chainType wallet.ChainType, req *wallet.ForwardRequest, signature []byte) (*RelayedTransaction, error) {
+	forwarderWallet, config, err := s.getRelayer(chainType)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRelayValue(req); err != nil {
+		return nil, err
+	}
+
+	requestHash, err := forwarderWallet.VerifyForwardRequest(ctx, config.Forwarder, req, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	relayerInfo, err := s.walletService.GetWalletInfo(config.WalletID)
+	if err != nil {
+		return nil, fmt.Errorf("relayer wallet unavailable: %w", err)
+	}
+	if relayerInfo.ChainType != chainType {
+		return nil, fmt.Errorf("relayer wallet is on %s, not %s", relayerInfo.ChainType, chainType)
+	}
+
+	screening, err := s.walletService.ScreenCall(req.From, req.To, req.Value, req.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := forwarderWallet.BuildForwardExecution(ctx, relayerInfo.Address, config.Forwarder, req, signature)
+	if err != nil {
+		return nil, err
+	}
+	cost := new(big.Int)
+	if tx.Fee != nil && tx.Fee.MaxAmount != nil {
+		cost.Set(tx.Fee.MaxAmount)
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize forward request: %v", err)
+	}
+	record := &storage.RelayTransaction{
+		ID:          uuid.New().String(),
+		ChainType:   string(chainType),
+		UserAddress: req.From,
+		Target:      req.To,
+		Forwarder:   config.Forwarder,
+		Relayer:     relayerInfo.Address,
+		RequestHash: requestHash,
+		Payload:     string(payload),
+		Signature:   fmt.Sprintf("0x%x", signature),
+		Status:      string(wallet.TxPending),
+		Cost:        cost.String(),
+		CreateTime:  time.Now().Unix(),
+	}
+	// 先按链上状态结算待确认的交易，加锁后只读写存储
+	s.refreshPending(ctx, chainType, req.From)
+	if err := s.reserve(record); err != nil {
+		return nil, err
+	}
+
+	signedTx, err := s.walletService.SignTransaction(ctx, chainType, config.WalletID, tx)
+	if err != nil {
+		s.release(record)
+		return nil, err
+	}
+	txHash, err := s.walletService.walletManager.SendTransaction(ctx, chainType, signedTx)
+	if err != nil {
+		s.release(record)
+		return nil, err
+	}
+	record.TxHash = txHash
+	if err := s.relayStorage.UpdateRelayTransactionHash(record.ID, txHash); err != nil {
+		// 交易已提交，记录失败不影响执行，预留的额度保留到窗口结束
+		log.Printf("Warning: Failed to save relayed transaction %s: %v", txHash, err)
+	}
+
+	// 交易记录归属中继钱包，发送方为验证过签名的原始发送方
+	transfer := &wallet.PayloadTransfer{From: req.From, To: req.To, Value: req.Value}
+	if err := s.walletService.recordTransaction(chainType, config.WalletID, txHash, transfer, screening); err != nil {
+		log.Printf("Warning: Failed to record relayed transaction %s: %v", txHash, err)
+	}
+
+	return toRelayedTransaction(record, req), nil
+}
+
+// reserve 请求未中继过且额度足够时写入中继交易记录，预留其手续费上限
+func (s *RelayerService) reserve(record *storage.RelayTransaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.relayStorage.GetRelayTransactionByRequestHash(record.RequestHash); err == nil {
+		return fmt.Errorf("%w: forward request %s already relayed", wallet.ErrInvalidTransaction, record.RequestHash)
+	}
+	chainType := wallet.ChainType(record.ChainType)
+	budget, err := s.budget(chainType, record.UserAddress)
+	if err != nil {
+		return err
+	}
+	cost, _ := new(big.Int).SetString(record.Cost, 10)
+	remaining, _ := new(big.Int).SetString(budget.Remaining, 10)
+	if cost.Cmp(remaining) > 0 {
+		return fmt.Errorf("%w: user %s needs %s wei, %s of %s wei remaining today",
+			wallet.ErrBudgetExceeded, record.UserAddress, record.Cost, budget.Remaining, budget.DailyLimit)
+	}
+	// requestHash有唯一索引，其他实例已预留同一请求时写入失败
+	if err := s.relayStorage.SaveRelayTransaction(record); err != nil {
+		return fmt.Errorf("%w: forward request %s already relayed", wallet.ErrInvalidTransaction, record.RequestHash)
+	}
+	return nil
+}
+
+// release 删除未能广播的中继交易记录
+func (s *RelayerService) release(record *storage.RelayTransaction) {
+	if err := s.relayStorage.DeleteRelayTransaction(record.ID); err != nil {
+		log.Printf("Warning: Failed to release relay reservation %s: %v", record.RequestHash, err)
+	}
+}
+
+// GetTransaction 获取中继交易，待确认时查询链上状态
+func (s *RelayerService) GetTransaction(ctx context.Context, id string) (*RelayedTransaction, error) {
+	record, err := s.relayStorage.GetRelayTransaction(id)
+	if err != nil {
+		return nil, fmt.Errorf("relayed transaction not found: %v", err)
+	}
+	if record.Status == string(wallet.TxPending) {
+		if err := s.refresh(ctx, record); err != nil {
+			return nil, err
+		}
+	}
+	return toRelayedTransactionRecord(record)
+}
+
+// ListTransactions 获取用户的中继交易
+func (s *RelayerService) ListTransactions(ctx context.Context, chainType wallet.ChainType, user string) ([]*RelayedTransaction, error) {
+	records, err := s.relayStorage.ListRelayTransactions(string(chainType), user, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relayed transactions: %v", err)
+	}
+
+	results := make([]*RelayedTransaction, 0, len(records))
+	for _, record := range records {
+		if record.Status == string(wallet.TxPending) {
+			if err := s.refresh(ctx, record); err != nil {
+				log.Printf("Warning: Failed to refresh relayed transaction %s: %v", record.TxHash, err)
+			}
+		}
+		result, err := toRelayedTransactionRecord(record)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// GetBudget 获取用户的代付额度使用情况
+func (s *RelayerService) GetBudget(ctx context.Context, chainType wallet.ChainType, user string) (*SponsorshipBudget, error) {
+	if _, _, err := s.getRelayer(chainType); err != nil {
+		return nil, err
+	}
+	s.refreshPending(ctx, chainType, user)
+	return s.budget(chainType, user)
+}
+
+// SetBudget 设置用户的每日代付额度，为0时禁止为该用户代付
+func (s *RelayerService) SetBudget(ctx context.Context, chainType wallet.ChainType, user string, dailyLimit *big.Int) (*SponsorshipBudget, error) {
+	if _, _, err := s.getRelayer(chainType); err != nil {
+		return nil, err
+	}
+	if dailyLimit.Sign() < 0 {
+		return nil, fmt.Errorf("%w: daily limit must not be negative", wallet.ErrInvalidTransaction)
+	}
+
+	if err := s.relayStorage.SaveRelayBudget(&storage.RelayBudget{
+		ChainType:   string(chainType),
+		UserAddress: strings.ToLower(user),
+		DailyLimit:  dailyLimit.String(),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save budget: %v", err)
+	}
+	return s.budget(chainType, user)
+}
+
+// refreshPending 查询用户在窗口内待确认的中继交易的链上状态，按实际费用结算
+func (s *RelayerService) refreshPending(ctx context.Context, chainType wallet.ChainType, user string) {
+	records, err := s.relayStorage.ListRelayTransactions(string(chainType), user, time.Now().Add(-relayBudgetWindow).Unix())
+	if err != nil {
+		log.Printf("Warning: Failed to get relayed transactions of %s: %v", user, err)
+		return
+	}
+	for _, record := range records {
+		if record.Status == string(wallet.TxPending) {
+			if err := s.refresh(ctx, record); err != nil {
+				log.Printf("Warning: Failed to refresh relayed transaction %s: %v", record.TxHash, err)
+			}
+		}
+	}
+}
+
+// budget 按存储的记录统计用户在窗口内已使用和预留的代付费用，不查询链上状态
+func (s *RelayerService) budget(chainType wallet.ChainType, user string) (*SponsorshipBudget, error) {
+	limit := new(big.Int)
+	setting, err := s.relayStorage.GetRelayBudget(string(chainType), strings.ToLower(user))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget: %v", err)
+	}
+	if setting != nil {
+		if _, ok := limit.SetString(setting.DailyLimit, 10); !ok {
+			return nil, fmt.Errorf("invalid stored budget for %s", user)
+		}
+	}
+
+	windowStart := time.Now().Add(-relayBudgetWindow).Unix()
+	records, err := s.relayStorage.ListRelayTransactions(string(chainType), user, windowStart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get relayed transactions: %v", err)
+	}
+	spent := new(big.Int)
+	for _, record := range records {
+		if cost, ok := new(big.Int).SetString(record.Cost, 10); ok {
+			spent.Add(spent, cost)
+		}
+	}
+
+	remaining := new(big.Int).Sub(limit, spent)
+	if remaining.Sign() < 0 {
+		remaining.SetInt64(0)
+	}
+	return &SponsorshipBudget{
+		ChainType:   chainType,
+		User:        user,
+		DailyLimit:  limit.String(),
+		Spent:       spent.String(),
+		Remaining:   remaining.String(),
+		Custom:      setting != nil,
+		WindowStart: windowStart,
+	}, nil
+}
+
+// refresh 查询中继交易的上链状态，已上链时按实际费用更新计入额度的金额。尚未广播的预留记录不查询
+func (s *RelayerService) refresh(ctx context.Context, record *storage.RelayTransaction) error {
+	if record.TxHash == "" {
+		return nil
+	}
+	chainType := wallet.ChainType(record.ChainType)
+	forwarderWallet, _, err := s.getRelayer(chainType)
+	if err != nil {
+		return err
+	}
+
+	status, err := s.walletService.GetTransactionStatus(ctx, chainType, record.TxHash)
+	if err != nil {
+		return err
+	}
+	if status == string(wallet.TxPending) {
+		return nil
+	}
+
+	fee, err := forwarderWallet.GetTransactionFee(ctx, record.TxHash)
+	if err != nil {
+		return err
+	}
+	record.Status = status
+	if fee != nil {
+		record.Cost = fee.String()
+	}
+	if err := s.relayStorage.UpdateRelayTransactionResult(record.ID, record.Status, record.Cost); err != nil {
+		return fmt.Errorf("failed to update relayed transaction: %v", err)
+	}
+	return nil
+}
+
+// checkRelayValue 中继钱包只代付gas，不为用户垫付转账金额
+func checkRelayValue(req *wallet.ForwardRequest) error {
+	if req.Value != nil && req.Value.Sign() != 0 {
+		return fmt.Errorf("%w: relayed requests must not transfer value", wallet.ErrInvalidTransaction)
+	}
+	return nil
+}
+
+func toRelayedTransactionRecord(record *storage.RelayTransaction) (*RelayedTransaction, error) {
+	var req wallet.ForwardRequest
+	if err := json.Unmarshal([]byte(record.Payload), &req); err != nil {
+		return nil, fmt.Errorf("failed to decode forward request: %v", err)
+	}
+	return toRelayedTransaction(record, &req), nil
+}
+
+func toRelayedTransaction(record *storage.RelayTransaction, req *wallet.ForwardRequest) *RelayedTransaction {
+	return &RelayedTransaction{
+		ID:          record.ID,
+		ChainType:   wallet.ChainType(record.ChainType),
+		User:        record.UserAddress,
+		Target:      record.Target,
+		Forwarder:   record.Forwarder,
+		Relayer:     record.Relayer,
+		RequestHash: record.RequestHash,
+		Request:     req,
+		TxHash:      record.TxHash,
+		Status:      record.Status,
+		Cost:        record.Cost,
+		CreateTime:  record.CreateTime,
+	}
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"multi-chain-wallet/internal/storage"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// fakeForwarderWallet 模拟链上没有转发合约：请求哈希取自nonce，execute交易为发往转发地址的普通调用
+type fakeForwarderWallet struct {
+	wallet.Wallet
+}
+
+func (f *fakeForwarderWallet) BuildForwardRequestTypedData(ctx context.Context, forwarder string, req *wallet.ForwardRequest) (*wallet.ForwardRequest, []byte, string, error) {
+	return nil, nil, "", wallet.ErrOperationNotSupported
+}
+
+func (f *fakeForwarderWallet) VerifyForwardRequest(ctx context.Context, forwarder string, req *wallet.ForwardRequest, signature []byte) (string, error) {
+	return "request-" + req.Nonce.String(), nil
+}
+
+func (f *fakeForwarderWallet) BuildForwardExecution(ctx context.Context, relayer string, forwarder string, req *wallet.ForwardRequest, signature []byte) (*wallet.UnsignedTx, error) {
+	return f.CreateTransaction(ctx, relayer, forwarder, big.NewInt(0), []byte{0x01})
+}
+
+func (f *fakeForwarderWallet) GetTransactionFee(ctx context.Context, txHash string) (*big.Int, error) {
+	return nil, nil
+}
+
+// newTestRelayerService 创建以第0个预置账户为中继钱包的中继服务，返回用户地址
+func newTestRelayerService(t *testing.T) (*RelayerService, string) {
+	t.Helper()
+	walletService := newTestWalletService(t)
+	walletImpl, _ := walletService.GetWalletByChainType(testChainType)
+	walletService.GetWalletManager().RegisterWallet(&fakeForwarderWallet{Wallet: walletImpl})
+	relayerID, _ := importDevAccount(t, walletService, 0)
+	_, user := saveTestWallet(t, testChainType)
+
+	relayStorage := storage.NewMySQLRelayStorage()
+	if err := relayStorage.InitRelayTables(); err != nil {
+		t.Fatal(err)
+	}
+	relayers := map[wallet.ChainType]RelayerConfig{
+		testChainType: {Forwarder: "0x000000000000000000000000000000000000f0f0", WalletID: relayerID},
+	}
+	return NewRelayerService(walletService, relayStorage, relayers), user
+}
+
+func testForwardRequest(user string, nonce int64) *wallet.ForwardRequest {
+	return &wallet.ForwardRequest{
+		From:  user,
+		To:    "0x000000000000000000000000000000000000dEaD",
+		Nonce: big.NewInt(nonce),
+		Gas:   50000,
+		Data:  "0x01",
+	}
+}
+
+// 未设置额度的地址不代付，同一请求只中继一次
+func TestRelayRequiresBudget(t *testing.T) {
+	s, user := newTestRelayerService(t)
+	ctx := context.Background()
+
+	if _, err := s.Relay(ctx, testChainType, testForwardRequest(user, 1), nil); !errors.Is(err, wallet.ErrBudgetExceeded) {
+		t.Fatalf("expected ErrBudgetExceeded for an address without a budget, got %v", err)
+	}
+	budget, err := s.GetBudget(ctx, testChainType, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if budget.DailyLimit != "0" || budget.Spent != "0" {
+		t.Fatalf("unexpected budget for a new address: %+v", budget)
+	}
+
+	if _, err := s.SetBudget(ctx, testChainType, user, new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)); err != nil {
+		t.Fatalf("SetBudget: %v", err)
+	}
+	relayed, err := s.Relay(ctx, testChainType, testForwardRequest(user, 1), nil)
+	if err != nil {
+		t.Fatalf("Relay: %v", err)
+	}
+	if relayed.TxHash == "" || relayed.Cost == "0" {
+		t.Fatalf("unexpected relayed transaction: %+v", relayed)
+	}
+	if _, err := s.Relay(ctx, testChainType, testForwardRequest(user, 1), nil); !errors.Is(err, wallet.ErrInvalidTransaction) {
+		t.Fatalf("expected ErrInvalidTransaction for a replayed request, got %v", err)
+	}
+}
+
+// 并发提交时额度只够一笔，只有一笔被中继
+func TestRelayConcurrentRequestsStayWithinBudget(t *testing.T) {
+	s, user := newTestRelayerService(t)
+	ctx := context.Background()
+
+	tx, err := s.walletService.GetWalletManager().CreateTransaction(ctx, testChainType, relayerAddress(t, s), "0x000000000000000000000000000000000000f0f0", big.NewInt(0), []byte{0x01})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 额度在一笔和两笔的手续费上限之间
+	limit := new(big.Int).Add(tx.Fee.MaxAmount, new(big.Int).Div(tx.Fee.MaxAmount, big.NewInt(2)))
+	if _, err := s.SetBudget(ctx, testChainType, user, limit); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	relayed, exceeded := 0, 0
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(nonce int64) {
+			defer wg.Done()
+			_, err := s.Relay(ctx, testChainType, testForwardRequest(user, nonce), nil)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				relayed++
+			case errors.Is(err, wallet.ErrBudgetExceeded):
+				exceeded++
+			default:
+				t.Errorf("Relay: %v", err)
+			}
+		}(int64(i + 10))
+	}
+	wg.Wait()
+	if relayed != 1 || exceeded != 3 {
+		t.Fatalf("expected 1 relayed and 3 over budget, got %d and %d", relayed, exceeded)
+	}
+}
+
+// relayerAddress 中继钱包地址
+func relayerAddress(t *testing.T, s *RelayerService) string {
+	t.Helper()
+	info, err := s.walletService.GetWalletInfo(s.relayers[testChainType].WalletID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Address
+}
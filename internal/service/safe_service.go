@@ -0,0 +1,461 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"multi-chain-wallet/internal/storage"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// SafeService Safe多签服务：部署Safe，创建SafeTx提案，收集托管钱包的所有者签名，达到门限后提交execTransaction
+type SafeService struct {
+	walletService *WalletService
+	safeStorage   *storage.MySQLSafeStorage
+	mu            sync.Mutex // 分配提案nonce、签名和执行时加锁，避免并发请求使用同一nonce或同一所有者重复签名
+}
+
+// NewSafeService 创建Safe多签服务
+func NewSafeService(walletService *WalletService, safeStorage *storage.MySQLSafeStorage) *SafeService {
+	return &SafeService{
+		walletService: walletService,
+		safeStorage:   safeStorage,
+	}
+}
+
+// SafeDeployment Safe部署结果
+type SafeDeployment struct {
+	Safe   string `json:"safe"`
+	TxHash string `json:"txHash"`
+}
+
+// SafeConfirmation 所有者签名
+type SafeConfirmation struct {
+	Owner      string `json:"owner"`
+	Signature  string `json:"signature"`
+	CreateTime int64  `json:"createTime"`
+}
+
+// SafeProposal Safe交易提案及签名收集情况
+type SafeProposal struct {
+	SafeTxHash    string                  `json:"safeTxHash"`
+	ChainType     wallet.ChainType        `json:"chainType"`
+	Transaction   *wallet.SafeTransaction `json:"transaction"`
+	TypedData     json.RawMessage         `json:"typedData"`
+	Status        string                  `json:"status"`
+	Confirmations []*SafeConfirmation     `json:"confirmations"`
+	ExecTxHash    string                  `json:"execTxHash,omitempty"`
+	CreateTime    int64                   `json:"createTime"`
+}
+
+// getSafeWallet 获取支持Safe的钱包实现
+func (s *SafeService) getSafeWallet(chainType wallet.ChainType) (wallet.SafeWallet, error) {
+	walletImpl, ok := s.walletService.GetWalletByChainType(chainType)
+	if !ok {
+		return nil, wallet.ErrUnsupportedChain
+	}
+
+	safeWallet, ok := walletImpl.(wallet.SafeWallet)
+	if !ok {
+		return nil, wallet.ErrOperationNotSupported
+	}
+
+	return safeWallet, nil
+}
+
+// DeploySafe 由托管钱包发送部署交易，创建指定所有者和门限的Safe
+func (s *SafeService) DeploySafe(ctx context.Context, walletID string, owners []string, threshold int, saltNonce *big.Int) (*SafeDeployment, error) {
+	walletInfo, err := s.walletService.GetWalletInfo(walletID)
+	if err != nil {
+		return nil, err
+	}
+	safeWallet, err := s.getSafeWallet(walletInfo.ChainType)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, safeAddress, err := safeWallet.BuildSafeDeployment(ctx, walletInfo.Address, owners, threshold, saltNonce)
+	if err != nil {
+		return nil, err
+	}
+	signedTx, err := s.walletService.SignTransaction(ctx, walletInfo.ChainType, walletID, tx)
+	if err != nil {
+		return nil, err
+	}
+	txHash, err := s.walletService.SendTransaction(ctx, walletInfo.ChainType, signedTx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SafeDeployment{Safe: safeAddress, TxHash: txHash}, nil
+}
+
+// GetSafeInfo 查询Safe的链上状态
+func (s *SafeService) GetSafeInfo(ctx context.Context, chainType wallet.ChainType, safeAddress string) (*wallet.SafeInfo, error) {
+	safeWallet, err := s.getSafeWallet(chainType)
+	if err != nil {
+		return nil, err
+	}
+	return safeWallet.GetSafeInfo(ctx, safeAddress)
+}
+
+// ProposeTransaction 创建SafeTx提案。nonce为空时取链上nonce与已有待签名提案之后的下一个值，
+// 指定nonce时可用于替换同一nonce的提案。DELEGATECALL会以Safe的身份执行目标合约代码，可改写所有者和门限，不予接受
+func (s *SafeService) ProposeTransaction(ctx context.Context, chainType wallet.ChainType, safeTx *wallet.SafeTransaction, nonce *uint64) (*SafeProposal, error) {
+	if err := checkSafeOperation(safeTx); err != nil {
+		return nil, err
+	}
+	safeWallet, err := s.getSafeWallet(chainType)
+	if err != nil {
+		return nil, err
+	}
+	info, err := safeWallet.GetSafeInfo(ctx, safeTx.Safe)
+	if err != nil {
+		return nil, err
+	}
+	safeTx.Safe = info.Address
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.safeStorage.ListSafeTransactions(string(chainType), info.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get safe transactions: %v", err)
+	}
+	if nonce != nil {
+		if *nonce < info.Nonce {
+			return nil, fmt.Errorf("%w: safe nonce %d already used, current nonce is %d", wallet.ErrInvalidTransaction, *nonce, info.Nonce)
+		}
+		safeTx.Nonce = *nonce
+	} else {
+		safeTx.Nonce = info.Nonce
+		for _, record := range records {
+			if record.Status == storage.SafeTxPending && record.Nonce >= safeTx.Nonce {
+				safeTx.Nonce = record.Nonce + 1
+			}
+		}
+	}
+
+	typedData, safeTxHash, err := safeWallet.BuildSafeTxTypedData(safeTx)
+	if err != nil {
+		return nil, err
+	}
+	if existing, err := s.safeStorage.GetSafeTransaction(safeTxHash); err == nil {
+		return s.toProposal(existing)
+	}
+
+	payload, err := json.Marshal(safeTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize safe transaction: %v", err)
+	}
+	record := &storage.SafeTransaction{
+		ID:         uuid.New().String(),
+		ChainType:  string(chainType),
+		Safe:       info.Address,
+		Nonce:      safeTx.Nonce,
+		SafeTxHash: safeTxHash,
+		Payload:    string(payload),
+		Status:     storage.SafeTxPending,
+		CreateTime: time.Now().Unix(),
+	}
+	if err := s.safeStorage.SaveSafeTransaction(record); err != nil {
+		return nil, fmt.Errorf("failed to save safe transaction: %v", err)
+	}
+
+	return &SafeProposal{
+		SafeTxHash:    safeTxHash,
+		ChainType:     chainType,
+		Transaction:   safeTx,
+		TypedData:     typedData,
+		Status:        record.Status,
+		Confirmations: []*SafeConfirmation{},
+		CreateTime:    record.CreateTime,
+	}, nil
+}
+
+// GetTransaction 获取交易提案
+func (s *SafeService) GetTransaction(safeTxHash string) (*SafeProposal, error) {
+	record, err := s.safeStorage.GetSafeTransaction(safeTxHash)
+	if err != nil {
+		return nil, fmt.Errorf("safe transaction not found: %v", err)
+	}
+	return s.toProposal(record)
+}
+
+// ListTransactions 获取Safe的交易提案
+func (s *SafeService) ListTransactions(chainType wallet.ChainType, safeAddress string) ([]*SafeProposal, error) {
+	records, err := s.safeStorage.ListSafeTransactions(string(chainType), safeAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get safe transactions: %v", err)
+	}
+	proposals := make([]*SafeProposal, 0, len(records))
+	for _, record := range records {
+		proposal, err := s.toProposal(record)
+		if err != nil {
+			return nil, err
+		}
+		proposals = append(proposals, proposal)
+	}
+	return proposals, nil
+}
+
+// ConfirmTransaction 用托管钱包对提案签名。钱包地址须是Safe当前的所有者，签名在保存前重新校验，
+// 同一所有者只能签名一次
+func (s *SafeService) ConfirmTransaction(ctx context.Context, safeTxHash string, walletID string) (*SafeProposal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, err := s.safeStorage.GetSafeTransaction(safeTxHash)
+	if err != nil {
+		return nil, fmt.Errorf("safe transaction not found: %v", err)
+	}
+	if record.Status != storage.SafeTxPending {
+		return nil, fmt.Errorf("%w: safe transaction is %s", wallet.ErrInvalidTransaction, strings.ToLower(record.Status))
+	}
+	chainType := wallet.ChainType(record.ChainType)
+
+	walletInfo, err := s.walletService.GetWalletInfo(walletID)
+	if err != nil {
+		return nil, err
+	}
+	if walletInfo.ChainType != chainType {
+		return nil, fmt.Errorf("%w: wallet is on %s, safe transaction is on %s", wallet.ErrInvalidTransaction, walletInfo.ChainType, chainType)
+	}
+
+	safeWallet, err := s.getSafeWallet(chainType)
+	if err != nil {
+		return nil, err
+	}
+	info, err := safeWallet.GetSafeInfo(ctx, record.Safe)
+	if err != nil {
+		return nil, err
+	}
+	if record.Nonce < info.Nonce {
+		return nil, fmt.Errorf("%w: safe nonce %d already used", wallet.ErrInvalidTransaction, record.Nonce)
+	}
+	owner, ok := findOwner(info.Owners, walletInfo.Address)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not an owner of safe %s", wallet.ErrInvalidTransaction, walletInfo.Address, record.Safe)
+	}
+
+	confirmations, err := s.safeStorage.GetSafeConfirmations(safeTxHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get confirmations: %v", err)
+	}
+	for _, confirmation := range confirmations {
+		if strings.EqualFold(confirmation.Owner, owner) {
+			return nil, fmt.Errorf("%w: owner %s already confirmed", wallet.ErrInvalidTransaction, owner)
+		}
+	}
+
+	safeTx, typedData, err := s.rebuild(safeWallet, record)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSafeOperation(safeTx); err != nil {
+		return nil, err
+	}
+	signature, err := s.walletService.SignTypedData(ctx, chainType, walletID, typedData)
+	if err != nil {
+		return nil, err
+	}
+	verification, err := s.walletService.VerifyTypedData(ctx, chainType, owner, typedData, signature)
+	if err != nil {
+		return nil, err
+	}
+	if !verification.Valid {
+		return nil, fmt.Errorf("signature was not produced by owner %s", owner)
+	}
+
+	if err := s.safeStorage.SaveSafeConfirmation(&storage.SafeConfirmation{
+		ID:         uuid.New().String(),
+		SafeTxHash: safeTxHash,
+		Owner:      owner,
+		Signature:  fmt.Sprintf("0x%x", signature),
+		CreateTime: time.Now().Unix(),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save confirmation: %v", err)
+	}
+
+	return s.toProposal(record)
+}
+
+// ExecuteTransaction 签名达到门限后，由托管钱包提交execTransaction。
+// 提案nonce必须等于Safe当前nonce，较小的nonce已被使用，较大的需等待之前的交易执行。
+// 调用涉及的地址命中拒绝名单时不执行。交易记录以Safe为发送方写入交易历史
+func (s *SafeService) ExecuteTransaction(ctx context.Context, safeTxHash string, walletID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, err := s.safeStorage.GetSafeTransaction(safeTxHash)
+	if err != nil {
+		return "", fmt.Errorf("safe transaction not found: %v", err)
+	}
+	if record.Status != storage.SafeTxPending {
+		return "", fmt.Errorf("%w: safe transaction is %s", wallet.ErrInvalidTransaction, strings.ToLower(record.Status))
+	}
+	chainType := wallet.ChainType(record.ChainType)
+
+	walletInfo, err := s.walletService.GetWalletInfo(walletID)
+	if err != nil {
+		return "", err
+	}
+	if walletInfo.ChainType != chainType {
+		return "", fmt.Errorf("%w: wallet is on %s, safe transaction is on %s", wallet.ErrInvalidTransaction, walletInfo.ChainType, chainType)
+	}
+
+	safeWallet, err := s.getSafeWallet(chainType)
+	if err != nil {
+		return "", err
+	}
+	info, err := safeWallet.GetSafeInfo(ctx, record.Safe)
+	if err != nil {
+		return "", err
+	}
+	if record.Nonce < info.Nonce {
+		return "", fmt.Errorf("%w: safe nonce %d already used", wallet.ErrInvalidTransaction, record.Nonce)
+	}
+	if record.Nonce > info.Nonce {
+		return "", fmt.Errorf("%w: safe transactions with nonce %d to %d must be executed first", wallet.ErrInvalidTransaction, info.Nonce, record.Nonce-1)
+	}
+
+	// 只使用当前所有者的签名，提案之后被移除的所有者签名无效
+	confirmations, err := s.safeStorage.GetSafeConfirmations(safeTxHash)
+	if err != nil {
+		return "", fmt.Errorf("failed to get confirmations: %v", err)
+	}
+	signatures := make(map[string][]byte, len(confirmations))
+	for _, confirmation := range confirmations {
+		if _, ok := findOwner(info.Owners, confirmation.Owner); !ok {
+			continue
+		}
+		signature, err := decodeHexSignature(confirmation.Signature)
+		if err != nil {
+			return "", err
+		}
+		signatures[confirmation.Owner] = signature
+	}
+	if len(signatures) < info.Threshold {
+		return "", fmt.Errorf("%w: %d of %d required confirmations", wallet.ErrInvalidTransaction, len(signatures), info.Threshold)
+	}
+
+	safeTx, _, err := s.rebuild(safeWallet, record)
+	if err != nil {
+		return "", err
+	}
+	if err := checkSafeOperation(safeTx); err != nil {
+		return "", err
+	}
+	screening, err := s.walletService.ScreenCall(safeTx.Safe, safeTx.To, safeTx.Value, safeTx.Data)
+	if err != nil {
+		return "", err
+	}
+	tx, err := safeWallet.BuildSafeExecTransaction(ctx, walletInfo.Address, safeTx, signatures)
+	if err != nil {
+		return "", err
+	}
+	signedTx, err := s.walletService.SignTransaction(ctx, chainType, walletID, tx)
+	if err != nil {
+		return "", err
+	}
+	txHash, err := s.walletService.walletManager.SendTransaction(ctx, chainType, signedTx)
+	if err != nil {
+		return "", err
+	}
+
+	// 交易记录归属执行的钱包，收发方和金额为Safe交易本身
+	transfer := &wallet.PayloadTransfer{From: safeTx.Safe, To: safeTx.To, Value: safeTx.Value}
+	if err := s.walletService.recordTransaction(chainType, walletID, txHash, transfer, screening); err != nil {
+		return "", err
+	}
+	if err := s.safeStorage.MarkSafeTransactionExecuted(record, txHash); err != nil {
+		return "", fmt.Errorf("failed to update safe transaction: %v", err)
+	}
+	return txHash, nil
+}
+
+// rebuild 从存储的提案重建SafeTx和签名数据，并确认哈希未变
+func (s *SafeService) rebuild(safeWallet wallet.SafeWallet, record *storage.SafeTransaction) (*wallet.SafeTransaction, []byte, error) {
+	var safeTx wallet.SafeTransaction
+	if err := json.Unmarshal([]byte(record.Payload), &safeTx); err != nil {
+		return nil, nil, fmt.Errorf("failed to deserialize safe transaction: %v", err)
+	}
+	typedData, safeTxHash, err := safeWallet.BuildSafeTxTypedData(&safeTx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if safeTxHash != record.SafeTxHash {
+		return nil, nil, errors.New("stored safe transaction does not match its hash")
+	}
+	return &safeTx, typedData, nil
+}
+
+// toProposal 转换为API响应格式
+func (s *SafeService) toProposal(record *storage.SafeTransaction) (*SafeProposal, error) {
+	safeWallet, err := s.getSafeWallet(wallet.ChainType(record.ChainType))
+	if err != nil {
+		return nil, err
+	}
+	safeTx, typedData, err := s.rebuild(safeWallet, record)
+	if err != nil {
+		return nil, err
+	}
+
+	confirmations, err := s.safeStorage.GetSafeConfirmations(record.SafeTxHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get confirmations: %v", err)
+	}
+	proposal := &SafeProposal{
+		SafeTxHash:    record.SafeTxHash,
+		ChainType:     wallet.ChainType(record.ChainType),
+		Transaction:   safeTx,
+		TypedData:     typedData,
+		Status:        record.Status,
+		Confirmations: make([]*SafeConfirmation, 0, len(confirmations)),
+		ExecTxHash:    record.ExecTxHash,
+		CreateTime:    record.CreateTime,
+	}
+	for _, confirmation := range confirmations {
+		proposal.Confirmations = append(proposal.Confirmations, &SafeConfirmation{
+			Owner:      confirmation.Owner,
+			Signature:  confirmation.Signature,
+			CreateTime: confirmation.CreateTime,
+		})
+	}
+	return proposal, nil
+}
+
+// checkSafeOperation 只接受CALL
+func checkSafeOperation(safeTx *wallet.SafeTransaction) error {
+	if safeTx.Operation != 0 {
+		return fmt.Errorf("%w: safe operation %d (delegatecall) is not allowed", wallet.ErrInvalidTransaction, safeTx.Operation)
+	}
+	return nil
+}
+
+// findOwner 在所有者列表中查找地址（不区分大小写），返回列表中的写法
+func findOwner(owners []string, address string) (string, bool) {
+	for _, owner := range owners {
+		if strings.EqualFold(owner, address) {
+			return owner, true
+		}
+	}
+	return "", false
+}
+
+func decodeHexSignature(signature string) ([]byte, error) {
+	decoded, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid stored signature: %v", err)
+	}
+	return decoded, nil
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+
+	"multi-chain-wallet/internal/storage"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// fakeSafeWallet 模拟链上没有Safe合约，GetSafeInfo返回预设的所有者和门限，其余方法使用模拟链钱包
+type fakeSafeWallet struct {
+	wallet.Wallet
+	wallet.SafeWallet
+	info wallet.SafeInfo
+}
+
+func (f *fakeSafeWallet) GetSafeInfo(ctx context.Context, safeAddress string) (*wallet.SafeInfo, error) {
+	info := f.info
+	return &info, nil
+}
+
+// newTestSafeService 创建Safe服务和由前owners个预置账户组成、门限为threshold的Safe，返回所有者的钱包ID
+func newTestSafeService(t *testing.T, owners int, threshold int) (*SafeService, *fakeSafeWallet, []string) {
+	t.Helper()
+	walletService := newTestWalletService(t)
+	walletImpl, _ := walletService.GetWalletByChainType(testChainType)
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeSafeWallet{
+		Wallet:     walletImpl,
+		SafeWallet: walletImpl.(wallet.SafeWallet),
+		info:       wallet.SafeInfo{Address: crypto.PubkeyToAddress(key.PublicKey).Hex(), Threshold: threshold},
+	}
+	walletService.GetWalletManager().RegisterWallet(fake)
+
+	walletIDs := make([]string, 0, owners)
+	for i := 0; i < owners; i++ {
+		walletID, address := importDevAccount(t, walletService, i)
+		walletIDs = append(walletIDs, walletID)
+		fake.info.Owners = append(fake.info.Owners, address)
+	}
+
+	safeStorage := storage.NewMySQLSafeStorage()
+	if err := safeStorage.InitSafeTables(); err != nil {
+		t.Fatal(err)
+	}
+	return NewSafeService(walletService, safeStorage), fake, walletIDs
+}
+
+func proposeTestSafeTx(t *testing.T, s *SafeService, safe string) *SafeProposal {
+	t.Helper()
+	proposal, err := s.ProposeTransaction(context.Background(), testChainType, &wallet.SafeTransaction{
+		Safe:  safe,
+		To:    "0x000000000000000000000000000000000000dEaD",
+		Value: big.NewInt(1),
+	}, nil)
+	if err != nil {
+		t.Fatalf("ProposeTransaction: %v", err)
+	}
+	return proposal
+}
+
+// 同一所有者并发签名只记录一次，非所有者不能签名
+func TestSafeConfirmTransactionOncePerOwner(t *testing.T) {
+	s, fake, walletIDs := newTestSafeService(t, 2, 2)
+	proposal := proposeTestSafeTx(t, s, fake.info.Address)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.ConfirmTransaction(context.Background(), proposal.SafeTxHash, walletIDs[0]); err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if succeeded != 1 {
+		t.Fatalf("expected exactly one confirmation to succeed, got %d", succeeded)
+	}
+
+	outsiderID, _ := importDevAccount(t, s.walletService, 5)
+	if _, err := s.ConfirmTransaction(context.Background(), proposal.SafeTxHash, outsiderID); !errors.Is(err, wallet.ErrInvalidTransaction) {
+		t.Fatalf("expected non-owner to be rejected, got %v", err)
+	}
+
+	got, err := s.GetTransaction(proposal.SafeTxHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Confirmations) != 1 || got.Confirmations[0].Owner != fake.info.Owners[0] {
+		t.Fatalf("unexpected confirmations: %+v", got.Confirmations)
+	}
+}
+
+// 签名未达门限时不执行，达到门限后提交execTransaction并标记为已执行
+func TestSafeExecuteTransactionRequiresThreshold(t *testing.T) {
+	s, fake, walletIDs := newTestSafeService(t, 2, 2)
+	proposal := proposeTestSafeTx(t, s, fake.info.Address)
+	ctx := context.Background()
+
+	if _, err := s.ConfirmTransaction(ctx, proposal.SafeTxHash, walletIDs[0]); err != nil {
+		t.Fatalf("ConfirmTransaction: %v", err)
+	}
+	if _, err := s.ExecuteTransaction(ctx, proposal.SafeTxHash, walletIDs[0]); !errors.Is(err, wallet.ErrInvalidTransaction) {
+		t.Fatalf("expected execution below threshold to fail, got %v", err)
+	}
+
+	if _, err := s.ConfirmTransaction(ctx, proposal.SafeTxHash, walletIDs[1]); err != nil {
+		t.Fatalf("ConfirmTransaction: %v", err)
+	}
+	txHash, err := s.ExecuteTransaction(ctx, proposal.SafeTxHash, walletIDs[0])
+	if err != nil {
+		t.Fatalf("ExecuteTransaction: %v", err)
+	}
+	got, err := s.GetTransaction(proposal.SafeTxHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != storage.SafeTxExecuted || got.ExecTxHash != txHash {
+		t.Fatalf("unexpected proposal after execution: %s %s", got.Status, got.ExecTxHash)
+	}
+}
+
+// DELEGATECALL既不能提案，已存储的提案也不能签名
+func TestSafeRejectsDelegateCall(t *testing.T) {
+	s, fake, walletIDs := newTestSafeService(t, 1, 1)
+	safeTx := &wallet.SafeTransaction{
+		Safe:      fake.info.Address,
+		To:        "0x000000000000000000000000000000000000dEaD",
+		Data:      "0x12345678",
+		Operation: 1,
+	}
+	if _, err := s.ProposeTransaction(context.Background(), testChainType, safeTx, nil); !errors.Is(err, wallet.ErrInvalidTransaction) {
+		t.Fatalf("expected delegatecall proposal to be rejected, got %v", err)
+	}
+
+	_, safeTxHash, err := fake.BuildSafeTxTypedData(safeTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, _ := json.Marshal(safeTx)
+	if err := s.safeStorage.SaveSafeTransaction(&storage.SafeTransaction{
+		ID:         uuid.New().String(),
+		ChainType:  string(testChainType),
+		Safe:       safeTx.Safe,
+		SafeTxHash: safeTxHash,
+		Payload:    string(payload),
+		Status:     storage.SafeTxPending,
+		CreateTime: time.Now().Unix(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ConfirmTransaction(context.Background(), safeTxHash, walletIDs[0]); !errors.Is(err, wallet.ErrInvalidTransaction) {
+		t.Fatalf("expected delegatecall confirmation to be rejected, got %v", err)
+	}
+}
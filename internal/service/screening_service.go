@@ -0,0 +1,294 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// 名单命中时的处理方式
+const (
+	ScreeningActionBlock = "block" // 拒绝交易
+	ScreeningActionFlag  = "flag"  // 放行交易并标记
+)
+
+// 筛查结论
+const (
+	ScreeningStatusClear   = "clear"
+	ScreeningStatusFlagged = "flagged"
+	ScreeningStatusBlocked = "blocked"
+)
+
+// minScreeningAddressLength 各链地址的最短长度（比特币P2PKH最短为25位）
+const minScreeningAddressLength = 25
+
+// sdnAddressPattern 匹配OFAC SDN导出文件中的数字货币地址，如"Digital Currency Address - XBT 1Abc..."
+var sdnAddressPattern = regexp.MustCompile(`Digital Currency Address - [A-Z0-9]+ ([A-Za-z0-9]+)`)
+
+// ScreeningList 名单文件及命中时的处理方式
+type ScreeningList struct {
+	Path   string
+	Action string
+}
+
+// ScreeningListStatus 名单的加载情况
+type ScreeningListStatus struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	Action   string `json:"action"`
+	Entries  int    `json:"entries"`
+	LoadedAt int64  `json:"loadedAt,omitempty"` // 最近一次成功加载的时间
+	Error    string `json:"error,omitempty"`    // 最近一次加载失败的原因，失败时沿用上次加载的地址
+}
+
+// ScreeningMatch 命中名单的地址
+type ScreeningMatch struct {
+	Address string `json:"address"`
+	List    string `json:"list"`
+	Action  string `json:"action"`
+}
+
+// ScreeningResult 一组地址的筛查结果
+type ScreeningResult struct {
+	Status      string            `json:"status"`
+	Addresses   []string          `json:"addresses"`
+	Matches     []*ScreeningMatch `json:"matches,omitempty"`
+	Unavailable []string          `json:"unavailableLists,omitempty"` // 从未成功加载的名单，按命中处理
+	CheckedAt   int64             `json:"checkedAt"`
+}
+
+// Blocked 是否有地址命中block名单
+func (r *ScreeningResult) Blocked() bool {
+	return r != nil && r.Status == ScreeningStatusBlocked
+}
+
+// ScreeningService 地址筛查服务，从本地名单文件加载制裁和拒绝地址并定时重新加载
+type ScreeningService struct {
+	lists    []ScreeningList
+	interval time.Duration
+	mu       sync.RWMutex
+	entries  []map[string]bool // 与lists一一对应的地址集合
+	status   []*ScreeningListStatus
+	stopChan chan struct{}
+}
+
+// NewScreeningService 创建地址筛查服务
+func NewScreeningService(lists []ScreeningList, interval time.Duration) *ScreeningService {
+	status := make([]*ScreeningListStatus, len(lists))
+	for i, list := range lists {
+		if list.Action == "" {
+			lists[i].Action = ScreeningActionBlock
+		}
+		status[i] = &ScreeningListStatus{Name: filepath.Base(list.Path), Path: list.Path, Action: lists[i].Action}
+	}
+	return &ScreeningService{
+		lists:    lists,
+		interval: interval,
+		entries:  make([]map[string]bool, len(lists)),
+		status:   status,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start 加载名单并启动定时重新加载任务
+func (s *ScreeningService) Start() {
+	s.Reload()
+	if s.interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stopChan:
+				return
+			case <-ticker.C:
+				s.Reload()
+			}
+		}
+	}()
+
+	log.Printf("Address screening reload job started, interval: %s", s.interval)
+}
+
+// Stop 停止定时重新加载任务
+func (s *ScreeningService) Stop() {
+	close(s.stopChan)
+	log.Println("Address screening reload job stopped")
+}
+
+// Reload 重新加载所有名单，单个名单加载失败时沿用上次加载的地址；
+// 从未成功加载的名单在加载成功前按所有地址都命中处理
+func (s *ScreeningService) Reload() []*ScreeningListStatus {
+	for i, list := range s.lists {
+		entries, err := loadScreeningList(list.Path)
+
+		s.mu.Lock()
+		if err != nil {
+			s.status[i].Error = err.Error()
+			log.Printf("Warning: failed to load screening list %s: %v", list.Path, err)
+		} else {
+			s.entries[i] = entries
+			s.status[i].Entries = len(entries)
+			s.status[i].LoadedAt = time.Now().Unix()
+			s.status[i].Error = ""
+		}
+		s.mu.Unlock()
+	}
+	return s.ListStatus()
+}
+
+// ListStatus 获取各名单的加载情况
+func (s *ScreeningService) ListStatus() []*ScreeningListStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*ScreeningListStatus, len(s.status))
+	for i, status := range s.status {
+		copied := *status
+		result[i] = &copied
+	}
+	return result
+}
+
+// Screen 按所有名单筛查地址，空地址和重复地址忽略。
+// 从未成功加载的名单无法确认地址不在其中，block名单拒绝、flag名单标记所有地址
+func (s *ScreeningService) Screen(addresses []string) *ScreeningResult {
+	result := &ScreeningResult{
+		Status:    ScreeningStatusClear,
+		Addresses: []string{},
+		CheckedAt: time.Now().Unix(),
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	for _, address := range addresses {
+		key := normalizeScreeningAddress(address)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result.Addresses = append(result.Addresses, strings.TrimSpace(address))
+
+		for i, list := range s.lists {
+			if s.entries[i] == nil || !s.entries[i][key] {
+				continue
+			}
+			result.Matches = append(result.Matches, &ScreeningMatch{
+				Address: strings.TrimSpace(address),
+				List:    s.status[i].Name,
+				Action:  list.Action,
+			})
+			result.apply(list.Action)
+		}
+	}
+	if len(result.Addresses) == 0 {
+		return result
+	}
+
+	for i, list := range s.lists {
+		if s.entries[i] != nil {
+			continue
+		}
+		result.Unavailable = append(result.Unavailable, s.status[i].Name)
+		result.apply(list.Action)
+	}
+	return result
+}
+
+// apply 按命中名单的处理方式更新筛查结论，block优先于flag
+func (r *ScreeningResult) apply(action string) {
+	if action == ScreeningActionBlock {
+		r.Status = ScreeningStatusBlocked
+	} else if r.Status == ScreeningStatusClear {
+		r.Status = ScreeningStatusFlagged
+	}
+}
+
+// Check 筛查地址，有地址命中block名单时返回ErrAddressBlocked及命中的地址
+func (s *ScreeningService) Check(addresses []string) (*ScreeningResult, error) {
+	result := s.Screen(addresses)
+	switch result.Status {
+	case ScreeningStatusBlocked:
+		var blocked []string
+		for _, match := range result.Matches {
+			if match.Action == ScreeningActionBlock {
+				blocked = append(blocked, fmt.Sprintf("%s (%s)", match.Address, match.List))
+			}
+		}
+		for _, list := range result.Unavailable {
+			blocked = append(blocked, fmt.Sprintf("screening list %s not loaded", list))
+		}
+		return result, fmt.Errorf("%w: %s", wallet.ErrAddressBlocked, strings.Join(blocked, ", "))
+	case ScreeningStatusFlagged:
+		for _, match := range result.Matches {
+			log.Printf("Warning: address %s flagged by screening list %s", match.Address, match.List)
+		}
+		for _, list := range result.Unavailable {
+			log.Printf("Warning: addresses %s flagged because screening list %s is not loaded", strings.Join(result.Addresses, ", "), list)
+		}
+	}
+	return result, nil
+}
+
+// loadScreeningList 读取名单文件。支持每行一个地址的文本或CSV（取第一列，#开头为注释），
+// 以及OFAC SDN导出文件（提取其中的"Digital Currency Address"）
+func loadScreeningList(path string) (map[string]bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if matches := sdnAddressPattern.FindAllStringSubmatch(line, -1); len(matches) > 0 {
+			for _, match := range matches {
+				entries[normalizeScreeningAddress(match[1])] = true
+			}
+			continue
+		}
+		fields := strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ';' || r == '\t' })
+		if len(fields) == 0 {
+			continue
+		}
+		// 第一列过短或含空白的是表头、SDN中不含地址的记录等，不是地址
+		address := strings.Trim(strings.TrimSpace(fields[0]), `"'`)
+		if len(address) < minScreeningAddressLength || strings.ContainsAny(address, " \t") {
+			continue
+		}
+		entries[normalizeScreeningAddress(address)] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// normalizeScreeningAddress EVM地址不区分大小写，其余链的地址按原样比较
+func normalizeScreeningAddress(address string) string {
+	address = strings.TrimSpace(address)
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		return strings.ToLower(address)
+	}
+	return address
+}
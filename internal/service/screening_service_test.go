@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+
+	"multi-chain-wallet/internal/storage"
+	"multi-chain-wallet/internal/wallet"
+	"multi-chain-wallet/internal/wallet/ethereum"
+)
+
+// writeScreeningList 在临时目录写入名单文件，返回文件路径
+func writeScreeningList(t *testing.T, name string, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadScreeningList(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name: "sdn export",
+			content: `36,"LAZARUS GROUP",-0- ,"DPRK3",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,"a.k.a. 'APPLEWORM'."
+41,"EXAMPLE MIXER",-0- ,"CYBER2",-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,-0- ,"Digital Currency Address - XBT 12QtD5BFwRsdNsAZY76UVE1xyCGNTojH9h; Digital Currency Address - ETH 0x098B716B8Aaf21512996dC57EB0615e2383E2f96; Website example.org."
+`,
+			want: []string{"12QtD5BFwRsdNsAZY76UVE1xyCGNTojH9h", "0x098b716b8aaf21512996dc57eb0615e2383e2f96"},
+		},
+		{
+			name: "csv",
+			content: `address,label
+"0x8589427373D6D84E98730D7795D8f6f8731FDA16",mixer
+# 注释
+TRX7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t;tron
+short,too short to be an address
+`,
+			want: []string{"0x8589427373d6d84e98730d7795d8f6f8731fda16", "TRX7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"},
+		},
+		{
+			name:    "plain text",
+			content: "\n  0x722122dF12D4e14e13Ac3b6895a86e84145b6967  \n# 0x0000000000000000000000000000000000000001\n",
+			want:    []string{"0x722122df12d4e14e13ac3b6895a86e84145b6967"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := loadScreeningList(writeScreeningList(t, "list.txt", tt.content))
+			if err != nil {
+				t.Fatalf("loadScreeningList: %v", err)
+			}
+			if len(entries) != len(tt.want) {
+				t.Fatalf("expected %d entries, got %v", len(tt.want), entries)
+			}
+			for _, address := range tt.want {
+				if !entries[address] {
+					t.Fatalf("expected %s in %v", address, entries)
+				}
+			}
+		})
+	}
+}
+
+// EVM地址不区分大小写，其他链按原样比较；block优先于flag
+func TestScreenMatchesAddresses(t *testing.T) {
+	blocked := writeScreeningList(t, "sdn.txt", "0x098B716B8Aaf21512996dC57EB0615e2383E2f96\n12QtD5BFwRsdNsAZY76UVE1xyCGNTojH9h\n")
+	flagged := writeScreeningList(t, "watch.txt", "0x722122dF12D4e14e13Ac3b6895a86e84145b6967\n")
+	s := NewScreeningService([]ScreeningList{{Path: blocked}, {Path: flagged, Action: ScreeningActionFlag}}, 0)
+	s.Reload()
+
+	clean := "0x0000000000000000000000000000000000000001"
+	tests := []struct {
+		name      string
+		addresses []string
+		status    string
+		matches   int
+	}{
+		{name: "clear", addresses: []string{clean, ""}, status: ScreeningStatusClear},
+		{name: "evm address in other case", addresses: []string{clean, "0x098b716b8aaf21512996dc57eb0615e2383e2f96"}, status: ScreeningStatusBlocked, matches: 1},
+		{name: "bitcoin address is case sensitive", addresses: []string{"12qtd5bfwrsdnsazy76uve1xycgntojh9h"}, status: ScreeningStatusClear},
+		{name: "flagged", addresses: []string{"0x722122DF12D4E14E13AC3B6895A86E84145B6967"}, status: ScreeningStatusFlagged, matches: 1},
+		{name: "block wins over flag", addresses: []string{"0x722122dF12D4e14e13Ac3b6895a86e84145b6967", "12QtD5BFwRsdNsAZY76UVE1xyCGNTojH9h"}, status: ScreeningStatusBlocked, matches: 2},
+		{name: "duplicates are screened once", addresses: []string{"12QtD5BFwRsdNsAZY76UVE1xyCGNTojH9h", " 12QtD5BFwRsdNsAZY76UVE1xyCGNTojH9h "}, status: ScreeningStatusBlocked, matches: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := s.Check(tt.addresses)
+			if result.Status != tt.status || len(result.Matches) != tt.matches {
+				t.Fatalf("expected %s with %d matches, got %+v", tt.status, tt.matches, result)
+			}
+			if blocked := errors.Is(err, wallet.ErrAddressBlocked); blocked != (tt.status == ScreeningStatusBlocked) {
+				t.Fatalf("unexpected error for %s: %v", tt.status, err)
+			}
+		})
+	}
+}
+
+// 名单从未加载成功时无法确认地址不在其中，加载成功前block名单拒绝、flag名单标记所有地址
+func TestScreenFailsClosedUntilListLoads(t *testing.T) {
+	dir := t.TempDir()
+	blocked := filepath.Join(dir, "sdn.txt")
+	flagged := filepath.Join(dir, "watch.txt")
+	s := NewScreeningService([]ScreeningList{{Path: blocked}, {Path: flagged, Action: ScreeningActionFlag}}, 0)
+	s.Reload()
+
+	address := "0x0000000000000000000000000000000000000001"
+	result, err := s.Check([]string{address})
+	if !errors.Is(err, wallet.ErrAddressBlocked) || result.Status != ScreeningStatusBlocked || len(result.Unavailable) != 2 {
+		t.Fatalf("expected every address blocked while lists are not loaded, got %+v, %v", result, err)
+	}
+	if result := s.Screen(nil); result.Status != ScreeningStatusClear {
+		t.Fatalf("expected no addresses to stay clear, got %+v", result)
+	}
+
+	if err := os.WriteFile(blocked, []byte("0x098B716B8Aaf21512996dC57EB0615e2383E2f96\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s.Reload()
+	result, err = s.Check([]string{address})
+	if err != nil || result.Status != ScreeningStatusFlagged || len(result.Unavailable) != 1 || result.Unavailable[0] != "watch.txt" {
+		t.Fatalf("expected the address flagged by the unloaded flag list, got %+v, %v", result, err)
+	}
+
+	// 加载成功后文件丢失，沿用上次加载的地址
+	if err := os.WriteFile(flagged, []byte(""), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s.Reload()
+	if err := os.Remove(blocked); err != nil {
+		t.Fatal(err)
+	}
+	status := s.Reload()
+	if status[0].Error == "" || status[0].Entries != 1 {
+		t.Fatalf("expected the load error reported with previous entries kept, got %+v", status[0])
+	}
+	if result, err := s.Check([]string{address}); err != nil || result.Status != ScreeningStatusClear {
+		t.Fatalf("expected clear, got %+v, %v", result, err)
+	}
+	if _, err := s.Check([]string{"0x098b716b8aaf21512996dc57eb0615e2383e2f96"}); !errors.Is(err, wallet.ErrAddressBlocked) {
+		t.Fatalf("expected previously loaded address blocked, got %v", err)
+	}
+}
+
+// broadcastDevCall 用第i个预置账户在链外签名一笔调用并直接广播，不经过钱包服务，返回交易哈希
+func broadcastDevCall(t *testing.T, s *WalletService, i int, to string, value int64, data []byte) string {
+	t.Helper()
+	accounts, err := ethereum.DevAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := crypto.HexToECDSA(accounts[i].PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainID := big.NewInt(ethereum.SimulatedChainID)
+	toAddress := common.HexToAddress(to)
+	tx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		GasTipCap: big.NewInt(params.GWei),
+		GasFeeCap: big.NewInt(100 * params.GWei),
+		Gas:       100000,
+		To:        &toAddress,
+		Value:     big.NewInt(value),
+		Data:      data,
+	}), types.LatestSignerForChainID(chainID), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	walletImpl, ok := s.GetWalletByChainType(testChainType)
+	if !ok {
+		t.Fatal("simulated chain not loaded")
+	}
+	txHash, err := walletImpl.SendTransaction(context.Background(), &wallet.SignedTx{ChainType: testChainType, Payload: raw})
+	if err != nil {
+		t.Fatalf("SendTransaction: %v", err)
+	}
+	return txHash
+}
+
+// 转入的转账按发送方和代币筛查，结果作为收款钱包的交易记录保存
+func TestScreenIncomingTransaction(t *testing.T) {
+	accounts, err := ethereum.DevAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := "0x00000000000000000000000000000000000000aa"
+	tests := []struct {
+		name    string
+		sender  int
+		call    func(recipient string) (string, int64, []byte)
+		token   string
+		amount  string
+		wantErr error
+	}{
+		{
+			name:   "native transfer from a blocked sender",
+			sender: 2,
+			call: func(recipient string) (string, int64, []byte) {
+				return recipient, 1000, nil
+			},
+			amount:  "1000",
+			wantErr: wallet.ErrAddressBlocked,
+		},
+		{
+			name:   "erc20 transfer from a clean sender",
+			sender: 3,
+			call: func(recipient string) (string, int64, []byte) {
+				// calldata中的收款人为小写地址
+				return token, 0, erc20Call(erc20TransferSelector, strings.ToLower(recipient), big.NewInt(500))
+			},
+			token:  token,
+			amount: "500",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestWalletService(t)
+			walletID, recipient := importDevAccount(t, s, 5)
+			list := writeScreeningList(t, "sdn.txt", accounts[2].Address+"\n")
+			screening := NewScreeningService([]ScreeningList{{Path: list}}, 0)
+			screening.Reload()
+			s.SetScreeningService(screening)
+
+			to, value, data := tt.call(recipient)
+			txHash := broadcastDevCall(t, s, tt.sender, to, value, data)
+
+			transfer, err := s.ScreenIncomingTransaction(context.Background(), testChainType, txHash)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if transfer.WalletID != walletID || !strings.EqualFold(transfer.From, accounts[tt.sender].Address) ||
+				!strings.EqualFold(transfer.Token, tt.token) || transfer.Amount != tt.amount {
+				t.Fatalf("unexpected transfer: %+v", transfer)
+			}
+
+			record, err := (&storage.MySQLTransactionStorage{}).GetTransactionByHash(txHash)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantStatus := ScreeningStatusClear
+			if tt.wantErr != nil {
+				wantStatus = ScreeningStatusBlocked
+			}
+			if record.WalletID != walletID || record.To != recipient || record.Amount != tt.amount || record.ScreeningStatus != wantStatus {
+				t.Fatalf("unexpected transaction record: %+v", record)
+			}
+		})
+	}
+}
+
+// 不是转入托管钱包的交易不做入账筛查
+func TestScreenIncomingTransactionRequiresManagedRecipient(t *testing.T) {
+	s := newTestWalletService(t)
+	screening := NewScreeningService([]ScreeningList{{Path: writeScreeningList(t, "sdn.txt", "")}}, 0)
+	screening.Reload()
+	s.SetScreeningService(screening)
+
+	txHash := broadcastDevCall(t, s, 2, "0x00000000000000000000000000000000000000bb", 1000, nil)
+	if _, err := s.ScreenIncomingTransaction(context.Background(), testChainType, txHash); !errors.Is(err, wallet.ErrWalletNotFound) {
+		t.Fatalf("expected ErrWalletNotFound, got %v", err)
+	}
+}
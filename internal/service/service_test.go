@@ -0,0 +1,81 @@
+package service
+
+import (
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+
+	"multi-chain-wallet/internal/storage"
+	"multi-chain-wallet/internal/wallet"
+	"multi-chain-wallet/internal/wallet/ethereum"
+)
+
+// 测试使用的模拟链
+const testChainType wallet.ChainType = "dev"
+
+func TestMain(m *testing.M) {
+	// 所有测试共用一个内存数据库，记录按随机ID区分
+	if err := storage.InitMemoryDB(); err != nil {
+		log.Fatalf("failed to initialize database: %v", err)
+	}
+	os.Exit(m.Run())
+}
+
+// newTestWalletService 创建只有模拟链的钱包服务。钱包地址唯一，先清空之前测试导入的钱包
+func newTestWalletService(t *testing.T) *WalletService {
+	t.Helper()
+	if err := storage.DB.Where("1 = 1").Delete(&storage.Wallet{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	manager := wallet.NewManager()
+	manager.RegisterFactory(wallet.ChainKindSimulated, ethereum.NewSimulatedFactory("test-encryption-key"))
+	if err := manager.LoadChains([]wallet.ChainInfo{{
+		ChainType:      testChainType,
+		Kind:           wallet.ChainKindSimulated,
+		ChainID:        ethereum.SimulatedChainID,
+		NativeSymbol:   "ETH",
+		NativeDecimals: 18,
+		EIP1559:        true,
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	return NewWalletService(manager, &storage.MySQLWalletStorage{}, &storage.MySQLTransactionStorage{})
+}
+
+// importDevAccount 导入第i个预置账户，返回钱包ID和地址
+func importDevAccount(t *testing.T, s *WalletService, i int) (string, string) {
+	t.Helper()
+	accounts, err := ethereum.DevAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	walletID, err := s.ImportWalletFromPrivateKey(testChainType, accounts[i].PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return walletID, accounts[i].Address
+}
+
+// saveTestWallet 直接写入随机地址的钱包记录，用于不需要私钥的测试，返回钱包ID和地址
+func saveTestWallet(t *testing.T, chainType wallet.ChainType) (string, string) {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	walletID := uuid.New().String()
+	address := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	if err := (&storage.MySQLWalletStorage{}).SaveWallet(&storage.Wallet{
+		ID:         walletID,
+		Address:    address,
+		ChainType:  string(chainType),
+		CreateTime: time.Now().Unix(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return walletID, address
+}
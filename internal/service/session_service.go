@@ -0,0 +1,480 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"multi-chain-wallet/internal/storage"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// 会话令牌前缀，便于在日志和配置中识别
+const sessionTokenPrefix = "sk_"
+
+// 会话的最长有效期
+const maxSessionTTL = 30 * 24 * time.Hour
+
+// SessionService 会话密钥服务：为托管钱包签发有范围限制、会过期的签名凭证，供机器人和外部集成使用。
+// 会话只能签名交易，签名前校验目标合约、方法、单笔value和累计额度，ERC20转账和授权另按代币额度校验
+type SessionService struct {
+	walletService  *WalletService
+	sessionStorage *storage.MySQLSessionStorage
+	mu             sync.Mutex // 校验额度和签名时加锁，避免并发签名超出累计额度
+}
+
+// NewSessionService 创建会话密钥服务
+func NewSessionService(walletService *WalletService, sessionStorage *storage.MySQLSessionStorage) *SessionService {
+	return &SessionService{
+		walletService:  walletService,
+		sessionStorage: sessionStorage,
+	}
+}
+
+// SessionScope 会话允许调用的合约，Methods为4字节函数选择器，为空时允许该合约的任意方法和原生代币转账。
+// 合约为ERC20代币时，transfer、transferFrom和approve的金额计入TokenBudget，未设置TokenBudget时不允许这些调用
+type SessionScope struct {
+	Contract      string   `json:"contract"`
+	Methods       []string `json:"methods,omitempty"`
+	MaxTokenPerTx string   `json:"maxTokenPerTx,omitempty"` // 单笔代币金额上限（最小单位），空表示不限
+	TokenBudget   string   `json:"tokenBudget,omitempty"`   // 会话内代币累计金额上限（最小单位）
+}
+
+// SessionParams 创建会话的参数，金额单位为原生代币最小单位，为空表示不限
+type SessionParams struct {
+	WalletID      string
+	Label         string
+	Scopes        []SessionScope
+	MaxValuePerTx *big.Int
+	TotalBudget   *big.Int
+	ExpiresAt     int64
+}
+
+// Session 会话及其使用情况，Token只在创建时返回
+type Session struct {
+	ID            string            `json:"id"`
+	Token         string            `json:"token,omitempty"`
+	WalletID      string            `json:"walletId"`
+	ChainType     wallet.ChainType  `json:"chainType"`
+	Label         string            `json:"label,omitempty"`
+	Scopes        []SessionScope    `json:"scopes"`
+	MaxValuePerTx string            `json:"maxValuePerTx,omitempty"`
+	TotalBudget   string            `json:"totalBudget,omitempty"`
+	Spent         string            `json:"spent"`
+	TokenSpent    map[string]string `json:"tokenSpent,omitempty"` // 代币地址（小写）到累计金额
+	TxCount       int               `json:"txCount"`
+	Status        string            `json:"status"` // active/expired/revoked
+	ExpiresAt     int64             `json:"expiresAt"`
+	RevokedAt     int64             `json:"revokedAt,omitempty"`
+	LastUsedAt    int64             `json:"lastUsedAt,omitempty"`
+	CreateTime    int64             `json:"createTime"`
+}
+
+// 会话状态
+const (
+	SessionActive  = "active"
+	SessionExpired = "expired"
+	SessionRevoked = "revoked"
+)
+
+// CreateSession 为钱包签发会话，返回的令牌只出现这一次
+func (s *SessionService) CreateSession(params *SessionParams) (*Session, error) {
+	walletInfo, err := s.walletService.GetWalletInfo(params.WalletID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getRawTransactionWallet(walletInfo.ChainType); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if params.ExpiresAt <= now.Unix() {
+		return nil, fmt.Errorf("%w: session expiry must be in the future", wallet.ErrInvalidTransaction)
+	}
+	if params.ExpiresAt > now.Add(maxSessionTTL).Unix() {
+		return nil, fmt.Errorf("%w: session expiry must be within %s", wallet.ErrInvalidTransaction, maxSessionTTL)
+	}
+	if len(params.Scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", wallet.ErrInvalidTransaction)
+	}
+	scopes, err := normalizeScopes(params.Scopes)
+	if err != nil {
+		return nil, err
+	}
+	if (params.MaxValuePerTx != nil && params.MaxValuePerTx.Sign() < 0) || (params.TotalBudget != nil && params.TotalBudget.Sign() < 0) {
+		return nil, fmt.Errorf("%w: session limits must not be negative", wallet.ErrInvalidTransaction)
+	}
+
+	token, tokenHash, err := newSessionToken()
+	if err != nil {
+		return nil, err
+	}
+	scopesJSON, err := json.Marshal(scopes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize scopes: %v", err)
+	}
+	record := &storage.SigningSession{
+		ID:            uuid.New().String(),
+		WalletID:      params.WalletID,
+		ChainType:     string(walletInfo.ChainType),
+		Label:         params.Label,
+		TokenHash:     tokenHash,
+		Scopes:        string(scopesJSON),
+		MaxValuePerTx: bigIntString(params.MaxValuePerTx),
+		TotalBudget:   bigIntString(params.TotalBudget),
+		Spent:         "0",
+		ExpiresAt:     params.ExpiresAt,
+		CreateTime:    now.Unix(),
+	}
+	if err := s.sessionStorage.SaveSession(record); err != nil {
+		return nil, fmt.Errorf("failed to save session: %v", err)
+	}
+
+	session, err := toSession(record)
+	if err != nil {
+		return nil, err
+	}
+	session.Token = token
+	return session, nil
+}
+
+// GetSession 获取会话
+func (s *SessionService) GetSession(id string) (*Session, error) {
+	record, err := s.sessionStorage.GetSession(id)
+	if err != nil {
+		return nil, fmt.Errorf("session not found: %v", err)
+	}
+	return toSession(record)
+}
+
+// ListSessions 获取钱包的会话
+func (s *SessionService) ListSessions(walletID string) ([]*Session, error) {
+	records, err := s.sessionStorage.ListSessions(walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %v", err)
+	}
+	sessions := make([]*Session, 0, len(records))
+	for _, record := range records {
+		session, err := toSession(record)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// RevokeSession 吊销会话，之后的签名请求立即被拒绝
+func (s *SessionService) RevokeSession(id string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, err := s.sessionStorage.GetSession(id)
+	if err != nil {
+		return nil, fmt.Errorf("session not found: %v", err)
+	}
+	if record.RevokedAt == 0 {
+		record.RevokedAt = time.Now().Unix()
+		if err := s.sessionStorage.RevokeSession(id, record.RevokedAt); err != nil {
+			return nil, fmt.Errorf("failed to revoke session: %v", err)
+		}
+	}
+	return toSession(record)
+}
+
+// SignTransaction 用会话令牌签名交易：解码待签名交易，确认在会话授权范围内后才交给钱包签名
+func (s *SessionService) SignTransaction(ctx context.Context, token string, tx *wallet.UnsignedTx) (*wallet.SignedTx, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, err := s.sessionStorage.GetSessionByTokenHash(hashSessionToken(token))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid session token", wallet.ErrPermissionDenied)
+	}
+	chainType := wallet.ChainType(record.ChainType)
+	if err := tx.CheckChain(chainType); err != nil {
+		return nil, fmt.Errorf("%w: %v", wallet.ErrInvalidTransaction, err)
+	}
+
+	usage, err := s.authorize(record, tx)
+	if err != nil {
+		return nil, err
+	}
+	tokenSpent, err := json.Marshal(usage.tokenSpent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize session usage: %v", err)
+	}
+
+	signedTx, err := s.walletService.SignTransaction(ctx, chainType, record.WalletID, tx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sessionStorage.RecordSessionUsage(record.ID, usage.spent.String(), string(tokenSpent), record.TxCount+1, time.Now().Unix()); err != nil {
+		// 未记账的签名不返回，避免绕过累计额度
+		return nil, fmt.Errorf("failed to record session usage: %v", err)
+	}
+	return signedTx, nil
+}
+
+// sessionUsage 签名后会话的累计value和各代币累计金额
+type sessionUsage struct {
+	spent      *big.Int
+	tokenSpent map[string]string
+}
+
+// authorize 校验会话状态和交易内容，返回签名后的累计用量
+func (s *SessionService) authorize(record *storage.SigningSession, tx *wallet.UnsignedTx) (*sessionUsage, error) {
+	switch sessionStatus(record) {
+	case SessionRevoked:
+		return nil, fmt.Errorf("%w: session %s was revoked", wallet.ErrPermissionDenied, record.ID)
+	case SessionExpired:
+		return nil, fmt.Errorf("%w: session %s expired", wallet.ErrPermissionDenied, record.ID)
+	}
+
+	// 以实际要签名的Payload为准，UnsignedTx的通用字段只用于展示
+	rawWallet, err := s.getRawTransactionWallet(wallet.ChainType(record.ChainType))
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := rawWallet.DecodeRawTransaction(tx.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if decoded.Signed {
+		return nil, fmt.Errorf("%w: transaction is already signed", wallet.ErrInvalidTransaction)
+	}
+
+	var scopes []SessionScope
+	if err := json.Unmarshal([]byte(record.Scopes), &scopes); err != nil {
+		return nil, fmt.Errorf("failed to decode session scopes: %v", err)
+	}
+	if decoded.To == "" {
+		return nil, fmt.Errorf("%w: session cannot deploy contracts", wallet.ErrPermissionDenied)
+	}
+	scope, ok := findScope(scopes, decoded.To)
+	if !ok {
+		return nil, fmt.Errorf("%w: contract %s is not allowed by session", wallet.ErrPermissionDenied, decoded.To)
+	}
+	if len(scope.Methods) > 0 && !containsFold(scope.Methods, decoded.Method) {
+		method := decoded.Method
+		if method == "" {
+			method = "native transfer"
+		}
+		return nil, fmt.Errorf("%w: method %s on %s is not allowed by session", wallet.ErrPermissionDenied, method, decoded.To)
+	}
+
+	value := decoded.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	if record.MaxValuePerTx != "" {
+		maxValue, _ := new(big.Int).SetString(record.MaxValuePerTx, 10)
+		if value.Cmp(maxValue) > 0 {
+			return nil, fmt.Errorf("%w: value %s exceeds per-transaction limit %s", wallet.ErrPermissionDenied, value, maxValue)
+		}
+	}
+	spent, ok := new(big.Int).SetString(record.Spent, 10)
+	if !ok {
+		spent = new(big.Int)
+	}
+	spent.Add(spent, value)
+	if record.TotalBudget != "" {
+		budget, _ := new(big.Int).SetString(record.TotalBudget, 10)
+		if spent.Cmp(budget) > 0 {
+			return nil, fmt.Errorf("%w: value %s exceeds remaining session budget %s", wallet.ErrPermissionDenied,
+				value, new(big.Int).Sub(budget, new(big.Int).Sub(spent, value)))
+		}
+	}
+
+	tokenSpent, err := authorizeTokenSpend(record, scope, decoded)
+	if err != nil {
+		return nil, err
+	}
+	return &sessionUsage{spent: spent, tokenSpent: tokenSpent}, nil
+}
+
+// authorizeTokenSpend 按callIntent解析ERC20 transfer、transferFrom和approve的金额，校验范围的代币额度，返回签名后各代币的累计金额
+func authorizeTokenSpend(record *storage.SigningSession, scope SessionScope, decoded *wallet.DecodedTx) (map[string]string, error) {
+	tokenSpent := make(map[string]string)
+	if record.TokenSpent != "" {
+		if err := json.Unmarshal([]byte(record.TokenSpent), &tokenSpent); err != nil {
+			return nil, fmt.Errorf("failed to decode session token usage: %v", err)
+		}
+	}
+	intent, err := callIntent(decoded.To, nil, decoded.Data)
+	if err != nil {
+		return nil, err
+	}
+	token := normalizeAsset(decoded.To)
+	amount, ok := intent.spends[token]
+	if !ok {
+		return tokenSpent, nil
+	}
+
+	if scope.TokenBudget == "" {
+		return nil, fmt.Errorf("%w: token transfers and approvals on %s require a tokenBudget in the session scope", wallet.ErrPermissionDenied, decoded.To)
+	}
+	if scope.MaxTokenPerTx != "" {
+		maxAmount, _ := new(big.Int).SetString(scope.MaxTokenPerTx, 10)
+		if amount.Cmp(maxAmount) > 0 {
+			return nil, fmt.Errorf("%w: token amount %s exceeds per-transaction limit %s", wallet.ErrPermissionDenied, amount, maxAmount)
+		}
+	}
+	total, ok := new(big.Int).SetString(tokenSpent[token], 10)
+	if !ok {
+		total = new(big.Int)
+	}
+	total.Add(total, amount)
+	budget, _ := new(big.Int).SetString(scope.TokenBudget, 10)
+	if total.Cmp(budget) > 0 {
+		return nil, fmt.Errorf("%w: token amount %s exceeds remaining session token budget %s", wallet.ErrPermissionDenied,
+			amount, new(big.Int).Sub(budget, new(big.Int).Sub(total, amount)))
+	}
+	tokenSpent[token] = total.String()
+	return tokenSpent, nil
+}
+
+// getRawTransactionWallet 会话需要解码待签名交易来校验范围，只支持能解码原始交易的链
+func (s *SessionService) getRawTransactionWallet(chainType wallet.ChainType) (wallet.RawTransactionWallet, error) {
+	walletImpl, ok := s.walletService.GetWalletByChainType(chainType)
+	if !ok {
+		return nil, wallet.ErrUnsupportedChain
+	}
+
+	rawWallet, ok := walletImpl.(wallet.RawTransactionWallet)
+	if !ok {
+		return nil, wallet.ErrOperationNotSupported
+	}
+
+	return rawWallet, nil
+}
+
+// normalizeScopes 校验合约地址和函数选择器，选择器统一为小写
+func normalizeScopes(scopes []SessionScope) ([]SessionScope, error) {
+	normalized := make([]SessionScope, 0, len(scopes))
+	for _, scope := range scopes {
+		if !isHexString(scope.Contract, 20) {
+			return nil, fmt.Errorf("%w: invalid contract address %s", wallet.ErrInvalidTransaction, scope.Contract)
+		}
+		methods := make([]string, 0, len(scope.Methods))
+		for _, method := range scope.Methods {
+			if !isHexString(method, 4) {
+				return nil, fmt.Errorf("%w: invalid method selector %s, expected 0x followed by 8 hex digits", wallet.ErrInvalidTransaction, method)
+			}
+			methods = append(methods, strings.ToLower(method))
+		}
+		for _, amount := range []string{scope.MaxTokenPerTx, scope.TokenBudget} {
+			if amount == "" {
+				continue
+			}
+			if value, ok := new(big.Int).SetString(amount, 10); !ok || value.Sign() < 0 {
+				return nil, fmt.Errorf("%w: invalid token amount %s for %s", wallet.ErrInvalidTransaction, amount, scope.Contract)
+			}
+		}
+		normalized = append(normalized, SessionScope{
+			Contract:      scope.Contract,
+			Methods:       methods,
+			MaxTokenPerTx: scope.MaxTokenPerTx,
+			TokenBudget:   scope.TokenBudget,
+		})
+	}
+	return normalized, nil
+}
+
+// isHexString 是否为0x开头、长度为size字节的十六进制字符串
+func isHexString(value string, size int) bool {
+	if !strings.HasPrefix(value, "0x") || len(value) != 2+size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(value[2:])
+	return err == nil
+}
+
+func findScope(scopes []SessionScope, contract string) (SessionScope, bool) {
+	for _, scope := range scopes {
+		if strings.EqualFold(scope.Contract, contract) {
+			return scope, true
+		}
+	}
+	return SessionScope{}, false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// newSessionToken 生成随机会话令牌及其哈希
+func newSessionToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate session token: %v", err)
+	}
+	token := sessionTokenPrefix + hex.EncodeToString(buf)
+	return token, hashSessionToken(token), nil
+}
+
+func hashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func sessionStatus(record *storage.SigningSession) string {
+	if record.RevokedAt != 0 {
+		return SessionRevoked
+	}
+	if record.ExpiresAt <= time.Now().Unix() {
+		return SessionExpired
+	}
+	return SessionActive
+}
+
+func bigIntString(v *big.Int) string {
+	if v == nil {
+		return ""
+	}
+	return v.String()
+}
+
+func toSession(record *storage.SigningSession) (*Session, error) {
+	var scopes []SessionScope
+	if err := json.Unmarshal([]byte(record.Scopes), &scopes); err != nil {
+		return nil, fmt.Errorf("failed to decode session scopes: %v", err)
+	}
+	var tokenSpent map[string]string
+	if record.TokenSpent != "" {
+		if err := json.Unmarshal([]byte(record.TokenSpent), &tokenSpent); err != nil {
+			return nil, fmt.Errorf("failed to decode session token usage: %v", err)
+		}
+	}
+	return &Session{
+		ID:            record.ID,
+		WalletID:      record.WalletID,
+		ChainType:     wallet.ChainType(record.ChainType),
+		Label:         record.Label,
+		Scopes:        scopes,
+		MaxValuePerTx: record.MaxValuePerTx,
+		TotalBudget:   record.TotalBudget,
+		Spent:         record.Spent,
+		TokenSpent:    tokenSpent,
+		TxCount:       record.TxCount,
+		Status:        sessionStatus(record),
+		ExpiresAt:     record.ExpiresAt,
+		RevokedAt:     record.RevokedAt,
+		LastUsedAt:    record.LastUsedAt,
+		CreateTime:    record.CreateTime,
+	}, nil
+}
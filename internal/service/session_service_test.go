@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"multi-chain-wallet/internal/storage"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// newTestSessionService 创建会话服务和第0个预置账户的钱包
+func newTestSessionService(t *testing.T) (*SessionService, string, string) {
+	t.Helper()
+	walletService := newTestWalletService(t)
+	walletID, address := importDevAccount(t, walletService, 0)
+	sessionStorage := storage.NewMySQLSessionStorage()
+	if err := sessionStorage.InitSessionTable(); err != nil {
+		t.Fatal(err)
+	}
+	return NewSessionService(walletService, sessionStorage), walletID, address
+}
+
+// erc20Call 构造ERC20 transfer或approve的calldata
+func erc20Call(selector string, to string, amount *big.Int) []byte {
+	data := common.FromHex(selector)
+	data = append(data, common.LeftPadBytes(common.HexToAddress(to).Bytes(), 32)...)
+	return append(data, common.LeftPadBytes(amount.Bytes(), 32)...)
+}
+
+// ERC20转账和授权按代币额度计入，超出单笔或累计额度时拒绝签名
+func TestSessionLimitsTokenTransfersAndApprovals(t *testing.T) {
+	s, walletID, address := newTestSessionService(t)
+	ctx := context.Background()
+	token := "0x00000000000000000000000000000000000070c0"
+	recipient := "0x000000000000000000000000000000000000dEaD"
+	session, err := s.CreateSession(&SessionParams{
+		WalletID:  walletID,
+		Scopes:    []SessionScope{{Contract: token, MaxTokenPerTx: "600", TokenBudget: "1000"}},
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	sign := func(data []byte) error {
+		tx, err := s.walletService.GetWalletManager().CreateTransaction(ctx, testChainType, address, token, big.NewInt(0), data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = s.SignTransaction(ctx, session.Token, tx)
+		return err
+	}
+
+	if err := sign(erc20Call("0xa9059cbb", recipient, big.NewInt(500))); err != nil {
+		t.Fatalf("transfer within budget: %v", err)
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "transfer over per-transaction limit", data: erc20Call("0xa9059cbb", recipient, big.NewInt(601))},
+		{name: "transfer over remaining budget", data: erc20Call("0xa9059cbb", recipient, big.NewInt(501))},
+		{name: "unlimited approve", data: erc20Call("0x095ea7b3", recipient, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1)))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := sign(tt.data); !errors.Is(err, wallet.ErrPermissionDenied) {
+				t.Fatalf("expected ErrPermissionDenied, got %v", err)
+			}
+		})
+	}
+	if err := sign(erc20Call("0x095ea7b3", recipient, big.NewInt(500))); err != nil {
+		t.Fatalf("approve within budget: %v", err)
+	}
+
+	got, err := s.GetSession(session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spent := got.TokenSpent[token]; spent != "1000" || got.TxCount != 2 {
+		t.Fatalf("unexpected token usage: %v, %d transactions", got.TokenSpent, got.TxCount)
+	}
+}
+
+// 范围未设置代币额度时不允许转移或授权代币
+func TestSessionRejectsTokenTransferWithoutTokenBudget(t *testing.T) {
+	s, walletID, address := newTestSessionService(t)
+	ctx := context.Background()
+	token := "0x00000000000000000000000000000000000070c0"
+	session, err := s.CreateSession(&SessionParams{
+		WalletID:    walletID,
+		Scopes:      []SessionScope{{Contract: token}},
+		TotalBudget: big.NewInt(1000),
+		ExpiresAt:   time.Now().Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	for _, selector := range []string{"0xa9059cbb", "0x095ea7b3"} {
+		tx, err := s.walletService.GetWalletManager().CreateTransaction(ctx, testChainType, address, token, big.NewInt(0),
+			erc20Call(selector, "0x000000000000000000000000000000000000dEaD", big.NewInt(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.SignTransaction(ctx, session.Token, tx); !errors.Is(err, wallet.ErrPermissionDenied) {
+			t.Fatalf("%s: expected ErrPermissionDenied, got %v", selector, err)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"multi-chain-wallet/internal/storage"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// SmartAccountService ERC-4337智能账户服务：托管钱包作为智能账户的所有者签名UserOperation，通过bundler提交
+type SmartAccountService struct {
+	walletService *WalletService
+	userOpStorage *storage.MySQLUserOperationStorage
+}
+
+// NewSmartAccountService 创建智能账户服务
+func NewSmartAccountService(walletService *WalletService, userOpStorage *storage.MySQLUserOperationStorage) *SmartAccountService {
+	return &SmartAccountService{
+		walletService: walletService,
+		userOpStorage: userOpStorage,
+	}
+}
+
+// UserOperationResult UserOperation及其提交、执行状态
+type UserOperationResult struct {
+	UserOpHash    string                `json:"userOpHash"`
+	ChainType     wallet.ChainType      `json:"chainType"`
+	UserOperation *wallet.UserOperation `json:"userOperation"`
+	Status        string                `json:"status,omitempty"` // 仅构建未提交时为空
+	TxHash        string                `json:"txHash,omitempty"`
+	BlockNumber   uint64                `json:"blockNumber,omitempty"`
+	GasCost       string                `json:"gasCost,omitempty"`
+	CreateTime    int64                 `json:"createTime,omitempty"`
+}
+
+// getSmartAccountWallet 获取支持ERC-4337的钱包实现
+func (s *SmartAccountService) getSmartAccountWallet(chainType wallet.ChainType) (wallet.SmartAccountWallet, error) {
+	walletImpl, ok := s.walletService.GetWalletByChainType(chainType)
+	if !ok {
+		return nil, wallet.ErrUnsupportedChain
+	}
+
+	accountWallet, ok := walletImpl.(wallet.SmartAccountWallet)
+	if !ok {
+		return nil, wallet.ErrOperationNotSupported
+	}
+
+	return accountWallet, nil
+}
+
+// GetAccount 获取托管钱包作为所有者的智能账户，未部署时返回反事实地址，首个UserOperation会同时部署账户
+func (s *SmartAccountService) GetAccount(ctx context.Context, walletID string, salt *big.Int) (*wallet.SmartAccount, error) {
+	walletInfo, err := s.walletService.GetWalletInfo(walletID)
+	if err != nil {
+		return nil, err
+	}
+	accountWallet, err := s.getSmartAccountWallet(walletInfo.ChainType)
+	if err != nil {
+		return nil, err
+	}
+	return accountWallet.GetSmartAccount(ctx, walletInfo.Address, salt)
+}
+
+// BuildUserOperation 构建未签名的UserOperation并估算gas，paymaster不为空时由paymaster代付gas
+func (s *SmartAccountService) BuildUserOperation(ctx context.Context, walletID string, salt *big.Int, calls []wallet.SmartAccountCall, paymaster *wallet.Paymaster) (*UserOperationResult, error) {
+	walletInfo, err := s.walletService.GetWalletInfo(walletID)
+	if err != nil {
+		return nil, err
+	}
+	accountWallet, err := s.getSmartAccountWallet(walletInfo.ChainType)
+	if err != nil {
+		return nil, err
+	}
+
+	op, err := accountWallet.BuildUserOperation(ctx, walletInfo.Address, salt, calls, paymaster)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := accountWallet.UserOperationHash(op)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserOperationResult{
+		UserOpHash:    hash,
+		ChainType:     walletInfo.ChainType,
+		UserOperation: op,
+	}, nil
+}
+
+// SendUserOperation 用所有者钱包签名并提交UserOperation，签名经过WalletService的支出策略校验。
+// op为BuildUserOperation的结果（可能已由paymaster服务补充数据），其sender必须是该钱包在salt下的智能账户
+func (s *SmartAccountService) SendUserOperation(ctx context.Context, walletID string, salt *big.Int, op *wallet.UserOperation) (*UserOperationResult, error) {
+	walletInfo, err := s.walletService.GetWalletInfo(walletID)
+	if err != nil {
+		return nil, err
+	}
+	accountWallet, err := s.getSmartAccountWallet(walletInfo.ChainType)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := accountWallet.GetSmartAccount(ctx, walletInfo.Address, salt)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(op.Sender, account.Address) {
+		return nil, fmt.Errorf("%w: sender %s is not the smart account %s of wallet %s", wallet.ErrInvalidTransaction, op.Sender, account.Address, walletID)
+	}
+
+	// 筛查智能账户要执行的每个调用，命中拒绝名单时不签名
+	calls, err := accountWallet.DecodeUserOperationCalls(op)
+	if err != nil {
+		return nil, err
+	}
+	for _, call := range calls {
+		if _, err := s.walletService.ScreenCall(op.Sender, call.To, call.Value, call.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	signed, err := s.walletService.SignUserOperation(ctx, walletInfo.ChainType, walletID, op)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := accountWallet.SendUserOperation(ctx, signed)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(signed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize user operation: %v", err)
+	}
+	record := &storage.UserOperation{
+		ID:         uuid.New().String(),
+		ChainType:  string(walletInfo.ChainType),
+		UserOpHash: hash,
+		Sender:     account.Address,
+		WalletID:   walletID,
+		Payload:    string(payload),
+		Status:     storage.UserOpPending,
+		CreateTime: time.Now().Unix(),
+	}
+	if err := s.userOpStorage.SaveUserOperation(record); err != nil {
+		// UserOperation已提交，记录失败不影响执行
+		log.Printf("Warning: Failed to save user operation %s: %v", hash, err)
+	}
+
+	return toUserOperationResult(record, signed), nil
+}
+
+// GetUserOperation 获取UserOperation，待打包时向bundler查询执行结果并更新记录
+func (s *SmartAccountService) GetUserOperation(ctx context.Context, userOpHash string) (*UserOperationResult, error) {
+	record, err := s.userOpStorage.GetUserOperation(userOpHash)
+	if err != nil {
+		return nil, fmt.Errorf("user operation not found: %v", err)
+	}
+
+	if record.Status == storage.UserOpPending {
+		if err := s.refresh(ctx, record); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.toResult(record)
+}
+
+// ListUserOperations 获取托管钱包在salt下的智能账户提交过的UserOperation
+func (s *SmartAccountService) ListUserOperations(ctx context.Context, walletID string, salt *big.Int) ([]*UserOperationResult, error) {
+	walletInfo, err := s.walletService.GetWalletInfo(walletID)
+	if err != nil {
+		return nil, err
+	}
+	account, err := s.GetAccount(ctx, walletID, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := s.userOpStorage.ListUserOperations(string(walletInfo.ChainType), account.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user operations: %v", err)
+	}
+	results := make([]*UserOperationResult, 0, len(records))
+	for _, record := range records {
+		result, err := s.toResult(record)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// refresh 向bundler查询收据，已上链时更新记录
+func (s *SmartAccountService) refresh(ctx context.Context, record *storage.UserOperation) error {
+	accountWallet, err := s.getSmartAccountWallet(wallet.ChainType(record.ChainType))
+	if err != nil {
+		return err
+	}
+	receipt, err := accountWallet.GetUserOperationReceipt(ctx, record.UserOpHash)
+	if err != nil || receipt == nil {
+		return err
+	}
+
+	record.Status = storage.UserOpSuccess
+	if !receipt.Success {
+		record.Status = storage.UserOpFailed
+	}
+	record.TxHash = receipt.TxHash
+	record.BlockNumber = receipt.BlockNumber
+	if receipt.ActualGasCost != nil {
+		record.GasCost = receipt.ActualGasCost.String()
+	}
+	if err := s.userOpStorage.UpdateUserOperationResult(record.UserOpHash, record.Status, record.TxHash, record.BlockNumber, record.GasCost); err != nil {
+		return fmt.Errorf("failed to update user operation: %v", err)
+	}
+	return nil
+}
+
+func (s *SmartAccountService) toResult(record *storage.UserOperation) (*UserOperationResult, error) {
+	var op wallet.UserOperation
+	if err := json.Unmarshal([]byte(record.Payload), &op); err != nil {
+		return nil, fmt.Errorf("failed to decode user operation: %v", err)
+	}
+	return toUserOperationResult(record, &op), nil
+}
+
+func toUserOperationResult(record *storage.UserOperation, op *wallet.UserOperation) *UserOperationResult {
+	return &UserOperationResult{
+		UserOpHash:    record.UserOpHash,
+		ChainType:     wallet.ChainType(record.ChainType),
+		UserOperation: op,
+		Status:        record.Status,
+		TxHash:        record.TxHash,
+		BlockNumber:   record.BlockNumber,
+		GasCost:       record.GasCost,
+		CreateTime:    record.CreateTime,
+	}
+}
@@ -0,0 +1,963 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"multi-chain-wallet/internal/storage"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// 审批令牌前缀
+const approverTokenPrefix = "ak_"
+
+// 审批请求的默认和最长有效期
+const (
+	defaultTxApprovalTTL = 24 * time.Hour
+	maxTxApprovalTTL     = 7 * 24 * time.Hour
+)
+
+// 审批请求的签名内容类型
+const (
+	TxApprovalKindTransaction   = "transaction"
+	TxApprovalKindTypedData     = "typed_data"
+	TxApprovalKindUserOperation = "user_operation"
+	TxApprovalKindRuleChange    = "rule_change"
+)
+
+// 审批人的表态
+const (
+	TxApprovalDecisionApprove = "approve"
+	TxApprovalDecisionReject  = "reject"
+)
+
+// 审计日志中的操作
+const (
+	txAuditRuleUpdated   = "rule_updated"
+	txAuditRuleDeleted   = "rule_deleted"
+	txAuditSuperseded    = "superseded"
+	txAuditCreated       = "created"
+	txAuditApproved      = "approved"
+	txAuditRejected      = "rejected"
+	txAuditQuorumReached = "quorum_reached"
+	txAuditExpired       = "expired"
+	txAuditSignBlocked   = "sign_blocked"
+	txAuditSigned        = "signed"
+	txAuditSendBlocked   = "send_blocked"
+	txAuditSent          = "sent"
+)
+
+// 系统操作和管理接口在审计日志中的操作人
+const (
+	txAuditSystemActor = "system"
+	txAuditAdminActor  = "admin"
+)
+
+// TxApprovalService 交易审批（maker-checker）服务。钱包设置审批规则后，交易、EIP-712数据和UserOperation
+// 须经规则中的N个审批人批准才能签名，签名结果也只有对应已批准请求的才能发送，修改或删除规则也须经现有审批人批准，
+// 每一步都写入审计日志
+type TxApprovalService struct {
+	walletService   *WalletService
+	approvalStorage *storage.MySQLTxApprovalStorage
+	mu              sync.Mutex // 表态、签名和过期处理时加锁，避免状态被并发改写
+	stopChan        chan struct{}
+}
+
+// NewTxApprovalService 创建交易审批服务
+func NewTxApprovalService(walletService *WalletService, approvalStorage *storage.MySQLTxApprovalStorage) *TxApprovalService {
+	return &TxApprovalService{
+		walletService:   walletService,
+		approvalStorage: approvalStorage,
+		stopChan:        make(chan struct{}),
+	}
+}
+
+// TxApprovalRuleParams 设置审批规则的参数，TTL为审批请求的有效期（秒），为0时使用默认值
+type TxApprovalRuleParams struct {
+	Approvers []string
+	Threshold int
+	TTL       int64
+}
+
+// TxApprovalRule 钱包的审批规则
+type TxApprovalRule struct {
+	WalletID   string            `json:"walletId"`
+	ChainType  wallet.ChainType  `json:"chainType"`
+	Address    string            `json:"address"`
+	Threshold  int               `json:"threshold"`
+	TTL        int64             `json:"ttl"`
+	Approvers  []*TxApproverInfo `json:"approvers"`
+	CreateTime int64             `json:"createTime"`
+	UpdatedAt  int64             `json:"updatedAt"`
+}
+
+// TxApproverInfo 审批人，Token只在签发时返回
+type TxApproverInfo struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Token string `json:"token,omitempty"`
+}
+
+// TxApprovalRuleChange 设置或删除规则的结果。钱包尚无规则时Rule为立即生效的规则；已有规则时Request为待现有审批人批准的变更请求。
+// IssuedTokens为本次新签发令牌的审批人，令牌不会再次返回
+type TxApprovalRuleChange struct {
+	Rule         *TxApprovalRule    `json:"rule,omitempty"`
+	Request      *TxApprovalRequest `json:"request,omitempty"`
+	IssuedTokens []*TxApproverInfo  `json:"issuedTokens,omitempty"`
+}
+
+// TxApprovalRuleProposal 规则变更请求的内容
+type TxApprovalRuleProposal struct {
+	Delete    bool     `json:"delete,omitempty"`
+	Threshold int      `json:"threshold,omitempty"`
+	TTL       int64    `json:"ttl,omitempty"`
+	Approvers []string `json:"approvers,omitempty"`
+}
+
+// txApprovalRuleProposal 规则变更请求中保存的内容，审批人只保存令牌哈希，保留的审批人沿用原记录
+type txApprovalRuleProposal struct {
+	Delete    bool                  `json:"delete,omitempty"`
+	Threshold int                   `json:"threshold,omitempty"`
+	TTL       int64                 `json:"ttl,omitempty"`
+	Approvers []*storage.TxApprover `json:"approvers,omitempty"`
+}
+
+// TxApprovalRequest 审批请求及表态情况
+type TxApprovalRequest struct {
+	ID            string                  `json:"id"`
+	WalletID      string                  `json:"walletId"`
+	ChainType     wallet.ChainType        `json:"chainType"`
+	Kind          string                  `json:"kind"`
+	Tx            *wallet.UnsignedTx      `json:"tx,omitempty"`
+	TypedData     json.RawMessage         `json:"typedData,omitempty"`
+	UserOperation *wallet.UserOperation   `json:"userOperation,omitempty"`
+	RuleChange    *TxApprovalRuleProposal `json:"ruleChange,omitempty"`
+	RequestedBy   string                  `json:"requestedBy,omitempty"`
+	Note          string                  `json:"note,omitempty"`
+	Threshold     int                     `json:"threshold"`
+	Approvals     int                     `json:"approvals"`
+	Status        string                  `json:"status"`
+	ExpiresAt     int64                   `json:"expiresAt"`
+	TxHash        string                  `json:"txHash,omitempty"`
+	Votes         []*TxApprovalVote       `json:"votes"`
+	Audit         []*TxApprovalAuditEntry `json:"audit,omitempty"`
+	CreateTime    int64                   `json:"createTime"`
+}
+
+// TxApprovalVote 审批人的表态
+type TxApprovalVote struct {
+	ApproverID string `json:"approverId"`
+	Approver   string `json:"approver"`
+	Decision   string `json:"decision"`
+	Comment    string `json:"comment,omitempty"`
+	CreateTime int64  `json:"createTime"`
+}
+
+// TxApprovalAuditEntry 审计日志条目
+type TxApprovalAuditEntry struct {
+	RequestID  string `json:"requestId,omitempty"`
+	Actor      string `json:"actor"`
+	Action     string `json:"action"`
+	Detail     string `json:"detail,omitempty"`
+	CreateTime int64  `json:"createTime"`
+}
+
+// Start 启动过期处理任务
+func (s *TxApprovalService) Start() {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.stopChan:
+				return
+			case <-ticker.C:
+				s.expireRequests()
+			}
+		}
+	}()
+
+	log.Println("Transaction approval expiry job started")
+}
+
+// Stop 停止过期处理任务
+func (s *TxApprovalService) Stop() {
+	close(s.stopChan)
+	log.Println("Transaction approval expiry job stopped")
+}
+
+// SetRule 设置钱包的审批规则。钱包尚无规则时立即生效并为每个审批人签发令牌；已有规则时创建规则变更请求，
+// 须经现有审批人按现有法定人数批准才生效，保留的审批人沿用原令牌，只为新审批人签发令牌。已有请求保持创建时的法定人数
+func (s *TxApprovalService) SetRule(walletID string, params *TxApprovalRuleParams) (*TxApprovalRuleChange, error) {
+	walletInfo, err := s.walletService.GetWalletInfo(walletID)
+	if err != nil {
+		return nil, wallet.ErrWalletNotFound
+	}
+
+	if len(params.Approvers) == 0 {
+		return nil, fmt.Errorf("%w: at least one approver is required", wallet.ErrInvalidTransaction)
+	}
+	seen := make(map[string]bool)
+	for _, name := range params.Approvers {
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("%w: approver name must not be empty", wallet.ErrInvalidTransaction)
+		}
+		if seen[strings.ToLower(name)] {
+			return nil, fmt.Errorf("%w: duplicate approver %s", wallet.ErrInvalidTransaction, name)
+		}
+		seen[strings.ToLower(name)] = true
+	}
+	if params.Threshold < 1 || params.Threshold > len(params.Approvers) {
+		return nil, fmt.Errorf("%w: threshold must be between 1 and %d", wallet.ErrInvalidTransaction, len(params.Approvers))
+	}
+	ttl := time.Duration(params.TTL) * time.Second
+	if ttl == 0 {
+		ttl = defaultTxApprovalTTL
+	}
+	if ttl < 0 || ttl > maxTxApprovalTTL {
+		return nil, fmt.Errorf("%w: ttl must be within %s", wallet.ErrInvalidTransaction, maxTxApprovalTTL)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, err := s.approvalStorage.GetRule(walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approval rule: %v", err)
+	}
+	detail := fmt.Sprintf("%d of %s, ttl %ds", params.Threshold, strings.Join(params.Approvers, ","), int64(ttl/time.Second))
+	if rule != nil {
+		current, err := s.approvalStorage.ListApprovers(walletID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get approvers: %v", err)
+		}
+		approvers, issued, err := issueApprovers(walletID, params.Approvers, current)
+		if err != nil {
+			return nil, err
+		}
+		request, err := s.proposeRuleChange(rule, &txApprovalRuleProposal{
+			Threshold: params.Threshold,
+			TTL:       int64(ttl / time.Second),
+			Approvers: approvers,
+		}, detail)
+		if err != nil {
+			return nil, err
+		}
+		return &TxApprovalRuleChange{Request: request, IssuedTokens: issued}, nil
+	}
+
+	rule = &storage.TxApprovalRule{
+		WalletID:   walletID,
+		ChainType:  string(walletInfo.ChainType),
+		Address:    walletInfo.Address,
+		Threshold:  params.Threshold,
+		TTL:        int64(ttl / time.Second),
+		CreateTime: time.Now().Unix(),
+	}
+	approvers, issued, err := issueApprovers(walletID, params.Approvers, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.approvalStorage.SaveRule(rule, approvers); err != nil {
+		return nil, fmt.Errorf("failed to save approval rule: %v", err)
+	}
+	s.audit(walletID, "", txAuditAdminActor, txAuditRuleUpdated, detail)
+
+	return &TxApprovalRuleChange{Rule: toTxApprovalRule(rule, approvers), IssuedTokens: issued}, nil
+}
+
+// GetRule 获取钱包的审批规则，未设置时返回nil
+func (s *TxApprovalService) GetRule(walletID string) (*TxApprovalRule, error) {
+	rule, err := s.approvalStorage.GetRule(walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approval rule: %v", err)
+	}
+	if rule == nil {
+		return nil, nil
+	}
+	approvers, err := s.approvalStorage.ListApprovers(walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approvers: %v", err)
+	}
+	return toTxApprovalRule(rule, approvers), nil
+}
+
+// DeleteRule 创建删除钱包审批规则的变更请求，经现有审批人批准后该钱包签名不再需要审批。未设置规则时返回nil
+func (s *TxApprovalService) DeleteRule(walletID string) (*TxApprovalRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, err := s.approvalStorage.GetRule(walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approval rule: %v", err)
+	}
+	if rule == nil {
+		return nil, nil
+	}
+	return s.proposeRuleChange(rule, &txApprovalRuleProposal{Delete: true}, "delete rule")
+}
+
+// RequestTransaction 发送方钱包设置了审批规则时为交易创建审批请求，未设置时返回nil。
+// requesterToken为发起人的审批令牌，据此记录发起人，为空时请求没有发起人。同一交易已有未结束的请求时返回该请求
+func (s *TxApprovalService) RequestTransaction(chainType wallet.ChainType, from string, tx *wallet.UnsignedTx, requesterToken string, note string) (*TxApprovalRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, err := s.approvalStorage.GetRuleByAddress(string(chainType), from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approval rule: %v", err)
+	}
+	if rule == nil {
+		return nil, nil
+	}
+
+	var requester *storage.TxApprover
+	if requesterToken != "" {
+		requester, err = s.approvalStorage.GetApproverByTokenHash(hashSessionToken(requesterToken))
+		if err != nil || requester.WalletID != rule.WalletID {
+			return nil, fmt.Errorf("%w: invalid requester token", wallet.ErrPermissionDenied)
+		}
+	}
+
+	content, err := json.Marshal(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize transaction: %v", err)
+	}
+	record, _, err := s.openRequest(rule, TxApprovalKindTransaction, content, payloadDigest(tx.Payload), requester, note)
+	if err != nil {
+		return nil, err
+	}
+	return s.toTxApprovalRequest(record, false)
+}
+
+// Approve 审批人批准请求，批准数达到法定人数后请求可以签名
+func (s *TxApprovalService) Approve(id string, token string, comment string) (*TxApprovalRequest, error) {
+	return s.vote(id, token, TxApprovalDecisionApprove, comment)
+}
+
+// Reject 审批人拒绝请求，任一审批人拒绝即终止请求
+func (s *TxApprovalService) Reject(id string, token string, comment string) (*TxApprovalRequest, error) {
+	return s.vote(id, token, TxApprovalDecisionReject, comment)
+}
+
+// GetRequest 获取审批请求及其表态和审计日志
+func (s *TxApprovalService) GetRequest(id string) (*TxApprovalRequest, error) {
+	record, err := s.approvalStorage.GetRequest(id)
+	if err != nil {
+		return nil, fmt.Errorf("approval request not found: %s", id)
+	}
+	return s.toTxApprovalRequest(s.refreshExpiry(record), true)
+}
+
+// ListRequests 获取钱包的审批请求，status为空时返回全部
+func (s *TxApprovalService) ListRequests(walletID string, status string) ([]*TxApprovalRequest, error) {
+	records, err := s.approvalStorage.ListRequests(walletID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approval requests: %v", err)
+	}
+
+	requests := make([]*TxApprovalRequest, 0, len(records))
+	for _, record := range records {
+		request, err := s.toTxApprovalRequest(s.refreshExpiry(record), false)
+		if err != nil {
+			return nil, err
+		}
+		if status != "" && request.Status != status {
+			continue
+		}
+		requests = append(requests, request)
+	}
+	return requests, nil
+}
+
+// ListAudit 获取钱包在since之后的审计日志
+func (s *TxApprovalService) ListAudit(walletID string, since int64) ([]*TxApprovalAuditEntry, error) {
+	entries, err := s.approvalStorage.ListWalletAudit(walletID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approval audit: %v", err)
+	}
+	return toTxApprovalAudit(entries), nil
+}
+
+// EnforceTransaction 钱包设置了审批规则时，只有对应已批准请求的交易才调用sign签名；
+// 尚无请求时自动创建，未批准时返回ErrApprovalRequired及请求ID
+func (s *TxApprovalService) EnforceTransaction(ctx context.Context, walletID string, tx *wallet.UnsignedTx, sign func() (*wallet.SignedTx, error)) (*wallet.SignedTx, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, err := s.approvalStorage.GetRule(walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approval rule: %v", err)
+	}
+	if rule == nil {
+		return sign()
+	}
+
+	content, err := json.Marshal(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize transaction: %v", err)
+	}
+	record, err := s.approvedRequest(rule, TxApprovalKindTransaction, content, payloadDigest(tx.Payload))
+	if err != nil {
+		return nil, err
+	}
+
+	signedTx, err := sign()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.markSigned(record, payloadDigest(signedTx.Payload), signedTx.Hash); err != nil {
+		// 未记录签名结果的交易无法通过发送检查，不返回
+		return nil, err
+	}
+	return signedTx, nil
+}
+
+// EnforceTypedData 钱包设置了审批规则时，只有对应已批准请求的EIP-712数据才调用sign签名
+func (s *TxApprovalService) EnforceTypedData(ctx context.Context, walletID string, typedDataJSON []byte, sign func() ([]byte, error)) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, err := s.approvalStorage.GetRule(walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approval rule: %v", err)
+	}
+	if rule == nil {
+		return sign()
+	}
+
+	if !json.Valid(typedDataJSON) {
+		return nil, fmt.Errorf("%w: invalid typed data", wallet.ErrInvalidTransaction)
+	}
+	record, err := s.approvedRequest(rule, TxApprovalKindTypedData, typedDataJSON, payloadDigest(typedDataJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := sign()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.markSigned(record, payloadDigest(signature), hex.EncodeToString(signature)); err != nil {
+		return nil, err
+	}
+	return signature, nil
+}
+
+// EnforceUserOperation 钱包设置了审批规则时，只有对应已批准请求的UserOperation才调用sign签名，请求按userOpHash匹配
+func (s *TxApprovalService) EnforceUserOperation(ctx context.Context, walletID string, userOpHash string, op *wallet.UserOperation, sign func() (*wallet.UserOperation, error)) (*wallet.UserOperation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule, err := s.approvalStorage.GetRule(walletID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approval rule: %v", err)
+	}
+	if rule == nil {
+		return sign()
+	}
+
+	unsigned := *op
+	unsigned.Signature = ""
+	content, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize user operation: %v", err)
+	}
+	record, err := s.approvedRequest(rule, TxApprovalKindUserOperation, content, payloadDigest([]byte(userOpHash)))
+	if err != nil {
+		return nil, err
+	}
+
+	signed, err := sign()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.markSigned(record, payloadDigest([]byte(signed.Signature)), userOpHash); err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
+
+// CheckSend 发送方钱包设置了审批规则时，确认已签名交易来自已批准的请求，返回请求ID；未设置规则时返回空
+func (s *TxApprovalService) CheckSend(chainType wallet.ChainType, signedTx *wallet.SignedTx) (string, error) {
+	from := signedTx.From
+	if walletImpl, ok := s.walletService.GetWalletByChainType(chainType); ok {
+		// 能解码的链以签名恢复的发送方为准，不信任请求中的from
+		if rawWallet, ok := walletImpl.(wallet.RawTransactionWallet); ok {
+			decoded, err := rawWallet.DecodeRawTransaction(signedTx.Payload)
+			if err != nil {
+				return "", err
+			}
+			from = decoded.From
+		}
+	}
+
+	rule, err := s.approvalStorage.GetRuleByAddress(string(chainType), from)
+	if err != nil {
+		return "", fmt.Errorf("failed to get approval rule: %v", err)
+	}
+	if rule == nil {
+		return "", nil
+	}
+
+	record, err := s.approvalStorage.FindRequestBySignedHash(payloadDigest(signedTx.Payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to get approval request: %v", err)
+	}
+	if record == nil || record.WalletID != rule.WalletID {
+		s.audit(rule.WalletID, "", txAuditSystemActor, txAuditSendBlocked, "signed transaction does not match an approved request")
+		return "", fmt.Errorf("%w: signed transaction does not match an approved request", wallet.ErrApprovalRequired)
+	}
+	return record.ID, nil
+}
+
+// MarkSent 记录已批准请求的交易已发送
+func (s *TxApprovalService) MarkSent(id string, txHash string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, err := s.approvalStorage.GetRequest(id)
+	if err != nil {
+		log.Printf("Warning: failed to get approval request %s: %v", id, err)
+		return
+	}
+	if err := s.approvalStorage.UpdateRequest(id, map[string]interface{}{
+		"status":  storage.TxApprovalSent,
+		"tx_hash": txHash,
+	}); err != nil {
+		log.Printf("Warning: failed to mark approval request %s sent: %v", id, err)
+		return
+	}
+	s.audit(record.WalletID, id, txAuditSystemActor, txAuditSent, txHash)
+}
+
+// vote 记录审批人的表态并推进请求状态
+func (s *TxApprovalService) vote(id string, token string, decision string, comment string) (*TxApprovalRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, err := s.approvalStorage.GetRequest(id)
+	if err != nil {
+		return nil, fmt.Errorf("approval request not found: %s", id)
+	}
+	approver, err := s.approvalStorage.GetApproverByTokenHash(hashSessionToken(token))
+	if err != nil || approver.WalletID != record.WalletID {
+		return nil, fmt.Errorf("%w: invalid approver token", wallet.ErrPermissionDenied)
+	}
+	if record.RequesterID != "" && record.RequesterID == approver.ID {
+		return nil, fmt.Errorf("%w: %s created this request and cannot review it", wallet.ErrPermissionDenied, approver.Name)
+	}
+	record = s.refreshExpiry(record)
+	if record.Status != storage.TxApprovalPending {
+		return nil, fmt.Errorf("%w: request %s is %s", wallet.ErrInvalidTransaction, id, record.Status)
+	}
+	votes, err := s.approvalStorage.GetVotes(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get votes: %v", err)
+	}
+	for _, v := range votes {
+		if v.ApproverID == approver.ID {
+			return nil, fmt.Errorf("%w: %s already voted on request %s", wallet.ErrInvalidTransaction, approver.Name, id)
+		}
+	}
+
+	now := time.Now().Unix()
+	if err := s.approvalStorage.SaveVote(&storage.TxApprovalVote{
+		RequestID:    id,
+		ApproverID:   approver.ID,
+		ApproverName: approver.Name,
+		Decision:     decision,
+		Comment:      comment,
+		CreateTime:   now,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save vote: %v", err)
+	}
+
+	if decision == TxApprovalDecisionReject {
+		s.audit(record.WalletID, id, approver.Name, txAuditRejected, comment)
+		if err := s.approvalStorage.UpdateRequest(id, map[string]interface{}{"status": storage.TxApprovalRejected}); err != nil {
+			return nil, fmt.Errorf("failed to update approval request: %v", err)
+		}
+	} else {
+		s.audit(record.WalletID, id, approver.Name, txAuditApproved, comment)
+		if countApprovals(votes)+1 >= record.Threshold {
+			if err := s.approvalStorage.UpdateRequest(id, map[string]interface{}{"status": storage.TxApprovalApproved}); err != nil {
+				return nil, fmt.Errorf("failed to update approval request: %v", err)
+			}
+			s.audit(record.WalletID, id, txAuditSystemActor, txAuditQuorumReached,
+				fmt.Sprintf("%d of %d approvals", countApprovals(votes)+1, record.Threshold))
+			if record.Kind == TxApprovalKindRuleChange {
+				if err := s.applyRuleChange(record); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	record, err = s.approvalStorage.GetRequest(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approval request: %v", err)
+	}
+	return s.toTxApprovalRequest(record, true)
+}
+
+// approvedRequest 返回与待签名数据对应的已批准请求。没有可用请求时创建新请求，并返回ErrApprovalRequired
+func (s *TxApprovalService) approvedRequest(rule *storage.TxApprovalRule, kind string, content []byte, payloadHash string) (*storage.TxApprovalRequest, error) {
+	record, created, err := s.openRequest(rule, kind, content, payloadHash, nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	switch record.Status {
+	case storage.TxApprovalApproved, storage.TxApprovalSigned, storage.TxApprovalSent:
+		return record, nil
+	}
+	if created {
+		s.audit(rule.WalletID, record.ID, txAuditSystemActor, txAuditSignBlocked, "no approved request, created one")
+		return nil, fmt.Errorf("%w: created approval request %s, %d approvals needed", wallet.ErrApprovalRequired, record.ID, record.Threshold)
+	}
+	votes, err := s.approvalStorage.GetVotes(record.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get votes: %v", err)
+	}
+	s.audit(rule.WalletID, record.ID, txAuditSystemActor, txAuditSignBlocked, "request is "+record.Status)
+	return nil, fmt.Errorf("%w: request %s is pending with %d of %d approvals", wallet.ErrApprovalRequired,
+		record.ID, countApprovals(votes), record.Threshold)
+}
+
+// openRequest 返回同一待签名数据未结束的请求，已拒绝、已过期或不存在时创建新请求。requester为空时请求没有发起人
+func (s *TxApprovalService) openRequest(rule *storage.TxApprovalRule, kind string, content []byte, payloadHash string, requester *storage.TxApprover, note string) (*storage.TxApprovalRequest, bool, error) {
+	existing, err := s.approvalStorage.FindRequestByPayload(rule.WalletID, payloadHash)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get approval request: %v", err)
+	}
+	if existing != nil {
+		existing = s.refreshExpiry(existing)
+		if existing.Status != storage.TxApprovalRejected && existing.Status != storage.TxApprovalExpired {
+			return existing, false, nil
+		}
+	}
+
+	var requestedBy, requesterID string
+	if requester != nil {
+		requestedBy, requesterID = requester.Name, requester.ID
+	} else if kind == TxApprovalKindRuleChange {
+		requestedBy = txAuditAdminActor
+	}
+	now := time.Now()
+	record := &storage.TxApprovalRequest{
+		ID:          uuid.New().String(),
+		WalletID:    rule.WalletID,
+		ChainType:   rule.ChainType,
+		Kind:        kind,
+		Content:     string(content),
+		PayloadHash: payloadHash,
+		RequestedBy: requestedBy,
+		RequesterID: requesterID,
+		Note:        note,
+		Threshold:   rule.Threshold,
+		Status:      storage.TxApprovalPending,
+		ExpiresAt:   now.Add(time.Duration(rule.TTL) * time.Second).Unix(),
+		CreateTime:  now.Unix(),
+	}
+	if err := s.approvalStorage.SaveRequest(record); err != nil {
+		return nil, false, fmt.Errorf("failed to save approval request: %v", err)
+	}
+	actor := requestedBy
+	if actor == "" {
+		actor = txAuditSystemActor
+	}
+	s.audit(rule.WalletID, record.ID, actor, txAuditCreated, note)
+	return record, true, nil
+}
+
+// proposeRuleChange 创建规则变更请求，取代该钱包尚未批准的其他规则变更请求
+func (s *TxApprovalService) proposeRuleChange(rule *storage.TxApprovalRule, proposal *txApprovalRuleProposal, detail string) (*TxApprovalRequest, error) {
+	pending, err := s.approvalStorage.ListRequests(rule.WalletID, storage.TxApprovalPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approval requests: %v", err)
+	}
+	for _, record := range pending {
+		if record.Kind != TxApprovalKindRuleChange {
+			continue
+		}
+		if err := s.approvalStorage.UpdateRequest(record.ID, map[string]interface{}{"status": storage.TxApprovalRejected}); err != nil {
+			return nil, fmt.Errorf("failed to update approval request: %v", err)
+		}
+		s.audit(rule.WalletID, record.ID, txAuditAdminActor, txAuditSuperseded, "replaced by a newer rule change")
+	}
+
+	content, err := json.Marshal(proposal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize rule change: %v", err)
+	}
+	record, _, err := s.openRequest(rule, TxApprovalKindRuleChange, content, payloadDigest(content), nil, detail)
+	if err != nil {
+		return nil, err
+	}
+	return s.toTxApprovalRequest(record, false)
+}
+
+// applyRuleChange 规则变更请求达到法定人数后应用变更
+func (s *TxApprovalService) applyRuleChange(record *storage.TxApprovalRequest) error {
+	var proposal txApprovalRuleProposal
+	if err := json.Unmarshal([]byte(record.Content), &proposal); err != nil {
+		return fmt.Errorf("failed to decode rule change: %v", err)
+	}
+	rule, err := s.approvalStorage.GetRule(record.WalletID)
+	if err != nil {
+		return fmt.Errorf("failed to get approval rule: %v", err)
+	}
+	if rule == nil {
+		return fmt.Errorf("%w: wallet %s no longer has an approval rule", wallet.ErrInvalidTransaction, record.WalletID)
+	}
+
+	if proposal.Delete {
+		if err := s.approvalStorage.DeleteRule(record.WalletID); err != nil {
+			return fmt.Errorf("failed to delete approval rule: %v", err)
+		}
+		s.audit(record.WalletID, record.ID, txAuditSystemActor, txAuditRuleDeleted, "")
+	} else {
+		rule.Threshold = proposal.Threshold
+		rule.TTL = proposal.TTL
+		if err := s.approvalStorage.SaveRule(rule, proposal.Approvers); err != nil {
+			return fmt.Errorf("failed to save approval rule: %v", err)
+		}
+		s.audit(record.WalletID, record.ID, txAuditSystemActor, txAuditRuleUpdated, record.Note)
+	}
+	if err := s.approvalStorage.UpdateRequest(record.ID, map[string]interface{}{"status": storage.TxApprovalApplied}); err != nil {
+		return fmt.Errorf("failed to update approval request: %v", err)
+	}
+	return nil
+}
+
+// markSigned 记录签名结果，发送时据此确认交易已获批准
+func (s *TxApprovalService) markSigned(record *storage.TxApprovalRequest, signedHash string, detail string) error {
+	if record.Status != storage.TxApprovalApproved {
+		return nil
+	}
+	if err := s.approvalStorage.UpdateRequest(record.ID, map[string]interface{}{
+		"status":      storage.TxApprovalSigned,
+		"signed_hash": signedHash,
+	}); err != nil {
+		return fmt.Errorf("failed to update approval request: %v", err)
+	}
+	s.audit(record.WalletID, record.ID, txAuditSystemActor, txAuditSigned, detail)
+	return nil
+}
+
+// refreshExpiry 待审批或待签名的请求过期时更新状态
+func (s *TxApprovalService) refreshExpiry(record *storage.TxApprovalRequest) *storage.TxApprovalRequest {
+	if record.Status != storage.TxApprovalPending && record.Status != storage.TxApprovalApproved {
+		return record
+	}
+	if record.ExpiresAt > time.Now().Unix() {
+		return record
+	}
+	if err := s.approvalStorage.UpdateRequest(record.ID, map[string]interface{}{"status": storage.TxApprovalExpired}); err != nil {
+		log.Printf("Warning: failed to expire approval request %s: %v", record.ID, err)
+		return record
+	}
+	s.audit(record.WalletID, record.ID, txAuditSystemActor, txAuditExpired, "was "+record.Status)
+	record.Status = storage.TxApprovalExpired
+	return record
+}
+
+// expireRequests 处理已过有效期的请求
+func (s *TxApprovalService) expireRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.approvalStorage.ListExpiredRequests(time.Now().Unix())
+	if err != nil {
+		log.Printf("Failed to list expired approval requests: %v", err)
+		return
+	}
+	for _, record := range records {
+		s.refreshExpiry(record)
+	}
+}
+
+// audit 追加审计日志，写入失败只记录日志
+func (s *TxApprovalService) audit(walletID string, requestID string, actor string, action string, detail string) {
+	if err := s.approvalStorage.AppendAudit(&storage.TxApprovalAudit{
+		RequestID:  requestID,
+		WalletID:   walletID,
+		Actor:      actor,
+		Action:     action,
+		Detail:     detail,
+		CreateTime: time.Now().Unix(),
+	}); err != nil {
+		log.Printf("Warning: failed to write approval audit %s for %s: %v", action, requestID, err)
+	}
+}
+
+func (s *TxApprovalService) toTxApprovalRequest(record *storage.TxApprovalRequest, withAudit bool) (*TxApprovalRequest, error) {
+	votes, err := s.approvalStorage.GetVotes(record.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get votes: %v", err)
+	}
+
+	request := &TxApprovalRequest{
+		ID:          record.ID,
+		WalletID:    record.WalletID,
+		ChainType:   wallet.ChainType(record.ChainType),
+		Kind:        record.Kind,
+		RequestedBy: record.RequestedBy,
+		Note:        record.Note,
+		Threshold:   record.Threshold,
+		Approvals:   countApprovals(votes),
+		Status:      record.Status,
+		ExpiresAt:   record.ExpiresAt,
+		TxHash:      record.TxHash,
+		Votes:       make([]*TxApprovalVote, 0, len(votes)),
+		CreateTime:  record.CreateTime,
+	}
+	switch record.Kind {
+	case TxApprovalKindTypedData:
+		request.TypedData = json.RawMessage(record.Content)
+	case TxApprovalKindRuleChange:
+		var proposal txApprovalRuleProposal
+		if err := json.Unmarshal([]byte(record.Content), &proposal); err != nil {
+			return nil, fmt.Errorf("failed to decode rule change: %v", err)
+		}
+		request.RuleChange = &TxApprovalRuleProposal{Delete: proposal.Delete, Threshold: proposal.Threshold, TTL: proposal.TTL}
+		for _, approver := range proposal.Approvers {
+			request.RuleChange.Approvers = append(request.RuleChange.Approvers, approver.Name)
+		}
+	case TxApprovalKindUserOperation:
+		var op wallet.UserOperation
+		if err := json.Unmarshal([]byte(record.Content), &op); err != nil {
+			return nil, fmt.Errorf("failed to decode approval request user operation: %v", err)
+		}
+		request.UserOperation = &op
+	default:
+		var tx wallet.UnsignedTx
+		if err := json.Unmarshal([]byte(record.Content), &tx); err != nil {
+			return nil, fmt.Errorf("failed to decode approval request transaction: %v", err)
+		}
+		request.Tx = &tx
+	}
+	for _, v := range votes {
+		request.Votes = append(request.Votes, &TxApprovalVote{
+			ApproverID: v.ApproverID,
+			Approver:   v.ApproverName,
+			Decision:   v.Decision,
+			Comment:    v.Comment,
+			CreateTime: v.CreateTime,
+		})
+	}
+	if withAudit {
+		entries, err := s.approvalStorage.ListRequestAudit(record.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get approval audit: %v", err)
+		}
+		request.Audit = toTxApprovalAudit(entries)
+	}
+	return request, nil
+}
+
+func toTxApprovalRule(rule *storage.TxApprovalRule, approvers []*storage.TxApprover) *TxApprovalRule {
+	result := &TxApprovalRule{
+		WalletID:   rule.WalletID,
+		ChainType:  wallet.ChainType(rule.ChainType),
+		Address:    rule.Address,
+		Threshold:  rule.Threshold,
+		TTL:        rule.TTL,
+		Approvers:  make([]*TxApproverInfo, 0, len(approvers)),
+		CreateTime: rule.CreateTime,
+		UpdatedAt:  rule.UpdatedAt.Unix(),
+	}
+	for _, approver := range approvers {
+		result.Approvers = append(result.Approvers, &TxApproverInfo{ID: approver.ID, Name: approver.Name})
+	}
+	return result
+}
+
+func toTxApprovalAudit(entries []*storage.TxApprovalAudit) []*TxApprovalAuditEntry {
+	result := make([]*TxApprovalAuditEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, &TxApprovalAuditEntry{
+			RequestID:  entry.RequestID,
+			Actor:      entry.Actor,
+			Action:     entry.Action,
+			Detail:     entry.Detail,
+			CreateTime: entry.CreateTime,
+		})
+	}
+	return result
+}
+
+func countApprovals(votes []*storage.TxApprovalVote) int {
+	count := 0
+	for _, v := range votes {
+		if v.Decision == TxApprovalDecisionApprove {
+			count++
+		}
+	}
+	return count
+}
+
+// issueApprovers 按名单生成审批人，与current中同名的审批人沿用原记录和令牌，其他审批人签发新令牌。
+// 返回审批人记录和新签发的令牌
+func issueApprovers(walletID string, names []string, current []*storage.TxApprover) ([]*storage.TxApprover, []*TxApproverInfo, error) {
+	now := time.Now().Unix()
+	approvers := make([]*storage.TxApprover, 0, len(names))
+	var issued []*TxApproverInfo
+	for _, name := range names {
+		if existing := findApprover(current, name); existing != nil {
+			approvers = append(approvers, existing)
+			continue
+		}
+		token, tokenHash, err := newApproverToken()
+		if err != nil {
+			return nil, nil, err
+		}
+		approver := &storage.TxApprover{
+			ID:         uuid.New().String(),
+			WalletID:   walletID,
+			Name:       name,
+			TokenHash:  tokenHash,
+			CreateTime: now,
+		}
+		approvers = append(approvers, approver)
+		issued = append(issued, &TxApproverInfo{ID: approver.ID, Name: name, Token: token})
+	}
+	return approvers, issued, nil
+}
+
+func findApprover(approvers []*storage.TxApprover, name string) *storage.TxApprover {
+	for _, approver := range approvers {
+		if strings.EqualFold(approver.Name, name) {
+			return approver
+		}
+	}
+	return nil
+}
+
+// newApproverToken 生成随机审批令牌及其哈希
+func newApproverToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate approver token: %v", err)
+	}
+	token := approverTokenPrefix + hex.EncodeToString(buf)
+	return token, hashSessionToken(token), nil
+}
+
+// payloadDigest 待签名数据或签名结果的SHA-256
+func payloadDigest(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"multi-chain-wallet/internal/storage"
+	"multi-chain-wallet/internal/wallet"
+)
+
+// newTestTxApprovalService 创建审批服务，并为第0个预置账户设置审批规则，返回钱包ID、地址和各审批人的令牌。
+// 规则按地址匹配，先清空之前测试为同一地址设置的规则
+func newTestTxApprovalService(t *testing.T, threshold int, approvers ...string) (*TxApprovalService, string, string, map[string]string) {
+	t.Helper()
+	walletService := newTestWalletService(t)
+	approvalStorage := storage.NewMySQLTxApprovalStorage()
+	if err := approvalStorage.InitTxApprovalTables(); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.DB.Where("1 = 1").Delete(&storage.TxApprovalRule{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	s := NewTxApprovalService(walletService, approvalStorage)
+	walletService.SetTxApprovalService(s)
+
+	walletID, address := importDevAccount(t, walletService, 0)
+	change, err := s.SetRule(walletID, &TxApprovalRuleParams{Approvers: approvers, Threshold: threshold})
+	if err != nil {
+		t.Fatalf("SetRule: %v", err)
+	}
+	if change.Rule == nil || change.Request != nil {
+		t.Fatalf("expected the first rule to apply immediately, got %+v", change)
+	}
+	return s, walletID, address, issuedTokens(change)
+}
+
+func issuedTokens(change *TxApprovalRuleChange) map[string]string {
+	tokens := make(map[string]string)
+	for _, approver := range change.IssuedTokens {
+		tokens[approver.Name] = approver.Token
+	}
+	return tokens
+}
+
+// createApprovalTx 创建转出value的交易及其审批请求
+func createApprovalTx(t *testing.T, s *TxApprovalService, address string, value int64, requesterToken string) (*wallet.UnsignedTx, *TxApprovalRequest) {
+	t.Helper()
+	tx, err := s.walletService.GetWalletManager().CreateTransaction(context.Background(), testChainType, address,
+		"0x000000000000000000000000000000000000dEaD", big.NewInt(value), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request, err := s.RequestTransaction(testChainType, address, tx, requesterToken, "")
+	if err != nil {
+		t.Fatalf("RequestTransaction: %v", err)
+	}
+	return tx, request
+}
+
+// 批准数达到法定人数前不能签名，每个审批人只能表态一次
+func TestTxApprovalThreshold(t *testing.T) {
+	s, walletID, address, tokens := newTestTxApprovalService(t, 2, "alice", "bob", "carol")
+	ctx := context.Background()
+	tx, request := createApprovalTx(t, s, address, 1, "")
+
+	if _, err := s.walletService.SignTransaction(ctx, testChainType, walletID, tx); !errors.Is(err, wallet.ErrApprovalRequired) {
+		t.Fatalf("expected ErrApprovalRequired without approvals, got %v", err)
+	}
+	if _, err := s.Approve(request.ID, tokens["alice"], ""); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if _, err := s.Approve(request.ID, tokens["alice"], ""); !errors.Is(err, wallet.ErrInvalidTransaction) {
+		t.Fatalf("expected a second vote to be rejected, got %v", err)
+	}
+	if _, err := s.walletService.SignTransaction(ctx, testChainType, walletID, tx); !errors.Is(err, wallet.ErrApprovalRequired) {
+		t.Fatalf("expected ErrApprovalRequired below threshold, got %v", err)
+	}
+
+	approved, err := s.Approve(request.ID, tokens["bob"], "")
+	if err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if approved.Status != storage.TxApprovalApproved || approved.Approvals != 2 {
+		t.Fatalf("unexpected request after quorum: %s with %d approvals", approved.Status, approved.Approvals)
+	}
+	if _, err := s.walletService.SignTransaction(ctx, testChainType, walletID, tx); err != nil {
+		t.Fatalf("SignTransaction: %v", err)
+	}
+	got, err := s.GetRequest(request.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != storage.TxApprovalSigned {
+		t.Fatalf("expected the request to be signed, got %s", got.Status)
+	}
+}
+
+// 任一审批人拒绝即终止请求，之后不能再批准，签名时创建新请求
+func TestTxApprovalRejection(t *testing.T) {
+	s, walletID, address, tokens := newTestTxApprovalService(t, 1, "alice", "bob")
+	tx, request := createApprovalTx(t, s, address, 2, "")
+
+	rejected, err := s.Reject(request.ID, tokens["alice"], "wrong recipient")
+	if err != nil {
+		t.Fatalf("Reject: %v", err)
+	}
+	if rejected.Status != storage.TxApprovalRejected {
+		t.Fatalf("expected the request to be rejected, got %s", rejected.Status)
+	}
+	if _, err := s.Approve(request.ID, tokens["bob"], ""); !errors.Is(err, wallet.ErrInvalidTransaction) {
+		t.Fatalf("expected approving a rejected request to fail, got %v", err)
+	}
+	_, err = s.walletService.SignTransaction(context.Background(), testChainType, walletID, tx)
+	if !errors.Is(err, wallet.ErrApprovalRequired) {
+		t.Fatalf("expected ErrApprovalRequired after rejection, got %v", err)
+	}
+}
+
+// 过期的请求不能再批准
+func TestTxApprovalExpiry(t *testing.T) {
+	s, _, address, tokens := newTestTxApprovalService(t, 1, "alice")
+	_, request := createApprovalTx(t, s, address, 3, "")
+	if err := s.approvalStorage.UpdateRequest(request.ID, map[string]interface{}{
+		"expires_at": time.Now().Add(-time.Second).Unix(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Approve(request.ID, tokens["alice"], ""); !errors.Is(err, wallet.ErrInvalidTransaction) {
+		t.Fatalf("expected approving an expired request to fail, got %v", err)
+	}
+	got, err := s.GetRequest(request.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != storage.TxApprovalExpired || got.Approvals != 0 {
+		t.Fatalf("expected an expired request without approvals, got %s with %d", got.Status, got.Approvals)
+	}
+}
+
+// 发起人凭审批令牌认证，不能审批自己的请求
+func TestTxApprovalSelfApproval(t *testing.T) {
+	s, _, address, tokens := newTestTxApprovalService(t, 1, "alice", "bob")
+	_, request := createApprovalTx(t, s, address, 4, tokens["alice"])
+	if request.RequestedBy != "alice" {
+		t.Fatalf("expected the requester to be alice, got %q", request.RequestedBy)
+	}
+
+	if _, err := s.Approve(request.ID, tokens["alice"], ""); !errors.Is(err, wallet.ErrPermissionDenied) {
+		t.Fatalf("expected self-approval to be denied, got %v", err)
+	}
+	if _, err := s.Approve(request.ID, tokens["bob"], ""); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+
+	tx, err := s.walletService.GetWalletManager().CreateTransaction(context.Background(), testChainType, address,
+		"0x000000000000000000000000000000000000dEaD", big.NewInt(5), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RequestTransaction(testChainType, address, tx, "ak_unknown", ""); !errors.Is(err, wallet.ErrPermissionDenied) {
+		t.Fatalf("expected an unknown requester token to be rejected, got %v", err)
+	}
+}
+
+// 修改和删除已有规则须经现有审批人按现有法定人数批准，保留的审批人沿用原令牌
+func TestTxApprovalRuleChangeRequiresQuorum(t *testing.T) {
+	s, walletID, _, tokens := newTestTxApprovalService(t, 2, "alice", "bob")
+
+	change, err := s.SetRule(walletID, &TxApprovalRuleParams{Approvers: []string{"alice", "dave"}, Threshold: 1})
+	if err != nil {
+		t.Fatalf("SetRule: %v", err)
+	}
+	issued := issuedTokens(change)
+	if change.Rule != nil || change.Request == nil || len(issued) != 1 || issued["dave"] == "" {
+		t.Fatalf("expected a pending rule change issuing a token only for dave, got %+v", change)
+	}
+	if _, err := s.Approve(change.Request.ID, issued["dave"], ""); !errors.Is(err, wallet.ErrPermissionDenied) {
+		t.Fatalf("expected a proposed approver not to vote on the change, got %v", err)
+	}
+	if _, err := s.Approve(change.Request.ID, tokens["alice"], ""); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	rule, err := s.GetRule(walletID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule.Threshold != 2 || len(rule.Approvers) != 2 || rule.Approvers[1].Name != "bob" {
+		t.Fatalf("expected the rule to be unchanged below quorum, got %+v", rule)
+	}
+
+	applied, err := s.Approve(change.Request.ID, tokens["bob"], "")
+	if err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if applied.Status != storage.TxApprovalApplied {
+		t.Fatalf("expected the change to be applied, got %s", applied.Status)
+	}
+	rule, err = s.GetRule(walletID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule.Threshold != 1 || len(rule.Approvers) != 2 || rule.Approvers[1].Name != "dave" {
+		t.Fatalf("unexpected rule after the change: %+v", rule)
+	}
+	for _, approver := range rule.Approvers {
+		if approver.Token != "" {
+			t.Fatal("expected approver tokens not to be returned")
+		}
+	}
+
+	request, err := s.DeleteRule(walletID)
+	if err != nil {
+		t.Fatalf("DeleteRule: %v", err)
+	}
+	if _, err := s.Approve(request.ID, tokens["bob"], ""); !errors.Is(err, wallet.ErrPermissionDenied) {
+		t.Fatalf("expected a removed approver's token to be invalid, got %v", err)
+	}
+	if _, err := s.Approve(request.ID, tokens["alice"], ""); err != nil {
+		t.Fatalf("Approve: %v", err)
+	}
+	if rule, err := s.GetRule(walletID); err != nil || rule != nil {
+		t.Fatalf("expected the rule to be deleted, got %+v, %v", rule, err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"strings"
 	"time"
 
 	"multi-chain-wallet/internal/storage"
@@ -15,9 +16,12 @@ import (
 
 // WalletService 钱包服务
 type WalletService struct {
-	walletManager *wallet.Manager
-	walletStorage storage.WalletStorage
-	txStorage     storage.TransactionStorage
+	walletManager     *wallet.Manager
+	walletStorage     storage.WalletStorage
+	txStorage         storage.TransactionStorage
+	policyService     *PolicyService     // 为空时签名不做策略校验
+	txApprovalService *TxApprovalService // 为空时签名和发送不需要审批
+	screeningService  *ScreeningService  // 为空时不做地址筛查
 }
 
 // NewWalletService 创建钱包服务
@@ -135,6 +139,84 @@ func (s *WalletService) ImportWalletFromPrivateKey(chainType wallet.ChainType, p
 	return walletID, nil
 }
 
+// ImportRemoteSignerWallet 注册由远程签名服务托管私钥的钱包
+func (s *WalletService) ImportRemoteSignerWallet(ctx context.Context, chainType wallet.ChainType, signerURL string, address string) (string, error) {
+	walletImpl, ok := s.walletManager.GetWallet(chainType)
+	if !ok {
+		return "", wallet.ErrUnsupportedChain
+	}
+
+	remoteWallet, ok := walletImpl.(wallet.RemoteSignerWallet)
+	if !ok {
+		return "", wallet.ErrOperationNotSupported
+	}
+
+	walletID, err := remoteWallet.ImportRemoteSigner(ctx, signerURL, address)
+	if err != nil {
+		return "", err
+	}
+
+	walletInfo, err := s.walletManager.GetWalletInfo(walletID)
+	if err != nil {
+		return "", err
+	}
+
+	// 保存到数据库，不含私钥
+	dbWallet := &storage.Wallet{
+		ID:         walletID,
+		Address:    walletInfo.Address,
+		SignerURL:  signerURL,
+		ChainType:  string(chainType),
+		CreateTime: walletInfo.CreateTime,
+	}
+
+	if err := s.walletStorage.SaveWallet(dbWallet); err != nil {
+		return "", fmt.Errorf("failed to save wallet to database: %v", err)
+	}
+
+	return walletID, nil
+}
+
+// CreateThresholdWallet 在签名节点上生成t-of-n门限密钥并创建钱包
+func (s *WalletService) CreateThresholdWallet(ctx context.Context, chainType wallet.ChainType, nodeURLs []string, threshold int) (string, error) {
+	walletImpl, ok := s.walletManager.GetWallet(chainType)
+	if !ok {
+		return "", wallet.ErrUnsupportedChain
+	}
+
+	thresholdWallet, ok := walletImpl.(wallet.ThresholdWallet)
+	if !ok {
+		return "", wallet.ErrOperationNotSupported
+	}
+
+	walletID, keyID, err := thresholdWallet.CreateThresholdWallet(ctx, nodeURLs, threshold)
+	if err != nil {
+		return "", err
+	}
+
+	walletInfo, err := s.walletManager.GetWalletInfo(walletID)
+	if err != nil {
+		return "", err
+	}
+
+	// 保存到数据库，不含私钥
+	dbWallet := &storage.Wallet{
+		ID:           walletID,
+		Address:      walletInfo.Address,
+		MPCKeyID:     keyID,
+		MPCNodes:     strings.Join(nodeURLs, ","),
+		MPCThreshold: threshold,
+		ChainType:    string(chainType),
+		CreateTime:   walletInfo.CreateTime,
+	}
+
+	if err := s.walletStorage.SaveWallet(dbWallet); err != nil {
+		return "", fmt.Errorf("failed to save wallet to database: %v", err)
+	}
+
+	return walletID, nil
+}
+
 // GetWalletInfo 获取钱包信息
 func (s *WalletService) GetWalletInfo(walletID string) (*wallet.WalletInfo, error) {
 	// 从数据库获取钱包信息
@@ -143,14 +225,21 @@ func (s *WalletService) GetWalletInfo(walletID string) (*wallet.WalletInfo, erro
 		return nil, err
 	}
 
-	return &wallet.WalletInfo{
-		ID:          dbWallet.ID,
-		Address:     dbWallet.Address,
-		PrivKeyEnc:  dbWallet.PrivKeyEnc,
-		MnemonicEnc: dbWallet.MnemonicEnc,
-		ChainType:   wallet.ChainType(dbWallet.ChainType),
-		CreateTime:  dbWallet.CreateTime,
-	}, nil
+	walletInfo := &wallet.WalletInfo{
+		ID:           dbWallet.ID,
+		Address:      dbWallet.Address,
+		PrivKeyEnc:   dbWallet.PrivKeyEnc,
+		MnemonicEnc:  dbWallet.MnemonicEnc,
+		SignerURL:    dbWallet.SignerURL,
+		MPCKeyID:     dbWallet.MPCKeyID,
+		MPCThreshold: dbWallet.MPCThreshold,
+		ChainType:    wallet.ChainType(dbWallet.ChainType),
+		CreateTime:   dbWallet.CreateTime,
+	}
+	if dbWallet.MPCNodes != "" {
+		walletInfo.MPCNodes = strings.Split(dbWallet.MPCNodes, ",")
+	}
+	return walletInfo, nil
 }
 
 // GetBalance 获取余额
@@ -163,53 +252,477 @@ func (s *WalletService) GetTokenBalance(ctx context.Context, chainType wallet.Ch
 	return s.walletManager.GetTokenBalance(ctx, chainType, address, tokenAddress)
 }
 
+// GetBalances 批量获取多个地址的原生代币和代币余额
+func (s *WalletService) GetBalances(ctx context.Context, chainType wallet.ChainType, addresses []string, tokenAddresses []string) ([]*wallet.AddressBalances, error) {
+	walletImpl, ok := s.walletManager.GetWallet(chainType)
+	if !ok {
+		return nil, wallet.ErrUnsupportedChain
+	}
+
+	if reader, ok := walletImpl.(wallet.BatchBalanceReader); ok {
+		return reader.GetBalances(ctx, addresses, tokenAddresses)
+	}
+
+	// 不支持批量查询的链逐个查询
+	balances := make([]*wallet.AddressBalances, 0, len(addresses))
+	for _, address := range addresses {
+		entry := &wallet.AddressBalances{
+			Address: address,
+			Tokens:  make(map[string]*big.Int),
+		}
+		if balance, err := walletImpl.GetBalance(ctx, address); err == nil {
+			entry.Native = balance
+		}
+		for _, token := range tokenAddresses {
+			if balance, err := walletImpl.GetTokenBalance(ctx, address, token); err == nil {
+				entry.Tokens[token] = balance
+			} else {
+				entry.Tokens[token] = nil
+			}
+		}
+		balances = append(balances, entry)
+	}
+
+	return balances, nil
+}
+
+// ListUTXOs 列出地址的未花费输出，仅UTXO模型的链支持
+func (s *WalletService) ListUTXOs(ctx context.Context, chainType wallet.ChainType, address string) ([]*wallet.UTXO, error) {
+	walletImpl, ok := s.walletManager.GetWallet(chainType)
+	if !ok {
+		return nil, wallet.ErrUnsupportedChain
+	}
+
+	utxoWallet, ok := walletImpl.(wallet.UTXOWallet)
+	if !ok {
+		return nil, wallet.ErrOperationNotSupported
+	}
+	return utxoWallet.ListUTXOs(ctx, address)
+}
+
 // CreateTransaction 创建交易
-func (s *WalletService) CreateTransaction(ctx context.Context, chainType wallet.ChainType, from string, to string, amount *big.Int, data []byte) ([]byte, error) {
+func (s *WalletService) CreateTransaction(ctx context.Context, chainType wallet.ChainType, from string, to string, amount *big.Int, data []byte) (*wallet.UnsignedTx, error) {
 	return s.walletManager.CreateTransaction(ctx, chainType, from, to, amount, data)
 }
 
-// SignTransaction 签名交易
-func (s *WalletService) SignTransaction(ctx context.Context, chainType wallet.ChainType, walletID string, txJSON []byte) ([]byte, error) {
-	return s.walletManager.SignTransaction(ctx, chainType, walletID, txJSON)
+// CreateTokenTransaction 创建代币转账交易
+func (s *WalletService) CreateTokenTransaction(ctx context.Context, chainType wallet.ChainType, from string, to string, tokenAddress string, amount *big.Int) (*wallet.UnsignedTx, error) {
+	walletImpl, ok := s.walletManager.GetWallet(chainType)
+	if !ok {
+		return nil, wallet.ErrUnsupportedChain
+	}
+
+	tokenWallet, ok := walletImpl.(wallet.TokenTransferWallet)
+	if !ok {
+		return nil, wallet.ErrOperationNotSupported
+	}
+	return tokenWallet.CreateTokenTransaction(ctx, from, to, tokenAddress, amount)
+}
+
+// SetPolicyService 设置支出策略服务，之后所有经过WalletService的签名都先按钱包策略校验
+func (s *WalletService) SetPolicyService(policyService *PolicyService) {
+	s.policyService = policyService
+}
+
+// SetTxApprovalService 设置交易审批服务，之后设置了审批规则的钱包签名和发送前须获得批准
+func (s *WalletService) SetTxApprovalService(txApprovalService *TxApprovalService) {
+	s.txApprovalService = txApprovalService
+}
+
+// SetScreeningService 设置地址筛查服务，之后创建和发送交易前筛查涉及的地址
+func (s *WalletService) SetScreeningService(screeningService *ScreeningService) {
+	s.screeningService = screeningService
+}
+
+// ScreenAddresses 按制裁和拒绝名单筛查地址，命中block名单时返回ErrAddressBlocked，未启用筛查时返回nil
+func (s *WalletService) ScreenAddresses(addresses ...string) (*ScreeningResult, error) {
+	if s.screeningService == nil {
+		return nil, nil
+	}
+	return s.screeningService.Check(addresses)
+}
+
+// ScreenTransaction 筛查交易的发送方、收款方和代币，以及从Payload解码出的地址：EVM交易筛查calldata中的收款人和被授权方，
+// 其他链筛查Payload中各笔转账的收发方和代币。signed表示Payload为SignTransaction的结果。无法解码Payload时返回ErrInvalidTransaction
+func (s *WalletService) ScreenTransaction(chainType wallet.ChainType, from string, to string, token string, payload []byte, signed bool) (*ScreeningResult, error) {
+	if s.screeningService == nil {
+		return nil, nil
+	}
+
+	walletImpl, ok := s.walletManager.GetWallet(chainType)
+	if !ok {
+		return nil, wallet.ErrUnsupportedChain
+	}
+	addresses := []string{from, to, token}
+	switch w := walletImpl.(type) {
+	case wallet.RawTransactionWallet:
+		decoded, err := w.DecodeRawTransaction(payload)
+		if err != nil {
+			return nil, err
+		}
+		intent, err := callIntent(decoded.To, decoded.Value, decoded.Data)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, decoded.From, decoded.To)
+		addresses = append(addresses, intent.destinations...)
+	case wallet.PayloadWallet:
+		decode := w.DecodePayload
+		if signed {
+			decode = w.DecodeSignedPayload
+		}
+		transfers, err := decode(payload)
+		if err != nil {
+			return nil, err
+		}
+		for _, transfer := range transfers {
+			addresses = append(addresses, transfer.From, transfer.To, transfer.Token)
+		}
+	default:
+		return nil, fmt.Errorf("%w: cannot decode %s transactions", wallet.ErrInvalidTransaction, chainType)
+	}
+	return s.screeningService.Check(addresses)
+}
+
+// ScreenCall 筛查EVM调用的发送方、目标合约及calldata中的收款人和被授权方，用于元交易、Safe和智能账户等代为执行的调用
+func (s *WalletService) ScreenCall(from string, to string, value *big.Int, data string) (*ScreeningResult, error) {
+	if s.screeningService == nil {
+		return nil, nil
+	}
+
+	intent, err := callIntent(to, value, data)
+	if err != nil {
+		return nil, err
+	}
+	addresses := append([]string{from, to}, intent.destinations...)
+	return s.screeningService.Check(addresses)
+}
+
+// IncomingTransfer 转入本服务所管理钱包的转账及对发送方的筛查结果
+type IncomingTransfer struct {
+	TxHash    string           `json:"txHash"`
+	WalletID  string           `json:"walletId"`
+	From      string           `json:"from"`
+	To        string           `json:"to"`
+	Token     string           `json:"token,omitempty"` // ERC20转账的代币合约，原生代币为空
+	Amount    string           `json:"amount"`
+	Screening *ScreeningResult `json:"screening"`
+}
+
+// ScreenIncomingTransaction 筛查转入本服务所管理钱包的EVM转账（原生代币或ERC20 transfer、transferFrom）的发送方和代币，
+// 并把转账及筛查结果保存为收款钱包的交易记录，已有记录的交易（如本服务发出的转账）不重复保存。
+// 链上转账无法拒收，命中block名单时返回转账和ErrAddressBlocked，由调用方拒绝入账
+func (s *WalletService) ScreenIncomingTransaction(ctx context.Context, chainType wallet.ChainType, txHash string) (*IncomingTransfer, error) {
+	if s.screeningService == nil {
+		return nil, fmt.Errorf("%w: address screening is not enabled", wallet.ErrOperationNotSupported)
+	}
+
+	walletImpl, ok := s.walletManager.GetWallet(chainType)
+	if !ok {
+		return nil, wallet.ErrUnsupportedChain
+	}
+	lookupWallet, ok := walletImpl.(wallet.TransactionLookupWallet)
+	if !ok {
+		return nil, wallet.ErrOperationNotSupported
+	}
+	decoded, err := lookupWallet.GetTransaction(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	intent, err := callIntent(decoded.To, decoded.Value, decoded.Data)
+	if err != nil {
+		return nil, err
+	}
+	transfer := &wallet.PayloadTransfer{From: decoded.From, Value: decoded.Value}
+	switch {
+	case decoded.Data == "" || decoded.Data == "0x":
+		transfer.To = decoded.To
+	case decoded.Method == erc20TransferSelector || decoded.Method == erc20TransferFromSelector:
+		transfer.To = intent.destinations[0]
+		transfer.Token = decoded.To
+		transfer.Value = intent.spends[normalizeAsset(decoded.To)]
+	default:
+		return nil, fmt.Errorf("%w: transaction %s is not a transfer", wallet.ErrInvalidTransaction, txHash)
+	}
+
+	dbWallet, err := s.walletStorage.GetWalletByAddress(string(chainType), transfer.To)
+	if err != nil {
+		return nil, fmt.Errorf("%w: recipient %s of transaction %s is not a managed wallet", wallet.ErrWalletNotFound, transfer.To, txHash)
+	}
+	incoming := &IncomingTransfer{
+		TxHash:   decoded.Hash,
+		WalletID: dbWallet.ID,
+		From:     transfer.From,
+		To:       dbWallet.Address,
+		Token:    transfer.Token,
+	}
+	if transfer.Value != nil {
+		incoming.Amount = transfer.Value.String()
+	}
+
+	screening, checkErr := s.screeningService.Check([]string{transfer.From, transfer.Token})
+	incoming.Screening = screening
+	if _, err := s.txStorage.GetTransactionByHash(decoded.Hash); err != nil {
+		transfer.To = dbWallet.Address
+		if err := s.recordTransaction(chainType, dbWallet.ID, decoded.Hash, transfer, screening); err != nil {
+			return nil, err
+		}
+	}
+	return incoming, checkErr
+}
+
+// RequestApproval 发送方钱包设置了审批规则时为交易创建审批请求，requesterToken为发起人的审批令牌，不需要审批时返回nil
+func (s *WalletService) RequestApproval(chainType wallet.ChainType, from string, tx *wallet.UnsignedTx, requesterToken string, note string) (*TxApprovalRequest, error) {
+	if s.txApprovalService == nil {
+		return nil, nil
+	}
+	return s.txApprovalService.RequestTransaction(chainType, from, tx, requesterToken, note)
 }
 
-// SendTransaction 发送交易
-func (s *WalletService) SendTransaction(ctx context.Context, chainType wallet.ChainType, signedTxJSON []byte) (string, error) {
+// SignTransaction 签名交易，设置了支出策略时先按策略校验，钱包要求审批时须有已批准的请求
+func (s *WalletService) SignTransaction(ctx context.Context, chainType wallet.ChainType, walletID string, tx *wallet.UnsignedTx) (*wallet.SignedTx, error) {
+	sign := func() (*wallet.SignedTx, error) {
+		return s.walletManager.SignTransaction(ctx, chainType, walletID, tx)
+	}
+	if s.txApprovalService != nil {
+		signWithoutApproval := sign
+		sign = func() (*wallet.SignedTx, error) {
+			return s.txApprovalService.EnforceTransaction(ctx, walletID, tx, signWithoutApproval)
+		}
+	}
+	if s.policyService == nil {
+		return sign()
+	}
+	return s.policyService.EnforceTransaction(ctx, chainType, walletID, tx, sign)
+}
+
+// SendTransaction 发送交易，涉及的地址命中拒绝名单时不发送，发送方钱包要求审批时只发送已批准请求的签名结果。
+// 交易记录的收发方和金额从已签名的Payload解码，不使用调用方填写的字段
+func (s *WalletService) SendTransaction(ctx context.Context, chainType wallet.ChainType, signedTx *wallet.SignedTx) (string, error) {
+	transfer, err := s.decodeSignedTransfer(chainType, signedTx.From, signedTx.Payload)
+	if err != nil {
+		return "", err
+	}
+
+	screening, err := s.ScreenTransaction(chainType, signedTx.From, signedTx.To, signedTx.Token, signedTx.Payload, true)
+	if err != nil {
+		return "", err
+	}
+
+	var approvalID string
+	if s.txApprovalService != nil {
+		id, err := s.txApprovalService.CheckSend(chainType, signedTx)
+		if err != nil {
+			return "", err
+		}
+		approvalID = id
+	}
+
 	// 发送交易
-	txHash, err := s.walletManager.SendTransaction(ctx, chainType, signedTxJSON)
+	txHash, err := s.walletManager.SendTransaction(ctx, chainType, signedTx)
 	if err != nil {
 		return "", err
 	}
+	if approvalID != "" {
+		s.txApprovalService.MarkSent(approvalID, txHash)
+	}
+
+	if err := s.recordTransaction(chainType, s.walletIDByAddress(chainType, transfer.From), txHash, transfer, screening); err != nil {
+		return "", err
+	}
+
+	return txHash, nil
+}
+
+// decodeSignedTransfer 从已签名的Payload解码交易的发送方、收款方和金额。EVM交易为交易本身的from、to和value，
+// 其他链为Payload中第一笔不是找零的转账。比特币原始交易的输入不含地址，发送方沿用signedFrom
+func (s *WalletService) decodeSignedTransfer(chainType wallet.ChainType, signedFrom string, payload []byte) (*wallet.PayloadTransfer, error) {
+	walletImpl, ok := s.walletManager.GetWallet(chainType)
+	if !ok {
+		return nil, wallet.ErrUnsupportedChain
+	}
+
+	switch w := walletImpl.(type) {
+	case wallet.RawTransactionWallet:
+		decoded, err := w.DecodeRawTransaction(payload)
+		if err != nil {
+			return nil, err
+		}
+		if !decoded.Signed {
+			return nil, fmt.Errorf("%w: transaction is not signed", wallet.ErrInvalidTransaction)
+		}
+		return &wallet.PayloadTransfer{From: decoded.From, To: decoded.To, Value: decoded.Value}, nil
+	case wallet.PayloadWallet:
+		transfers, err := w.DecodeSignedPayload(payload)
+		if err != nil {
+			return nil, err
+		}
+		if len(transfers) == 0 {
+			return nil, fmt.Errorf("%w: transaction contains no transfers", wallet.ErrInvalidTransaction)
+		}
+		transfer := *transfers[0]
+		for _, t := range transfers {
+			if t.To != signedFrom {
+				transfer = *t
+				break
+			}
+		}
+		if transfer.From == "" {
+			transfer.From = signedFrom
+		}
+		return &transfer, nil
+	default:
+		return nil, fmt.Errorf("%w: cannot decode %s transactions", wallet.ErrInvalidTransaction, chainType)
+	}
+}
 
-	// 解析交易数据
-	var txData struct {
-		From  string `json:"from"`
-		To    string `json:"to"`
-		Value string `json:"value"`
-		Data  string `json:"data"`
+// walletIDByAddress 返回地址对应的钱包ID，地址不是本服务管理的钱包时返回空
+func (s *WalletService) walletIDByAddress(chainType wallet.ChainType, address string) string {
+	dbWallet, err := s.walletStorage.GetWalletByAddress(string(chainType), address)
+	if err != nil {
+		return ""
 	}
-	if err := json.Unmarshal(signedTxJSON, &txData); err != nil {
-		return "", fmt.Errorf("failed to parse transaction data: %v", err)
+	return dbWallet.ID
+}
+
+// recordTransaction 保存交易记录及地址筛查结果到数据库，状态由调度任务跟踪更新
+func (s *WalletService) recordTransaction(chainType wallet.ChainType, walletID string, txHash string, transfer *wallet.PayloadTransfer, screening *ScreeningResult) error {
+	var amount string
+	if transfer.Value != nil {
+		amount = transfer.Value.String()
 	}
+	screeningStatus, screeningJSON := encodeScreening(screening)
 
-	// 保存交易记录到数据库
 	dbTx := &storage.Transaction{
-		ID:         uuid.New().String(),
-		WalletID:   txData.From,
-		TxHash:     txHash,
-		From:       txData.From,
-		To:         txData.To,
-		Amount:     txData.Value,
-		Status:     string(wallet.TxPending),
-		ChainType:  string(chainType),
-		CreateTime: time.Now().Unix(),
+		ID:              uuid.New().String(),
+		WalletID:        walletID,
+		TxHash:          txHash,
+		From:            transfer.From,
+		To:              transfer.To,
+		Amount:          amount,
+		Status:          string(wallet.TxPending),
+		ChainType:       string(chainType),
+		CreateTime:      time.Now().Unix(),
+		ScreeningStatus: screeningStatus,
+		Screening:       screeningJSON,
 	}
 
 	if err := s.txStorage.SaveTransaction(dbTx); err != nil {
-		return "", fmt.Errorf("failed to save transaction to database: %v", err)
+		return fmt.Errorf("failed to save transaction to database: %v", err)
 	}
+	return nil
+}
 
-	return txHash, nil
+// encodeScreening 把筛查结果转为存储格式，未筛查时返回空值
+func encodeScreening(screening *ScreeningResult) (string, string) {
+	if screening == nil {
+		return "", ""
+	}
+	data, err := json.Marshal(screening)
+	if err != nil {
+		return screening.Status, ""
+	}
+	return screening.Status, string(data)
+}
+
+// DecodeRawTransaction 解码RLP编码的原始交易
+func (s *WalletService) DecodeRawTransaction(chainType wallet.ChainType, rawTx []byte) (*wallet.DecodedTx, error) {
+	walletImpl, ok := s.walletManager.GetWallet(chainType)
+	if !ok {
+		return nil, wallet.ErrUnsupportedChain
+	}
+
+	rawWallet, ok := walletImpl.(wallet.RawTransactionWallet)
+	if !ok {
+		return nil, wallet.ErrOperationNotSupported
+	}
+	return rawWallet.DecodeRawTransaction(rawTx)
+}
+
+// BroadcastRawTransaction 广播外部签名的原始交易，交易记录的发送方从签名恢复
+func (s *WalletService) BroadcastRawTransaction(ctx context.Context, chainType wallet.ChainType, rawTx []byte) (string, error) {
+	decoded, err := s.DecodeRawTransaction(chainType, rawTx)
+	if err != nil {
+		return "", err
+	}
+	if !decoded.Signed {
+		return "", fmt.Errorf("%w: transaction is not signed", wallet.ErrInvalidTransaction)
+	}
+
+	return s.SendTransaction(ctx, chainType, &wallet.SignedTx{
+		ChainType: chainType,
+		From:      decoded.From,
+		To:        decoded.To,
+		Value:     decoded.Value,
+		Nonce:     decoded.Nonce,
+		Fee:       decoded.Fee,
+		Hash:      decoded.Hash,
+		Payload:   rawTx,
+	})
+}
+
+// SignMessage 签名消息（EIP-191）
+func (s *WalletService) SignMessage(ctx context.Context, chainType wallet.ChainType, walletID string, message []byte) ([]byte, error) {
+	return s.walletManager.SignMessage(ctx, chainType, walletID, message)
+}
+
+// SignTypedData 签名结构化数据（EIP-712），设置了支出策略时先按策略校验其中的授权和调用，钱包要求审批时须有已批准的请求
+func (s *WalletService) SignTypedData(ctx context.Context, chainType wallet.ChainType, walletID string, typedDataJSON []byte) ([]byte, error) {
+	sign := func() ([]byte, error) {
+		return s.walletManager.SignTypedData(ctx, chainType, walletID, typedDataJSON)
+	}
+	if s.txApprovalService != nil {
+		signWithoutApproval := sign
+		sign = func() ([]byte, error) {
+			return s.txApprovalService.EnforceTypedData(ctx, walletID, typedDataJSON, signWithoutApproval)
+		}
+	}
+	if s.policyService == nil {
+		return sign()
+	}
+	return s.policyService.EnforceTypedData(ctx, walletID, typedDataJSON, sign)
+}
+
+// SignUserOperation 用所有者钱包签名UserOperation，设置了支出策略时先按策略校验callData中智能账户要执行的调用，
+// 钱包要求审批时须有已批准的请求
+func (s *WalletService) SignUserOperation(ctx context.Context, chainType wallet.ChainType, walletID string, op *wallet.UserOperation) (*wallet.UserOperation, error) {
+	walletImpl, ok := s.walletManager.GetWallet(chainType)
+	if !ok {
+		return nil, wallet.ErrUnsupportedChain
+	}
+	accountWallet, ok := walletImpl.(wallet.SmartAccountWallet)
+	if !ok {
+		return nil, wallet.ErrOperationNotSupported
+	}
+	hash, err := accountWallet.UserOperationHash(op)
+	if err != nil {
+		return nil, err
+	}
+
+	sign := func() (*wallet.UserOperation, error) {
+		return accountWallet.SignUserOperation(ctx, walletID, op)
+	}
+	if s.txApprovalService != nil {
+		signWithoutApproval := sign
+		sign = func() (*wallet.UserOperation, error) {
+			return s.txApprovalService.EnforceUserOperation(ctx, walletID, hash, op, signWithoutApproval)
+		}
+	}
+	if s.policyService == nil {
+		return sign()
+	}
+	return s.policyService.EnforceUserOperation(ctx, walletID, hash, op, accountWallet.DecodeUserOperationCalls, sign)
+}
+
+// VerifyMessage 校验消息签名
+func (s *WalletService) VerifyMessage(ctx context.Context, chainType wallet.ChainType, expectedSigner string, message []byte, signature []byte) (*wallet.SignatureVerification, error) {
+	return s.walletManager.VerifyMessage(ctx, chainType, expectedSigner, message, signature)
+}
+
+// VerifyTypedData 校验结构化数据签名
+func (s *WalletService) VerifyTypedData(ctx context.Context, chainType wallet.ChainType, expectedSigner string, typedDataJSON []byte, signature []byte) (*wallet.SignatureVerification, error) {
+	return s.walletManager.VerifyTypedData(ctx, chainType, expectedSigner, typedDataJSON, signature)
 }
 
 // GetTransactionStatus 获取交易状态
@@ -263,17 +776,22 @@ func (s *WalletService) GetTransactionHistory(walletID string) ([]*wallet.Transa
 	// 转换为API响应格式
 	var txs []*wallet.Transaction
 	for _, dbTx := range dbTxs {
-		txs = append(txs, &wallet.Transaction{
-			ID:         dbTx.ID,
-			WalletID:   dbTx.WalletID,
-			TxHash:     dbTx.TxHash,
-			From:       dbTx.From,
-			To:         dbTx.To,
-			Amount:     dbTx.Amount,
-			Status:     wallet.TransactionStatus(dbTx.Status),
-			ChainType:  wallet.ChainType(dbTx.ChainType),
-			CreateTime: dbTx.CreateTime,
-		})
+		tx := &wallet.Transaction{
+			ID:              dbTx.ID,
+			WalletID:        dbTx.WalletID,
+			TxHash:          dbTx.TxHash,
+			From:            dbTx.From,
+			To:              dbTx.To,
+			Amount:          dbTx.Amount,
+			Status:          wallet.TransactionStatus(dbTx.Status),
+			ChainType:       wallet.ChainType(dbTx.ChainType),
+			CreateTime:      dbTx.CreateTime,
+			ScreeningStatus: dbTx.ScreeningStatus,
+		}
+		if dbTx.Screening != "" {
+			tx.Screening = json.RawMessage(dbTx.Screening)
+		}
+		txs = append(txs, tx)
 	}
 
 	return txs, nil
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+
+	"multi-chain-wallet/internal/wallet"
+	"multi-chain-wallet/internal/wallet/ethereum"
+)
+
+// 交易记录的收发方和金额以已签名交易为准，归属发送方的钱包ID
+func TestSendTransactionRecordsDecodedTransfer(t *testing.T) {
+	s := newTestWalletService(t)
+	walletID, from := importDevAccount(t, s, 0)
+	_, to := importDevAccount(t, s, 1)
+	ctx := context.Background()
+
+	tx, err := s.CreateTransaction(ctx, testChainType, from, to, big.NewInt(1000), nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	signed, err := s.SignTransaction(ctx, testChainType, walletID, tx)
+	if err != nil {
+		t.Fatalf("SignTransaction: %v", err)
+	}
+	// 调用方填写的字段与签名的交易不一致
+	signed.From = to
+	signed.To = from
+	signed.Value = big.NewInt(1)
+
+	txHash, err := s.SendTransaction(ctx, testChainType, signed)
+	if err != nil {
+		t.Fatalf("SendTransaction: %v", err)
+	}
+
+	history, err := s.GetTransactionHistory(walletID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("expected one transaction in the sender's history, got %d", len(history))
+	}
+	got := history[0]
+	if got.TxHash != txHash || got.WalletID != walletID || !strings.EqualFold(got.From, from) ||
+		!strings.EqualFold(got.To, to) || got.Amount != "1000" {
+		t.Fatalf("unexpected transaction record: %+v", got)
+	}
+}
+
+// signDevTransaction 用第i个预置账户的私钥在链外签名一笔EIP-1559转账，chainID为空时不签名
+func signDevTransaction(t *testing.T, i int, chainID *big.Int, to string, value int64) []byte {
+	t.Helper()
+	accounts, err := ethereum.DevAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	toAddress := common.HexToAddress(to)
+	inner := &types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     0,
+		GasTipCap: big.NewInt(params.GWei),
+		GasFeeCap: big.NewInt(100 * params.GWei),
+		Gas:       21000,
+		To:        &toAddress,
+		Value:     big.NewInt(value),
+	}
+	tx := types.NewTx(inner)
+	if chainID != nil {
+		key, err := crypto.HexToECDSA(accounts[i].PrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tx, err = types.SignTx(tx, types.LatestSignerForChainID(chainID), key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestBroadcastRawTransaction(t *testing.T) {
+	chainID := big.NewInt(ethereum.SimulatedChainID)
+	accounts, err := ethereum.DevAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	to := accounts[5].Address
+
+	tests := []struct {
+		name     string
+		signer   int
+		imported bool // 签名账户是否导入为钱包
+		raw      func(t *testing.T) []byte
+		wantErr  error
+	}{
+		{name: "signed by a managed wallet", signer: 0, imported: true},
+		{name: "signed externally", signer: 2},
+		{name: "unsigned", wantErr: wallet.ErrInvalidTransaction, raw: func(t *testing.T) []byte {
+			return signDevTransaction(t, 0, nil, to, 1000)
+		}},
+		{name: "other chain", wantErr: wallet.ErrChainIDMismatch, raw: func(t *testing.T) []byte {
+			return signDevTransaction(t, 0, big.NewInt(1), to, 1000)
+		}},
+		{name: "not rlp", wantErr: wallet.ErrInvalidTransaction, raw: func(t *testing.T) []byte {
+			return []byte{0x02, 0x01}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestWalletService(t)
+			var walletID string
+			if tt.imported {
+				walletID, _ = importDevAccount(t, s, tt.signer)
+			}
+			var raw []byte
+			if tt.raw != nil {
+				raw = tt.raw(t)
+			} else {
+				raw = signDevTransaction(t, tt.signer, chainID, to, 1000)
+			}
+
+			txHash, err := s.BroadcastRawTransaction(context.Background(), testChainType, raw)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BroadcastRawTransaction: %v", err)
+			}
+			var tx types.Transaction
+			if err := tx.UnmarshalBinary(raw); err != nil {
+				t.Fatal(err)
+			}
+			if txHash != tx.Hash().Hex() {
+				t.Fatalf("expected hash %s, got %s", tx.Hash().Hex(), txHash)
+			}
+
+			// 外部签名的交易不属于任何钱包，只有托管钱包签名的交易出现在钱包历史中
+			if walletID == "" {
+				return
+			}
+			history, err := s.GetTransactionHistory(walletID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(history) != 1 || history[0].TxHash != txHash || !strings.EqualFold(history[0].From, accounts[tt.signer].Address) ||
+				!strings.EqualFold(history[0].To, to) || history[0].Amount != "1000" || history[0].Status != wallet.TxPending {
+				t.Fatalf("unexpected history: %+v", history)
+			}
+		})
+	}
+}
+
+func TestDecodeRawTransaction(t *testing.T) {
+	s := newTestWalletService(t)
+	accounts, err := ethereum.DevAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := s.DecodeRawTransaction(testChainType, signDevTransaction(t, 3, big.NewInt(ethereum.SimulatedChainID), accounts[4].Address, 7))
+	if err != nil {
+		t.Fatalf("DecodeRawTransaction: %v", err)
+	}
+	if !signed.Signed || signed.Type != types.DynamicFeeTxType || signed.From != accounts[3].Address ||
+		signed.To != accounts[4].Address || signed.Value.Int64() != 7 || signed.Fee == nil {
+		t.Fatalf("unexpected decoded transaction: %+v", signed)
+	}
+
+	unsigned, err := s.DecodeRawTransaction(testChainType, signDevTransaction(t, 3, nil, accounts[4].Address, 7))
+	if err != nil {
+		t.Fatalf("DecodeRawTransaction: %v", err)
+	}
+	if unsigned.Signed || unsigned.From != "" {
+		t.Fatalf("expected an unsigned transaction without sender, got %+v", unsigned)
+	}
+}
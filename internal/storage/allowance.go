@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TokenAllowance 钱包当前的ERC20授权
+type TokenAllowance struct {
+	ID          string `gorm:"primaryKey;type:varchar(255)"` // walletID:chainType:owner:token:spender
+	WalletID    string `gorm:"index;type:varchar(100)"`
+	ChainType   string `gorm:"type:varchar(50)"`
+	Owner       string `gorm:"index;type:varchar(100)"`
+	Token       string `gorm:"type:varchar(100)"`
+	Spender     string `gorm:"type:varchar(100)"`
+	Allowance   string `gorm:"type:varchar(100)"`
+	RiskFlags   string `gorm:"type:varchar(255)"` // 逗号分隔的风险标记
+	BlockNumber uint64 // 最近一次Approval事件所在区块
+	TxHash      string `gorm:"type:varchar(100)"`
+	UpdatedAt   time.Time
+}
+
+// ApprovalScanCursor 授权扫描进度
+type ApprovalScanCursor struct {
+	ID        string `gorm:"primaryKey;type:varchar(200)"` // walletID:chainType:owner
+	WalletID  string `gorm:"type:varchar(100)"`
+	ChainType string `gorm:"type:varchar(50)"`
+	Owner     string `gorm:"type:varchar(100)"`
+	LastBlock uint64 // 已扫描到的区块
+	UpdatedAt time.Time
+}
+
+// MySQLAllowanceStorage MySQL授权存储实现
+type MySQLAllowanceStorage struct{}
+
+// NewMySQLAllowanceStorage 创建MySQL授权存储
+func NewMySQLAllowanceStorage() *MySQLAllowanceStorage {
+	return &MySQLAllowanceStorage{}
+}
+
+// InitAllowanceTables 初始化授权相关表
+func (s *MySQLAllowanceStorage) InitAllowanceTables() error {
+	if err := DB.AutoMigrate(&TokenAllowance{}, &ApprovalScanCursor{}); err != nil {
+		return err
+	}
+	return s.migrateLegacyIDs()
+}
+
+// migrateLegacyIDs 把不含钱包ID的旧记录ID改为包含钱包ID，同一地址导入为多个钱包时记录互不覆盖。
+// 旧的扫描进度无法确定所属钱包，直接删除，下次扫描时重新回溯
+func (s *MySQLAllowanceStorage) migrateLegacyIDs() error {
+	var legacy []*TokenAllowance
+	if err := DB.Where("id NOT LIKE ?", "%:%:%:%:%").Find(&legacy).Error; err != nil {
+		return err
+	}
+	for _, allowance := range legacy {
+		if err := DB.Delete(&TokenAllowance{}, "id = ?", allowance.ID).Error; err != nil {
+			return err
+		}
+		allowance.ID = AllowanceID(allowance.WalletID, allowance.ChainType, allowance.Owner, allowance.Token, allowance.Spender)
+		if err := DB.Save(allowance).Error; err != nil {
+			return err
+		}
+	}
+	return DB.Where("id NOT LIKE ?", "%:%:%").Delete(&ApprovalScanCursor{}).Error
+}
+
+// AllowanceID 生成授权记录ID
+func AllowanceID(walletID, chainType, owner, token, spender string) string {
+	return strings.ToLower(fmt.Sprintf("%s:%s:%s:%s:%s", walletID, chainType, owner, token, spender))
+}
+
+// scanCursorID 生成扫描进度ID
+func scanCursorID(walletID, chainType, owner string) string {
+	return strings.ToLower(fmt.Sprintf("%s:%s:%s", walletID, chainType, owner))
+}
+
+// SaveAllowance 保存授权（存在则更新）
+func (s *MySQLAllowanceStorage) SaveAllowance(allowance *TokenAllowance) error {
+	if allowance.ID == "" {
+		allowance.ID = AllowanceID(allowance.WalletID, allowance.ChainType, allowance.Owner, allowance.Token, allowance.Spender)
+	}
+	return DB.Save(allowance).Error
+}
+
+// GetAllowancesByWallet 获取钱包的非零授权
+func (s *MySQLAllowanceStorage) GetAllowancesByWallet(walletID string) ([]*TokenAllowance, error) {
+	var allowances []*TokenAllowance
+	err := DB.Where("wallet_id = ? AND allowance <> ?", walletID, "0").Order("updated_at DESC").Find(&allowances).Error
+	return allowances, err
+}
+
+// GetScanCursor 获取扫描进度，不存在时返回nil
+func (s *MySQLAllowanceStorage) GetScanCursor(walletID, chainType, owner string) (*ApprovalScanCursor, error) {
+	var cursors []*ApprovalScanCursor
+	if err := DB.Where("id = ?", scanCursorID(walletID, chainType, owner)).Limit(1).Find(&cursors).Error; err != nil {
+		return nil, err
+	}
+	if len(cursors) == 0 {
+		return nil, nil
+	}
+	return cursors[0], nil
+}
+
+// SaveScanCursor 保存扫描进度
+func (s *MySQLAllowanceStorage) SaveScanCursor(walletID, chainType, owner string, lastBlock uint64) error {
+	return DB.Save(&ApprovalScanCursor{
+		ID:        scanCursorID(walletID, chainType, owner),
+		WalletID:  walletID,
+		ChainType: chainType,
+		Owner:     owner,
+		LastBlock: lastBlock,
+	}).Error
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"gorm.io/driver/mysql"
@@ -144,6 +145,8 @@ type WalletStorage interface {
 	SaveWallet(wallet *Wallet) error
 	// 获取钱包
 	GetWallet(id string) (*Wallet, error)
+	// 按链类型和地址获取钱包
+	GetWalletByAddress(chainType string, address string) (*Wallet, error)
 	// 获取所有钱包
 	GetAllWallets() ([]*Wallet, error)
 	// 删除钱包
@@ -156,6 +159,8 @@ type TransactionStorage interface {
 	SaveTransaction(tx *Transaction) error
 	// 获取交易
 	GetTransaction(id string) (*Transaction, error)
+	// 按交易哈希获取交易
+	GetTransactionByHash(txHash string) (*Transaction, error)
 	// 获取钱包的所有交易
 	GetWalletTransactions(walletID string) ([]*Transaction, error)
 	// 更新交易状态
@@ -189,6 +194,21 @@ func (s *MySQLWalletStorage) GetWallet(id string) (*Wallet, error) {
 	return &wallet, nil
 }
 
+// GetWalletByAddress 按链类型和地址获取钱包，EVM地址不区分大小写
+func (s *MySQLWalletStorage) GetWalletByAddress(chainType string, address string) (*Wallet, error) {
+	var wallet Wallet
+	query := "chain_type = ? AND address = ?"
+	if strings.HasPrefix(address, "0x") || strings.HasPrefix(address, "0X") {
+		query = "chain_type = ? AND LOWER(address) = ?"
+		address = strings.ToLower(address)
+	}
+	err := DB.First(&wallet, query, chainType, address).Error
+	if err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
 // GetAllWallets 获取所有钱包
 func (s *MySQLWalletStorage) GetAllWallets() ([]*Wallet, error) {
 	var wallets []*Wallet
@@ -222,6 +242,16 @@ func (s *MySQLTransactionStorage) GetTransaction(id string) (*Transaction, error
 	return &tx, nil
 }
 
+// GetTransactionByHash 按交易哈希获取交易
+func (s *MySQLTransactionStorage) GetTransactionByHash(txHash string) (*Transaction, error) {
+	var tx Transaction
+	err := DB.First(&tx, "tx_hash = ?", txHash).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
 // GetWalletTransactions 获取钱包的所有交易
 func (s *MySQLTransactionStorage) GetWalletTransactions(walletID string) ([]*Transaction, error) {
 	var txs []*Transaction
@@ -6,25 +6,31 @@ import (
 
 // Wallet 钱包数据模型
 type Wallet struct {
-	ID          string    `gorm:"primaryKey;type:varchar(100)"`  // 明确指定ID的类型和长度
-	Address     string    `gorm:"uniqueIndex;type:varchar(100)"` // 指定类型和长度
-	PrivKeyEnc  string    // 加密后的私钥
-	MnemonicEnc string    // 加密后的助记词
-	ChainType   string    `gorm:"type:varchar(50)"` // 链类型，指定类型和长度
-	CreateTime  int64     // 创建时间
-	UpdatedAt   time.Time // 更新时间
+	ID           string    `gorm:"primaryKey;type:varchar(100)"`  // 明确指定ID的类型和长度
+	Address      string    `gorm:"uniqueIndex;type:varchar(100)"` // 指定类型和长度
+	PrivKeyEnc   string    // 加密后的私钥
+	MnemonicEnc  string    // 加密后的助记词
+	SignerURL    string    `gorm:"type:varchar(255)"` // 远程签名服务地址，远程签名的钱包不保存私钥
+	MPCKeyID     string    `gorm:"type:varchar(64)"`  // 门限密钥ID，门限签名的钱包不保存私钥
+	MPCNodes     string    `gorm:"type:text"`         // 签名节点地址，逗号分隔
+	MPCThreshold int       // 签名所需的最少节点数
+	ChainType    string    `gorm:"type:varchar(50)"` // 链类型，指定类型和长度
+	CreateTime   int64     // 创建时间
+	UpdatedAt    time.Time // 更新时间
 }
 
 // Transaction 交易记录模型
 type Transaction struct {
-	ID         string    `gorm:"primaryKey;type:varchar(100)"`  // 指定类型和长度
-	WalletID   string    `gorm:"index;type:varchar(100)"`       // 确保与Wallet.ID类型一致
-	TxHash     string    `gorm:"uniqueIndex;type:varchar(100)"` // 指定类型和长度
-	From       string    `gorm:"index;type:varchar(100)"`       // 指定类型和长度
-	To         string    `gorm:"index;type:varchar(100)"`       // 指定类型和长度
-	Amount     string    // 交易金额
-	Status     string    `gorm:"type:varchar(50)"` // 指定类型和长度
-	ChainType  string    `gorm:"type:varchar(50)"` // 链类型，指定类型和长度
-	CreateTime int64     // 创建时间
-	UpdatedAt  time.Time // 更新时间
+	ID              string    `gorm:"primaryKey;type:varchar(100)"`  // 指定类型和长度
+	WalletID        string    `gorm:"index;type:varchar(100)"`       // 确保与Wallet.ID类型一致
+	TxHash          string    `gorm:"uniqueIndex;type:varchar(100)"` // 指定类型和长度
+	From            string    `gorm:"index;type:varchar(100)"`       // 指定类型和长度
+	To              string    `gorm:"index;type:varchar(100)"`       // 指定类型和长度
+	Amount          string    // 交易金额
+	Status          string    `gorm:"type:varchar(50)"` // 指定类型和长度
+	ChainType       string    `gorm:"type:varchar(50)"` // 链类型，指定类型和长度
+	CreateTime      int64     // 创建时间
+	UpdatedAt       time.Time // 更新时间
+	ScreeningStatus string    `gorm:"index;type:varchar(20)"` // 地址筛查结论，未启用筛查时为空
+	Screening       string    `gorm:"type:text"`              // 地址筛查结果，JSON
 }
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"time"
+)
+
+// 离线签名包状态
+const (
+	OfflineBundlePrepared  = "PREPARED"  // 已生成，等待离线签名
+	OfflineBundleBroadcast = "BROADCAST" // 签名结果已导入并广播
+)
+
+// OfflineBundle 离线签名包记录
+type OfflineBundle struct {
+	ID         string `gorm:"primaryKey;type:varchar(100)"`
+	ChainType  string `gorm:"type:varchar(50)"`
+	From       string `gorm:"index;type:varchar(100)"`
+	Bundle     string `gorm:"type:text"` // 签名包JSON，导入签名结果时据此校验交易意图
+	Status     string `gorm:"type:varchar(20)"`
+	TxHash     string `gorm:"type:varchar(100)"`
+	CreateTime int64
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
+
+// MySQLOfflineStorage MySQL离线签名包存储实现
+type MySQLOfflineStorage struct{}
+
+// NewMySQLOfflineStorage 创建MySQL离线签名包存储
+func NewMySQLOfflineStorage() *MySQLOfflineStorage {
+	return &MySQLOfflineStorage{}
+}
+
+// InitOfflineTables 初始化离线签名包表
+func (s *MySQLOfflineStorage) InitOfflineTables() error {
+	return DB.AutoMigrate(&OfflineBundle{})
+}
+
+// SaveOfflineBundle 保存离线签名包
+func (s *MySQLOfflineStorage) SaveOfflineBundle(bundle *OfflineBundle) error {
+	return DB.Create(bundle).Error
+}
+
+// GetOfflineBundle 获取离线签名包
+func (s *MySQLOfflineStorage) GetOfflineBundle(id string) (*OfflineBundle, error) {
+	var bundle OfflineBundle
+	if err := DB.Where("id = ?", id).First(&bundle).Error; err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+// MarkOfflineBundleBroadcast 标记离线签名包已广播
+func (s *MySQLOfflineStorage) MarkOfflineBundleBroadcast(id string, txHash string) error {
+	return DB.Model(&OfflineBundle{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": OfflineBundleBroadcast, "tx_hash": txHash}).Error
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WalletPolicy 钱包的支出策略，签名前按策略校验交易。放宽策略的变更先记为待生效，到PendingAt后才替换Document
+type WalletPolicy struct {
+	WalletID      string `gorm:"primaryKey;type:varchar(100)"`
+	Document      string `gorm:"type:text"` // 策略内容，JSON
+	Pending       string `gorm:"type:text"` // 待生效的策略，JSON
+	PendingDelete bool   // 待生效的变更为删除策略
+	PendingAt     int64  // 待生效变更的生效时间，0表示没有待生效的变更
+	CreateTime    int64
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+}
+
+// PolicySpend 策略下已签名交易的支出，用于计算滚动24小时限额。同一交易重复签名只记一次。
+// 签名前先以预留键记录，签名成功后改记到交易哈希下，失败时删除
+type PolicySpend struct {
+	ID         uint   `gorm:"primaryKey;autoIncrement"`
+	WalletID   string `gorm:"uniqueIndex:idx_policy_spend;index:idx_policy_spend_window;type:varchar(100)"`
+	Asset      string `gorm:"uniqueIndex:idx_policy_spend;index:idx_policy_spend_window;type:varchar(100)"` // native或小写的代币地址
+	TxHash     string `gorm:"uniqueIndex:idx_policy_spend;type:varchar(150)"`
+	Amount     string `gorm:"type:varchar(100)"`
+	CreateTime int64  `gorm:"index:idx_policy_spend_window"`
+}
+
+// MySQLPolicyStorage MySQL支出策略存储实现
+type MySQLPolicyStorage struct{}
+
+// NewMySQLPolicyStorage 创建MySQL支出策略存储
+func NewMySQLPolicyStorage() *MySQLPolicyStorage {
+	return &MySQLPolicyStorage{}
+}
+
+// InitPolicyTables 初始化支出策略相关表
+func (s *MySQLPolicyStorage) InitPolicyTables() error {
+	return DB.AutoMigrate(&WalletPolicy{}, &PolicySpend{})
+}
+
+// GetPolicy 获取钱包的支出策略，未设置时返回nil
+func (s *MySQLPolicyStorage) GetPolicy(walletID string) (*WalletPolicy, error) {
+	var policies []*WalletPolicy
+	if err := DB.Where("wallet_id = ?", walletID).Limit(1).Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+	return policies[0], nil
+}
+
+// SavePolicy 保存钱包的支出策略
+func (s *MySQLPolicyStorage) SavePolicy(policy *WalletPolicy) error {
+	return DB.Save(policy).Error
+}
+
+// DeletePolicy 删除钱包的支出策略，已记录的支出保留
+func (s *MySQLPolicyStorage) DeletePolicy(walletID string) error {
+	return DB.Where("wallet_id = ?", walletID).Delete(&WalletPolicy{}).Error
+}
+
+// ListPolicySpends 获取钱包在since之后的支出记录
+func (s *MySQLPolicyStorage) ListPolicySpends(walletID string, since int64) ([]*PolicySpend, error) {
+	var spends []*PolicySpend
+	if err := DB.Where("wallet_id = ? AND create_time >= ?", walletID, since).Find(&spends).Error; err != nil {
+		return nil, err
+	}
+	return spends, nil
+}
+
+// SavePolicySpends 记录一笔交易的各资产支出，交易已记录过时忽略
+func (s *MySQLPolicyStorage) SavePolicySpends(spends []*PolicySpend) error {
+	for _, spend := range spends {
+		var count int64
+		if err := DB.Model(&PolicySpend{}).Where("wallet_id = ? AND asset = ? AND tx_hash = ?", spend.WalletID, spend.Asset, spend.TxHash).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		if err := DB.Create(spend).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CommitPolicySpends 把预留键下的支出改记到交易哈希下，该交易已记录过的资产删除预留，不重复计入
+func (s *MySQLPolicyStorage) CommitPolicySpends(walletID string, reservation string, txHash string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var spends []*PolicySpend
+		if err := tx.Where("wallet_id = ? AND tx_hash = ?", walletID, reservation).Find(&spends).Error; err != nil {
+			return err
+		}
+		for _, spend := range spends {
+			var count int64
+			if err := tx.Model(&PolicySpend{}).Where("wallet_id = ? AND asset = ? AND tx_hash = ?", walletID, spend.Asset, txHash).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				if err := tx.Delete(spend).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Model(spend).Update("tx_hash", txHash).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeletePolicySpends 删除预留键下的支出
+func (s *MySQLPolicyStorage) DeletePolicySpends(walletID string, reservation string) error {
+	return DB.Where("wallet_id = ? AND tx_hash = ?", walletID, reservation).Delete(&PolicySpend{}).Error
+}
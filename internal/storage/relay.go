@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"time"
+)
+
+// RelayTransaction 中继钱包代用户提交的EIP-2771元交易，按原始发送方UserAddress归属
+type RelayTransaction struct {
+	ID          string    `gorm:"primaryKey;type:varchar(100)"`
+	ChainType   string    `gorm:"type:varchar(50)"`
+	UserAddress string    `gorm:"index;type:varchar(100)"` // ForwardRequest的from
+	Target      string    `gorm:"type:varchar(100)"`       // 目标合约
+	Forwarder   string    `gorm:"type:varchar(100)"`
+	Relayer     string    `gorm:"type:varchar(100)"` // 提交交易并支付gas的中继钱包地址
+	RequestHash string    `gorm:"uniqueIndex;type:varchar(100)"`
+	Payload     string    `gorm:"type:text"` // ForwardRequest JSON
+	Signature   string    `gorm:"type:varchar(200)"`
+	TxHash      string    `gorm:"index;type:varchar(100)"` // 预留额度后、广播前为空
+	Status      string    `gorm:"type:varchar(20)"`        // pending/confirmed/failed，与交易状态一致
+	Cost        string    `gorm:"type:varchar(100)"`       // 计入代付额度的gas费用（wei），上链前为手续费上限，上链后为实际费用
+	CreateTime  int64     `gorm:"index"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+// RelayBudget 用户的每日gas代付额度，未设置的用户不代付
+type RelayBudget struct {
+	ChainType   string    `gorm:"primaryKey;type:varchar(50)"`
+	UserAddress string    `gorm:"primaryKey;type:varchar(100)"` // 小写地址
+	DailyLimit  string    `gorm:"type:varchar(100)"`            // wei
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+// MySQLRelayStorage MySQL元交易中继存储实现
+type MySQLRelayStorage struct{}
+
+// NewMySQLRelayStorage 创建MySQL元交易中继存储
+func NewMySQLRelayStorage() *MySQLRelayStorage {
+	return &MySQLRelayStorage{}
+}
+
+// InitRelayTables 初始化中继交易和代付额度表
+func (s *MySQLRelayStorage) InitRelayTables() error {
+	return DB.AutoMigrate(&RelayTransaction{}, &RelayBudget{})
+}
+
+// SaveRelayTransaction 保存中继交易
+func (s *MySQLRelayStorage) SaveRelayTransaction(tx *RelayTransaction) error {
+	return DB.Create(tx).Error
+}
+
+// GetRelayTransaction 按ID获取中继交易
+func (s *MySQLRelayStorage) GetRelayTransaction(id string) (*RelayTransaction, error) {
+	var tx RelayTransaction
+	if err := DB.Where("id = ?", id).First(&tx).Error; err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// GetRelayTransactionByRequestHash 按ForwardRequest哈希获取中继交易
+func (s *MySQLRelayStorage) GetRelayTransactionByRequestHash(requestHash string) (*RelayTransaction, error) {
+	var tx RelayTransaction
+	if err := DB.Where("request_hash = ?", requestHash).First(&tx).Error; err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// ListRelayTransactions 获取用户在since之后的中继交易，按创建时间降序，地址不区分大小写
+func (s *MySQLRelayStorage) ListRelayTransactions(chainType string, user string, since int64) ([]*RelayTransaction, error) {
+	var txs []*RelayTransaction
+	err := DB.Where("chain_type = ? AND LOWER(user_address) = LOWER(?) AND create_time >= ?", chainType, user, since).
+		Order("create_time DESC").Find(&txs).Error
+	if err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
+
+// UpdateRelayTransactionHash 记录预留额度的中继交易广播后的交易哈希
+func (s *MySQLRelayStorage) UpdateRelayTransactionHash(id string, txHash string) error {
+	return DB.Model(&RelayTransaction{}).Where("id = ?", id).Update("tx_hash", txHash).Error
+}
+
+// DeleteRelayTransaction 删除未能广播的中继交易，释放预留的额度
+func (s *MySQLRelayStorage) DeleteRelayTransaction(id string) error {
+	return DB.Where("id = ?", id).Delete(&RelayTransaction{}).Error
+}
+
+// UpdateRelayTransactionResult 记录中继交易的上链结果和实际费用
+func (s *MySQLRelayStorage) UpdateRelayTransactionResult(id string, status string, cost string) error {
+	return DB.Model(&RelayTransaction{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status": status,
+		"cost":   cost,
+	}).Error
+}
+
+// GetRelayBudget 获取用户的代付额度设置，未设置时返回nil
+func (s *MySQLRelayStorage) GetRelayBudget(chainType string, user string) (*RelayBudget, error) {
+	var budgets []*RelayBudget
+	if err := DB.Where("chain_type = ? AND user_address = ?", chainType, user).Limit(1).Find(&budgets).Error; err != nil {
+		return nil, err
+	}
+	if len(budgets) == 0 {
+		return nil, nil
+	}
+	return budgets[0], nil
+}
+
+// SaveRelayBudget 保存用户的代付额度设置
+func (s *MySQLRelayStorage) SaveRelayBudget(budget *RelayBudget) error {
+	return DB.Save(budget).Error
+}
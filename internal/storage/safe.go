@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"time"
+)
+
+// Safe交易状态
+const (
+	SafeTxPending  = "PENDING"  // 收集所有者签名中
+	SafeTxExecuted = "EXECUTED" // execTransaction已提交
+	SafeTxReplaced = "REPLACED" // 同一nonce的其他交易已执行，本交易作废
+)
+
+// SafeTransaction Safe多签交易提案
+type SafeTransaction struct {
+	ID         string `gorm:"primaryKey;type:varchar(100)"`
+	ChainType  string `gorm:"type:varchar(50)"`
+	Safe       string `gorm:"index;type:varchar(100)"`
+	Nonce      uint64 `gorm:"index"`
+	SafeTxHash string `gorm:"uniqueIndex;type:varchar(100)"`
+	Payload    string `gorm:"type:text"` // SafeTx JSON，执行时据此重建execTransaction
+	Status     string `gorm:"type:varchar(20)"`
+	ExecTxHash string `gorm:"type:varchar(100)"`
+	CreateTime int64
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
+
+// SafeConfirmation Safe所有者对交易提案的签名
+type SafeConfirmation struct {
+	ID         string `gorm:"primaryKey;type:varchar(100)"`
+	SafeTxHash string `gorm:"uniqueIndex:idx_safe_confirmation;type:varchar(100)"`
+	Owner      string `gorm:"uniqueIndex:idx_safe_confirmation;type:varchar(100)"`
+	Signature  string `gorm:"type:varchar(200)"` // 0x开头的65字节签名
+	CreateTime int64
+}
+
+// MySQLSafeStorage MySQL Safe多签交易存储实现
+type MySQLSafeStorage struct{}
+
+// NewMySQLSafeStorage 创建MySQL Safe多签交易存储
+func NewMySQLSafeStorage() *MySQLSafeStorage {
+	return &MySQLSafeStorage{}
+}
+
+// InitSafeTables 初始化Safe多签交易表
+func (s *MySQLSafeStorage) InitSafeTables() error {
+	return DB.AutoMigrate(&SafeTransaction{}, &SafeConfirmation{})
+}
+
+// SaveSafeTransaction 保存交易提案
+func (s *MySQLSafeStorage) SaveSafeTransaction(tx *SafeTransaction) error {
+	return DB.Create(tx).Error
+}
+
+// GetSafeTransaction 按safeTxHash获取交易提案
+func (s *MySQLSafeStorage) GetSafeTransaction(safeTxHash string) (*SafeTransaction, error) {
+	var tx SafeTransaction
+	if err := DB.Where("safe_tx_hash = ?", safeTxHash).First(&tx).Error; err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// ListSafeTransactions 获取Safe的交易提案，按nonce降序，地址不区分大小写
+func (s *MySQLSafeStorage) ListSafeTransactions(chainType string, safe string) ([]*SafeTransaction, error) {
+	var txs []*SafeTransaction
+	err := DB.Where("chain_type = ? AND LOWER(safe) = LOWER(?)", chainType, safe).Order("nonce DESC, create_time DESC").Find(&txs).Error
+	if err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
+
+// MarkSafeTransactionExecuted 标记交易已执行，同一nonce的其他待签名提案标记为作废
+func (s *MySQLSafeStorage) MarkSafeTransactionExecuted(tx *SafeTransaction, execTxHash string) error {
+	if err := DB.Model(&SafeTransaction{}).Where("safe_tx_hash = ?", tx.SafeTxHash).
+		Updates(map[string]interface{}{"status": SafeTxExecuted, "exec_tx_hash": execTxHash}).Error; err != nil {
+		return err
+	}
+	return DB.Model(&SafeTransaction{}).
+		Where("chain_type = ? AND safe = ? AND nonce = ? AND status = ?", tx.ChainType, tx.Safe, tx.Nonce, SafeTxPending).
+		Update("status", SafeTxReplaced).Error
+}
+
+// SaveSafeConfirmation 保存所有者签名
+func (s *MySQLSafeStorage) SaveSafeConfirmation(confirmation *SafeConfirmation) error {
+	return DB.Create(confirmation).Error
+}
+
+// GetSafeConfirmations 获取交易提案已收集的签名
+func (s *MySQLSafeStorage) GetSafeConfirmations(safeTxHash string) ([]*SafeConfirmation, error) {
+	var confirmations []*SafeConfirmation
+	err := DB.Where("safe_tx_hash = ?", safeTxHash).Order("create_time ASC").Find(&confirmations).Error
+	if err != nil {
+		return nil, err
+	}
+	return confirmations, nil
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"time"
+)
+
+// SigningSession 绑定到托管钱包的会话密钥，只能在授权范围内签名交易
+type SigningSession struct {
+	ID            string `gorm:"primaryKey;type:varchar(100)"`
+	WalletID      string `gorm:"index;type:varchar(100)"`
+	ChainType     string `gorm:"type:varchar(50)"`
+	Label         string `gorm:"type:varchar(200)"`
+	TokenHash     string `gorm:"uniqueIndex;type:varchar(100)"` // 会话令牌的SHA-256，令牌本身只在创建时返回
+	Scopes        string `gorm:"type:text"`                     // 允许调用的合约和方法，JSON
+	MaxValuePerTx string `gorm:"type:varchar(100)"`             // 单笔交易的value上限，空表示不限
+	TotalBudget   string `gorm:"type:varchar(100)"`             // 会话内累计value上限，空表示不限
+	Spent         string `gorm:"type:varchar(100)"`             // 已签名交易的累计value
+	TokenSpent    string `gorm:"type:text"`                     // 各代币已转移和授权的累计金额，JSON
+	TxCount       int
+	ExpiresAt     int64
+	RevokedAt     int64
+	LastUsedAt    int64
+	CreateTime    int64
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+}
+
+// MySQLSessionStorage MySQL会话密钥存储实现
+type MySQLSessionStorage struct{}
+
+// NewMySQLSessionStorage 创建MySQL会话密钥存储
+func NewMySQLSessionStorage() *MySQLSessionStorage {
+	return &MySQLSessionStorage{}
+}
+
+// InitSessionTable 初始化会话密钥表
+func (s *MySQLSessionStorage) InitSessionTable() error {
+	return DB.AutoMigrate(&SigningSession{})
+}
+
+// SaveSession 保存会话
+func (s *MySQLSessionStorage) SaveSession(session *SigningSession) error {
+	return DB.Create(session).Error
+}
+
+// GetSession 按ID获取会话
+func (s *MySQLSessionStorage) GetSession(id string) (*SigningSession, error) {
+	var session SigningSession
+	if err := DB.Where("id = ?", id).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetSessionByTokenHash 按令牌哈希获取会话
+func (s *MySQLSessionStorage) GetSessionByTokenHash(tokenHash string) (*SigningSession, error) {
+	var session SigningSession
+	if err := DB.Where("token_hash = ?", tokenHash).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListSessions 获取钱包的会话，按创建时间降序
+func (s *MySQLSessionStorage) ListSessions(walletID string) ([]*SigningSession, error) {
+	var sessions []*SigningSession
+	if err := DB.Where("wallet_id = ?", walletID).Order("create_time DESC").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// RecordSessionUsage 记录会话签名的交易，累加已用额度
+func (s *MySQLSessionStorage) RecordSessionUsage(id string, spent string, tokenSpent string, txCount int, lastUsedAt int64) error {
+	return DB.Model(&SigningSession{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"spent":        spent,
+		"token_spent":  tokenSpent,
+		"tx_count":     txCount,
+		"last_used_at": lastUsedAt,
+	}).Error
+}
+
+// RevokeSession 吊销会话
+func (s *MySQLSessionStorage) RevokeSession(id string, revokedAt int64) error {
+	return DB.Model(&SigningSession{}).Where("id = ?", id).Update("revoked_at", revokedAt).Error
+}
@@ -20,6 +20,8 @@ type BridgeTransaction struct {
 	Status          string    // 交易状态
 	CreateTime      int64     // 创建时间
 	UpdatedAt       time.Time // 更新时间
+	ScreeningStatus string    `gorm:"index;type:varchar(20)"` // 地址筛查结论，未启用筛查时为空
+	Screening       string    `gorm:"type:text"`              // 地址筛查结果，JSON
 }
 
 // SaveBridgeTransaction 保存跨链交易记录
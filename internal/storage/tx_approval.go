@@ -0,0 +1,266 @@
+package storage
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 审批请求状态
+const (
+	TxApprovalPending  = "pending"  // 收集审批中
+	TxApprovalApproved = "approved" // 已达到法定人数，等待签名
+	TxApprovalRejected = "rejected" // 有审批人拒绝
+	TxApprovalExpired  = "expired"  // 签名前已过有效期
+	TxApprovalSigned   = "signed"   // 已签名，等待发送
+	TxApprovalSent     = "sent"     // 已发送
+	TxApprovalApplied  = "applied"  // 规则变更已生效
+)
+
+// TxApprovalRule 钱包的交易审批规则，设置后该钱包的签名须经Threshold个审批人批准
+type TxApprovalRule struct {
+	WalletID   string `gorm:"primaryKey;type:varchar(100)"`
+	ChainType  string `gorm:"index:idx_tx_approval_rule_address;type:varchar(50)"`
+	Address    string `gorm:"index:idx_tx_approval_rule_address;type:varchar(100)"` // 钱包地址，用于识别已签名交易的发送方
+	Threshold  int
+	TTL        int64 // 审批请求的有效期，秒
+	CreateTime int64
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
+
+// TxApprover 审批人，凭令牌审批，令牌本身只在设置规则时返回
+type TxApprover struct {
+	ID         string `gorm:"primaryKey;type:varchar(100)"`
+	WalletID   string `gorm:"index;type:varchar(100)"`
+	Name       string `gorm:"type:varchar(100)"`
+	TokenHash  string `gorm:"uniqueIndex;type:varchar(100)"` // 审批令牌的SHA-256
+	CreateTime int64
+}
+
+// TxApprovalRequest 待审批的签名请求
+type TxApprovalRequest struct {
+	ID          string `gorm:"primaryKey;type:varchar(100)"`
+	WalletID    string `gorm:"index:idx_tx_approval_request_payload;type:varchar(100)"`
+	ChainType   string `gorm:"type:varchar(50)"`
+	Kind        string `gorm:"type:varchar(20)"`                                        // transaction、typed_data、user_operation或rule_change
+	Content     string `gorm:"type:text"`                                               // 待签名的交易、EIP-712数据、UserOperation或规则变更，JSON
+	PayloadHash string `gorm:"index:idx_tx_approval_request_payload;type:varchar(100)"` // 待签名数据的SHA-256
+	RequestedBy string `gorm:"type:varchar(100)"`
+	RequesterID string `gorm:"type:varchar(100)"` // 凭审批令牌认证的发起人，不能审批自己的请求
+	Note        string `gorm:"type:varchar(500)"`
+	Threshold   int
+	Status      string `gorm:"index;type:varchar(20)"`
+	ExpiresAt   int64
+	SignedHash  string `gorm:"index;type:varchar(100)"` // 签名结果的SHA-256，发送时据此匹配
+	TxHash      string `gorm:"type:varchar(150)"`
+	CreateTime  int64
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+// TxApprovalVote 审批人对请求的批准或拒绝，每人只能表态一次
+type TxApprovalVote struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement"`
+	RequestID    string `gorm:"uniqueIndex:idx_tx_approval_vote;type:varchar(100)"`
+	ApproverID   string `gorm:"uniqueIndex:idx_tx_approval_vote;type:varchar(100)"`
+	ApproverName string `gorm:"type:varchar(100)"`
+	Decision     string `gorm:"type:varchar(20)"` // approve/reject
+	Comment      string `gorm:"type:varchar(500)"`
+	CreateTime   int64
+}
+
+// TxApprovalAudit 审批流程的审计日志，只追加不修改
+type TxApprovalAudit struct {
+	ID         uint   `gorm:"primaryKey;autoIncrement"`
+	RequestID  string `gorm:"index;type:varchar(100)"` // 规则变更等与请求无关的记录为空
+	WalletID   string `gorm:"index;type:varchar(100)"`
+	Actor      string `gorm:"type:varchar(100)"`
+	Action     string `gorm:"type:varchar(50)"`
+	Detail     string `gorm:"type:text"`
+	CreateTime int64  `gorm:"index"`
+}
+
+// MySQLTxApprovalStorage MySQL交易审批存储实现
+type MySQLTxApprovalStorage struct{}
+
+// NewMySQLTxApprovalStorage 创建MySQL交易审批存储
+func NewMySQLTxApprovalStorage() *MySQLTxApprovalStorage {
+	return &MySQLTxApprovalStorage{}
+}
+
+// InitTxApprovalTables 初始化交易审批相关表
+func (s *MySQLTxApprovalStorage) InitTxApprovalTables() error {
+	return DB.AutoMigrate(&TxApprovalRule{}, &TxApprover{}, &TxApprovalRequest{}, &TxApprovalVote{}, &TxApprovalAudit{})
+}
+
+// GetRule 获取钱包的审批规则，未设置时返回nil
+func (s *MySQLTxApprovalStorage) GetRule(walletID string) (*TxApprovalRule, error) {
+	var rules []*TxApprovalRule
+	if err := DB.Where("wallet_id = ?", walletID).Limit(1).Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	return rules[0], nil
+}
+
+// GetRuleByAddress 按链和地址获取审批规则，地址不区分大小写，未设置时返回nil
+func (s *MySQLTxApprovalStorage) GetRuleByAddress(chainType string, address string) (*TxApprovalRule, error) {
+	var rules []*TxApprovalRule
+	if err := DB.Where("chain_type = ? AND LOWER(address) = LOWER(?)", chainType, address).Limit(1).Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	return rules[0], nil
+}
+
+// SaveRule 保存审批规则并替换审批人
+func (s *MySQLTxApprovalStorage) SaveRule(rule *TxApprovalRule, approvers []*TxApprover) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(rule).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("wallet_id = ?", rule.WalletID).Delete(&TxApprover{}).Error; err != nil {
+			return err
+		}
+		if len(approvers) == 0 {
+			return nil
+		}
+		return tx.Create(&approvers).Error
+	})
+}
+
+// DeleteRule 删除审批规则和审批人
+func (s *MySQLTxApprovalStorage) DeleteRule(walletID string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("wallet_id = ?", walletID).Delete(&TxApprover{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("wallet_id = ?", walletID).Delete(&TxApprovalRule{}).Error
+	})
+}
+
+// ListApprovers 获取钱包的审批人
+func (s *MySQLTxApprovalStorage) ListApprovers(walletID string) ([]*TxApprover, error) {
+	var approvers []*TxApprover
+	if err := DB.Where("wallet_id = ?", walletID).Order("name").Find(&approvers).Error; err != nil {
+		return nil, err
+	}
+	return approvers, nil
+}
+
+// GetApproverByTokenHash 按令牌哈希获取审批人
+func (s *MySQLTxApprovalStorage) GetApproverByTokenHash(tokenHash string) (*TxApprover, error) {
+	var approver TxApprover
+	if err := DB.Where("token_hash = ?", tokenHash).First(&approver).Error; err != nil {
+		return nil, err
+	}
+	return &approver, nil
+}
+
+// SaveRequest 保存新的审批请求
+func (s *MySQLTxApprovalStorage) SaveRequest(request *TxApprovalRequest) error {
+	return DB.Create(request).Error
+}
+
+// GetRequest 按ID获取审批请求
+func (s *MySQLTxApprovalStorage) GetRequest(id string) (*TxApprovalRequest, error) {
+	var request TxApprovalRequest
+	if err := DB.Where("id = ?", id).First(&request).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// FindRequestByPayload 获取钱包对同一待签名数据的最新请求，不存在时返回nil
+func (s *MySQLTxApprovalStorage) FindRequestByPayload(walletID string, payloadHash string) (*TxApprovalRequest, error) {
+	var requests []*TxApprovalRequest
+	err := DB.Where("wallet_id = ? AND payload_hash = ?", walletID, payloadHash).
+		Order("create_time DESC").Limit(1).Find(&requests).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		return nil, nil
+	}
+	return requests[0], nil
+}
+
+// FindRequestBySignedHash 按签名结果获取请求，不存在时返回nil
+func (s *MySQLTxApprovalStorage) FindRequestBySignedHash(signedHash string) (*TxApprovalRequest, error) {
+	var requests []*TxApprovalRequest
+	if err := DB.Where("signed_hash = ?", signedHash).Limit(1).Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	if len(requests) == 0 {
+		return nil, nil
+	}
+	return requests[0], nil
+}
+
+// ListRequests 获取钱包的审批请求，status为空时返回全部，按创建时间降序
+func (s *MySQLTxApprovalStorage) ListRequests(walletID string, status string) ([]*TxApprovalRequest, error) {
+	query := DB.Where("wallet_id = ?", walletID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var requests []*TxApprovalRequest
+	if err := query.Order("create_time DESC").Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// ListExpiredRequests 获取已过有效期但仍待审批或待签名的请求
+func (s *MySQLTxApprovalStorage) ListExpiredRequests(now int64) ([]*TxApprovalRequest, error) {
+	var requests []*TxApprovalRequest
+	err := DB.Where("status IN ? AND expires_at <= ?", []string{TxApprovalPending, TxApprovalApproved}, now).Find(&requests).Error
+	if err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// SaveVote 保存表态
+func (s *MySQLTxApprovalStorage) SaveVote(vote *TxApprovalVote) error {
+	return DB.Create(vote).Error
+}
+
+// GetVotes 获取请求的表态，按表态时间升序
+func (s *MySQLTxApprovalStorage) GetVotes(requestID string) ([]*TxApprovalVote, error) {
+	var votes []*TxApprovalVote
+	if err := DB.Where("request_id = ?", requestID).Order("id").Find(&votes).Error; err != nil {
+		return nil, err
+	}
+	return votes, nil
+}
+
+// UpdateRequest 更新请求状态及签名、发送结果
+func (s *MySQLTxApprovalStorage) UpdateRequest(id string, fields map[string]interface{}) error {
+	return DB.Model(&TxApprovalRequest{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// AppendAudit 追加审计日志
+func (s *MySQLTxApprovalStorage) AppendAudit(entry *TxApprovalAudit) error {
+	return DB.Create(entry).Error
+}
+
+// ListRequestAudit 获取请求的审计日志，按时间升序
+func (s *MySQLTxApprovalStorage) ListRequestAudit(requestID string) ([]*TxApprovalAudit, error) {
+	var entries []*TxApprovalAudit
+	if err := DB.Where("request_id = ?", requestID).Order("id").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// ListWalletAudit 获取钱包在since之后的审计日志，按时间升序
+func (s *MySQLTxApprovalStorage) ListWalletAudit(walletID string, since int64) ([]*TxApprovalAudit, error) {
+	var entries []*TxApprovalAudit
+	if err := DB.Where("wallet_id = ? AND create_time >= ?", walletID, since).Order("id").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
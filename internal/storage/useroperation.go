@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"time"
+)
+
+// UserOperation状态
+const (
+	UserOpPending = "PENDING" // 已提交到bundler，等待打包
+	UserOpSuccess = "SUCCESS"
+	UserOpFailed  = "FAILED" // 已上链但账户调用执行失败
+)
+
+// UserOperation 通过bundler提交的ERC-4337 UserOperation
+type UserOperation struct {
+	ID          string `gorm:"primaryKey;type:varchar(100)"`
+	ChainType   string `gorm:"type:varchar(50)"`
+	UserOpHash  string `gorm:"uniqueIndex;type:varchar(100)"`
+	Sender      string `gorm:"index;type:varchar(100)"` // 智能账户地址
+	WalletID    string `gorm:"type:varchar(100)"`       // 签名的所有者钱包
+	Payload     string `gorm:"type:text"`               // UserOperation JSON
+	Status      string `gorm:"type:varchar(20)"`
+	TxHash      string `gorm:"type:varchar(100)"` // 打包该UserOperation的handleOps交易
+	BlockNumber uint64
+	GasCost     string `gorm:"type:varchar(100)"` // 实际支付的gas费用（wei），使用paymaster时由paymaster支付
+	CreateTime  int64
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+// MySQLUserOperationStorage MySQL UserOperation存储实现
+type MySQLUserOperationStorage struct{}
+
+// NewMySQLUserOperationStorage 创建MySQL UserOperation存储
+func NewMySQLUserOperationStorage() *MySQLUserOperationStorage {
+	return &MySQLUserOperationStorage{}
+}
+
+// InitUserOperationTable 初始化UserOperation表
+func (s *MySQLUserOperationStorage) InitUserOperationTable() error {
+	return DB.AutoMigrate(&UserOperation{})
+}
+
+// SaveUserOperation 保存UserOperation
+func (s *MySQLUserOperationStorage) SaveUserOperation(op *UserOperation) error {
+	return DB.Create(op).Error
+}
+
+// GetUserOperation 按userOpHash获取UserOperation
+func (s *MySQLUserOperationStorage) GetUserOperation(userOpHash string) (*UserOperation, error) {
+	var op UserOperation
+	if err := DB.Where("user_op_hash = ?", userOpHash).First(&op).Error; err != nil {
+		return nil, err
+	}
+	return &op, nil
+}
+
+// ListUserOperations 获取智能账户的UserOperation，按创建时间降序，地址不区分大小写
+func (s *MySQLUserOperationStorage) ListUserOperations(chainType string, sender string) ([]*UserOperation, error) {
+	var ops []*UserOperation
+	err := DB.Where("chain_type = ? AND LOWER(sender) = LOWER(?)", chainType, sender).Order("create_time DESC").Find(&ops).Error
+	if err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+// UpdateUserOperationResult 记录UserOperation的上链结果
+func (s *MySQLUserOperationStorage) UpdateUserOperationResult(userOpHash string, status string, txHash string, blockNumber uint64, gasCost string) error {
+	return DB.Model(&UserOperation{}).Where("user_op_hash = ?", userOpHash).Updates(map[string]interface{}{
+		"status":       status,
+		"tx_hash":      txHash,
+		"block_number": blockNumber,
+		"gas_cost":     gasCost,
+	}).Error
+}
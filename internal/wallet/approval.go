@@ -0,0 +1,41 @@
+package wallet
+
+import (
+	"context"
+	"math/big"
+)
+
+// ApprovalScanner 支持ERC20授权扫描和撤销的钱包（EVM链）
+type ApprovalScanner interface {
+	// 获取最新区块高度
+	LatestBlock(ctx context.Context) (uint64, error)
+
+	// 获取owner在[fromBlock, toBlock]区间内发出的Approval事件
+	GetApprovalEvents(ctx context.Context, owner string, fromBlock uint64, toBlock uint64) ([]ApprovalEvent, error)
+
+	// 查询当前授权额度
+	GetAllowance(ctx context.Context, tokenAddress string, owner string, spender string) (*big.Int, error)
+
+	// 判断地址是否为合约
+	IsContract(ctx context.Context, address string) (bool, error)
+
+	// 构建approve(spender, 0)撤销交易，nonce依次递增
+	BuildRevokeTransactions(ctx context.Context, owner string, approvals []TokenSpender) ([][]byte, error)
+}
+
+// ApprovalEvent ERC20 Approval事件
+type ApprovalEvent struct {
+	Token       string   `json:"token"`
+	Owner       string   `json:"owner"`
+	Spender     string   `json:"spender"`
+	Value       *big.Int `json:"value"`
+	BlockNumber uint64   `json:"blockNumber"`
+	LogIndex    uint     `json:"logIndex"`
+	TxHash      string   `json:"txHash"`
+}
+
+// TokenSpender 代币与被授权地址
+type TokenSpender struct {
+	Token   string `json:"token"`
+	Spender string `json:"spender"`
+}
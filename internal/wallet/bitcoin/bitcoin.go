@@ -0,0 +1,299 @@
+package bitcoin
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/google/uuid"
+	"github.com/tyler-smith/go-bip39"
+
+	"multi-chain-wallet/internal/wallet"
+	"multi-chain-wallet/internal/wallet/hd"
+	"multi-chain-wallet/internal/wallet/keycrypt"
+)
+
+// 地址类型
+const (
+	AddressTypeP2WPKH = "p2wpkh" // BIP84 原生隔离见证
+	AddressTypeP2TR   = "p2tr"   // BIP86 Taproot单密钥
+)
+
+// regtest等无法估算费率的网络使用的默认费率，单位sat/vB
+const defaultFeeRate = 2
+
+// KeyStore 比特币钱包密钥库
+type KeyStore struct {
+	ID          string           `json:"id"`
+	Address     string           `json:"address"`
+	AddressType string           `json:"addressType"`
+	PrivKeyEnc  string           `json:"privKeyEnc"`
+	MnemonicEnc string           `json:"mnemonicEnc,omitempty"`
+	ChainType   wallet.ChainType `json:"chainType"`
+	CreateTime  int64            `json:"createTime"`
+}
+
+// BTCWallet 比特币钱包实现，通过bitcoind兼容的RPC查询UTXO和广播交易
+type BTCWallet struct {
+	rpc            *rpcClient
+	netParams      *chaincfg.Params
+	encryptionKey  []byte
+	mu             sync.RWMutex
+	keyMap         map[string]*KeyStore // walletID -> keystore
+	chainType      wallet.ChainType
+	addressType    string
+	defaultFeeRate int64
+	tracker        *utxoTracker
+}
+
+// NewBTCWallet 创建比特币钱包，network为mainnet/testnet3/regtest/signet
+func NewBTCWallet(chainType wallet.ChainType, rpcURL string, network string, addressType string, encryptionKey string) (*BTCWallet, error) {
+	netParams, err := networkParams(network)
+	if err != nil {
+		return nil, err
+	}
+
+	if addressType == "" {
+		addressType = AddressTypeP2WPKH
+	}
+	if addressType != AddressTypeP2WPKH && addressType != AddressTypeP2TR {
+		return nil, fmt.Errorf("unsupported bitcoin address type: %s", addressType)
+	}
+
+	return &BTCWallet{
+		rpc:            newRPCClient(rpcURL),
+		netParams:      netParams,
+		encryptionKey:  keycrypt.DeriveKey(encryptionKey),
+		keyMap:         make(map[string]*KeyStore),
+		chainType:      chainType,
+		addressType:    addressType,
+		defaultFeeRate: defaultFeeRate,
+		tracker:        newUTXOTracker(),
+	}, nil
+}
+
+// NewWalletFromChain 按链注册表中的链信息创建比特币钱包
+func NewWalletFromChain(chain wallet.ChainInfo, encryptionKey string) (*BTCWallet, error) {
+	if len(chain.RPCURLs) == 0 {
+		return nil, errors.New("no rpc url configured")
+	}
+	return NewBTCWallet(chain.ChainType, chain.RPCURLs[0], chain.Network, chain.AddressType, encryptionKey)
+}
+
+// NewFactory 创建比特币链的钱包工厂
+func NewFactory(encryptionKey string) wallet.WalletFactory {
+	return func(chain wallet.ChainInfo) (wallet.Wallet, error) {
+		return NewWalletFromChain(chain, encryptionKey)
+	}
+}
+
+// networkParams 按网络名获取链参数
+func networkParams(network string) (*chaincfg.Params, error) {
+	switch network {
+	case "", chaincfg.MainNetParams.Name:
+		return &chaincfg.MainNetParams, nil
+	case chaincfg.TestNet3Params.Name, "testnet":
+		return &chaincfg.TestNet3Params, nil
+	case chaincfg.RegressionNetParams.Name:
+		return &chaincfg.RegressionNetParams, nil
+	case chaincfg.SigNetParams.Name:
+		return &chaincfg.SigNetParams, nil
+	default:
+		return nil, fmt.Errorf("unknown bitcoin network: %s", network)
+	}
+}
+
+// derivationPath 按地址类型返回派生路径：BIP84 m/84'/coin'/0'/0/0，BIP86 m/86'/coin'/0'/0/0
+func (w *BTCWallet) derivationPath(addressType string) string {
+	purpose := 84
+	if addressType == AddressTypeP2TR {
+		purpose = 86
+	}
+	return fmt.Sprintf("m/%d'/%d'/0'/0/0", purpose, w.netParams.HDCoinType)
+}
+
+// addressFromKey 按地址类型由公钥生成地址
+func (w *BTCWallet) addressFromKey(pubKey *btcec.PublicKey, addressType string) (btcutil.Address, error) {
+	if addressType == AddressTypeP2TR {
+		outputKey := txscript.ComputeTaprootKeyNoScript(pubKey)
+		return btcutil.NewAddressTaproot(schnorr.SerializePubKey(outputKey), w.netParams)
+	}
+	return btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(pubKey.SerializeCompressed()), w.netParams)
+}
+
+// decodeAddress 解析并校验地址属于当前网络
+func (w *BTCWallet) decodeAddress(address string) (btcutil.Address, error) {
+	addr, err := btcutil.DecodeAddress(address, w.netParams)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address format: %v", err)
+	}
+	if !addr.IsForNet(w.netParams) {
+		return nil, fmt.Errorf("address %s is not for %s", address, w.netParams.Name)
+	}
+	return addr, nil
+}
+
+// saveKey 加密并保存私钥，返回钱包ID
+func (w *BTCWallet) saveKey(privKey *btcec.PrivateKey, mnemonic string) (string, error) {
+	address, err := w.addressFromKey(privKey.PubKey(), w.addressType)
+	if err != nil {
+		return "", fmt.Errorf("failed to derive address: %v", err)
+	}
+
+	privKeyEnc, err := keycrypt.Encrypt(w.encryptionKey, privKey.Serialize())
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt private key: %v", err)
+	}
+
+	var mnemonicEnc string
+	if mnemonic != "" {
+		mnemonicEnc, err = keycrypt.Encrypt(w.encryptionKey, []byte(mnemonic))
+		if err != nil {
+			return "", fmt.Errorf("failed to encrypt mnemonic: %v", err)
+		}
+	}
+
+	keystore := &KeyStore{
+		ID:          uuid.New().String(),
+		Address:     address.EncodeAddress(),
+		AddressType: w.addressType,
+		PrivKeyEnc:  privKeyEnc,
+		MnemonicEnc: mnemonicEnc,
+		ChainType:   w.chainType,
+		CreateTime:  time.Now().Unix(),
+	}
+
+	w.mu.Lock()
+	w.keyMap[keystore.ID] = keystore
+	w.mu.Unlock()
+
+	return keystore.ID, nil
+}
+
+// getKeyStore 获取钱包密钥库
+func (w *BTCWallet) getKeyStore(walletID string) (*KeyStore, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	keystore, exists := w.keyMap[walletID]
+	if !exists {
+		return nil, wallet.ErrWalletNotFound
+	}
+	return keystore, nil
+}
+
+// getPrivateKey 解密钱包私钥
+func (w *BTCWallet) getPrivateKey(walletID string) (*btcec.PrivateKey, *KeyStore, error) {
+	keystore, err := w.getKeyStore(walletID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyBytes, err := keycrypt.Decrypt(w.encryptionKey, keystore.PrivKeyEnc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt private key: %v", err)
+	}
+
+	privKey, _ := btcec.PrivKeyFromBytes(keyBytes)
+	return privKey, keystore, nil
+}
+
+// Create 创建新钱包
+func (w *BTCWallet) Create() (string, error) {
+	entropy, err := bip39.NewEntropy(256)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate mnemonic: %v", err)
+	}
+
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate mnemonic: %v", err)
+	}
+
+	return w.ImportFromMnemonic(mnemonic)
+}
+
+// ImportFromMnemonic 从助记词导入钱包，按地址类型使用BIP84或BIP86路径
+func (w *BTCWallet) ImportFromMnemonic(mnemonic string) (string, error) {
+	key, err := hd.DeriveFromMnemonic(mnemonic, w.derivationPath(w.addressType))
+	if err != nil {
+		return "", err
+	}
+
+	privKey, err := key.ECPrivKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to derive private key: %v", err)
+	}
+
+	return w.saveKey(privKey, mnemonic)
+}
+
+// ImportFromPrivateKey 从WIF或hex私钥导入钱包
+func (w *BTCWallet) ImportFromPrivateKey(privateKey string) (string, error) {
+	if wif, err := btcutil.DecodeWIF(privateKey); err == nil {
+		if !wif.IsForNet(w.netParams) {
+			return "", fmt.Errorf("private key is not for %s", w.netParams.Name)
+		}
+		return w.saveKey(wif.PrivKey, "")
+	}
+
+	keyBytes, err := hex.DecodeString(privateKey)
+	if err != nil || len(keyBytes) != btcec.PrivKeyBytesLen {
+		return "", errors.New("invalid private key format")
+	}
+
+	privKey, _ := btcec.PrivKeyFromBytes(keyBytes)
+	return w.saveKey(privKey, "")
+}
+
+// GetAddress 获取钱包地址
+func (w *BTCWallet) GetAddress(walletID string) (string, error) {
+	keystore, err := w.getKeyStore(walletID)
+	if err != nil {
+		return "", err
+	}
+	return keystore.Address, nil
+}
+
+// GetBalance 获取已确认余额，单位聪
+func (w *BTCWallet) GetBalance(ctx context.Context, address string) (*big.Int, error) {
+	utxos, err := w.ListUTXOs(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := big.NewInt(0)
+	for _, utxo := range utxos {
+		balance.Add(balance, big.NewInt(utxo.Value))
+	}
+	return balance, nil
+}
+
+// GetTokenBalance 比特币没有代币余额
+func (w *BTCWallet) GetTokenBalance(ctx context.Context, address string, tokenAddress string) (*big.Int, error) {
+	return nil, wallet.ErrOperationNotSupported
+}
+
+// SignTypedData 比特币不支持EIP-712
+func (w *BTCWallet) SignTypedData(ctx context.Context, walletID string, typedData []byte) ([]byte, error) {
+	return nil, wallet.ErrOperationNotSupported
+}
+
+// VerifyTypedData 比特币不支持EIP-712
+func (w *BTCWallet) VerifyTypedData(ctx context.Context, expectedSigner string, typedData []byte, signature []byte) (*wallet.SignatureVerification, error) {
+	return nil, wallet.ErrOperationNotSupported
+}
+
+// ChainType 获取链类型
+func (w *BTCWallet) ChainType() wallet.ChainType {
+	return w.chainType
+}
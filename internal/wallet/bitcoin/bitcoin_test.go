@@ -0,0 +1,220 @@
+package bitcoin
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// BIP84/BIP86测试向量的助记词
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestImportFromMnemonicDerivesBIP84AndBIP86(t *testing.T) {
+	tests := []struct {
+		addressType string
+		want        string
+	}{
+		{addressType: AddressTypeP2WPKH, want: "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu"},
+		{addressType: AddressTypeP2TR, want: "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addressType, func(t *testing.T) {
+			w, err := NewBTCWallet("bitcoin", "http://127.0.0.1:0", chaincfg.MainNetParams.Name, tt.addressType, "test-encryption-key")
+			if err != nil {
+				t.Fatal(err)
+			}
+			walletID, err := w.ImportFromMnemonic(testMnemonic)
+			if err != nil {
+				t.Fatalf("ImportFromMnemonic: %v", err)
+			}
+			address, err := w.GetAddress(walletID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if address != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, address)
+			}
+		})
+	}
+}
+
+func TestSelectCoins(t *testing.T) {
+	utxos := []*wallet.UTXO{{TxID: "a", Value: 5_000}, {TxID: "b", Value: 100_000}, {TxID: "c", Value: 20_000}}
+
+	tests := []struct {
+		name       string
+		target     int64
+		wantInputs int
+		wantChange bool
+		wantErr    error
+	}{
+		{name: "largest first with change", target: 50_000, wantInputs: 1, wantChange: true},
+		{name: "adds inputs until fee is covered", target: 100_000 - 200, wantInputs: 2, wantChange: true},
+		{name: "dust change goes to fee", target: 124_200, wantInputs: 3},
+		{name: "insufficient funds", target: 200_000, wantErr: wallet.ErrInsufficientFunds},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selection, err := selectCoins(utxos, tt.target, 2, 11+31, 68, 31)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selectCoins: %v", err)
+			}
+			if len(selection.inputs) != tt.wantInputs || (selection.change > 0) != tt.wantChange {
+				t.Fatalf("unexpected selection: %d inputs, change %d", len(selection.inputs), selection.change)
+			}
+			var total int64
+			for _, input := range selection.inputs {
+				total += input.Value
+			}
+			if total != tt.target+selection.fee+selection.change {
+				t.Fatalf("inputs %d do not cover target %d, fee %d and change %d", total, tt.target, selection.fee, selection.change)
+			}
+			if selection.change > 0 && selection.change < dustThreshold {
+				t.Fatalf("change %d below dust threshold", selection.change)
+			}
+		})
+	}
+}
+
+// newTestBitcoind 模拟bitcoind，scantxoutset返回给定的UTXO，不支持费率估算
+func newTestBitcoind(t *testing.T, unspents []map[string]interface{}) (*httptest.Server, *[]string) {
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid request: %v", err)
+			return
+		}
+
+		var result interface{}
+		switch req.Method {
+		case "scantxoutset":
+			result = map[string]interface{}{"success": true, "unspents": unspents}
+		case "estimatesmartfee":
+			result = map[string]interface{}{"errors": []string{"Insufficient data or no feerate found"}}
+		case "sendrawtransaction":
+			var txHex string
+			json.Unmarshal(req.Params[0], &txHex)
+			sent = append(sent, txHex)
+			result = "txid"
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": req.ID, "result": result})
+	}))
+	t.Cleanup(server.Close)
+	return server, &sent
+}
+
+// 构建的PSBT签名后通过脚本校验，输入在广播前被占用
+func TestCreateSignAndSendTransaction(t *testing.T) {
+	for _, addressType := range []string{AddressTypeP2WPKH, AddressTypeP2TR} {
+		t.Run(addressType, func(t *testing.T) {
+			w, err := NewBTCWallet("bitcoin", "", chaincfg.RegressionNetParams.Name, addressType, "test-encryption-key")
+			if err != nil {
+				t.Fatal(err)
+			}
+			walletID, err := w.ImportFromMnemonic(testMnemonic)
+			if err != nil {
+				t.Fatal(err)
+			}
+			from, err := w.GetAddress(walletID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fromAddress, err := w.decodeAddress(from)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fromScript := payToAddr(t, fromAddress)
+
+			server, sent := newTestBitcoind(t, []map[string]interface{}{
+				{"txid": "01" + hex.EncodeToString(make([]byte, 31)), "vout": 0, "scriptPubKey": hex.EncodeToString(fromScript), "amount": 0.01, "height": 100},
+				{"txid": "02" + hex.EncodeToString(make([]byte, 31)), "vout": 1, "scriptPubKey": hex.EncodeToString(fromScript), "amount": 0.002, "height": 101},
+			})
+			w.rpc = newRPCClient(server.URL)
+			to := randomAddress(t)
+
+			tx, err := w.CreateTransaction(context.Background(), from, to.EncodeAddress(), big.NewInt(500_000), nil)
+			if err != nil {
+				t.Fatalf("CreateTransaction: %v", err)
+			}
+			if tx.Fee.GasPrice.Int64() != defaultFeeRate {
+				t.Fatalf("expected default fee rate, got %s", tx.Fee.GasPrice)
+			}
+			utxos, err := w.ListUTXOs(context.Background(), from)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !utxos[0].Reserved || utxos[1].Reserved {
+				t.Fatalf("expected only the selected utxo to be reserved: %+v %+v", utxos[0], utxos[1])
+			}
+
+			signed, err := w.SignTransaction(context.Background(), walletID, tx)
+			if err != nil {
+				t.Fatalf("SignTransaction: %v", err)
+			}
+			var msgTx wire.MsgTx
+			if err := msgTx.Deserialize(bytes.NewReader(signed.Payload)); err != nil {
+				t.Fatalf("signed payload is not a raw transaction: %v", err)
+			}
+			if signed.Hash != msgTx.TxHash().String() {
+				t.Fatalf("hash mismatch: %s != %s", signed.Hash, msgTx.TxHash())
+			}
+			prevOut := wire.NewTxOut(1_000_000, fromScript)
+			fetcher := txscript.NewCannedPrevOutputFetcher(prevOut.PkScript, prevOut.Value)
+			engine, err := txscript.NewEngine(fromScript, &msgTx, 0, txscript.StandardVerifyFlags, nil,
+				txscript.NewTxSigHashes(&msgTx, fetcher), prevOut.Value, fetcher)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := engine.Execute(); err != nil {
+				t.Fatalf("signature does not verify: %v", err)
+			}
+
+			if _, err := w.SendTransaction(context.Background(), signed); err != nil {
+				t.Fatalf("SendTransaction: %v", err)
+			}
+			if len(*sent) != 1 || (*sent)[0] != hex.EncodeToString(signed.Payload) {
+				t.Fatalf("unexpected broadcast: %v", *sent)
+			}
+		})
+	}
+}
+
+// 签名不属于该钱包的PSBT返回错误
+func TestSignTransactionRejectsForeignInputs(t *testing.T) {
+	w := newTestWallet(t)
+	walletID, err := w.ImportFromMnemonic(testMnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := testPSBT(t, randomAddress(t), wire.NewTxOut(250_000, payToAddr(t, randomAddress(t))))
+
+	tx := &wallet.UnsignedTx{ChainType: "bitcoin", Payload: payload}
+	if _, err := w.SignTransaction(context.Background(), walletID, tx); err == nil {
+		t.Fatal("expected error signing a psbt without wallet inputs")
+	}
+}
@@ -0,0 +1,108 @@
+package bitcoin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// 比特币消息签名前缀
+const messageMagic = "Bitcoin Signed Message:\n"
+
+// BIP-137签名头：27-30未压缩P2PKH，31-34压缩P2PKH，35-38 P2SH-P2WPKH，39-42 P2WPKH
+const (
+	bip137HeaderCompressed = 31
+	bip137HeaderP2WPKH     = 39
+)
+
+// messageHash 计算比特币签名消息哈希：double-SHA256(varstr(magic) || varstr(message))
+func messageHash(message []byte) []byte {
+	var buf bytes.Buffer
+	_ = wire.WriteVarString(&buf, 0, messageMagic)
+	_ = wire.WriteVarBytes(&buf, 0, message)
+	return chainhash.DoubleHashB(buf.Bytes())
+}
+
+// SignMessage 按BIP-137签名消息，返回65字节签名；Taproot地址的BIP-322签名暂不支持
+func (w *BTCWallet) SignMessage(ctx context.Context, walletID string, message []byte) ([]byte, error) {
+	privKey, keystore, err := w.getPrivateKey(walletID)
+	if err != nil {
+		return nil, err
+	}
+	if keystore.AddressType != AddressTypeP2WPKH {
+		return nil, fmt.Errorf("%w: message signing for %s addresses", wallet.ErrOperationNotSupported, keystore.AddressType)
+	}
+
+	sig := ecdsa.SignCompact(privKey, messageHash(message), true)
+
+	// SignCompact生成的是压缩P2PKH签名头，改为P2WPKH
+	sig[0] = sig[0] - bip137HeaderCompressed + bip137HeaderP2WPKH
+	return sig, nil
+}
+
+// VerifyMessage 校验BIP-137消息签名，恢复公钥后按签名头对应的地址类型比对
+func (w *BTCWallet) VerifyMessage(ctx context.Context, expectedSigner string, message []byte, signature []byte) (*wallet.SignatureVerification, error) {
+	if len(signature) != 65 {
+		return nil, errors.New("invalid signature length")
+	}
+
+	header := signature[0]
+	if header < 27 || header > 42 {
+		return nil, fmt.Errorf("invalid signature header: %d", header)
+	}
+
+	// RecoverCompact只接受P2PKH签名头，先换算回来
+	compact := make([]byte, 65)
+	copy(compact, signature)
+	recID := (header - 27) % 4
+	compressed := header >= bip137HeaderCompressed
+	if compressed {
+		compact[0] = bip137HeaderCompressed + recID
+	} else {
+		compact[0] = 27 + recID
+	}
+
+	pubKey, _, err := ecdsa.RecoverCompact(compact, messageHash(message))
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover public key: %v", err)
+	}
+
+	var serialized []byte
+	if compressed {
+		serialized = pubKey.SerializeCompressed()
+	} else {
+		serialized = pubKey.SerializeUncompressed()
+	}
+	pubKeyHash := btcutil.Hash160(serialized)
+
+	var address btcutil.Address
+	switch {
+	case header >= bip137HeaderP2WPKH:
+		address, err = btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, w.netParams)
+	case header >= 35:
+		// P2SH-P2WPKH：脚本为 OP_0 <20字节公钥哈希>
+		redeemScript := append([]byte{0x00, 0x14}, pubKeyHash...)
+		address, err = btcutil.NewAddressScriptHash(redeemScript, w.netParams)
+	default:
+		address, err = btcutil.NewAddressPubKeyHash(pubKeyHash, w.netParams)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive address: %v", err)
+	}
+
+	recovered := address.EncodeAddress()
+	return &wallet.SignatureVerification{
+		RecoveredAddress: recovered,
+		ExpectedSigner:   expectedSigner,
+		Valid:            recovered == expectedSigner,
+		Method:           wallet.VerifyMethodECRecover,
+	}, nil
+}
@@ -0,0 +1,348 @@
+package bitcoin
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// psbtMagic PSBT序列化的前缀，用于区分部分签名的PSBT和原始交易
+var psbtMagic = []byte("psbt\xff")
+
+// CreateTransaction 选币并构建未签名的PSBT，data非空时附加OP_RETURN输出。
+// Payload为序列化的PSBT
+func (w *BTCWallet) CreateTransaction(ctx context.Context, from string, to string, amount *big.Int, data []byte) (*wallet.UnsignedTx, error) {
+	fromAddress, err := w.decodeAddress(from)
+	if err != nil {
+		return nil, err
+	}
+	toAddress, err := w.decodeAddress(to)
+	if err != nil {
+		return nil, err
+	}
+	if amount == nil || amount.Sign() <= 0 || !amount.IsInt64() {
+		return nil, errors.New("invalid amount")
+	}
+	if amount.Int64() < dustThreshold {
+		return nil, fmt.Errorf("amount below dust threshold of %d sat", dustThreshold)
+	}
+
+	toScript, err := txscript.PayToAddrScript(toAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build output script: %v", err)
+	}
+	changeScript, err := txscript.PayToAddrScript(fromAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build change script: %v", err)
+	}
+
+	outputs := []*wire.TxOut{wire.NewTxOut(amount.Int64(), toScript)}
+	if len(data) > 0 {
+		nullData, err := txscript.NullDataScript(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build OP_RETURN output: %v", err)
+		}
+		outputs = append(outputs, wire.NewTxOut(0, nullData))
+	}
+
+	baseVSize := int64(txOverheadVSize)
+	for _, out := range outputs {
+		baseVSize += outputVSize(out.PkScript)
+	}
+
+	inputVSize := int64(p2wpkhInputVSize)
+	if txscript.IsPayToTaproot(changeScript) {
+		inputVSize = p2trInputVSize
+	} else if !txscript.IsPayToWitnessPubKeyHash(changeScript) {
+		return nil, fmt.Errorf("unsupported sender address type: %s", from)
+	}
+
+	utxos, err := w.listSpendable(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+
+	feeRate := w.estimateFeeRate(ctx)
+	selection, err := selectCoins(utxos, amount.Int64(), feeRate, baseVSize, inputVSize, outputVSize(changeScript))
+	if err != nil {
+		return nil, err
+	}
+
+	if selection.change > 0 {
+		outputs = append(outputs, wire.NewTxOut(selection.change, changeScript))
+	}
+
+	inputs := make([]*wire.OutPoint, 0, len(selection.inputs))
+	sequences := make([]uint32, 0, len(selection.inputs))
+	for _, utxo := range selection.inputs {
+		hash, err := chainhash.NewHashFromStr(utxo.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid utxo txid: %v", err)
+		}
+		inputs = append(inputs, wire.NewOutPoint(hash, utxo.Vout))
+		// 允许RBF替换
+		sequences = append(sequences, wire.MaxTxInSequenceNum-2)
+	}
+
+	packet, err := psbt.New(inputs, outputs, 2, 0, sequences)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create psbt: %v", err)
+	}
+
+	updater, err := psbt.NewUpdater(packet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create psbt updater: %v", err)
+	}
+	for i, utxo := range selection.inputs {
+		if err := updater.AddInWitnessUtxo(wire.NewTxOut(utxo.Value, changeScript), i); err != nil {
+			return nil, fmt.Errorf("failed to add witness utxo: %v", err)
+		}
+	}
+
+	var encoded bytes.Buffer
+	if err := packet.Serialize(&encoded); err != nil {
+		return nil, fmt.Errorf("failed to encode psbt: %v", err)
+	}
+
+	outpoints := make([]wire.OutPoint, 0, len(inputs))
+	for _, op := range inputs {
+		outpoints = append(outpoints, *op)
+	}
+	w.tracker.lock(outpoints, createReserveTTL)
+
+	return &wallet.UnsignedTx{
+		ChainType: w.chainType,
+		From:      from,
+		To:        to,
+		Value:     amount,
+		Fee: &wallet.TxFee{
+			GasPrice: big.NewInt(feeRate),
+			Amount:   big.NewInt(selection.fee),
+		},
+		Payload: encoded.Bytes(),
+	}, nil
+}
+
+// SignTransaction 用钱包私钥签名PSBT中属于该钱包的输入，全部输入签名后finalize并导出原始交易。
+// 还有其他签名者的输入时Payload为部分签名的PSBT，Hash为空，可作为UnsignedTx的Payload继续签名
+func (w *BTCWallet) SignTransaction(ctx context.Context, walletID string, tx *wallet.UnsignedTx) (*wallet.SignedTx, error) {
+	if err := tx.CheckChain(w.chainType); err != nil {
+		return nil, err
+	}
+
+	packet, err := psbt.NewFromRawBytes(bytes.NewReader(tx.Payload), false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode psbt: %v", err)
+	}
+
+	privKey, keystore, err := w.getPrivateKey(walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	ownAddress, err := w.decodeAddress(keystore.Address)
+	if err != nil {
+		return nil, err
+	}
+	ownScript, err := txscript.PayToAddrScript(ownAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build wallet script: %v", err)
+	}
+
+	// 计算签名哈希需要所有输入的前序输出
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut, len(packet.Inputs))
+	for i, input := range packet.Inputs {
+		if input.WitnessUtxo == nil {
+			return nil, fmt.Errorf("input %d missing witness utxo", i)
+		}
+		prevOuts[packet.UnsignedTx.TxIn[i].PreviousOutPoint] = input.WitnessUtxo
+	}
+	fetcher := txscript.NewMultiPrevOutFetcher(prevOuts)
+	sigHashes := txscript.NewTxSigHashes(packet.UnsignedTx, fetcher)
+
+	updater, err := psbt.NewUpdater(packet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create psbt updater: %v", err)
+	}
+
+	signed := 0
+	for i, input := range packet.Inputs {
+		prevOut := input.WitnessUtxo
+		if !bytes.Equal(prevOut.PkScript, ownScript) {
+			continue
+		}
+
+		if keystore.AddressType == AddressTypeP2TR {
+			sig, err := txscript.RawTxInTaprootSignature(packet.UnsignedTx, sigHashes, i,
+				prevOut.Value, prevOut.PkScript, []byte{}, txscript.SigHashDefault, privKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to sign input %d: %v", i, err)
+			}
+			packet.Inputs[i].TaprootKeySpendSig = sig
+		} else {
+			sig, err := txscript.RawTxInWitnessSignature(packet.UnsignedTx, sigHashes, i,
+				prevOut.Value, prevOut.PkScript, txscript.SigHashAll, privKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to sign input %d: %v", i, err)
+			}
+			if _, err := updater.Sign(i, sig, privKey.PubKey().SerializeCompressed(), nil, nil); err != nil {
+				return nil, fmt.Errorf("failed to add signature to input %d: %v", i, err)
+			}
+		}
+		signed++
+	}
+
+	if signed == 0 {
+		return nil, errors.New("no inputs belong to this wallet")
+	}
+
+	// 还有其他签名者的输入时只返回部分签名的PSBT
+	if err := psbt.MaybeFinalizeAll(packet); err != nil {
+		var buf bytes.Buffer
+		if err := packet.Serialize(&buf); err != nil {
+			return nil, fmt.Errorf("failed to encode psbt: %v", err)
+		}
+		return tx.Signed(buf.Bytes(), ""), nil
+	}
+
+	finalTx, err := psbt.Extract(packet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract transaction: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := finalTx.Serialize(&buf); err != nil {
+		return nil, fmt.Errorf("failed to serialize transaction: %v", err)
+	}
+	return tx.Signed(buf.Bytes(), finalTx.TxHash().String()), nil
+}
+
+// DecodePayload 解析PSBT中的转账。支付到任一输入脚本的输出视为找零，不含金额的OP_RETURN输出不计入转账，
+// 其他无法解析出地址的输出返回错误
+func (w *BTCWallet) DecodePayload(payload []byte) ([]*wallet.PayloadTransfer, error) {
+	packet, err := psbt.NewFromRawBytes(bytes.NewReader(payload), false)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to decode psbt: %v", wallet.ErrInvalidTransaction, err)
+	}
+
+	var from string
+	inputScripts := make([][]byte, 0, len(packet.Inputs))
+	for i, input := range packet.Inputs {
+		if input.WitnessUtxo == nil {
+			return nil, fmt.Errorf("%w: input %d missing witness utxo", wallet.ErrInvalidTransaction, i)
+		}
+		inputScripts = append(inputScripts, input.WitnessUtxo.PkScript)
+		if from == "" {
+			if _, addrs, _, err := txscript.ExtractPkScriptAddrs(input.WitnessUtxo.PkScript, w.netParams); err == nil && len(addrs) == 1 {
+				from = addrs[0].EncodeAddress()
+			}
+		}
+	}
+
+	var transfers []*wallet.PayloadTransfer
+outputs:
+	for i, out := range packet.UnsignedTx.TxOut {
+		for _, script := range inputScripts {
+			if bytes.Equal(out.PkScript, script) {
+				continue outputs
+			}
+		}
+		if txscript.GetScriptClass(out.PkScript) == txscript.NullDataTy && out.Value == 0 {
+			continue
+		}
+
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(out.PkScript, w.netParams)
+		if err != nil || len(addrs) != 1 {
+			return nil, fmt.Errorf("%w: unsupported script in output %d", wallet.ErrInvalidTransaction, i)
+		}
+		transfers = append(transfers, &wallet.PayloadTransfer{
+			From:  from,
+			To:    addrs[0].EncodeAddress(),
+			Value: big.NewInt(out.Value),
+		})
+	}
+	return transfers, nil
+}
+
+// DecodeSignedPayload 解析已签名交易中的转账。部分签名的PSBT按DecodePayload解析；
+// 完整的原始交易不含前序输出，无法区分找零，除不含金额的OP_RETURN外的输出都作为转账返回
+func (w *BTCWallet) DecodeSignedPayload(payload []byte) ([]*wallet.PayloadTransfer, error) {
+	if bytes.HasPrefix(payload, psbtMagic) {
+		return w.DecodePayload(payload)
+	}
+
+	var msgTx wire.MsgTx
+	if err := msgTx.Deserialize(bytes.NewReader(payload)); err != nil {
+		return nil, fmt.Errorf("%w: invalid raw transaction: %v", wallet.ErrInvalidTransaction, err)
+	}
+	var transfers []*wallet.PayloadTransfer
+	for i, out := range msgTx.TxOut {
+		if txscript.GetScriptClass(out.PkScript) == txscript.NullDataTy && out.Value == 0 {
+			continue
+		}
+		_, addrs, _, err := txscript.ExtractPkScriptAddrs(out.PkScript, w.netParams)
+		if err != nil || len(addrs) != 1 {
+			return nil, fmt.Errorf("%w: unsupported script in output %d", wallet.ErrInvalidTransaction, i)
+		}
+		transfers = append(transfers, &wallet.PayloadTransfer{
+			To:    addrs[0].EncodeAddress(),
+			Value: big.NewInt(out.Value),
+		})
+	}
+	return transfers, nil
+}
+
+// SendTransaction 广播已签名交易，并在确认前占用其输入
+func (w *BTCWallet) SendTransaction(ctx context.Context, signedTx *wallet.SignedTx) (string, error) {
+	if err := signedTx.CheckChain(w.chainType); err != nil {
+		return "", err
+	}
+	if bytes.HasPrefix(signedTx.Payload, psbtMagic) {
+		return "", errors.New("transaction is not fully signed")
+	}
+
+	var msgTx wire.MsgTx
+	if err := msgTx.Deserialize(bytes.NewReader(signedTx.Payload)); err != nil {
+		return "", fmt.Errorf("invalid raw transaction: %v", err)
+	}
+
+	txid, err := w.rpc.sendRawTransaction(ctx, hex.EncodeToString(signedTx.Payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to send transaction: %w", err)
+	}
+
+	outpoints := make([]wire.OutPoint, 0, len(msgTx.TxIn))
+	for _, in := range msgTx.TxIn {
+		outpoints = append(outpoints, in.PreviousOutPoint)
+	}
+	w.tracker.lock(outpoints, broadcastReserveTTL)
+
+	return txid, nil
+}
+
+// GetTransactionStatus 获取交易状态，需要节点开启txindex或交易仍在内存池中
+func (w *BTCWallet) GetTransactionStatus(ctx context.Context, txHash string) (string, error) {
+	confirmations, err := w.rpc.getConfirmations(ctx, txHash)
+	if err != nil {
+		var rpcErr *rpcError
+		if errors.As(err, &rpcErr) && rpcErr.Code == rpcErrInvalidAddressOrKey {
+			return "", errors.New("transaction not found")
+		}
+		return "", fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	if confirmations > 0 {
+		return string(wallet.TxConfirmed), nil
+	}
+	return string(wallet.TxPending), nil
+}
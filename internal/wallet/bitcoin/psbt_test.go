@@ -0,0 +1,130 @@
+package bitcoin
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+func newTestWallet(t *testing.T) *BTCWallet {
+	w, err := NewBTCWallet("bitcoin", "http://127.0.0.1:0", chaincfg.RegressionNetParams.Name, "", "test-encryption-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func randomAddress(t *testing.T) btcutil.Address {
+	key, err := btcec.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(key.PubKey().SerializeCompressed()), &chaincfg.RegressionNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
+
+func payToAddr(t *testing.T, addr btcutil.Address) []byte {
+	script, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+// testPSBT 构造花费from的一个输出的PSBT
+func testPSBT(t *testing.T, from btcutil.Address, outputs ...*wire.TxOut) []byte {
+	inputs := []*wire.OutPoint{wire.NewOutPoint(&chainhash.Hash{1}, 0)}
+	packet, err := psbt.New(inputs, outputs, 2, 0, []uint32{wire.MaxTxInSequenceNum})
+	if err != nil {
+		t.Fatal(err)
+	}
+	packet.Inputs[0].WitnessUtxo = wire.NewTxOut(1_000_000, payToAddr(t, from))
+
+	var buf bytes.Buffer
+	if err := packet.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodePayload(t *testing.T) {
+	w := newTestWallet(t)
+	from := randomAddress(t)
+	to := randomAddress(t)
+	nullData, err := txscript.NullDataScript([]byte("memo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 收款、OP_RETURN和找零三个输出，只有收款计入转账
+	payload := testPSBT(t, from,
+		wire.NewTxOut(250_000, payToAddr(t, to)),
+		wire.NewTxOut(0, nullData),
+		wire.NewTxOut(740_000, payToAddr(t, from)),
+	)
+
+	transfers, err := w.DecodePayload(payload)
+	if err != nil {
+		t.Fatalf("DecodePayload: %v", err)
+	}
+	if len(transfers) != 1 {
+		t.Fatalf("expected one transfer, got %d", len(transfers))
+	}
+	got := transfers[0]
+	if got.From != from.EncodeAddress() || got.To != to.EncodeAddress() || got.Value.Int64() != 250_000 || got.Token != "" {
+		t.Fatalf("unexpected transfer: %+v", got)
+	}
+}
+
+func TestDecodePayloadRejectsUnknownScript(t *testing.T) {
+	w := newTestWallet(t)
+	from := randomAddress(t)
+
+	// 带金额的OP_RETURN会销毁资金，无法解析出收款方
+	burn, err := txscript.NullDataScript([]byte("burn"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := testPSBT(t, from, wire.NewTxOut(250_000, burn))
+
+	if _, err := w.DecodePayload(payload); !errors.Is(err, wallet.ErrInvalidTransaction) {
+		t.Fatalf("expected ErrInvalidTransaction, got %v", err)
+	}
+}
+
+func TestDecodeSignedPayload(t *testing.T) {
+	w := newTestWallet(t)
+	to := randomAddress(t)
+	change := randomAddress(t)
+
+	// 原始交易不含前序输出，找零也作为转账返回
+	msgTx := wire.NewMsgTx(2)
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{1}, 0), nil, nil))
+	msgTx.AddTxOut(wire.NewTxOut(250_000, payToAddr(t, to)))
+	msgTx.AddTxOut(wire.NewTxOut(740_000, payToAddr(t, change)))
+	var buf bytes.Buffer
+	if err := msgTx.Serialize(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	transfers, err := w.DecodeSignedPayload(buf.Bytes())
+	if err != nil {
+		t.Fatalf("DecodeSignedPayload: %v", err)
+	}
+	if len(transfers) != 2 || transfers[0].To != to.EncodeAddress() || transfers[1].To != change.EncodeAddress() {
+		t.Fatalf("unexpected transfers: %+v", transfers)
+	}
+}
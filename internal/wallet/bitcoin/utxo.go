@@ -0,0 +1,191 @@
+package bitcoin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+const (
+	// 已构建但未广播的交易占用UTXO的时长
+	createReserveTTL = 10 * time.Minute
+	// 已广播的交易占用UTXO的时长，scantxoutset只反映已确认的UTXO集，确认前需要避免重复花费
+	broadcastReserveTTL = 24 * time.Hour
+)
+
+// 交易体积估算（vbytes）
+const (
+	txOverheadVSize   = 11 // 版本、锁定时间、输入输出计数及隔离见证标记
+	p2wpkhInputVSize  = 68
+	p2trInputVSize    = 58
+	dustThreshold     = 546 // 低于该值的找零并入手续费
+	defaultConfTarget = 6   // 费率估算的目标确认区块数
+)
+
+// utxoTracker 记录被待发送交易占用的UTXO，防止并发构建的交易花费同一输出
+type utxoTracker struct {
+	mu     sync.Mutex
+	locked map[wire.OutPoint]time.Time // outpoint -> 到期时间
+}
+
+// newUTXOTracker 创建UTXO跟踪器
+func newUTXOTracker() *utxoTracker {
+	return &utxoTracker{
+		locked: make(map[wire.OutPoint]time.Time),
+	}
+}
+
+// lock 占用UTXO直到ttl到期，已占用的输出延长到较晚的到期时间
+func (t *utxoTracker) lock(outpoints []wire.OutPoint, ttl time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	expiry := time.Now().Add(ttl)
+	for _, op := range outpoints {
+		if current, ok := t.locked[op]; !ok || current.Before(expiry) {
+			t.locked[op] = expiry
+		}
+	}
+}
+
+// isLocked 判断UTXO是否被占用，顺便清理过期记录
+func (t *utxoTracker) isLocked(op wire.OutPoint) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	expiry, ok := t.locked[op]
+	if !ok {
+		return false
+	}
+	if time.Now().After(expiry) {
+		delete(t.locked, op)
+		return false
+	}
+	return true
+}
+
+// ListUTXOs 列出地址已确认的UTXO
+func (w *BTCWallet) ListUTXOs(ctx context.Context, address string) ([]*wallet.UTXO, error) {
+	if _, err := w.decodeAddress(address); err != nil {
+		return nil, err
+	}
+
+	result, err := w.rpc.scanAddress(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan utxos: %w", err)
+	}
+
+	utxos := make([]*wallet.UTXO, 0, len(result.Unspents))
+	for _, unspent := range result.Unspents {
+		amount, err := btcutil.NewAmount(unspent.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("invalid utxo amount: %v", err)
+		}
+
+		hash, err := chainhash.NewHashFromStr(unspent.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid utxo txid: %v", err)
+		}
+
+		utxos = append(utxos, &wallet.UTXO{
+			TxID:         unspent.TxID,
+			Vout:         unspent.Vout,
+			Value:        int64(amount),
+			Address:      address,
+			ScriptPubKey: unspent.ScriptPubKey,
+			Height:       unspent.Height,
+			Reserved:     w.tracker.isLocked(wire.OutPoint{Hash: *hash, Index: unspent.Vout}),
+		})
+	}
+
+	return utxos, nil
+}
+
+// listSpendable 列出未被占用的UTXO
+func (w *BTCWallet) listSpendable(ctx context.Context, address string) ([]*wallet.UTXO, error) {
+	utxos, err := w.ListUTXOs(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	spendable := make([]*wallet.UTXO, 0, len(utxos))
+	for _, utxo := range utxos {
+		if !utxo.Reserved {
+			spendable = append(spendable, utxo)
+		}
+	}
+	return spendable, nil
+}
+
+// estimateFeeRate 估算费率，单位sat/vB；节点无法估算时（如regtest）使用默认费率
+func (w *BTCWallet) estimateFeeRate(ctx context.Context) int64 {
+	btcPerKvB, err := w.rpc.estimateSmartFee(ctx, defaultConfTarget)
+	if err != nil {
+		return w.defaultFeeRate
+	}
+
+	amount, err := btcutil.NewAmount(btcPerKvB)
+	if err != nil {
+		return w.defaultFeeRate
+	}
+
+	// sat/kvB -> sat/vB，向上取整
+	feeRate := (int64(amount) + 999) / 1000
+	if feeRate < 1 {
+		feeRate = 1
+	}
+	return feeRate
+}
+
+// coinSelection 选币结果
+type coinSelection struct {
+	inputs []*wallet.UTXO
+	fee    int64
+	change int64 // 0表示不找零
+}
+
+// selectCoins 按金额从大到小选择UTXO，直到覆盖目标金额和按费率计算的手续费。
+// baseVSize为交易固定部分与非找零输出的体积，找零低于粉尘阈值时并入手续费
+func selectCoins(utxos []*wallet.UTXO, target int64, feeRate int64, baseVSize int64, inputVSize int64, changeVSize int64) (*coinSelection, error) {
+	sorted := make([]*wallet.UTXO, len(utxos))
+	copy(sorted, utxos)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Value > sorted[j].Value
+	})
+
+	var total int64
+	selected := make([]*wallet.UTXO, 0)
+	for _, utxo := range sorted {
+		selected = append(selected, utxo)
+		total += utxo.Value
+
+		vsize := baseVSize + int64(len(selected))*inputVSize
+		feeWithoutChange := vsize * feeRate
+		if total < target+feeWithoutChange {
+			continue
+		}
+
+		feeWithChange := (vsize + changeVSize) * feeRate
+		change := total - target - feeWithChange
+		if change >= dustThreshold {
+			return &coinSelection{inputs: selected, fee: feeWithChange, change: change}, nil
+		}
+
+		return &coinSelection{inputs: selected, fee: total - target}, nil
+	}
+
+	return nil, fmt.Errorf("%w: need %d sat plus fees, have %d sat", wallet.ErrInsufficientFunds, target, total)
+}
+
+// outputVSize 输出的体积：金额8字节 + 脚本长度 + 脚本
+func outputVSize(pkScript []byte) int64 {
+	return int64(8 + wire.VarIntSerializeSize(uint64(len(pkScript))) + len(pkScript))
+}
@@ -0,0 +1,266 @@
+package cosmos
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/tyler-smith/go-bip39"
+
+	"multi-chain-wallet/internal/wallet"
+	"multi-chain-wallet/internal/wallet/ethereum"
+	"multi-chain-wallet/internal/wallet/keycrypt"
+)
+
+// Cosmos Hub的BIP44币种，大多数Cosmos SDK链沿用
+const defaultCoinType = 118
+
+// KeyStore Cosmos钱包密钥库
+type KeyStore struct {
+	ID          string           `json:"id"`
+	Address     string           `json:"address"`
+	PrivKeyEnc  string           `json:"privKeyEnc"`
+	MnemonicEnc string           `json:"mnemonicEnc,omitempty"`
+	ChainType   wallet.ChainType `json:"chainType"`
+	CreateTime  int64            `json:"createTime"`
+}
+
+// Config Cosmos SDK链的参数
+type Config struct {
+	ChainID      string  // chain-id，如cosmoshub-4
+	Bech32Prefix string  // 地址前缀，如cosmos
+	CoinType     uint32  // BIP44币种
+	Denom        string  // 原生代币denom，如uatom
+	GasPrice     float64 // 单位denom/gas
+}
+
+// CosmosWallet Cosmos SDK链钱包实现，通过REST（gRPC-gateway）查询和广播
+type CosmosWallet struct {
+	rest          *restClient
+	config        Config
+	encryptionKey []byte
+	mu            sync.RWMutex
+	keyMap        map[string]*KeyStore // walletID -> keystore
+	chainType     wallet.ChainType
+	broadcasts    map[string]time.Time // 本实例广播过的交易，打包前查询状态返回pending
+}
+
+// NewCosmosWallet 创建Cosmos钱包
+func NewCosmosWallet(chainType wallet.ChainType, restURL string, config Config, encryptionKey string) (*CosmosWallet, error) {
+	if config.ChainID == "" {
+		return nil, errors.New("cosmos chain id is required")
+	}
+	if config.Bech32Prefix == "" || config.Denom == "" {
+		return nil, errors.New("cosmos bech32 prefix and denom are required")
+	}
+	if config.CoinType == 0 {
+		config.CoinType = defaultCoinType
+	}
+
+	return &CosmosWallet{
+		rest:          newRESTClient(restURL),
+		config:        config,
+		encryptionKey: keycrypt.DeriveKey(encryptionKey),
+		keyMap:        make(map[string]*KeyStore),
+		chainType:     chainType,
+		broadcasts:    make(map[string]time.Time),
+	}, nil
+}
+
+// NewFactory 创建Cosmos链的钱包工厂，链信息中的Network为chain-id
+func NewFactory(encryptionKey string) wallet.WalletFactory {
+	return func(chain wallet.ChainInfo) (wallet.Wallet, error) {
+		if len(chain.RPCURLs) == 0 {
+			return nil, errors.New("no rpc url configured")
+		}
+		return NewCosmosWallet(chain.ChainType, chain.RPCURLs[0], Config{
+			ChainID:      chain.Network,
+			Bech32Prefix: chain.Bech32Prefix,
+			CoinType:     chain.CoinType,
+			Denom:        chain.Denom,
+			GasPrice:     chain.GasPrice,
+		}, encryptionKey)
+	}
+}
+
+// addressFromPublicKey 由压缩公钥计算bech32地址：ripemd160(sha256(pubkey))
+func (w *CosmosWallet) addressFromPublicKey(pub *ecdsa.PublicKey) (string, error) {
+	hash := btcutil.Hash160(crypto.CompressPubkey(pub))
+	converted, err := bech32.ConvertBits(hash, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	return bech32.Encode(w.config.Bech32Prefix, converted)
+}
+
+// validateAddress 校验地址为当前链前缀的bech32地址
+func (w *CosmosWallet) validateAddress(address string) error {
+	prefix, data, err := bech32.Decode(address)
+	if err != nil {
+		return fmt.Errorf("invalid address format: %v", err)
+	}
+	if prefix != w.config.Bech32Prefix {
+		return fmt.Errorf("address prefix %s does not match %s", prefix, w.config.Bech32Prefix)
+	}
+	decoded, err := bech32.ConvertBits(data, 5, 8, false)
+	if err != nil || (len(decoded) != 20 && len(decoded) != 32) {
+		return fmt.Errorf("invalid address format: %s", address)
+	}
+	return nil
+}
+
+// saveKey 加密并保存私钥，返回钱包ID
+func (w *CosmosWallet) saveKey(privateKey *ecdsa.PrivateKey, mnemonic string) (string, error) {
+	address, err := w.addressFromPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to derive address: %v", err)
+	}
+
+	privKeyEnc, err := keycrypt.Encrypt(w.encryptionKey, crypto.FromECDSA(privateKey))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt private key: %v", err)
+	}
+
+	var mnemonicEnc string
+	if mnemonic != "" {
+		mnemonicEnc, err = keycrypt.Encrypt(w.encryptionKey, []byte(mnemonic))
+		if err != nil {
+			return "", fmt.Errorf("failed to encrypt mnemonic: %v", err)
+		}
+	}
+
+	keystore := &KeyStore{
+		ID:          uuid.New().String(),
+		Address:     address,
+		PrivKeyEnc:  privKeyEnc,
+		MnemonicEnc: mnemonicEnc,
+		ChainType:   w.chainType,
+		CreateTime:  time.Now().Unix(),
+	}
+
+	w.mu.Lock()
+	w.keyMap[keystore.ID] = keystore
+	w.mu.Unlock()
+
+	return keystore.ID, nil
+}
+
+// getKeyStore 获取钱包密钥库
+func (w *CosmosWallet) getKeyStore(walletID string) (*KeyStore, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	keystore, exists := w.keyMap[walletID]
+	if !exists {
+		return nil, wallet.ErrWalletNotFound
+	}
+	return keystore, nil
+}
+
+// getPrivateKey 解密钱包私钥
+func (w *CosmosWallet) getPrivateKey(walletID string) (*ecdsa.PrivateKey, *KeyStore, error) {
+	keystore, err := w.getKeyStore(walletID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyBytes, err := keycrypt.Decrypt(w.encryptionKey, keystore.PrivKeyEnc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt private key: %v", err)
+	}
+
+	privateKey, err := crypto.ToECDSA(keyBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid private key: %v", err)
+	}
+	return privateKey, keystore, nil
+}
+
+// Create 创建新钱包
+func (w *CosmosWallet) Create() (string, error) {
+	entropy, err := bip39.NewEntropy(256)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate mnemonic: %v", err)
+	}
+
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate mnemonic: %v", err)
+	}
+
+	return w.ImportFromMnemonic(mnemonic)
+}
+
+// ImportFromMnemonic 从助记词导入钱包，路径为m/44'/{coinType}'/0'/0/0
+func (w *CosmosWallet) ImportFromMnemonic(mnemonic string) (string, error) {
+	path := fmt.Sprintf("m/44'/%d'/0'/0/0", w.config.CoinType)
+	privateKey, err := ethereum.DerivePrivateKey(mnemonic, path)
+	if err != nil {
+		return "", fmt.Errorf("failed to derive private key: %v", err)
+	}
+	return w.saveKey(privateKey, mnemonic)
+}
+
+// ImportFromPrivateKey 从hex私钥导入钱包
+func (w *CosmosWallet) ImportFromPrivateKey(privateKey string) (string, error) {
+	key, err := ethereum.ParsePrivateKey(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse private key: %v", err)
+	}
+	return w.saveKey(key, "")
+}
+
+// GetAddress 获取钱包地址
+func (w *CosmosWallet) GetAddress(walletID string) (string, error) {
+	keystore, err := w.getKeyStore(walletID)
+	if err != nil {
+		return "", err
+	}
+	return keystore.Address, nil
+}
+
+// GetBalance 获取原生代币余额，单位为最小denom
+func (w *CosmosWallet) GetBalance(ctx context.Context, address string) (*big.Int, error) {
+	return w.GetTokenBalance(ctx, address, w.config.Denom)
+}
+
+// GetTokenBalance 获取bank模块中任意denom的余额，tokenAddress为denom，如ibc/...或factory/...
+func (w *CosmosWallet) GetTokenBalance(ctx context.Context, address string, tokenAddress string) (*big.Int, error) {
+	if err := w.validateAddress(address); err != nil {
+		return nil, err
+	}
+
+	amount, err := w.rest.getBalance(ctx, address, tokenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance: %w", err)
+	}
+
+	balance, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid balance amount: %s", amount)
+	}
+	return balance, nil
+}
+
+// SignTypedData Cosmos不支持EIP-712
+func (w *CosmosWallet) SignTypedData(ctx context.Context, walletID string, typedData []byte) ([]byte, error) {
+	return nil, wallet.ErrOperationNotSupported
+}
+
+// VerifyTypedData Cosmos不支持EIP-712
+func (w *CosmosWallet) VerifyTypedData(ctx context.Context, expectedSigner string, typedData []byte, signature []byte) (*wallet.SignatureVerification, error) {
+	return nil, wallet.ErrOperationNotSupported
+}
+
+// ChainType 获取链类型
+func (w *CosmosWallet) ChainType() wallet.ChainType {
+	return w.chainType
+}
@@ -0,0 +1,185 @@
+package cosmos
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 与Keplr、gaiad一致的派生路径和地址
+func TestImportFromMnemonic(t *testing.T) {
+	w := newTestWallet(t)
+	walletID, err := w.ImportFromMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Fatalf("ImportFromMnemonic: %v", err)
+	}
+	address, err := w.GetAddress(walletID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "cosmos19rl4cm2hmr8afy4kldpxz3fka4jguq0auqdal4"; address != want {
+		t.Fatalf("expected %s, got %s", want, address)
+	}
+}
+
+func TestValidateAddress(t *testing.T) {
+	w := newTestWallet(t)
+	valid := randomAddress(t, w)
+	osmosisConfig := testConfig
+	osmosisConfig.Bech32Prefix = "osmo"
+	osmosis, err := NewCosmosWallet("osmosis", "http://127.0.0.1:0", osmosisConfig, "test-encryption-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		address string
+		wantErr bool
+	}{
+		{name: "valid", address: valid},
+		{name: "other prefix", address: randomAddress(t, osmosis), wantErr: true},
+		{name: "bad checksum", address: valid[:len(valid)-1] + "q", wantErr: true},
+		{name: "not bech32", address: "0x9858EfFD232B4033E47d90003D41EC34EcaEda94", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := w.validateAddress(tt.address); (err != nil) != tt.wantErr {
+				t.Fatalf("validateAddress(%s) = %v", tt.address, err)
+			}
+		})
+	}
+}
+
+// newTestREST 模拟gRPC-gateway：账户编号7、序号3，余额为balance，模拟消耗gasUsed
+func newTestREST(t *testing.T, balance string, gasUsed string) (*httptest.Server, *[][]byte) {
+	var broadcasts [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var result interface{}
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/cosmos/auth/v1beta1/accounts/"):
+			result = map[string]interface{}{"account": map[string]string{"account_number": "7", "sequence": "3"}}
+		case strings.HasPrefix(r.URL.Path, "/cosmos/bank/v1beta1/balances/"):
+			result = map[string]interface{}{"balance": Coin{Denom: r.URL.Query().Get("denom"), Amount: balance}}
+		case r.URL.Path == "/cosmos/tx/v1beta1/simulate":
+			result = map[string]interface{}{"gas_info": map[string]string{"gas_used": gasUsed}}
+		case r.URL.Path == "/cosmos/tx/v1beta1/txs":
+			var body struct {
+				TxBytes []byte `json:"tx_bytes"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			broadcasts = append(broadcasts, body.TxBytes)
+			result = map[string]interface{}{"tx_response": map[string]interface{}{"txhash": "HASH", "code": 0}}
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		json.NewEncoder(w).Encode(result)
+	}))
+	t.Cleanup(server.Close)
+	return server, &broadcasts
+}
+
+// SIGN_MODE_DIRECT：签名为sha256(SignDoc)上的64字节r||s，SignDoc带账户编号和chain-id
+func TestCreateSignAndSendTransaction(t *testing.T) {
+	server, broadcasts := newTestREST(t, "1000000", "80000")
+	w, err := NewCosmosWallet("cosmos", server.URL, testConfig, "test-encryption-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	walletID, err := w.ImportFromPrivateKey(hex.EncodeToString(crypto.FromECDSA(key)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, _ := w.GetAddress(walletID)
+	to := randomAddress(t, w)
+	ctx := context.Background()
+
+	if _, err := w.CreateTransaction(ctx, from, to, big.NewInt(999_000), nil); err == nil {
+		t.Fatal("expected insufficient funds once the fee is added")
+	}
+
+	tx, err := w.CreateTransaction(ctx, from, to, big.NewInt(1000), []byte("memo"))
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	// gas按1.4倍模拟结果，手续费按gas价格向上取整
+	if tx.Nonce != 3 || tx.Fee.GasLimit != 112_000 || tx.Fee.Amount.Int64() != 2800 {
+		t.Fatalf("unexpected sequence or fee: nonce %d, gas %d, fee %s", tx.Nonce, tx.Fee.GasLimit, tx.Fee.Amount)
+	}
+
+	signed, err := w.SignTransaction(ctx, walletID, tx)
+	if err != nil {
+		t.Fatalf("SignTransaction: %v", err)
+	}
+	fields, err := parseProto(signed.Payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bodyBytes, authInfo, signature []byte
+	for _, field := range fields {
+		switch field.num {
+		case 1:
+			bodyBytes = field.bytes
+		case 2:
+			authInfo = field.bytes
+		case 3:
+			signature = field.bytes
+		}
+	}
+	digest := sha256.Sum256(encodeSignDoc(bodyBytes, authInfo, testConfig.ChainID, 7))
+	if len(signature) != 64 || !crypto.VerifySignature(crypto.CompressPubkey(&key.PublicKey), digest[:], signature) {
+		t.Fatal("signature does not verify against the sign doc")
+	}
+	txHash := sha256.Sum256(signed.Payload)
+	if signed.Hash != strings.ToUpper(hex.EncodeToString(txHash[:])) {
+		t.Fatalf("unexpected hash %s", signed.Hash)
+	}
+
+	hash, err := w.SendTransaction(ctx, signed)
+	if err != nil {
+		t.Fatalf("SendTransaction: %v", err)
+	}
+	if hash != "HASH" || len(*broadcasts) != 1 || string((*broadcasts)[0]) != string(signed.Payload) {
+		t.Fatalf("unexpected broadcast: %s %d", hash, len(*broadcasts))
+	}
+}
+
+// 广播前模拟的gas超过签名时的gas limit则不广播
+func TestSendTransactionRejectsGasOverLimit(t *testing.T) {
+	server, broadcasts := newTestREST(t, "1000000", "200000")
+	w, err := NewCosmosWallet("cosmos", server.URL, testConfig, "test-encryption-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := crypto.GenerateKey()
+	walletID, err := w.ImportFromPrivateKey(hex.EncodeToString(crypto.FromECDSA(key)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, _ := w.GetAddress(walletID)
+	to := randomAddress(t, w)
+
+	tx := testUnsignedTx(t, from, to, big.NewInt(1000), encodeAny(typeURLMsgSend, encodeMsgSend(from, to, []Coin{{Denom: "uatom", Amount: "1000"}})))
+	signed, err := w.SignTransaction(context.Background(), walletID, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.SendTransaction(context.Background(), signed); err == nil {
+		t.Fatal("expected error when simulated gas exceeds the gas limit")
+	}
+	if len(*broadcasts) != 0 {
+		t.Fatal("expected the transaction not to be broadcast")
+	}
+}
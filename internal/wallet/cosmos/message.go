@@ -0,0 +1,109 @@
+package cosmos
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// adr036SignDoc 按ADR-036构造离线签名文档：chain_id为空、账户编号和序号为0的amino JSON，
+// 字段按字母序排列，与Keplr的signArbitrary一致
+func adr036SignDoc(signer string, message []byte) ([]byte, error) {
+	type msgValue struct {
+		Data   []byte `json:"data"`
+		Signer string `json:"signer"`
+	}
+	type msg struct {
+		Type  string   `json:"type"`
+		Value msgValue `json:"value"`
+	}
+	type fee struct {
+		Amount []Coin `json:"amount"`
+		Gas    string `json:"gas"`
+	}
+	doc := struct {
+		AccountNumber string `json:"account_number"`
+		ChainID       string `json:"chain_id"`
+		Fee           fee    `json:"fee"`
+		Memo          string `json:"memo"`
+		Msgs          []msg  `json:"msgs"`
+		Sequence      string `json:"sequence"`
+	}{
+		AccountNumber: "0",
+		Fee:           fee{Amount: []Coin{}, Gas: "0"},
+		Msgs:          []msg{{Type: "sign/MsgSignData", Value: msgValue{Data: message, Signer: signer}}},
+		Sequence:      "0",
+	}
+	return json.Marshal(doc)
+}
+
+// SignMessage 按ADR-036签名消息，返回64字节r||s签名
+func (w *CosmosWallet) SignMessage(ctx context.Context, walletID string, message []byte) ([]byte, error) {
+	privateKey, keystore, err := w.getPrivateKey(walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := adr036SignDoc(keystore.Address, message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build sign doc: %v", err)
+	}
+	digest := sha256.Sum256(doc)
+
+	signature, err := crypto.Sign(digest[:], privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign message: %v", err)
+	}
+	return signature[:64], nil
+}
+
+// VerifyMessage 校验ADR-036消息签名。签名不含恢复ID，依次尝试两个恢复ID并比对地址
+func (w *CosmosWallet) VerifyMessage(ctx context.Context, expectedSigner string, message []byte, signature []byte) (*wallet.SignatureVerification, error) {
+	if len(signature) != 64 && len(signature) != crypto.SignatureLength {
+		return nil, errors.New("invalid signature length")
+	}
+	if err := w.validateAddress(expectedSigner); err != nil {
+		return nil, err
+	}
+
+	doc, err := adr036SignDoc(expectedSigner, message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build sign doc: %v", err)
+	}
+	digest := sha256.Sum256(doc)
+
+	var recovered string
+	sig := make([]byte, crypto.SignatureLength)
+	copy(sig, signature[:64])
+	for v := byte(0); v < 2; v++ {
+		sig[crypto.RecoveryIDOffset] = v
+		pubKey, err := crypto.SigToPub(digest[:], sig)
+		if err != nil {
+			continue
+		}
+		address, err := w.addressFromPublicKey(pubKey)
+		if err != nil {
+			continue
+		}
+		recovered = address
+		if address == expectedSigner {
+			break
+		}
+	}
+	if recovered == "" {
+		return nil, errors.New("failed to recover public key")
+	}
+
+	return &wallet.SignatureVerification{
+		RecoveredAddress: recovered,
+		ExpectedSigner:   expectedSigner,
+		Valid:            recovered == expectedSigner,
+		Method:           wallet.VerifyMethodECRecover,
+	}, nil
+}
@@ -0,0 +1,258 @@
+package cosmos
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// 交易中使用的protobuf类型URL
+const (
+	typeURLMsgSend      = "/cosmos.bank.v1beta1.MsgSend"
+	typeURLSecp256k1Key = "/cosmos.crypto.secp256k1.PubKey"
+)
+
+// signModeDirect SIGN_MODE_DIRECT，签名内容为protobuf编码的SignDoc
+const signModeDirect = 1
+
+// Coin 代币金额，amount为整数字符串
+type Coin struct {
+	Denom  string `json:"denom"`
+	Amount string `json:"amount"`
+}
+
+// 以下编码函数按cosmos-sdk的proto定义手工编码，字段按编号顺序写入且省略默认值，
+// 与SDK的确定性编码一致
+
+// appendString 追加string字段，空字符串省略
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+// appendBytes 追加bytes或嵌套消息字段，空值省略
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+// appendUint64 追加uint64字段，0省略
+func appendUint64(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+// encodeCoin cosmos.base.v1beta1.Coin
+func encodeCoin(coin Coin) []byte {
+	var b []byte
+	b = appendString(b, 1, coin.Denom)
+	b = appendString(b, 2, coin.Amount)
+	return b
+}
+
+// encodeAny google.protobuf.Any
+func encodeAny(typeURL string, value []byte) []byte {
+	var b []byte
+	b = appendString(b, 1, typeURL)
+	b = appendBytes(b, 2, value)
+	return b
+}
+
+// encodeMsgSend cosmos.bank.v1beta1.MsgSend
+func encodeMsgSend(from string, to string, amount []Coin) []byte {
+	var b []byte
+	b = appendString(b, 1, from)
+	b = appendString(b, 2, to)
+	for _, coin := range amount {
+		b = appendBytes(b, 3, encodeCoin(coin))
+	}
+	return b
+}
+
+// encodeTxBody cosmos.tx.v1beta1.TxBody，messages为已编码的Any
+func encodeTxBody(messages [][]byte, memo string) []byte {
+	var b []byte
+	for _, msg := range messages {
+		b = appendBytes(b, 1, msg)
+	}
+	b = appendString(b, 2, memo)
+	return b
+}
+
+// encodeAuthInfo cosmos.tx.v1beta1.AuthInfo，只有一个SIGN_MODE_DIRECT签名者。
+// pubKey为空时省略公钥，仅用于链上尚无公钥的账户做模拟
+func encodeAuthInfo(pubKey []byte, sequence uint64, fee []Coin, gasLimit uint64) []byte {
+	// ModeInfo{single: {mode: SIGN_MODE_DIRECT}}
+	single := appendUint64(nil, 1, signModeDirect)
+	modeInfo := appendBytes(nil, 1, single)
+
+	var signerInfo []byte
+	if len(pubKey) > 0 {
+		signerInfo = appendBytes(signerInfo, 1, encodeAny(typeURLSecp256k1Key, appendBytes(nil, 1, pubKey)))
+	}
+	signerInfo = appendBytes(signerInfo, 2, modeInfo)
+	signerInfo = appendUint64(signerInfo, 3, sequence)
+
+	var feeBytes []byte
+	for _, coin := range fee {
+		feeBytes = appendBytes(feeBytes, 1, encodeCoin(coin))
+	}
+	feeBytes = appendUint64(feeBytes, 2, gasLimit)
+
+	var b []byte
+	b = appendBytes(b, 1, signerInfo)
+	b = appendBytes(b, 2, feeBytes)
+	return b
+}
+
+// encodeSignDoc cosmos.tx.v1beta1.SignDoc
+func encodeSignDoc(bodyBytes []byte, authInfoBytes []byte, chainID string, accountNumber uint64) []byte {
+	var b []byte
+	b = appendBytes(b, 1, bodyBytes)
+	b = appendBytes(b, 2, authInfoBytes)
+	b = appendString(b, 3, chainID)
+	b = appendUint64(b, 4, accountNumber)
+	return b
+}
+
+// encodeTxRaw cosmos.tx.v1beta1.TxRaw，签名为空时仍需写入占位项
+func encodeTxRaw(bodyBytes []byte, authInfoBytes []byte, signatures [][]byte) []byte {
+	var b []byte
+	b = appendBytes(b, 1, bodyBytes)
+	b = appendBytes(b, 2, authInfoBytes)
+	for _, sig := range signatures {
+		b = protowire.AppendTag(b, 3, protowire.BytesType)
+		b = protowire.AppendBytes(b, sig)
+	}
+	return b
+}
+
+// protoField protobuf消息的一个字段，varint类型的值在varint中，length-delimited类型的值在bytes中
+type protoField struct {
+	num    protowire.Number
+	varint uint64
+	bytes  []byte
+}
+
+// parseProto 按顺序解析protobuf消息的顶层字段，fixed32/fixed64字段只校验不保留
+func parseProto(b []byte) ([]protoField, error) {
+	var fields []protoField
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		field := protoField{num: num}
+		switch typ {
+		case protowire.VarintType:
+			field.varint, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			field.bytes, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// msgSend 解码后的cosmos.bank.v1beta1.MsgSend
+type msgSend struct {
+	from   string
+	to     string
+	amount []Coin
+}
+
+// decodeTxBody 解析TxBody中的消息，只接受MsgSend。memo和timeout_height之外的字段（如extension_options）返回错误
+func decodeTxBody(bodyBytes []byte) ([]*msgSend, error) {
+	fields, err := parseProto(bodyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	var msgs []*msgSend
+	for _, field := range fields {
+		switch field.num {
+		case 1:
+			msg, err := decodeMsgSend(field.bytes)
+			if err != nil {
+				return nil, err
+			}
+			msgs = append(msgs, msg)
+		case 2, 3:
+		default:
+			return nil, fmt.Errorf("unsupported tx body field %d", field.num)
+		}
+	}
+	if len(msgs) == 0 {
+		return nil, errors.New("transaction has no messages")
+	}
+	return msgs, nil
+}
+
+// decodeMsgSend 解析Any包装的MsgSend
+func decodeMsgSend(anyBytes []byte) (*msgSend, error) {
+	fields, err := parseProto(anyBytes)
+	if err != nil {
+		return nil, err
+	}
+	var typeURL string
+	var value []byte
+	for _, field := range fields {
+		switch field.num {
+		case 1:
+			typeURL = string(field.bytes)
+		case 2:
+			value = field.bytes
+		}
+	}
+	if typeURL != typeURLMsgSend {
+		return nil, fmt.Errorf("unsupported message %s", typeURL)
+	}
+
+	fields, err = parseProto(value)
+	if err != nil {
+		return nil, err
+	}
+	msg := &msgSend{}
+	for _, field := range fields {
+		switch field.num {
+		case 1:
+			msg.from = string(field.bytes)
+		case 2:
+			msg.to = string(field.bytes)
+		case 3:
+			coinFields, err := parseProto(field.bytes)
+			if err != nil {
+				return nil, err
+			}
+			var coin Coin
+			for _, f := range coinFields {
+				switch f.num {
+				case 1:
+					coin.Denom = string(f.bytes)
+				case 2:
+					coin.Amount = string(f.bytes)
+				}
+			}
+			msg.amount = append(msg.amount, coin)
+		}
+	}
+	return msg, nil
+}
@@ -0,0 +1,208 @@
+package cosmos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// gRPC状态码NotFound
+const grpcCodeNotFound = 5
+
+// restError gRPC-gateway返回的错误
+type restError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *restError) Error() string {
+	return fmt.Sprintf("cosmos rest error %d: %s", e.Code, e.Message)
+}
+
+// isNotFound 判断是否为资源不存在错误，部分版本的节点查询不存在的交易时返回InvalidArgument
+func isNotFound(err error) bool {
+	var restErr *restError
+	if !errors.As(err, &restErr) {
+		return false
+	}
+	return restErr.Code == grpcCodeNotFound || strings.Contains(restErr.Message, "not found")
+}
+
+// restClient Cosmos SDK REST（gRPC-gateway）客户端
+type restClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// newRESTClient 创建REST客户端
+func newRESTClient(baseURL string) *restClient {
+	return &restClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// do 发送请求并解码响应，body为nil时使用GET
+func (c *restClient) do(ctx context.Context, path string, body interface{}, result interface{}) error {
+	method := http.MethodGet
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %v", err)
+		}
+		method = http.MethodPost
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", wallet.ErrChainUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusBadGateway ||
+		resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusGatewayTimeout {
+		return fmt.Errorf("%w: cosmos rest returned status %d", wallet.ErrChainUnavailable, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var restErr restError
+		if err := json.Unmarshal(data, &restErr); err == nil && restErr.Message != "" {
+			return &restErr
+		}
+		return fmt.Errorf("cosmos rest returned status %d for %s", resp.StatusCode, path)
+	}
+
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("failed to decode %s response: %v", path, err)
+	}
+	return nil
+}
+
+// getBalance 查询地址某个denom的余额
+func (c *restClient) getBalance(ctx context.Context, address string, denom string) (string, error) {
+	var result struct {
+		Balance Coin `json:"balance"`
+	}
+	path := fmt.Sprintf("/cosmos/bank/v1beta1/balances/%s/by_denom?denom=%s", address, url.QueryEscape(denom))
+	if err := c.do(ctx, path, nil, &result); err != nil {
+		return "", err
+	}
+	if result.Balance.Amount == "" {
+		return "0", nil
+	}
+	return result.Balance.Amount, nil
+}
+
+// baseAccount 账户编号和序号，数值在JSON中为字符串
+type baseAccount struct {
+	AccountNumber string `json:"account_number"`
+	Sequence      string `json:"sequence"`
+}
+
+// getAccount 查询账户编号和序号，兼容BaseAccount和各类vesting账户
+func (c *restClient) getAccount(ctx context.Context, address string) (uint64, uint64, error) {
+	var result struct {
+		Account struct {
+			baseAccount
+			BaseAccount        *baseAccount `json:"base_account"`
+			BaseVestingAccount *struct {
+				BaseAccount *baseAccount `json:"base_account"`
+			} `json:"base_vesting_account"`
+		} `json:"account"`
+	}
+	if err := c.do(ctx, "/cosmos/auth/v1beta1/accounts/"+address, nil, &result); err != nil {
+		return 0, 0, err
+	}
+
+	account := &result.Account.baseAccount
+	switch {
+	case result.Account.BaseAccount != nil:
+		account = result.Account.BaseAccount
+	case result.Account.BaseVestingAccount != nil && result.Account.BaseVestingAccount.BaseAccount != nil:
+		account = result.Account.BaseVestingAccount.BaseAccount
+	}
+
+	accountNumber, err := strconv.ParseUint(account.AccountNumber, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid account number: %q", account.AccountNumber)
+	}
+	sequence, err := strconv.ParseUint(account.Sequence, 10, 64)
+	if err != nil && account.Sequence != "" {
+		return 0, 0, fmt.Errorf("invalid sequence: %q", account.Sequence)
+	}
+	return accountNumber, sequence, nil
+}
+
+// simulate 模拟执行交易，返回消耗的gas
+func (c *restClient) simulate(ctx context.Context, txBytes []byte) (uint64, error) {
+	var result struct {
+		GasInfo struct {
+			GasUsed string `json:"gas_used"`
+		} `json:"gas_info"`
+	}
+	if err := c.do(ctx, "/cosmos/tx/v1beta1/simulate", map[string]interface{}{"tx_bytes": txBytes}, &result); err != nil {
+		return 0, err
+	}
+
+	gasUsed, err := strconv.ParseUint(result.GasInfo.GasUsed, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid gas_used: %q", result.GasInfo.GasUsed)
+	}
+	return gasUsed, nil
+}
+
+// txResponse 交易执行结果，code为0表示成功
+type txResponse struct {
+	TxHash string `json:"txhash"`
+	Height string `json:"height"`
+	Code   uint32 `json:"code"`
+	RawLog string `json:"raw_log"`
+}
+
+// broadcast 以SYNC模式广播交易，CheckTx通过后返回
+func (c *restClient) broadcast(ctx context.Context, txBytes []byte) (*txResponse, error) {
+	var result struct {
+		TxResponse txResponse `json:"tx_response"`
+	}
+	body := map[string]interface{}{"tx_bytes": txBytes, "mode": "BROADCAST_MODE_SYNC"}
+	if err := c.do(ctx, "/cosmos/tx/v1beta1/txs", body, &result); err != nil {
+		return nil, err
+	}
+	return &result.TxResponse, nil
+}
+
+// getTx 查询已打包的交易
+func (c *restClient) getTx(ctx context.Context, hash string) (*txResponse, error) {
+	var result struct {
+		TxResponse txResponse `json:"tx_response"`
+	}
+	if err := c.do(ctx, "/cosmos/tx/v1beta1/txs/"+hash, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result.TxResponse, nil
+}
@@ -0,0 +1,305 @@
+package cosmos
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// 模拟得到的gas乘以该系数作为gas limit，与cosmjs/keplr的默认值一致
+const gasAdjustment = 1.4
+
+// 广播记录保留时间，超时后仍查不到的交易视为不存在
+const broadcastRetention = 30 * time.Minute
+
+// signPayload 待签名交易的Payload，BodyBytes在创建时固定，签名时补充AuthInfo生成SignDoc。
+// sequence、gas limit和手续费取自UnsignedTx的Nonce和Fee
+type signPayload struct {
+	BodyBytes     []byte `json:"bodyBytes"`
+	ChainID       string `json:"chainId"`
+	AccountNumber uint64 `json:"accountNumber"`
+}
+
+// CreateTransaction 创建原生代币转账交易，data非空时作为memo
+func (w *CosmosWallet) CreateTransaction(ctx context.Context, from string, to string, amount *big.Int, data []byte) (*wallet.UnsignedTx, error) {
+	return w.createSendTransaction(ctx, from, to, w.config.Denom, amount, string(data))
+}
+
+// CreateTokenTransaction 创建bank模块中其他denom的转账交易，tokenAddress为denom
+func (w *CosmosWallet) CreateTokenTransaction(ctx context.Context, from string, to string, tokenAddress string, amount *big.Int) (*wallet.UnsignedTx, error) {
+	if tokenAddress == "" {
+		return nil, errors.New("denom is required")
+	}
+	return w.createSendTransaction(ctx, from, to, tokenAddress, amount, "")
+}
+
+// createSendTransaction 构建MsgSend交易，先模拟执行估算gas再计算手续费
+func (w *CosmosWallet) createSendTransaction(ctx context.Context, from string, to string, denom string, amount *big.Int, memo string) (*wallet.UnsignedTx, error) {
+	if err := w.validateAddress(from); err != nil {
+		return nil, err
+	}
+	if err := w.validateAddress(to); err != nil {
+		return nil, err
+	}
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, errors.New("invalid amount")
+	}
+
+	accountNumber, sequence, err := w.rest.getAccount(ctx, from)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, fmt.Errorf("%w: account %s does not exist on chain", wallet.ErrInsufficientFunds, from)
+		}
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+
+	msg := encodeMsgSend(from, to, []Coin{{Denom: denom, Amount: amount.String()}})
+	bodyBytes := encodeTxBody([][]byte{encodeAny(typeURLMsgSend, msg)}, memo)
+
+	// 模拟时不带公钥和签名，节点在模拟模式下跳过验签
+	simAuthInfo := encodeAuthInfo(nil, sequence, nil, 0)
+	gasUsed, err := w.rest.simulate(ctx, encodeTxRaw(bodyBytes, simAuthInfo, [][]byte{{}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to simulate transaction: %w", err)
+	}
+
+	gasLimit := uint64(math.Ceil(float64(gasUsed) * gasAdjustment))
+	fee := w.calculateFee(gasLimit)
+
+	if err := w.checkBalance(ctx, from, denom, amount, fee); err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(&signPayload{
+		BodyBytes:     bodyBytes,
+		ChainID:       w.config.ChainID,
+		AccountNumber: accountNumber,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize transaction: %v", err)
+	}
+
+	feeAmount := big.NewInt(0)
+	if len(fee) > 0 {
+		feeAmount.SetString(fee[0].Amount, 10)
+	}
+
+	tx := &wallet.UnsignedTx{
+		ChainType: w.chainType,
+		From:      from,
+		To:        to,
+		Value:     amount,
+		Nonce:     sequence,
+		Fee:       &wallet.TxFee{GasLimit: gasLimit, Amount: feeAmount},
+		Payload:   payload,
+	}
+	if denom != w.config.Denom {
+		tx.Token = denom
+	}
+	return tx, nil
+}
+
+// calculateFee 按gas limit和gas价格计算手续费，向上取整
+func (w *CosmosWallet) calculateFee(gasLimit uint64) []Coin {
+	if w.config.GasPrice <= 0 {
+		return nil
+	}
+	amount := uint64(math.Ceil(float64(gasLimit) * w.config.GasPrice))
+	return []Coin{{Denom: w.config.Denom, Amount: fmt.Sprintf("%d", amount)}}
+}
+
+// checkBalance 检查转账金额及同denom手续费是否足够
+func (w *CosmosWallet) checkBalance(ctx context.Context, address string, denom string, amount *big.Int, fee []Coin) error {
+	required := map[string]*big.Int{denom: new(big.Int).Set(amount)}
+	for _, coin := range fee {
+		feeAmount, _ := new(big.Int).SetString(coin.Amount, 10)
+		if existing, ok := required[coin.Denom]; ok {
+			existing.Add(existing, feeAmount)
+		} else {
+			required[coin.Denom] = feeAmount
+		}
+	}
+
+	for d, need := range required {
+		balance, err := w.GetTokenBalance(ctx, address, d)
+		if err != nil {
+			return err
+		}
+		if balance.Cmp(need) < 0 {
+			return fmt.Errorf("%w: have %s%s, need %s%s", wallet.ErrInsufficientFunds, balance, d, need, d)
+		}
+	}
+	return nil
+}
+
+// SignTransaction 以SIGN_MODE_DIRECT签名交易，签名内容为sha256(SignDoc)。Payload为TxRaw
+func (w *CosmosWallet) SignTransaction(ctx context.Context, walletID string, tx *wallet.UnsignedTx) (*wallet.SignedTx, error) {
+	if err := tx.CheckChain(w.chainType); err != nil {
+		return nil, err
+	}
+
+	privateKey, keystore, err := w.getPrivateKey(walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload signPayload
+	if err := json.Unmarshal(tx.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("failed to deserialize transaction: %v", err)
+	}
+	if payload.ChainID != w.config.ChainID {
+		return nil, fmt.Errorf("transaction chain id %s does not match %s", payload.ChainID, w.config.ChainID)
+	}
+	msgs, err := decodeTxBody(payload.BodyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", wallet.ErrInvalidTransaction, err)
+	}
+	for _, msg := range msgs {
+		if msg.from != keystore.Address {
+			return nil, fmt.Errorf("transaction sender %s does not match wallet address %s", msg.from, keystore.Address)
+		}
+	}
+	if tx.Fee == nil || tx.Fee.GasLimit == 0 {
+		return nil, errors.New("transaction gas limit is not set")
+	}
+
+	var fee []Coin
+	if tx.Fee.Amount != nil && tx.Fee.Amount.Sign() > 0 {
+		fee = []Coin{{Denom: w.config.Denom, Amount: tx.Fee.Amount.String()}}
+	}
+
+	authInfo := encodeAuthInfo(crypto.CompressPubkey(&privateKey.PublicKey), tx.Nonce, fee, tx.Fee.GasLimit)
+	signDoc := encodeSignDoc(payload.BodyBytes, authInfo, payload.ChainID, payload.AccountNumber)
+	digest := sha256.Sum256(signDoc)
+
+	signature, err := crypto.Sign(digest[:], privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %v", err)
+	}
+
+	// Cosmos使用64字节的r||s签名，不含恢复ID
+	txBytes := encodeTxRaw(payload.BodyBytes, authInfo, [][]byte{signature[:64]})
+	txHash := sha256.Sum256(txBytes)
+
+	return tx.Signed(txBytes, strings.ToUpper(hex.EncodeToString(txHash[:]))), nil
+}
+
+// DecodePayload 解析BodyBytes中MsgSend的转账，每个coin一笔，原生代币的Token为空
+func (w *CosmosWallet) DecodePayload(payload []byte) ([]*wallet.PayloadTransfer, error) {
+	var p signPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, fmt.Errorf("%w: failed to deserialize transaction: %v", wallet.ErrInvalidTransaction, err)
+	}
+	return w.decodeBody(p.BodyBytes)
+}
+
+// decodeBody 按MsgSend的每个coin生成一笔转账
+func (w *CosmosWallet) decodeBody(bodyBytes []byte) ([]*wallet.PayloadTransfer, error) {
+	msgs, err := decodeTxBody(bodyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", wallet.ErrInvalidTransaction, err)
+	}
+
+	var transfers []*wallet.PayloadTransfer
+	for _, msg := range msgs {
+		for _, coin := range msg.amount {
+			amount, ok := new(big.Int).SetString(coin.Amount, 10)
+			if !ok || amount.Sign() < 0 {
+				return nil, fmt.Errorf("%w: invalid amount %q", wallet.ErrInvalidTransaction, coin.Amount)
+			}
+			transfer := &wallet.PayloadTransfer{From: msg.from, To: msg.to, Value: amount}
+			if coin.Denom != w.config.Denom {
+				transfer.Token = coin.Denom
+			}
+			transfers = append(transfers, transfer)
+		}
+	}
+	return transfers, nil
+}
+
+// DecodeSignedPayload 解析TxRaw的BodyBytes中MsgSend的转账
+func (w *CosmosWallet) DecodeSignedPayload(payload []byte) ([]*wallet.PayloadTransfer, error) {
+	fields, err := parseProto(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid TxRaw: %v", wallet.ErrInvalidTransaction, err)
+	}
+	var bodyBytes []byte
+	for _, field := range fields {
+		if field.num == 1 {
+			bodyBytes = field.bytes
+		}
+	}
+	return w.decodeBody(bodyBytes)
+}
+
+// SendTransaction 广播已签名交易，广播前再次模拟以提前发现序号或余额变化
+func (w *CosmosWallet) SendTransaction(ctx context.Context, signedTx *wallet.SignedTx) (string, error) {
+	if err := signedTx.CheckChain(w.chainType); err != nil {
+		return "", err
+	}
+
+	gasUsed, err := w.rest.simulate(ctx, signedTx.Payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to simulate transaction: %w", err)
+	}
+	if signedTx.Fee != nil && signedTx.Fee.GasLimit > 0 && gasUsed > signedTx.Fee.GasLimit {
+		return "", fmt.Errorf("simulated gas %d exceeds gas limit %d", gasUsed, signedTx.Fee.GasLimit)
+	}
+
+	result, err := w.rest.broadcast(ctx, signedTx.Payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to send transaction: %w", err)
+	}
+	if result.Code != 0 {
+		return "", fmt.Errorf("failed to send transaction: code %d: %s", result.Code, result.RawLog)
+	}
+
+	w.mu.Lock()
+	now := time.Now()
+	for hash, sentAt := range w.broadcasts {
+		if now.Sub(sentAt) > broadcastRetention {
+			delete(w.broadcasts, hash)
+		}
+	}
+	w.broadcasts[result.TxHash] = now
+	w.mu.Unlock()
+
+	return result.TxHash, nil
+}
+
+// GetTransactionStatus 获取交易状态，已打包的交易按执行结果code区分成功与失败
+func (w *CosmosWallet) GetTransactionStatus(ctx context.Context, txHash string) (string, error) {
+	txHash = strings.ToUpper(strings.TrimPrefix(txHash, "0x"))
+
+	result, err := w.rest.getTx(ctx, txHash)
+	if err != nil {
+		if !isNotFound(err) {
+			return "", fmt.Errorf("failed to get transaction: %w", err)
+		}
+
+		// SYNC模式广播后交易可能仍在内存池中
+		w.mu.RLock()
+		sentAt, ok := w.broadcasts[txHash]
+		w.mu.RUnlock()
+		if ok && time.Since(sentAt) <= broadcastRetention {
+			return string(wallet.TxPending), nil
+		}
+		return "", errors.New("transaction not found")
+	}
+
+	if result.Code != 0 {
+		return string(wallet.TxFailed), nil
+	}
+	return string(wallet.TxConfirmed), nil
+}
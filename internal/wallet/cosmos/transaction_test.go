@@ -0,0 +1,134 @@
+package cosmos
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+var testConfig = Config{
+	ChainID:      "cosmoshub-4",
+	Bech32Prefix: "cosmos",
+	CoinType:     118,
+	Denom:        "uatom",
+	GasPrice:     0.025,
+}
+
+func newTestWallet(t *testing.T) *CosmosWallet {
+	w, err := NewCosmosWallet("cosmos", "http://127.0.0.1:0", testConfig, "test-encryption-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func randomAddress(t *testing.T, w *CosmosWallet) string {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, err := w.addressFromPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return address
+}
+
+// testUnsignedTx 构造Payload中为给定消息的待签名交易，通用字段按调用方填写
+func testUnsignedTx(t *testing.T, from string, to string, value *big.Int, messages ...[]byte) *wallet.UnsignedTx {
+	payload, err := json.Marshal(&signPayload{
+		BodyBytes:     encodeTxBody(messages, ""),
+		ChainID:       testConfig.ChainID,
+		AccountNumber: 7,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &wallet.UnsignedTx{
+		ChainType: "cosmos",
+		From:      from,
+		To:        to,
+		Value:     value,
+		Fee:       &wallet.TxFee{GasLimit: 100_000, Amount: big.NewInt(2500)},
+		Payload:   payload,
+	}
+}
+
+func TestDecodePayload(t *testing.T) {
+	w := newTestWallet(t)
+	from := randomAddress(t, w)
+	to := randomAddress(t, w)
+
+	msg := encodeMsgSend(from, to, []Coin{{Denom: "uatom", Amount: "1000"}, {Denom: "ibc/27394FB0", Amount: "5"}})
+	// 调用方填写的收款方和金额与Payload不一致，解析结果以Payload为准
+	tx := testUnsignedTx(t, from, randomAddress(t, w), big.NewInt(1), encodeAny(typeURLMsgSend, msg))
+
+	transfers, err := w.DecodePayload(tx.Payload)
+	if err != nil {
+		t.Fatalf("DecodePayload: %v", err)
+	}
+	if len(transfers) != 2 {
+		t.Fatalf("expected two transfers, got %d", len(transfers))
+	}
+	if got := transfers[0]; got.From != from || got.To != to || got.Token != "" || got.Value.Int64() != 1000 {
+		t.Fatalf("unexpected native transfer: %+v", got)
+	}
+	if got := transfers[1]; got.To != to || got.Token != "ibc/27394FB0" || got.Value.Int64() != 5 {
+		t.Fatalf("unexpected token transfer: %+v", got)
+	}
+}
+
+func TestDecodePayloadRejectsOtherMessages(t *testing.T) {
+	w := newTestWallet(t)
+	from := randomAddress(t, w)
+
+	// MsgDelegate等非转账消息无法按收款方和金额校验
+	tx := testUnsignedTx(t, from, "", big.NewInt(0), encodeAny("/cosmos.staking.v1beta1.MsgDelegate", appendString(nil, 1, from)))
+	if _, err := w.DecodePayload(tx.Payload); !errors.Is(err, wallet.ErrInvalidTransaction) {
+		t.Fatalf("expected ErrInvalidTransaction, got %v", err)
+	}
+}
+
+// 发送方以BodyBytes中的MsgSend为准，调用方填写的From与钱包一致也不能签名他人的消息
+func TestSignTransactionChecksMessageSender(t *testing.T) {
+	w := newTestWallet(t)
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	walletID, err := w.ImportFromPrivateKey(hex.EncodeToString(crypto.FromECDSA(key)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, err := w.GetAddress(walletID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to := randomAddress(t, w)
+	coins := []Coin{{Denom: "uatom", Amount: "1000"}}
+
+	own := testUnsignedTx(t, address, to, big.NewInt(1000), encodeAny(typeURLMsgSend, encodeMsgSend(address, to, coins)))
+	signed, err := w.SignTransaction(context.Background(), walletID, own)
+	if err != nil {
+		t.Fatalf("SignTransaction: %v", err)
+	}
+	transfers, err := w.DecodeSignedPayload(signed.Payload)
+	if err != nil {
+		t.Fatalf("DecodeSignedPayload: %v", err)
+	}
+	if len(transfers) != 1 || transfers[0].From != address || transfers[0].To != to || transfers[0].Value.Int64() != 1000 {
+		t.Fatalf("unexpected transfers: %+v", transfers)
+	}
+
+	other := testUnsignedTx(t, address, to, big.NewInt(1000), encodeAny(typeURLMsgSend, encodeMsgSend(randomAddress(t, w), to, coins)))
+	if _, err := w.SignTransaction(context.Background(), walletID, other); err == nil {
+		t.Fatal("expected error signing a message sent by another address")
+	}
+}
@@ -8,4 +8,46 @@ var (
 
 	// ErrWalletNotFound 钱包未找到
 	ErrWalletNotFound = errors.New("wallet not found")
+
+	// ErrChainIDMismatch 签名数据中的链ID与钱包所在链不一致
+	ErrChainIDMismatch = errors.New("chain id mismatch")
+
+	// ErrPermitNotSupported 代币或链不支持链下签名授权
+	ErrPermitNotSupported = errors.New("permit not supported")
+
+	// ErrOperationNotSupported 该链不支持此操作
+	ErrOperationNotSupported = errors.New("operation not supported on this chain")
+
+	// ErrInsufficientFunds 可用余额不足
+	ErrInsufficientFunds = errors.New("insufficient funds")
+
+	// ErrInvalidTransaction 原始交易无法解码或签名无效
+	ErrInvalidTransaction = errors.New("invalid transaction")
+
+	// ErrChainUnavailable 链的RPC节点暂时不可用
+	ErrChainUnavailable = errors.New("chain unavailable")
+
+	// ErrSignerUnavailable 远程签名服务不可达
+	ErrSignerUnavailable = errors.New("remote signer unavailable")
+
+	// ErrBundlerUnavailable ERC-4337 bundler不可达
+	ErrBundlerUnavailable = errors.New("bundler unavailable")
+
+	// ErrBudgetExceeded 用户的gas代付额度不足
+	ErrBudgetExceeded = errors.New("sponsorship budget exceeded")
+
+	// ErrPermissionDenied 会话密钥无效或交易超出授权范围
+	ErrPermissionDenied = errors.New("permission denied")
+
+	// ErrPolicyViolation 交易不符合钱包的支出策略
+	ErrPolicyViolation = errors.New("spending policy violation")
+
+	// ErrApprovalRequired 钱包要求审批，交易尚未获得足够的批准
+	ErrApprovalRequired = errors.New("approval required")
+
+	// ErrAddressBlocked 交易涉及的地址命中制裁或拒绝名单
+	ErrAddressBlocked = errors.New("address blocked by screening")
+
+	// ErrTransactionNotFound 链上查不到该交易
+	ErrTransactionNotFound = errors.New("transaction not found")
 )
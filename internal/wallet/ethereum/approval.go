@@ -0,0 +1,181 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// approve(address,uint256) 的ABI
+const erc20ApproveABI = `[{"inputs":[{"name":"spender","type":"address"},{"name":"amount","type":"uint256"}],"name":"approve","outputs":[{"name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"}]`
+
+// Approval(address indexed owner, address indexed spender, uint256 value)
+var approvalEventTopic = crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+
+// LatestBlock 获取最新区块高度
+func (w *BaseETHWallet) LatestBlock(ctx context.Context) (uint64, error) {
+	client, err := w.getClient()
+	if err != nil {
+		return 0, err
+	}
+
+	blockNumber, err := client.BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get block number: %v", err)
+	}
+	return blockNumber, nil
+}
+
+// GetApprovalEvents 获取owner在区间内发出的Approval事件
+func (w *BaseETHWallet) GetApprovalEvents(ctx context.Context, owner string, fromBlock uint64, toBlock uint64) ([]wallet.ApprovalEvent, error) {
+	if !common.IsHexAddress(owner) {
+		return nil, errors.New("invalid address format")
+	}
+
+	ownerTopic := common.BytesToHash(common.HexToAddress(owner).Bytes())
+	query := eth.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+		Topics:    [][]common.Hash{{approvalEventTopic}, {ownerTopic}},
+	}
+
+	client, err := w.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	logs, err := client.FilterLogs(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter logs: %v", err)
+	}
+
+	events := make([]wallet.ApprovalEvent, 0, len(logs))
+	for _, log := range logs {
+		// ERC721的Approval事件有3个indexed参数，data为空，这里只处理ERC20
+		if len(log.Topics) != 3 || len(log.Data) != 32 {
+			continue
+		}
+		events = append(events, wallet.ApprovalEvent{
+			Token:       log.Address.Hex(),
+			Owner:       common.BytesToAddress(log.Topics[1].Bytes()).Hex(),
+			Spender:     common.BytesToAddress(log.Topics[2].Bytes()).Hex(),
+			Value:       new(big.Int).SetBytes(log.Data),
+			BlockNumber: log.BlockNumber,
+			LogIndex:    log.Index,
+			TxHash:      log.TxHash.Hex(),
+		})
+	}
+
+	return events, nil
+}
+
+// GetAllowance 查询当前授权额度
+func (w *BaseETHWallet) GetAllowance(ctx context.Context, tokenAddress string, owner string, spender string) (*big.Int, error) {
+	if !common.IsHexAddress(tokenAddress) || !common.IsHexAddress(owner) || !common.IsHexAddress(spender) {
+		return nil, errors.New("invalid address format")
+	}
+
+	result, err := w.callContract(ctx, common.HexToAddress(tokenAddress), erc20PermitABI, "allowance",
+		common.HexToAddress(owner), common.HexToAddress(spender))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get allowance: %v", err)
+	}
+
+	allowance, ok := result[0].(*big.Int)
+	if !ok {
+		return nil, errors.New("failed to convert result to big.Int")
+	}
+
+	return allowance, nil
+}
+
+// IsContract 判断地址是否为合约
+func (w *BaseETHWallet) IsContract(ctx context.Context, address string) (bool, error) {
+	if !common.IsHexAddress(address) {
+		return false, errors.New("invalid address format")
+	}
+
+	client, err := w.getClient()
+	if err != nil {
+		return false, err
+	}
+
+	code, err := client.CodeAt(ctx, common.HexToAddress(address), nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to get code: %v", err)
+	}
+
+	return len(code) > 0, nil
+}
+
+// BuildRevokeTransactions 构建approve(spender, 0)撤销交易，同一批交易使用连续的nonce
+func (w *BaseETHWallet) BuildRevokeTransactions(ctx context.Context, owner string, approvals []wallet.TokenSpender) ([][]byte, error) {
+	if !common.IsHexAddress(owner) {
+		return nil, errors.New("invalid address format")
+	}
+
+	approveABI, err := abi.JSON(strings.NewReader(erc20ApproveABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %v", err)
+	}
+
+	ownerAddress := common.HexToAddress(owner)
+
+	client, err := w.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := client.PendingNonceAt(ctx, ownerAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nonce: %v", err)
+	}
+
+	fees, err := w.suggestFees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make([][]byte, 0, len(approvals))
+	for _, approval := range approvals {
+		if !common.IsHexAddress(approval.Token) || !common.IsHexAddress(approval.Spender) {
+			return nil, errors.New("invalid address format")
+		}
+		token := common.HexToAddress(approval.Token)
+
+		data, err := approveABI.Pack("approve", common.HexToAddress(approval.Spender), big.NewInt(0))
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack approve call: %v", err)
+		}
+
+		gasLimit, err := client.EstimateGas(ctx, eth.CallMsg{
+			From: ownerAddress,
+			To:   &token,
+			Data: data,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate gas: %v", err)
+		}
+
+		tx := w.newTransaction(nonce, token, big.NewInt(0), gasLimit, fees, data)
+		txJSON, err := json.Marshal(tx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize transaction: %v", err)
+		}
+
+		txs = append(txs, txJSON)
+		nonce++
+	}
+
+	return txs, nil
+}
@@ -1,3 +0,0 @@
-package ethereum
-
-// 此文件已移至 common.go
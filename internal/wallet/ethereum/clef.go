@@ -0,0 +1,154 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// ClefSigner 通过Clef兼容的JSON-RPC接口（account_signTransaction/account_signData）远程签名，
+// 私钥不进入本进程。签名结果会在本地重新校验签名者和交易内容
+type ClefSigner struct {
+	client  *rpc.Client
+	address common.Address
+}
+
+// clefSignTxResult account_signTransaction的返回值
+type clefSignTxResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+// NewClefSigner 创建远程签名器，client可通过rpc.DialContext连接HTTP地址或IPC路径
+func NewClefSigner(client *rpc.Client, address common.Address) *ClefSigner {
+	return &ClefSigner{client: client, address: address}
+}
+
+// ListClefAccounts 调用account_list获取签名服务管理的账户
+func ListClefAccounts(ctx context.Context, client *rpc.Client) ([]common.Address, error) {
+	var addresses []common.Address
+	if err := client.CallContext(ctx, &addresses, "account_list"); err != nil {
+		return nil, clefError(err)
+	}
+	return addresses, nil
+}
+
+func (s *ClefSigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx 调用account_signTransaction签名交易。Clef的审批界面或规则可能修改交易，
+// 因此要求返回交易的签名哈希与请求一致
+func (s *ClefSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	data := hexutil.Bytes(tx.Data())
+	args := apitypes.SendTxArgs{
+		From:    common.NewMixedcaseAddress(s.address),
+		Gas:     hexutil.Uint64(tx.Gas()),
+		Value:   hexutil.Big(*tx.Value()),
+		Nonce:   hexutil.Uint64(tx.Nonce()),
+		Data:    &data,
+		ChainID: (*hexutil.Big)(chainID),
+	}
+	if tx.To() != nil {
+		to := common.NewMixedcaseAddress(*tx.To())
+		args.To = &to
+	}
+	switch tx.Type() {
+	case types.LegacyTxType:
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	case types.DynamicFeeTxType:
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+		accessList := tx.AccessList()
+		args.AccessList = &accessList
+	default:
+		return nil, fmt.Errorf("unsupported transaction type %d for remote signing", tx.Type())
+	}
+
+	var result clefSignTxResult
+	if err := s.client.CallContext(ctx, &result, "account_signTransaction", args); err != nil {
+		return nil, clefError(err)
+	}
+
+	var signed types.Transaction
+	if err := signed.UnmarshalBinary(result.Raw); err != nil {
+		return nil, fmt.Errorf("remote signer returned invalid transaction: %v", err)
+	}
+
+	signer := types.LatestSignerForChainID(chainID)
+	if signer.Hash(&signed) != signer.Hash(tx) {
+		return nil, errors.New("remote signer returned a modified transaction")
+	}
+	if err := s.checkSender(signer, &signed); err != nil {
+		return nil, err
+	}
+	return &signed, nil
+}
+
+// SignText 以text/plain调用account_signData，对应EIP-191 personal_sign
+func (s *ClefSigner) SignText(ctx context.Context, message []byte) ([]byte, error) {
+	return s.signData(ctx, accounts.MimetypeTextPlain, message, accounts.TextHash(message))
+}
+
+// SignTypedData 以data/typed调用account_signData，数据为十六进制编码的EIP-712 JSON
+func (s *ClefSigner) SignTypedData(ctx context.Context, typedData apitypes.TypedData) ([]byte, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash typed data: %v", err)
+	}
+
+	typedDataJSON, err := json.Marshal(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize typed data: %v", err)
+	}
+	return s.signData(ctx, accounts.MimetypeTypedData, typedDataJSON, hash)
+}
+
+// signData 调用account_signData，并用本地计算的哈希恢复签名者校验结果
+func (s *ClefSigner) signData(ctx context.Context, contentType string, data []byte, hash []byte) ([]byte, error) {
+	var signature hexutil.Bytes
+	err := s.client.CallContext(ctx, &signature, "account_signData", contentType, common.NewMixedcaseAddress(s.address), hexutil.Encode(data))
+	if err != nil {
+		return nil, clefError(err)
+	}
+
+	recovered, err := recoverSigner(hash, signature)
+	if err != nil {
+		return nil, fmt.Errorf("remote signer returned invalid signature: %v", err)
+	}
+	if recovered != s.address {
+		return nil, fmt.Errorf("remote signer signed with %s, expected %s", recovered.Hex(), s.address.Hex())
+	}
+	return signature, nil
+}
+
+func (s *ClefSigner) checkSender(signer types.Signer, tx *types.Transaction) error {
+	sender, err := types.Sender(signer, tx)
+	if err != nil {
+		return fmt.Errorf("remote signer returned invalid signature: %v", err)
+	}
+	if sender != s.address {
+		return fmt.Errorf("remote signer signed with %s, expected %s", sender.Hex(), s.address.Hex())
+	}
+	return nil
+}
+
+// clefError 区分签名服务拒绝请求（JSON-RPC错误）和签名服务不可达
+func clefError(err error) error {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return fmt.Errorf("remote signer rejected request: %v", err)
+	}
+	return fmt.Errorf("%w: %v", wallet.ErrSignerUnavailable, err)
+}
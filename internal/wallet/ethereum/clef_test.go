@@ -0,0 +1,274 @@
+package ethereum
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"multi-chain-wallet/internal/wallet"
+	"multi-chain-wallet/internal/wallet/ethereum/clefmock"
+)
+
+const testTypedData = `{
+	"types": {
+		"EIP712Domain": [{"name": "name", "type": "string"}, {"name": "chainId", "type": "uint256"}],
+		"Mail": [{"name": "to", "type": "address"}, {"name": "contents", "type": "string"}]
+	},
+	"primaryType": "Mail",
+	"domain": {"name": "Test", "chainId": "1337"},
+	"message": {"to": "0x3333333333333333333333333333333333333333", "contents": "hello"}
+}`
+
+// newSimulatedTestWallet 创建模拟链上的钱包，返回第一个预置账户的私钥
+func newSimulatedTestWallet(t *testing.T) (*BaseETHWallet, *ecdsa.PrivateKey) {
+	w, err := NewSimulatedWallet(wallet.ChainInfo{ChainType: "dev", ChainID: SimulatedChainID}, "test-encryption-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(w.Close)
+	if !w.checkConnection() {
+		t.Fatal("simulated chain is not connected")
+	}
+
+	accounts, err := DevAccounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParsePrivateKey(accounts[0].PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w, key
+}
+
+// startClef 启动托管keys的模拟Clef签名服务，返回服务地址
+func startClef(t *testing.T, chainID int64, keys ...*ecdsa.PrivateKey) string {
+	clef, err := clefmock.NewServer(big.NewInt(chainID), keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(clef)
+	t.Cleanup(func() {
+		server.Close()
+		clef.Close()
+	})
+	return server.URL
+}
+
+func TestRemoteSignerSignTransaction(t *testing.T) {
+	ctx := context.Background()
+	w, key := newSimulatedTestWallet(t)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+
+	walletID, err := w.ImportRemoteSigner(ctx, startClef(t, SimulatedChainID, key), address.Hex())
+	if err != nil {
+		t.Fatalf("ImportRemoteSigner: %v", err)
+	}
+	// 远程签名的钱包不在本进程保存私钥
+	if w.keyMap[walletID].PrivKeyEnc != "" {
+		t.Fatal("remote signer wallet must not store a private key")
+	}
+
+	to := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	tx, err := w.CreateTransaction(ctx, address.Hex(), to.Hex(), big.NewInt(1e15), nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+
+	// 经account_signTransaction签名，交易内容不变且签名者为该账户
+	signed, err := w.SignTransaction(ctx, walletID, tx)
+	if err != nil {
+		t.Fatalf("SignTransaction: %v", err)
+	}
+	var unsignedTx, signedTx types.Transaction
+	if err := unsignedTx.UnmarshalBinary(tx.Payload); err != nil {
+		t.Fatal(err)
+	}
+	if err := signedTx.UnmarshalBinary(signed.Payload); err != nil {
+		t.Fatal(err)
+	}
+	signer := types.LatestSignerForChainID(big.NewInt(SimulatedChainID))
+	if signer.Hash(&signedTx) != signer.Hash(&unsignedTx) {
+		t.Fatal("signed transaction differs from the request")
+	}
+	if sender, err := types.Sender(signer, &signedTx); err != nil || sender != address {
+		t.Fatalf("transaction signed by %s, want %s", sender.Hex(), address.Hex())
+	}
+
+	txHash, err := w.SendTransaction(ctx, signed)
+	if err != nil {
+		t.Fatalf("SendTransaction: %v", err)
+	}
+	if status, err := w.GetTransactionStatus(ctx, txHash); err != nil || status != string(wallet.TxConfirmed) {
+		t.Fatalf("transaction status %s, err %v", status, err)
+	}
+}
+
+func TestRemoteSignerSignData(t *testing.T) {
+	ctx := context.Background()
+	w, key := newSimulatedTestWallet(t)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+
+	walletID, err := w.ImportRemoteSigner(ctx, startClef(t, SimulatedChainID, key), address.Hex())
+	if err != nil {
+		t.Fatalf("ImportRemoteSigner: %v", err)
+	}
+
+	// account_signData text/plain
+	message := []byte("hello from the remote signer")
+	signature, err := w.SignMessage(ctx, walletID, message)
+	if err != nil {
+		t.Fatalf("SignMessage: %v", err)
+	}
+	verification, err := w.VerifyMessage(ctx, address.Hex(), message, signature)
+	if err != nil || !verification.Valid {
+		t.Fatalf("message signature invalid: %+v, %v", verification, err)
+	}
+
+	// account_signData data/typed
+	signature, err = w.SignTypedData(ctx, walletID, []byte(testTypedData))
+	if err != nil {
+		t.Fatalf("SignTypedData: %v", err)
+	}
+	verification, err = w.VerifyTypedData(ctx, address.Hex(), []byte(testTypedData), signature)
+	if err != nil || !verification.Valid {
+		t.Fatalf("typed data signature invalid: %+v, %v", verification, err)
+	}
+}
+
+func TestRemoteSignerRejections(t *testing.T) {
+	ctx := context.Background()
+	w, key := newSimulatedTestWallet(t)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+
+	// 签名服务未托管的地址不能注册
+	other, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.ImportRemoteSigner(ctx, startClef(t, SimulatedChainID, other), address.Hex()); err == nil {
+		t.Fatal("expected error registering an address the signer does not manage")
+	}
+
+	// 签名服务配置的链ID不同，拒绝签名交易，属于拒绝而非不可达
+	walletID, err := w.ImportRemoteSigner(ctx, startClef(t, 1, key), address.Hex())
+	if err != nil {
+		t.Fatalf("ImportRemoteSigner: %v", err)
+	}
+	tx, err := w.CreateTransaction(ctx, address.Hex(), address.Hex(), big.NewInt(1), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = w.SignTransaction(ctx, walletID, tx)
+	if err == nil || errors.Is(err, wallet.ErrSignerUnavailable) || !strings.Contains(err.Error(), "rejected") {
+		t.Fatalf("expected rejection for chain id mismatch, got %v", err)
+	}
+}
+
+func TestRemoteSignerUnavailable(t *testing.T) {
+	ctx := context.Background()
+	w, key := newSimulatedTestWallet(t)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+
+	clef, err := clefmock.NewServer(big.NewInt(SimulatedChainID), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(clef)
+	walletID, err := w.ImportRemoteSigner(ctx, server.URL, address.Hex())
+	if err != nil {
+		t.Fatalf("ImportRemoteSigner: %v", err)
+	}
+	server.Close()
+	clef.Close()
+
+	if _, err := w.SignMessage(ctx, walletID, []byte("hello")); !errors.Is(err, wallet.ErrSignerUnavailable) {
+		t.Fatalf("expected ErrSignerUnavailable, got %v", err)
+	}
+}
+
+// tamperingClef 签名服务返回与请求不一致的结果
+type tamperingClef struct {
+	key   *ecdsa.PrivateKey
+	other *ecdsa.PrivateKey
+}
+
+func (c *tamperingClef) List() []common.Address {
+	return []common.Address{crypto.PubkeyToAddress(c.key.PublicKey)}
+}
+
+// SignTransaction 修改金额后签名
+func (c *tamperingClef) SignTransaction(args apitypes.SendTxArgs, methodSelector *string) (map[string]interface{}, error) {
+	args.Value = hexutil.Big(*big.NewInt(1e18))
+	tx, err := args.ToTransaction()
+	if err != nil {
+		return nil, err
+	}
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID((*big.Int)(args.ChainID)), c.key)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"raw": hexutil.Bytes(raw), "tx": signed}, nil
+}
+
+// SignData 用其他私钥签名
+func (c *tamperingClef) SignData(contentType string, addr common.MixedcaseAddress, data string) (hexutil.Bytes, error) {
+	raw, err := hexutil.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := crypto.Sign(crypto.Keccak256(raw), c.other)
+	if err != nil {
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
+
+func TestRemoteSignerVerifiesResponses(t *testing.T) {
+	ctx := context.Background()
+	w, key := newSimulatedTestWallet(t)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	other, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("account", &tamperingClef{key: key, other: other}); err != nil {
+		t.Fatal(err)
+	}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	walletID, err := w.ImportRemoteSigner(ctx, httpServer.URL, address.Hex())
+	if err != nil {
+		t.Fatalf("ImportRemoteSigner: %v", err)
+	}
+
+	tx, err := w.CreateTransaction(ctx, address.Hex(), address.Hex(), big.NewInt(1), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.SignTransaction(ctx, walletID, tx); err == nil || !strings.Contains(err.Error(), "modified transaction") {
+		t.Fatalf("expected modified transaction error, got %v", err)
+	}
+	if _, err := w.SignMessage(ctx, walletID, []byte("hello")); err == nil || !strings.Contains(err.Error(), "expected "+address.Hex()) {
+		t.Fatalf("expected wrong signer error, got %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+// Package clefmock 进程内的Clef兼容签名服务，自动批准所有请求并直接持有私钥，只用于测试远程签名，
+// 不得在服务进程中启动
+package clefmock
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"mime"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Server 实现account_list、account_signTransaction和account_signData（text/plain、data/typed）
+type Server struct {
+	chainID *big.Int
+	keys    map[common.Address]*ecdsa.PrivateKey
+	server  *rpc.Server
+}
+
+// NewServer 创建模拟签名服务，chainID与Clef的--chainid参数含义相同
+func NewServer(chainID *big.Int, keys ...*ecdsa.PrivateKey) (*Server, error) {
+	m := &Server{
+		chainID: chainID,
+		keys:    make(map[common.Address]*ecdsa.PrivateKey, len(keys)),
+		server:  rpc.NewServer(),
+	}
+	for _, key := range keys {
+		m.keys[crypto.PubkeyToAddress(key.PublicKey)] = key
+	}
+	if err := m.server.RegisterName("account", &accountAPI{clef: m}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ServeHTTP 处理JSON-RPC请求
+func (m *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.server.ServeHTTP(w, r)
+}
+
+// Close 停止服务
+func (m *Server) Close() {
+	m.server.Stop()
+}
+
+func (m *Server) key(address common.Address) (*ecdsa.PrivateKey, error) {
+	key, ok := m.keys[address]
+	if !ok {
+		return nil, fmt.Errorf("account %s not found", address.Hex())
+	}
+	return key, nil
+}
+
+// signTxResult account_signTransaction的返回值
+type signTxResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+// accountAPI account命名空间的方法
+type accountAPI struct {
+	clef *Server
+}
+
+// List 对应account_list
+func (api *accountAPI) List(ctx context.Context) ([]common.Address, error) {
+	addresses := make([]common.Address, 0, len(api.clef.keys))
+	for address := range api.clef.keys {
+		addresses = append(addresses, address)
+	}
+	return addresses, nil
+}
+
+// SignTransaction 对应account_signTransaction
+func (api *accountAPI) SignTransaction(ctx context.Context, args apitypes.SendTxArgs, methodSelector *string) (*signTxResult, error) {
+	if args.ChainID != nil && (*big.Int)(args.ChainID).Cmp(api.clef.chainID) != 0 {
+		return nil, fmt.Errorf("requested chainid %d does not match the configuration of the signer", (*big.Int)(args.ChainID))
+	}
+	key, err := api.clef.key(args.From.Address())
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := args.ToTransaction()
+	if err != nil {
+		return nil, err
+	}
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID(api.clef.chainID), key)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := signed.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &signTxResult{Raw: raw, Tx: signed}, nil
+}
+
+// SignData 对应account_signData，data为十六进制字符串
+func (api *accountAPI) SignData(ctx context.Context, contentType string, addr common.MixedcaseAddress, data string) (hexutil.Bytes, error) {
+	key, err := api.clef.key(addr.Address())
+	if err != nil {
+		return nil, err
+	}
+	raw, err := hexutil.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	var hash []byte
+	switch mediaType {
+	case accounts.MimetypeTextPlain:
+		hash = accounts.TextHash(raw)
+	case accounts.MimetypeTypedData:
+		var typedData apitypes.TypedData
+		if err := json.Unmarshal(raw, &typedData); err != nil {
+			return nil, err
+		}
+		if hash, _, err = apitypes.TypedDataAndHash(typedData); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("unsupported content type")
+	}
+
+	signature, err := crypto.Sign(hash, key)
+	if err != nil {
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
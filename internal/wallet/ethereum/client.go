@@ -0,0 +1,56 @@
+package ethereum
+
+import (
+	"context"
+	"math/big"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ChainClient BaseETHWallet访问链上数据使用的接口，
+// 由连接真实节点的ethclient或开发模式下的模拟链实现
+type ChainClient interface {
+	// 余额、nonce与合约代码
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error)
+	PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error)
+
+	// 区块
+	BlockNumber(ctx context.Context) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	ChainID(ctx context.Context) (*big.Int, error)
+	SyncProgress(ctx context.Context) (*eth.SyncProgress, error)
+
+	// gas
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	EstimateGas(ctx context.Context, call eth.CallMsg) (uint64, error)
+
+	// 交易
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+
+	// 合约调用与日志
+	CallContract(ctx context.Context, call eth.CallMsg, blockNumber *big.Int) ([]byte, error)
+	FilterLogs(ctx context.Context, query eth.FilterQuery) ([]types.Log, error)
+	SubscribeFilterLogs(ctx context.Context, query eth.FilterQuery, ch chan<- types.Log) (eth.Subscription, error)
+
+	Close()
+}
+
+// rpcClientProvider 可以提供底层JSON-RPC客户端的ChainClient，用于批量请求
+type rpcClientProvider interface {
+	Client() *rpc.Client
+}
+
+var (
+	_ ChainClient       = (*ethclient.Client)(nil)
+	_ rpcClientProvider = (*ethclient.Client)(nil)
+)
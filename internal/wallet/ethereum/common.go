@@ -2,35 +2,31 @@ package ethereum
 
 import (
 	"context"
-	"crypto/aes"
-	"crypto/cipher"
 	"crypto/ecdsa"
-	"crypto/rand"
-	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"math/big"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
-	hdwallet "github.com/miguelmota/go-ethereum-hdwallet"
 	"github.com/tyler-smith/go-bip39"
 
 	"multi-chain-wallet/internal/wallet"
+	"multi-chain-wallet/internal/wallet/hd"
+	"multi-chain-wallet/internal/wallet/keycrypt"
+	"multi-chain-wallet/internal/wallet/mpc"
 
 	"github.com/ethereum/go-ethereum"
 	eth "github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 // KeyStore 钱包密钥库
@@ -39,51 +35,74 @@ type KeyStore struct {
 	Address     string           `json:"address"`
 	PrivKeyEnc  string           `json:"privKeyEnc"`
 	MnemonicEnc string           `json:"mnemonicEnc,omitempty"`
+	SignerURL   string           `json:"signerUrl,omitempty"` // 远程签名服务地址，非空时私钥不在本进程
+	MPCKey      *mpc.Key         `json:"mpcKey,omitempty"`    // 门限密钥，非空时私钥以分片形式保存在签名节点上
 	ChainType   wallet.ChainType `json:"chainType"`
 	CreateTime  int64            `json:"createTime"`
 }
 
 // BaseETHWallet 以太坊系列钱包基础实现
 type BaseETHWallet struct {
-	client        *ethclient.Client
+	connMu        sync.RWMutex
+	client        ChainClient // 未连接时为nil，通过getClient获取
+	dial          func() (ChainClient, *RPCPool, error)
 	encryptionKey []byte
 	keyMap        map[string]*KeyStore // walletID -> keystore
+	signerMu      sync.Mutex
+	signerClients map[string]*rpc.Client // 远程签名服务地址 -> RPC连接
+	coordinator   *mpc.Coordinator       // 门限签名协议协调方
+	bundlerURL    string                 // ERC-4337 bundler地址，为空时不支持UserOperation
+	bundlerMu     sync.Mutex
+	bundler       *rpc.Client // 首次提交UserOperation时建立连接
 	chainType     wallet.ChainType
 	chainID       *big.Int
-	rpcURL        string
+	rpcURLs       []string
+	rpcPool       *RPCPool // 配置多个节点时使用，单节点时为nil
+	status        wallet.ChainStatusInfo
+	stopChan      chan struct{}
+	closeOnce     sync.Once
+	eip1559       bool
 	keyDerivPath  string
 	tokenABI      string
 }
 
-// NewBaseETHWallet 创建新的以太坊系列钱包
-func NewBaseETHWallet(chainType wallet.ChainType, rpcURL string, chainID *big.Int, encryptionKey string) (*BaseETHWallet, error) {
+// NewBaseETHWallet 创建新的以太坊系列钱包。RPC连接在后台建立并自动重连，
+// 节点不可达不会导致创建失败，期间的请求返回ErrChainUnavailable
+func NewBaseETHWallet(chainType wallet.ChainType, rpcURLs []string, chainID *big.Int, encryptionKey string) (*BaseETHWallet, error) {
 	fmt.Printf("BaseETHWallet: Creating new wallet for chain type: %s\n", chainType)
 
-	client, err := ethclient.Dial(rpcURL)
-	if err != nil {
-		fmt.Printf("BaseETHWallet: Error connecting to RPC: %v\n", err)
-		return nil, fmt.Errorf("failed to connect to %s network: %v", chainType, err)
+	if len(rpcURLs) == 0 {
+		return nil, errors.New("no rpc url configured")
 	}
 
-	fmt.Printf("BaseETHWallet: Connected to RPC successfully\n")
+	base := newBaseETHWallet(chainType, chainID, encryptionKey, func() (ChainClient, *RPCPool, error) {
+		return dialRPC(chainType, rpcURLs)
+	})
+	base.rpcURLs = rpcURLs
 
-	// 将加密密钥转换为固定长度密钥
-	// 实际应用中应该使用更安全的密钥派生和存储方式
-	key := sha256.Sum256([]byte(encryptionKey))
+	fmt.Printf("BaseETHWallet: Wallet created successfully with chain type: %s\n", base.ChainType())
+	return base, nil
+}
 
-	wallet := &BaseETHWallet{
-		client:        client,
-		encryptionKey: key[:],
+// newBaseETHWallet 使用指定的连接方式创建钱包并启动后台连接维护
+func newBaseETHWallet(chainType wallet.ChainType, chainID *big.Int, encryptionKey string, dial func() (ChainClient, *RPCPool, error)) *BaseETHWallet {
+	w := &BaseETHWallet{
+		dial:          dial,
+		encryptionKey: keycrypt.DeriveKey(encryptionKey),
 		keyMap:        make(map[string]*KeyStore),
+		signerClients: make(map[string]*rpc.Client),
+		coordinator:   mpc.NewCoordinator(),
 		chainType:     chainType,
 		chainID:       chainID,
-		rpcURL:        rpcURL,
+		status:        wallet.ChainStatusInfo{ChainType: chainType, Status: wallet.ChainStatusConnecting},
+		stopChan:      make(chan struct{}),
 		keyDerivPath:  "m/44'/60'/0'/0/0", // 以太坊系列通用路径
 		tokenABI:      "",                 // 在具体实现中设置
 	}
 
-	fmt.Printf("BaseETHWallet: Wallet created successfully with chain type: %s\n", wallet.ChainType())
-	return wallet, nil
+	go w.monitorConnection()
+
+	return w
 }
 
 // 生成助记词
@@ -103,94 +122,54 @@ func (w *BaseETHWallet) generateMnemonic() (string, error) {
 
 // 从助记词派生私钥
 func (w *BaseETHWallet) derivePrivateKey(mnemonic string) (*ecdsa.PrivateKey, common.Address, error) {
-	seed := bip39.NewSeed(mnemonic, "")
-	wallet, err := hdwallet.NewFromSeed(seed)
+	privateKey, err := DerivePrivateKey(mnemonic, w.keyDerivPath)
 	if err != nil {
 		return nil, common.Address{}, err
 	}
+	return privateKey, crypto.PubkeyToAddress(privateKey.PublicKey), nil
+}
 
-	path, err := accounts.ParseDerivationPath(w.keyDerivPath)
+// 从私钥字符串转换为私钥对象
+func (w *BaseETHWallet) privateKeyFromString(privateKeyStr string) (*ecdsa.PrivateKey, common.Address, error) {
+	privateKey, err := ParsePrivateKey(privateKeyStr)
 	if err != nil {
 		return nil, common.Address{}, err
 	}
+	return privateKey, crypto.PubkeyToAddress(privateKey.PublicKey), nil
+}
 
-	account, err := wallet.Derive(path, false)
+// DerivePrivateKey 从助记词按BIP32路径派生secp256k1私钥，Tron等同曲线的链也使用
+func DerivePrivateKey(mnemonic string, path string) (*ecdsa.PrivateKey, error) {
+	key, err := hd.DeriveFromMnemonic(mnemonic, path)
 	if err != nil {
-		return nil, common.Address{}, err
+		return nil, err
 	}
 
-	privateKey, err := wallet.PrivateKey(account)
+	btcecKey, err := key.ECPrivKey()
 	if err != nil {
-		return nil, common.Address{}, err
+		return nil, err
 	}
 
-	return privateKey, account.Address, nil
+	return btcecKey.ToECDSA(), nil
 }
 
-// 从私钥字符串转换为私钥对象
-func (w *BaseETHWallet) privateKeyFromString(privateKeyStr string) (*ecdsa.PrivateKey, common.Address, error) {
+// ParsePrivateKey 解析hex编码的secp256k1私钥
+func ParsePrivateKey(privateKeyStr string) (*ecdsa.PrivateKey, error) {
 	privateKeyBytes, err := hex.DecodeString(privateKeyStr)
 	if err != nil {
-		return nil, common.Address{}, err
-	}
-
-	privateKey, err := crypto.ToECDSA(privateKeyBytes)
-	if err != nil {
-		return nil, common.Address{}, err
-	}
-
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return nil, common.Address{}, errors.New("failed to get public key")
+		return nil, err
 	}
-
-	address := crypto.PubkeyToAddress(*publicKeyECDSA)
-	return privateKey, address, nil
+	return crypto.ToECDSA(privateKeyBytes)
 }
 
 // 加密数据
 func (w *BaseETHWallet) encrypt(data []byte) (string, error) {
-	block, err := aes.NewCipher(w.encryptionKey)
-	if err != nil {
-		return "", err
-	}
-
-	ciphertext := make([]byte, aes.BlockSize+len(data))
-	iv := ciphertext[:aes.BlockSize]
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
-		return "", err
-	}
-
-	stream := cipher.NewCFBEncrypter(block, iv)
-	stream.XORKeyStream(ciphertext[aes.BlockSize:], data)
-
-	return hex.EncodeToString(ciphertext), nil
+	return keycrypt.Encrypt(w.encryptionKey, data)
 }
 
 // 解密数据
 func (w *BaseETHWallet) decrypt(encryptedHex string) ([]byte, error) {
-	ciphertext, err := hex.DecodeString(encryptedHex)
-	if err != nil {
-		return nil, err
-	}
-
-	block, err := aes.NewCipher(w.encryptionKey)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(ciphertext) < aes.BlockSize {
-		return nil, errors.New("ciphertext too short")
-	}
-
-	iv := ciphertext[:aes.BlockSize]
-	ciphertext = ciphertext[aes.BlockSize:]
-
-	stream := cipher.NewCFBDecrypter(block, iv)
-	stream.XORKeyStream(ciphertext, ciphertext)
-
-	return ciphertext, nil
+	return keycrypt.Decrypt(w.encryptionKey, encryptedHex)
 }
 
 // 保存加密的密钥
@@ -325,17 +304,72 @@ func (w *BaseETHWallet) ImportFromPrivateKey(privateKeyStr string) (string, erro
 func (w *BaseETHWallet) GetAddress(walletID string) (string, error) {
 	keystore, exists := w.keyMap[walletID]
 	if !exists {
-		return "", errors.New("wallet not found")
+		return "", wallet.ErrWalletNotFound
 	}
 
 	return keystore.Address, nil
 }
 
-// getPrivateKey 获取私钥（内部使用）
-func (w *BaseETHWallet) getPrivateKey(walletID string) (*ecdsa.PrivateKey, error) {
+// ImportRemoteSigner 注册由远程签名服务（Clef兼容）托管私钥的钱包，注册前通过account_list确认服务管理该地址
+func (w *BaseETHWallet) ImportRemoteSigner(ctx context.Context, signerURL string, address string) (string, error) {
+	if !common.IsHexAddress(address) {
+		return "", errors.New("invalid address format")
+	}
+	signerAddress := common.HexToAddress(address)
+
+	client, err := w.signerClient(ctx, signerURL)
+	if err != nil {
+		return "", err
+	}
+	addresses, err := ListClefAccounts(ctx, client)
+	if err != nil {
+		return "", err
+	}
+	found := false
+	for _, managed := range addresses {
+		if managed == signerAddress {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("remote signer does not manage account %s", signerAddress.Hex())
+	}
+
+	keystore := &KeyStore{
+		ID:         uuid.New().String(),
+		Address:    signerAddress.Hex(),
+		SignerURL:  signerURL,
+		ChainType:  w.chainType,
+		CreateTime: time.Now().Unix(),
+	}
+	if err := w.saveKeyStore(keystore); err != nil {
+		return "", fmt.Errorf("failed to save keystore: %v", err)
+	}
+
+	return keystore.ID, nil
+}
+
+// getSigner 获取钱包的签名器（内部使用），远程签名的钱包返回Clef签名器，门限钱包返回门限签名器
+func (w *BaseETHWallet) getSigner(ctx context.Context, walletID string) (Signer, error) {
 	keystore, exists := w.keyMap[walletID]
 	if !exists {
-		return nil, errors.New("wallet not found")
+		return nil, wallet.ErrWalletNotFound
+	}
+
+	if keystore.SignerURL != "" {
+		client, err := w.signerClient(ctx, keystore.SignerURL)
+		if err != nil {
+			return nil, err
+		}
+		return NewClefSigner(client, common.HexToAddress(keystore.Address)), nil
+	}
+	if keystore.MPCKey != nil {
+		return &thresholdSigner{
+			coordinator: w.coordinator,
+			key:         keystore.MPCKey,
+			address:     common.HexToAddress(keystore.Address),
+		}, nil
 	}
 
 	privateKeyBytes, err := w.decrypt(keystore.PrivKeyEnc)
@@ -348,7 +382,23 @@ func (w *BaseETHWallet) getPrivateKey(walletID string) (*ecdsa.PrivateKey, error
 		return nil, fmt.Errorf("invalid private key: %v", err)
 	}
 
-	return privateKey, nil
+	return NewLocalSigner(privateKey), nil
+}
+
+// signerClient 获取远程签名服务的RPC连接，同一地址的钱包共用连接
+func (w *BaseETHWallet) signerClient(ctx context.Context, signerURL string) (*rpc.Client, error) {
+	w.signerMu.Lock()
+	defer w.signerMu.Unlock()
+
+	if client, ok := w.signerClients[signerURL]; ok {
+		return client, nil
+	}
+	client, err := rpc.DialContext(ctx, signerURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", wallet.ErrSignerUnavailable, err)
+	}
+	w.signerClients[signerURL] = client
+	return client, nil
 }
 
 // ChainType 获取链类型
@@ -363,7 +413,12 @@ func (w *BaseETHWallet) GetBalance(ctx context.Context, address string) (*big.In
 	}
 
 	account := common.HexToAddress(address)
-	balance, err := w.client.BalanceAt(ctx, account, nil)
+	client, err := w.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := client.BalanceAt(ctx, account, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get balance: %v", err)
 	}
@@ -387,7 +442,12 @@ func (w *BaseETHWallet) GetTokenBalance(ctx context.Context, address string, tok
 	}
 
 	// 创建合约实例
-	token := bind.NewBoundContract(common.HexToAddress(tokenAddress), tokenABI, w.client, w.client, w.client)
+	client, err := w.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	token := bind.NewBoundContract(common.HexToAddress(tokenAddress), tokenABI, client, client, client)
 
 	// 调用合约方法
 	var result []interface{}
@@ -409,8 +469,8 @@ func (w *BaseETHWallet) GetTokenBalance(ctx context.Context, address string, tok
 	return balance, nil
 }
 
-// CreateTransaction 创建交易
-func (w *BaseETHWallet) CreateTransaction(ctx context.Context, from string, to string, amount *big.Int, data []byte) ([]byte, error) {
+// CreateTransaction 创建交易，Payload为RLP编码的未签名交易
+func (w *BaseETHWallet) CreateTransaction(ctx context.Context, from string, to string, amount *big.Int, data []byte) (*wallet.UnsignedTx, error) {
 	if !common.IsHexAddress(from) || !common.IsHexAddress(to) {
 		return nil, errors.New("invalid address format")
 	}
@@ -419,26 +479,31 @@ func (w *BaseETHWallet) CreateTransaction(ctx context.Context, from string, to s
 	toAddress := common.HexToAddress(to)
 
 	// 获取发送者的nonce
-	nonce, err := w.client.PendingNonceAt(ctx, fromAddress)
+	client, err := w.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := client.PendingNonceAt(ctx, fromAddress)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get nonce: %v", err)
 	}
 
-	// 获取当前gas价格
-	gasPrice, err := w.client.SuggestGasPrice(ctx)
+	// 获取当前gas费用
+	fees, err := w.suggestFees(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get gas price: %v", err)
+		return nil, err
 	}
 
 	// 创建交易对象
 	var tx *types.Transaction
 	if data == nil || len(data) == 0 {
 		// 普通转账交易
-		tx = types.NewTransaction(nonce, toAddress, amount, 21000, gasPrice, nil)
+		tx = w.newTransaction(nonce, toAddress, amount, 21000, fees, nil)
 	} else {
 		// 合约交互交易
 		// 预估gas用量
-		gasLimit, err := w.client.EstimateGas(ctx, eth.CallMsg{
+		gasLimit, err := client.EstimateGas(ctx, eth.CallMsg{
 			From:  fromAddress,
 			To:    &toAddress,
 			Value: amount,
@@ -448,61 +513,194 @@ func (w *BaseETHWallet) CreateTransaction(ctx context.Context, from string, to s
 			return nil, fmt.Errorf("failed to estimate gas: %v", err)
 		}
 
-		tx = types.NewTransaction(nonce, toAddress, amount, gasLimit, gasPrice, data)
+		tx = w.newTransaction(nonce, toAddress, amount, gasLimit, fees, data)
 	}
 
-	// 将交易序列化为JSON
-	txJSON, err := json.Marshal(tx)
+	// 将交易编码为RLP
+	payload, err := tx.MarshalBinary()
 	if err != nil {
 		return nil, fmt.Errorf("failed to serialize transaction: %v", err)
 	}
 
-	return txJSON, nil
+	return &wallet.UnsignedTx{
+		ChainType: w.chainType,
+		From:      fromAddress.Hex(),
+		To:        toAddress.Hex(),
+		Value:     tx.Value(),
+		Nonce:     nonce,
+		Fee:       txFee(tx),
+		Payload:   payload,
+	}, nil
+}
+
+// txFee 从交易中提取费用字段
+func txFee(tx *types.Transaction) *wallet.TxFee {
+	gas := new(big.Int).SetUint64(tx.Gas())
+	if tx.Type() == types.LegacyTxType {
+		amount := new(big.Int).Mul(gas, tx.GasPrice())
+		return &wallet.TxFee{GasLimit: tx.Gas(), GasPrice: tx.GasPrice(), Amount: amount, MaxAmount: amount}
+	}
+	return &wallet.TxFee{
+		GasLimit:  tx.Gas(),
+		GasTipCap: tx.GasTipCap(),
+		GasFeeCap: tx.GasFeeCap(),
+		MaxAmount: new(big.Int).Mul(gas, tx.GasFeeCap()),
+	}
+}
+
+// ERC20 transfer的ABI
+const erc20TransferABI = `[{"constant":false,"inputs":[{"name":"_to","type":"address"},{"name":"_value","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"payable":false,"stateMutability":"nonpayable","type":"function"}]`
+
+// CreateTokenTransaction 创建ERC20代币转账交易
+func (w *BaseETHWallet) CreateTokenTransaction(ctx context.Context, from string, to string, tokenAddress string, amount *big.Int) (*wallet.UnsignedTx, error) {
+	if !common.IsHexAddress(to) || !common.IsHexAddress(tokenAddress) {
+		return nil, errors.New("invalid address format")
+	}
+
+	transferABI, err := abi.JSON(strings.NewReader(erc20TransferABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %v", err)
+	}
+
+	data, err := transferABI.Pack("transfer", common.HexToAddress(to), amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack transfer: %v", err)
+	}
+
+	tx, err := w.CreateTransaction(ctx, from, tokenAddress, big.NewInt(0), data)
+	if err != nil {
+		return nil, err
+	}
+
+	// 通用字段记录实际收款人和代币金额，链上交易发往代币合约
+	tx.To = common.HexToAddress(to).Hex()
+	tx.Value = amount
+	tx.Token = common.HexToAddress(tokenAddress).Hex()
+	return tx, nil
+}
+
+// txFees 交易费用参数，EIP-1559链使用tipCap/feeCap，其余使用gasPrice
+type txFees struct {
+	gasPrice  *big.Int
+	gasTipCap *big.Int
+	gasFeeCap *big.Int
 }
 
-// SignTransaction 签名交易
-func (w *BaseETHWallet) SignTransaction(ctx context.Context, walletID string, txJSON []byte) ([]byte, error) {
-	privateKey, err := w.getPrivateKey(walletID)
+// suggestFees 按链是否支持EIP-1559获取建议费用
+func (w *BaseETHWallet) suggestFees(ctx context.Context) (*txFees, error) {
+	client, err := w.getClient()
 	if err != nil {
 		return nil, err
 	}
 
-	// 反序列化交易
+	if !w.eip1559 {
+		gasPrice, err := client.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get gas price: %v", err)
+		}
+		return &txFees{gasPrice: gasPrice}, nil
+	}
+
+	tipCap, err := client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gas tip cap: %v", err)
+	}
+
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest header: %v", err)
+	}
+	if header.BaseFee == nil {
+		return nil, fmt.Errorf("chain %s has no base fee, check eip1559 setting", w.chainType)
+	}
+
+	// feeCap = 2 * baseFee + tipCap，可容忍连续数个区块的baseFee上涨
+	feeCap := new(big.Int).Add(new(big.Int).Mul(header.BaseFee, big.NewInt(2)), tipCap)
+
+	return &txFees{gasTipCap: tipCap, gasFeeCap: feeCap}, nil
+}
+
+// newTransaction 按费用模型创建legacy或EIP-1559交易
+func (w *BaseETHWallet) newTransaction(nonce uint64, to common.Address, amount *big.Int, gasLimit uint64, fees *txFees, data []byte) *types.Transaction {
+	if fees.gasFeeCap == nil {
+		return types.NewTransaction(nonce, to, amount, gasLimit, fees.gasPrice, data)
+	}
+
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   w.chainID,
+		Nonce:     nonce,
+		GasTipCap: fees.gasTipCap,
+		GasFeeCap: fees.gasFeeCap,
+		Gas:       gasLimit,
+		To:        &to,
+		Value:     amount,
+		Data:      data,
+	})
+}
+
+// SignTransaction 签名交易，返回的Payload可直接用于eth_sendRawTransaction
+func (w *BaseETHWallet) SignTransaction(ctx context.Context, walletID string, unsignedTx *wallet.UnsignedTx) (*wallet.SignedTx, error) {
+	if err := unsignedTx.CheckChain(w.chainType); err != nil {
+		return nil, err
+	}
+
+	txSigner, err := w.getSigner(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 解码交易
 	var tx types.Transaction
-	if err := json.Unmarshal(txJSON, &tx); err != nil {
+	if err := tx.UnmarshalBinary(unsignedTx.Payload); err != nil {
 		return nil, fmt.Errorf("failed to deserialize transaction: %v", err)
 	}
 
+	signer := txSigner.Address()
+	if unsignedTx.From != "" && common.HexToAddress(unsignedTx.From) != signer {
+		return nil, fmt.Errorf("transaction sender %s does not match wallet address %s", unsignedTx.From, signer.Hex())
+	}
+
 	// 签名交易
-	signedTx, err := types.SignTx(&tx, types.NewEIP155Signer(w.chainID), privateKey)
+	signedTx, err := txSigner.SignTx(ctx, &tx, w.chainID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to sign transaction: %v", err)
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
 	}
 
-	// 将签名后的交易序列化
-	signedTxJSON, err := json.Marshal(signedTx)
+	// 将签名后的交易编码为RLP
+	payload, err := signedTx.MarshalBinary()
 	if err != nil {
 		return nil, fmt.Errorf("failed to serialize signed transaction: %v", err)
 	}
 
-	return signedTxJSON, nil
+	result := unsignedTx.Signed(payload, signedTx.Hash().Hex())
+	result.From = signer.Hex()
+	return result, nil
 }
 
 // SendTransaction 发送交易
-func (w *BaseETHWallet) SendTransaction(ctx context.Context, signedTxJSON []byte) (string, error) {
-	// 反序列化签名后的交易
-	var signedTx types.Transaction
-	if err := json.Unmarshal(signedTxJSON, &signedTx); err != nil {
+func (w *BaseETHWallet) SendTransaction(ctx context.Context, signedTx *wallet.SignedTx) (string, error) {
+	if err := signedTx.CheckChain(w.chainType); err != nil {
+		return "", err
+	}
+
+	// 解码签名后的交易
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(signedTx.Payload); err != nil {
 		return "", fmt.Errorf("failed to deserialize signed transaction: %v", err)
 	}
 
+	client, err := w.getClient()
+	if err != nil {
+		return "", err
+	}
+
 	// 发送交易
-	err := w.client.SendTransaction(ctx, &signedTx)
+	err = client.SendTransaction(ctx, &tx)
 	if err != nil {
 		return "", fmt.Errorf("failed to send transaction: %v", err)
 	}
 
-	return signedTx.Hash().Hex(), nil
+	return tx.Hash().Hex(), nil
 }
 
 // GetTransactionStatus 获取交易状态
@@ -510,12 +708,17 @@ func (w *BaseETHWallet) GetTransactionStatus(ctx context.Context, txHash string)
 	hash := common.HexToHash(txHash)
 
 	// 获取交易收据
-	receipt, err := w.client.TransactionReceipt(ctx, hash)
+	client, err := w.getClient()
+	if err != nil {
+		return "", err
+	}
+
+	receipt, err := client.TransactionReceipt(ctx, hash)
 	if err != nil {
 		// 如果交易未找到，可能还处于pending状态
 		if err == ethereum.NotFound {
 			// 检查交易是否在交易池中
-			_, isPending, err := w.client.TransactionByHash(ctx, hash)
+			_, isPending, err := client.TransactionByHash(ctx, hash)
 			if err != nil {
 				return "", fmt.Errorf("failed to get transaction: %v", err)
 			}
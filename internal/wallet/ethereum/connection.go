@@ -0,0 +1,226 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+const (
+	// 连接检查超时
+	connectionCheckTimeout = 5 * time.Second
+	// 首次重连等待时间，之后指数退避
+	reconnectMinDelay = 2 * time.Second
+	// 重连最大等待时间
+	reconnectMaxDelay = time.Minute
+)
+
+// rpcURLPattern 匹配错误信息中的RPC地址，如net/http返回的`Post "https://.../v3/<API_KEY>": ...`
+var rpcURLPattern = regexp.MustCompile(`(?i)\b(?:https?|wss?)://[^\s"'<>]+`)
+
+// getClient 获取当前可用的RPC客户端，链不可用时返回ErrChainUnavailable
+func (w *BaseETHWallet) getClient() (ChainClient, error) {
+	w.connMu.RLock()
+	defer w.connMu.RUnlock()
+
+	if w.client == nil || w.status.Status == wallet.ChainStatusUnreachable {
+		if w.status.LastError != "" {
+			return nil, fmt.Errorf("%w: %s is %s: %s", wallet.ErrChainUnavailable, w.chainType, w.status.Status, w.status.LastError)
+		}
+		return nil, fmt.Errorf("%w: %s is %s", wallet.ErrChainUnavailable, w.chainType, w.status.Status)
+	}
+
+	return w.client, nil
+}
+
+// Status 返回链的连接状态
+func (w *BaseETHWallet) Status() wallet.ChainStatusInfo {
+	w.connMu.RLock()
+	defer w.connMu.RUnlock()
+	return w.status
+}
+
+// RPCHealth 返回RPC节点的健康状况，单节点时返回nil
+func (w *BaseETHWallet) RPCHealth() []wallet.RPCEndpointHealth {
+	w.connMu.RLock()
+	pool := w.rpcPool
+	w.connMu.RUnlock()
+
+	if pool == nil {
+		return nil
+	}
+	return pool.Health()
+}
+
+// Close 停止后台连接检查并断开RPC连接，可重复调用
+func (w *BaseETHWallet) Close() {
+	w.closeOnce.Do(func() { close(w.stopChan) })
+
+	w.connMu.Lock()
+	defer w.connMu.Unlock()
+	w.disconnect()
+}
+
+// monitorConnection 后台维护RPC连接：连接正常时定期检查，失败时按指数退避重连
+func (w *BaseETHWallet) monitorConnection() {
+	backoff := reconnectMinDelay
+	for {
+		wait := rpcHealthCheckInterval
+		if w.checkConnection() {
+			backoff = reconnectMinDelay
+		} else {
+			wait = backoff
+			backoff *= 2
+			if backoff > reconnectMaxDelay {
+				backoff = reconnectMaxDelay
+			}
+		}
+
+		select {
+		case <-w.stopChan:
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// checkConnection 按需建立连接并检查节点状态，返回节点是否可用
+func (w *BaseETHWallet) checkConnection() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionCheckTimeout)
+	defer cancel()
+
+	w.connMu.RLock()
+	client := w.client
+	w.connMu.RUnlock()
+
+	if client == nil {
+		var pool *RPCPool
+		var err error
+		client, pool, err = w.dial()
+		if err != nil {
+			w.setStatus(wallet.ChainStatusUnreachable, 0, fmt.Errorf("failed to connect: %v", err))
+			return false
+		}
+
+		w.connMu.Lock()
+		w.client = client
+		w.rpcPool = pool
+		w.connMu.Unlock()
+	}
+
+	blockNumber, err := client.BlockNumber(ctx)
+	if err != nil {
+		// 断开连接，下次检查时重新拨号
+		w.connMu.Lock()
+		w.disconnect()
+		w.connMu.Unlock()
+		w.setStatus(wallet.ChainStatusUnreachable, 0, err)
+		return false
+	}
+
+	progress, err := client.SyncProgress(ctx)
+	if err == nil && progress != nil && !progress.Done() {
+		w.setStatus(wallet.ChainStatusSyncing, blockNumber, nil)
+		return true
+	}
+
+	w.setStatus(wallet.ChainStatusConnected, blockNumber, nil)
+	return true
+}
+
+// setStatus 更新连接状态，状态变化时输出日志
+func (w *BaseETHWallet) setStatus(status wallet.ChainStatus, blockNumber uint64, err error) {
+	w.connMu.Lock()
+	defer w.connMu.Unlock()
+
+	// 错误信息会通过链状态接口和ErrChainUnavailable返回给调用方，先隐藏其中的API密钥
+	lastError := ""
+	if err != nil {
+		lastError = redactRPCError(err)
+	}
+
+	if w.status.Status != status {
+		if err != nil {
+			fmt.Printf("BaseETHWallet: %s status %s -> %s: %s\n", w.chainType, w.status.Status, status, lastError)
+		} else {
+			fmt.Printf("BaseETHWallet: %s status %s -> %s\n", w.chainType, w.status.Status, status)
+		}
+	}
+
+	w.status.Status = status
+	if blockNumber > 0 {
+		w.status.BlockNumber = blockNumber
+	}
+	w.status.LastError = lastError
+	w.status.LastCheck = time.Now().Unix()
+}
+
+// disconnect 关闭当前连接，调用方需持有connMu写锁
+func (w *BaseETHWallet) disconnect() {
+	if w.client != nil {
+		w.client.Close()
+		w.client = nil
+	}
+	if w.rpcPool != nil {
+		w.rpcPool.Stop()
+		w.rpcPool = nil
+	}
+}
+
+// redactRPCError 返回隐藏了RPC地址中认证信息和API密钥的错误信息
+func redactRPCError(err error) string {
+	if err == nil {
+		return ""
+	}
+	return rpcURLPattern.ReplaceAllStringFunc(err.Error(), redactRPCURL)
+}
+
+// redactRPCURL 隐藏RPC地址中的认证信息、路径和查询参数，只保留协议和主机，
+// 各服务商的API密钥都在这几部分中（如infura的/v3/<KEY>、alchemy的/v2/<KEY>）
+func redactRPCURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return "***"
+	}
+	redacted := parsed.Scheme + "://" + parsed.Host
+	if parsed.User != nil {
+		redacted = parsed.Scheme + "://***@" + parsed.Host
+	}
+	if strings.Trim(parsed.Path, "/") != "" || parsed.RawQuery != "" {
+		redacted += "/***"
+	}
+	return redacted
+}
+
+// dialRPC 连接RPC节点：单节点直接连接，多个HTTP节点时使用节点池
+func dialRPC(chainType wallet.ChainType, rpcURLs []string) (ChainClient, *RPCPool, error) {
+	if len(rpcURLs) == 1 {
+		client, err := ethclient.Dial(rpcURLs[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		return client, nil, nil
+	}
+
+	pool, err := NewRPCPool(chainType, rpcURLs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rpcClient, err := rpc.DialOptions(context.Background(), pool.URL(), rpc.WithHTTPClient(&http.Client{Transport: pool}))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pool.Start()
+	return ethclient.NewClient(rpcClient), pool, nil
+}
@@ -6,22 +6,21 @@ import (
 	"multi-chain-wallet/internal/wallet"
 )
 
-// ETHWallet 以太坊钱包实现
-type ETHWallet struct {
-	*BaseETHWallet
-}
-
-// NewETHWallet 创建新的以太坊钱包
-func NewETHWallet(rpcURL string, encryptionKey string) (*ETHWallet, error) {
-	// 以太坊主网ChainID为1
-	chainID := big.NewInt(1)
-
-	base, err := NewBaseETHWallet(wallet.ChainTypeETH, rpcURL, chainID, encryptionKey)
+// NewWalletFromChain 按链注册表中的链信息创建EVM钱包
+func NewWalletFromChain(chain wallet.ChainInfo, encryptionKey string) (*BaseETHWallet, error) {
+	base, err := NewBaseETHWallet(chain.ChainType, chain.RPCURLs, new(big.Int).SetUint64(chain.ChainID), encryptionKey)
 	if err != nil {
 		return nil, err
 	}
+	base.eip1559 = chain.EIP1559
+	base.bundlerURL = chain.BundlerURL
 
-	return &ETHWallet{
-		BaseETHWallet: base,
-	}, nil
+	return base, nil
+}
+
+// NewFactory 创建EVM链的钱包工厂，供Manager按链注册表实例化钱包
+func NewFactory(encryptionKey string) wallet.WalletFactory {
+	return func(chain wallet.ChainInfo) (wallet.Wallet, error) {
+		return NewWalletFromChain(chain, encryptionKey)
+	}
 }
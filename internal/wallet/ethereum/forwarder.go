@@ -0,0 +1,298 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// 未指定有效期时ForwardRequest的默认有效期
+const defaultForwardRequestTTL = time.Hour
+
+// OpenZeppelin ERC2771Forwarder（v5）的nonces、verify、execute，以及ERC-5267 eip712Domain
+const erc2771ForwarderABI = `[
+	{"inputs":[{"name":"owner","type":"address"}],"name":"nonces","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"components":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"gas","type":"uint256"},{"name":"deadline","type":"uint48"},{"name":"data","type":"bytes"},{"name":"signature","type":"bytes"}],"name":"request","type":"tuple"}],"name":"verify","outputs":[{"name":"","type":"bool"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"components":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"gas","type":"uint256"},{"name":"deadline","type":"uint48"},{"name":"data","type":"bytes"},{"name":"signature","type":"bytes"}],"name":"request","type":"tuple"}],"name":"execute","outputs":[],"stateMutability":"payable","type":"function"},
+	{"inputs":[],"name":"eip712Domain","outputs":[{"name":"fields","type":"bytes1"},{"name":"name","type":"string"},{"name":"version","type":"string"},{"name":"chainId","type":"uint256"},{"name":"verifyingContract","type":"address"},{"name":"salt","type":"bytes32"},{"name":"extensions","type":"uint256[]"}],"stateMutability":"view","type":"function"}
+]`
+
+// ERC-2771接收方合约的isTrustedForwarder
+const erc2771RecipientABI = `[{"inputs":[{"name":"forwarder","type":"address"}],"name":"isTrustedForwarder","outputs":[{"name":"","type":"bool"}],"stateMutability":"view","type":"function"}]`
+
+// forwardRequestData ERC2771Forwarder.ForwardRequestData，字段顺序与合约一致。nonce不在结构中，由转发合约按from读取
+type forwardRequestData struct {
+	From      common.Address
+	To        common.Address
+	Value     *big.Int
+	Gas       *big.Int
+	Deadline  *big.Int
+	Data      []byte
+	Signature []byte
+}
+
+// BuildForwardRequestTypedData 构造ForwardRequest的EIP-712签名数据，域的name和version从转发合约的eip712Domain读取
+func (w *BaseETHWallet) BuildForwardRequestTypedData(ctx context.Context, forwarder string, req *wallet.ForwardRequest) (*wallet.ForwardRequest, []byte, string, error) {
+	if !common.IsHexAddress(forwarder) || !common.IsHexAddress(req.From) || !common.IsHexAddress(req.To) {
+		return nil, nil, "", errors.New("invalid address format")
+	}
+	forwarderAddress := common.HexToAddress(forwarder)
+	data, err := forwardRequestCallData(req)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	filled := *req
+	filled.From = common.HexToAddress(req.From).Hex()
+	filled.To = common.HexToAddress(req.To).Hex()
+	filled.Value = bigOrZero(req.Value)
+	filled.Data = hexutil.Encode(data)
+	if filled.Nonce == nil {
+		result, err := w.callContract(ctx, forwarderAddress, erc2771ForwarderABI, "nonces", common.HexToAddress(req.From))
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("failed to get forwarder nonce: %v", err)
+		}
+		nonce, ok := result[0].(*big.Int)
+		if !ok {
+			return nil, nil, "", errors.New("failed to convert nonce to big.Int")
+		}
+		filled.Nonce = nonce
+	}
+	if filled.Deadline == 0 {
+		filled.Deadline = uint64(time.Now().Add(defaultForwardRequestTTL).Unix())
+	}
+	if filled.Gas == 0 {
+		gas, err := w.estimateForwardedGas(ctx, forwarderAddress, &filled, data)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		filled.Gas = gas
+	}
+
+	typedData, err := w.forwardRequestTypedData(ctx, forwarderAddress, &filled)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to hash forward request: %v", err)
+	}
+	typedDataJSON, err := json.Marshal(typedData)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to serialize typed data: %v", err)
+	}
+
+	return &filled, typedDataJSON, hexutil.Encode(hash), nil
+}
+
+// VerifyForwardRequest 依次校验有效期、签名者、目标合约是否信任转发合约，最后由转发合约的verify确认nonce未被使用
+func (w *BaseETHWallet) VerifyForwardRequest(ctx context.Context, forwarder string, req *wallet.ForwardRequest, signature []byte) (string, error) {
+	if !common.IsHexAddress(forwarder) || !common.IsHexAddress(req.From) || !common.IsHexAddress(req.To) {
+		return "", errors.New("invalid address format")
+	}
+	if req.Nonce == nil {
+		return "", fmt.Errorf("%w: forward request nonce is required", wallet.ErrInvalidTransaction)
+	}
+	if req.Deadline <= uint64(time.Now().Unix()) {
+		return "", fmt.Errorf("%w: forward request expired", wallet.ErrInvalidTransaction)
+	}
+	forwarderAddress := common.HexToAddress(forwarder)
+	from := common.HexToAddress(req.From)
+	to := common.HexToAddress(req.To)
+
+	typedData, err := w.forwardRequestTypedData(ctx, forwarderAddress, req)
+	if err != nil {
+		return "", err
+	}
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash forward request: %v", err)
+	}
+	// ERC2771Forwarder只接受ECDSA签名，不支持合约账户
+	signer, err := recoverSigner(hash, signature)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", wallet.ErrInvalidTransaction, err)
+	}
+	if signer != from {
+		return "", fmt.Errorf("%w: forward request signed by %s, not %s", wallet.ErrInvalidTransaction, signer.Hex(), from.Hex())
+	}
+
+	result, err := w.callContract(ctx, to, erc2771RecipientABI, "isTrustedForwarder", forwarderAddress)
+	if err != nil {
+		return "", fmt.Errorf("%w: target %s does not support ERC-2771: %v", wallet.ErrInvalidTransaction, to.Hex(), err)
+	}
+	if trusted, ok := result[0].(bool); !ok || !trusted {
+		return "", fmt.Errorf("%w: target %s does not trust forwarder %s", wallet.ErrInvalidTransaction, to.Hex(), forwarderAddress.Hex())
+	}
+
+	request, err := toForwardRequestData(req, signature)
+	if err != nil {
+		return "", err
+	}
+	result, err = w.callContract(ctx, forwarderAddress, erc2771ForwarderABI, "verify", request)
+	if err != nil {
+		return "", fmt.Errorf("failed to verify forward request: %v", err)
+	}
+	if valid, ok := result[0].(bool); !ok || !valid {
+		return "", fmt.Errorf("%w: forward request rejected by forwarder, nonce %s may already be used", wallet.ErrInvalidTransaction, req.Nonce.String())
+	}
+
+	return hexutil.Encode(hash), nil
+}
+
+// BuildForwardExecution 构建relayer调用execute的交易，交易的value等于请求的value
+func (w *BaseETHWallet) BuildForwardExecution(ctx context.Context, relayer string, forwarder string, req *wallet.ForwardRequest, signature []byte) (*wallet.UnsignedTx, error) {
+	if !common.IsHexAddress(forwarder) {
+		return nil, errors.New("invalid address format")
+	}
+	request, err := toForwardRequestData(req, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(erc2771ForwarderABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %v", err)
+	}
+	callData, err := parsed.Pack("execute", request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode execute: %v", err)
+	}
+
+	return w.CreateTransaction(ctx, relayer, common.HexToAddress(forwarder).Hex(), request.Value, callData)
+}
+
+// GetTransactionFee 按收据的gasUsed和effectiveGasPrice计算实际手续费
+func (w *BaseETHWallet) GetTransactionFee(ctx context.Context, txHash string) (*big.Int, error) {
+	client, err := w.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	receipt, err := client.TransactionReceipt(ctx, common.HexToHash(txHash))
+	if err != nil {
+		if errors.Is(err, eth.NotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get transaction receipt: %v", err)
+	}
+	if receipt.EffectiveGasPrice == nil {
+		return nil, nil
+	}
+
+	return new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice), nil
+}
+
+// estimateForwardedGas 以转发合约为调用方、calldata末尾附加from估算目标调用的gas，并留出20%余量
+func (w *BaseETHWallet) estimateForwardedGas(ctx context.Context, forwarder common.Address, req *wallet.ForwardRequest, data []byte) (uint64, error) {
+	client, err := w.getClient()
+	if err != nil {
+		return 0, err
+	}
+
+	to := common.HexToAddress(req.To)
+	forwardedData := append(append([]byte{}, data...), common.HexToAddress(req.From).Bytes()...)
+	gas, err := client.EstimateGas(ctx, eth.CallMsg{
+		From:  forwarder,
+		To:    &to,
+		Value: req.Value,
+		Data:  forwardedData,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate gas: %v", err)
+	}
+
+	return gas * 6 / 5, nil
+}
+
+// forwardRequestTypedData ForwardRequest的EIP-712签名数据
+func (w *BaseETHWallet) forwardRequestTypedData(ctx context.Context, forwarder common.Address, req *wallet.ForwardRequest) (apitypes.TypedData, error) {
+	result, err := w.callContract(ctx, forwarder, erc2771ForwarderABI, "eip712Domain")
+	if err != nil {
+		return apitypes.TypedData{}, fmt.Errorf("%w: failed to read forwarder domain: %v", wallet.ErrOperationNotSupported, err)
+	}
+	name, ok1 := result[1].(string)
+	version, ok2 := result[2].(string)
+	if !ok1 || !ok2 {
+		return apitypes.TypedData{}, errors.New("failed to decode forwarder domain")
+	}
+	data, err := forwardRequestCallData(req)
+	if err != nil {
+		return apitypes.TypedData{}, err
+	}
+
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": eip712DomainType(true),
+			"ForwardRequest": []apitypes.Type{
+				{Name: "from", Type: "address"},
+				{Name: "to", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "gas", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint48"},
+				{Name: "data", Type: "bytes"},
+			},
+		},
+		PrimaryType: "ForwardRequest",
+		Domain: apitypes.TypedDataDomain{
+			Name:              name,
+			Version:           version,
+			ChainId:           (*math.HexOrDecimal256)(new(big.Int).Set(w.chainID)),
+			VerifyingContract: forwarder.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"from":     common.HexToAddress(req.From).Hex(),
+			"to":       common.HexToAddress(req.To).Hex(),
+			"value":    bigOrZero(req.Value).String(),
+			"gas":      fmt.Sprintf("%d", req.Gas),
+			"nonce":    bigOrZero(req.Nonce).String(),
+			"deadline": fmt.Sprintf("%d", req.Deadline),
+			"data":     hexutil.Encode(data),
+		},
+	}, nil
+}
+
+func toForwardRequestData(req *wallet.ForwardRequest, signature []byte) (*forwardRequestData, error) {
+	if !common.IsHexAddress(req.From) || !common.IsHexAddress(req.To) {
+		return nil, errors.New("invalid address format")
+	}
+	data, err := forwardRequestCallData(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &forwardRequestData{
+		From:      common.HexToAddress(req.From),
+		To:        common.HexToAddress(req.To),
+		Value:     bigOrZero(req.Value),
+		Gas:       new(big.Int).SetUint64(req.Gas),
+		Deadline:  new(big.Int).SetUint64(req.Deadline),
+		Data:      data,
+		Signature: signature,
+	}, nil
+}
+
+func forwardRequestCallData(req *wallet.ForwardRequest) ([]byte, error) {
+	if req.Data == "" || req.Data == "0x" {
+		return []byte{}, nil
+	}
+	data, err := hexutil.Decode(req.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid forward request data: %v", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,386 @@
+package ethereum
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ERC-7769定义的bundler错误码
+const (
+	bundlerErrInvalidParams    = -32602
+	bundlerErrValidation       = -32500 // EntryPoint模拟验证失败
+	bundlerErrInvalidSignature = -32507
+)
+
+// 本地bundler的gas估算值：未部署账户无法模拟调用，使用保守的固定值
+const (
+	localVerificationGas       = 150000
+	localDeploymentGas         = 350000
+	localCallGas               = 200000
+	localPaymasterVerification = 100000
+	localPaymasterPostOp       = 50000
+	localBundleOverheadGas     = 50000
+)
+
+// LocalBundler 进程内的最小ERC-4337 bundler，仅用于开发和测试：每个UserOperation单独打包为一笔handleOps交易，
+// 由bundler自己的账户支付gas并作为受益人收回费用。要求链上已部署EntryPoint v0.7和SimpleAccountFactory（如anvil分叉网络）
+type LocalBundler struct {
+	client   ChainClient
+	chainID  *big.Int
+	key      *ecdsa.PrivateKey
+	server   *rpc.Server
+	mu       sync.Mutex
+	included map[common.Hash]common.Hash // userOpHash -> handleOps交易哈希
+}
+
+// NewLocalBundler 创建本地bundler，key为支付handleOps交易gas的账户
+func NewLocalBundler(client ChainClient, chainID *big.Int, key *ecdsa.PrivateKey) (*LocalBundler, error) {
+	b := &LocalBundler{
+		client:   client,
+		chainID:  chainID,
+		key:      key,
+		server:   rpc.NewServer(),
+		included: make(map[common.Hash]common.Hash),
+	}
+	if err := b.server.RegisterName("eth", &localBundlerAPI{bundler: b}); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// ServeHTTP 处理JSON-RPC请求
+func (b *LocalBundler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.server.ServeHTTP(w, r)
+}
+
+// Close 停止服务
+func (b *LocalBundler) Close() {
+	b.server.Stop()
+}
+
+// Address bundler账户地址
+func (b *LocalBundler) Address() common.Address {
+	return crypto.PubkeyToAddress(b.key.PublicKey)
+}
+
+// bundlerRPCError 带ERC-7769错误码的JSON-RPC错误
+type bundlerRPCError struct {
+	code    int
+	message string
+}
+
+func (e *bundlerRPCError) Error() string  { return e.message }
+func (e *bundlerRPCError) ErrorCode() int { return e.code }
+
+func newBundlerRPCError(code int, format string, args ...interface{}) error {
+	return &bundlerRPCError{code: code, message: fmt.Sprintf(format, args...)}
+}
+
+// localBundlerAPI eth命名空间的bundler方法
+type localBundlerAPI struct {
+	bundler *LocalBundler
+}
+
+// ChainId 对应eth_chainId
+func (api *localBundlerAPI) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(api.bundler.chainID)
+}
+
+// SupportedEntryPoints 对应eth_supportedEntryPoints
+func (api *localBundlerAPI) SupportedEntryPoints() []common.Address {
+	return []common.Address{common.HexToAddress(EntryPointAddress)}
+}
+
+// EstimateUserOperationGas 对应eth_estimateUserOperationGas。已部署账户的callGasLimit通过以EntryPoint身份模拟调用得到
+func (api *localBundlerAPI) EstimateUserOperationGas(ctx context.Context, op rpcUserOperation, entryPoint common.Address) (*rpcGasEstimate, error) {
+	if entryPoint != common.HexToAddress(EntryPointAddress) {
+		return nil, newBundlerRPCError(bundlerErrInvalidParams, "unsupported entry point %s", entryPoint.Hex())
+	}
+	b := api.bundler
+
+	estimate := &rpcGasEstimate{
+		VerificationGasLimit: (*hexutil.Big)(big.NewInt(localVerificationGas)),
+		CallGasLimit:         (*hexutil.Big)(big.NewInt(localCallGas)),
+	}
+	if op.Factory != nil {
+		estimate.VerificationGasLimit = (*hexutil.Big)(big.NewInt(localVerificationGas + localDeploymentGas))
+	} else {
+		callGas, err := b.client.EstimateGas(ctx, eth.CallMsg{From: entryPoint, To: &op.Sender, Data: op.CallData})
+		if err != nil {
+			return nil, newBundlerRPCError(bundlerErrValidation, "call simulation failed: %v", err)
+		}
+		estimate.CallGasLimit = (*hexutil.Big)(new(big.Int).SetUint64(callGas + callGas/5))
+	}
+	if op.Paymaster != nil {
+		estimate.PaymasterVerificationGasLimit = (*hexutil.Big)(big.NewInt(localPaymasterVerification))
+		estimate.PaymasterPostOpGasLimit = (*hexutil.Big)(big.NewInt(localPaymasterPostOp))
+	}
+
+	// preVerificationGas覆盖handleOps交易中该UserOperation的calldata费用和打包开销
+	packed, err := op.pack()
+	if err != nil {
+		return nil, newBundlerRPCError(bundlerErrInvalidParams, "%v", err)
+	}
+	calldata, err := b.handleOpsData(packed)
+	if err != nil {
+		return nil, err
+	}
+	preVerificationGas := uint64(localBundleOverheadGas)
+	for _, c := range calldata {
+		if c == 0 {
+			preVerificationGas += 4
+		} else {
+			preVerificationGas += 16
+		}
+	}
+	estimate.PreVerificationGas = (*hexutil.Big)(new(big.Int).SetUint64(preVerificationGas))
+
+	return estimate, nil
+}
+
+// SendUserOperation 对应eth_sendUserOperation：校验签名和nonce后立即提交handleOps交易
+func (api *localBundlerAPI) SendUserOperation(ctx context.Context, op rpcUserOperation, entryPoint common.Address) (common.Hash, error) {
+	if entryPoint != common.HexToAddress(EntryPointAddress) {
+		return common.Hash{}, newBundlerRPCError(bundlerErrInvalidParams, "unsupported entry point %s", entryPoint.Hex())
+	}
+	b := api.bundler
+
+	packed, err := op.pack()
+	if err != nil {
+		return common.Hash{}, newBundlerRPCError(bundlerErrInvalidParams, "%v", err)
+	}
+	hash := userOperationHash(packed, entryPoint, b.chainID)
+	if err := b.validate(ctx, &op, hash); err != nil {
+		return common.Hash{}, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.included[hash]; ok {
+		return common.Hash{}, newBundlerRPCError(bundlerErrInvalidParams, "user operation %s already submitted", hash.Hex())
+	}
+	txHash, err := b.submit(ctx, packed)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	b.included[hash] = txHash
+
+	return hash, nil
+}
+
+// GetUserOperationReceipt 对应eth_getUserOperationReceipt，从handleOps交易的UserOperationEvent解析结果
+func (api *localBundlerAPI) GetUserOperationReceipt(ctx context.Context, hash common.Hash) (*rpcUserOperationReceipt, error) {
+	b := api.bundler
+
+	b.mu.Lock()
+	txHash, ok := b.included[hash]
+	b.mu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+
+	receipt, err := b.client.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		if errors.Is(err, eth.NotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	entryPointABI, err := abi.JSON(strings.NewReader(entryPointABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %v", err)
+	}
+	opEvent := entryPointABI.Events["UserOperationEvent"]
+	revertEvent := entryPointABI.Events["UserOperationRevertReason"]
+
+	var result *rpcUserOperationReceipt
+	var reason []byte
+	for _, log := range receipt.Logs {
+		if log.Address != common.HexToAddress(EntryPointAddress) || len(log.Topics) < 3 || log.Topics[1] != hash {
+			continue
+		}
+		switch log.Topics[0] {
+		case opEvent.ID:
+			values, err := opEvent.Inputs.NonIndexed().Unpack(log.Data)
+			if err != nil || len(values) != 4 || len(log.Topics) != 4 {
+				return nil, fmt.Errorf("failed to decode UserOperationEvent: %v", err)
+			}
+			result = &rpcUserOperationReceipt{
+				UserOpHash:    hash,
+				EntryPoint:    log.Address,
+				Sender:        common.BytesToAddress(log.Topics[2].Bytes()),
+				Paymaster:     common.BytesToAddress(log.Topics[3].Bytes()),
+				Nonce:         (*hexutil.Big)(values[0].(*big.Int)),
+				Success:       values[1].(bool),
+				ActualGasCost: (*hexutil.Big)(values[2].(*big.Int)),
+				ActualGasUsed: (*hexutil.Big)(values[3].(*big.Int)),
+				Receipt: rpcReceiptRef{
+					TransactionHash: receipt.TxHash,
+					BlockNumber:     hexutil.Uint64(receipt.BlockNumber.Uint64()),
+				},
+			}
+		case revertEvent.ID:
+			values, err := revertEvent.Inputs.NonIndexed().Unpack(log.Data)
+			if err == nil && len(values) == 2 {
+				reason, _ = values[1].([]byte)
+			}
+		}
+	}
+	if result == nil {
+		return nil, fmt.Errorf("user operation %s not found in transaction %s", hash.Hex(), txHash.Hex())
+	}
+	result.Reason = reason
+	return result, nil
+}
+
+// validate 校验nonce和所有者签名。所有者取自已部署账户的owner()，未部署时从createAccount参数中解析
+func (b *LocalBundler) validate(ctx context.Context, op *rpcUserOperation, hash common.Hash) error {
+	nonce := op.Nonce.ToInt()
+	if nonce == nil {
+		return newBundlerRPCError(bundlerErrInvalidParams, "missing nonce")
+	}
+	key := new(big.Int).Rsh(nonce, 64)
+	expected, err := entryPointNonce(ctx, b.client, op.Sender, key)
+	if err != nil {
+		return err
+	}
+	if expected.Cmp(nonce) != 0 {
+		return newBundlerRPCError(bundlerErrValidation, "invalid nonce %s, expected %s", nonce, expected)
+	}
+
+	owner, err := b.accountOwner(ctx, op)
+	if err != nil {
+		return err
+	}
+	if len(op.Signature) != crypto.SignatureLength {
+		return newBundlerRPCError(bundlerErrInvalidSignature, "invalid signature length")
+	}
+	signer, err := recoverSigner(accounts.TextHash(hash.Bytes()), op.Signature)
+	if err != nil || signer != owner {
+		return newBundlerRPCError(bundlerErrInvalidSignature, "signature does not match account owner %s", owner.Hex())
+	}
+	return nil
+}
+
+// accountOwner 获取SimpleAccount的所有者
+func (b *LocalBundler) accountOwner(ctx context.Context, op *rpcUserOperation) (common.Address, error) {
+	code, err := b.client.CodeAt(ctx, op.Sender, nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	if op.Factory == nil {
+		if len(code) == 0 {
+			return common.Address{}, newBundlerRPCError(bundlerErrValidation, "account %s is not deployed and no factory given", op.Sender.Hex())
+		}
+		result, err := callContract(ctx, b.client, op.Sender, simpleAccountABI, "owner")
+		if err != nil {
+			return common.Address{}, newBundlerRPCError(bundlerErrValidation, "failed to get account owner: %v", err)
+		}
+		owner, _ := result[0].(common.Address)
+		return owner, nil
+	}
+
+	if len(code) > 0 {
+		return common.Address{}, newBundlerRPCError(bundlerErrValidation, "account %s is already deployed", op.Sender.Hex())
+	}
+	if *op.Factory != common.HexToAddress(SimpleAccountFactoryAddress) {
+		return common.Address{}, newBundlerRPCError(bundlerErrValidation, "unsupported factory %s", op.Factory.Hex())
+	}
+	owner, salt, err := decodeCreateAccount(op.FactoryData)
+	if err != nil {
+		return common.Address{}, newBundlerRPCError(bundlerErrInvalidParams, "%v", err)
+	}
+
+	account, err := getSmartAccount(ctx, b.client, owner, salt)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if common.HexToAddress(account.Address) != op.Sender {
+		return common.Address{}, newBundlerRPCError(bundlerErrValidation, "sender does not match factory address %s", account.Address)
+	}
+	return owner, nil
+}
+
+// handleOpsData 编码handleOps([op], beneficiary)
+func (b *LocalBundler) handleOpsData(op *packedUserOperation) ([]byte, error) {
+	parsed, err := abi.JSON(strings.NewReader(entryPointABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %v", err)
+	}
+	data, err := parsed.Pack("handleOps", []packedUserOperation{*op}, b.Address())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode handleOps: %v", err)
+	}
+	return data, nil
+}
+
+// submit 由bundler账户发送handleOps交易，模拟执行失败（EntryPoint的FailedOp）时拒绝该UserOperation
+func (b *LocalBundler) submit(ctx context.Context, op *packedUserOperation) (common.Hash, error) {
+	data, err := b.handleOpsData(op)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	from := b.Address()
+	entryPoint := common.HexToAddress(EntryPointAddress)
+
+	gas, err := b.client.EstimateGas(ctx, eth.CallMsg{From: from, To: &entryPoint, Data: data})
+	if err != nil {
+		return common.Hash{}, newBundlerRPCError(bundlerErrValidation, "handleOps simulation failed: %v", err)
+	}
+	nonce, err := b.client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get nonce: %v", err)
+	}
+	header, err := b.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get latest header: %v", err)
+	}
+
+	var tx *types.Transaction
+	if header.BaseFee != nil {
+		tipCap, err := b.client.SuggestGasTipCap(ctx)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("failed to get gas tip cap: %v", err)
+		}
+		tx = types.NewTx(&types.DynamicFeeTx{
+			ChainID:   b.chainID,
+			Nonce:     nonce,
+			GasTipCap: tipCap,
+			GasFeeCap: new(big.Int).Add(new(big.Int).Mul(header.BaseFee, big.NewInt(2)), tipCap),
+			Gas:       gas + gas/5,
+			To:        &entryPoint,
+			Data:      data,
+		})
+	} else {
+		gasPrice, err := b.client.SuggestGasPrice(ctx)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("failed to get gas price: %v", err)
+		}
+		tx = types.NewTransaction(nonce, entryPoint, new(big.Int), gas+gas/5, gasPrice, data)
+	}
+
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(b.chainID), b.key)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to sign transaction: %v", err)
+	}
+	if err := b.client.SendTransaction(ctx, signedTx); err != nil {
+		return common.Hash{}, fmt.Errorf("failed to send transaction: %v", err)
+	}
+	return signedTx.Hash(), nil
+}
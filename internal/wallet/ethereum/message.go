@@ -0,0 +1,217 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// SignMessage 按EIP-191（personal_sign）规则签名任意消息
+func (w *BaseETHWallet) SignMessage(ctx context.Context, walletID string, message []byte) ([]byte, error) {
+	signer, err := w.getSigner(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := signer.SignText(ctx, message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign message: %w", err)
+	}
+
+	return signature, nil
+}
+
+// SignTypedData 按EIP-712 v4规则签名结构化数据，typedData为eth_signTypedData_v4格式的JSON
+func (w *BaseETHWallet) SignTypedData(ctx context.Context, walletID string, typedDataJSON []byte) ([]byte, error) {
+	signer, err := w.getSigner(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal(typedDataJSON, &typedData); err != nil {
+		return nil, fmt.Errorf("failed to parse typed data: %v", err)
+	}
+
+	// 防止把其他链的签名请求签到当前链的钱包上
+	if typedData.Domain.ChainId != nil {
+		domainChainID := (*big.Int)(typedData.Domain.ChainId)
+		if domainChainID.Cmp(w.chainID) != 0 {
+			return nil, fmt.Errorf("%w: domain chainId %s, wallet chainId %s",
+				wallet.ErrChainIDMismatch, domainChainID.String(), w.chainID.String())
+		}
+	}
+
+	signature, err := signer.SignTypedData(ctx, typedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign typed data: %w", err)
+	}
+
+	return signature, nil
+}
+
+// ERC-1271 isValidSignature(bytes32,bytes) 的ABI和合法返回值
+const erc1271ABI = `[{"inputs":[{"name":"hash","type":"bytes32"},{"name":"signature","type":"bytes"}],"name":"isValidSignature","outputs":[{"name":"magicValue","type":"bytes4"}],"stateMutability":"view","type":"function"}]`
+
+var erc1271MagicValue = [4]byte{0x16, 0x26, 0xba, 0x7e}
+
+// VerifyMessage 校验EIP-191消息签名
+func (w *BaseETHWallet) VerifyMessage(ctx context.Context, expectedSigner string, message []byte, signature []byte) (*wallet.SignatureVerification, error) {
+	return w.verifyHash(ctx, expectedSigner, accounts.TextHash(message), signature)
+}
+
+// VerifyTypedData 校验EIP-712结构化数据签名
+func (w *BaseETHWallet) VerifyTypedData(ctx context.Context, expectedSigner string, typedDataJSON []byte, signature []byte) (*wallet.SignatureVerification, error) {
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal(typedDataJSON, &typedData); err != nil {
+		return nil, fmt.Errorf("failed to parse typed data: %v", err)
+	}
+
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash typed data: %v", err)
+	}
+
+	return w.verifyHash(ctx, expectedSigner, hash, signature)
+}
+
+// verifyHash 先通过ecrecover恢复签名地址，与期望签名者不一致且期望签名者为合约时再走ERC-1271校验
+func (w *BaseETHWallet) verifyHash(ctx context.Context, expectedSigner string, hash []byte, signature []byte) (*wallet.SignatureVerification, error) {
+	if expectedSigner != "" && !common.IsHexAddress(expectedSigner) {
+		return nil, errors.New("invalid address format")
+	}
+
+	result := &wallet.SignatureVerification{
+		ExpectedSigner: expectedSigner,
+		Method:         wallet.VerifyMethodECRecover,
+	}
+
+	if recovered, err := recoverSigner(hash, signature); err == nil {
+		result.RecoveredAddress = recovered.Hex()
+		if expectedSigner != "" && recovered == common.HexToAddress(expectedSigner) {
+			result.Valid = true
+			return result, nil
+		}
+	} else if expectedSigner == "" {
+		// 没有期望签名者时无法走合约钱包校验，直接返回恢复失败原因
+		return nil, err
+	}
+
+	if expectedSigner == "" {
+		return result, nil
+	}
+
+	client, err := w.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// 期望签名者有合约代码时，按ERC-1271询问合约
+	signer := common.HexToAddress(expectedSigner)
+	code, err := client.CodeAt(ctx, signer, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get code: %v", wallet.ErrChainUnavailable, err)
+	}
+	if len(code) == 0 {
+		return result, nil
+	}
+
+	valid, err := w.isValidSignatureERC1271(ctx, signer, hash, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	result.Method = wallet.VerifyMethodERC1271
+	result.Valid = valid
+	return result, nil
+}
+
+// isValidSignatureERC1271 调用合约钱包的isValidSignature方法
+func (w *BaseETHWallet) isValidSignatureERC1271(ctx context.Context, contract common.Address, hash []byte, signature []byte) (bool, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(erc1271ABI))
+	if err != nil {
+		return false, fmt.Errorf("failed to parse ABI: %v", err)
+	}
+
+	var digest [32]byte
+	copy(digest[:], hash)
+
+	callData, err := parsedABI.Pack("isValidSignature", digest, signature)
+	if err != nil {
+		return false, fmt.Errorf("failed to pack isValidSignature call: %v", err)
+	}
+
+	client, err := w.getClient()
+	if err != nil {
+		return false, err
+	}
+
+	output, err := client.CallContract(ctx, eth.CallMsg{To: &contract, Data: callData}, nil)
+	if err != nil {
+		// 合约revert视为签名无效，节点不可达等其他错误不能当作签名无效
+		if isExecutionReverted(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("%w: isValidSignature call failed: %v", wallet.ErrChainUnavailable, err)
+	}
+
+	// 没有返回数据或返回数据无法解析（合约未实现isValidSignature）视为签名无效
+	values, err := parsedABI.Unpack("isValidSignature", output)
+	if err != nil || len(values) == 0 {
+		return false, nil
+	}
+
+	magic, ok := values[0].([4]byte)
+	if !ok {
+		return false, nil
+	}
+
+	return magic == erc1271MagicValue, nil
+}
+
+// isExecutionReverted 判断eth_call的错误是否为合约执行失败：节点返回的JSON-RPC错误中，
+// 错误码3（带revert数据）或错误信息表明执行被回滚的属于执行失败，其余为网络或节点错误
+func isExecutionReverted(err error) bool {
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) {
+		return false
+	}
+	if rpcErr.ErrorCode() == 3 {
+		return true
+	}
+	message := strings.ToLower(rpcErr.Error())
+	return strings.Contains(message, "revert") || strings.Contains(message, "invalid opcode")
+}
+
+// recoverSigner 从65字节签名中恢复签名地址，兼容v为0/1和27/28两种形式
+func recoverSigner(hash []byte, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("invalid signature length: %d", len(signature))
+	}
+
+	sig := make([]byte, len(signature))
+	copy(sig, signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	publicKey, err := crypto.SigToPub(hash, sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover public key: %v", err)
+	}
+
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
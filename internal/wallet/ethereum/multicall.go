@@ -0,0 +1,265 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// Multicall3Address Multicall3合约地址，各EVM链相同
+const Multicall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+// Multicall3 aggregate3 和 getEthBalance 的ABI
+const multicall3ABI = `[
+	{"inputs":[{"components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}],"name":"calls","type":"tuple[]"}],"name":"aggregate3","outputs":[{"components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}],"name":"returnData","type":"tuple[]"}],"stateMutability":"payable","type":"function"},
+	{"inputs":[{"name":"addr","type":"address"}],"name":"getEthBalance","outputs":[{"name":"balance","type":"uint256"}],"stateMutability":"view","type":"function"}
+]`
+
+// 单次multicall包含的最大调用数，避免超出节点的gas和响应大小限制
+const multicallBatchSize = 500
+
+// multicall3Call aggregate3的单个调用
+type multicall3Call struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// multicall3Result aggregate3的单个结果
+type multicall3Result struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// balanceCall 一次余额查询，token为空表示原生代币
+type balanceCall struct {
+	address common.Address
+	token   string
+}
+
+// GetBalances 一次性查询多个地址的原生代币和多个代币余额，优先使用Multicall3，失败时回退到JSON-RPC批量请求
+func (w *BaseETHWallet) GetBalances(ctx context.Context, addresses []string, tokenAddresses []string) ([]*wallet.AddressBalances, error) {
+	for _, address := range append(append([]string{}, addresses...), tokenAddresses...) {
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("invalid address format: %s", address)
+		}
+	}
+
+	calls := make([]balanceCall, 0, len(addresses)*(len(tokenAddresses)+1))
+	for _, address := range addresses {
+		calls = append(calls, balanceCall{address: common.HexToAddress(address)})
+		for _, token := range tokenAddresses {
+			calls = append(calls, balanceCall{address: common.HexToAddress(address), token: common.HexToAddress(token).Hex()})
+		}
+	}
+
+	results, err := w.multicallBalances(ctx, calls)
+	if err != nil {
+		fmt.Printf("BaseETHWallet: multicall failed on %s, falling back to JSON-RPC batch: %v\n", w.chainType, err)
+		results, err = w.batchRPCBalances(ctx, calls)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 按地址归并结果
+	balances := make([]*wallet.AddressBalances, 0, len(addresses))
+	index := make(map[common.Address]*wallet.AddressBalances)
+	for _, address := range addresses {
+		addr := common.HexToAddress(address)
+		if _, exists := index[addr]; exists {
+			continue
+		}
+		entry := &wallet.AddressBalances{
+			Address: addr.Hex(),
+			Tokens:  make(map[string]*big.Int),
+		}
+		index[addr] = entry
+		balances = append(balances, entry)
+	}
+
+	for i, call := range calls {
+		entry := index[call.address]
+		if call.token == "" {
+			entry.Native = results[i]
+		} else {
+			entry.Tokens[call.token] = results[i]
+		}
+	}
+
+	return balances, nil
+}
+
+// multicallBalances 通过Multicall3 aggregate3批量查询，单个调用失败时对应结果为nil
+func (w *BaseETHWallet) multicallBalances(ctx context.Context, calls []balanceCall) ([]*big.Int, error) {
+	multicallABI, err := abi.JSON(strings.NewReader(multicall3ABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %v", err)
+	}
+	tokenABI, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %v", err)
+	}
+
+	client, err := w.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	multicall := common.HexToAddress(Multicall3Address)
+	results := make([]*big.Int, 0, len(calls))
+
+	for start := 0; start < len(calls); start += multicallBatchSize {
+		end := start + multicallBatchSize
+		if end > len(calls) {
+			end = len(calls)
+		}
+
+		batch := make([]multicall3Call, 0, end-start)
+		for _, call := range calls[start:end] {
+			var callData []byte
+			target := multicall
+			if call.token == "" {
+				callData, err = multicallABI.Pack("getEthBalance", call.address)
+			} else {
+				target = common.HexToAddress(call.token)
+				callData, err = tokenABI.Pack("balanceOf", call.address)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to pack call: %v", err)
+			}
+			batch = append(batch, multicall3Call{Target: target, AllowFailure: true, CallData: callData})
+		}
+
+		input, err := multicallABI.Pack("aggregate3", batch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack aggregate3: %v", err)
+		}
+
+		output, err := client.CallContract(ctx, eth.CallMsg{To: &multicall, Data: input}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to call multicall: %v", err)
+		}
+		if len(output) == 0 {
+			return nil, errors.New("multicall3 not deployed")
+		}
+
+		var decoded []multicall3Result
+		if err := multicallABI.UnpackIntoInterface(&decoded, "aggregate3", output); err != nil {
+			return nil, fmt.Errorf("failed to unpack aggregate3: %v", err)
+		}
+		if len(decoded) != len(batch) {
+			return nil, errors.New("unexpected multicall result length")
+		}
+
+		for _, result := range decoded {
+			if !result.Success || len(result.ReturnData) < 32 {
+				results = append(results, nil)
+				continue
+			}
+			results = append(results, new(big.Int).SetBytes(result.ReturnData[:32]))
+		}
+	}
+
+	return results, nil
+}
+
+// batchRPCBalances 通过JSON-RPC批量请求查询，单个请求失败时对应结果为nil；
+// 客户端不支持批量请求时（如模拟链）逐个查询
+func (w *BaseETHWallet) batchRPCBalances(ctx context.Context, calls []balanceCall) ([]*big.Int, error) {
+	tokenABI, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %v", err)
+	}
+
+	client, err := w.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	provider, ok := client.(rpcClientProvider)
+	if !ok {
+		return sequentialBalances(ctx, client, tokenABI, calls)
+	}
+
+	// eth_getBalance返回数值，eth_call返回字节，分开接收
+	nativeResults := make([]hexutil.Big, len(calls))
+	callResults := make([]hexutil.Bytes, len(calls))
+	elems := make([]rpc.BatchElem, len(calls))
+	for i, call := range calls {
+		if call.token == "" {
+			elems[i] = rpc.BatchElem{
+				Method: "eth_getBalance",
+				Args:   []interface{}{call.address, "latest"},
+				Result: &nativeResults[i],
+			}
+			continue
+		}
+
+		callData, err := tokenABI.Pack("balanceOf", call.address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack call: %v", err)
+		}
+		elems[i] = rpc.BatchElem{
+			Method: "eth_call",
+			Args: []interface{}{map[string]interface{}{
+				"to":   call.token,
+				"data": hexutil.Bytes(callData),
+			}, "latest"},
+			Result: &callResults[i],
+		}
+	}
+
+	if err := provider.Client().BatchCallContext(ctx, elems); err != nil {
+		return nil, fmt.Errorf("failed to send batch request: %v", err)
+	}
+
+	results := make([]*big.Int, len(calls))
+	for i, elem := range elems {
+		if elem.Error != nil {
+			continue
+		}
+		if calls[i].token == "" {
+			results[i] = nativeResults[i].ToInt()
+		} else if len(callResults[i]) >= 32 {
+			results[i] = new(big.Int).SetBytes(callResults[i][:32])
+		}
+	}
+
+	return results, nil
+}
+
+// sequentialBalances 逐个查询余额，单个查询失败时对应结果为nil
+func sequentialBalances(ctx context.Context, client ChainClient, tokenABI abi.ABI, calls []balanceCall) ([]*big.Int, error) {
+	results := make([]*big.Int, len(calls))
+	for i, call := range calls {
+		if call.token == "" {
+			if balance, err := client.BalanceAt(ctx, call.address, nil); err == nil {
+				results[i] = balance
+			}
+			continue
+		}
+
+		callData, err := tokenABI.Pack("balanceOf", call.address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack call: %v", err)
+		}
+		token := common.HexToAddress(call.token)
+		output, err := client.CallContract(ctx, eth.CallMsg{To: &token, Data: callData}, nil)
+		if err == nil && len(output) >= 32 {
+			results[i] = new(big.Int).SetBytes(output[:32])
+		}
+	}
+
+	return results, nil
+}
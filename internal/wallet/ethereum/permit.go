@@ -0,0 +1,294 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// Permit2Address Uniswap Permit2合约地址，各EVM链相同
+const Permit2Address = "0x000000000022D473030F116dDEE9F6B43aC78BA3"
+
+// EIP-2612相关的ERC20方法
+const erc20PermitABI = `[
+	{"inputs":[],"name":"DOMAIN_SEPARATOR","outputs":[{"name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"owner","type":"address"}],"name":"nonces","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"name","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"version","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"owner","type":"address"},{"name":"spender","type":"address"}],"name":"allowance","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}
+]`
+
+// Permit2 allowance(owner, token, spender) 返回 (amount, expiration, nonce)
+const permit2ABI = `[{"inputs":[{"name":"owner","type":"address"},{"name":"token","type":"address"},{"name":"spender","type":"address"}],"name":"allowance","outputs":[{"name":"amount","type":"uint160"},{"name":"expiration","type":"uint48"},{"name":"nonce","type":"uint48"}],"stateMutability":"view","type":"function"}]`
+
+// 未实现version()的代币常见的版本号
+var permitVersionCandidates = []string{"1", "2"}
+
+// callContract 调用合约只读方法
+func (w *BaseETHWallet) callContract(ctx context.Context, contract common.Address, abiJSON string, method string, args ...interface{}) ([]interface{}, error) {
+	client, err := w.getClient()
+	if err != nil {
+		return nil, err
+	}
+	return callContract(ctx, client, contract, abiJSON, method, args...)
+}
+
+// callContract 通过指定的链客户端调用合约只读方法
+func callContract(ctx context.Context, client ChainClient, contract common.Address, abiJSON string, method string, args ...interface{}) ([]interface{}, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %v", err)
+	}
+
+	bound := bind.NewBoundContract(contract, parsedABI, client, client, client)
+
+	var result []interface{}
+	if err := bound.Call(&bind.CallOpts{Context: ctx}, &result, method, args...); err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("empty result from %s call", method)
+	}
+
+	return result, nil
+}
+
+// GetPermitSupport 检测代币是否支持EIP-2612，并查询代币对Permit2的授权额度
+func (w *BaseETHWallet) GetPermitSupport(ctx context.Context, tokenAddress string, owner string) (*wallet.PermitSupport, error) {
+	if !common.IsHexAddress(tokenAddress) || !common.IsHexAddress(owner) {
+		return nil, errors.New("invalid address format")
+	}
+
+	token := common.HexToAddress(tokenAddress)
+	ownerAddr := common.HexToAddress(owner)
+
+	support := &wallet.PermitSupport{
+		Token:            token.Hex(),
+		Permit2Allowance: "0",
+	}
+
+	// Permit2需要代币先对Permit2合约做过一次授权
+	if result, err := w.callContract(ctx, token, erc20PermitABI, "allowance", ownerAddr, common.HexToAddress(Permit2Address)); err == nil {
+		if allowance, ok := result[0].(*big.Int); ok {
+			support.Permit2Allowance = allowance.String()
+		}
+	}
+
+	name, version, nonce, err := w.detectEIP2612(ctx, token, ownerAddr)
+	if err != nil {
+		// 不支持EIP-2612不是错误，调用方可回退到Permit2或链上授权
+		return support, nil
+	}
+
+	support.EIP2612 = true
+	support.Name = name
+	support.Version = version
+	support.Nonce = nonce.String()
+
+	return support, nil
+}
+
+// detectEIP2612 通过nonces和DOMAIN_SEPARATOR判断代币是否支持EIP-2612，并确定域名称和版本
+func (w *BaseETHWallet) detectEIP2612(ctx context.Context, token common.Address, owner common.Address) (string, string, *big.Int, error) {
+	result, err := w.callContract(ctx, token, erc20PermitABI, "nonces", owner)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("nonces not supported: %v", err)
+	}
+	nonce, ok := result[0].(*big.Int)
+	if !ok {
+		return "", "", nil, errors.New("failed to convert nonce to big.Int")
+	}
+
+	result, err = w.callContract(ctx, token, erc20PermitABI, "DOMAIN_SEPARATOR")
+	if err != nil {
+		return "", "", nil, fmt.Errorf("DOMAIN_SEPARATOR not supported: %v", err)
+	}
+	onChainSeparator, ok := result[0].([32]byte)
+	if !ok {
+		return "", "", nil, errors.New("failed to convert DOMAIN_SEPARATOR")
+	}
+
+	result, err = w.callContract(ctx, token, erc20PermitABI, "name")
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to get token name: %v", err)
+	}
+	name, _ := result[0].(string)
+
+	versions := permitVersionCandidates
+	if result, err := w.callContract(ctx, token, erc20PermitABI, "version"); err == nil {
+		if version, ok := result[0].(string); ok {
+			versions = []string{version}
+		}
+	}
+
+	// 用本地计算的域分隔符与链上值比对，确认签名参数正确
+	for _, version := range versions {
+		typedData := apitypes.TypedData{
+			Types:  apitypes.Types{"EIP712Domain": eip712DomainType(true)},
+			Domain: w.permitDomain(name, version, token),
+		}
+		separator, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+		if err != nil {
+			continue
+		}
+		if common.BytesToHash(separator) == common.Hash(onChainSeparator) {
+			return name, version, nonce, nil
+		}
+	}
+
+	return "", "", nil, errors.New("domain separator does not match EIP-2612 domain")
+}
+
+// BuildPermitTypedData 构造EIP-2612 Permit的EIP-712数据
+func (w *BaseETHWallet) BuildPermitTypedData(ctx context.Context, tokenAddress string, owner string, spender string, value *big.Int, deadline *big.Int) ([]byte, error) {
+	if !common.IsHexAddress(tokenAddress) || !common.IsHexAddress(owner) || !common.IsHexAddress(spender) {
+		return nil, errors.New("invalid address format")
+	}
+
+	token := common.HexToAddress(tokenAddress)
+	name, version, nonce, err := w.detectEIP2612(ctx, token, common.HexToAddress(owner))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", wallet.ErrPermitNotSupported, err)
+	}
+
+	typedData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": eip712DomainType(true),
+			"Permit": {
+				{Name: "owner", Type: "address"},
+				{Name: "spender", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Permit",
+		Domain:      w.permitDomain(name, version, token),
+		Message: apitypes.TypedDataMessage{
+			"owner":    common.HexToAddress(owner).Hex(),
+			"spender":  common.HexToAddress(spender).Hex(),
+			"value":    value.String(),
+			"nonce":    nonce.String(),
+			"deadline": deadline.String(),
+		},
+	}
+
+	return json.Marshal(typedData)
+}
+
+// BuildPermit2TypedData 构造Permit2 PermitSingle（单个代币）或PermitBatch（多个代币）的EIP-712数据
+func (w *BaseETHWallet) BuildPermit2TypedData(ctx context.Context, owner string, spender string, details []wallet.Permit2Details, sigDeadline *big.Int) ([]byte, error) {
+	if !common.IsHexAddress(owner) || !common.IsHexAddress(spender) {
+		return nil, errors.New("invalid address format")
+	}
+	if len(details) == 0 {
+		return nil, errors.New("permit2 details are required")
+	}
+
+	ownerAddr := common.HexToAddress(owner)
+	spenderAddr := common.HexToAddress(spender)
+	permit2 := common.HexToAddress(Permit2Address)
+
+	messages := make([]interface{}, 0, len(details))
+	for _, detail := range details {
+		if !common.IsHexAddress(detail.Token) {
+			return nil, errors.New("invalid address format")
+		}
+		token := common.HexToAddress(detail.Token)
+
+		// Permit2的nonce按(owner, token, spender)维护
+		result, err := w.callContract(ctx, permit2, permit2ABI, "allowance", ownerAddr, token, spenderAddr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get permit2 nonce: %v", err)
+		}
+		if len(result) < 3 {
+			return nil, errors.New("unexpected permit2 allowance result")
+		}
+		nonce, ok := result[2].(*big.Int)
+		if !ok {
+			return nil, errors.New("failed to convert permit2 nonce")
+		}
+
+		messages = append(messages, map[string]interface{}{
+			"token":      token.Hex(),
+			"amount":     detail.Amount.String(),
+			"expiration": fmt.Sprintf("%d", detail.Expiration),
+			"nonce":      nonce.String(),
+		})
+	}
+
+	primaryType := "PermitSingle"
+	var detailsValue interface{} = messages[0]
+	if len(messages) > 1 {
+		primaryType = "PermitBatch"
+		detailsValue = messages
+	}
+
+	detailsType := "PermitDetails"
+	if primaryType == "PermitBatch" {
+		detailsType = "PermitDetails[]"
+	}
+
+	typedData := apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": eip712DomainType(false),
+			"PermitDetails": {
+				{Name: "token", Type: "address"},
+				{Name: "amount", Type: "uint160"},
+				{Name: "expiration", Type: "uint48"},
+				{Name: "nonce", Type: "uint48"},
+			},
+			primaryType: {
+				{Name: "details", Type: detailsType},
+				{Name: "spender", Type: "address"},
+				{Name: "sigDeadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: primaryType,
+		Domain: apitypes.TypedDataDomain{
+			Name:              "Permit2",
+			ChainId:           (*math.HexOrDecimal256)(new(big.Int).Set(w.chainID)),
+			VerifyingContract: permit2.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"details":     detailsValue,
+			"spender":     spenderAddr.Hex(),
+			"sigDeadline": sigDeadline.String(),
+		},
+	}
+
+	return json.Marshal(typedData)
+}
+
+// permitDomain EIP-2612代币的签名域
+func (w *BaseETHWallet) permitDomain(name string, version string, token common.Address) apitypes.TypedDataDomain {
+	return apitypes.TypedDataDomain{
+		Name:              name,
+		Version:           version,
+		ChainId:           (*math.HexOrDecimal256)(new(big.Int).Set(w.chainID)),
+		VerifyingContract: token.Hex(),
+	}
+}
+
+// eip712DomainType EIP712Domain类型定义，Permit2的域不包含version
+func eip712DomainType(withVersion bool) []apitypes.Type {
+	fields := []apitypes.Type{{Name: "name", Type: "string"}}
+	if withVersion {
+		fields = append(fields, apitypes.Type{Name: "version", Type: "string"})
+	}
+	return append(fields,
+		apitypes.Type{Name: "chainId", Type: "uint256"},
+		apitypes.Type{Name: "verifyingContract", Type: "address"},
+	)
+}
@@ -0,0 +1,87 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// DecodeRawTransaction 解码RLP编码的交易，已签名时按当前链的签名规则恢复发送方
+func (w *BaseETHWallet) DecodeRawTransaction(rawTx []byte) (*wallet.DecodedTx, error) {
+	return DecodeRawTransaction(rawTx, w.chainID)
+}
+
+// GetTransaction 获取已广播的交易并解码，交易不存在时返回ErrTransactionNotFound
+func (w *BaseETHWallet) GetTransaction(ctx context.Context, txHash string) (*wallet.DecodedTx, error) {
+	client, err := w.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, _, err := client.TransactionByHash(ctx, common.HexToHash(txHash))
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, fmt.Errorf("%w: %s", wallet.ErrTransactionNotFound, txHash)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %v", err)
+	}
+
+	rawTx, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction: %v", err)
+	}
+	return DecodeRawTransaction(rawTx, w.chainID)
+}
+
+// DecodeRawTransaction 按指定链ID解码RLP编码的交易，供离线签名工具在无RPC连接时使用
+func DecodeRawTransaction(rawTx []byte, chainID *big.Int) (*wallet.DecodedTx, error) {
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(rawTx); err != nil {
+		return nil, fmt.Errorf("%w: %v", wallet.ErrInvalidTransaction, err)
+	}
+
+	_, r, s := tx.RawSignatureValues()
+	decoded := &wallet.DecodedTx{
+		Hash:   tx.Hash().Hex(),
+		Type:   tx.Type(),
+		Signed: r.Sign() != 0 || s.Sign() != 0,
+		Nonce:  tx.Nonce(),
+		Value:  tx.Value(),
+		Fee:    txFee(&tx),
+	}
+	if tx.To() != nil {
+		decoded.To = tx.To().Hex()
+	}
+	if len(tx.Data()) > 0 {
+		decoded.Data = hexutil.Encode(tx.Data())
+		if len(tx.Data()) >= 4 {
+			decoded.Method = hexutil.Encode(tx.Data()[:4])
+		}
+	}
+
+	// 未签名的legacy交易不含链ID，v为0时ChainId()的结果没有意义
+	if tx.Type() != types.LegacyTxType || decoded.Signed {
+		decoded.ChainID = tx.ChainId()
+	}
+	if decoded.ChainID != nil && decoded.ChainID.Sign() != 0 && decoded.ChainID.Cmp(chainID) != 0 {
+		return nil, fmt.Errorf("%w: transaction chain id %s, wallet chain id %s", wallet.ErrChainIDMismatch, decoded.ChainID, chainID)
+	}
+
+	if decoded.Signed {
+		from, err := types.Sender(types.LatestSignerForChainID(chainID), &tx)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid signature: %v", wallet.ErrInvalidTransaction, err)
+		}
+		decoded.From = from.Hex()
+	}
+
+	return decoded, nil
+}
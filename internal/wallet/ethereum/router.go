@@ -0,0 +1,229 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// Universal Router命令
+const (
+	routerCommandV3SwapExactIn = 0x00
+	routerCommandPermit2Permit = 0x0a
+)
+
+// routerMsgSender Universal Router中表示调用方的特殊地址，用作兑换收款方
+var routerMsgSender = common.HexToAddress("0x0000000000000000000000000000000000000001")
+
+// Universal Router execute(bytes commands, bytes[] inputs, uint256 deadline)
+const universalRouterABI = `[{"inputs":[{"name":"commands","type":"bytes"},{"name":"inputs","type":"bytes[]"},{"name":"deadline","type":"uint256"}],"name":"execute","outputs":[],"stateMutability":"payable","type":"function"}]`
+
+// permitSingle Permit2的IAllowanceTransfer.PermitSingle
+type permitSingle struct {
+	Details     permitDetails
+	Spender     common.Address
+	SigDeadline *big.Int
+}
+
+type permitDetails struct {
+	Token      common.Address
+	Amount     *big.Int
+	Expiration *big.Int
+	Nonce      *big.Int
+}
+
+// BuildRouterSwap 构造Universal Router的兑换交易。带Permit2签名时授权与兑换在同一笔交易中完成，
+// 签名内容须与兑换一致：代币为路径起点，spender为router，额度不小于输入金额
+func (w *BaseETHWallet) BuildRouterSwap(ctx context.Context, from string, router string, swap *wallet.RouterSwap) (*wallet.UnsignedTx, error) {
+	if !common.IsHexAddress(from) || !common.IsHexAddress(router) {
+		return nil, errors.New("invalid address format")
+	}
+	if swap.AmountIn == nil || swap.AmountIn.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: swap amount must be positive", wallet.ErrInvalidTransaction)
+	}
+	amountOutMin := swap.AmountOutMin
+	if amountOutMin == nil {
+		amountOutMin = new(big.Int)
+	}
+
+	path, err := encodeV3Path(swap.Path, swap.FeeTiers)
+	if err != nil {
+		return nil, err
+	}
+
+	var commands []byte
+	var inputs [][]byte
+	if len(swap.Permit2TypedData) > 0 {
+		permit, err := decodePermitSingle(swap.Permit2TypedData)
+		if err != nil {
+			return nil, err
+		}
+		if permit.Spender != common.HexToAddress(router) {
+			return nil, fmt.Errorf("%w: permit2 spender %s is not the router", wallet.ErrInvalidTransaction, permit.Spender.Hex())
+		}
+		if permit.Details.Token != common.HexToAddress(swap.Path[0]) {
+			return nil, fmt.Errorf("%w: permit2 token %s is not the input token", wallet.ErrInvalidTransaction, permit.Details.Token.Hex())
+		}
+		if permit.Details.Amount.Cmp(swap.AmountIn) < 0 {
+			return nil, fmt.Errorf("%w: permit2 amount is less than swap amount", wallet.ErrInvalidTransaction)
+		}
+
+		input, err := encodePermit2PermitInput(permit, swap.Permit2Signature)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, routerCommandPermit2Permit)
+		inputs = append(inputs, input)
+	}
+
+	// V3_SWAP_EXACT_IN(recipient, amountIn, amountOutMin, path, payerIsUser)，由Permit2从调用方扣款
+	addressType, _ := abi.NewType("address", "", nil)
+	uint256Type, _ := abi.NewType("uint256", "", nil)
+	bytesType, _ := abi.NewType("bytes", "", nil)
+	boolType, _ := abi.NewType("bool", "", nil)
+	swapInput, err := abi.Arguments{
+		{Type: addressType},
+		{Type: uint256Type},
+		{Type: uint256Type},
+		{Type: bytesType},
+		{Type: boolType},
+	}.Pack(routerMsgSender, swap.AmountIn, amountOutMin, path, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode swap: %v", err)
+	}
+	commands = append(commands, routerCommandV3SwapExactIn)
+	inputs = append(inputs, swapInput)
+
+	routerABI, err := abi.JSON(strings.NewReader(universalRouterABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %v", err)
+	}
+	deadline := swap.Deadline
+	if deadline == nil {
+		return nil, fmt.Errorf("%w: swap deadline is required", wallet.ErrInvalidTransaction)
+	}
+	data, err := routerABI.Pack("execute", commands, inputs, deadline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode router call: %v", err)
+	}
+
+	return w.CreateTransaction(ctx, from, router, big.NewInt(0), data)
+}
+
+// encodeV3Path 编码V3路径：token(20字节) fee(3字节) token ...
+func encodeV3Path(tokens []string, feeTiers []uint32) ([]byte, error) {
+	if len(tokens) < 2 || len(feeTiers) != len(tokens)-1 {
+		return nil, fmt.Errorf("%w: swap path needs n tokens and n-1 fee tiers", wallet.ErrInvalidTransaction)
+	}
+	var path []byte
+	for i, token := range tokens {
+		if !common.IsHexAddress(token) {
+			return nil, errors.New("invalid address format")
+		}
+		path = append(path, common.HexToAddress(token).Bytes()...)
+		if i < len(feeTiers) {
+			fee := feeTiers[i]
+			if fee >= 1<<24 {
+				return nil, fmt.Errorf("%w: invalid fee tier %d", wallet.ErrInvalidTransaction, fee)
+			}
+			path = append(path, byte(fee>>16), byte(fee>>8), byte(fee))
+		}
+	}
+	return path, nil
+}
+
+// encodePermit2PermitInput 编码PERMIT2_PERMIT命令的输入abi.encode(PermitSingle, bytes signature)
+func encodePermit2PermitInput(permit *permitSingle, signature []byte) ([]byte, error) {
+	if len(signature) != 65 {
+		return nil, fmt.Errorf("%w: permit2 signature must be 65 bytes", wallet.ErrInvalidTransaction)
+	}
+	permitType, err := abi.NewType("tuple", "", []abi.ArgumentMarshaling{
+		{Name: "details", Type: "tuple", Components: []abi.ArgumentMarshaling{
+			{Name: "token", Type: "address"},
+			{Name: "amount", Type: "uint160"},
+			{Name: "expiration", Type: "uint48"},
+			{Name: "nonce", Type: "uint48"},
+		}},
+		{Name: "spender", Type: "address"},
+		{Name: "sigDeadline", Type: "uint256"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build permit type: %v", err)
+	}
+	bytesType, _ := abi.NewType("bytes", "", nil)
+	input, err := abi.Arguments{{Type: permitType}, {Type: bytesType}}.Pack(*permit, signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode permit2 permit: %v", err)
+	}
+	return input, nil
+}
+
+// decodePermitSingle 从已签名的Permit2 EIP-712数据中取出PermitSingle，保证编码内容与签名内容一致
+func decodePermitSingle(typedDataJSON []byte) (*permitSingle, error) {
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal(typedDataJSON, &typedData); err != nil {
+		return nil, fmt.Errorf("%w: invalid permit2 typed data: %v", wallet.ErrInvalidTransaction, err)
+	}
+	if typedData.PrimaryType != "PermitSingle" ||
+		!strings.EqualFold(typedData.Domain.VerifyingContract, Permit2Address) {
+		return nil, fmt.Errorf("%w: typed data is not a Permit2 PermitSingle", wallet.ErrInvalidTransaction)
+	}
+
+	details, ok := typedData.Message["details"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: permit2 details missing", wallet.ErrInvalidTransaction)
+	}
+	token, _ := details["token"].(string)
+	spender, _ := typedData.Message["spender"].(string)
+	if !common.IsHexAddress(token) || !common.IsHexAddress(spender) {
+		return nil, fmt.Errorf("%w: invalid permit2 address", wallet.ErrInvalidTransaction)
+	}
+
+	permit := &permitSingle{
+		Details: permitDetails{Token: common.HexToAddress(token)},
+		Spender: common.HexToAddress(spender),
+	}
+	fields := []struct {
+		value interface{}
+		dest  **big.Int
+	}{
+		{details["amount"], &permit.Details.Amount},
+		{details["expiration"], &permit.Details.Expiration},
+		{details["nonce"], &permit.Details.Nonce},
+		{typedData.Message["sigDeadline"], &permit.SigDeadline},
+	}
+	for _, field := range fields {
+		n, ok := typedDataInteger(field.value)
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid permit2 number %v", wallet.ErrInvalidTransaction, field.value)
+		}
+		*field.dest = n
+	}
+	return permit, nil
+}
+
+// typedDataInteger 解析EIP-712消息中的整数，支持十进制或0x开头的字符串及JSON数字
+func typedDataInteger(value interface{}) (*big.Int, bool) {
+	switch v := value.(type) {
+	case string:
+		if strings.HasPrefix(v, "0x") {
+			return new(big.Int).SetString(v[2:], 16)
+		}
+		return new(big.Int).SetString(v, 10)
+	case float64:
+		n, accuracy := new(big.Float).SetFloat64(v).Int(nil)
+		return n, accuracy == big.Exact
+	case json.Number:
+		return new(big.Int).SetString(v.String(), 10)
+	}
+	return nil, false
+}
@@ -0,0 +1,509 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+const (
+	// 健康检查间隔
+	rpcHealthCheckInterval = 15 * time.Second
+	// 单次健康检查超时
+	rpcHealthCheckTimeout = 5 * time.Second
+	// 落后最高区块超过该值的节点视为不健康
+	rpcMaxBlockLag = 5
+	// 错误率超过该值的节点视为不健康
+	rpcMaxErrorRate = 0.5
+	// 发送交易时同时广播的节点数
+	rpcBroadcastFanout = 3
+	// 延迟和错误率的指数滑动平均系数
+	rpcEWMAAlpha = 0.3
+)
+
+// rpcEndpoint 单个RPC节点及其健康指标
+type rpcEndpoint struct {
+	rawURL string
+	url    *url.URL
+
+	mu          sync.Mutex
+	latency     time.Duration
+	blockNumber uint64
+	errorRate   float64
+	lastError   string
+	lastCheck   time.Time
+}
+
+// recordSuccess 记录一次成功请求
+func (e *rpcEndpoint) recordSuccess(latency time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.latency == 0 {
+		e.latency = latency
+	} else {
+		e.latency = time.Duration(rpcEWMAAlpha*float64(latency) + (1-rpcEWMAAlpha)*float64(e.latency))
+	}
+	e.errorRate = (1 - rpcEWMAAlpha) * e.errorRate
+}
+
+// recordFailure 记录一次失败请求
+func (e *rpcEndpoint) recordFailure(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.errorRate = rpcEWMAAlpha + (1-rpcEWMAAlpha)*e.errorRate
+	e.lastError = redactRPCError(err)
+}
+
+// RPCPool 单条链的多RPC节点池，作为ethclient底层的HTTP传输层：
+// 读请求按健康评分选择节点并在失败时自动切换，发送交易时广播到多个节点
+type RPCPool struct {
+	chainType wallet.ChainType
+	endpoints []*rpcEndpoint
+	transport http.RoundTripper
+	stopChan  chan struct{}
+	stopOnce  sync.Once
+}
+
+// NewRPCPool 创建RPC节点池，仅支持HTTP(S)节点
+func NewRPCPool(chainType wallet.ChainType, rpcURLs []string) (*RPCPool, error) {
+	if len(rpcURLs) == 0 {
+		return nil, errors.New("no rpc url configured")
+	}
+
+	endpoints := make([]*rpcEndpoint, 0, len(rpcURLs))
+	for _, rawURL := range rpcURLs {
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rpc url: %v", err)
+		}
+		if parsed.Scheme != "http" && parsed.Scheme != "https" {
+			return nil, fmt.Errorf("rpc pool only supports http(s) endpoints, got %s", parsed.Scheme)
+		}
+		endpoints = append(endpoints, &rpcEndpoint{rawURL: rawURL, url: parsed})
+	}
+
+	return &RPCPool{
+		chainType: chainType,
+		endpoints: endpoints,
+		transport: http.DefaultTransport,
+		stopChan:  make(chan struct{}),
+	}, nil
+}
+
+// URL 返回用于创建rpc客户端的地址，实际请求地址由节点池决定
+func (p *RPCPool) URL() string {
+	return p.endpoints[0].rawURL
+}
+
+// Start 启动后台健康检查
+func (p *RPCPool) Start() {
+	p.checkHealth()
+
+	go func() {
+		ticker := time.NewTicker(rpcHealthCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-p.stopChan:
+				return
+			case <-ticker.C:
+				p.checkHealth()
+			}
+		}
+	}()
+}
+
+// Stop 停止后台健康检查
+func (p *RPCPool) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stopChan)
+	})
+}
+
+// checkHealth 并发检查所有节点的延迟和区块高度
+func (p *RPCPool) checkHealth() {
+	var wg sync.WaitGroup
+	for _, endpoint := range p.endpoints {
+		wg.Add(1)
+		go func(endpoint *rpcEndpoint) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), rpcHealthCheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			blockNumber, err := p.fetchBlockNumber(ctx, endpoint)
+			if err != nil {
+				endpoint.recordFailure(err)
+			} else {
+				endpoint.recordSuccess(time.Since(start))
+			}
+
+			endpoint.mu.Lock()
+			if err == nil {
+				endpoint.blockNumber = blockNumber
+			}
+			endpoint.lastCheck = time.Now()
+			endpoint.mu.Unlock()
+		}(endpoint)
+	}
+	wg.Wait()
+}
+
+// fetchBlockNumber 直接向节点请求eth_blockNumber
+func (p *RPCPool) fetchBlockNumber(ctx context.Context, endpoint *rpcEndpoint) (uint64, error) {
+	body := []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.rawURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.transport.RoundTrip(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Result hexutil.Uint64 `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, err
+	}
+	if result.Error != nil {
+		return 0, errors.New(result.Error.Message)
+	}
+
+	return uint64(result.Result), nil
+}
+
+// endpointScore 节点评分，越小越好
+type endpointScore struct {
+	endpoint *rpcEndpoint
+	healthy  bool
+	score    float64
+}
+
+// rankedEndpoints 按健康状态和评分排序节点，不健康的节点排在最后作为兜底，同时返回健康节点数
+func (p *RPCPool) rankedEndpoints() ([]*rpcEndpoint, int) {
+	var maxBlock uint64
+	for _, endpoint := range p.endpoints {
+		endpoint.mu.Lock()
+		if endpoint.blockNumber > maxBlock {
+			maxBlock = endpoint.blockNumber
+		}
+		endpoint.mu.Unlock()
+	}
+
+	scores := make([]endpointScore, 0, len(p.endpoints))
+	for _, endpoint := range p.endpoints {
+		endpoint.mu.Lock()
+		lag := maxBlock - endpoint.blockNumber
+		// 延迟(ms) * 错误惩罚 + 区块落后惩罚
+		score := float64(endpoint.latency.Milliseconds()+1)*(1+4*endpoint.errorRate) + float64(lag)*200
+		healthy := endpoint.errorRate < rpcMaxErrorRate && lag <= rpcMaxBlockLag
+		endpoint.mu.Unlock()
+
+		scores = append(scores, endpointScore{endpoint: endpoint, healthy: healthy, score: score})
+	}
+
+	sort.SliceStable(scores, func(i, j int) bool {
+		if scores[i].healthy != scores[j].healthy {
+			return scores[i].healthy
+		}
+		return scores[i].score < scores[j].score
+	})
+
+	ranked := make([]*rpcEndpoint, 0, len(scores))
+	healthy := 0
+	for _, s := range scores {
+		ranked = append(ranked, s.endpoint)
+		if s.healthy {
+			healthy++
+		}
+	}
+	return ranked, healthy
+}
+
+// RoundTrip 实现http.RoundTripper
+func (p *RPCPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ranked, healthy := p.rankedEndpoints()
+
+	// 批量请求中的发送交易单独广播，其他调用只发往一个节点
+	sends, others, isBatch := splitSendCalls(body)
+	switch {
+	case !isBatch && isSendCall(body), isBatch && len(others) == 0:
+		return p.broadcast(req, body, ranked, healthy)
+	case !isBatch || len(sends) == 0:
+		return p.failover(req, body, ranked)
+	}
+
+	sendResp, err := p.broadcast(req, encodeBatch(sends), ranked, healthy)
+	if err != nil {
+		return nil, err
+	}
+	otherResp, err := p.failover(req, encodeBatch(others), ranked)
+	if err != nil {
+		sendResp.Body.Close()
+		return nil, err
+	}
+	return mergeBatchResponses(sendResp, otherResp)
+}
+
+// failover 按排序依次请求节点，直到有节点响应
+func (p *RPCPool) failover(req *http.Request, body []byte, ranked []*rpcEndpoint) (*http.Response, error) {
+	var lastErr error
+	for _, endpoint := range ranked {
+		start := time.Now()
+		resp, err := p.send(req, body, endpoint)
+		if err != nil {
+			endpoint.recordFailure(err)
+			lastErr = err
+			// 调用方取消时不再尝试其他节点
+			if req.Context().Err() != nil {
+				return nil, err
+			}
+			continue
+		}
+		endpoint.recordSuccess(time.Since(start))
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("all rpc endpoints failed for %s: %s", p.chainType, redactRPCError(lastErr))
+}
+
+// send 向指定节点发送请求，HTTP 429/5xx视为节点故障
+func (p *RPCPool) send(req *http.Request, body []byte, endpoint *rpcEndpoint) (*http.Response, error) {
+	outReq := req.Clone(req.Context())
+	outReq.URL = endpoint.url
+	outReq.Host = ""
+	outReq.Body = io.NopCloser(bytes.NewReader(body))
+	outReq.ContentLength = int64(len(body))
+
+	resp, err := p.transport.RoundTrip(outReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s returned status %d", endpoint.url.Host, resp.StatusCode)
+	}
+
+	return resp, nil
+}
+
+// broadcast 将交易同时发送到评分最高的几个健康节点，优先返回没有JSON-RPC错误的响应。
+// 没有健康节点时只发往排在第一的节点
+func (p *RPCPool) broadcast(req *http.Request, body []byte, ranked []*rpcEndpoint, healthy int) (*http.Response, error) {
+	targets := ranked[:max(healthy, 1)]
+	if len(targets) > rpcBroadcastFanout {
+		targets = targets[:rpcBroadcastFanout]
+	}
+
+	type broadcastResult struct {
+		resp *http.Response
+		body []byte
+		err  error
+	}
+
+	results := make([]broadcastResult, len(targets))
+	var wg sync.WaitGroup
+	for i, endpoint := range targets {
+		wg.Add(1)
+		go func(i int, endpoint *rpcEndpoint) {
+			defer wg.Done()
+
+			start := time.Now()
+			resp, err := p.send(req, body, endpoint)
+			if err != nil {
+				endpoint.recordFailure(err)
+				results[i] = broadcastResult{err: err}
+				return
+			}
+			endpoint.recordSuccess(time.Since(start))
+
+			respBody, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			results[i] = broadcastResult{resp: resp, body: respBody, err: err}
+		}(i, endpoint)
+	}
+	wg.Wait()
+
+	var fallback *broadcastResult
+	var lastErr error
+	for i := range results {
+		result := &results[i]
+		if result.err != nil {
+			lastErr = result.err
+			continue
+		}
+		if !hasRPCError(result.body) {
+			return rebuildResponse(result.resp, result.body), nil
+		}
+		if fallback == nil {
+			fallback = result
+		}
+	}
+
+	if fallback != nil {
+		return rebuildResponse(fallback.resp, fallback.body), nil
+	}
+
+	return nil, fmt.Errorf("failed to broadcast transaction on %s: %s", p.chainType, redactRPCError(lastErr))
+}
+
+// Health 返回各节点的健康状况
+func (p *RPCPool) Health() []wallet.RPCEndpointHealth {
+	var maxBlock uint64
+	for _, endpoint := range p.endpoints {
+		endpoint.mu.Lock()
+		if endpoint.blockNumber > maxBlock {
+			maxBlock = endpoint.blockNumber
+		}
+		endpoint.mu.Unlock()
+	}
+
+	health := make([]wallet.RPCEndpointHealth, 0, len(p.endpoints))
+	for _, endpoint := range p.endpoints {
+		endpoint.mu.Lock()
+		lag := maxBlock - endpoint.blockNumber
+		health = append(health, wallet.RPCEndpointHealth{
+			Endpoint:    endpoint.url.Host,
+			Healthy:     endpoint.errorRate < rpcMaxErrorRate && lag <= rpcMaxBlockLag,
+			LatencyMs:   endpoint.latency.Milliseconds(),
+			BlockNumber: endpoint.blockNumber,
+			BlockLag:    lag,
+			ErrorRate:   endpoint.errorRate,
+			LastError:   endpoint.lastError,
+			LastCheck:   endpoint.lastCheck.Unix(),
+		})
+		endpoint.mu.Unlock()
+	}
+
+	return health
+}
+
+// rpcCall JSON-RPC请求中用于区分发送交易的字段
+type rpcCall struct {
+	Method string `json:"method"`
+}
+
+// isSendCall 判断单个请求是否为发送交易
+func isSendCall(body []byte) bool {
+	var call rpcCall
+	if err := json.Unmarshal(body, &call); err != nil {
+		return false
+	}
+	return call.Method == "eth_sendRawTransaction"
+}
+
+// splitSendCalls 把批量请求拆分为发送交易的调用和其他调用，不是批量请求时isBatch为false
+func splitSendCalls(body []byte) (sends []json.RawMessage, others []json.RawMessage, isBatch bool) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		return nil, nil, false
+	}
+
+	var calls []json.RawMessage
+	if err := json.Unmarshal(trimmed, &calls); err != nil {
+		return nil, nil, false
+	}
+	for _, call := range calls {
+		if isSendCall(call) {
+			sends = append(sends, call)
+		} else {
+			others = append(others, call)
+		}
+	}
+	return sends, others, true
+}
+
+// encodeBatch 编码批量请求
+func encodeBatch(calls []json.RawMessage) []byte {
+	body, _ := json.Marshal(calls)
+	return body
+}
+
+// mergeBatchResponses 合并拆分后两部分批量请求的响应，JSON-RPC客户端按id匹配响应，顺序无关
+func mergeBatchResponses(first *http.Response, second *http.Response) (*http.Response, error) {
+	var merged []json.RawMessage
+	for _, resp := range []*http.Response{first, second} {
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		var responses []json.RawMessage
+		if err := json.Unmarshal(body, &responses); err != nil {
+			return nil, fmt.Errorf("invalid batch response: %v", err)
+		}
+		merged = append(merged, responses...)
+	}
+	return rebuildResponse(second, encodeBatch(merged)), nil
+}
+
+// hasRPCError 判断JSON-RPC响应是否包含错误
+func hasRPCError(body []byte) bool {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var responses []map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &responses); err != nil {
+			return true
+		}
+		for _, resp := range responses {
+			if _, ok := resp["error"]; ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	var resp map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &resp); err != nil {
+		return true
+	}
+	_, ok := resp["error"]
+	return ok || !strings.Contains(string(trimmed), `"result"`)
+}
+
+// rebuildResponse 用已读取的响应体重建响应
+func rebuildResponse(resp *http.Response, body []byte) *http.Response {
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	return resp
+}
@@ -0,0 +1,238 @@
+package ethereum
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// testRPCNode 记录收到的方法并返回固定区块高度的JSON-RPC节点
+type testRPCNode struct {
+	server *httptest.Server
+
+	mu          sync.Mutex
+	blockNumber uint64
+	status      int // 非0时直接返回该HTTP状态码
+	methods     []string
+}
+
+func newTestRPCNode(t *testing.T, blockNumber uint64) *testRPCNode {
+	node := &testRPCNode{blockNumber: blockNumber}
+	node.server = httptest.NewServer(http.HandlerFunc(node.serve))
+	t.Cleanup(node.server.Close)
+	return node
+}
+
+func (n *testRPCNode) serve(w http.ResponseWriter, r *http.Request) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.status != 0 {
+		w.WriteHeader(n.status)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	type request struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	respond := func(req request) map[string]interface{} {
+		n.methods = append(n.methods, req.Method)
+		var result interface{} = hexutil.Uint64(n.blockNumber)
+		if req.Method == "eth_sendRawTransaction" {
+			result = "0x01"
+		}
+		return map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result}
+	}
+
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")) {
+		var reqs []request
+		json.Unmarshal(body, &reqs)
+		resps := make([]map[string]interface{}, 0, len(reqs))
+		for _, req := range reqs {
+			resps = append(resps, respond(req))
+		}
+		json.NewEncoder(w).Encode(resps)
+		return
+	}
+	var req request
+	json.Unmarshal(body, &req)
+	json.NewEncoder(w).Encode(respond(req))
+}
+
+// calls 返回收到method的次数
+func (n *testRPCNode) calls(method string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	count := 0
+	for _, m := range n.methods {
+		if m == method {
+			count++
+		}
+	}
+	return count
+}
+
+func (n *testRPCNode) reset() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.methods = nil
+}
+
+func newTestRPCPool(t *testing.T, nodes ...*testRPCNode) *RPCPool {
+	urls := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		urls = append(urls, node.server.URL)
+	}
+	pool, err := NewRPCPool("test", urls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.checkHealth()
+	for _, node := range nodes {
+		node.reset()
+	}
+	return pool
+}
+
+func roundTrip(t *testing.T, pool *RPCPool, body string) []byte {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, pool.URL(), bytes.NewReader([]byte(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := pool.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return respBody
+}
+
+const (
+	blockNumberCall = `{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`
+	sendCall        = `{"jsonrpc":"2.0","id":2,"method":"eth_sendRawTransaction","params":["0x00"]}`
+)
+
+// 节点返回5xx时切换到下一个节点，并计入错误率
+func TestRPCPoolFailover(t *testing.T) {
+	first := newTestRPCNode(t, 100)
+	second := newTestRPCNode(t, 100)
+	pool := newTestRPCPool(t, first, second)
+	ranked, _ := pool.rankedEndpoints()
+
+	// 让排在前面的节点故障
+	failing, fallback := first, second
+	if ranked[0].rawURL == second.server.URL {
+		failing, fallback = second, first
+	}
+	failing.mu.Lock()
+	failing.status = http.StatusBadGateway
+	failing.mu.Unlock()
+
+	roundTrip(t, pool, blockNumberCall)
+	if fallback.calls("eth_blockNumber") != 1 {
+		t.Fatal("expected the request to fail over to the healthy node")
+	}
+	if ranked[0].errorRate == 0 {
+		t.Fatal("expected the failure to be recorded")
+	}
+}
+
+// 落后超过rpcMaxBlockLag或错误率过高的节点不健康，排在最后
+func TestRPCPoolHealthScoring(t *testing.T) {
+	lagging := newTestRPCNode(t, 100)
+	current := newTestRPCNode(t, 100+rpcMaxBlockLag+1)
+	down := newTestRPCNode(t, 0)
+	down.status = http.StatusServiceUnavailable
+	pool := newTestRPCPool(t, lagging, current, down)
+	pool.checkHealth()
+
+	ranked, healthy := pool.rankedEndpoints()
+	if healthy != 1 || ranked[0].rawURL != current.server.URL {
+		t.Fatalf("expected only the up-to-date node to be healthy, got %d healthy, first %s", healthy, ranked[0].rawURL)
+	}
+	for _, health := range pool.Health() {
+		if want := health.Endpoint == current.server.Listener.Addr().String(); health.Healthy != want {
+			t.Fatalf("unexpected health for %s: %+v", health.Endpoint, health)
+		}
+	}
+}
+
+// 发送交易只广播到健康节点，最多rpcBroadcastFanout个
+func TestRPCPoolBroadcastSkipsUnhealthyNodes(t *testing.T) {
+	var nodes []*testRPCNode
+	for i := 0; i < rpcBroadcastFanout+1; i++ {
+		nodes = append(nodes, newTestRPCNode(t, 100))
+	}
+	lagging := newTestRPCNode(t, 1)
+	pool := newTestRPCPool(t, append([]*testRPCNode{lagging}, nodes...)...)
+
+	roundTrip(t, pool, sendCall)
+	if lagging.calls("eth_sendRawTransaction") != 0 {
+		t.Fatal("expected the lagging node not to receive the transaction")
+	}
+	sent := 0
+	for _, node := range nodes {
+		sent += node.calls("eth_sendRawTransaction")
+	}
+	if sent != rpcBroadcastFanout {
+		t.Fatalf("expected %d broadcasts, got %d", rpcBroadcastFanout, sent)
+	}
+
+	// 只有两个健康节点时只广播到这两个
+	healthyNodes := nodes[:2]
+	pool = newTestRPCPool(t, append([]*testRPCNode{lagging, newTestRPCNode(t, 2)}, healthyNodes...)...)
+	roundTrip(t, pool, sendCall)
+	for _, node := range healthyNodes {
+		if node.calls("eth_sendRawTransaction") != 1 {
+			t.Fatal("expected each healthy node to receive the transaction")
+		}
+	}
+	if lagging.calls("eth_sendRawTransaction") != 0 {
+		t.Fatal("expected the lagging node not to receive the transaction")
+	}
+}
+
+// 批量请求中只有发送交易广播，其他调用只发往一个节点，响应合并返回
+func TestRPCPoolSplitsMixedBatch(t *testing.T) {
+	nodes := []*testRPCNode{newTestRPCNode(t, 100), newTestRPCNode(t, 100), newTestRPCNode(t, 100)}
+	pool := newTestRPCPool(t, nodes...)
+
+	body := roundTrip(t, pool, "["+blockNumberCall+","+sendCall+"]")
+
+	reads, sends := 0, 0
+	for _, node := range nodes {
+		reads += node.calls("eth_blockNumber")
+		sends += node.calls("eth_sendRawTransaction")
+	}
+	if reads != 1 || sends != len(nodes) {
+		t.Fatalf("expected 1 read and %d sends, got %d and %d", len(nodes), reads, sends)
+	}
+
+	var responses []struct {
+		ID     int    `json:"id"`
+		Result string `json:"result"`
+	}
+	if err := json.Unmarshal(body, &responses); err != nil {
+		t.Fatal(err)
+	}
+	ids := map[int]bool{}
+	for _, resp := range responses {
+		ids[resp.ID] = true
+	}
+	if len(responses) != 2 || !ids[1] || !ids[2] {
+		t.Fatalf("expected responses for both calls, got %s", body)
+	}
+}
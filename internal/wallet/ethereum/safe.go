@@ -0,0 +1,320 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// Safe v1.4.1合约地址，各EVM链通过确定性部署保持一致
+const (
+	SafeProxyFactoryAddress    = "0x4e1DCf7AD4e460CfD30791CCC4F9c8a4f820ec67"
+	SafeSingletonAddress       = "0x41675C099F32341bf84BFc5382aF534df5C7461a"
+	SafeL2SingletonAddress     = "0x29fcB43b46531BcA003ddC8FCB67FFE91900C762" // 额外发出事件，便于在没有trace的链上索引，主网以外的链使用
+	SafeFallbackHandlerAddress = "0xfd0732Dc9E303f09fCEf3a7388Ad10A83459Ec99" // CompatibilityFallbackHandler
+)
+
+const (
+	safeMainnetChainID        = 1
+	safeSignatureLength       = 65
+	safeOperationDelegateCall = 1
+)
+
+// SafeProxyFactory createProxyWithNonce和proxyCreationCode
+const safeProxyFactoryABI = `[
+	{"inputs":[{"name":"_singleton","type":"address"},{"name":"initializer","type":"bytes"},{"name":"saltNonce","type":"uint256"}],"name":"createProxyWithNonce","outputs":[{"name":"proxy","type":"address"}],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[],"name":"proxyCreationCode","outputs":[{"name":"","type":"bytes"}],"stateMutability":"pure","type":"function"}
+]`
+
+// Safe合约方法
+const safeABI = `[
+	{"inputs":[{"name":"_owners","type":"address[]"},{"name":"_threshold","type":"uint256"},{"name":"to","type":"address"},{"name":"data","type":"bytes"},{"name":"fallbackHandler","type":"address"},{"name":"paymentToken","type":"address"},{"name":"payment","type":"uint256"},{"name":"paymentReceiver","type":"address"}],"name":"setup","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[],"name":"getOwners","outputs":[{"name":"","type":"address[]"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"getThreshold","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"nonce","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"VERSION","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"},{"name":"operation","type":"uint8"},{"name":"safeTxGas","type":"uint256"},{"name":"baseGas","type":"uint256"},{"name":"gasPrice","type":"uint256"},{"name":"gasToken","type":"address"},{"name":"refundReceiver","type":"address"},{"name":"signatures","type":"bytes"}],"name":"execTransaction","outputs":[{"name":"success","type":"bool"}],"stateMutability":"payable","type":"function"}
+]`
+
+// GetSafeInfo 查询Safe的所有者、门限、nonce和合约版本
+func (w *BaseETHWallet) GetSafeInfo(ctx context.Context, safeAddress string) (*wallet.SafeInfo, error) {
+	if !common.IsHexAddress(safeAddress) {
+		return nil, errors.New("invalid address format")
+	}
+	safe := common.HexToAddress(safeAddress)
+
+	result, err := w.callContract(ctx, safe, safeABI, "getOwners")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get safe owners: %v", err)
+	}
+	ownerAddresses, ok := result[0].([]common.Address)
+	if !ok {
+		return nil, errors.New("failed to convert result to address list")
+	}
+
+	result, err = w.callContract(ctx, safe, safeABI, "getThreshold")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get safe threshold: %v", err)
+	}
+	threshold, ok := result[0].(*big.Int)
+	if !ok {
+		return nil, errors.New("failed to convert result to big.Int")
+	}
+
+	result, err = w.callContract(ctx, safe, safeABI, "nonce")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get safe nonce: %v", err)
+	}
+	nonce, ok := result[0].(*big.Int)
+	if !ok {
+		return nil, errors.New("failed to convert result to big.Int")
+	}
+
+	info := &wallet.SafeInfo{
+		Address:   safe.Hex(),
+		Owners:    make([]string, 0, len(ownerAddresses)),
+		Threshold: int(threshold.Int64()),
+		Nonce:     nonce.Uint64(),
+	}
+	for _, owner := range ownerAddresses {
+		info.Owners = append(info.Owners, owner.Hex())
+	}
+	if result, err := w.callContract(ctx, safe, safeABI, "VERSION"); err == nil {
+		info.Version, _ = result[0].(string)
+	}
+
+	return info, nil
+}
+
+// BuildSafeDeployment 构建部署Safe代理合约的交易。Safe地址由CREATE2确定，
+// 相同的所有者、门限和saltNonce总是得到同一地址
+func (w *BaseETHWallet) BuildSafeDeployment(ctx context.Context, from string, owners []string, threshold int, saltNonce *big.Int) (*wallet.UnsignedTx, string, error) {
+	if len(owners) == 0 || threshold < 1 || threshold > len(owners) {
+		return nil, "", fmt.Errorf("threshold must be between 1 and %d", len(owners))
+	}
+	ownerAddresses := make([]common.Address, 0, len(owners))
+	seen := make(map[common.Address]bool, len(owners))
+	for _, owner := range owners {
+		if !common.IsHexAddress(owner) {
+			return nil, "", fmt.Errorf("invalid owner address %s", owner)
+		}
+		address := common.HexToAddress(owner)
+		if address == (common.Address{}) || seen[address] {
+			return nil, "", fmt.Errorf("invalid or duplicate owner %s", owner)
+		}
+		seen[address] = true
+		ownerAddresses = append(ownerAddresses, address)
+	}
+	if saltNonce == nil {
+		saltNonce = new(big.Int)
+	}
+
+	factory := common.HexToAddress(SafeProxyFactoryAddress)
+	deployed, err := w.IsContract(ctx, factory.Hex())
+	if err != nil {
+		return nil, "", err
+	}
+	if !deployed {
+		return nil, "", fmt.Errorf("%w: Safe contracts are not deployed on this chain", wallet.ErrOperationNotSupported)
+	}
+
+	singleton := common.HexToAddress(SafeL2SingletonAddress)
+	if w.chainID.Cmp(big.NewInt(safeMainnetChainID)) == 0 {
+		singleton = common.HexToAddress(SafeSingletonAddress)
+	}
+
+	safeParsed, err := abi.JSON(strings.NewReader(safeABI))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse ABI: %v", err)
+	}
+	initializer, err := safeParsed.Pack("setup", ownerAddresses, big.NewInt(int64(threshold)),
+		common.Address{}, []byte{}, common.HexToAddress(SafeFallbackHandlerAddress), common.Address{}, big.NewInt(0), common.Address{})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode safe setup: %v", err)
+	}
+
+	// 地址 = CREATE2(factory, keccak256(keccak256(initializer) || saltNonce), keccak256(proxyCreationCode || singleton))
+	result, err := w.callContract(ctx, factory, safeProxyFactoryABI, "proxyCreationCode")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get proxy creation code: %v", err)
+	}
+	creationCode, ok := result[0].([]byte)
+	if !ok {
+		return nil, "", errors.New("failed to convert result to bytes")
+	}
+	salt := crypto.Keccak256Hash(crypto.Keccak256(initializer), common.BigToHash(saltNonce).Bytes())
+	initCodeHash := crypto.Keccak256(creationCode, common.BytesToHash(singleton.Bytes()).Bytes())
+	safeAddress := crypto.CreateAddress2(factory, salt, initCodeHash)
+
+	factoryParsed, err := abi.JSON(strings.NewReader(safeProxyFactoryABI))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse ABI: %v", err)
+	}
+	data, err := factoryParsed.Pack("createProxyWithNonce", singleton, initializer, saltNonce)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode safe deployment: %v", err)
+	}
+
+	tx, err := w.CreateTransaction(ctx, from, factory.Hex(), big.NewInt(0), data)
+	if err != nil {
+		return nil, "", err
+	}
+	return tx, safeAddress.Hex(), nil
+}
+
+// BuildSafeTxTypedData 构造SafeTx的EIP-712签名数据，域为(chainId, verifyingContract=Safe地址)
+func (w *BaseETHWallet) BuildSafeTxTypedData(safeTx *wallet.SafeTransaction) ([]byte, string, error) {
+	typedData, err := w.safeTxTypedData(safeTx)
+	if err != nil {
+		return nil, "", err
+	}
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to hash safe transaction: %v", err)
+	}
+
+	typedDataJSON, err := json.Marshal(typedData)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to serialize typed data: %v", err)
+	}
+	return typedDataJSON, hexutil.Encode(hash), nil
+}
+
+func (w *BaseETHWallet) safeTxTypedData(safeTx *wallet.SafeTransaction) (apitypes.TypedData, error) {
+	if !common.IsHexAddress(safeTx.Safe) || !common.IsHexAddress(safeTx.To) ||
+		(safeTx.GasToken != "" && !common.IsHexAddress(safeTx.GasToken)) ||
+		(safeTx.RefundReceiver != "" && !common.IsHexAddress(safeTx.RefundReceiver)) {
+		return apitypes.TypedData{}, errors.New("invalid address format")
+	}
+	if safeTx.Operation > safeOperationDelegateCall {
+		return apitypes.TypedData{}, fmt.Errorf("invalid safe operation %d", safeTx.Operation)
+	}
+	data, err := safeTxData(safeTx)
+	if err != nil {
+		return apitypes.TypedData{}, err
+	}
+
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": []apitypes.Type{
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"SafeTx": []apitypes.Type{
+				{Name: "to", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "data", Type: "bytes"},
+				{Name: "operation", Type: "uint8"},
+				{Name: "safeTxGas", Type: "uint256"},
+				{Name: "baseGas", Type: "uint256"},
+				{Name: "gasPrice", Type: "uint256"},
+				{Name: "gasToken", Type: "address"},
+				{Name: "refundReceiver", Type: "address"},
+				{Name: "nonce", Type: "uint256"},
+			},
+		},
+		PrimaryType: "SafeTx",
+		Domain: apitypes.TypedDataDomain{
+			ChainId:           (*math.HexOrDecimal256)(new(big.Int).Set(w.chainID)),
+			VerifyingContract: common.HexToAddress(safeTx.Safe).Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"to":             common.HexToAddress(safeTx.To).Hex(),
+			"value":          bigOrZero(safeTx.Value).String(),
+			"data":           hexutil.Encode(data),
+			"operation":      fmt.Sprintf("%d", safeTx.Operation),
+			"safeTxGas":      bigOrZero(safeTx.SafeTxGas).String(),
+			"baseGas":        bigOrZero(safeTx.BaseGas).String(),
+			"gasPrice":       bigOrZero(safeTx.GasPrice).String(),
+			"gasToken":       addressOrZero(safeTx.GasToken).Hex(),
+			"refundReceiver": addressOrZero(safeTx.RefundReceiver).Hex(),
+			"nonce":          fmt.Sprintf("%d", safeTx.Nonce),
+		},
+	}, nil
+}
+
+// BuildSafeExecTransaction 构建execTransaction交易。Safe要求签名按所有者地址升序拼接，
+// 每个签名为r||s||v，v取27/28表示对safeTxHash的EIP-712签名
+func (w *BaseETHWallet) BuildSafeExecTransaction(ctx context.Context, from string, safeTx *wallet.SafeTransaction, signatures map[string][]byte) (*wallet.UnsignedTx, error) {
+	if _, err := w.safeTxTypedData(safeTx); err != nil {
+		return nil, err
+	}
+	data, err := safeTxData(safeTx)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := make([]common.Address, 0, len(signatures))
+	byOwner := make(map[common.Address][]byte, len(signatures))
+	for owner, signature := range signatures {
+		if !common.IsHexAddress(owner) {
+			return nil, fmt.Errorf("invalid owner address %s", owner)
+		}
+		if len(signature) != safeSignatureLength {
+			return nil, fmt.Errorf("invalid signature length for owner %s", owner)
+		}
+		address := common.HexToAddress(owner)
+		owners = append(owners, address)
+		byOwner[address] = signature
+	}
+	sort.Slice(owners, func(i, j int) bool {
+		return bytes.Compare(owners[i].Bytes(), owners[j].Bytes()) < 0
+	})
+	packedSignatures := make([]byte, 0, len(owners)*safeSignatureLength)
+	for _, owner := range owners {
+		packedSignatures = append(packedSignatures, byOwner[owner]...)
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(safeABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %v", err)
+	}
+	callData, err := parsed.Pack("execTransaction",
+		common.HexToAddress(safeTx.To), bigOrZero(safeTx.Value), data, safeTx.Operation,
+		bigOrZero(safeTx.SafeTxGas), bigOrZero(safeTx.BaseGas), bigOrZero(safeTx.GasPrice),
+		addressOrZero(safeTx.GasToken), addressOrZero(safeTx.RefundReceiver), packedSignatures)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode execTransaction: %v", err)
+	}
+
+	return w.CreateTransaction(ctx, from, common.HexToAddress(safeTx.Safe).Hex(), big.NewInt(0), callData)
+}
+
+func safeTxData(safeTx *wallet.SafeTransaction) ([]byte, error) {
+	if safeTx.Data == "" || safeTx.Data == "0x" {
+		return []byte{}, nil
+	}
+	data, err := hexutil.Decode(safeTx.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid safe transaction data: %v", err)
+	}
+	return data, nil
+}
+
+func bigOrZero(v *big.Int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return v
+}
+
+func addressOrZero(address string) common.Address {
+	if address == "" {
+		return common.Address{}
+	}
+	return common.HexToAddress(address)
+}
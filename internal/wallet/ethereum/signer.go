@@ -0,0 +1,71 @@
+package ethereum
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Signer 以太坊签名器，私钥可以在本进程内（本地keystore），也可以在外部签名服务中（Clef）。
+// 消息和结构化数据签名返回65字节r||s||v，v取值27/28
+type Signer interface {
+	// 签名者地址
+	Address() common.Address
+	// 签名交易，返回已签名交易
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+	// 按EIP-191（personal_sign）签名消息
+	SignText(ctx context.Context, message []byte) ([]byte, error)
+	// 按EIP-712 v4签名结构化数据
+	SignTypedData(ctx context.Context, typedData apitypes.TypedData) ([]byte, error)
+}
+
+// localSigner 使用本地解密的私钥签名
+type localSigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewLocalSigner 创建使用本地私钥的签名器
+func NewLocalSigner(key *ecdsa.PrivateKey) Signer {
+	return &localSigner{key: key, address: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+func (s *localSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *localSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+}
+
+func (s *localSigner) SignText(ctx context.Context, message []byte) ([]byte, error) {
+	// "\x19Ethereum Signed Message:\n" + len(message) + message
+	return s.signHash(accounts.TextHash(message))
+}
+
+func (s *localSigner) SignTypedData(ctx context.Context, typedData apitypes.TypedData) ([]byte, error) {
+	// keccak256("\x19\x01" || domainSeparator || hashStruct(message))
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash typed data: %v", err)
+	}
+	return s.signHash(hash)
+}
+
+func (s *localSigner) signHash(hash []byte) ([]byte, error) {
+	signature, err := crypto.Sign(hash, s.key)
+	if err != nil {
+		return nil, err
+	}
+
+	// 以太坊钱包约定 v 取值为 27/28
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
@@ -0,0 +1,114 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/params"
+
+	"multi-chain-wallet/internal/wallet"
+	"multi-chain-wallet/internal/wallet/hd"
+)
+
+// DevMnemonic 开发模式预置账户的助记词，与Hardhat/Anvil相同，切勿在真实网络使用
+const DevMnemonic = "test test test test test test test test test test test junk"
+
+// SimulatedChainID 模拟链的链ID，go-ethereum模拟后端固定为1337
+const SimulatedChainID = 1337
+
+// 开发模式预置账户数量
+const devAccountCount = 10
+
+// 每个预置账户的初始余额：10000 ETH
+var devAccountBalance = new(big.Int).Mul(big.NewInt(10000), big.NewInt(params.Ether))
+
+// DevAccount 开发模式预置账户
+type DevAccount struct {
+	Address    string `json:"address"`
+	PrivateKey string `json:"privateKey"`
+}
+
+// DevAccounts 从DevMnemonic按m/44'/60'/0'/0/i派生预置账户
+func DevAccounts() ([]DevAccount, error) {
+	accounts := make([]DevAccount, 0, devAccountCount)
+	for i := 0; i < devAccountCount; i++ {
+		key, err := hd.DeriveFromMnemonic(DevMnemonic, fmt.Sprintf("m/44'/60'/0'/0/%d", i))
+		if err != nil {
+			return nil, err
+		}
+		btcecKey, err := key.ECPrivKey()
+		if err != nil {
+			return nil, err
+		}
+		privateKey := btcecKey.ToECDSA()
+		accounts = append(accounts, DevAccount{
+			Address:    crypto.PubkeyToAddress(privateKey.PublicKey).Hex(),
+			PrivateKey: fmt.Sprintf("%x", crypto.FromECDSA(privateKey)),
+		})
+	}
+
+	return accounts, nil
+}
+
+// simulatedClient 模拟链客户端，发送交易后立即出块，使交易马上得到收据
+type simulatedClient struct {
+	simulated.Client
+	backend *simulated.Backend
+}
+
+// SendTransaction 发送交易并出块
+func (c *simulatedClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := c.Client.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	c.backend.Commit()
+	return nil
+}
+
+// SyncProgress 模拟链始终处于已同步状态
+func (c *simulatedClient) SyncProgress(ctx context.Context) (*eth.SyncProgress, error) {
+	return nil, nil
+}
+
+// Close 模拟链与钱包同生命周期，断线重连时不关闭
+func (c *simulatedClient) Close() {}
+
+// NewSimulatedWallet 创建运行在进程内模拟链上的钱包，预置账户在创世块中注资
+func NewSimulatedWallet(chain wallet.ChainInfo, encryptionKey string) (*BaseETHWallet, error) {
+	if chain.ChainID != SimulatedChainID {
+		return nil, fmt.Errorf("%w: simulated chain must use chain id %d", wallet.ErrChainIDMismatch, SimulatedChainID)
+	}
+
+	accounts, err := DevAccounts()
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive dev accounts: %v", err)
+	}
+
+	alloc := make(types.GenesisAlloc, len(accounts))
+	for _, account := range accounts {
+		alloc[common.HexToAddress(account.Address)] = types.Account{Balance: devAccountBalance}
+	}
+
+	backend := simulated.NewBackend(alloc)
+	client := &simulatedClient{Client: backend.Client(), backend: backend}
+
+	base := newBaseETHWallet(chain.ChainType, new(big.Int).SetUint64(chain.ChainID), encryptionKey, func() (ChainClient, *RPCPool, error) {
+		return client, nil, nil
+	})
+	base.eip1559 = true
+
+	return base, nil
+}
+
+// NewSimulatedFactory 创建模拟链的钱包工厂
+func NewSimulatedFactory(encryptionKey string) wallet.WalletFactory {
+	return func(chain wallet.ChainInfo) (wallet.Wallet, error) {
+		return NewSimulatedWallet(chain, encryptionKey)
+	}
+}
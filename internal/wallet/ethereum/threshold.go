@@ -0,0 +1,103 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/google/uuid"
+
+	"multi-chain-wallet/internal/wallet"
+	"multi-chain-wallet/internal/wallet/mpc"
+)
+
+// thresholdSigner 通过门限签名节点签名，签名结果是普通的secp256k1签名
+type thresholdSigner struct {
+	coordinator *mpc.Coordinator
+	key         *mpc.Key
+	address     common.Address
+}
+
+func (s *thresholdSigner) Address() common.Address {
+	return s.address
+}
+
+func (s *thresholdSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	signer := types.LatestSignerForChainID(chainID)
+	hash := signer.Hash(tx)
+	signature, err := s.sign(ctx, hash[:])
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(signer, signature)
+}
+
+func (s *thresholdSigner) SignText(ctx context.Context, message []byte) ([]byte, error) {
+	return s.signHash(ctx, accounts.TextHash(message))
+}
+
+func (s *thresholdSigner) SignTypedData(ctx context.Context, typedData apitypes.TypedData) ([]byte, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash typed data: %v", err)
+	}
+	return s.signHash(ctx, hash)
+}
+
+func (s *thresholdSigner) signHash(ctx context.Context, hash []byte) ([]byte, error) {
+	signature, err := s.sign(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
+
+// sign 执行门限签名协议，在线节点不足门限时返回ErrSignerUnavailable
+func (s *thresholdSigner) sign(ctx context.Context, hash []byte) ([]byte, error) {
+	signature, err := s.coordinator.Sign(ctx, s.key, hash)
+	if err != nil {
+		return nil, thresholdError(err)
+	}
+	return signature, nil
+}
+
+// CreateThresholdWallet 在签名节点上生成t-of-n门限密钥并创建钱包，地址由聚合公钥导出
+func (w *BaseETHWallet) CreateThresholdWallet(ctx context.Context, nodeURLs []string, threshold int) (string, string, error) {
+	key, err := w.coordinator.Keygen(ctx, nodeURLs, threshold)
+	if err != nil {
+		return "", "", thresholdError(err)
+	}
+	publicKey, err := crypto.DecompressPubkey(key.PublicKey)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid threshold public key: %v", err)
+	}
+
+	keystore := &KeyStore{
+		ID:         uuid.New().String(),
+		Address:    crypto.PubkeyToAddress(*publicKey).Hex(),
+		MPCKey:     key,
+		ChainType:  w.chainType,
+		CreateTime: time.Now().Unix(),
+	}
+	if err := w.saveKeyStore(keystore); err != nil {
+		return "", "", fmt.Errorf("failed to save keystore: %v", err)
+	}
+
+	return keystore.ID, key.ID, nil
+}
+
+// thresholdError 签名节点不可达时返回ErrSignerUnavailable，节点拒绝等协议错误原样返回
+func thresholdError(err error) error {
+	if errors.Is(err, mpc.ErrNotEnoughNodes) || errors.Is(err, mpc.ErrNodeUnavailable) {
+		return fmt.Errorf("%w: %v", wallet.ErrSignerUnavailable, err)
+	}
+	return err
+}
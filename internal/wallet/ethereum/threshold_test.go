@@ -0,0 +1,95 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"multi-chain-wallet/internal/wallet"
+	"multi-chain-wallet/internal/wallet/mpc"
+)
+
+// startSignerNodes 在本机启动count个门限签名节点，返回节点地址
+func startSignerNodes(t *testing.T, count int) []string {
+	urls := make([]string, count)
+	for i := range urls {
+		node, err := mpc.NewNode(t.TempDir(), fmt.Sprintf("node%d-secret", i+1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		server := httptest.NewServer(node.Handler())
+		t.Cleanup(server.Close)
+		urls[i] = server.URL
+	}
+	return urls
+}
+
+func TestThresholdWalletSendTransaction(t *testing.T) {
+	ctx := context.Background()
+	w, key := newSimulatedTestWallet(t)
+
+	walletID, _, err := w.CreateThresholdWallet(ctx, startSignerNodes(t, 3), 2)
+	if err != nil {
+		t.Fatalf("CreateThresholdWallet: %v", err)
+	}
+	address := common.HexToAddress(w.keyMap[walletID].Address)
+
+	// 用预置账户给门限地址转账
+	funderID, err := w.ImportFromPrivateKey(fmt.Sprintf("%x", crypto.FromECDSA(key)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	funding, err := w.CreateTransaction(ctx, crypto.PubkeyToAddress(key.PublicKey).Hex(), address.Hex(), big.NewInt(1e18), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signedFunding, err := w.SignTransaction(ctx, funderID, funding)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.SendTransaction(ctx, signedFunding); err != nil {
+		t.Fatal(err)
+	}
+
+	to := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	tx, err := w.CreateTransaction(ctx, address.Hex(), to.Hex(), big.NewInt(1e15), nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	signed, err := w.SignTransaction(ctx, walletID, tx)
+	if err != nil {
+		t.Fatalf("SignTransaction: %v", err)
+	}
+	var signedTx types.Transaction
+	if err := signedTx.UnmarshalBinary(signed.Payload); err != nil {
+		t.Fatal(err)
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(SimulatedChainID)), &signedTx)
+	if err != nil || sender != address {
+		t.Fatalf("transaction signed by %s, want %s", sender.Hex(), address.Hex())
+	}
+
+	txHash, err := w.SendTransaction(ctx, signed)
+	if err != nil {
+		t.Fatalf("SendTransaction: %v", err)
+	}
+	if status, err := w.GetTransactionStatus(ctx, txHash); err != nil || status != string(wallet.TxConfirmed) {
+		t.Fatalf("transaction status %s, err %v", status, err)
+	}
+
+	message := []byte("signed by threshold nodes")
+	signature, err := w.SignMessage(ctx, walletID, message)
+	if err != nil {
+		t.Fatalf("SignMessage: %v", err)
+	}
+	verification, err := w.VerifyMessage(ctx, address.Hex(), message, signature)
+	if err != nil || !verification.Valid {
+		t.Fatalf("message signature invalid: %+v, %v", verification, err)
+	}
+}
@@ -0,0 +1,688 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// ERC-4337 v0.7合约地址，各EVM链通过确定性部署保持一致
+const (
+	EntryPointAddress           = "0x0000000071727De22E5E9d8BAf0edAc6f37da032"
+	SimpleAccountFactoryAddress = "0x91E60e0613810449d098b926D86B3C31ba6C1D31" // eth-infinitism SimpleAccountFactory，账户由单个ECDSA所有者控制
+)
+
+// dummySignature 估算gas时使用的占位签名，长度与真实签名相同且能通过ecrecover，使验证阶段的gas消耗接近真实值
+const dummySignature = "0xfffffffffffffffffffffffffffffff0000000000000000000000000000000007aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa1c"
+
+// SimpleAccountFactory createAccount和getAddress
+const simpleAccountFactoryABI = `[
+	{"inputs":[{"name":"owner","type":"address"},{"name":"salt","type":"uint256"}],"name":"createAccount","outputs":[{"name":"ret","type":"address"}],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"name":"owner","type":"address"},{"name":"salt","type":"uint256"}],"name":"getAddress","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"}
+]`
+
+// SimpleAccount execute、executeBatch和owner
+const simpleAccountABI = `[
+	{"inputs":[{"name":"dest","type":"address"},{"name":"value","type":"uint256"},{"name":"func","type":"bytes"}],"name":"execute","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[{"name":"dest","type":"address[]"},{"name":"value","type":"uint256[]"},{"name":"func","type":"bytes[]"}],"name":"executeBatch","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"inputs":[],"name":"owner","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"}
+]`
+
+// EntryPoint v0.7 getNonce、handleOps及执行结果事件
+const entryPointABI = `[
+	{"inputs":[{"name":"sender","type":"address"},{"name":"key","type":"uint192"}],"name":"getNonce","outputs":[{"name":"nonce","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"components":[{"name":"sender","type":"address"},{"name":"nonce","type":"uint256"},{"name":"initCode","type":"bytes"},{"name":"callData","type":"bytes"},{"name":"accountGasLimits","type":"bytes32"},{"name":"preVerificationGas","type":"uint256"},{"name":"gasFees","type":"bytes32"},{"name":"paymasterAndData","type":"bytes"},{"name":"signature","type":"bytes"}],"name":"ops","type":"tuple[]"},{"name":"beneficiary","type":"address"}],"name":"handleOps","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"userOpHash","type":"bytes32"},{"indexed":true,"name":"sender","type":"address"},{"indexed":true,"name":"paymaster","type":"address"},{"indexed":false,"name":"nonce","type":"uint256"},{"indexed":false,"name":"success","type":"bool"},{"indexed":false,"name":"actualGasCost","type":"uint256"},{"indexed":false,"name":"actualGasUsed","type":"uint256"}],"name":"UserOperationEvent","type":"event"},
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"userOpHash","type":"bytes32"},{"indexed":true,"name":"sender","type":"address"},{"indexed":false,"name":"nonce","type":"uint256"},{"indexed":false,"name":"revertReason","type":"bytes"}],"name":"UserOperationRevertReason","type":"event"}
+]`
+
+// rpcUserOperation bundler RPC中的UserOperation，数值为hex编码
+type rpcUserOperation struct {
+	Sender                        common.Address  `json:"sender"`
+	Nonce                         *hexutil.Big    `json:"nonce"`
+	Factory                       *common.Address `json:"factory,omitempty"`
+	FactoryData                   hexutil.Bytes   `json:"factoryData,omitempty"`
+	CallData                      hexutil.Bytes   `json:"callData"`
+	CallGasLimit                  *hexutil.Big    `json:"callGasLimit"`
+	VerificationGasLimit          *hexutil.Big    `json:"verificationGasLimit"`
+	PreVerificationGas            *hexutil.Big    `json:"preVerificationGas"`
+	MaxFeePerGas                  *hexutil.Big    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas          *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	Paymaster                     *common.Address `json:"paymaster,omitempty"`
+	PaymasterVerificationGasLimit *hexutil.Big    `json:"paymasterVerificationGasLimit,omitempty"`
+	PaymasterPostOpGasLimit       *hexutil.Big    `json:"paymasterPostOpGasLimit,omitempty"`
+	PaymasterData                 hexutil.Bytes   `json:"paymasterData,omitempty"`
+	Signature                     hexutil.Bytes   `json:"signature"`
+}
+
+// rpcGasEstimate eth_estimateUserOperationGas的返回值
+type rpcGasEstimate struct {
+	PreVerificationGas            *hexutil.Big `json:"preVerificationGas"`
+	VerificationGasLimit          *hexutil.Big `json:"verificationGasLimit"`
+	CallGasLimit                  *hexutil.Big `json:"callGasLimit"`
+	PaymasterVerificationGasLimit *hexutil.Big `json:"paymasterVerificationGasLimit,omitempty"`
+	PaymasterPostOpGasLimit       *hexutil.Big `json:"paymasterPostOpGasLimit,omitempty"`
+}
+
+// rpcUserOperationReceipt eth_getUserOperationReceipt的返回值，只解析用到的字段
+type rpcUserOperationReceipt struct {
+	UserOpHash    common.Hash    `json:"userOpHash"`
+	EntryPoint    common.Address `json:"entryPoint"`
+	Sender        common.Address `json:"sender"`
+	Nonce         *hexutil.Big   `json:"nonce"`
+	Paymaster     common.Address `json:"paymaster"`
+	ActualGasCost *hexutil.Big   `json:"actualGasCost"`
+	ActualGasUsed *hexutil.Big   `json:"actualGasUsed"`
+	Success       bool           `json:"success"`
+	Reason        hexutil.Bytes  `json:"reason,omitempty"`
+	Receipt       rpcReceiptRef  `json:"receipt"`
+}
+
+// rpcReceiptRef UserOperation所在handleOps交易的收据
+type rpcReceiptRef struct {
+	TransactionHash common.Hash    `json:"transactionHash"`
+	BlockNumber     hexutil.Uint64 `json:"blockNumber"`
+}
+
+// packedUserOperation EntryPoint v0.7链上使用的PackedUserOperation，字段名与handleOps的tuple对应
+type packedUserOperation struct {
+	Sender             common.Address
+	Nonce              *big.Int
+	InitCode           []byte
+	CallData           []byte
+	AccountGasLimits   [32]byte
+	PreVerificationGas *big.Int
+	GasFees            [32]byte
+	PaymasterAndData   []byte
+	Signature          []byte
+}
+
+// GetSmartAccount 通过工厂合约计算所有者的智能账户地址，账户未部署时为CREATE2反事实地址
+func (w *BaseETHWallet) GetSmartAccount(ctx context.Context, owner string, salt *big.Int) (*wallet.SmartAccount, error) {
+	if !common.IsHexAddress(owner) {
+		return nil, errors.New("invalid address format")
+	}
+	if salt == nil {
+		salt = new(big.Int)
+	}
+	client, err := w.getClient()
+	if err != nil {
+		return nil, err
+	}
+	return getSmartAccount(ctx, client, common.HexToAddress(owner), salt)
+}
+
+func getSmartAccount(ctx context.Context, client ChainClient, owner common.Address, salt *big.Int) (*wallet.SmartAccount, error) {
+	entryPoint := common.HexToAddress(EntryPointAddress)
+	factory := common.HexToAddress(SimpleAccountFactoryAddress)
+	for _, contract := range []common.Address{entryPoint, factory} {
+		code, err := client.CodeAt(ctx, contract, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get code: %v", err)
+		}
+		if len(code) == 0 {
+			return nil, fmt.Errorf("%w: ERC-4337 v0.7 contracts are not deployed on this chain", wallet.ErrOperationNotSupported)
+		}
+	}
+
+	result, err := callContract(ctx, client, factory, simpleAccountFactoryABI, "getAddress", owner, salt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get smart account address: %v", err)
+	}
+	sender, ok := result[0].(common.Address)
+	if !ok {
+		return nil, errors.New("failed to convert result to address")
+	}
+
+	code, err := client.CodeAt(ctx, sender, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get code: %v", err)
+	}
+	nonce, err := entryPointNonce(ctx, client, sender, new(big.Int))
+	if err != nil {
+		return nil, err
+	}
+
+	return &wallet.SmartAccount{
+		Address:    sender.Hex(),
+		Owner:      owner.Hex(),
+		Salt:       salt,
+		Factory:    factory.Hex(),
+		EntryPoint: entryPoint.Hex(),
+		Deployed:   len(code) > 0,
+		Nonce:      nonce,
+	}, nil
+}
+
+// entryPointNonce 查询账户在EntryPoint中指定key的nonce，高192位为key，低64位为序号
+func entryPointNonce(ctx context.Context, client ChainClient, sender common.Address, key *big.Int) (*big.Int, error) {
+	result, err := callContract(ctx, client, common.HexToAddress(EntryPointAddress), entryPointABI, "getNonce", sender, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entry point nonce: %v", err)
+	}
+	nonce, ok := result[0].(*big.Int)
+	if !ok {
+		return nil, errors.New("failed to convert result to big.Int")
+	}
+	return nonce, nil
+}
+
+// BuildUserOperation 构建UserOperation：一个调用使用execute，多个调用使用executeBatch，
+// 费用取链上建议值，gas限制由bundler按占位签名估算
+func (w *BaseETHWallet) BuildUserOperation(ctx context.Context, owner string, salt *big.Int, calls []wallet.SmartAccountCall, paymaster *wallet.Paymaster) (*wallet.UserOperation, error) {
+	account, err := w.GetSmartAccount(ctx, owner, salt)
+	if err != nil {
+		return nil, err
+	}
+	callData, err := smartAccountCallData(calls)
+	if err != nil {
+		return nil, err
+	}
+	fees, err := w.suggestFees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	op := &wallet.UserOperation{
+		Sender:               account.Address,
+		Nonce:                account.Nonce,
+		CallData:             hexutil.Encode(callData),
+		CallGasLimit:         new(big.Int),
+		VerificationGasLimit: new(big.Int),
+		PreVerificationGas:   new(big.Int),
+		MaxFeePerGas:         fees.gasFeeCap,
+		MaxPriorityFeePerGas: fees.gasTipCap,
+		Signature:            dummySignature,
+	}
+	if fees.gasFeeCap == nil {
+		op.MaxFeePerGas, op.MaxPriorityFeePerGas = fees.gasPrice, fees.gasPrice
+	}
+	if !account.Deployed {
+		factoryABI, err := abi.JSON(strings.NewReader(simpleAccountFactoryABI))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ABI: %v", err)
+		}
+		factoryData, err := factoryABI.Pack("createAccount", common.HexToAddress(account.Owner), account.Salt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode createAccount: %v", err)
+		}
+		op.Factory = account.Factory
+		op.FactoryData = hexutil.Encode(factoryData)
+	}
+	if paymaster != nil {
+		if !common.IsHexAddress(paymaster.Address) {
+			return nil, errors.New("invalid paymaster address")
+		}
+		op.Paymaster = common.HexToAddress(paymaster.Address).Hex()
+		op.PaymasterData = paymaster.Data
+		op.PaymasterVerificationGasLimit = paymaster.VerificationGasLimit
+		op.PaymasterPostOpGasLimit = paymaster.PostOpGasLimit
+	}
+
+	estimate, err := w.estimateUserOperationGas(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+	op.CallGasLimit = estimate.CallGasLimit.ToInt()
+	op.VerificationGasLimit = estimate.VerificationGasLimit.ToInt()
+	op.PreVerificationGas = estimate.PreVerificationGas.ToInt()
+	if paymaster != nil {
+		if op.PaymasterVerificationGasLimit == nil && estimate.PaymasterVerificationGasLimit != nil {
+			op.PaymasterVerificationGasLimit = estimate.PaymasterVerificationGasLimit.ToInt()
+		}
+		if op.PaymasterPostOpGasLimit == nil && estimate.PaymasterPostOpGasLimit != nil {
+			op.PaymasterPostOpGasLimit = estimate.PaymasterPostOpGasLimit.ToInt()
+		}
+		if op.PaymasterVerificationGasLimit == nil || op.PaymasterPostOpGasLimit == nil {
+			return nil, errors.New("bundler did not estimate paymaster gas limits")
+		}
+	}
+	op.Signature = ""
+
+	return op, nil
+}
+
+// estimateUserOperationGas 调用bundler的eth_estimateUserOperationGas
+func (w *BaseETHWallet) estimateUserOperationGas(ctx context.Context, op *wallet.UserOperation) (*rpcGasEstimate, error) {
+	rpcOp, err := toRPCUserOperation(op)
+	if err != nil {
+		return nil, err
+	}
+	// 估算时paymaster的gas限制由bundler给出
+	if rpcOp.PaymasterVerificationGasLimit == nil && rpcOp.Paymaster != nil {
+		rpcOp.PaymasterVerificationGasLimit = new(hexutil.Big)
+		rpcOp.PaymasterPostOpGasLimit = new(hexutil.Big)
+	}
+	client, err := w.bundlerClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var estimate rpcGasEstimate
+	if err := client.CallContext(ctx, &estimate, "eth_estimateUserOperationGas", rpcOp, common.HexToAddress(EntryPointAddress)); err != nil {
+		return nil, bundlerError(err)
+	}
+	if estimate.CallGasLimit == nil || estimate.VerificationGasLimit == nil || estimate.PreVerificationGas == nil {
+		return nil, errors.New("incomplete gas estimate from bundler")
+	}
+	return &estimate, nil
+}
+
+// UserOperationHash 计算userOpHash：keccak256(abi.encode(keccak256(pack(userOp)), entryPoint, chainId))
+func (w *BaseETHWallet) UserOperationHash(op *wallet.UserOperation) (string, error) {
+	rpcOp, err := toRPCUserOperation(op)
+	if err != nil {
+		return "", err
+	}
+	packed, err := rpcOp.pack()
+	if err != nil {
+		return "", err
+	}
+	return userOperationHash(packed, common.HexToAddress(EntryPointAddress), w.chainID).Hex(), nil
+}
+
+// SignUserOperation 所有者按EIP-191签名userOpHash，与SimpleAccount的签名校验方式一致。
+// sender须是该钱包的智能账户，否则签名可被用于其他账户或构造的合约
+func (w *BaseETHWallet) SignUserOperation(ctx context.Context, walletID string, op *wallet.UserOperation) (*wallet.UserOperation, error) {
+	rpcOp, err := toRPCUserOperation(op)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", wallet.ErrInvalidTransaction, err)
+	}
+	hash, err := w.UserOperationHash(op)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := w.getSigner(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	client, err := w.getClient()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSender(ctx, client, rpcOp, signer.Address()); err != nil {
+		return nil, err
+	}
+	signature, err := signer.SignText(ctx, common.HexToHash(hash).Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign user operation: %w", err)
+	}
+
+	signed := *op
+	signed.Signature = hexutil.Encode(signature)
+	return &signed, nil
+}
+
+// checkSender 确认sender是owner的SimpleAccount：带工厂数据时须为createAccount(owner, salt)且sender等于其反事实地址，
+// 否则账户须已部署且owner()为owner
+func checkSender(ctx context.Context, client ChainClient, op *rpcUserOperation, owner common.Address) error {
+	if op.Factory != nil {
+		if *op.Factory != common.HexToAddress(SimpleAccountFactoryAddress) {
+			return fmt.Errorf("%w: unsupported factory %s", wallet.ErrInvalidTransaction, op.Factory.Hex())
+		}
+		accountOwner, salt, err := decodeCreateAccount(op.FactoryData)
+		if err != nil {
+			return fmt.Errorf("%w: %v", wallet.ErrInvalidTransaction, err)
+		}
+		if accountOwner != owner {
+			return fmt.Errorf("%w: factory data creates an account for %s, not %s", wallet.ErrInvalidTransaction, accountOwner.Hex(), owner.Hex())
+		}
+		account, err := getSmartAccount(ctx, client, owner, salt)
+		if err != nil {
+			return err
+		}
+		if common.HexToAddress(account.Address) != op.Sender {
+			return fmt.Errorf("%w: sender %s is not the smart account %s of %s", wallet.ErrInvalidTransaction, op.Sender.Hex(), account.Address, owner.Hex())
+		}
+		return nil
+	}
+
+	code, err := client.CodeAt(ctx, op.Sender, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get code: %v", err)
+	}
+	if len(code) == 0 {
+		return fmt.Errorf("%w: account %s is not deployed and no factory given", wallet.ErrInvalidTransaction, op.Sender.Hex())
+	}
+	result, err := callContract(ctx, client, op.Sender, simpleAccountABI, "owner")
+	if err != nil {
+		return fmt.Errorf("%w: failed to get owner of %s: %v", wallet.ErrInvalidTransaction, op.Sender.Hex(), err)
+	}
+	if accountOwner, _ := result[0].(common.Address); accountOwner != owner {
+		return fmt.Errorf("%w: %s is not the owner of smart account %s", wallet.ErrInvalidTransaction, owner.Hex(), op.Sender.Hex())
+	}
+	return nil
+}
+
+// decodeCreateAccount 解析SimpleAccountFactory.createAccount的所有者和salt
+func decodeCreateAccount(factoryData []byte) (common.Address, *big.Int, error) {
+	factoryABI, err := abi.JSON(strings.NewReader(simpleAccountFactoryABI))
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("failed to parse ABI: %v", err)
+	}
+	method := factoryABI.Methods["createAccount"]
+	if len(factoryData) < 4 || !bytes.Equal(factoryData[:4], method.ID) {
+		return common.Address{}, nil, errors.New("factory data is not a createAccount call")
+	}
+	args, err := method.Inputs.Unpack(factoryData[4:])
+	if err != nil || len(args) != 2 {
+		return common.Address{}, nil, errors.New("invalid factory data")
+	}
+	owner, _ := args[0].(common.Address)
+	salt, _ := args[1].(*big.Int)
+	if salt == nil {
+		return common.Address{}, nil, errors.New("invalid factory data")
+	}
+	return owner, salt, nil
+}
+
+// SendUserOperation 通过eth_sendUserOperation提交到bundler
+func (w *BaseETHWallet) SendUserOperation(ctx context.Context, op *wallet.UserOperation) (string, error) {
+	rpcOp, err := toRPCUserOperation(op)
+	if err != nil {
+		return "", err
+	}
+	if len(rpcOp.Signature) == 0 {
+		return "", fmt.Errorf("%w: user operation is not signed", wallet.ErrInvalidTransaction)
+	}
+	client, err := w.bundlerClient(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var hash common.Hash
+	if err := client.CallContext(ctx, &hash, "eth_sendUserOperation", rpcOp, common.HexToAddress(EntryPointAddress)); err != nil {
+		return "", bundlerError(err)
+	}
+	return hash.Hex(), nil
+}
+
+// GetUserOperationReceipt 通过eth_getUserOperationReceipt查询执行结果
+func (w *BaseETHWallet) GetUserOperationReceipt(ctx context.Context, userOpHash string) (*wallet.UserOperationReceipt, error) {
+	client, err := w.bundlerClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var receipt *rpcUserOperationReceipt
+	if err := client.CallContext(ctx, &receipt, "eth_getUserOperationReceipt", common.HexToHash(userOpHash)); err != nil {
+		return nil, bundlerError(err)
+	}
+	if receipt == nil {
+		return nil, nil
+	}
+
+	result := &wallet.UserOperationReceipt{
+		UserOpHash:    receipt.UserOpHash.Hex(),
+		Sender:        receipt.Sender.Hex(),
+		Nonce:         receipt.Nonce.ToInt(),
+		Success:       receipt.Success,
+		ActualGasCost: receipt.ActualGasCost.ToInt(),
+		ActualGasUsed: receipt.ActualGasUsed.ToInt(),
+		TxHash:        receipt.Receipt.TransactionHash.Hex(),
+		BlockNumber:   uint64(receipt.Receipt.BlockNumber),
+	}
+	if receipt.Paymaster != (common.Address{}) {
+		result.Paymaster = receipt.Paymaster.Hex()
+	}
+	if len(receipt.Reason) > 0 {
+		result.Reason = receipt.Reason.String()
+	}
+	return result, nil
+}
+
+// bundlerClient 获取bundler的RPC连接，链未配置bundler时不支持UserOperation
+func (w *BaseETHWallet) bundlerClient(ctx context.Context) (*rpc.Client, error) {
+	if w.bundlerURL == "" {
+		return nil, fmt.Errorf("%w: no bundler configured for chain %s", wallet.ErrOperationNotSupported, w.chainType)
+	}
+
+	w.bundlerMu.Lock()
+	defer w.bundlerMu.Unlock()
+
+	if w.bundler != nil {
+		return w.bundler, nil
+	}
+	client, err := rpc.DialContext(ctx, w.bundlerURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", wallet.ErrBundlerUnavailable, err)
+	}
+	w.bundler = client
+	return client, nil
+}
+
+// bundlerError bundler返回的JSON-RPC错误通常表示UserOperation无效（签名、nonce、余额等），其余为网络错误
+func bundlerError(err error) error {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return fmt.Errorf("%w: bundler error: %v", wallet.ErrInvalidTransaction, err)
+	}
+	return fmt.Errorf("%w: %v", wallet.ErrBundlerUnavailable, err)
+}
+
+// smartAccountCallData 编码SimpleAccount的execute/executeBatch调用
+func smartAccountCallData(calls []wallet.SmartAccountCall) ([]byte, error) {
+	if len(calls) == 0 {
+		return nil, errors.New("no calls to execute")
+	}
+	accountABI, err := abi.JSON(strings.NewReader(simpleAccountABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %v", err)
+	}
+
+	dests := make([]common.Address, 0, len(calls))
+	values := make([]*big.Int, 0, len(calls))
+	datas := make([][]byte, 0, len(calls))
+	for _, call := range calls {
+		if !common.IsHexAddress(call.To) {
+			return nil, fmt.Errorf("invalid call target %s", call.To)
+		}
+		data := []byte{}
+		if call.Data != "" && call.Data != "0x" {
+			if data, err = hexutil.Decode(call.Data); err != nil {
+				return nil, fmt.Errorf("invalid call data: %v", err)
+			}
+		}
+		dests = append(dests, common.HexToAddress(call.To))
+		values = append(values, bigOrZero(call.Value))
+		datas = append(datas, data)
+	}
+
+	if len(calls) == 1 {
+		return accountABI.Pack("execute", dests[0], values[0], datas[0])
+	}
+	return accountABI.Pack("executeBatch", dests, values, datas)
+}
+
+// DecodeUserOperationCalls 解析callData中SimpleAccount的execute/executeBatch调用，callData为空时没有调用
+func (w *BaseETHWallet) DecodeUserOperationCalls(op *wallet.UserOperation) ([]wallet.SmartAccountCall, error) {
+	callData, err := decodeOptionalHex(op.CallData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid callData: %v", wallet.ErrInvalidTransaction, err)
+	}
+	if len(callData) == 0 {
+		return nil, nil
+	}
+	calls, err := decodeSmartAccountCallData(callData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", wallet.ErrInvalidTransaction, err)
+	}
+	return calls, nil
+}
+
+// decodeSmartAccountCallData 解码smartAccountCallData编码的调用，executeBatch的value数组为空时各调用不附带ETH
+func decodeSmartAccountCallData(callData []byte) ([]wallet.SmartAccountCall, error) {
+	accountABI, err := abi.JSON(strings.NewReader(simpleAccountABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ABI: %v", err)
+	}
+	if len(callData) < 4 {
+		return nil, errors.New("callData too short")
+	}
+	method, err := accountABI.MethodById(callData[:4])
+	if err != nil || (method.Name != "execute" && method.Name != "executeBatch") {
+		return nil, fmt.Errorf("unsupported smart account method 0x%x", callData[:4])
+	}
+	args, err := method.Inputs.Unpack(callData[4:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s arguments: %v", method.Name, err)
+	}
+
+	var dests []common.Address
+	var values []*big.Int
+	var datas [][]byte
+	if method.Name == "execute" {
+		dests = []common.Address{args[0].(common.Address)}
+		values = []*big.Int{args[1].(*big.Int)}
+		datas = [][]byte{args[2].([]byte)}
+	} else {
+		dests = args[0].([]common.Address)
+		values = args[1].([]*big.Int)
+		datas = args[2].([][]byte)
+		if len(datas) != len(dests) || (len(values) != 0 && len(values) != len(dests)) {
+			return nil, errors.New("executeBatch argument lengths do not match")
+		}
+	}
+
+	calls := make([]wallet.SmartAccountCall, len(dests))
+	for i, dest := range dests {
+		value := new(big.Int)
+		if len(values) > 0 {
+			value = values[i]
+		}
+		calls[i] = wallet.SmartAccountCall{To: dest.Hex(), Value: value, Data: hexutil.Encode(datas[i])}
+	}
+	return calls, nil
+}
+
+// toRPCUserOperation 校验并转换为bundler RPC格式
+func toRPCUserOperation(op *wallet.UserOperation) (*rpcUserOperation, error) {
+	if !common.IsHexAddress(op.Sender) {
+		return nil, errors.New("invalid sender address")
+	}
+	rpcOp := &rpcUserOperation{
+		Sender:               common.HexToAddress(op.Sender),
+		Nonce:                (*hexutil.Big)(bigOrZero(op.Nonce)),
+		CallGasLimit:         (*hexutil.Big)(bigOrZero(op.CallGasLimit)),
+		VerificationGasLimit: (*hexutil.Big)(bigOrZero(op.VerificationGasLimit)),
+		PreVerificationGas:   (*hexutil.Big)(bigOrZero(op.PreVerificationGas)),
+		MaxFeePerGas:         (*hexutil.Big)(bigOrZero(op.MaxFeePerGas)),
+		MaxPriorityFeePerGas: (*hexutil.Big)(bigOrZero(op.MaxPriorityFeePerGas)),
+	}
+
+	var err error
+	if rpcOp.CallData, err = decodeOptionalHex(op.CallData); err != nil {
+		return nil, fmt.Errorf("invalid call data: %v", err)
+	}
+	if rpcOp.Signature, err = decodeOptionalHex(op.Signature); err != nil {
+		return nil, fmt.Errorf("invalid signature: %v", err)
+	}
+	if op.Factory != "" {
+		if !common.IsHexAddress(op.Factory) {
+			return nil, errors.New("invalid factory address")
+		}
+		factory := common.HexToAddress(op.Factory)
+		rpcOp.Factory = &factory
+		if rpcOp.FactoryData, err = decodeOptionalHex(op.FactoryData); err != nil {
+			return nil, fmt.Errorf("invalid factory data: %v", err)
+		}
+	}
+	if op.Paymaster != "" {
+		if !common.IsHexAddress(op.Paymaster) {
+			return nil, errors.New("invalid paymaster address")
+		}
+		paymaster := common.HexToAddress(op.Paymaster)
+		rpcOp.Paymaster = &paymaster
+		if op.PaymasterVerificationGasLimit != nil {
+			rpcOp.PaymasterVerificationGasLimit = (*hexutil.Big)(op.PaymasterVerificationGasLimit)
+		}
+		if op.PaymasterPostOpGasLimit != nil {
+			rpcOp.PaymasterPostOpGasLimit = (*hexutil.Big)(op.PaymasterPostOpGasLimit)
+		}
+		if rpcOp.PaymasterData, err = decodeOptionalHex(op.PaymasterData); err != nil {
+			return nil, fmt.Errorf("invalid paymaster data: %v", err)
+		}
+	}
+	return rpcOp, nil
+}
+
+func decodeOptionalHex(value string) ([]byte, error) {
+	if value == "" || value == "0x" {
+		return []byte{}, nil
+	}
+	return hexutil.Decode(value)
+}
+
+// pack 转换为链上的PackedUserOperation：initCode = factory || factoryData，
+// 两两打包的gas字段各占16字节，paymasterAndData = paymaster || 验证gas || postOp gas || paymasterData
+func (op *rpcUserOperation) pack() (*packedUserOperation, error) {
+	packed := &packedUserOperation{
+		Sender:             op.Sender,
+		Nonce:              bigOrZero(op.Nonce.ToInt()),
+		CallData:           op.CallData,
+		PreVerificationGas: bigOrZero(op.PreVerificationGas.ToInt()),
+		Signature:          op.Signature,
+	}
+	if op.Factory != nil {
+		packed.InitCode = append(op.Factory.Bytes(), op.FactoryData...)
+	}
+
+	var err error
+	if packed.AccountGasLimits, err = packUint128Pair(op.VerificationGasLimit, op.CallGasLimit); err != nil {
+		return nil, err
+	}
+	if packed.GasFees, err = packUint128Pair(op.MaxPriorityFeePerGas, op.MaxFeePerGas); err != nil {
+		return nil, err
+	}
+	if op.Paymaster != nil {
+		gasLimits, err := packUint128Pair(op.PaymasterVerificationGasLimit, op.PaymasterPostOpGasLimit)
+		if err != nil {
+			return nil, err
+		}
+		packed.PaymasterAndData = append(append(op.Paymaster.Bytes(), gasLimits[:]...), op.PaymasterData...)
+	}
+	return packed, nil
+}
+
+// packUint128Pair 将两个uint128拼接为bytes32，high在前
+func packUint128Pair(high *hexutil.Big, low *hexutil.Big) ([32]byte, error) {
+	var packed [32]byte
+	for i, value := range []*hexutil.Big{high, low} {
+		v := bigOrZero(value.ToInt())
+		if v.Sign() < 0 || v.BitLen() > 128 {
+			return packed, errors.New("gas value out of uint128 range")
+		}
+		v.FillBytes(packed[i*16 : (i+1)*16])
+	}
+	return packed, nil
+}
+
+// userOperationHash EntryPoint v0.7的getUserOpHash
+func userOperationHash(op *packedUserOperation, entryPoint common.Address, chainID *big.Int) common.Hash {
+	addressType, _ := abi.NewType("address", "", nil)
+	uint256Type, _ := abi.NewType("uint256", "", nil)
+	bytes32Type, _ := abi.NewType("bytes32", "", nil)
+
+	inner, _ := abi.Arguments{
+		{Type: addressType}, {Type: uint256Type}, {Type: bytes32Type}, {Type: bytes32Type},
+		{Type: bytes32Type}, {Type: uint256Type}, {Type: bytes32Type}, {Type: bytes32Type},
+	}.Pack(
+		op.Sender, op.Nonce, crypto.Keccak256Hash(op.InitCode), crypto.Keccak256Hash(op.CallData),
+		op.AccountGasLimits, op.PreVerificationGas, op.GasFees, crypto.Keccak256Hash(op.PaymasterAndData),
+	)
+	outer, _ := abi.Arguments{{Type: bytes32Type}, {Type: addressType}, {Type: uint256Type}}.Pack(
+		crypto.Keccak256Hash(inner), entryPoint, chainID,
+	)
+	return crypto.Keccak256Hash(outer)
+}
@@ -0,0 +1,623 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	eth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+const testAccountChainID = 31337
+
+// accountChain 模拟部署了EntryPoint v0.7和SimpleAccountFactory的链：按合约ABI应答只读调用，
+// 收到handleOps交易时按EntryPoint的语义部署账户、递增nonce并产生UserOperationEvent
+type accountChain struct {
+	mu       sync.Mutex
+	chainID  *big.Int
+	code     map[common.Address][]byte
+	owners   map[common.Address]common.Address // 已部署账户的所有者
+	nonces   map[common.Address]*big.Int       // EntryPoint中key为0的nonce
+	sent     []*types.Transaction
+	executed [][]byte // 已执行的账户callData
+	receipts map[common.Hash]*types.Receipt
+}
+
+func newAccountChain() *accountChain {
+	return &accountChain{
+		chainID: big.NewInt(testAccountChainID),
+		code: map[common.Address][]byte{
+			common.HexToAddress(EntryPointAddress):           {0x60, 0x80},
+			common.HexToAddress(SimpleAccountFactoryAddress): {0x60, 0x80},
+		},
+		owners:   make(map[common.Address]common.Address),
+		nonces:   make(map[common.Address]*big.Int),
+		receipts: make(map[common.Hash]*types.Receipt),
+	}
+}
+
+// counterfactualAddress 模拟工厂合约的CREATE2地址
+func counterfactualAddress(owner common.Address, salt *big.Int) common.Address {
+	return common.BytesToAddress(crypto.Keccak256(owner.Bytes(), common.LeftPadBytes(salt.Bytes(), 32))[12:])
+}
+
+func mustParseABI(abiJSON string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+func (c *accountChain) CallContract(ctx context.Context, call eth.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if call.To == nil || len(call.Data) < 4 {
+		return nil, errors.New("invalid call")
+	}
+	var contractABI abi.ABI
+	switch {
+	case *call.To == common.HexToAddress(EntryPointAddress):
+		contractABI = mustParseABI(entryPointABI)
+	case *call.To == common.HexToAddress(SimpleAccountFactoryAddress):
+		contractABI = mustParseABI(simpleAccountFactoryABI)
+	case len(c.code[*call.To]) > 0:
+		contractABI = mustParseABI(simpleAccountABI)
+	default:
+		return nil, nil
+	}
+	method, err := contractABI.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	args, err := method.Inputs.Unpack(call.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+
+	switch method.Name {
+	case "getAddress":
+		return method.Outputs.Pack(counterfactualAddress(args[0].(common.Address), args[1].(*big.Int)))
+	case "getNonce":
+		return method.Outputs.Pack(bigOrZero(c.nonces[args[0].(common.Address)]))
+	case "owner":
+		return method.Outputs.Pack(c.owners[*call.To])
+	}
+	return nil, fmt.Errorf("unexpected call to %s", method.Name)
+}
+
+// SendTransaction 执行handleOps：部署账户、递增nonce并记录UserOperationEvent
+func (c *accountChain) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	entryPointABI := mustParseABI(entryPointABI)
+	factoryABI := mustParseABI(simpleAccountFactoryABI)
+
+	if tx.To() == nil || *tx.To() != common.HexToAddress(EntryPointAddress) {
+		return errors.New("unexpected transaction target")
+	}
+	method, err := entryPointABI.MethodById(tx.Data()[:4])
+	if err != nil || method.Name != "handleOps" {
+		return errors.New("not a handleOps call")
+	}
+	values, err := method.Inputs.Unpack(tx.Data()[4:])
+	if err != nil {
+		return err
+	}
+	var args struct {
+		Ops         []packedUserOperation
+		Beneficiary common.Address
+	}
+	if err := method.Inputs.Copy(&args, values); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	receipt := &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		TxHash:      tx.Hash(),
+		BlockNumber: big.NewInt(int64(len(c.sent) + 1)),
+	}
+	opEvent := entryPointABI.Events["UserOperationEvent"]
+	for _, op := range args.Ops {
+		if len(op.InitCode) > 0 {
+			createArgs, err := factoryABI.Methods["createAccount"].Inputs.Unpack(op.InitCode[common.AddressLength+4:])
+			if err != nil {
+				return err
+			}
+			c.code[op.Sender] = []byte{0x60, 0x80}
+			c.owners[op.Sender] = createArgs[0].(common.Address)
+		}
+		c.nonces[op.Sender] = new(big.Int).Add(bigOrZero(c.nonces[op.Sender]), big.NewInt(1))
+		c.executed = append(c.executed, op.CallData)
+
+		data, err := opEvent.Inputs.NonIndexed().Pack(op.Nonce, true, big.NewInt(21000*1e9), big.NewInt(21000))
+		if err != nil {
+			return err
+		}
+		paymaster := common.Address{}
+		if len(op.PaymasterAndData) >= common.AddressLength {
+			paymaster = common.BytesToAddress(op.PaymasterAndData[:common.AddressLength])
+		}
+		receipt.Logs = append(receipt.Logs, &types.Log{
+			Address: common.HexToAddress(EntryPointAddress),
+			Topics: []common.Hash{
+				opEvent.ID,
+				userOperationHash(&op, common.HexToAddress(EntryPointAddress), c.chainID),
+				common.BytesToHash(op.Sender.Bytes()),
+				common.BytesToHash(paymaster.Bytes()),
+			},
+			Data: data,
+		})
+	}
+
+	c.sent = append(c.sent, tx)
+	c.receipts[tx.Hash()] = receipt
+	return nil
+}
+
+func (c *accountChain) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if receipt, ok := c.receipts[txHash]; ok {
+		return receipt, nil
+	}
+	return nil, eth.NotFound
+}
+
+func (c *accountChain) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.code[contract], nil
+}
+
+func (c *accountChain) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return c.CodeAt(ctx, account, nil)
+}
+
+func (c *accountChain) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return uint64(len(c.sent)), nil
+}
+
+func (c *accountChain) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return c.PendingNonceAt(ctx, account)
+}
+
+func (c *accountChain) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return big.NewInt(1e18), nil
+}
+
+func (c *accountChain) BlockNumber(ctx context.Context) (uint64, error) { return 1, nil }
+
+func (c *accountChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(1), BaseFee: big.NewInt(2e9)}, nil
+}
+
+func (c *accountChain) ChainID(ctx context.Context) (*big.Int, error) { return c.chainID, nil }
+
+func (c *accountChain) SyncProgress(ctx context.Context) (*eth.SyncProgress, error) { return nil, nil }
+
+func (c *accountChain) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(3e9), nil
+}
+
+func (c *accountChain) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(1e9), nil
+}
+
+func (c *accountChain) EstimateGas(ctx context.Context, call eth.CallMsg) (uint64, error) {
+	return 100000, nil
+}
+
+func (c *accountChain) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	return nil, false, eth.NotFound
+}
+
+func (c *accountChain) FilterLogs(ctx context.Context, query eth.FilterQuery) ([]types.Log, error) {
+	return nil, nil
+}
+
+func (c *accountChain) SubscribeFilterLogs(ctx context.Context, query eth.FilterQuery, ch chan<- types.Log) (eth.Subscription, error) {
+	return nil, errors.New("subscriptions not supported")
+}
+
+func (c *accountChain) Close() {}
+
+// newBundlerTestWallet 创建连接模拟链的钱包，bundler为cmd/bundler使用的LocalBundler
+func newBundlerTestWallet(t *testing.T) (*BaseETHWallet, *accountChain, *LocalBundler) {
+	chain := newAccountChain()
+
+	bundlerKey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundler, err := NewLocalBundler(chain, chain.chainID, bundlerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(bundler)
+	t.Cleanup(func() {
+		server.Close()
+		bundler.Close()
+	})
+
+	w := newBaseETHWallet("dev", chain.chainID, "test-encryption-key", func() (ChainClient, *RPCPool, error) {
+		return chain, nil, nil
+	})
+	w.eip1559 = true
+	w.bundlerURL = server.URL
+	t.Cleanup(w.Close)
+	if !w.checkConnection() {
+		t.Fatal("wallet failed to connect to the mock chain")
+	}
+	return w, chain, bundler
+}
+
+func importTestKey(t *testing.T, w *BaseETHWallet) (string, common.Address) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	walletID, err := w.ImportFromPrivateKey(hexutil.Encode(crypto.FromECDSA(key))[2:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return walletID, crypto.PubkeyToAddress(key.PublicKey)
+}
+
+func TestUserOperationHash(t *testing.T) {
+	w := newBaseETHWallet("dev", big.NewInt(testAccountChainID), "test-encryption-key", func() (ChainClient, *RPCPool, error) {
+		return newAccountChain(), nil, nil
+	})
+	defer w.Close()
+
+	sender := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	paymaster := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	op := &wallet.UserOperation{
+		Sender:                        sender.Hex(),
+		Nonce:                         big.NewInt(5),
+		Factory:                       SimpleAccountFactoryAddress,
+		FactoryData:                   "0xdeadbeef",
+		CallData:                      "0x1234",
+		CallGasLimit:                  big.NewInt(100000),
+		VerificationGasLimit:          big.NewInt(200000),
+		PreVerificationGas:            big.NewInt(50000),
+		MaxFeePerGas:                  big.NewInt(3e9),
+		MaxPriorityFeePerGas:          big.NewInt(1e9),
+		Paymaster:                     paymaster.Hex(),
+		PaymasterVerificationGasLimit: big.NewInt(30000),
+		PaymasterPostOpGasLimit:       big.NewInt(20000),
+		PaymasterData:                 "0xabcd",
+	}
+
+	// 按EntryPoint v0.7的getUserOpHash逐字段拼接：各字段均为32字节静态类型，abi.encode即依次拼接
+	word := func(v *big.Int) []byte { return common.LeftPadBytes(v.Bytes(), 32) }
+	pair := func(high, low int64) []byte {
+		return append(common.LeftPadBytes(big.NewInt(high).Bytes(), 16), common.LeftPadBytes(big.NewInt(low).Bytes(), 16)...)
+	}
+	initCode := append(common.HexToAddress(SimpleAccountFactoryAddress).Bytes(), 0xde, 0xad, 0xbe, 0xef)
+	paymasterAndData := append(append(paymaster.Bytes(), pair(30000, 20000)...), 0xab, 0xcd)
+
+	var inner []byte
+	inner = append(inner, common.LeftPadBytes(sender.Bytes(), 32)...)
+	inner = append(inner, word(big.NewInt(5))...)
+	inner = append(inner, crypto.Keccak256(initCode)...)
+	inner = append(inner, crypto.Keccak256([]byte{0x12, 0x34})...)
+	inner = append(inner, pair(200000, 100000)...)
+	inner = append(inner, word(big.NewInt(50000))...)
+	inner = append(inner, pair(1e9, 3e9)...)
+	inner = append(inner, crypto.Keccak256(paymasterAndData)...)
+
+	var outer []byte
+	outer = append(outer, crypto.Keccak256(inner)...)
+	outer = append(outer, common.LeftPadBytes(common.HexToAddress(EntryPointAddress).Bytes(), 32)...)
+	outer = append(outer, word(big.NewInt(testAccountChainID))...)
+	want := crypto.Keccak256Hash(outer).Hex()
+
+	got, err := w.UserOperationHash(op)
+	if err != nil {
+		t.Fatalf("UserOperationHash: %v", err)
+	}
+	if got != want {
+		t.Fatalf("UserOperationHash = %s, want %s", got, want)
+	}
+
+	// 签名不参与哈希
+	op.Signature = hexutil.Encode(make([]byte, 65))
+	if got, _ := w.UserOperationHash(op); got != want {
+		t.Fatal("signature must not affect the user operation hash")
+	}
+
+	// 其他链上同一UserOperation的哈希不同，防止跨链重放
+	other := newBaseETHWallet("dev", big.NewInt(1), "test-encryption-key", func() (ChainClient, *RPCPool, error) {
+		return newAccountChain(), nil, nil
+	})
+	defer other.Close()
+	if got, _ := other.UserOperationHash(op); got == want {
+		t.Fatal("user operation hash must depend on chain id")
+	}
+}
+
+func TestPackUint128PairRejectsOverflow(t *testing.T) {
+	tooLarge := (*hexutil.Big)(new(big.Int).Lsh(big.NewInt(1), 128))
+	if _, err := packUint128Pair(tooLarge, (*hexutil.Big)(big.NewInt(1))); err == nil {
+		t.Fatal("expected error for gas value above uint128")
+	}
+}
+
+func TestSmartAccountCallData(t *testing.T) {
+	accountABI := mustParseABI(simpleAccountABI)
+	target := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	single, err := smartAccountCallData([]wallet.SmartAccountCall{{To: target.Hex(), Value: big.NewInt(7), Data: "0x01"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(single[:4], accountABI.Methods["execute"].ID) {
+		t.Fatal("single call must use execute")
+	}
+
+	batch, err := smartAccountCallData([]wallet.SmartAccountCall{{To: target.Hex()}, {To: target.Hex(), Data: "0x02"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(batch[:4], accountABI.Methods["executeBatch"].ID) {
+		t.Fatal("multiple calls must use executeBatch")
+	}
+
+	if _, err := smartAccountCallData(nil); err == nil {
+		t.Fatal("expected error for empty calls")
+	}
+}
+
+func TestDecodeUserOperationCalls(t *testing.T) {
+	w := &BaseETHWallet{}
+	target := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	token := common.HexToAddress("0x4444444444444444444444444444444444444444")
+
+	for _, calls := range [][]wallet.SmartAccountCall{
+		{{To: target.Hex(), Value: big.NewInt(7), Data: "0x01"}},
+		{{To: target.Hex(), Value: big.NewInt(0), Data: "0x"}, {To: token.Hex(), Value: big.NewInt(0), Data: "0xa9059cbb"}},
+	} {
+		callData, err := smartAccountCallData(calls)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := w.DecodeUserOperationCalls(&wallet.UserOperation{CallData: hexutil.Encode(callData)})
+		if err != nil {
+			t.Fatalf("DecodeUserOperationCalls: %v", err)
+		}
+		if len(decoded) != len(calls) {
+			t.Fatalf("decoded %d calls, want %d", len(decoded), len(calls))
+		}
+		for i, call := range calls {
+			if decoded[i].To != call.To || decoded[i].Value.Cmp(call.Value) != 0 || decoded[i].Data != call.Data {
+				t.Fatalf("call %d = %+v, want %+v", i, decoded[i], call)
+			}
+		}
+	}
+
+	// owner()等非执行调用无法按策略校验
+	owner := hexutil.Encode(mustParseABI(simpleAccountABI).Methods["owner"].ID)
+	if _, err := w.DecodeUserOperationCalls(&wallet.UserOperation{CallData: owner}); !errors.Is(err, wallet.ErrInvalidTransaction) {
+		t.Fatalf("expected ErrInvalidTransaction, got %v", err)
+	}
+}
+
+func TestUserOperationThroughLocalBundler(t *testing.T) {
+	ctx := context.Background()
+	w, chain, bundler := newBundlerTestWallet(t)
+	walletID, owner := importTestKey(t, w)
+	target := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	account, err := w.GetSmartAccount(ctx, owner.Hex(), big.NewInt(0))
+	if err != nil {
+		t.Fatalf("GetSmartAccount: %v", err)
+	}
+	if account.Deployed || account.Address != counterfactualAddress(owner, big.NewInt(0)).Hex() {
+		t.Fatalf("unexpected account: %+v", account)
+	}
+
+	// 首个UserOperation附带工厂部署数据，gas由bundler估算
+	op, err := w.BuildUserOperation(ctx, owner.Hex(), big.NewInt(0), []wallet.SmartAccountCall{{To: target.Hex(), Value: big.NewInt(1)}}, nil)
+	if err != nil {
+		t.Fatalf("BuildUserOperation: %v", err)
+	}
+	if !strings.EqualFold(op.Factory, SimpleAccountFactoryAddress) || op.FactoryData == "" || op.Signature != "" {
+		t.Fatalf("unexpected user operation: %+v", op)
+	}
+	if op.VerificationGasLimit.Int64() != localVerificationGas+localDeploymentGas || op.PreVerificationGas.Sign() <= 0 {
+		t.Fatalf("unexpected gas estimate: verification %s preVerification %s", op.VerificationGasLimit, op.PreVerificationGas)
+	}
+
+	hash, err := w.UserOperationHash(op)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := w.SignUserOperation(ctx, walletID, op)
+	if err != nil {
+		t.Fatalf("SignUserOperation: %v", err)
+	}
+
+	// 所有者按EIP-191签名userOpHash
+	signature, err := hexutil.Decode(signed.Signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := recoverSigner(accounts.TextHash(common.HexToHash(hash).Bytes()), signature)
+	if err != nil || signer != owner {
+		t.Fatalf("signature recovers to %s, want %s", signer.Hex(), owner.Hex())
+	}
+
+	submitted, err := w.SendUserOperation(ctx, signed)
+	if err != nil {
+		t.Fatalf("SendUserOperation: %v", err)
+	}
+	if submitted != hash {
+		t.Fatalf("bundler returned %s, want %s", submitted, hash)
+	}
+
+	// bundler以自己的账户发送handleOps交易
+	if len(chain.sent) != 1 {
+		t.Fatalf("expected one handleOps transaction, got %d", len(chain.sent))
+	}
+	sender, err := types.Sender(types.LatestSignerForChainID(chain.chainID), chain.sent[0])
+	if err != nil || sender != bundler.Address() {
+		t.Fatalf("handleOps sent by %s, want bundler %s", sender.Hex(), bundler.Address().Hex())
+	}
+
+	receipt, err := w.GetUserOperationReceipt(ctx, hash)
+	if err != nil {
+		t.Fatalf("GetUserOperationReceipt: %v", err)
+	}
+	if receipt == nil || !receipt.Success || receipt.TxHash != chain.sent[0].Hash().Hex() || receipt.Sender != account.Address {
+		t.Fatalf("unexpected receipt: %+v", receipt)
+	}
+
+	// 重复提交同一UserOperation被拒绝
+	if _, err := w.SendUserOperation(ctx, signed); !errors.Is(err, wallet.ErrInvalidTransaction) {
+		t.Fatalf("expected ErrInvalidTransaction for replay, got %v", err)
+	}
+
+	// 账户已部署，后续UserOperation不带工厂数据，nonce递增，所有者取自账户合约
+	next, err := w.BuildUserOperation(ctx, owner.Hex(), big.NewInt(0), []wallet.SmartAccountCall{{To: target.Hex()}, {To: target.Hex(), Data: "0x01"}}, nil)
+	if err != nil {
+		t.Fatalf("BuildUserOperation: %v", err)
+	}
+	if next.Factory != "" || next.Nonce.Int64() != 1 {
+		t.Fatalf("unexpected second user operation: %+v", next)
+	}
+	otherID, _ := importTestKey(t, w)
+	if _, err := w.SignUserOperation(ctx, otherID, next); !errors.Is(err, wallet.ErrInvalidTransaction) {
+		t.Fatalf("expected ErrInvalidTransaction signing for a deployed account of another owner, got %v", err)
+	}
+	signedNext, err := w.SignUserOperation(ctx, walletID, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.SendUserOperation(ctx, signedNext); err != nil {
+		t.Fatalf("SendUserOperation: %v", err)
+	}
+	if len(chain.executed) != 2 {
+		t.Fatalf("expected two executed user operations, got %d", len(chain.executed))
+	}
+}
+
+func TestLocalBundlerRejectsInvalidUserOperation(t *testing.T) {
+	ctx := context.Background()
+	w, chain, _ := newBundlerTestWallet(t)
+	walletID, owner := importTestKey(t, w)
+	otherID, _ := importTestKey(t, w)
+	target := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	op, err := w.BuildUserOperation(ctx, owner.Hex(), nil, []wallet.SmartAccountCall{{To: target.Hex()}}, nil)
+	if err != nil {
+		t.Fatalf("BuildUserOperation: %v", err)
+	}
+
+	// 未签名
+	if _, err := w.SendUserOperation(ctx, op); !errors.Is(err, wallet.ErrInvalidTransaction) {
+		t.Fatalf("expected ErrInvalidTransaction for unsigned operation, got %v", err)
+	}
+
+	// 钱包不签名其他所有者的账户，也不签名sender与工厂数据不符的UserOperation
+	if _, err := w.SignUserOperation(ctx, otherID, op); !errors.Is(err, wallet.ErrInvalidTransaction) {
+		t.Fatalf("expected ErrInvalidTransaction signing another owner's account, got %v", err)
+	}
+	swapped := *op
+	swapped.Sender = target.Hex()
+	if _, err := w.SignUserOperation(ctx, walletID, &swapped); !errors.Is(err, wallet.ErrInvalidTransaction) {
+		t.Fatalf("expected ErrInvalidTransaction for a sender that is not the wallet's account, got %v", err)
+	}
+
+	// 非所有者签名
+	hash, err := w.UserOperationHash(op)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSigner, err := w.getSigner(ctx, otherID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSignature, err := otherSigner.SignText(ctx, common.HexToHash(hash).Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := *op
+	forged.Signature = hexutil.Encode(otherSignature)
+	if _, err := w.SendUserOperation(ctx, &forged); !errors.Is(err, wallet.ErrInvalidTransaction) {
+		t.Fatalf("expected ErrInvalidTransaction for wrong signer, got %v", err)
+	}
+
+	// 签名后修改了callData
+	signed, err := w.SignUserOperation(ctx, walletID, op)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := *signed
+	tampered.CallData = hexutil.Encode(append(hexutil.MustDecode(signed.CallData), 0))
+	if _, err := w.SendUserOperation(ctx, &tampered); !errors.Is(err, wallet.ErrInvalidTransaction) {
+		t.Fatalf("expected ErrInvalidTransaction for tampered operation, got %v", err)
+	}
+
+	// 错误的nonce
+	stale := *signed
+	stale.Nonce = big.NewInt(3)
+	if stale, err := w.SignUserOperation(ctx, walletID, &stale); err != nil {
+		t.Fatal(err)
+	} else if _, err := w.SendUserOperation(ctx, stale); !errors.Is(err, wallet.ErrInvalidTransaction) {
+		t.Fatalf("expected ErrInvalidTransaction for invalid nonce, got %v", err)
+	}
+
+	if len(chain.sent) != 0 {
+		t.Fatalf("bundler must not submit invalid operations, sent %d", len(chain.sent))
+	}
+}
+
+func TestUserOperationBundlerUnavailable(t *testing.T) {
+	ctx := context.Background()
+	w, _, _ := newBundlerTestWallet(t)
+	walletID, owner := importTestKey(t, w)
+
+	op, err := w.BuildUserOperation(ctx, owner.Hex(), nil, []wallet.SmartAccountCall{{To: owner.Hex()}}, nil)
+	if err != nil {
+		t.Fatalf("BuildUserOperation: %v", err)
+	}
+	signed, err := w.SignUserOperation(ctx, walletID, op)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(nil)
+	server.Close()
+	w.bundlerMu.Lock()
+	w.bundler = nil
+	w.bundlerURL = server.URL
+	w.bundlerMu.Unlock()
+
+	if _, err := w.SendUserOperation(ctx, signed); !errors.Is(err, wallet.ErrBundlerUnavailable) {
+		t.Fatalf("expected ErrBundlerUnavailable, got %v", err)
+	}
+
+	w.bundlerURL = ""
+	if _, err := w.SendUserOperation(ctx, signed); !errors.Is(err, wallet.ErrOperationNotSupported) {
+		t.Fatalf("expected ErrOperationNotSupported without bundler, got %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package hd
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// ed25519曲线的SLIP-0010主密钥HMAC密钥
+const ed25519Curve = "ed25519 seed"
+
+// DeriveEd25519FromMnemonic 从助记词按SLIP-0010派生ed25519私钥，如 m/44'/501'/0'/0'
+func DeriveEd25519FromMnemonic(mnemonic string, path string) (ed25519.PrivateKey, error) {
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, errors.New("invalid mnemonic")
+	}
+	return DeriveEd25519FromSeed(bip39.NewSeed(mnemonic, ""), path)
+}
+
+// DeriveEd25519FromSeed 从种子按SLIP-0010派生ed25519私钥，ed25519只支持硬化派生
+func DeriveEd25519FromSeed(seed []byte, path string) (ed25519.PrivateKey, error) {
+	derivationPath, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid derivation path %s: %v", path, err)
+	}
+
+	mac := hmac.New(sha512.New, []byte(ed25519Curve))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := sum[:32], sum[32:]
+
+	for _, index := range derivationPath {
+		if index < hdkeychain.HardenedKeyStart {
+			return nil, fmt.Errorf("ed25519 only supports hardened derivation: %s", path)
+		}
+
+		data := make([]byte, 0, 37)
+		data = append(data, 0x00)
+		data = append(data, key...)
+		data = binary.BigEndian.AppendUint32(data, index)
+
+		mac = hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		sum = mac.Sum(nil)
+		key, chainCode = sum[:32], sum[32:]
+	}
+
+	return ed25519.NewKeyFromSeed(key), nil
+}
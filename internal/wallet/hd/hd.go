@@ -0,0 +1,43 @@
+// Package hd 提供各链共用的BIP32分层确定性密钥派生
+package hd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// DeriveFromMnemonic 从助记词按BIP32路径派生扩展私钥，如 m/44'/60'/0'/0/0
+func DeriveFromMnemonic(mnemonic string, path string) (*hdkeychain.ExtendedKey, error) {
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, errors.New("invalid mnemonic")
+	}
+	return DeriveFromSeed(bip39.NewSeed(mnemonic, ""), path)
+}
+
+// DeriveFromSeed 从种子按BIP32路径派生扩展私钥
+func DeriveFromSeed(seed []byte, path string) (*hdkeychain.ExtendedKey, error) {
+	derivationPath, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid derivation path %s: %v", path, err)
+	}
+
+	// 派生结果与网络无关，网络参数只影响扩展密钥的序列化前缀
+	key, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, index := range derivationPath {
+		key, err = key.Derive(index)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return key, nil
+}
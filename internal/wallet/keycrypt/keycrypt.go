@@ -0,0 +1,63 @@
+// Package keycrypt 提供各链钱包共用的私钥、助记词加解密
+package keycrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+)
+
+// DeriveKey 将配置中的加密密钥转换为固定长度的AES-256密钥
+// 实际应用中应该使用更安全的密钥派生和存储方式
+func DeriveKey(encryptionKey string) []byte {
+	key := sha256.Sum256([]byte(encryptionKey))
+	return key[:]
+}
+
+// Encrypt 使用AES-CFB加密数据，返回hex编码的 IV+密文
+func Encrypt(key []byte, data []byte) (string, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext := make([]byte, aes.BlockSize+len(data))
+	iv := ciphertext[:aes.BlockSize]
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return "", err
+	}
+
+	stream := cipher.NewCFBEncrypter(block, iv)
+	stream.XORKeyStream(ciphertext[aes.BlockSize:], data)
+
+	return hex.EncodeToString(ciphertext), nil
+}
+
+// Decrypt 解密Encrypt生成的数据
+func Decrypt(key []byte, encryptedHex string) ([]byte, error) {
+	ciphertext, err := hex.DecodeString(encryptedHex)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < aes.BlockSize {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	iv := ciphertext[:aes.BlockSize]
+	ciphertext = ciphertext[aes.BlockSize:]
+
+	stream := cipher.NewCFBDecrypter(block, iv)
+	stream.XORKeyStream(ciphertext, ciphertext)
+
+	return ciphertext, nil
+}
@@ -4,21 +4,95 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sort"
 	"time"
 )
 
 // Manager 钱包管理器
 type Manager struct {
-	wallets map[ChainType]Wallet
+	wallets   map[ChainType]Wallet
+	chains    map[ChainType]ChainInfo
+	factories map[ChainKind]WalletFactory
 }
 
 // NewManager 创建新的钱包管理器
 func NewManager() *Manager {
 	return &Manager{
-		wallets: make(map[ChainType]Wallet),
+		wallets:   make(map[ChainType]Wallet),
+		chains:    make(map[ChainType]ChainInfo),
+		factories: make(map[ChainKind]WalletFactory),
 	}
 }
 
+// RegisterFactory 注册某类链的钱包工厂
+func (m *Manager) RegisterFactory(kind ChainKind, factory WalletFactory) {
+	m.factories[kind] = factory
+}
+
+// LoadChains 按链注册表为每条链创建并注册钱包
+func (m *Manager) LoadChains(chains []ChainInfo) error {
+	for _, chain := range chains {
+		factory, exists := m.factories[chain.Kind]
+		if !exists {
+			return fmt.Errorf("%w: no factory for chain kind %q (%s)", ErrUnsupportedChain, chain.Kind, chain.ChainType)
+		}
+
+		wallet, err := factory(chain)
+		if err != nil {
+			return fmt.Errorf("failed to create wallet for %s: %v", chain.ChainType, err)
+		}
+
+		m.chains[chain.ChainType] = chain
+		m.RegisterWallet(wallet)
+	}
+	return nil
+}
+
+// GetChainInfo 获取链信息
+func (m *Manager) GetChainInfo(chainType ChainType) (ChainInfo, bool) {
+	chain, exists := m.chains[chainType]
+	return chain, exists
+}
+
+// ListChains 获取注册表中的所有链，按链标识排序
+func (m *Manager) ListChains() []ChainInfo {
+	chains := make([]ChainInfo, 0, len(m.chains))
+	for _, chain := range m.chains {
+		chains = append(chains, chain)
+	}
+	sort.Slice(chains, func(i, j int) bool {
+		return chains[i].ChainType < chains[j].ChainType
+	})
+	return chains
+}
+
+// GetChainStatus 获取链的连接状态，未实现StatusReporter的钱包视为已连接
+func (m *Manager) GetChainStatus(chainType ChainType) (ChainStatusInfo, bool) {
+	wallet, exists := m.wallets[chainType]
+	if !exists {
+		return ChainStatusInfo{}, false
+	}
+
+	if reporter, ok := wallet.(StatusReporter); ok {
+		return reporter.Status(), true
+	}
+
+	return ChainStatusInfo{ChainType: chainType, Status: ChainStatusConnected}, true
+}
+
+// ListChainStatus 获取所有链的连接状态，按链标识排序
+func (m *Manager) ListChainStatus() []ChainStatusInfo {
+	statuses := make([]ChainStatusInfo, 0, len(m.wallets))
+	for chainType := range m.wallets {
+		status, _ := m.GetChainStatus(chainType)
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ChainType < statuses[j].ChainType
+	})
+	return statuses
+}
+
 // RegisterWallet 注册钱包
 func (m *Manager) RegisterWallet(wallet Wallet) {
 	fmt.Printf("Registering wallet for chain type: %s\n", wallet.ChainType())
@@ -118,7 +192,7 @@ func (m *Manager) GetTokenBalance(ctx context.Context, chainType ChainType, addr
 }
 
 // CreateTransaction 创建交易
-func (m *Manager) CreateTransaction(ctx context.Context, chainType ChainType, from string, to string, amount *big.Int, data []byte) ([]byte, error) {
+func (m *Manager) CreateTransaction(ctx context.Context, chainType ChainType, from string, to string, amount *big.Int, data []byte) (*UnsignedTx, error) {
 	wallet, exists := m.wallets[chainType]
 	if !exists {
 		return nil, ErrUnsupportedChain
@@ -127,21 +201,57 @@ func (m *Manager) CreateTransaction(ctx context.Context, chainType ChainType, fr
 }
 
 // SignTransaction 签名交易
-func (m *Manager) SignTransaction(ctx context.Context, chainType ChainType, walletID string, txJSON []byte) ([]byte, error) {
+func (m *Manager) SignTransaction(ctx context.Context, chainType ChainType, walletID string, tx *UnsignedTx) (*SignedTx, error) {
 	wallet, exists := m.wallets[chainType]
 	if !exists {
 		return nil, ErrUnsupportedChain
 	}
-	return wallet.SignTransaction(ctx, walletID, txJSON)
+	return wallet.SignTransaction(ctx, walletID, tx)
 }
 
 // SendTransaction 发送交易
-func (m *Manager) SendTransaction(ctx context.Context, chainType ChainType, signedTxJSON []byte) (string, error) {
+func (m *Manager) SendTransaction(ctx context.Context, chainType ChainType, signedTx *SignedTx) (string, error) {
 	wallet, exists := m.wallets[chainType]
 	if !exists {
 		return "", ErrUnsupportedChain
 	}
-	return wallet.SendTransaction(ctx, signedTxJSON)
+	return wallet.SendTransaction(ctx, signedTx)
+}
+
+// SignMessage 签名消息
+func (m *Manager) SignMessage(ctx context.Context, chainType ChainType, walletID string, message []byte) ([]byte, error) {
+	wallet, exists := m.wallets[chainType]
+	if !exists {
+		return nil, ErrUnsupportedChain
+	}
+	return wallet.SignMessage(ctx, walletID, message)
+}
+
+// SignTypedData 签名结构化数据
+func (m *Manager) SignTypedData(ctx context.Context, chainType ChainType, walletID string, typedDataJSON []byte) ([]byte, error) {
+	wallet, exists := m.wallets[chainType]
+	if !exists {
+		return nil, ErrUnsupportedChain
+	}
+	return wallet.SignTypedData(ctx, walletID, typedDataJSON)
+}
+
+// VerifyMessage 校验消息签名
+func (m *Manager) VerifyMessage(ctx context.Context, chainType ChainType, expectedSigner string, message []byte, signature []byte) (*SignatureVerification, error) {
+	wallet, exists := m.wallets[chainType]
+	if !exists {
+		return nil, ErrUnsupportedChain
+	}
+	return wallet.VerifyMessage(ctx, expectedSigner, message, signature)
+}
+
+// VerifyTypedData 校验结构化数据签名
+func (m *Manager) VerifyTypedData(ctx context.Context, chainType ChainType, expectedSigner string, typedDataJSON []byte, signature []byte) (*SignatureVerification, error) {
+	wallet, exists := m.wallets[chainType]
+	if !exists {
+		return nil, ErrUnsupportedChain
+	}
+	return wallet.VerifyTypedData(ctx, expectedSigner, typedDataJSON, signature)
 }
 
 // GetTransactionStatus 获取交易状态
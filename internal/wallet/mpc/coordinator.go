@@ -0,0 +1,303 @@
+package mpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNodeUnavailable 签名节点不可达
+	ErrNodeUnavailable = errors.New("signer node unavailable")
+	// ErrNotEnoughNodes 可用的签名节点不足门限
+	ErrNotEnoughNodes = errors.New("not enough signer nodes available")
+)
+
+// Key 门限密钥的元数据，协调方不持有任何分片
+type Key struct {
+	ID        string        `json:"id"`
+	Nodes     []string      `json:"nodes"` // 节点地址，第i个节点的分片序号为i+1
+	Threshold int           `json:"threshold"`
+	PublicKey hexutil.Bytes `json:"publicKey"`
+}
+
+// Coordinator 驱动密钥生成和签名协议，在各节点之间转发消息
+type Coordinator struct {
+	client *http.Client
+}
+
+// NewCoordinator 创建协调方
+func NewCoordinator() *Coordinator {
+	return &Coordinator{client: &http.Client{Timeout: 2 * time.Minute}}
+}
+
+// Keygen 在nodeURLs上生成t-of-n门限密钥，所有节点都必须在线
+func (c *Coordinator) Keygen(ctx context.Context, nodeURLs []string, threshold int) (*Key, error) {
+	if threshold < 2 || threshold > len(nodeURLs) {
+		return nil, fmt.Errorf("threshold must be between 2 and %d", len(nodeURLs))
+	}
+	nodes := make([]string, len(nodeURLs))
+	seen := make(map[string]bool, len(nodeURLs))
+	for i, nodeURL := range nodeURLs {
+		nodes[i] = strings.TrimRight(nodeURL, "/")
+		if seen[nodes[i]] {
+			return nil, fmt.Errorf("duplicate signer node %s", nodes[i])
+		}
+		seen[nodes[i]] = true
+	}
+
+	parties := make(map[int]hexutil.Bytes, len(nodes))
+	infos, err := each(nodes, func(i int, node string) (*NodeInfo, error) {
+		var info NodeInfo
+		return &info, c.call(ctx, http.MethodGet, node+"/info", nil, &info)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, info := range infos {
+		parties[i+1] = info.IdentityKey
+	}
+
+	keyID := uuid.New().String()
+	sessionID := uuid.New().String()
+	round1, err := each(nodes, func(i int, node string) (*KeygenRound1Response, error) {
+		var resp KeygenRound1Response
+		return &resp, c.call(ctx, http.MethodPost, node+"/keygen/round1", &KeygenRound1Request{
+			SessionID: sessionID,
+			KeyID:     keyID,
+			Index:     i + 1,
+			Threshold: threshold,
+			Parties:   parties,
+		}, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	round2Req := &KeygenRound2Request{
+		SessionID: sessionID,
+		Round1:    make(map[int]*KeygenRound1Response, len(nodes)),
+	}
+	for i, resp := range round1 {
+		round2Req.Round1[i+1] = resp
+	}
+	round2, err := each(nodes, func(i int, node string) (*KeygenRound2Response, error) {
+		var resp KeygenRound2Response
+		return &resp, c.call(ctx, http.MethodPost, node+"/keygen/round2", round2Req, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, resp := range round2[1:] {
+		if !bytes.Equal(resp.PublicKey, round2[0].PublicKey) {
+			return nil, errors.New("signer nodes derived different public keys")
+		}
+	}
+	return &Key{
+		ID:        keyID,
+		Nodes:     nodes,
+		Threshold: threshold,
+		PublicKey: round2[0].PublicKey,
+	}, nil
+}
+
+// Sign 用门限密钥签名32字节哈希，返回65字节r||s||v（v取值0/1，s已规范为低位）。
+// 任选threshold个在线节点参与，离线节点不影响签名
+func (c *Coordinator) Sign(ctx context.Context, key *Key, hash []byte) ([]byte, error) {
+	if len(hash) != 32 {
+		return nil, errors.New("hash must be 32 bytes")
+	}
+	publicKey, err := crypto.DecompressPubkey(key.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid threshold public key: %v", err)
+	}
+
+	// 第一轮发给所有节点，取最先序号的threshold个成功者参与后续轮次
+	sessionID := uuid.New().String()
+	round1 := make([]*SignRound1Response, len(key.Nodes))
+	errs := make([]error, len(key.Nodes))
+	var wg sync.WaitGroup
+	for i, node := range key.Nodes {
+		wg.Add(1)
+		go func(i int, node string) {
+			defer wg.Done()
+			var resp SignRound1Response
+			if errs[i] = c.call(ctx, http.MethodPost, node+"/sign/round1", &SignRound1Request{
+				SessionID: sessionID,
+				KeyID:     key.ID,
+				Hash:      hash,
+			}, &resp); errs[i] == nil {
+				round1[i] = &resp
+			}
+		}(i, node)
+	}
+	wg.Wait()
+
+	var parties []int
+	var nodes []string
+	for i, resp := range round1 {
+		if resp != nil && len(parties) < key.Threshold {
+			parties = append(parties, i+1)
+			nodes = append(nodes, key.Nodes[i])
+		}
+	}
+	if len(parties) < key.Threshold {
+		return nil, fmt.Errorf("%w: %d of %d required: %v", ErrNotEnoughNodes, len(parties), key.Threshold, errors.Join(errs...))
+	}
+
+	round1Outputs := make(map[int]*SignRound1Response, len(parties))
+	for _, index := range parties {
+		round1Outputs[index] = round1[index-1]
+	}
+	round2, err := each(nodes, func(i int, node string) (*SignRound2Response, error) {
+		var resp SignRound2Response
+		return &resp, c.call(ctx, http.MethodPost, node+"/sign/round2", &SignRound2Request{
+			SessionID: sessionID,
+			Parties:   parties,
+			Round1:    round1Outputs,
+		}, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	round3, err := each(nodes, func(i int, node string) (*SignRound3Response, error) {
+		mta := make(map[int]*MtAResponse, len(parties)-1)
+		for j, resp := range round2 {
+			if j != i {
+				mta[parties[j]] = resp.MtA[parties[i]]
+			}
+		}
+		var resp SignRound3Response
+		return &resp, c.call(ctx, http.MethodPost, node+"/sign/round3", &SignRound3Request{
+			SessionID: sessionID,
+			MtA:       mta,
+		}, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	round4Req := &SignRound4Request{
+		SessionID: sessionID,
+		Round3:    make(map[int]*SignRound3Response, len(parties)),
+	}
+	for i, resp := range round3 {
+		round4Req.Round3[parties[i]] = resp
+	}
+	round4, err := each(nodes, func(i int, node string) (*SignRound4Response, error) {
+		var resp SignRound4Response
+		return &resp, c.call(ctx, http.MethodPost, node+"/sign/round4", round4Req, &resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// s = sum s_i
+	s := new(big.Int)
+	for _, resp := range round4 {
+		if !bytes.Equal(resp.R, round4[0].R) {
+			return nil, errors.New("signer nodes computed different R")
+		}
+		if resp.S == nil {
+			return nil, errors.New("signer node returned empty partial signature")
+		}
+		s.Add(s, resp.S)
+	}
+	s.Mod(s, curveN)
+	R, err := decodePoint(round4[0].R)
+	if err != nil {
+		return nil, err
+	}
+	r := pointX(R)
+	r.Mod(r, curveN)
+	return assembleSignature(hash, r, s, crypto.FromECDSAPub(publicKey))
+}
+
+// assembleSignature 规范为低位s，通过公钥恢复确定v，并校验签名
+func assembleSignature(hash []byte, r, s *big.Int, publicKey []byte) ([]byte, error) {
+	halfN := new(big.Int).Rsh(curveN, 1)
+	if s.Cmp(halfN) > 0 {
+		s = new(big.Int).Sub(curveN, s)
+	}
+
+	signature := make([]byte, crypto.SignatureLength)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:64])
+	for v := byte(0); v < 2; v++ {
+		signature[crypto.RecoveryIDOffset] = v
+		recovered, err := crypto.Ecrecover(hash, signature)
+		if err == nil && bytes.Equal(recovered, publicKey) {
+			return signature, nil
+		}
+	}
+	return nil, errors.New("threshold signature verification failed")
+}
+
+// call 向节点发送JSON请求，网络错误和节点返回的协议错误分别处理
+func (c *Coordinator) call(ctx context.Context, method, url string, req interface{}, resp interface{}) error {
+	var body io.Reader
+	if req != nil {
+		data, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := c.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNodeUnavailable, err)
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(httpResp.Body, maxMessageSize))
+	if err != nil {
+		return err
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		var errResp errorResponse
+		if json.Unmarshal(data, &errResp) == nil && errResp.Error != "" {
+			return fmt.Errorf("signer node %s: %s", url, errResp.Error)
+		}
+		return fmt.Errorf("signer node %s returned status %d", url, httpResp.StatusCode)
+	}
+	return json.Unmarshal(data, resp)
+}
+
+// each 并发地在每个节点上执行一轮协议，任一节点失败则整轮失败
+func each[T any](nodes []string, round func(i int, node string) (*T, error)) ([]*T, error) {
+	results := make([]*T, len(nodes))
+	errs := make([]error, len(nodes))
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		wg.Add(1)
+		go func(i int, node string) {
+			defer wg.Done()
+			results[i], errs[i] = round(i, node)
+		}(i, node)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,99 @@
+package mpc
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+)
+
+// secp256k1的阶
+var curveN = btcec.S256().N
+
+// randomScalar 生成[1, n)内的随机数
+func randomScalar() (*big.Int, error) {
+	for {
+		k, err := rand.Int(rand.Reader, curveN)
+		if err != nil {
+			return nil, err
+		}
+		if k.Sign() != 0 {
+			return k, nil
+		}
+	}
+}
+
+func toModNScalar(k *big.Int) *btcec.ModNScalar {
+	var s btcec.ModNScalar
+	s.SetByteSlice(new(big.Int).Mod(k, curveN).FillBytes(make([]byte, 32)))
+	return &s
+}
+
+// scalarBaseMult 计算k*G
+func scalarBaseMult(k *big.Int) *btcec.JacobianPoint {
+	var result btcec.JacobianPoint
+	btcec.ScalarBaseMultNonConst(toModNScalar(k), &result)
+	return &result
+}
+
+// scalarMult 计算k*P
+func scalarMult(k *big.Int, p *btcec.JacobianPoint) *btcec.JacobianPoint {
+	var result btcec.JacobianPoint
+	btcec.ScalarMultNonConst(toModNScalar(k), p, &result)
+	return &result
+}
+
+// addPoints 计算P+Q
+func addPoints(p, q *btcec.JacobianPoint) *btcec.JacobianPoint {
+	var result btcec.JacobianPoint
+	btcec.AddNonConst(p, q, &result)
+	return &result
+}
+
+// encodePoint 压缩编码曲线点
+func encodePoint(p *btcec.JacobianPoint) ([]byte, error) {
+	point := *p
+	if point.Z.IsZero() {
+		return nil, errors.New("point at infinity")
+	}
+	point.ToAffine()
+	return btcec.NewPublicKey(&point.X, &point.Y).SerializeCompressed(), nil
+}
+
+// decodePoint 解析压缩编码的曲线点
+func decodePoint(data []byte) (*btcec.JacobianPoint, error) {
+	pub, err := btcec.ParsePubKey(data)
+	if err != nil {
+		return nil, err
+	}
+	var result btcec.JacobianPoint
+	pub.AsJacobian(&result)
+	return &result, nil
+}
+
+// pointX 返回曲线点的仿射x坐标
+func pointX(p *btcec.JacobianPoint) *big.Int {
+	point := *p
+	point.ToAffine()
+	x := point.X.Bytes()
+	return new(big.Int).SetBytes(x[:])
+}
+
+// lagrangeCoefficient 计算参与方index在parties集合中于0点处的拉格朗日系数
+func lagrangeCoefficient(index int, parties []int) *big.Int {
+	num := big.NewInt(1)
+	den := big.NewInt(1)
+	xi := big.NewInt(int64(index))
+	for _, j := range parties {
+		if j == index {
+			continue
+		}
+		xj := big.NewInt(int64(j))
+		num.Mul(num, xj)
+		num.Mod(num, curveN)
+		den.Mul(den, new(big.Int).Sub(xj, xi))
+		den.Mod(den, curveN)
+	}
+	return num.Mul(num, den.ModInverse(den, curveN)).Mod(num, curveN)
+}
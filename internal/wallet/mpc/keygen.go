@@ -0,0 +1,320 @@
+package mpc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/ecies"
+)
+
+// keygenSession 密钥生成会话，round1生成的多项式在round2结束后丢弃
+type keygenSession struct {
+	keyID      string
+	index      int
+	threshold  int
+	parties    []int
+	identities map[int]hexutil.Bytes
+	coeffs     []*big.Int
+	paillier   *PaillierPrivateKey
+	created    time.Time
+}
+
+// KeygenRound1 生成t-1次随机多项式（Feldman VSS），向每个节点输出用其身份公钥加密的分片，
+// 同时生成Paillier密钥和环Pedersen参数，并证明其构造正确
+func (n *Node) KeygenRound1(req *KeygenRound1Request) (*KeygenRound1Response, error) {
+	if !idPattern.MatchString(req.SessionID) || !idPattern.MatchString(req.KeyID) {
+		return nil, errors.New("invalid session or key id")
+	}
+	if req.Threshold < 2 || req.Threshold > len(req.Parties) {
+		return nil, fmt.Errorf("invalid threshold %d of %d", req.Threshold, len(req.Parties))
+	}
+	// 确认协调方分配给本节点的序号对应本节点身份
+	if !bytes.Equal(req.Parties[req.Index], n.Info().IdentityKey) {
+		return nil, errors.New("party index does not match node identity")
+	}
+	if err := n.checkTrustedPeers(req.Parties); err != nil {
+		return nil, err
+	}
+	if _, err := n.loadShare(req.KeyID); err == nil {
+		return nil, fmt.Errorf("key share %s already exists", req.KeyID)
+	}
+
+	parties := make([]int, 0, len(req.Parties))
+	for index := range req.Parties {
+		if index < 1 {
+			return nil, fmt.Errorf("invalid party index %d", index)
+		}
+		parties = append(parties, index)
+	}
+
+	coeffs := make([]*big.Int, req.Threshold)
+	commitments := make([]hexutil.Bytes, req.Threshold)
+	for i := range coeffs {
+		coeff, err := randomScalar()
+		if err != nil {
+			return nil, err
+		}
+		commitment, err := encodePoint(scalarBaseMult(coeff))
+		if err != nil {
+			return nil, err
+		}
+		coeffs[i] = coeff
+		commitments[i] = commitment
+	}
+
+	paillier, err := generatePaillierKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate paillier key: %v", err)
+	}
+	ringPedersen, lambda, err := generateRingPedersen(paillier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ring-pedersen parameters: %v", err)
+	}
+	context := keygenContext(req.SessionID, req.KeyID, req.Index)
+	modProof, err := proveModulus(paillier, context)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prove paillier modulus: %v", err)
+	}
+	prmProof, err := proveRingPedersen(ringPedersen, lambda, paillier.Lambda, context)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prove ring-pedersen parameters: %v", err)
+	}
+
+	shares := make(map[int]hexutil.Bytes, len(parties)-1)
+	for _, index := range parties {
+		if index == req.Index {
+			continue
+		}
+		pub, err := crypto.DecompressPubkey(req.Parties[index])
+		if err != nil {
+			return nil, fmt.Errorf("invalid identity key of party %d: %v", index, err)
+		}
+		share := evalPolynomial(coeffs, index)
+		encrypted, err := ecies.Encrypt(rand.Reader, ecies.ImportECDSAPublic(pub), share.FillBytes(make([]byte, 32)), shareContext(req.SessionID, req.Index, index), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt share: %v", err)
+		}
+		shares[index] = encrypted
+	}
+
+	resp := &KeygenRound1Response{
+		Commitments:  commitments,
+		PaillierKey:  &paillier.PaillierPublicKey,
+		RingPedersen: ringPedersen,
+		ModProof:     modProof,
+		PrmProof:     prmProof,
+		Shares:       shares,
+	}
+	if resp.Signature, err = signMessage(n.identity, messageKeygenRound1, context, resp); err != nil {
+		return nil, err
+	}
+
+	n.mu.Lock()
+	n.cleanSessions()
+	n.keygenSessions[req.SessionID] = &keygenSession{
+		keyID:      req.KeyID,
+		index:      req.Index,
+		threshold:  req.Threshold,
+		parties:    parties,
+		identities: req.Parties,
+		coeffs:     coeffs,
+		paillier:   paillier,
+		created:    time.Now(),
+	}
+	n.mu.Unlock()
+
+	return resp, nil
+}
+
+// KeygenRound2 校验各节点第一轮输出的签名和证明，用承诺校验收到的分片，累加得到本节点的密钥分片并保存
+func (n *Node) KeygenRound2(req *KeygenRound2Request) (*KeygenRound2Response, error) {
+	n.mu.Lock()
+	session, ok := n.keygenSessions[req.SessionID]
+	delete(n.keygenSessions, req.SessionID)
+	n.mu.Unlock()
+	if !ok {
+		return nil, errors.New("keygen session not found")
+	}
+
+	commitments := make(map[int][]hexutil.Bytes, len(session.parties))
+	for _, index := range session.parties {
+		if err := verifyKeygenRound1(req.SessionID, session, index, req.Round1[index]); err != nil {
+			return nil, fmt.Errorf("party %d: %v", index, err)
+		}
+		commitments[index] = req.Round1[index].Commitments
+	}
+	if req.Round1[session.index].PaillierKey.N.Cmp(session.paillier.N) != 0 {
+		return nil, errors.New("round1 output of this node was altered")
+	}
+
+	// x_i = sum_j f_j(i)
+	share := evalPolynomial(session.coeffs, session.index)
+	for _, index := range session.parties {
+		if index == session.index {
+			continue
+		}
+
+		encrypted, ok := req.Round1[index].Shares[session.index]
+		if !ok {
+			return nil, fmt.Errorf("missing share from party %d", index)
+		}
+		plain, err := ecies.ImportECDSA(n.identity).Decrypt(encrypted, shareContext(req.SessionID, index, session.index), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt share from party %d: %v", index, err)
+		}
+		received := new(big.Int).SetBytes(plain)
+
+		expected, err := evalCommitments(commitments[index], session.index)
+		if err != nil {
+			return nil, fmt.Errorf("invalid commitments from party %d: %v", index, err)
+		}
+		actual, err := encodePoint(scalarBaseMult(received))
+		if err != nil || !bytes.Equal(actual, expected) {
+			return nil, fmt.Errorf("share from party %d does not match its commitments", index)
+		}
+		share.Add(share, received)
+	}
+	share.Mod(share, curveN)
+
+	// 公钥为各多项式常数项承诺之和，各节点的公开分片为承诺多项式在其序号处的值之和
+	publicKey, err := sumPoints(commitments, session.parties, func(c []hexutil.Bytes) (hexutil.Bytes, error) { return c[0], nil })
+	if err != nil {
+		return nil, err
+	}
+	publicShares := make(map[int]hexutil.Bytes, len(session.parties))
+	for _, index := range session.parties {
+		target := index
+		publicShare, err := sumPoints(commitments, session.parties, func(c []hexutil.Bytes) (hexutil.Bytes, error) {
+			return evalCommitments(c, target)
+		})
+		if err != nil {
+			return nil, err
+		}
+		publicShares[index] = publicShare
+	}
+
+	ownPublicShare, err := encodePoint(scalarBaseMult(share))
+	if err != nil || !bytes.Equal(ownPublicShare, publicShares[session.index]) {
+		return nil, errors.New("key share does not match public share")
+	}
+
+	paillierKeys := make(map[int]*PaillierPublicKey, len(session.parties))
+	ringPedersen := make(map[int]*RingPedersen, len(session.parties))
+	for _, index := range session.parties {
+		paillierKeys[index] = req.Round1[index].PaillierKey
+		ringPedersen[index] = req.Round1[index].RingPedersen
+	}
+	if err := n.saveShare(&KeyShare{
+		KeyID:        session.keyID,
+		Index:        session.index,
+		Threshold:    session.threshold,
+		Share:        share,
+		PublicKey:    publicKey,
+		PublicShares: publicShares,
+		Paillier:     session.paillier,
+		PaillierKeys: paillierKeys,
+		RingPedersen: ringPedersen,
+		Identities:   session.identities,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &KeygenRound2Response{
+		PublicKey:   publicKey,
+		PublicShare: ownPublicShare,
+	}, nil
+}
+
+// verifyKeygenRound1 校验节点第一轮输出的签名、承诺数量、Paillier模数证明和环Pedersen参数证明
+func verifyKeygenRound1(sessionID string, session *keygenSession, index int, resp *KeygenRound1Response) error {
+	if resp == nil {
+		return errors.New("missing round1 output")
+	}
+	context := keygenContext(sessionID, session.keyID, index)
+	unsigned := *resp
+	unsigned.Signature = nil
+	if err := verifyMessage(session.identities[index], messageKeygenRound1, context, &unsigned, resp.Signature); err != nil {
+		return err
+	}
+	if len(resp.Commitments) != session.threshold {
+		return errors.New("invalid commitments")
+	}
+	if resp.PaillierKey == nil || resp.PaillierKey.N == nil || resp.PaillierKey.N.BitLen() < paillierBits-1 {
+		return errors.New("invalid paillier key")
+	}
+	if resp.RingPedersen == nil || resp.RingPedersen.N == nil || resp.RingPedersen.N.Cmp(resp.PaillierKey.N) != 0 {
+		return errors.New("ring-pedersen modulus does not match paillier key")
+	}
+	if index == session.index {
+		return nil
+	}
+	if err := verifyModulus(resp.PaillierKey.N, resp.ModProof, context); err != nil {
+		return err
+	}
+	return verifyRingPedersen(resp.RingPedersen, resp.PrmProof, context)
+}
+
+// keygenContext 密钥生成消息和证明绑定的会话、密钥和发送方
+func keygenContext(sessionID, keyID string, index int) string {
+	return fmt.Sprintf("%s:%s:%d", sessionID, keyID, index)
+}
+
+// evalPolynomial 计算f(x) mod n
+func evalPolynomial(coeffs []*big.Int, x int) *big.Int {
+	result := new(big.Int)
+	xi := big.NewInt(int64(x))
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		result.Mul(result, xi)
+		result.Add(result, coeffs[i])
+		result.Mod(result, curveN)
+	}
+	return result
+}
+
+// evalCommitments 计算sum_k C_k * x^k，即f(x)*G
+func evalCommitments(commitments []hexutil.Bytes, x int) (hexutil.Bytes, error) {
+	xi := big.NewInt(int64(x))
+	power := big.NewInt(1)
+
+	result, err := decodePoint(commitments[0])
+	if err != nil {
+		return nil, err
+	}
+	for k := 1; k < len(commitments); k++ {
+		power.Mul(power, xi).Mod(power, curveN)
+		point, err := decodePoint(commitments[k])
+		if err != nil {
+			return nil, err
+		}
+		result = addPoints(result, scalarMult(power, point))
+	}
+	return encodePoint(result)
+}
+
+// sumPoints 对各参与方按term取出的曲线点求和
+func sumPoints(commitments map[int][]hexutil.Bytes, parties []int, term func([]hexutil.Bytes) (hexutil.Bytes, error)) (hexutil.Bytes, error) {
+	var sum *btcec.JacobianPoint
+	for _, index := range parties {
+		encoded, err := term(commitments[index])
+		if err != nil {
+			return nil, err
+		}
+		point, err := decodePoint(encoded)
+		if err != nil {
+			return nil, err
+		}
+		if sum == nil {
+			sum = point
+		} else {
+			sum = addPoints(sum, point)
+		}
+	}
+	return encodePoint(sum)
+}
@@ -0,0 +1,167 @@
+package mpc
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// maxMessageSize 协议消息的大小上限，密钥生成第二轮包含所有节点的模数证明
+const maxMessageSize = 16 << 20
+
+// 节点签名的协议消息类型
+const (
+	messageKeygenRound1 = "keygen/round1"
+	messageSignRound1   = "sign/round1"
+	messageSignMtA      = "sign/mta"
+	messageSignRound3   = "sign/round3"
+)
+
+// NodeInfo 签名节点信息
+type NodeInfo struct {
+	IdentityKey hexutil.Bytes `json:"identityKey"` // 节点身份公钥，密钥生成时用于加密发给该节点的分片
+}
+
+// KeygenRound1Request 密钥生成第一轮：各节点生成随机多项式和Paillier密钥
+type KeygenRound1Request struct {
+	SessionID string                `json:"sessionId"`
+	KeyID     string                `json:"keyId"`
+	Index     int                   `json:"index"` // 本节点序号，从1开始
+	Threshold int                   `json:"threshold"`
+	Parties   map[int]hexutil.Bytes `json:"parties"` // 序号 -> 节点身份公钥
+}
+
+// KeygenRound1Response 多项式系数承诺、Paillier公钥和环Pedersen参数及其证明、发给其他节点的加密分片，
+// 由本节点身份密钥签名
+type KeygenRound1Response struct {
+	Commitments  []hexutil.Bytes       `json:"commitments"`
+	PaillierKey  *PaillierPublicKey    `json:"paillierKey"`
+	RingPedersen *RingPedersen         `json:"ringPedersen"`
+	ModProof     *ModProof             `json:"modProof"`
+	PrmProof     *PrmProof             `json:"prmProof"`
+	Shares       map[int]hexutil.Bytes `json:"shares"` // 接收方序号 -> ECIES加密的分片
+	Signature    hexutil.Bytes         `json:"signature"`
+}
+
+// KeygenRound2Request 密钥生成第二轮：转发所有节点的第一轮输出
+type KeygenRound2Request struct {
+	SessionID string                        `json:"sessionId"`
+	Round1    map[int]*KeygenRound1Response `json:"round1"` // 发送方序号 -> 第一轮输出
+}
+
+// KeygenRound2Response 聚合公钥和本节点的公开分片
+type KeygenRound2Response struct {
+	PublicKey   hexutil.Bytes `json:"publicKey"`
+	PublicShare hexutil.Bytes `json:"publicShare"`
+}
+
+// SignRound1Request 签名第一轮：生成随机数k_i、gamma_i
+type SignRound1Request struct {
+	SessionID string        `json:"sessionId"`
+	KeyID     string        `json:"keyId"`
+	Hash      hexutil.Bytes `json:"hash"`
+}
+
+// SignRound1Response Gamma_i的承诺、用本节点Paillier公钥加密的k_i，以及对每个其他节点的k_i范围证明
+type SignRound1Response struct {
+	Commitment hexutil.Bytes     `json:"commitment"`
+	EncK       *big.Int          `json:"encK"`
+	EncProofs  map[int]*EncProof `json:"encProofs"` // 验证方序号 -> 范围证明
+	Signature  hexutil.Bytes     `json:"signature"`
+}
+
+// SignRound2Request 签名第二轮：确定参与方，对其他参与方的Enc(k_j)执行MtA
+type SignRound2Request struct {
+	SessionID string                      `json:"sessionId"`
+	Parties   []int                       `json:"parties"`
+	Round1    map[int]*SignRound1Response `json:"round1"` // 发送方序号 -> 第一轮输出
+}
+
+// MtAResponse MtA响应，分别对应k_j*gamma_i和k_j*w_i。Y为响应方公钥下加密的掩码，
+// 证明中的X分别为Gamma_i和lambda_i*X_i；同时打开Gamma_i的承诺，供接收方校验证明
+type MtAResponse struct {
+	Gamma      *big.Int      `json:"gamma"`
+	GammaY     *big.Int      `json:"gammaY"`
+	GammaProof *AffGProof    `json:"gammaProof"`
+	W          *big.Int      `json:"w"`
+	WY         *big.Int      `json:"wY"`
+	WProof     *AffGProof    `json:"wProof"`
+	GammaPoint hexutil.Bytes `json:"gammaPoint"`
+	Blind      hexutil.Bytes `json:"blind"`
+	Signature  hexutil.Bytes `json:"signature"`
+}
+
+// SignRound2Response 本节点发给其他参与方的MtA响应
+type SignRound2Response struct {
+	MtA map[int]*MtAResponse `json:"mta"` // 接收方序号 -> 响应
+}
+
+// SignRound3Request 签名第三轮：其他参与方发给本节点的MtA响应
+type SignRound3Request struct {
+	SessionID string               `json:"sessionId"`
+	MtA       map[int]*MtAResponse `json:"mta"` // 发送方序号 -> 响应
+}
+
+// SignRound3Response delta_i、Gamma_i的承诺打开值，以及Delta_i = k_i*Gamma和向每个其他参与方证明其与Enc(k_i)一致的证明
+type SignRound3Response struct {
+	Delta       *big.Int              `json:"delta"`
+	Gamma       hexutil.Bytes         `json:"gamma"`
+	Blind       hexutil.Bytes         `json:"blind"`
+	BigDelta    hexutil.Bytes         `json:"bigDelta"`
+	DeltaProofs map[int]*LogStarProof `json:"deltaProofs"` // 验证方序号 -> 证明
+	Signature   hexutil.Bytes         `json:"signature"`
+}
+
+// SignRound4Request 签名第四轮：汇总delta和Gamma计算R并输出部分签名
+type SignRound4Request struct {
+	SessionID string                      `json:"sessionId"`
+	Round3    map[int]*SignRound3Response `json:"round3"` // 发送方序号 -> 第三轮输出
+}
+
+// SignRound4Response 部分签名s_i
+type SignRound4Response struct {
+	R hexutil.Bytes `json:"r"`
+	S *big.Int      `json:"s"`
+}
+
+// errorResponse 节点错误响应
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// signMessage 用节点身份密钥签名协议消息，msg的Signature字段须为空。
+// 消息经协调方转发，签名保证协调方无法篡改或冒充其他节点的消息
+func signMessage(identity *ecdsa.PrivateKey, kind, context string, msg interface{}) (hexutil.Bytes, error) {
+	digest, err := messageDigest(kind, context, msg)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Sign(digest, identity)
+}
+
+// verifyMessage 用发送方身份公钥校验协议消息的签名，msg的Signature字段须已清空
+func verifyMessage(identityKey []byte, kind, context string, msg interface{}, signature []byte) error {
+	if len(signature) != crypto.SignatureLength {
+		return errors.New("missing message signature")
+	}
+	digest, err := messageDigest(kind, context, msg)
+	if err != nil {
+		return err
+	}
+	if !crypto.VerifySignature(identityKey, digest, signature[:crypto.RecoveryIDOffset]) {
+		return errors.New("invalid message signature")
+	}
+	return nil
+}
+
+func messageDigest(kind, context string, msg interface{}) ([]byte, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256([]byte(kind), []byte{0}, []byte(context), []byte{0}, data), nil
+}
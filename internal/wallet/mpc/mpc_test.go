@@ -0,0 +1,517 @@
+package mpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// startNodes 在本机启动count个签名节点，返回节点和对应的HTTP服务
+func startNodes(t *testing.T, count int) ([]*Node, []*httptest.Server) {
+	nodes := make([]*Node, count)
+	servers := make([]*httptest.Server, count)
+	for i := range nodes {
+		node, err := NewNode(t.TempDir(), fmt.Sprintf("node%d-secret", i+1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		nodes[i] = node
+		servers[i] = httptest.NewServer(node.Handler())
+		t.Cleanup(servers[i].Close)
+	}
+	return nodes, servers
+}
+
+func serverURLs(servers []*httptest.Server) []string {
+	urls := make([]string, len(servers))
+	for i, server := range servers {
+		urls[i] = server.URL
+	}
+	return urls
+}
+
+// checkSignature 校验签名能恢复出门限公钥
+func checkSignature(t *testing.T, key *Key, hash, signature []byte) {
+	t.Helper()
+	publicKey, err := crypto.DecompressPubkey(key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recovered, err := crypto.Ecrecover(hash, signature)
+	if err != nil {
+		t.Fatalf("Ecrecover: %v", err)
+	}
+	if !bytes.Equal(recovered, crypto.FromECDSAPub(publicKey)) {
+		t.Fatal("signature does not recover to the threshold public key")
+	}
+	if signature[crypto.RecoveryIDOffset] > 1 {
+		t.Fatalf("unexpected recovery id %d", signature[crypto.RecoveryIDOffset])
+	}
+}
+
+func TestThresholdKeygenAndSign(t *testing.T) {
+	ctx := context.Background()
+	_, servers := startNodes(t, 3)
+	coordinator := NewCoordinator()
+
+	key, err := coordinator.Keygen(ctx, serverURLs(servers), 2)
+	if err != nil {
+		t.Fatalf("Keygen: %v", err)
+	}
+	if key.Threshold != 2 || len(key.Nodes) != 3 {
+		t.Fatalf("unexpected key %+v", key)
+	}
+
+	hash := crypto.Keccak256([]byte("threshold signing"))
+	signature, err := coordinator.Sign(ctx, key, hash)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	checkSignature(t, key, hash, signature)
+
+	// 第一个节点离线，其余两个节点仍能签名
+	servers[0].Close()
+	hash = crypto.Keccak256([]byte("node 1 offline"))
+	signature, err = coordinator.Sign(ctx, key, hash)
+	if err != nil {
+		t.Fatalf("Sign with node 1 offline: %v", err)
+	}
+	checkSignature(t, key, hash, signature)
+
+	// 在线节点不足门限
+	servers[1].Close()
+	if _, err := coordinator.Sign(ctx, key, hash); !errors.Is(err, ErrNotEnoughNodes) {
+		t.Fatalf("expected ErrNotEnoughNodes, got %v", err)
+	}
+}
+
+// tamperingHandler 修改发往节点的某一轮请求，模拟恶意协调方
+type tamperingHandler struct {
+	handler http.Handler
+	mu      sync.Mutex
+	path    string
+	tamper  func(body []byte) []byte
+}
+
+func (h *tamperingHandler) set(path string, tamper func(body []byte) []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.path, h.tamper = path, tamper
+}
+
+func (h *tamperingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	path, tamper := h.path, h.tamper
+	h.mu.Unlock()
+	if tamper != nil && r.URL.Path == path {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(tamper(body)))
+		r.ContentLength = -1
+	}
+	h.handler.ServeHTTP(w, r)
+}
+
+func TestSignRejectsRelayedTampering(t *testing.T) {
+	ctx := context.Background()
+	_, servers := startNodes(t, 1)
+	second, err := NewNode(t.TempDir(), "node2-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampering := &tamperingHandler{handler: second.Handler()}
+	server := httptest.NewServer(tampering)
+	defer server.Close()
+
+	coordinator := NewCoordinator()
+	key, err := coordinator.Keygen(ctx, []string{servers[0].URL, server.URL}, 2)
+	if err != nil {
+		t.Fatalf("Keygen: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		tamper func(body []byte) []byte
+		want   string
+	}{
+		{
+			name: "mta ciphertext",
+			path: "/sign/round3",
+			tamper: func(body []byte) []byte {
+				var req SignRound3Request
+				json.Unmarshal(body, &req)
+				for _, resp := range req.MtA {
+					resp.W.Add(resp.W, big.NewInt(1))
+				}
+				data, _ := json.Marshal(&req)
+				return data
+			},
+			want: "invalid message signature",
+		},
+		{
+			name: "encrypted nonce",
+			path: "/sign/round2",
+			tamper: func(body []byte) []byte {
+				var req SignRound2Request
+				json.Unmarshal(body, &req)
+				req.Round1[1].EncK.Add(req.Round1[1].EncK, big.NewInt(1))
+				data, _ := json.Marshal(&req)
+				return data
+			},
+			want: "invalid message signature",
+		},
+		{
+			name: "delta",
+			path: "/sign/round4",
+			tamper: func(body []byte) []byte {
+				var req SignRound4Request
+				json.Unmarshal(body, &req)
+				req.Round3[1].Delta.Add(req.Round3[1].Delta, big.NewInt(1))
+				data, _ := json.Marshal(&req)
+				return data
+			},
+			want: "invalid message signature",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampering.set(tt.path, tt.tamper)
+			defer tampering.set("", nil)
+
+			_, err := coordinator.Sign(ctx, key, crypto.Keccak256([]byte(tt.name)))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// cheatingRound4 模拟恶意参与方：修改其发给诚实节点的第三轮输出并用自己的身份密钥重新签名，
+// 签名校验通过，必须由Delta一致性检查发现
+func cheatingRound4(t *testing.T, cheater *Node, index int, key *Key, hash []byte, cheat func(msg *SignRound3Response)) func(body []byte) []byte {
+	return func(body []byte) []byte {
+		var req SignRound4Request
+		json.Unmarshal(body, &req)
+		msg := req.Round3[index]
+		cheat(msg)
+		msg.Signature = nil
+		context := fmt.Sprintf("%s:%s:%x:%d:%d", req.SessionID, key.ID, hash, index, 0)
+		signature, err := signMessage(cheater.identity, messageSignRound3, context, msg)
+		if err != nil {
+			t.Error(err)
+		}
+		msg.Signature = signature
+		data, _ := json.Marshal(&req)
+		return data
+	}
+}
+
+func TestSignDetectsCheatingParty(t *testing.T) {
+	ctx := context.Background()
+	cheaters, servers := startNodes(t, 1)
+	honest, err := NewNode(t.TempDir(), "honest-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampering := &tamperingHandler{handler: honest.Handler()}
+	server := httptest.NewServer(tampering)
+	defer server.Close()
+
+	// 诚实节点为参与方1，恶意节点为参与方2
+	coordinator := NewCoordinator()
+	key, err := coordinator.Keygen(ctx, []string{server.URL, servers[0].URL}, 2)
+	if err != nil {
+		t.Fatalf("Keygen: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		cheat func(msg *SignRound3Response)
+		want  string
+	}{
+		{
+			// delta_2错误时R不等于k^-1*G，公开s_1会泄露私钥信息
+			name:  "wrong delta",
+			cheat: func(msg *SignRound3Response) { msg.Delta.Add(msg.Delta, big.NewInt(1)) },
+			want:  "delta shares are inconsistent",
+		},
+		{
+			// 同时修改Delta_2以掩盖错误的delta_2，证明无法通过
+			name: "wrong delta with matching Delta",
+			cheat: func(msg *SignRound3Response) {
+				msg.Delta.Add(msg.Delta, big.NewInt(1))
+				point, _ := decodePoint(msg.BigDelta)
+				fake, _ := encodePoint(addPoints(point, scalarBaseMult(big.NewInt(1))))
+				msg.BigDelta = fake
+			},
+			want: "big delta from party 2: log proof failed",
+		},
+		{
+			name:  "missing proof",
+			cheat: func(msg *SignRound3Response) { msg.DeltaProofs = nil },
+			want:  "big delta from party 2: missing log proof",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash := crypto.Keccak256([]byte(tt.name))
+			tampering.set("/sign/round4", cheatingRound4(t, cheaters[0], 2, key, hash, tt.cheat))
+			defer tampering.set("", nil)
+
+			_, err := coordinator.Sign(ctx, key, hash)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// 没有作弊时正常签名
+	hash := crypto.Keccak256([]byte("honest"))
+	signature, err := coordinator.Sign(ctx, key, hash)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	checkSignature(t, key, hash, signature)
+}
+
+func TestKeygenRejectsUntrustedPeer(t *testing.T) {
+	nodes, servers := startNodes(t, 2)
+	other, err := NewNode(t.TempDir(), "other-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes[0].SetTrustedPeers([]hexutil.Bytes{nodes[0].Info().IdentityKey, other.Info().IdentityKey})
+
+	_, err = NewCoordinator().Keygen(context.Background(), serverURLs(servers), 2)
+	if err == nil || !strings.Contains(err.Error(), "party 2 is not a trusted peer") {
+		t.Fatalf("expected untrusted peer error, got %v", err)
+	}
+}
+
+var (
+	testKeysOnce sync.Once
+	testKeys     [2]*PaillierPrivateKey
+	testRP       [2]*RingPedersen
+	testLambda   [2]*big.Int
+	testKeysErr  error
+)
+
+// paillierTestKeys 生成两组Paillier密钥和环Pedersen参数，供证明测试复用
+func paillierTestKeys(t *testing.T) ([2]*PaillierPrivateKey, [2]*RingPedersen, [2]*big.Int) {
+	testKeysOnce.Do(func() {
+		for i := range testKeys {
+			if testKeys[i], testKeysErr = generatePaillierKey(); testKeysErr != nil {
+				return
+			}
+			if testRP[i], testLambda[i], testKeysErr = generateRingPedersen(testKeys[i]); testKeysErr != nil {
+				return
+			}
+		}
+	})
+	if testKeysErr != nil {
+		t.Fatal(testKeysErr)
+	}
+	return testKeys, testRP, testLambda
+}
+
+func TestModulusProof(t *testing.T) {
+	keys, _, _ := paillierTestKeys(t)
+	proof, err := proveModulus(keys[0], "ctx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyModulus(keys[0].N, proof, "ctx"); err != nil {
+		t.Fatalf("valid proof rejected: %v", err)
+	}
+	if err := verifyModulus(keys[0].N, proof, "other"); err == nil {
+		t.Fatal("proof accepted in another context")
+	}
+	if err := verifyModulus(keys[1].N, proof, "ctx"); err == nil {
+		t.Fatal("proof accepted for another modulus")
+	}
+	// 素数不是合法的Paillier模数
+	if err := verifyModulus(keys[0].p, proof, "ctx"); err == nil {
+		t.Fatal("prime modulus accepted")
+	}
+}
+
+func TestRingPedersenProof(t *testing.T) {
+	keys, rps, lambdas := paillierTestKeys(t)
+	proof, err := proveRingPedersen(rps[0], lambdas[0], keys[0].Lambda, "ctx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyRingPedersen(rps[0], proof, "ctx"); err != nil {
+		t.Fatalf("valid proof rejected: %v", err)
+	}
+	// s不在t生成的子群中时无法证明
+	forged := &RingPedersen{N: rps[0].N, S: rps[1].S, T: rps[0].T}
+	if err := verifyRingPedersen(forged, proof, "ctx"); err == nil {
+		t.Fatal("proof accepted for other parameters")
+	}
+}
+
+func TestEncProof(t *testing.T) {
+	keys, rps, _ := paillierTestKeys(t)
+	pk := &keys[0].PaillierPublicKey
+
+	k, _ := randomScalar()
+	K, rho, err := pk.encrypt(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := proveEnc(pk, rps[1], K, k, rho, "ctx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyEnc(pk, rps[1], K, proof, "ctx"); err != nil {
+		t.Fatalf("valid proof rejected: %v", err)
+	}
+	if err := verifyEnc(pk, rps[1], K, proof, "other"); err == nil {
+		t.Fatal("proof accepted in another context")
+	}
+
+	// k超出范围
+	large := new(big.Int).Lsh(one, proofL+proofEpsilon+64)
+	K, rho, err = pk.encrypt(large)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err = proveEnc(pk, rps[1], K, large, rho, "ctx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyEnc(pk, rps[1], K, proof, "ctx"); err == nil {
+		t.Fatal("out of range nonce accepted")
+	}
+}
+
+func TestAffGProof(t *testing.T) {
+	keys, rps, _ := paillierTestKeys(t)
+	verifier := &keys[0].PaillierPublicKey
+	prover := &keys[1].PaillierPublicKey
+
+	k, _ := randomScalar()
+	encK, err := verifier.Encrypt(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statement := func(x *big.Int) *affGStatement {
+		X, err := encodePoint(scalarBaseMult(x))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &affGStatement{verifier: verifier, prover: prover, rp: rps[0], C: encK, X: X}
+	}
+
+	x, _ := randomScalar()
+	st := statement(x)
+	proof, beta, err := mtaRespond(st, x, "ctx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyAffG(st, proof, "ctx"); err != nil {
+		t.Fatalf("valid proof rejected: %v", err)
+	}
+
+	// 解密结果与响应方分片之和为k*x
+	alpha, err := keys[0].Decrypt(st.D)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := new(big.Int).Add(alpha, beta)
+	if sum.Mod(sum, curveN).Cmp(new(big.Int).Mod(new(big.Int).Mul(k, x), curveN)) != 0 {
+		t.Fatal("mta shares do not add up to k*x")
+	}
+
+	// X与响应使用的x不一致
+	wrong := statement(new(big.Int).Add(x, one))
+	wrong.D, wrong.Y = st.D, st.Y
+	if err := verifyAffG(wrong, proof, "ctx"); err == nil {
+		t.Fatal("proof accepted for another public point")
+	}
+
+	// 掩码超出范围
+	st = statement(x)
+	y := new(big.Int).Lsh(one, proofLPrime+proofEpsilon+64)
+	encY, rho, err := verifier.encrypt(y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Y, rhoY, err := prover.encrypt(y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st.D = verifier.Add(verifier.MulConst(encK, x), encY)
+	st.Y = Y
+	proof, err = proveAffG(st, x, y, rho, rhoY, "ctx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyAffG(st, proof, "ctx"); err == nil {
+		t.Fatal("out of range mask accepted")
+	}
+}
+
+func TestLogStarProof(t *testing.T) {
+	keys, rps, _ := paillierTestKeys(t)
+	pk := &keys[0].PaillierPublicKey
+
+	gamma, _ := randomScalar()
+	G, err := encodePoint(scalarBaseMult(gamma))
+	if err != nil {
+		t.Fatal(err)
+	}
+	base, _ := decodePoint(G)
+	k, _ := randomScalar()
+	K, rho, err := pk.encrypt(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	X, err := encodePoint(scalarMult(k, base))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	st := &logStarStatement{prover: pk, rp: rps[1], C: K, G: G, X: X}
+	proof, err := proveLogStar(st, k, rho, "ctx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyLogStar(st, proof, "ctx"); err != nil {
+		t.Fatalf("valid proof rejected: %v", err)
+	}
+	if err := verifyLogStar(st, proof, "other"); err == nil {
+		t.Fatal("proof accepted in another context")
+	}
+
+	// X的离散对数与密文中的k不一致
+	other, _ := randomScalar()
+	wrongX, _ := encodePoint(scalarMult(other, base))
+	wrong := &logStarStatement{prover: pk, rp: rps[1], C: K, G: G, X: wrongX}
+	proof, err = proveLogStar(wrong, k, rho, "ctx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyLogStar(wrong, proof, "ctx"); err == nil {
+		t.Fatal("proof accepted for a point with another discrete log")
+	}
+
+	// 基点不同
+	wrongBase := &logStarStatement{prover: pk, rp: rps[1], C: K, G: X, X: X}
+	if err := verifyLogStar(wrongBase, proof, "ctx"); err == nil {
+		t.Fatal("proof accepted for another base point")
+	}
+}
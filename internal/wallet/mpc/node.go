@@ -0,0 +1,250 @@
+// Package mpc 实现门限ECDSA（secp256k1）签名：密钥生成得到t-of-n分片，分别保存在独立的签名节点上，
+// 任意t个节点通过多方计算协议产生普通签名，完整私钥从不出现在任何一台机器上。
+// 签名协议参考GG18，使用Paillier加密的MtA，并采用CGGMP21的零知识证明：密钥生成时证明Paillier模数和
+// 环Pedersen参数构造正确，签名时发起方证明Enc(k_i)的范围，响应方证明MtA响应的范围及其与Gamma_i、
+// lambda_i*X_i一致。协议消息由发送节点的身份密钥签名，协调方只负责转发，无法篡改或伪造
+package mpc
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"multi-chain-wallet/internal/wallet/keycrypt"
+)
+
+// 未完成的协议会话保留时间
+const sessionTimeout = 5 * time.Minute
+
+// 密钥ID和会话ID只允许UUID等安全字符，避免拼接文件路径时越界
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9-]{1,64}$`)
+
+// KeyShare 节点持有的密钥分片
+type KeyShare struct {
+	KeyID        string                     `json:"keyId"`
+	Index        int                        `json:"index"`
+	Threshold    int                        `json:"threshold"`
+	Share        *big.Int                   `json:"share"`        // x_i
+	PublicKey    hexutil.Bytes              `json:"publicKey"`    // 聚合公钥
+	PublicShares map[int]hexutil.Bytes      `json:"publicShares"` // 各节点的x_j*G
+	Paillier     *PaillierPrivateKey        `json:"paillier"`
+	PaillierKeys map[int]*PaillierPublicKey `json:"paillierKeys"` // 各节点的Paillier公钥
+	RingPedersen map[int]*RingPedersen      `json:"ringPedersen"` // 各节点的环Pedersen参数
+	Identities   map[int]hexutil.Bytes      `json:"identities"`   // 各节点的身份公钥，用于校验协议消息签名
+}
+
+// Node 门限签名节点，保存一个密钥分片并参与密钥生成和签名协议。
+// 协议消息经协调方转发，节点之间不直接通信
+type Node struct {
+	dataDir       string
+	encryptionKey []byte
+	identity      *ecdsa.PrivateKey
+	trustedPeers  map[string]bool
+
+	mu              sync.Mutex
+	keygenSessions  map[string]*keygenSession
+	signSessions    map[string]*signSession
+	sessionsCleaned time.Time
+}
+
+// NewNode 创建签名节点，dataDir保存节点身份密钥和加密后的密钥分片
+func NewNode(dataDir string, encryptionKey string) (*Node, error) {
+	if err := os.MkdirAll(filepath.Join(dataDir, "shares"), 0700); err != nil {
+		return nil, err
+	}
+
+	n := &Node{
+		dataDir:        dataDir,
+		encryptionKey:  keycrypt.DeriveKey(encryptionKey),
+		keygenSessions: make(map[string]*keygenSession),
+		signSessions:   make(map[string]*signSession),
+	}
+	identity, err := n.loadIdentity()
+	if err != nil {
+		return nil, err
+	}
+	n.identity = identity
+	return n, nil
+}
+
+// SetTrustedPeers 限定密钥生成的参与节点，identityKeys为各节点/info返回的身份公钥（含本节点）。
+// 设置后协调方不能在密钥生成时替换其他节点的身份
+func (n *Node) SetTrustedPeers(identityKeys []hexutil.Bytes) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.trustedPeers = make(map[string]bool, len(identityKeys))
+	for _, key := range identityKeys {
+		n.trustedPeers[string(key)] = true
+	}
+}
+
+// checkTrustedPeers 设置了可信节点时，所有参与方都须在其中
+func (n *Node) checkTrustedPeers(parties map[int]hexutil.Bytes) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.trustedPeers == nil {
+		return nil
+	}
+	for index, key := range parties {
+		if !n.trustedPeers[string(key)] {
+			return fmt.Errorf("party %d is not a trusted peer", index)
+		}
+	}
+	return nil
+}
+
+// loadIdentity 读取节点身份密钥，不存在时生成
+func (n *Node) loadIdentity() (*ecdsa.PrivateKey, error) {
+	path := filepath.Join(n.dataDir, "identity.key")
+	data, err := os.ReadFile(path)
+	if err == nil {
+		keyBytes, err := keycrypt.Decrypt(n.encryptionKey, strings.TrimSpace(string(data)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt identity key: %v", err)
+		}
+		return crypto.ToECDSA(keyBytes)
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	identity, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := keycrypt.Encrypt(n.encryptionKey, crypto.FromECDSA(identity))
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, []byte(encrypted), 0600); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// Info 返回节点信息
+func (n *Node) Info() *NodeInfo {
+	return &NodeInfo{IdentityKey: crypto.CompressPubkey(&n.identity.PublicKey)}
+}
+
+func (n *Node) sharePath(keyID string) string {
+	return filepath.Join(n.dataDir, "shares", keyID+".json")
+}
+
+// saveShare 加密保存密钥分片，同一密钥ID不允许覆盖
+func (n *Node) saveShare(share *KeyShare) error {
+	data, err := json.Marshal(share)
+	if err != nil {
+		return err
+	}
+	encrypted, err := keycrypt.Encrypt(n.encryptionKey, data)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(n.sharePath(share.KeyID), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to save key share: %v", err)
+	}
+	defer file.Close()
+	_, err = file.WriteString(encrypted)
+	return err
+}
+
+// loadShare 读取并解密密钥分片
+func (n *Node) loadShare(keyID string) (*KeyShare, error) {
+	if !idPattern.MatchString(keyID) {
+		return nil, errors.New("invalid key id")
+	}
+	data, err := os.ReadFile(n.sharePath(keyID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("key share %s not found", keyID)
+		}
+		return nil, err
+	}
+	plain, err := keycrypt.Decrypt(n.encryptionKey, strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt key share: %v", err)
+	}
+
+	var share KeyShare
+	if err := json.Unmarshal(plain, &share); err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// cleanSessions 清理超时的会话，调用方需持有锁
+func (n *Node) cleanSessions() {
+	now := time.Now()
+	if now.Sub(n.sessionsCleaned) < time.Minute {
+		return
+	}
+	n.sessionsCleaned = now
+	for id, session := range n.keygenSessions {
+		if now.Sub(session.created) > sessionTimeout {
+			delete(n.keygenSessions, id)
+		}
+	}
+	for id, session := range n.signSessions {
+		if now.Sub(session.created) > sessionTimeout {
+			delete(n.signSessions, id)
+		}
+	}
+}
+
+// Handler 返回节点的HTTP接口
+func (n *Node) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /info", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, n.Info())
+	})
+	mux.HandleFunc("POST /keygen/round1", handle(n.KeygenRound1))
+	mux.HandleFunc("POST /keygen/round2", handle(n.KeygenRound2))
+	mux.HandleFunc("POST /sign/round1", handle(n.SignRound1))
+	mux.HandleFunc("POST /sign/round2", handle(n.SignRound2))
+	mux.HandleFunc("POST /sign/round3", handle(n.SignRound3))
+	mux.HandleFunc("POST /sign/round4", handle(n.SignRound4))
+	return mux
+}
+
+// handle 将协议轮次包装为JSON接口
+func handle[Req any, Resp any](round func(*Req) (*Resp, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req Req
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxMessageSize)).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, &errorResponse{Error: "invalid request: " + err.Error()})
+			return
+		}
+		resp, err := round(&req)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, &errorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// shareContext ECIES共享信息，把分片密文绑定到会话和收发双方
+func shareContext(sessionID string, from, to int) []byte {
+	return []byte(fmt.Sprintf("mpc-keygen:%s:%d:%d", sessionID, from, to))
+}
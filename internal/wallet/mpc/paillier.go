@@ -0,0 +1,131 @@
+package mpc
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+// Paillier模数位数
+const paillierBits = 2048
+
+var one = big.NewInt(1)
+
+// PaillierPublicKey Paillier公钥，生成元取N+1
+type PaillierPublicKey struct {
+	N *big.Int `json:"n"`
+}
+
+// PaillierPrivateKey Paillier私钥
+type PaillierPrivateKey struct {
+	PaillierPublicKey
+	Lambda *big.Int `json:"lambda"` // (p-1)(q-1)
+	Mu     *big.Int `json:"mu"`     // lambda^-1 mod N
+
+	p, q *big.Int // 只在密钥生成会话中用于模数证明，不保存
+}
+
+// generatePaillierKey 生成Paillier密钥对，p、q均模4余3，以便证明N是Paillier-Blum模数
+func generatePaillierKey() (*PaillierPrivateKey, error) {
+	for {
+		p, err := blumPrime(paillierBits / 2)
+		if err != nil {
+			return nil, err
+		}
+		q, err := blumPrime(paillierBits / 2)
+		if err != nil {
+			return nil, err
+		}
+		if p.Cmp(q) == 0 {
+			continue
+		}
+
+		n := new(big.Int).Mul(p, q)
+		lambda := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+		mu := new(big.Int).ModInverse(lambda, n)
+		if mu == nil {
+			continue
+		}
+		return &PaillierPrivateKey{
+			PaillierPublicKey: PaillierPublicKey{N: n},
+			Lambda:            lambda,
+			Mu:                mu,
+			p:                 p,
+			q:                 q,
+		}, nil
+	}
+}
+
+// blumPrime 生成模4余3的素数
+func blumPrime(bits int) (*big.Int, error) {
+	for {
+		p, err := rand.Prime(rand.Reader, bits)
+		if err != nil {
+			return nil, err
+		}
+		if p.Bit(1) == 1 {
+			return p, nil
+		}
+	}
+}
+
+func (pk *PaillierPublicKey) nSquare() *big.Int {
+	return new(big.Int).Mul(pk.N, pk.N)
+}
+
+// Encrypt 加密m：(1+N)^m * r^N mod N^2
+func (pk *PaillierPublicKey) Encrypt(m *big.Int) (*big.Int, error) {
+	c, _, err := pk.encrypt(m)
+	return c, err
+}
+
+// encrypt 加密m并返回随机数r，零知识证明需要r
+func (pk *PaillierPublicKey) encrypt(m *big.Int) (*big.Int, *big.Int, error) {
+	if m.Sign() < 0 || m.Cmp(pk.N) >= 0 {
+		return nil, nil, errors.New("paillier plaintext out of range")
+	}
+	r, err := randomUnit(pk.N)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pk.encryptWithNonce(m, r), r, nil
+}
+
+// encryptWithNonce 用给定随机数计算(1+N)^m * r^N mod N^2，m可以为负
+func (pk *PaillierPublicKey) encryptWithNonce(m, r *big.Int) *big.Int {
+	n2 := pk.nSquare()
+	// (1+N)^m = 1 + m*N mod N^2
+	gm := new(big.Int).Mul(m, pk.N)
+	gm.Add(gm, one).Mod(gm, n2)
+	c := new(big.Int).Exp(r, pk.N, n2)
+	return c.Mul(c, gm).Mod(c, n2)
+}
+
+// Add 同态加法：Enc(a)*Enc(b) = Enc(a+b)
+func (pk *PaillierPublicKey) Add(c1, c2 *big.Int) *big.Int {
+	n2 := pk.nSquare()
+	return new(big.Int).Mod(new(big.Int).Mul(c1, c2), n2)
+}
+
+// MulConst 同态数乘：Enc(a)^k = Enc(a*k)
+func (pk *PaillierPublicKey) MulConst(c, k *big.Int) *big.Int {
+	return new(big.Int).Exp(c, k, pk.nSquare())
+}
+
+// ValidCiphertext 校验密文在(0, N^2)内且与N互素
+func (pk *PaillierPublicKey) ValidCiphertext(c *big.Int) bool {
+	if c == nil || c.Sign() <= 0 || c.Cmp(pk.nSquare()) >= 0 {
+		return false
+	}
+	return new(big.Int).GCD(nil, nil, c, pk.N).Cmp(one) == 0
+}
+
+// Decrypt 解密：L(c^lambda mod N^2) * mu mod N，L(u) = (u-1)/N
+func (sk *PaillierPrivateKey) Decrypt(c *big.Int) (*big.Int, error) {
+	if !sk.ValidCiphertext(c) {
+		return nil, errors.New("invalid paillier ciphertext")
+	}
+	u := new(big.Int).Exp(c, sk.Lambda, sk.nSquare())
+	u.Sub(u, one).Div(u, sk.N)
+	return u.Mul(u, sk.Mu).Mod(u, sk.N), nil
+}
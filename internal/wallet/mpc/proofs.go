@@ -0,0 +1,712 @@
+package mpc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// 零知识证明的参数（参考CGGMP21）：ℓ为secp256k1阶的位数，ℓ'覆盖MtA掩码q^5，ε为统计隐藏的余量
+const (
+	proofL       = 256
+	proofLPrime  = 5 * proofL
+	proofEpsilon = 2 * proofL
+
+	// 模数证明和环Pedersen参数证明的重复次数，可靠性误差2^-80
+	proofRounds = 80
+)
+
+// RingPedersen 环Pedersen参数，s = t^lambda mod N，lambda只有生成方知道。
+// 其他节点向本节点证明范围时用它做承诺，模数与本节点的Paillier模数相同
+type RingPedersen struct {
+	N *big.Int `json:"n"`
+	S *big.Int `json:"s"`
+	T *big.Int `json:"t"`
+}
+
+// ModProof 证明Paillier模数N是两个模4余3的素数之积（Paillier-Blum模数），即N无平方因子且与phi(N)互素
+type ModProof struct {
+	W *big.Int   `json:"w"`
+	X []*big.Int `json:"x"`
+	A []bool     `json:"a"`
+	B []bool     `json:"b"`
+	Z []*big.Int `json:"z"`
+}
+
+// PrmProof 证明环Pedersen参数中s属于t生成的子群
+type PrmProof struct {
+	A []*big.Int `json:"a"`
+	Z []*big.Int `json:"z"`
+}
+
+// EncProof 证明密文K = Enc_0(k)中|k| <= 2^(ℓ+ε)，承诺使用验证方的环Pedersen参数
+type EncProof struct {
+	S  *big.Int `json:"s"`
+	A  *big.Int `json:"a"`
+	C  *big.Int `json:"c"`
+	Z1 *big.Int `json:"z1"`
+	Z2 *big.Int `json:"z2"`
+	Z3 *big.Int `json:"z3"`
+}
+
+// AffGProof 证明MtA响应D = C^x * Enc_0(y)中|x| <= 2^(ℓ+ε)、|y| <= 2^(ℓ'+ε)，
+// 且x与公开的X = x*G一致、y与响应方公钥下的Y = Enc_1(y)一致
+type AffGProof struct {
+	S  *big.Int      `json:"s"`
+	T  *big.Int      `json:"t"`
+	A  *big.Int      `json:"a"`
+	Bx hexutil.Bytes `json:"bx"`
+	By *big.Int      `json:"by"`
+	E  *big.Int      `json:"e"`
+	F  *big.Int      `json:"f"`
+	Z1 *big.Int      `json:"z1"`
+	Z2 *big.Int      `json:"z2"`
+	Z3 *big.Int      `json:"z3"`
+	Z4 *big.Int      `json:"z4"`
+	W  *big.Int      `json:"w"`
+	Wy *big.Int      `json:"wy"`
+}
+
+// LogStarProof 证明X = x*G的离散对数x与密文C = Enc_0(x)的明文一致且|x| <= 2^(ℓ+ε)，G为任意基点。
+// 签名时用于证明Delta_i = k_i*Gamma中的k_i就是第一轮加密的k_i
+type LogStarProof struct {
+	S  *big.Int      `json:"s"`
+	A  *big.Int      `json:"a"`
+	Y  hexutil.Bytes `json:"y"`
+	D  *big.Int      `json:"d"`
+	Z1 *big.Int      `json:"z1"`
+	Z2 *big.Int      `json:"z2"`
+	Z3 *big.Int      `json:"z3"`
+}
+
+// logStarStatement 离散对数与密文一致性证明的公开输入
+type logStarStatement struct {
+	prover *PaillierPublicKey // C在证明方公钥下加密
+	rp     *RingPedersen      // 验证方的环Pedersen参数
+	C      *big.Int
+	G      hexutil.Bytes // 基点
+	X      hexutil.Bytes
+}
+
+// affGStatement MtA响应证明的公开输入
+type affGStatement struct {
+	verifier *PaillierPublicKey // C、D在验证方公钥下加密
+	prover   *PaillierPublicKey // Y在响应方公钥下加密
+	rp       *RingPedersen      // 验证方的环Pedersen参数
+	C        *big.Int
+	D        *big.Int
+	Y        *big.Int
+	X        hexutil.Bytes
+}
+
+// generateRingPedersen 在Paillier模数上生成环Pedersen参数，返回参数和lambda
+func generateRingPedersen(sk *PaillierPrivateKey) (*RingPedersen, *big.Int, error) {
+	tau, err := randomUnit(sk.N)
+	if err != nil {
+		return nil, nil, err
+	}
+	t := new(big.Int).Exp(tau, big.NewInt(2), sk.N)
+	lambda, err := rand.Int(rand.Reader, sk.Lambda)
+	if err != nil {
+		return nil, nil, err
+	}
+	s := new(big.Int).Exp(t, lambda, sk.N)
+	return &RingPedersen{N: sk.N, S: s, T: t}, lambda, nil
+}
+
+// proveModulus 生成Paillier-Blum模数证明
+func proveModulus(sk *PaillierPrivateKey, context string) (*ModProof, error) {
+	p, q, n := sk.p, sk.q, sk.N
+	if p == nil || q == nil {
+		return nil, errors.New("paillier primes are not available")
+	}
+
+	var w *big.Int
+	for {
+		var err error
+		if w, err = randomUnit(n); err != nil {
+			return nil, err
+		}
+		if big.Jacobi(w, n) == -1 {
+			break
+		}
+	}
+
+	tr := newTranscript("mpc/modulus", context)
+	tr.ints(n, w)
+	nInv := new(big.Int).ModInverse(n, sk.Lambda)
+	if nInv == nil {
+		return nil, errors.New("paillier modulus is not coprime to phi(N)")
+	}
+
+	proof := &ModProof{W: w}
+	for i := 0; i < proofRounds; i++ {
+		y := tr.expand(i, n)
+		found := false
+		for _, a := range []bool{false, true} {
+			for _, b := range []bool{false, true} {
+				v := modulusAdjust(y, w, a, b, n)
+				if !isQuadraticResidue(v, p) || !isQuadraticResidue(v, q) {
+					continue
+				}
+				proof.X = append(proof.X, fourthRoot(v, p, q))
+				proof.A = append(proof.A, a)
+				proof.B = append(proof.B, b)
+				found = true
+				break
+			}
+			if found {
+				break
+			}
+		}
+		if !found {
+			return nil, errors.New("modulus proof challenge is not a unit")
+		}
+		proof.Z = append(proof.Z, new(big.Int).Exp(y, nInv, n))
+	}
+	return proof, nil
+}
+
+// verifyModulus 校验Paillier-Blum模数证明
+func verifyModulus(n *big.Int, proof *ModProof, context string) error {
+	if n == nil || n.Bit(0) == 0 || n.ProbablyPrime(20) {
+		return errors.New("paillier modulus must be an odd composite")
+	}
+	if proof == nil || len(proof.X) != proofRounds || len(proof.A) != proofRounds ||
+		len(proof.B) != proofRounds || len(proof.Z) != proofRounds {
+		return errors.New("malformed modulus proof")
+	}
+	if !isUnit(proof.W, n) || big.Jacobi(proof.W, n) != -1 {
+		return errors.New("invalid modulus proof")
+	}
+
+	tr := newTranscript("mpc/modulus", context)
+	tr.ints(n, proof.W)
+	four := big.NewInt(4)
+	for i := 0; i < proofRounds; i++ {
+		y := tr.expand(i, n)
+		if !isUnit(proof.Z[i], n) || !isUnit(proof.X[i], n) {
+			return errors.New("invalid modulus proof")
+		}
+		if new(big.Int).Exp(proof.Z[i], n, n).Cmp(y) != 0 {
+			return errors.New("modulus proof failed: N is not coprime to phi(N)")
+		}
+		if new(big.Int).Exp(proof.X[i], four, n).Cmp(modulusAdjust(y, proof.W, proof.A[i], proof.B[i], n)) != 0 {
+			return errors.New("modulus proof failed: N is not a Paillier-Blum modulus")
+		}
+	}
+	return nil
+}
+
+// proveRingPedersen 证明s = t^lambda mod N
+func proveRingPedersen(rp *RingPedersen, lambda, phi *big.Int, context string) (*PrmProof, error) {
+	secrets := make([]*big.Int, proofRounds)
+	proof := &PrmProof{A: make([]*big.Int, proofRounds), Z: make([]*big.Int, proofRounds)}
+	for i := range secrets {
+		a, err := rand.Int(rand.Reader, phi)
+		if err != nil {
+			return nil, err
+		}
+		secrets[i] = a
+		proof.A[i] = new(big.Int).Exp(rp.T, a, rp.N)
+	}
+
+	e := ringPedersenChallenge(rp, proof.A, context)
+	for i, a := range secrets {
+		z := new(big.Int).Set(a)
+		if e.Bit(i) == 1 {
+			z.Add(z, lambda)
+		}
+		proof.Z[i] = z.Mod(z, phi)
+	}
+	return proof, nil
+}
+
+// verifyRingPedersen 校验环Pedersen参数证明
+func verifyRingPedersen(rp *RingPedersen, proof *PrmProof, context string) error {
+	if rp == nil || rp.N == nil || !isUnit(rp.S, rp.N) || !isUnit(rp.T, rp.N) {
+		return errors.New("invalid ring-pedersen parameters")
+	}
+	if proof == nil || len(proof.A) != proofRounds || len(proof.Z) != proofRounds {
+		return errors.New("malformed ring-pedersen proof")
+	}
+	for i := range proof.A {
+		if !isUnit(proof.A[i], rp.N) || proof.Z[i] == nil || proof.Z[i].Sign() < 0 {
+			return errors.New("invalid ring-pedersen proof")
+		}
+	}
+
+	e := ringPedersenChallenge(rp, proof.A, context)
+	for i := range proof.A {
+		expected := new(big.Int).Set(proof.A[i])
+		if e.Bit(i) == 1 {
+			expected.Mul(expected, rp.S).Mod(expected, rp.N)
+		}
+		if new(big.Int).Exp(rp.T, proof.Z[i], rp.N).Cmp(expected) != 0 {
+			return errors.New("ring-pedersen proof failed")
+		}
+	}
+	return nil
+}
+
+func ringPedersenChallenge(rp *RingPedersen, commitments []*big.Int, context string) *big.Int {
+	tr := newTranscript("mpc/ring-pedersen", context)
+	tr.ints(rp.N, rp.S, rp.T)
+	tr.ints(commitments...)
+	return tr.expand(0, new(big.Int).Lsh(one, proofRounds))
+}
+
+// proveEnc 证明K = Enc_0(k; rho)中的k在范围内，k为发起方的MtA输入
+func proveEnc(pk *PaillierPublicKey, rp *RingPedersen, K, k, rho *big.Int, context string) (*EncProof, error) {
+	alpha, err := randomSigned(bitBound(proofL + proofEpsilon))
+	if err != nil {
+		return nil, err
+	}
+	mu, err := randomSigned(new(big.Int).Mul(bitBound(proofL), rp.N))
+	if err != nil {
+		return nil, err
+	}
+	gamma, err := randomSigned(new(big.Int).Mul(bitBound(proofL+proofEpsilon), rp.N))
+	if err != nil {
+		return nil, err
+	}
+	r, err := randomUnit(pk.N)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &EncProof{
+		S: pedersenCommit(rp, k, mu),
+		A: pk.encryptWithNonce(alpha, r),
+		C: pedersenCommit(rp, alpha, gamma),
+	}
+	e := encChallenge(pk, rp, K, proof, context)
+
+	proof.Z1 = new(big.Int).Add(alpha, new(big.Int).Mul(e, k))
+	proof.Z2 = modPow(rho, e, pk.N)
+	proof.Z2.Mul(proof.Z2, r).Mod(proof.Z2, pk.N)
+	proof.Z3 = new(big.Int).Add(gamma, new(big.Int).Mul(e, mu))
+	return proof, nil
+}
+
+// verifyEnc 校验发起方的范围证明
+func verifyEnc(pk *PaillierPublicKey, rp *RingPedersen, K *big.Int, proof *EncProof, context string) error {
+	if proof == nil || proof.Z1 == nil || proof.Z3 == nil {
+		return errors.New("missing range proof")
+	}
+	if !pk.ValidCiphertext(K) || !pk.ValidCiphertext(proof.A) || !isUnit(proof.Z2, pk.N) ||
+		!isUnit(proof.S, rp.N) || !isUnit(proof.C, rp.N) {
+		return errors.New("invalid range proof")
+	}
+	if !inRange(proof.Z1, proofL+proofEpsilon) {
+		return errors.New("range proof failed: value out of range")
+	}
+
+	e := encChallenge(pk, rp, K, proof, context)
+	n2 := pk.nSquare()
+	expected := modPow(K, e, n2)
+	expected.Mul(expected, proof.A).Mod(expected, n2)
+	if pk.encryptWithNonce(proof.Z1, proof.Z2).Cmp(expected) != 0 {
+		return errors.New("range proof failed: ciphertext mismatch")
+	}
+	expected = modPow(proof.S, e, rp.N)
+	expected.Mul(expected, proof.C).Mod(expected, rp.N)
+	if pedersenCommit(rp, proof.Z1, proof.Z3).Cmp(expected) != 0 {
+		return errors.New("range proof failed: commitment mismatch")
+	}
+	return nil
+}
+
+func encChallenge(pk *PaillierPublicKey, rp *RingPedersen, K *big.Int, proof *EncProof, context string) *big.Int {
+	tr := newTranscript("mpc/enc", context)
+	tr.ints(pk.N, rp.N, rp.S, rp.T, K, proof.S, proof.A, proof.C)
+	return tr.challenge()
+}
+
+// proveAffG 证明D = C^x * Enc_0(y; rho)、Y = Enc_1(y; rhoY)、X = x*G，x、y为响应方的MtA输入和掩码
+func proveAffG(st *affGStatement, x, y, rho, rhoY *big.Int, context string) (*AffGProof, error) {
+	alpha, err := randomSigned(bitBound(proofL + proofEpsilon))
+	if err != nil {
+		return nil, err
+	}
+	beta, err := randomSigned(bitBound(proofLPrime + proofEpsilon))
+	if err != nil {
+		return nil, err
+	}
+	r, err := randomUnit(st.verifier.N)
+	if err != nil {
+		return nil, err
+	}
+	ry, err := randomUnit(st.prover.N)
+	if err != nil {
+		return nil, err
+	}
+	hiding := new(big.Int).Mul(bitBound(proofL+proofEpsilon), st.rp.N)
+	small := new(big.Int).Mul(bitBound(proofL), st.rp.N)
+	var gamma, m, delta, mu *big.Int
+	for _, v := range []struct {
+		dest  **big.Int
+		bound *big.Int
+	}{{&gamma, hiding}, {&m, small}, {&delta, hiding}, {&mu, small}} {
+		if *v.dest, err = randomSigned(v.bound); err != nil {
+			return nil, err
+		}
+	}
+
+	Bx, err := encodePoint(scalarBaseMult(alpha))
+	if err != nil {
+		return nil, err
+	}
+	n2 := st.verifier.nSquare()
+	A := modPow(st.C, alpha, n2)
+	A.Mul(A, st.verifier.encryptWithNonce(beta, r)).Mod(A, n2)
+	proof := &AffGProof{
+		S:  pedersenCommit(st.rp, x, m),
+		T:  pedersenCommit(st.rp, y, mu),
+		A:  A,
+		Bx: Bx,
+		By: st.prover.encryptWithNonce(beta, ry),
+		E:  pedersenCommit(st.rp, alpha, gamma),
+		F:  pedersenCommit(st.rp, beta, delta),
+	}
+	e := affGChallenge(st, proof, context)
+
+	proof.Z1 = new(big.Int).Add(alpha, new(big.Int).Mul(e, x))
+	proof.Z2 = new(big.Int).Add(beta, new(big.Int).Mul(e, y))
+	proof.Z3 = new(big.Int).Add(gamma, new(big.Int).Mul(e, m))
+	proof.Z4 = new(big.Int).Add(delta, new(big.Int).Mul(e, mu))
+	proof.W = modPow(rho, e, st.verifier.N)
+	proof.W.Mul(proof.W, r).Mod(proof.W, st.verifier.N)
+	proof.Wy = modPow(rhoY, e, st.prover.N)
+	proof.Wy.Mul(proof.Wy, ry).Mod(proof.Wy, st.prover.N)
+	return proof, nil
+}
+
+// verifyAffG 校验响应方的MtA证明
+func verifyAffG(st *affGStatement, proof *AffGProof, context string) error {
+	if proof == nil || proof.Z1 == nil || proof.Z2 == nil || proof.Z3 == nil || proof.Z4 == nil {
+		return errors.New("missing mta proof")
+	}
+	if !st.verifier.ValidCiphertext(st.C) || !st.verifier.ValidCiphertext(st.D) || !st.verifier.ValidCiphertext(proof.A) ||
+		!st.prover.ValidCiphertext(st.Y) || !st.prover.ValidCiphertext(proof.By) ||
+		!isUnit(proof.W, st.verifier.N) || !isUnit(proof.Wy, st.prover.N) ||
+		!isUnit(proof.S, st.rp.N) || !isUnit(proof.T, st.rp.N) || !isUnit(proof.E, st.rp.N) || !isUnit(proof.F, st.rp.N) {
+		return errors.New("invalid mta proof")
+	}
+	if !inRange(proof.Z1, proofL+proofEpsilon) || !inRange(proof.Z2, proofLPrime+proofEpsilon) {
+		return errors.New("mta proof failed: value out of range")
+	}
+	X, err := decodePoint(st.X)
+	if err != nil {
+		return fmt.Errorf("invalid mta public point: %v", err)
+	}
+	Bx, err := decodePoint(proof.Bx)
+	if err != nil {
+		return fmt.Errorf("invalid mta proof: %v", err)
+	}
+
+	e := affGChallenge(st, proof, context)
+
+	// C^z1 * Enc_0(z2; w) = A * D^e
+	n2 := st.verifier.nSquare()
+	lhs := modPow(st.C, proof.Z1, n2)
+	lhs.Mul(lhs, st.verifier.encryptWithNonce(proof.Z2, proof.W)).Mod(lhs, n2)
+	rhs := modPow(st.D, e, n2)
+	rhs.Mul(rhs, proof.A).Mod(rhs, n2)
+	if lhs.Cmp(rhs) != 0 {
+		return errors.New("mta proof failed: response mismatch")
+	}
+
+	// z1*G = Bx + e*X
+	lhsPoint, err := encodePoint(scalarBaseMult(proof.Z1))
+	if err != nil {
+		return errors.New("mta proof failed: degenerate point")
+	}
+	rhsPoint, err := encodePoint(addPoints(Bx, scalarMult(e, X)))
+	if err != nil || !bytes.Equal(lhsPoint, rhsPoint) {
+		return errors.New("mta proof failed: public point mismatch")
+	}
+
+	// Enc_1(z2; wy) = By * Y^e
+	n2 = st.prover.nSquare()
+	rhs = modPow(st.Y, e, n2)
+	rhs.Mul(rhs, proof.By).Mod(rhs, n2)
+	if st.prover.encryptWithNonce(proof.Z2, proof.Wy).Cmp(rhs) != 0 {
+		return errors.New("mta proof failed: mask mismatch")
+	}
+
+	rhs = modPow(proof.S, e, st.rp.N)
+	rhs.Mul(rhs, proof.E).Mod(rhs, st.rp.N)
+	if pedersenCommit(st.rp, proof.Z1, proof.Z3).Cmp(rhs) != 0 {
+		return errors.New("mta proof failed: commitment mismatch")
+	}
+	rhs = modPow(proof.T, e, st.rp.N)
+	rhs.Mul(rhs, proof.F).Mod(rhs, st.rp.N)
+	if pedersenCommit(st.rp, proof.Z2, proof.Z4).Cmp(rhs) != 0 {
+		return errors.New("mta proof failed: commitment mismatch")
+	}
+	return nil
+}
+
+func affGChallenge(st *affGStatement, proof *AffGProof, context string) *big.Int {
+	tr := newTranscript("mpc/aff-g", context)
+	tr.ints(st.verifier.N, st.prover.N, st.rp.N, st.rp.S, st.rp.T, st.C, st.D, st.Y)
+	tr.bytes(st.X, proof.Bx)
+	tr.ints(proof.S, proof.T, proof.A, proof.By, proof.E, proof.F)
+	return tr.challenge()
+}
+
+// proveLogStar 证明X = x*G且C = Enc_0(x; rho)
+func proveLogStar(st *logStarStatement, x, rho *big.Int, context string) (*LogStarProof, error) {
+	G, err := decodePoint(st.G)
+	if err != nil {
+		return nil, err
+	}
+	alpha, err := randomSigned(bitBound(proofL + proofEpsilon))
+	if err != nil {
+		return nil, err
+	}
+	mu, err := randomSigned(new(big.Int).Mul(bitBound(proofL), st.rp.N))
+	if err != nil {
+		return nil, err
+	}
+	gamma, err := randomSigned(new(big.Int).Mul(bitBound(proofL+proofEpsilon), st.rp.N))
+	if err != nil {
+		return nil, err
+	}
+	r, err := randomUnit(st.prover.N)
+	if err != nil {
+		return nil, err
+	}
+	Y, err := encodePoint(scalarMult(alpha, G))
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &LogStarProof{
+		S: pedersenCommit(st.rp, x, mu),
+		A: st.prover.encryptWithNonce(alpha, r),
+		Y: Y,
+		D: pedersenCommit(st.rp, alpha, gamma),
+	}
+	e := logStarChallenge(st, proof, context)
+
+	proof.Z1 = new(big.Int).Add(alpha, new(big.Int).Mul(e, x))
+	proof.Z2 = modPow(rho, e, st.prover.N)
+	proof.Z2.Mul(proof.Z2, r).Mod(proof.Z2, st.prover.N)
+	proof.Z3 = new(big.Int).Add(gamma, new(big.Int).Mul(e, mu))
+	return proof, nil
+}
+
+// verifyLogStar 校验离散对数与密文一致性证明
+func verifyLogStar(st *logStarStatement, proof *LogStarProof, context string) error {
+	if proof == nil || proof.Z1 == nil || proof.Z3 == nil {
+		return errors.New("missing log proof")
+	}
+	if !st.prover.ValidCiphertext(st.C) || !st.prover.ValidCiphertext(proof.A) || !isUnit(proof.Z2, st.prover.N) ||
+		!isUnit(proof.S, st.rp.N) || !isUnit(proof.D, st.rp.N) {
+		return errors.New("invalid log proof")
+	}
+	if !inRange(proof.Z1, proofL+proofEpsilon) {
+		return errors.New("log proof failed: value out of range")
+	}
+	G, err := decodePoint(st.G)
+	if err != nil {
+		return fmt.Errorf("invalid log proof base point: %v", err)
+	}
+	X, err := decodePoint(st.X)
+	if err != nil {
+		return fmt.Errorf("invalid log proof public point: %v", err)
+	}
+	Y, err := decodePoint(proof.Y)
+	if err != nil {
+		return fmt.Errorf("invalid log proof: %v", err)
+	}
+
+	e := logStarChallenge(st, proof, context)
+
+	// Enc_0(z1; z2) = A * C^e
+	n2 := st.prover.nSquare()
+	rhs := modPow(st.C, e, n2)
+	rhs.Mul(rhs, proof.A).Mod(rhs, n2)
+	if st.prover.encryptWithNonce(proof.Z1, proof.Z2).Cmp(rhs) != 0 {
+		return errors.New("log proof failed: ciphertext mismatch")
+	}
+
+	// z1*G = Y + e*X
+	lhsPoint, err := encodePoint(scalarMult(proof.Z1, G))
+	if err != nil {
+		return errors.New("log proof failed: degenerate point")
+	}
+	rhsPoint, err := encodePoint(addPoints(Y, scalarMult(e, X)))
+	if err != nil || !bytes.Equal(lhsPoint, rhsPoint) {
+		return errors.New("log proof failed: public point mismatch")
+	}
+
+	rhs = modPow(proof.S, e, st.rp.N)
+	rhs.Mul(rhs, proof.D).Mod(rhs, st.rp.N)
+	if pedersenCommit(st.rp, proof.Z1, proof.Z3).Cmp(rhs) != 0 {
+		return errors.New("log proof failed: commitment mismatch")
+	}
+	return nil
+}
+
+func logStarChallenge(st *logStarStatement, proof *LogStarProof, context string) *big.Int {
+	tr := newTranscript("mpc/log-star", context)
+	tr.ints(st.prover.N, st.rp.N, st.rp.S, st.rp.T, st.C)
+	tr.bytes(st.G, st.X, proof.Y)
+	tr.ints(proof.S, proof.A, proof.D)
+	return tr.challenge()
+}
+
+// transcript Fiat-Shamir变换的哈希输入，每项带长度前缀
+type transcript struct {
+	buf bytes.Buffer
+}
+
+func newTranscript(label, context string) *transcript {
+	tr := &transcript{}
+	tr.bytes([]byte(label), []byte(context))
+	return tr
+}
+
+func (tr *transcript) bytes(values ...[]byte) {
+	for _, value := range values {
+		var length [4]byte
+		binary.BigEndian.PutUint32(length[:], uint32(len(value)))
+		tr.buf.Write(length[:])
+		tr.buf.Write(value)
+	}
+}
+
+// ints 写入整数，nil按0处理，符号单独编码
+func (tr *transcript) ints(values ...*big.Int) {
+	for _, value := range values {
+		if value == nil {
+			value = new(big.Int)
+		}
+		sign := byte(0)
+		if value.Sign() < 0 {
+			sign = 1
+		}
+		tr.bytes(append([]byte{sign}, value.Bytes()...))
+	}
+}
+
+// expand 由当前内容导出[0, bound)内的整数，多取128位使取模后的偏差可以忽略
+func (tr *transcript) expand(index int, bound *big.Int) *big.Int {
+	var out []byte
+	for counter := uint32(0); len(out)*8 < bound.BitLen()+128; counter++ {
+		var suffix [8]byte
+		binary.BigEndian.PutUint32(suffix[:4], uint32(index))
+		binary.BigEndian.PutUint32(suffix[4:], counter)
+		h := sha256.New()
+		h.Write(tr.buf.Bytes())
+		h.Write(suffix[:])
+		out = h.Sum(out)
+	}
+	return new(big.Int).Mod(new(big.Int).SetBytes(out), bound)
+}
+
+// challenge 导出[-q, q]内的挑战
+func (tr *transcript) challenge() *big.Int {
+	bound := new(big.Int).Lsh(curveN, 1)
+	e := tr.expand(0, bound.Add(bound, one))
+	return e.Sub(e, curveN)
+}
+
+// pedersenCommit 计算s^x * t^r mod N
+func pedersenCommit(rp *RingPedersen, x, r *big.Int) *big.Int {
+	c := modPow(rp.S, x, rp.N)
+	return c.Mul(c, modPow(rp.T, r, rp.N)).Mod(c, rp.N)
+}
+
+// modPow 计算base^exp mod m，exp为负时取逆元，调用方保证base与m互素
+func modPow(base, exp, m *big.Int) *big.Int {
+	if exp.Sign() >= 0 {
+		return new(big.Int).Exp(base, exp, m)
+	}
+	inverse := new(big.Int).ModInverse(base, m)
+	if inverse == nil {
+		return new(big.Int)
+	}
+	return inverse.Exp(inverse, new(big.Int).Neg(exp), m)
+}
+
+// modulusAdjust 计算(-1)^a * w^b * y mod N
+func modulusAdjust(y, w *big.Int, a, b bool, n *big.Int) *big.Int {
+	v := new(big.Int).Set(y)
+	if b {
+		v.Mul(v, w)
+	}
+	if a {
+		v.Neg(v)
+	}
+	return v.Mod(v, n)
+}
+
+func isQuadraticResidue(v, p *big.Int) bool {
+	return big.Jacobi(new(big.Int).Mod(v, p), p) == 1
+}
+
+// fourthRoot 计算v模pq的四次根，v须同时是模p和模q的二次剩余，p、q模4余3
+func fourthRoot(v, p, q *big.Int) *big.Int {
+	root := func(prime *big.Int) *big.Int {
+		e := new(big.Int).Add(prime, one)
+		e.Rsh(e, 2)
+		x := new(big.Int).Exp(new(big.Int).Mod(v, prime), e, prime)
+		return x.Exp(x, e, prime)
+	}
+	xp, xq := root(p), root(q)
+	// x = xp + p*((xq-xp)*p^-1 mod q)
+	h := new(big.Int).Sub(xq, xp)
+	h.Mul(h, new(big.Int).ModInverse(p, q)).Mod(h, q)
+	return h.Mul(h, p).Add(h, xp)
+}
+
+// bitBound 返回2^bits
+func bitBound(bits uint) *big.Int {
+	return new(big.Int).Lsh(one, bits)
+}
+
+// inRange 判断|x| <= 2^bits
+func inRange(x *big.Int, bits uint) bool {
+	return x != nil && x.CmpAbs(bitBound(bits)) <= 0
+}
+
+// isUnit 判断x属于Z*_N
+func isUnit(x, n *big.Int) bool {
+	if x == nil || n == nil || x.Sign() <= 0 || x.Cmp(n) >= 0 {
+		return false
+	}
+	return new(big.Int).GCD(nil, nil, x, n).Cmp(one) == 0
+}
+
+// randomUnit 生成Z*_N内的随机数
+func randomUnit(n *big.Int) (*big.Int, error) {
+	for {
+		r, err := rand.Int(rand.Reader, n)
+		if err != nil {
+			return nil, err
+		}
+		if isUnit(r, n) {
+			return r, nil
+		}
+	}
+}
+
+// randomSigned 生成[-bound, bound]内的随机数
+func randomSigned(bound *big.Int) (*big.Int, error) {
+	width := new(big.Int).Lsh(bound, 1)
+	r, err := rand.Int(rand.Reader, width.Add(width, one))
+	if err != nil {
+		return nil, err
+	}
+	return r.Sub(r, bound), nil
+}
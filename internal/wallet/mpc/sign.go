@@ -0,0 +1,522 @@
+package mpc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// MtA中用于掩盖乘积的随机数上界q^5，保证k*x+beta'不超过Paillier模数
+var mtaMaskBound = new(big.Int).Exp(curveN, big.NewInt(5), nil)
+
+// 签名会话所处的轮次
+const (
+	signStageRound1 = iota + 1
+	signStageRound2
+	signStageRound3
+)
+
+// signSession 签名会话。k_i、gamma_i只在本会话内使用，第四轮结束后丢弃
+type signSession struct {
+	share *KeyShare
+	hash  []byte
+	stage int
+
+	k     *big.Int
+	gamma *big.Int
+	Gamma hexutil.Bytes // gamma_i*G
+	blind hexutil.Bytes
+	encK  *big.Int
+	rho   *big.Int // Enc(k_i)的随机数，第三轮证明Delta_i时使用
+
+	parties     []int
+	commitments map[int]hexutil.Bytes
+	encKs       map[int]*big.Int // 各参与方的Enc(k_j)，第四轮校验Delta_j时使用
+	betas       []*big.Int       // -beta'，对应本节点作为MtA响应方时k_j*gamma_i的加性分片
+	nus         []*big.Int       // -nu'，对应k_j*w_i的加性分片
+	w           *big.Int         // lambda_i*x_i
+	delta       *big.Int
+	sigma       *big.Int
+	bigDelta    hexutil.Bytes // k_i*Gamma
+
+	created time.Time
+}
+
+// SignRound1 生成本次签名的k_i、gamma_i，输出Gamma_i的承诺、Enc(k_i)及向每个其他节点证明k_i范围的证明
+func (n *Node) SignRound1(req *SignRound1Request) (*SignRound1Response, error) {
+	if !idPattern.MatchString(req.SessionID) {
+		return nil, errors.New("invalid session id")
+	}
+	if len(req.Hash) != 32 {
+		return nil, errors.New("hash must be 32 bytes")
+	}
+	share, err := n.loadShare(req.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(share.RingPedersen) != len(share.PaillierKeys) || len(share.Identities) != len(share.PaillierKeys) {
+		return nil, fmt.Errorf("key share %s has no zero-knowledge proof parameters, generate a new key", req.KeyID)
+	}
+
+	k, err := randomScalar()
+	if err != nil {
+		return nil, err
+	}
+	gamma, err := randomScalar()
+	if err != nil {
+		return nil, err
+	}
+	Gamma, err := encodePoint(scalarBaseMult(gamma))
+	if err != nil {
+		return nil, err
+	}
+	blind := make([]byte, 32)
+	if _, err := rand.Read(blind); err != nil {
+		return nil, err
+	}
+	encK, rho, err := share.Paillier.encrypt(k)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &signSession{
+		share:   share,
+		hash:    req.Hash,
+		stage:   signStageRound1,
+		k:       k,
+		gamma:   gamma,
+		Gamma:   Gamma,
+		blind:   blind,
+		encK:    encK,
+		rho:     rho,
+		created: time.Now(),
+	}
+	resp := &SignRound1Response{
+		Commitment: gammaCommitment(Gamma, blind),
+		EncK:       encK,
+		EncProofs:  make(map[int]*EncProof, len(share.PaillierKeys)-1),
+	}
+	for index := range share.PaillierKeys {
+		if index == share.Index {
+			continue
+		}
+		proof, err := proveEnc(&share.Paillier.PaillierPublicKey, share.RingPedersen[index], encK, k, rho, session.context(req.SessionID, share.Index, index))
+		if err != nil {
+			return nil, err
+		}
+		resp.EncProofs[index] = proof
+	}
+	if resp.Signature, err = signMessage(n.identity, messageSignRound1, session.context(req.SessionID, share.Index, 0), resp); err != nil {
+		return nil, err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.cleanSessions()
+	if _, exists := n.signSessions[req.SessionID]; exists {
+		return nil, errors.New("sign session already exists")
+	}
+	n.signSessions[req.SessionID] = session
+	return resp, nil
+}
+
+// SignRound2 确定参与签名的节点集合，校验各参与方第一轮输出的签名和范围证明，
+// 对每个其他参与方的Enc(k_j)执行MtA：返回Enc_j(k_j*gamma_i+beta')和Enc_j(k_j*w_i+nu')及证明，
+// 本节点保留-beta'、-nu'
+func (n *Node) SignRound2(req *SignRound2Request) (*SignRound2Response, error) {
+	session, err := n.signSession(req.SessionID, signStageRound1)
+	if err != nil {
+		return nil, err
+	}
+	share := session.share
+
+	if err := checkSignParties(share, req.Parties); err != nil {
+		return nil, err
+	}
+	commitments := make(map[int]hexutil.Bytes, len(req.Parties))
+	encK := make(map[int]*big.Int, len(req.Parties))
+	for _, index := range req.Parties {
+		msg := req.Round1[index]
+		if msg == nil {
+			return nil, fmt.Errorf("missing round1 output from party %d", index)
+		}
+		unsigned := *msg
+		unsigned.Signature = nil
+		if err := verifyMessage(share.Identities[index], messageSignRound1, session.context(req.SessionID, index, 0), &unsigned, msg.Signature); err != nil {
+			return nil, fmt.Errorf("round1 output from party %d: %v", index, err)
+		}
+		if len(msg.Commitment) != 32 {
+			return nil, fmt.Errorf("missing commitment from party %d", index)
+		}
+		if !share.PaillierKeys[index].ValidCiphertext(msg.EncK) {
+			return nil, fmt.Errorf("invalid encrypted nonce from party %d", index)
+		}
+		if index != share.Index {
+			if err := verifyEnc(share.PaillierKeys[index], share.RingPedersen[share.Index], msg.EncK, msg.EncProofs[share.Index], session.context(req.SessionID, index, share.Index)); err != nil {
+				return nil, fmt.Errorf("encrypted nonce from party %d: %v", index, err)
+			}
+		}
+		commitments[index] = msg.Commitment
+		encK[index] = msg.EncK
+	}
+	if encK[share.Index].Cmp(session.encK) != 0 ||
+		!bytes.Equal(commitments[share.Index], gammaCommitment(session.Gamma, session.blind)) {
+		return nil, errors.New("round1 output of this node was altered")
+	}
+
+	// w_i = lambda_i*x_i，参与方的w_i之和等于私钥
+	w := lagrangeCoefficient(share.Index, req.Parties)
+	w.Mul(w, share.Share).Mod(w, curveN)
+	W, err := encodePoint(scalarBaseMult(w))
+	if err != nil {
+		return nil, err
+	}
+
+	own := &share.Paillier.PaillierPublicKey
+	mta := make(map[int]*MtAResponse, len(req.Parties)-1)
+	var betas, nus []*big.Int
+	for _, index := range req.Parties {
+		if index == share.Index {
+			continue
+		}
+		context := session.context(req.SessionID, share.Index, index)
+		gammaStatement := &affGStatement{verifier: share.PaillierKeys[index], prover: own, rp: share.RingPedersen[index], C: encK[index], X: session.Gamma}
+		gammaProof, beta, err := mtaRespond(gammaStatement, session.gamma, "gamma:"+context)
+		if err != nil {
+			return nil, err
+		}
+		wStatement := &affGStatement{verifier: share.PaillierKeys[index], prover: own, rp: share.RingPedersen[index], C: encK[index], X: W}
+		wProof, nu, err := mtaRespond(wStatement, w, "w:"+context)
+		if err != nil {
+			return nil, err
+		}
+
+		resp := &MtAResponse{
+			Gamma:      gammaStatement.D,
+			GammaY:     gammaStatement.Y,
+			GammaProof: gammaProof,
+			W:          wStatement.D,
+			WY:         wStatement.Y,
+			WProof:     wProof,
+			GammaPoint: session.Gamma,
+			Blind:      session.blind,
+		}
+		if resp.Signature, err = signMessage(n.identity, messageSignMtA, context, resp); err != nil {
+			return nil, err
+		}
+		mta[index] = resp
+		betas = append(betas, beta)
+		nus = append(nus, nu)
+	}
+
+	n.mu.Lock()
+	session.parties = req.Parties
+	session.commitments = commitments
+	session.encKs = encK
+	session.betas = betas
+	session.nus = nus
+	session.w = w
+	session.stage = signStageRound2
+	n.mu.Unlock()
+
+	return &SignRound2Response{MtA: mta}, nil
+}
+
+// SignRound3 校验其他参与方MtA响应的签名和证明后解密，得到delta_i = k_i*gamma的分片和
+// sigma_i = k_i*x的分片，公开delta_i并打开Gamma_i的承诺。同时公开Delta_i = k_i*Gamma，
+// 并向每个其他参与方证明其中的k_i与第一轮的Enc(k_i)一致，第四轮据此校验delta与R是否一致
+func (n *Node) SignRound3(req *SignRound3Request) (*SignRound3Response, error) {
+	session, err := n.signSession(req.SessionID, signStageRound2)
+	if err != nil {
+		return nil, err
+	}
+	share := session.share
+	own := &share.Paillier.PaillierPublicKey
+
+	delta := new(big.Int).Mul(session.k, session.gamma)
+	sigma := new(big.Int).Mul(session.k, session.w)
+	Gamma, err := decodePoint(session.Gamma)
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range session.parties {
+		if index == share.Index {
+			continue
+		}
+		resp := req.MtA[index]
+		if resp == nil {
+			return nil, fmt.Errorf("missing mta response from party %d", index)
+		}
+		context := session.context(req.SessionID, index, share.Index)
+		unsigned := *resp
+		unsigned.Signature = nil
+		if err := verifyMessage(share.Identities[index], messageSignMtA, context, &unsigned, resp.Signature); err != nil {
+			return nil, fmt.Errorf("mta response from party %d: %v", index, err)
+		}
+		if !bytes.Equal(gammaCommitment(resp.GammaPoint, resp.Blind), session.commitments[index]) {
+			return nil, fmt.Errorf("gamma of party %d does not match its commitment", index)
+		}
+		W, err := partyW(share, index, session.parties)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public share of party %d: %v", index, err)
+		}
+
+		gammaStatement := &affGStatement{verifier: own, prover: share.PaillierKeys[index], rp: share.RingPedersen[share.Index], C: session.encK, D: resp.Gamma, Y: resp.GammaY, X: resp.GammaPoint}
+		if err := verifyAffG(gammaStatement, resp.GammaProof, "gamma:"+context); err != nil {
+			return nil, fmt.Errorf("mta response from party %d: %v", index, err)
+		}
+		wStatement := &affGStatement{verifier: own, prover: share.PaillierKeys[index], rp: share.RingPedersen[share.Index], C: session.encK, D: resp.W, Y: resp.WY, X: W}
+		if err := verifyAffG(wStatement, resp.WProof, "w:"+context); err != nil {
+			return nil, fmt.Errorf("mta response from party %d: %v", index, err)
+		}
+
+		alpha, err := share.Paillier.Decrypt(resp.Gamma)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mta response from party %d: %v", index, err)
+		}
+		mu, err := share.Paillier.Decrypt(resp.W)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mta response from party %d: %v", index, err)
+		}
+		delta.Add(delta, alpha)
+		sigma.Add(sigma, mu)
+
+		point, err := decodePoint(resp.GammaPoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gamma from party %d: %v", index, err)
+		}
+		Gamma = addPoints(Gamma, point)
+	}
+	for i := range session.betas {
+		delta.Add(delta, session.betas[i])
+		sigma.Add(sigma, session.nus[i])
+	}
+	delta.Mod(delta, curveN)
+	sigma.Mod(sigma, curveN)
+
+	encodedGamma, err := encodePoint(Gamma)
+	if err != nil {
+		return nil, err
+	}
+	bigDelta, err := encodePoint(scalarMult(session.k, Gamma))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &SignRound3Response{
+		Delta:       delta,
+		Gamma:       session.Gamma,
+		Blind:       session.blind,
+		BigDelta:    bigDelta,
+		DeltaProofs: make(map[int]*LogStarProof, len(session.parties)-1),
+	}
+	for _, index := range session.parties {
+		if index == share.Index {
+			continue
+		}
+		st := &logStarStatement{prover: own, rp: share.RingPedersen[index], C: session.encK, G: encodedGamma, X: bigDelta}
+		proof, err := proveLogStar(st, session.k, session.rho, session.context(req.SessionID, share.Index, index))
+		if err != nil {
+			return nil, err
+		}
+		resp.DeltaProofs[index] = proof
+	}
+	if resp.Signature, err = signMessage(n.identity, messageSignRound3, session.context(req.SessionID, share.Index, 0), resp); err != nil {
+		return nil, err
+	}
+
+	n.mu.Lock()
+	session.delta = delta
+	session.sigma = sigma
+	session.bigDelta = bigDelta
+	session.stage = signStageRound3
+	n.mu.Unlock()
+
+	return resp, nil
+}
+
+// SignRound4 校验各参与方第三轮输出的签名和Gamma_j承诺，计算R = (sum delta_j)^-1 * sum Gamma_j。
+// 公开s_i前先校验每个Delta_j = k_j*Gamma的证明，并检查delta*G = sum Delta_j：任一参与方公开了错误的delta_j时
+// R不等于k^-1*G，此时公开s_i会泄露私钥信息，因此中止。通过后输出部分签名s_i = m*k_i + r*sigma_i。
+// 会话随即删除，k_i不会再被使用
+func (n *Node) SignRound4(req *SignRound4Request) (*SignRound4Response, error) {
+	session, err := n.signSession(req.SessionID, signStageRound3)
+	if err != nil {
+		return nil, err
+	}
+	n.mu.Lock()
+	delete(n.signSessions, req.SessionID)
+	n.mu.Unlock()
+	share := session.share
+
+	delta := new(big.Int)
+	var Gamma *btcec.JacobianPoint
+	for _, index := range session.parties {
+		msg := req.Round3[index]
+		if msg == nil || msg.Delta == nil {
+			return nil, fmt.Errorf("missing delta from party %d", index)
+		}
+		unsigned := *msg
+		unsigned.Signature = nil
+		if err := verifyMessage(share.Identities[index], messageSignRound3, session.context(req.SessionID, index, 0), &unsigned, msg.Signature); err != nil {
+			return nil, fmt.Errorf("round3 output from party %d: %v", index, err)
+		}
+		if index == share.Index && (msg.Delta.Cmp(session.delta) != 0 || !bytes.Equal(msg.BigDelta, session.bigDelta)) {
+			return nil, errors.New("round3 output of this node was altered")
+		}
+		if !bytes.Equal(gammaCommitment(msg.Gamma, msg.Blind), session.commitments[index]) {
+			return nil, fmt.Errorf("gamma of party %d does not match its commitment", index)
+		}
+		point, err := decodePoint(msg.Gamma)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gamma from party %d: %v", index, err)
+		}
+		delta.Add(delta, msg.Delta)
+		if Gamma == nil {
+			Gamma = point
+		} else {
+			Gamma = addPoints(Gamma, point)
+		}
+	}
+	delta.Mod(delta, curveN)
+	if delta.Sign() == 0 {
+		return nil, errors.New("degenerate delta")
+	}
+
+	encodedGamma, err := encodePoint(Gamma)
+	if err != nil {
+		return nil, err
+	}
+	var sumDelta *btcec.JacobianPoint
+	for _, index := range session.parties {
+		msg := req.Round3[index]
+		point, err := decodePoint(msg.BigDelta)
+		if err != nil {
+			return nil, fmt.Errorf("invalid big delta from party %d: %v", index, err)
+		}
+		if index != share.Index {
+			st := &logStarStatement{prover: share.PaillierKeys[index], rp: share.RingPedersen[share.Index], C: session.encKs[index], G: encodedGamma, X: msg.BigDelta}
+			if err := verifyLogStar(st, msg.DeltaProofs[share.Index], session.context(req.SessionID, index, share.Index)); err != nil {
+				return nil, fmt.Errorf("big delta from party %d: %v", index, err)
+			}
+		}
+		if sumDelta == nil {
+			sumDelta = point
+		} else {
+			sumDelta = addPoints(sumDelta, point)
+		}
+	}
+	expected, err := encodePoint(scalarBaseMult(delta))
+	if err != nil {
+		return nil, err
+	}
+	actual, err := encodePoint(sumDelta)
+	if err != nil || !bytes.Equal(expected, actual) {
+		return nil, errors.New("delta shares are inconsistent with the committed nonces, refusing to release the partial signature")
+	}
+
+	// R = k^-1*G，其中k = sum k_j
+	R := scalarMult(new(big.Int).ModInverse(delta, curveN), Gamma)
+	encodedR, err := encodePoint(R)
+	if err != nil {
+		return nil, err
+	}
+	r := pointX(R)
+	r.Mod(r, curveN)
+	if r.Sign() == 0 {
+		return nil, errors.New("degenerate r")
+	}
+
+	m := new(big.Int).SetBytes(session.hash)
+	s := new(big.Int).Mul(m, session.k)
+	s.Add(s, new(big.Int).Mul(r, session.sigma))
+	s.Mod(s, curveN)
+
+	return &SignRound4Response{R: encodedR, S: s}, nil
+}
+
+// signSession 获取处于指定轮次的签名会话
+func (n *Node) signSession(sessionID string, stage int) (*signSession, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	session, ok := n.signSessions[sessionID]
+	if !ok {
+		return nil, errors.New("sign session not found")
+	}
+	if session.stage != stage {
+		return nil, errors.New("sign session is not at the expected round")
+	}
+	return session, nil
+}
+
+// checkSignParties 参与方须互不相同、包含本节点，且数量不少于门限
+func checkSignParties(share *KeyShare, parties []int) error {
+	if len(parties) < share.Threshold {
+		return fmt.Errorf("need at least %d parties, got %d", share.Threshold, len(parties))
+	}
+	seen := make(map[int]bool, len(parties))
+	for _, index := range parties {
+		if seen[index] {
+			return fmt.Errorf("duplicate party %d", index)
+		}
+		if _, ok := share.PaillierKeys[index]; !ok {
+			return fmt.Errorf("unknown party %d", index)
+		}
+		seen[index] = true
+	}
+	if !seen[share.Index] {
+		return errors.New("this node is not a signing party")
+	}
+	return nil
+}
+
+// mtaRespond 作为MtA响应方计算D = Enc_j(k_j*x + beta')和Y = Enc_i(beta')并生成证明，
+// 填入st的D、Y，返回证明和本方分片-beta' mod q
+func mtaRespond(st *affGStatement, x *big.Int, context string) (*AffGProof, *big.Int, error) {
+	betaPrime, err := rand.Int(rand.Reader, mtaMaskBound)
+	if err != nil {
+		return nil, nil, err
+	}
+	encBeta, rho, err := st.verifier.encrypt(betaPrime)
+	if err != nil {
+		return nil, nil, err
+	}
+	Y, rhoY, err := st.prover.encrypt(betaPrime)
+	if err != nil {
+		return nil, nil, err
+	}
+	st.D = st.verifier.Add(st.verifier.MulConst(st.C, x), encBeta)
+	st.Y = Y
+	proof, err := proveAffG(st, x, betaPrime, rho, rhoY, context)
+	if err != nil {
+		return nil, nil, err
+	}
+	beta := new(big.Int).Neg(betaPrime)
+	return proof, beta.Mod(beta, curveN), nil
+}
+
+// partyW 计算参与方的lambda_j*X_j，即其w_j对应的公开点
+func partyW(share *KeyShare, index int, parties []int) (hexutil.Bytes, error) {
+	X, err := decodePoint(share.PublicShares[index])
+	if err != nil {
+		return nil, err
+	}
+	return encodePoint(scalarMult(lagrangeCoefficient(index, parties), X))
+}
+
+// context 签名消息和证明绑定的会话、密钥、待签哈希及收发双方，广播消息的to为0
+func (s *signSession) context(sessionID string, from, to int) string {
+	return fmt.Sprintf("%s:%s:%x:%d:%d", sessionID, s.share.KeyID, s.hash, from, to)
+}
+
+// gammaCommitment Gamma_i的哈希承诺
+func gammaCommitment(Gamma, blind []byte) hexutil.Bytes {
+	return crypto.Keccak256(Gamma, blind)
+}
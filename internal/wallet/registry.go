@@ -0,0 +1,53 @@
+package wallet
+
+// ChainKind 链的实现类别，决定使用哪个工厂创建钱包
+type ChainKind string
+
+const (
+	ChainKindEVM       ChainKind = "evm"
+	ChainKindSimulated ChainKind = "simulated" // 进程内模拟EVM链，仅用于开发模式
+	ChainKindBitcoin   ChainKind = "bitcoin"
+	ChainKindSolana    ChainKind = "solana"
+	ChainKindTron      ChainKind = "tron"
+	ChainKindCosmos    ChainKind = "cosmos"
+)
+
+// ChainInfo 链注册表中的链信息
+type ChainInfo struct {
+	ChainType      ChainType `json:"chainType"`
+	Kind           ChainKind `json:"kind"`
+	Name           string    `json:"name"`
+	ChainID        uint64    `json:"chainId"`
+	RPCURLs        []string  `json:"-"` // 可能包含API密钥，不对外暴露
+	NativeSymbol   string    `json:"nativeSymbol"`
+	NativeDecimals int       `json:"nativeDecimals"`
+	ExplorerURL    string    `json:"explorerUrl,omitempty"`
+	EIP1559        bool      `json:"eip1559"`
+	Network        string    `json:"network,omitempty"`      // 非EVM链的网络，如比特币的mainnet/testnet3/regtest/signet，Cosmos链的chain-id
+	AddressType    string    `json:"addressType,omitempty"`  // 非EVM链的默认地址类型，如比特币的p2wpkh/p2tr
+	Bech32Prefix   string    `json:"bech32Prefix,omitempty"` // Cosmos链的地址前缀，如cosmos/osmo
+	CoinType       uint32    `json:"coinType,omitempty"`     // Cosmos链的BIP44币种，默认118
+	Denom          string    `json:"denom,omitempty"`        // Cosmos链的原生代币denom，如uatom
+	GasPrice       float64   `json:"gasPrice,omitempty"`     // Cosmos链的gas价格，单位denom/gas
+	BundlerURL     string    `json:"-"`                      // ERC-4337 bundler地址，可能包含API密钥，不对外暴露
+}
+
+// WalletFactory 根据链信息创建钱包实现
+type WalletFactory func(chain ChainInfo) (Wallet, error)
+
+// RPCEndpointHealth 单个RPC节点的健康状况，Endpoint只包含主机名，不暴露URL中的API密钥
+type RPCEndpointHealth struct {
+	Endpoint    string  `json:"endpoint"`
+	Healthy     bool    `json:"healthy"`
+	LatencyMs   int64   `json:"latencyMs"`
+	BlockNumber uint64  `json:"blockNumber"`
+	BlockLag    uint64  `json:"blockLag"`
+	ErrorRate   float64 `json:"errorRate"`
+	LastError   string  `json:"lastError,omitempty"`
+	LastCheck   int64   `json:"lastCheck"`
+}
+
+// RPCHealthReporter 可选接口，配置了多个RPC节点的钱包实现上报节点健康状况
+type RPCHealthReporter interface {
+	RPCHealth() []RPCEndpointHealth
+}
@@ -0,0 +1,94 @@
+package solana
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"filippo.io/edwards25519"
+	"github.com/btcsuite/btcd/btcutil/base58"
+)
+
+// PublicKeySize 公钥（地址）长度
+const PublicKeySize = 32
+
+// PublicKey Solana公钥，即账户地址，以base58编码展示
+type PublicKey [PublicKeySize]byte
+
+// 程序地址
+var (
+	SystemProgramID                 = MustPublicKey("11111111111111111111111111111111")
+	TokenProgramID                  = MustPublicKey("TokenkegQfeZyiNwAJbNbGKPFXCWuBvf9Ss623VQ5DA")
+	Token2022ProgramID              = MustPublicKey("TokenzQdBNbLqP5VEhdkAS6EPFLC1PHnBqCXEpPxuEb")
+	AssociatedTokenAccountProgramID = MustPublicKey("ATokenGPvbdGVxr1b2hvZbsiqW5xWH25efTNsLJA8knL")
+	MemoProgramID                   = MustPublicKey("MemoSq4gqABAXKb96qnH8TysNcWxMyWCqXgDLGmfcHr")
+)
+
+// PublicKeyFromBase58 解析base58编码的公钥
+func PublicKeyFromBase58(s string) (PublicKey, error) {
+	var key PublicKey
+	decoded := base58.Decode(s)
+	if len(decoded) != PublicKeySize {
+		return key, fmt.Errorf("invalid address format: %s", s)
+	}
+	copy(key[:], decoded)
+	return key, nil
+}
+
+// MustPublicKey 解析公钥，失败时panic，仅用于常量
+func MustPublicKey(s string) PublicKey {
+	key, err := PublicKeyFromBase58(s)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// String 返回base58编码的地址
+func (k PublicKey) String() string {
+	return base58.Encode(k[:])
+}
+
+// isOnCurve 判断公钥是否为ed25519曲线上的点，程序派生地址必须不在曲线上
+func isOnCurve(key []byte) bool {
+	_, err := new(edwards25519.Point).SetBytes(key)
+	return err == nil
+}
+
+// createProgramAddress 由种子和程序ID计算程序派生地址（PDA）
+func createProgramAddress(seeds [][]byte, programID PublicKey) (PublicKey, error) {
+	h := sha256.New()
+	for _, seed := range seeds {
+		if len(seed) > 32 {
+			return PublicKey{}, errors.New("seed too long")
+		}
+		h.Write(seed)
+	}
+	h.Write(programID[:])
+	h.Write([]byte("ProgramDerivedAddress"))
+
+	var key PublicKey
+	copy(key[:], h.Sum(nil))
+	if isOnCurve(key[:]) {
+		return PublicKey{}, errors.New("derived address is on the ed25519 curve")
+	}
+	return key, nil
+}
+
+// findProgramAddress 从255开始递减查找第一个有效的bump，返回PDA和bump
+func findProgramAddress(seeds [][]byte, programID PublicKey) (PublicKey, uint8, error) {
+	for bump := 255; bump >= 0; bump-- {
+		seedsWithBump := append(append([][]byte{}, seeds...), []byte{byte(bump)})
+		key, err := createProgramAddress(seedsWithBump, programID)
+		if err == nil {
+			return key, uint8(bump), nil
+		}
+	}
+	return PublicKey{}, 0, errors.New("unable to find a valid program address")
+}
+
+// associatedTokenAddress 计算钱包在某个代币下的关联代币账户（ATA）地址
+func associatedTokenAddress(owner PublicKey, mint PublicKey, tokenProgramID PublicKey) (PublicKey, error) {
+	key, _, err := findProgramAddress([][]byte{owner[:], tokenProgramID[:], mint[:]}, AssociatedTokenAccountProgramID)
+	return key, err
+}
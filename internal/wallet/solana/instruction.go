@@ -0,0 +1,295 @@
+package solana
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// AccountMeta 指令引用的账户及其权限
+type AccountMeta struct {
+	PublicKey  PublicKey
+	IsSigner   bool
+	IsWritable bool
+}
+
+// Instruction 交易中的单条指令
+type Instruction struct {
+	ProgramID PublicKey
+	Accounts  []AccountMeta
+	Data      []byte
+}
+
+// 系统程序和SPL代币程序的指令编号
+const (
+	systemInstructionTransfer          = 2
+	tokenInstructionTransferChecked    = 12
+	associatedTokenCreateIdempotentIdx = 1
+)
+
+// systemTransferInstruction 系统程序转账SOL
+func systemTransferInstruction(from PublicKey, to PublicKey, lamports uint64) Instruction {
+	data := make([]byte, 12)
+	binary.LittleEndian.PutUint32(data[0:4], systemInstructionTransfer)
+	binary.LittleEndian.PutUint64(data[4:12], lamports)
+
+	return Instruction{
+		ProgramID: SystemProgramID,
+		Accounts: []AccountMeta{
+			{PublicKey: from, IsSigner: true, IsWritable: true},
+			{PublicKey: to, IsWritable: true},
+		},
+		Data: data,
+	}
+}
+
+// createAssociatedTokenAccountIdempotentInstruction 创建关联代币账户，账户已存在时不报错
+func createAssociatedTokenAccountIdempotentInstruction(payer PublicKey, ata PublicKey, owner PublicKey, mint PublicKey, tokenProgramID PublicKey) Instruction {
+	return Instruction{
+		ProgramID: AssociatedTokenAccountProgramID,
+		Accounts: []AccountMeta{
+			{PublicKey: payer, IsSigner: true, IsWritable: true},
+			{PublicKey: ata, IsWritable: true},
+			{PublicKey: owner},
+			{PublicKey: mint},
+			{PublicKey: SystemProgramID},
+			{PublicKey: tokenProgramID},
+		},
+		Data: []byte{associatedTokenCreateIdempotentIdx},
+	}
+}
+
+// transferCheckedInstruction SPL代币转账，链上校验mint和精度
+func transferCheckedInstruction(source PublicKey, mint PublicKey, destination PublicKey, owner PublicKey, amount uint64, decimals uint8, tokenProgramID PublicKey) Instruction {
+	data := make([]byte, 10)
+	data[0] = tokenInstructionTransferChecked
+	binary.LittleEndian.PutUint64(data[1:9], amount)
+	data[9] = decimals
+
+	return Instruction{
+		ProgramID: tokenProgramID,
+		Accounts: []AccountMeta{
+			{PublicKey: source, IsWritable: true},
+			{PublicKey: mint},
+			{PublicKey: destination, IsWritable: true},
+			{PublicKey: owner, IsSigner: true},
+		},
+		Data: data,
+	}
+}
+
+// memoInstruction 备注指令，相当于EVM交易的附加数据
+func memoInstruction(signer PublicKey, memo []byte) Instruction {
+	return Instruction{
+		ProgramID: MemoProgramID,
+		Accounts:  []AccountMeta{{PublicKey: signer, IsSigner: true}},
+		Data:      memo,
+	}
+}
+
+// appendCompactU16 按Solana的compact-u16格式追加长度
+func appendCompactU16(buf []byte, n int) []byte {
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if n == 0 {
+			return append(buf, b)
+		}
+		buf = append(buf, b|0x80)
+	}
+}
+
+// readCompactU16 读取compact-u16长度，返回值和占用的字节数
+func readCompactU16(buf []byte) (int, int, error) {
+	n := 0
+	for i := 0; i < 3; i++ {
+		if i >= len(buf) {
+			return 0, 0, errors.New("unexpected end of data")
+		}
+		n |= int(buf[i]&0x7f) << (7 * i)
+		if buf[i]&0x80 == 0 {
+			return n, i + 1, nil
+		}
+	}
+	return 0, 0, errors.New("invalid compact-u16")
+}
+
+// compileMessage 编译legacy格式的交易消息。账户按 可写签名者、只读签名者、可写非签名者、
+// 只读非签名者 排序，付费账户固定在第一位
+func compileMessage(feePayer PublicKey, instructions []Instruction, recentBlockhash PublicKey) ([]byte, error) {
+	metas := []*AccountMeta{{PublicKey: feePayer, IsSigner: true, IsWritable: true}}
+	index := map[PublicKey]*AccountMeta{feePayer: metas[0]}
+
+	addAccount := func(meta AccountMeta) {
+		if existing, ok := index[meta.PublicKey]; ok {
+			existing.IsSigner = existing.IsSigner || meta.IsSigner
+			existing.IsWritable = existing.IsWritable || meta.IsWritable
+			return
+		}
+		m := meta
+		metas = append(metas, &m)
+		index[m.PublicKey] = &m
+	}
+	for _, ix := range instructions {
+		for _, account := range ix.Accounts {
+			addAccount(account)
+		}
+		addAccount(AccountMeta{PublicKey: ix.ProgramID})
+	}
+
+	groups := make([][]*AccountMeta, 4)
+	for _, meta := range metas {
+		switch {
+		case meta.IsSigner && meta.IsWritable:
+			groups[0] = append(groups[0], meta)
+		case meta.IsSigner:
+			groups[1] = append(groups[1], meta)
+		case meta.IsWritable:
+			groups[2] = append(groups[2], meta)
+		default:
+			groups[3] = append(groups[3], meta)
+		}
+	}
+
+	keys := make([]PublicKey, 0, len(metas))
+	keyIndex := make(map[PublicKey]int, len(metas))
+	for _, group := range groups {
+		for _, meta := range group {
+			keyIndex[meta.PublicKey] = len(keys)
+			keys = append(keys, meta.PublicKey)
+		}
+	}
+	if len(keys) > 256 {
+		return nil, errors.New("too many accounts in transaction")
+	}
+
+	msg := []byte{
+		byte(len(groups[0]) + len(groups[1])), // 需要的签名数
+		byte(len(groups[1])),                  // 只读签名账户数
+		byte(len(groups[3])),                  // 只读非签名账户数
+	}
+	msg = appendCompactU16(msg, len(keys))
+	for _, key := range keys {
+		msg = append(msg, key[:]...)
+	}
+	msg = append(msg, recentBlockhash[:]...)
+
+	msg = appendCompactU16(msg, len(instructions))
+	for _, ix := range instructions {
+		msg = append(msg, byte(keyIndex[ix.ProgramID]))
+		msg = appendCompactU16(msg, len(ix.Accounts))
+		for _, account := range ix.Accounts {
+			msg = append(msg, byte(keyIndex[account.PublicKey]))
+		}
+		msg = appendCompactU16(msg, len(ix.Data))
+		msg = append(msg, ix.Data...)
+	}
+
+	return msg, nil
+}
+
+// messageSigners 返回消息中需要签名的账户，顺序与交易中的签名顺序一致
+func messageSigners(msg []byte) ([]PublicKey, error) {
+	if len(msg) < 3 {
+		return nil, errors.New("message too short")
+	}
+	numSigners := int(msg[0])
+
+	numKeys, n, err := readCompactU16(msg[3:])
+	if err != nil {
+		return nil, err
+	}
+	offset := 3 + n
+	if numSigners > numKeys || len(msg) < offset+numKeys*PublicKeySize {
+		return nil, errors.New("malformed message")
+	}
+
+	signers := make([]PublicKey, numSigners)
+	for i := range signers {
+		copy(signers[i][:], msg[offset+i*PublicKeySize:])
+	}
+	return signers, nil
+}
+
+// decodeMessage 解析legacy格式的交易消息中的指令，指令的账户只填充公钥
+func decodeMessage(msg []byte) ([]Instruction, error) {
+	if len(msg) < 3 {
+		return nil, errors.New("message too short")
+	}
+	if msg[0]&0x80 != 0 {
+		return nil, errors.New("versioned messages are not supported")
+	}
+
+	numKeys, n, err := readCompactU16(msg[3:])
+	if err != nil {
+		return nil, err
+	}
+	offset := 3 + n
+	if len(msg) < offset+numKeys*PublicKeySize+PublicKeySize {
+		return nil, errors.New("malformed message")
+	}
+	keys := make([]PublicKey, numKeys)
+	for i := range keys {
+		copy(keys[i][:], msg[offset+i*PublicKeySize:])
+	}
+	offset += numKeys*PublicKeySize + PublicKeySize // 跳过recent blockhash
+
+	key := func(index byte) (PublicKey, error) {
+		if int(index) >= len(keys) {
+			return PublicKey{}, errors.New("account index out of range")
+		}
+		return keys[index], nil
+	}
+	next := func() (int, error) {
+		v, n, err := readCompactU16(msg[offset:])
+		offset += n
+		return v, err
+	}
+
+	numInstructions, err := next()
+	if err != nil {
+		return nil, err
+	}
+	instructions := make([]Instruction, 0, numInstructions)
+	for i := 0; i < numInstructions; i++ {
+		if offset >= len(msg) {
+			return nil, errors.New("unexpected end of data")
+		}
+		var ix Instruction
+		if ix.ProgramID, err = key(msg[offset]); err != nil {
+			return nil, err
+		}
+		offset++
+
+		numAccounts, err := next()
+		if err != nil {
+			return nil, err
+		}
+		if len(msg) < offset+numAccounts {
+			return nil, errors.New("unexpected end of data")
+		}
+		for _, index := range msg[offset : offset+numAccounts] {
+			account, err := key(index)
+			if err != nil {
+				return nil, err
+			}
+			ix.Accounts = append(ix.Accounts, AccountMeta{PublicKey: account})
+		}
+		offset += numAccounts
+
+		dataLen, err := next()
+		if err != nil {
+			return nil, err
+		}
+		if len(msg) < offset+dataLen {
+			return nil, errors.New("unexpected end of data")
+		}
+		ix.Data = msg[offset : offset+dataLen]
+		offset += dataLen
+
+		instructions = append(instructions, ix)
+	}
+	if offset != len(msg) {
+		return nil, errors.New("trailing data after instructions")
+	}
+	return instructions, nil
+}
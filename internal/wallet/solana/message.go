@@ -0,0 +1,41 @@
+package solana
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// SignMessage 用ed25519直接签名消息原文，与钱包适配器的signMessage一致，返回64字节签名
+func (w *SolanaWallet) SignMessage(ctx context.Context, walletID string, message []byte) ([]byte, error) {
+	privKey, _, err := w.getPrivateKey(walletID)
+	if err != nil {
+		return nil, err
+	}
+	return ed25519.Sign(privKey, message), nil
+}
+
+// VerifyMessage 校验ed25519消息签名，ed25519无法从签名恢复公钥，只能用期望的签名者校验
+func (w *SolanaWallet) VerifyMessage(ctx context.Context, expectedSigner string, message []byte, signature []byte) (*wallet.SignatureVerification, error) {
+	signer, err := PublicKeyFromBase58(expectedSigner)
+	if err != nil {
+		return nil, err
+	}
+	if len(signature) != ed25519.SignatureSize {
+		return nil, errors.New("invalid signature length")
+	}
+
+	valid := ed25519.Verify(ed25519.PublicKey(signer[:]), message, signature)
+
+	result := &wallet.SignatureVerification{
+		ExpectedSigner: expectedSigner,
+		Valid:          valid,
+		Method:         wallet.VerifyMethodEd25519,
+	}
+	if valid {
+		result.RecoveredAddress = expectedSigner
+	}
+	return result, nil
+}
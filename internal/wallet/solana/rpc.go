@@ -0,0 +1,214 @@
+package solana
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// 查询和预检使用的确认级别
+const defaultCommitment = "confirmed"
+
+// rpcError Solana节点返回的JSON-RPC错误
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("solana rpc error %d: %s", e.Code, e.Message)
+}
+
+// rpcClient Solana JSON-RPC客户端，可以指向solana-test-validator（http://127.0.0.1:8899）
+type rpcClient struct {
+	url        string
+	httpClient *http.Client
+	nextID     uint64
+}
+
+// newRPCClient 创建RPC客户端
+func newRPCClient(url string) *rpcClient {
+	return &rpcClient{
+		url:        url,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Call 调用RPC方法，result为nil时忽略返回值
+func (c *rpcClient) Call(ctx context.Context, method string, params []interface{}, result interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      atomic.AddUint64(&c.nextID, 1),
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", wallet.ErrChainUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: solana rpc returned status %d", wallet.ErrChainUnavailable, resp.StatusCode)
+	}
+
+	var rpcResp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return fmt.Errorf("failed to decode response (status %d): %v", resp.StatusCode, err)
+	}
+	if rpcResp.Error != nil {
+		return rpcResp.Error
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(rpcResp.Result, result); err != nil {
+		return fmt.Errorf("failed to decode %s result: %v", method, err)
+	}
+
+	return nil
+}
+
+// commitmentConfig 查询参数中的确认级别配置
+func commitmentConfig() map[string]interface{} {
+	return map[string]interface{}{"commitment": defaultCommitment}
+}
+
+// getBalance 查询账户余额，单位lamports
+func (c *rpcClient) getBalance(ctx context.Context, account PublicKey) (uint64, error) {
+	var result struct {
+		Value uint64 `json:"value"`
+	}
+	if err := c.Call(ctx, "getBalance", []interface{}{account.String(), commitmentConfig()}, &result); err != nil {
+		return 0, err
+	}
+	return result.Value, nil
+}
+
+// accountInfo 账户信息，不存在的账户为nil
+type accountInfo struct {
+	Owner    string `json:"owner"`
+	Lamports uint64 `json:"lamports"`
+}
+
+// getAccountInfo 查询账户信息，账户不存在时返回nil
+func (c *rpcClient) getAccountInfo(ctx context.Context, account PublicKey) (*accountInfo, error) {
+	var result struct {
+		Value *accountInfo `json:"value"`
+	}
+	params := []interface{}{account.String(), map[string]interface{}{
+		"commitment": defaultCommitment,
+		"encoding":   "base64",
+		"dataSlice":  map[string]int{"offset": 0, "length": 0},
+	}}
+	if err := c.Call(ctx, "getAccountInfo", params, &result); err != nil {
+		return nil, err
+	}
+	return result.Value, nil
+}
+
+// tokenAmount SPL代币金额
+type tokenAmount struct {
+	Amount   string `json:"amount"`
+	Decimals uint8  `json:"decimals"`
+}
+
+// getTokenAccountBalance 查询代币账户余额
+func (c *rpcClient) getTokenAccountBalance(ctx context.Context, account PublicKey) (*tokenAmount, error) {
+	var result struct {
+		Value tokenAmount `json:"value"`
+	}
+	if err := c.Call(ctx, "getTokenAccountBalance", []interface{}{account.String(), commitmentConfig()}, &result); err != nil {
+		return nil, err
+	}
+	return &result.Value, nil
+}
+
+// getTokenDecimals 通过getTokenSupply查询代币精度
+func (c *rpcClient) getTokenDecimals(ctx context.Context, mint PublicKey) (uint8, error) {
+	var result struct {
+		Value tokenAmount `json:"value"`
+	}
+	if err := c.Call(ctx, "getTokenSupply", []interface{}{mint.String(), commitmentConfig()}, &result); err != nil {
+		return 0, err
+	}
+	return result.Value.Decimals, nil
+}
+
+// getLatestBlockhash 获取最新区块哈希，交易在lastValidBlockHeight之前有效
+func (c *rpcClient) getLatestBlockhash(ctx context.Context) (PublicKey, uint64, error) {
+	var result struct {
+		Value struct {
+			Blockhash            string `json:"blockhash"`
+			LastValidBlockHeight uint64 `json:"lastValidBlockHeight"`
+		} `json:"value"`
+	}
+	if err := c.Call(ctx, "getLatestBlockhash", []interface{}{commitmentConfig()}, &result); err != nil {
+		return PublicKey{}, 0, err
+	}
+
+	blockhash, err := PublicKeyFromBase58(result.Value.Blockhash)
+	if err != nil {
+		return PublicKey{}, 0, fmt.Errorf("invalid blockhash: %v", err)
+	}
+	return blockhash, result.Value.LastValidBlockHeight, nil
+}
+
+// sendTransaction 广播base64编码的已签名交易，返回交易签名
+func (c *rpcClient) sendTransaction(ctx context.Context, txBase64 string) (string, error) {
+	var signature string
+	params := []interface{}{txBase64, map[string]interface{}{
+		"encoding":            "base64",
+		"preflightCommitment": defaultCommitment,
+	}}
+	if err := c.Call(ctx, "sendTransaction", params, &signature); err != nil {
+		return "", err
+	}
+	return signature, nil
+}
+
+// signatureStatus 交易签名状态，Err非空表示执行失败
+type signatureStatus struct {
+	Slot               uint64          `json:"slot"`
+	ConfirmationStatus string          `json:"confirmationStatus"`
+	Err                json.RawMessage `json:"err"`
+}
+
+// getSignatureStatus 查询交易状态，交易不存在时返回nil
+func (c *rpcClient) getSignatureStatus(ctx context.Context, signature string) (*signatureStatus, error) {
+	var result struct {
+		Value []*signatureStatus `json:"value"`
+	}
+	params := []interface{}{[]string{signature}, map[string]bool{"searchTransactionHistory": true}}
+	if err := c.Call(ctx, "getSignatureStatuses", params, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Value) == 0 {
+		return nil, nil
+	}
+	return result.Value[0], nil
+}
@@ -0,0 +1,273 @@
+package solana
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/google/uuid"
+	"github.com/tyler-smith/go-bip39"
+
+	"multi-chain-wallet/internal/wallet"
+	"multi-chain-wallet/internal/wallet/hd"
+	"multi-chain-wallet/internal/wallet/keycrypt"
+)
+
+// 与Phantom、Solflare等钱包一致的派生路径
+const derivationPath = "m/44'/501'/0'/0'"
+
+// KeyStore Solana钱包密钥库
+type KeyStore struct {
+	ID          string           `json:"id"`
+	Address     string           `json:"address"`
+	PrivKeyEnc  string           `json:"privKeyEnc"`
+	MnemonicEnc string           `json:"mnemonicEnc,omitempty"`
+	ChainType   wallet.ChainType `json:"chainType"`
+	CreateTime  int64            `json:"createTime"`
+}
+
+// SolanaWallet Solana钱包实现，支持SOL和SPL代币
+type SolanaWallet struct {
+	rpc           *rpcClient
+	encryptionKey []byte
+	mu            sync.RWMutex
+	keyMap        map[string]*KeyStore // walletID -> keystore
+	chainType     wallet.ChainType
+}
+
+// NewSolanaWallet 创建Solana钱包
+func NewSolanaWallet(chainType wallet.ChainType, rpcURL string, encryptionKey string) *SolanaWallet {
+	return &SolanaWallet{
+		rpc:           newRPCClient(rpcURL),
+		encryptionKey: keycrypt.DeriveKey(encryptionKey),
+		keyMap:        make(map[string]*KeyStore),
+		chainType:     chainType,
+	}
+}
+
+// NewFactory 创建Solana链的钱包工厂
+func NewFactory(encryptionKey string) wallet.WalletFactory {
+	return func(chain wallet.ChainInfo) (wallet.Wallet, error) {
+		if len(chain.RPCURLs) == 0 {
+			return nil, errors.New("no rpc url configured")
+		}
+		return NewSolanaWallet(chain.ChainType, chain.RPCURLs[0], encryptionKey), nil
+	}
+}
+
+// saveKey 加密并保存私钥，返回钱包ID
+func (w *SolanaWallet) saveKey(privKey ed25519.PrivateKey, mnemonic string) (string, error) {
+	var address PublicKey
+	copy(address[:], privKey.Public().(ed25519.PublicKey))
+
+	// 只保存32字节种子，公钥可以由种子重新计算
+	privKeyEnc, err := keycrypt.Encrypt(w.encryptionKey, privKey.Seed())
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt private key: %v", err)
+	}
+
+	var mnemonicEnc string
+	if mnemonic != "" {
+		mnemonicEnc, err = keycrypt.Encrypt(w.encryptionKey, []byte(mnemonic))
+		if err != nil {
+			return "", fmt.Errorf("failed to encrypt mnemonic: %v", err)
+		}
+	}
+
+	keystore := &KeyStore{
+		ID:          uuid.New().String(),
+		Address:     address.String(),
+		PrivKeyEnc:  privKeyEnc,
+		MnemonicEnc: mnemonicEnc,
+		ChainType:   w.chainType,
+		CreateTime:  time.Now().Unix(),
+	}
+
+	w.mu.Lock()
+	w.keyMap[keystore.ID] = keystore
+	w.mu.Unlock()
+
+	return keystore.ID, nil
+}
+
+// getKeyStore 获取钱包密钥库
+func (w *SolanaWallet) getKeyStore(walletID string) (*KeyStore, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	keystore, exists := w.keyMap[walletID]
+	if !exists {
+		return nil, wallet.ErrWalletNotFound
+	}
+	return keystore, nil
+}
+
+// getPrivateKey 解密钱包私钥
+func (w *SolanaWallet) getPrivateKey(walletID string) (ed25519.PrivateKey, *KeyStore, error) {
+	keystore, err := w.getKeyStore(walletID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seed, err := keycrypt.Decrypt(w.encryptionKey, keystore.PrivKeyEnc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt private key: %v", err)
+	}
+	if len(seed) != ed25519.SeedSize {
+		return nil, nil, errors.New("invalid private key length")
+	}
+
+	return ed25519.NewKeyFromSeed(seed), keystore, nil
+}
+
+// Create 创建新钱包
+func (w *SolanaWallet) Create() (string, error) {
+	entropy, err := bip39.NewEntropy(256)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate mnemonic: %v", err)
+	}
+
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate mnemonic: %v", err)
+	}
+
+	return w.ImportFromMnemonic(mnemonic)
+}
+
+// ImportFromMnemonic 从助记词导入钱包，按SLIP-0010在m/44'/501'/0'/0'派生ed25519密钥
+func (w *SolanaWallet) ImportFromMnemonic(mnemonic string) (string, error) {
+	privKey, err := hd.DeriveEd25519FromMnemonic(mnemonic, derivationPath)
+	if err != nil {
+		return "", err
+	}
+	return w.saveKey(privKey, mnemonic)
+}
+
+// ImportFromPrivateKey 导入私钥，支持base58编码的64字节密钥对（Phantom导出格式）
+// 以及hex或base58编码的32字节种子
+func (w *SolanaWallet) ImportFromPrivateKey(privateKey string) (string, error) {
+	keyBytes, err := hex.DecodeString(privateKey)
+	if err != nil {
+		keyBytes = base58.Decode(privateKey)
+	}
+
+	switch len(keyBytes) {
+	case ed25519.SeedSize:
+		return w.saveKey(ed25519.NewKeyFromSeed(keyBytes), "")
+	case ed25519.PrivateKeySize:
+		privKey := ed25519.NewKeyFromSeed(keyBytes[:ed25519.SeedSize])
+		// 密钥对后32字节为公钥，需要与种子一致
+		if !privKey.Public().(ed25519.PublicKey).Equal(ed25519.PublicKey(keyBytes[ed25519.SeedSize:])) {
+			return "", errors.New("invalid keypair: public key does not match secret key")
+		}
+		return w.saveKey(privKey, "")
+	default:
+		return "", errors.New("invalid private key format")
+	}
+}
+
+// GetAddress 获取钱包地址
+func (w *SolanaWallet) GetAddress(walletID string) (string, error) {
+	keystore, err := w.getKeyStore(walletID)
+	if err != nil {
+		return "", err
+	}
+	return keystore.Address, nil
+}
+
+// GetBalance 获取SOL余额，单位lamports
+func (w *SolanaWallet) GetBalance(ctx context.Context, address string) (*big.Int, error) {
+	account, err := PublicKeyFromBase58(address)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := w.rpc.getBalance(ctx, account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance: %w", err)
+	}
+	return new(big.Int).SetUint64(balance), nil
+}
+
+// GetTokenBalance 获取SPL代币余额，查询钱包在该代币下的关联代币账户，账户不存在时余额为0
+func (w *SolanaWallet) GetTokenBalance(ctx context.Context, address string, tokenAddress string) (*big.Int, error) {
+	owner, err := PublicKeyFromBase58(address)
+	if err != nil {
+		return nil, err
+	}
+	mint, err := PublicKeyFromBase58(tokenAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenProgramID, err := w.tokenProgramOf(ctx, mint)
+	if err != nil {
+		return nil, err
+	}
+
+	ata, err := associatedTokenAddress(owner, mint, tokenProgramID)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := w.rpc.getAccountInfo(ctx, ata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token account: %w", err)
+	}
+	if info == nil {
+		return big.NewInt(0), nil
+	}
+
+	amount, err := w.rpc.getTokenAccountBalance(ctx, ata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token balance: %w", err)
+	}
+
+	balance, ok := new(big.Int).SetString(amount.Amount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid token amount: %s", amount.Amount)
+	}
+	return balance, nil
+}
+
+// tokenProgramOf 按mint账户的所有者判断代币属于SPL Token还是Token-2022程序
+func (w *SolanaWallet) tokenProgramOf(ctx context.Context, mint PublicKey) (PublicKey, error) {
+	info, err := w.rpc.getAccountInfo(ctx, mint)
+	if err != nil {
+		return PublicKey{}, fmt.Errorf("failed to get mint account: %w", err)
+	}
+	if info == nil {
+		return PublicKey{}, fmt.Errorf("token mint %s not found", mint)
+	}
+
+	owner, err := PublicKeyFromBase58(info.Owner)
+	if err != nil {
+		return PublicKey{}, err
+	}
+	if owner != TokenProgramID && owner != Token2022ProgramID {
+		return PublicKey{}, fmt.Errorf("%s is not a token mint", mint)
+	}
+	return owner, nil
+}
+
+// SignTypedData Solana不支持EIP-712
+func (w *SolanaWallet) SignTypedData(ctx context.Context, walletID string, typedData []byte) ([]byte, error) {
+	return nil, wallet.ErrOperationNotSupported
+}
+
+// VerifyTypedData Solana不支持EIP-712
+func (w *SolanaWallet) VerifyTypedData(ctx context.Context, expectedSigner string, typedData []byte, signature []byte) (*wallet.SignatureVerification, error) {
+	return nil, wallet.ErrOperationNotSupported
+}
+
+// ChainType 获取链类型
+func (w *SolanaWallet) ChainType() wallet.ChainType {
+	return w.chainType
+}
@@ -0,0 +1,220 @@
+package solana
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+
+	"multi-chain-wallet/internal/wallet"
+	"multi-chain-wallet/internal/wallet/hd"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+// SLIP-0010 ed25519测试向量1
+func TestDeriveEd25519FromSeed(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	tests := []struct {
+		path    string
+		private string
+		public  string
+	}{
+		{"m/0'", "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3", "8c8a13df77a28f3445213a0f432fde644acaa215fc72dcdf300d5efaa85d350c"},
+		{"m/0'/1'/2'/2'/1000000000'", "8f94d394a8e8fd6b1bc2f3f49f5c47e385281d5c17e65324b0f62483e37e8793", "3c24da049451555d51a7014a37337aa4e12d41e485abccfa46b47dfb2af54b7a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			key, err := hd.DeriveEd25519FromSeed(seed, tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(key.Seed()); got != tt.private {
+				t.Fatalf("expected private key %s, got %s", tt.private, got)
+			}
+			if got := hex.EncodeToString(key.Public().(ed25519.PublicKey)); got != tt.public {
+				t.Fatalf("expected public key %s, got %s", tt.public, got)
+			}
+		})
+	}
+
+	if _, err := hd.DeriveEd25519FromSeed(seed, "m/0"); err == nil {
+		t.Fatal("expected non-hardened derivation to fail")
+	}
+}
+
+// 与Phantom、solana-keygen一致的派生路径
+func TestImportFromMnemonic(t *testing.T) {
+	w := NewSolanaWallet("solana", "http://127.0.0.1:0", "test-encryption-key")
+	walletID, err := w.ImportFromMnemonic(testMnemonic)
+	if err != nil {
+		t.Fatalf("ImportFromMnemonic: %v", err)
+	}
+	address, err := w.GetAddress(walletID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "HAgk14JpMQLgt6rVgv7cBQFJWFto5Dqxi472uT3DKpqk"; address != want {
+		t.Fatalf("expected %s, got %s", want, address)
+	}
+}
+
+func TestImportFromPrivateKey(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mismatched := append(append([]byte{}, priv.Seed()...), make([]byte, 32)...)
+
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "base58 keypair", key: base58.Encode(priv)},
+		{name: "hex seed", key: hex.EncodeToString(priv.Seed())},
+		{name: "base58 seed", key: base58.Encode(priv.Seed())},
+		{name: "mismatched keypair", key: base58.Encode(mismatched), wantErr: true},
+		{name: "wrong length", key: hex.EncodeToString(priv.Seed()[:31]), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewSolanaWallet("solana", "http://127.0.0.1:0", "test-encryption-key")
+			walletID, err := w.ImportFromPrivateKey(tt.key)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ImportFromPrivateKey: %v", err)
+			}
+			address, _ := w.GetAddress(walletID)
+			var want PublicKey
+			copy(want[:], pub)
+			if address != want.String() {
+				t.Fatalf("expected %s, got %s", want, address)
+			}
+		})
+	}
+}
+
+// newTestRPC 模拟Solana节点：mint属于SPL Token程序，发送方的代币账户余额为balance
+func newTestRPC(t *testing.T, mint PublicKey, balance string) (*httptest.Server, *[]string) {
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("invalid request: %v", err)
+			return
+		}
+
+		var result interface{}
+		switch req.Method {
+		case "getAccountInfo":
+			var account string
+			json.Unmarshal(req.Params[0], &account)
+			owner := SystemProgramID.String()
+			if account == mint.String() {
+				owner = TokenProgramID.String()
+			}
+			result = map[string]interface{}{"value": map[string]interface{}{"owner": owner, "lamports": 1}}
+		case "getTokenSupply":
+			result = map[string]interface{}{"value": map[string]interface{}{"amount": "1000000000", "decimals": 6}}
+		case "getTokenAccountBalance":
+			result = map[string]interface{}{"value": map[string]interface{}{"amount": balance, "decimals": 6}}
+		case "getLatestBlockhash":
+			result = map[string]interface{}{"value": map[string]interface{}{"blockhash": randomPublicKey(t).String(), "lastValidBlockHeight": 1000}}
+		case "sendTransaction":
+			var txBase64 string
+			json.Unmarshal(req.Params[0], &txBase64)
+			sent = append(sent, txBase64)
+			result = "signature"
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	t.Cleanup(server.Close)
+	return server, &sent
+}
+
+// SPL代币转账：构建的消息可被解析为一笔转账，签名可用发送方公钥验证
+func TestCreateSignAndSendTokenTransaction(t *testing.T) {
+	mint := randomPublicKey(t)
+	to := randomPublicKey(t)
+	server, sent := newTestRPC(t, mint, "5000000")
+
+	w := NewSolanaWallet("solana", server.URL, "test-encryption-key")
+	walletID, err := w.ImportFromMnemonic(testMnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, _ := w.GetAddress(walletID)
+	ctx := context.Background()
+
+	if _, err := w.CreateTokenTransaction(ctx, from, to.String(), mint.String(), big.NewInt(6_000_000)); err == nil {
+		t.Fatal("expected insufficient token balance")
+	}
+
+	tx, err := w.CreateTokenTransaction(ctx, from, to.String(), mint.String(), big.NewInt(1_500_000))
+	if err != nil {
+		t.Fatalf("CreateTokenTransaction: %v", err)
+	}
+	transfers, err := w.DecodePayload(tx.Payload)
+	if err != nil {
+		t.Fatalf("DecodePayload: %v", err)
+	}
+	if len(transfers) != 1 || transfers[0].From != from || transfers[0].To != to.String() ||
+		transfers[0].Token != mint.String() || transfers[0].Value.Int64() != 1_500_000 {
+		t.Fatalf("unexpected transfers: %+v", transfers)
+	}
+
+	signed, err := w.SignTransaction(ctx, walletID, tx)
+	if err != nil {
+		t.Fatalf("SignTransaction: %v", err)
+	}
+	signature := base58.Decode(signed.Hash)
+	fromKey := MustPublicKey(from)
+	if !ed25519.Verify(fromKey[:], tx.Payload, signature) {
+		t.Fatal("signature does not verify")
+	}
+	if _, err := w.DecodeSignedPayload(signed.Payload); err != nil {
+		t.Fatalf("DecodeSignedPayload: %v", err)
+	}
+
+	if _, err := w.SendTransaction(ctx, signed); err != nil {
+		t.Fatalf("SendTransaction: %v", err)
+	}
+	if len(*sent) != 1 || (*sent)[0] != base64.StdEncoding.EncodeToString(signed.Payload) {
+		t.Fatalf("unexpected broadcast: %v", *sent)
+	}
+}
+
+// 消息的签名者不是该钱包时拒绝签名
+func TestSignTransactionRejectsForeignSigner(t *testing.T) {
+	w := NewSolanaWallet("solana", "http://127.0.0.1:0", "test-encryption-key")
+	walletID, err := w.ImportFromMnemonic(testMnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := randomPublicKey(t)
+	msg := compileTestMessage(t, other, systemTransferInstruction(other, randomPublicKey(t), 1))
+
+	tx := &wallet.UnsignedTx{ChainType: "solana", Payload: msg}
+	if _, err := w.SignTransaction(context.Background(), walletID, tx); err == nil {
+		t.Fatal("expected error signing a message for another signer")
+	}
+}
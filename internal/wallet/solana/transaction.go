@@ -0,0 +1,267 @@
+package solana
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// 每个签名的基础手续费，单位lamports
+const lamportsPerSignature = 5000
+
+// TransactionDetails 交易的附加信息，放在UnsignedTx.Details中
+type TransactionDetails struct {
+	Decimals             uint8  `json:"decimals,omitempty"`   // 代币转账时的代币精度
+	LastValidBlockHeight uint64 `json:"lastValidBlockHeight"` // 超过该区块高度后交易失效，需要重新创建
+}
+
+// CreateTransaction 创建SOL转账交易，data非空时附加Memo指令。Payload为待签名的消息
+func (w *SolanaWallet) CreateTransaction(ctx context.Context, from string, to string, amount *big.Int, data []byte) (*wallet.UnsignedTx, error) {
+	fromKey, err := PublicKeyFromBase58(from)
+	if err != nil {
+		return nil, err
+	}
+	toKey, err := PublicKeyFromBase58(to)
+	if err != nil {
+		return nil, err
+	}
+	if amount == nil || amount.Sign() <= 0 || !amount.IsUint64() {
+		return nil, errors.New("invalid amount")
+	}
+
+	instructions := []Instruction{systemTransferInstruction(fromKey, toKey, amount.Uint64())}
+	if len(data) > 0 {
+		instructions = append(instructions, memoInstruction(fromKey, data))
+	}
+
+	tx := &wallet.UnsignedTx{
+		From:  from,
+		To:    to,
+		Value: amount,
+	}
+	return w.buildTransaction(ctx, fromKey, instructions, tx, 0)
+}
+
+// CreateTokenTransaction 创建SPL代币转账交易。接收方的关联代币账户不存在时由发送方创建并支付租金
+func (w *SolanaWallet) CreateTokenTransaction(ctx context.Context, from string, to string, tokenAddress string, amount *big.Int) (*wallet.UnsignedTx, error) {
+	fromKey, err := PublicKeyFromBase58(from)
+	if err != nil {
+		return nil, err
+	}
+	toKey, err := PublicKeyFromBase58(to)
+	if err != nil {
+		return nil, err
+	}
+	mint, err := PublicKeyFromBase58(tokenAddress)
+	if err != nil {
+		return nil, err
+	}
+	if amount == nil || amount.Sign() <= 0 || !amount.IsUint64() {
+		return nil, errors.New("invalid amount")
+	}
+
+	tokenProgramID, err := w.tokenProgramOf(ctx, mint)
+	if err != nil {
+		return nil, err
+	}
+
+	decimals, err := w.rpc.getTokenDecimals(ctx, mint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token decimals: %w", err)
+	}
+
+	source, err := associatedTokenAddress(fromKey, mint, tokenProgramID)
+	if err != nil {
+		return nil, err
+	}
+	destination, err := associatedTokenAddress(toKey, mint, tokenProgramID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := w.GetTokenBalance(ctx, from, tokenAddress)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Cmp(amount) < 0 {
+		return nil, fmt.Errorf("%w: token balance %s, need %s", wallet.ErrInsufficientFunds, balance, amount)
+	}
+
+	instructions := []Instruction{
+		createAssociatedTokenAccountIdempotentInstruction(fromKey, destination, toKey, mint, tokenProgramID),
+		transferCheckedInstruction(source, mint, destination, fromKey, amount.Uint64(), decimals, tokenProgramID),
+	}
+
+	tx := &wallet.UnsignedTx{
+		From:  from,
+		To:    to,
+		Value: amount,
+		Token: tokenAddress,
+	}
+	return w.buildTransaction(ctx, fromKey, instructions, tx, decimals)
+}
+
+// buildTransaction 获取最新区块哈希并编译交易消息
+func (w *SolanaWallet) buildTransaction(ctx context.Context, feePayer PublicKey, instructions []Instruction, tx *wallet.UnsignedTx, decimals uint8) (*wallet.UnsignedTx, error) {
+	blockhash, lastValidBlockHeight, err := w.rpc.getLatestBlockhash(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest blockhash: %w", err)
+	}
+
+	msg, err := compileMessage(feePayer, instructions, blockhash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile message: %v", err)
+	}
+
+	tx.ChainType = w.chainType
+	tx.Fee = &wallet.TxFee{Amount: big.NewInt(lamportsPerSignature)}
+	tx.Payload = msg
+	tx.Details = &TransactionDetails{
+		Decimals:             decimals,
+		LastValidBlockHeight: lastValidBlockHeight,
+	}
+	return tx, nil
+}
+
+// SignTransaction 签名交易，钱包必须是消息中唯一的签名者。Payload为完整的已签名交易，Hash为交易签名
+func (w *SolanaWallet) SignTransaction(ctx context.Context, walletID string, tx *wallet.UnsignedTx) (*wallet.SignedTx, error) {
+	if err := tx.CheckChain(w.chainType); err != nil {
+		return nil, err
+	}
+
+	msg := tx.Payload
+	signers, err := messageSigners(msg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction message: %v", err)
+	}
+
+	privKey, keystore, err := w.getPrivateKey(walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(signers) != 1 || signers[0].String() != keystore.Address {
+		return nil, errors.New("transaction signer does not match wallet")
+	}
+
+	signature := ed25519.Sign(privKey, msg)
+
+	// 交易格式：compact-u16签名数 || 签名 || 消息
+	raw := appendCompactU16(nil, 1)
+	raw = append(raw, signature...)
+	raw = append(raw, msg...)
+
+	return tx.Signed(raw, base58.Encode(signature)), nil
+}
+
+// DecodePayload 解析消息中的SOL和SPL代币转账。只接受钱包会构建的指令：系统程序转账、SPL代币TransferChecked、
+// 创建关联代币账户和备注，其他指令返回错误。代币收款方为关联代币账户的所有者，消息中没有创建该账户的指令时为代币账户地址
+func (w *SolanaWallet) DecodePayload(payload []byte) ([]*wallet.PayloadTransfer, error) {
+	instructions, err := decodeMessage(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid transaction message: %v", wallet.ErrInvalidTransaction, err)
+	}
+	invalid := func(i int, reason string) error {
+		return fmt.Errorf("%w: instruction %d: %s", wallet.ErrInvalidTransaction, i, reason)
+	}
+
+	// 关联代币账户到所有者
+	owners := make(map[PublicKey]PublicKey)
+	for _, ix := range instructions {
+		if ix.ProgramID == AssociatedTokenAccountProgramID && len(ix.Accounts) >= 3 {
+			owners[ix.Accounts[1].PublicKey] = ix.Accounts[2].PublicKey
+		}
+	}
+
+	var transfers []*wallet.PayloadTransfer
+	for i, ix := range instructions {
+		switch ix.ProgramID {
+		case SystemProgramID:
+			if len(ix.Data) != 12 || binary.LittleEndian.Uint32(ix.Data) != systemInstructionTransfer || len(ix.Accounts) < 2 {
+				return nil, invalid(i, "only system transfers are supported")
+			}
+			transfers = append(transfers, &wallet.PayloadTransfer{
+				From:  ix.Accounts[0].PublicKey.String(),
+				To:    ix.Accounts[1].PublicKey.String(),
+				Value: new(big.Int).SetUint64(binary.LittleEndian.Uint64(ix.Data[4:12])),
+			})
+		case TokenProgramID, Token2022ProgramID:
+			if len(ix.Data) != 10 || ix.Data[0] != tokenInstructionTransferChecked || len(ix.Accounts) < 4 {
+				return nil, invalid(i, "only TransferChecked token instructions are supported")
+			}
+			mint := ix.Accounts[1].PublicKey
+			destination := ix.Accounts[2].PublicKey
+			if owner, ok := owners[destination]; ok {
+				if ata, err := associatedTokenAddress(owner, mint, ix.ProgramID); err == nil && ata == destination {
+					destination = owner
+				}
+			}
+			transfers = append(transfers, &wallet.PayloadTransfer{
+				From:  ix.Accounts[3].PublicKey.String(),
+				To:    destination.String(),
+				Value: new(big.Int).SetUint64(binary.LittleEndian.Uint64(ix.Data[1:9])),
+				Token: mint.String(),
+			})
+		case AssociatedTokenAccountProgramID:
+			if len(ix.Data) != 1 || ix.Data[0] != associatedTokenCreateIdempotentIdx {
+				return nil, invalid(i, "only idempotent associated token account creation is supported")
+			}
+		case MemoProgramID:
+		default:
+			return nil, invalid(i, fmt.Sprintf("unsupported program %s", ix.ProgramID))
+		}
+	}
+	return transfers, nil
+}
+
+// DecodeSignedPayload 跳过已签名交易开头的签名，解析其中的消息
+func (w *SolanaWallet) DecodeSignedPayload(payload []byte) ([]*wallet.PayloadTransfer, error) {
+	numSignatures, n, err := readCompactU16(payload)
+	if err != nil || len(payload) < n+numSignatures*ed25519.SignatureSize {
+		return nil, fmt.Errorf("%w: malformed signed transaction", wallet.ErrInvalidTransaction)
+	}
+	return w.DecodePayload(payload[n+numSignatures*ed25519.SignatureSize:])
+}
+
+// SendTransaction 广播已签名交易，返回交易签名（即交易哈希）
+func (w *SolanaWallet) SendTransaction(ctx context.Context, signedTx *wallet.SignedTx) (string, error) {
+	if err := signedTx.CheckChain(w.chainType); err != nil {
+		return "", err
+	}
+
+	signature, err := w.rpc.sendTransaction(ctx, base64.StdEncoding.EncodeToString(signedTx.Payload))
+	if err != nil {
+		return "", fmt.Errorf("failed to send transaction: %w", err)
+	}
+	return signature, nil
+}
+
+// GetTransactionStatus 获取交易状态，confirmed和finalized视为已确认
+func (w *SolanaWallet) GetTransactionStatus(ctx context.Context, txHash string) (string, error) {
+	status, err := w.rpc.getSignatureStatus(ctx, txHash)
+	if err != nil {
+		return "", fmt.Errorf("failed to get transaction status: %w", err)
+	}
+	if status == nil {
+		return "", errors.New("transaction not found")
+	}
+
+	if len(status.Err) > 0 && string(status.Err) != "null" {
+		return string(wallet.TxFailed), nil
+	}
+
+	switch status.ConfirmationStatus {
+	case "confirmed", "finalized":
+		return string(wallet.TxConfirmed), nil
+	default:
+		return string(wallet.TxPending), nil
+	}
+}
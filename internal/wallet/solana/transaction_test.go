@@ -0,0 +1,147 @@
+package solana
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+func randomPublicKey(t *testing.T) PublicKey {
+	pub, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var key PublicKey
+	copy(key[:], pub)
+	return key
+}
+
+func compileTestMessage(t *testing.T, feePayer PublicKey, instructions ...Instruction) []byte {
+	msg, err := compileMessage(feePayer, instructions, randomPublicKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestDecodePayload(t *testing.T) {
+	w := NewSolanaWallet("solana", "http://127.0.0.1:0", "test-encryption-key")
+	from := randomPublicKey(t)
+	to := randomPublicKey(t)
+	mint := randomPublicKey(t)
+
+	source, err := associatedTokenAddress(from, mint, TokenProgramID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	destination, err := associatedTokenAddress(to, mint, TokenProgramID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		instructions []Instruction
+		want         wallet.PayloadTransfer
+	}{
+		{
+			name: "SOL",
+			instructions: []Instruction{
+				systemTransferInstruction(from, to, 1_000_000),
+				memoInstruction(from, []byte("memo")),
+			},
+			want: wallet.PayloadTransfer{From: from.String(), To: to.String()},
+		},
+		{
+			// 收款方为关联代币账户的所有者
+			name: "SPL",
+			instructions: []Instruction{
+				createAssociatedTokenAccountIdempotentInstruction(from, destination, to, mint, TokenProgramID),
+				transferCheckedInstruction(source, mint, destination, from, 1_000_000, 6, TokenProgramID),
+			},
+			want: wallet.PayloadTransfer{From: from.String(), To: to.String(), Token: mint.String()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transfers, err := w.DecodePayload(compileTestMessage(t, from, tt.instructions...))
+			if err != nil {
+				t.Fatalf("DecodePayload: %v", err)
+			}
+			if len(transfers) != 1 {
+				t.Fatalf("expected one transfer, got %d", len(transfers))
+			}
+			got := transfers[0]
+			if got.From != tt.want.From || got.To != tt.want.To || got.Token != tt.want.Token || got.Value.Int64() != 1_000_000 {
+				t.Fatalf("unexpected transfer: %+v", got)
+			}
+		})
+	}
+}
+
+// 创建关联代币账户的指令指定的所有者与代币账户不对应时，收款方为代币账户本身
+func TestDecodePayloadIgnoresMismatchedOwner(t *testing.T) {
+	w := NewSolanaWallet("solana", "http://127.0.0.1:0", "test-encryption-key")
+	from := randomPublicKey(t)
+	mint := randomPublicKey(t)
+	claimed := randomPublicKey(t)
+	destination := randomPublicKey(t)
+
+	msg := compileTestMessage(t, from,
+		createAssociatedTokenAccountIdempotentInstruction(from, destination, claimed, mint, TokenProgramID),
+		transferCheckedInstruction(randomPublicKey(t), mint, destination, from, 1, 6, TokenProgramID),
+	)
+	transfers, err := w.DecodePayload(msg)
+	if err != nil {
+		t.Fatalf("DecodePayload: %v", err)
+	}
+	if len(transfers) != 1 || transfers[0].To != destination.String() {
+		t.Fatalf("unexpected transfers: %+v", transfers)
+	}
+}
+
+func TestDecodePayloadRejectsUnknownInstruction(t *testing.T) {
+	w := NewSolanaWallet("solana", "http://127.0.0.1:0", "test-encryption-key")
+	from := randomPublicKey(t)
+
+	// SPL Approve(4)授权他人转走代币，不在允许的指令中
+	approve := Instruction{
+		ProgramID: TokenProgramID,
+		Accounts: []AccountMeta{
+			{PublicKey: randomPublicKey(t), IsWritable: true},
+			{PublicKey: randomPublicKey(t)},
+			{PublicKey: from, IsSigner: true},
+		},
+		Data: []byte{4, 1, 0, 0, 0, 0, 0, 0, 0},
+	}
+	if _, err := w.DecodePayload(compileTestMessage(t, from, approve)); !errors.Is(err, wallet.ErrInvalidTransaction) {
+		t.Fatalf("expected ErrInvalidTransaction, got %v", err)
+	}
+
+	unknown := Instruction{ProgramID: randomPublicKey(t), Accounts: []AccountMeta{{PublicKey: from, IsSigner: true}}}
+	if _, err := w.DecodePayload(compileTestMessage(t, from, unknown)); !errors.Is(err, wallet.ErrInvalidTransaction) {
+		t.Fatalf("expected ErrInvalidTransaction, got %v", err)
+	}
+}
+
+func TestDecodeSignedPayload(t *testing.T) {
+	w := NewSolanaWallet("solana", "http://127.0.0.1:0", "test-encryption-key")
+	from := randomPublicKey(t)
+	to := randomPublicKey(t)
+
+	// 已签名交易：compact-u16签名数 || 签名 || 消息
+	msg := compileTestMessage(t, from, systemTransferInstruction(from, to, 42))
+	raw := appendCompactU16(nil, 1)
+	raw = append(raw, make([]byte, ed25519.SignatureSize)...)
+	raw = append(raw, msg...)
+
+	transfers, err := w.DecodeSignedPayload(raw)
+	if err != nil {
+		t.Fatalf("DecodeSignedPayload: %v", err)
+	}
+	if len(transfers) != 1 || transfers[0].To != to.String() || transfers[0].Value.Int64() != 42 {
+		t.Fatalf("unexpected transfers: %+v", transfers)
+	}
+}
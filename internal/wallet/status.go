@@ -0,0 +1,30 @@
+package wallet
+
+// ChainStatus 链的连接状态
+type ChainStatus string
+
+const (
+	ChainStatusConnecting  ChainStatus = "connecting"  // 尚未完成首次连接
+	ChainStatusConnected   ChainStatus = "connected"   // 节点可用且已同步
+	ChainStatusSyncing     ChainStatus = "syncing"     // 节点可用但仍在同步区块
+	ChainStatusUnreachable ChainStatus = "unreachable" // 节点不可达，后台持续重连
+)
+
+// ChainStatusInfo 链的连接状态详情
+type ChainStatusInfo struct {
+	ChainType   ChainType   `json:"chainType"`
+	Status      ChainStatus `json:"status"`
+	BlockNumber uint64      `json:"blockNumber,omitempty"`
+	LastError   string      `json:"lastError,omitempty"`
+	LastCheck   int64       `json:"lastCheck,omitempty"`
+}
+
+// Available 链是否可以处理请求，同步中的节点仍可读写
+func (s ChainStatusInfo) Available() bool {
+	return s.Status == ChainStatusConnected || s.Status == ChainStatusSyncing
+}
+
+// StatusReporter 可选接口，需要网络连接的钱包实现上报链的连接状态
+type StatusReporter interface {
+	Status() ChainStatusInfo
+}
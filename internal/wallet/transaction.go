@@ -0,0 +1,81 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// UnsignedTx 链无关的待签名交易，由CreateTransaction生成后交给SignTransaction。
+// 通用字段用于展示和记账，签名只依赖Payload中的链特定数据
+type UnsignedTx struct {
+	ChainType ChainType   `json:"chainType"`
+	From      string      `json:"from"`
+	To        string      `json:"to"`              // 收款地址，代币转账时为实际收款人而非代币合约
+	Value     *big.Int    `json:"value"`           // 转账金额，原生代币或代币的最小单位
+	Token     string      `json:"token,omitempty"` // 代币合约、mint或denom，原生代币转账为空
+	Nonce     uint64      `json:"nonce,omitempty"` // EVM的nonce，Cosmos的sequence
+	Fee       *TxFee      `json:"fee,omitempty"`
+	Payload   []byte      `json:"payload"`           // 链特定的待签名数据：EVM为RLP编码交易，比特币为PSBT
+	Details   interface{} `json:"details,omitempty"` // 链特定的附加信息（如Tron资源预估），签名时忽略
+}
+
+// TxFee 交易费用，链不使用的字段为空
+type TxFee struct {
+	GasLimit  uint64   `json:"gasLimit,omitempty"`  // EVM/Cosmos的gas上限，Tron为预估能量
+	GasPrice  *big.Int `json:"gasPrice,omitempty"`  // legacy交易的gas价格，比特币为费率sat/vB
+	GasTipCap *big.Int `json:"gasTipCap,omitempty"` // EIP-1559小费上限
+	GasFeeCap *big.Int `json:"gasFeeCap,omitempty"` // EIP-1559总费用上限
+	Amount    *big.Int `json:"amount,omitempty"`    // 预估手续费，原生代币最小单位
+	MaxAmount *big.Int `json:"maxAmount,omitempty"` // 手续费上限，EVM为gasLimit*gasFeeCap，Tron为fee_limit
+}
+
+// SignedTx 已签名交易，Payload为可直接广播的原始交易
+type SignedTx struct {
+	ChainType ChainType `json:"chainType"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Value     *big.Int  `json:"value"`
+	Token     string    `json:"token,omitempty"`
+	Nonce     uint64    `json:"nonce,omitempty"`
+	Fee       *TxFee    `json:"fee,omitempty"`
+	Hash      string    `json:"hash"`
+	Payload   []byte    `json:"payload"`
+}
+
+// Signed 用签名后的原始交易和交易哈希生成SignedTx，通用字段沿用待签名交易
+func (tx *UnsignedTx) Signed(payload []byte, hash string) *SignedTx {
+	return &SignedTx{
+		ChainType: tx.ChainType,
+		From:      tx.From,
+		To:        tx.To,
+		Value:     tx.Value,
+		Token:     tx.Token,
+		Nonce:     tx.Nonce,
+		Fee:       tx.Fee,
+		Hash:      hash,
+		Payload:   payload,
+	}
+}
+
+// CheckChain 校验交易属于指定链且包含链特定数据
+func (tx *UnsignedTx) CheckChain(chainType ChainType) error {
+	if tx == nil || len(tx.Payload) == 0 {
+		return errors.New("transaction payload is empty")
+	}
+	if tx.ChainType != "" && tx.ChainType != chainType {
+		return fmt.Errorf("transaction is for chain %s, not %s", tx.ChainType, chainType)
+	}
+	return nil
+}
+
+// CheckChain 校验交易属于指定链且包含原始交易
+func (tx *SignedTx) CheckChain(chainType ChainType) error {
+	if tx == nil || len(tx.Payload) == 0 {
+		return errors.New("signed transaction payload is empty")
+	}
+	if tx.ChainType != "" && tx.ChainType != chainType {
+		return fmt.Errorf("transaction is for chain %s, not %s", tx.ChainType, chainType)
+	}
+	return nil
+}
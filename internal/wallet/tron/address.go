@@ -0,0 +1,57 @@
+package tron
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Tron主网地址前缀，base58check编码后以T开头
+const addressPrefix = 0x41
+
+// Address Tron地址，与以太坊地址使用相同的20字节公钥哈希
+type Address common.Address
+
+// AddressFromPublicKey 由secp256k1公钥计算地址
+func AddressFromPublicKey(pub ecdsa.PublicKey) Address {
+	return Address(crypto.PubkeyToAddress(pub))
+}
+
+// ParseAddress 解析base58check编码的T地址或41开头的hex地址
+func ParseAddress(s string) (Address, error) {
+	if len(s) == 42 && s[:2] == "41" {
+		if raw, err := hex.DecodeString(s); err == nil {
+			var addr Address
+			copy(addr[:], raw[1:])
+			return addr, nil
+		}
+	}
+
+	decoded, version, err := base58.CheckDecode(s)
+	if err != nil || version != addressPrefix || len(decoded) != common.AddressLength {
+		return Address{}, fmt.Errorf("invalid tron address: %s", s)
+	}
+
+	var addr Address
+	copy(addr[:], decoded)
+	return addr, nil
+}
+
+// String 返回base58check编码的T地址
+func (a Address) String() string {
+	return base58.CheckEncode(a[:], addressPrefix)
+}
+
+// Hex 返回41开头的hex地址
+func (a Address) Hex() string {
+	return fmt.Sprintf("%x%x", addressPrefix, a[:])
+}
+
+// abiWord ABI编码中的地址参数，左侧补零到32字节
+func (a Address) abiWord() []byte {
+	return common.LeftPadBytes(a[:], 32)
+}
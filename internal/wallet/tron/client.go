@@ -0,0 +1,65 @@
+package tron
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// NodeClient Tron全节点HTTP API客户端，测试时可以替换为mock实现
+type NodeClient interface {
+	// Post 以JSON调用path（如/wallet/getaccount），并将响应解码到resp
+	Post(ctx context.Context, path string, req interface{}, resp interface{}) error
+}
+
+// httpNodeClient 基于HTTP的全节点客户端，可以指向java-tron节点或TronGrid
+type httpNodeClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewHTTPNodeClient 创建全节点HTTP客户端，baseURL如 https://nile.trongrid.io
+func NewHTTPNodeClient(baseURL string) NodeClient {
+	return &httpNodeClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Post 调用全节点接口
+func (c *httpNodeClient) Post(ctx context.Context, path string, req interface{}, resp interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %v", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("%w: %v", wallet.ErrChainUnavailable, err)
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode == http.StatusTooManyRequests || httpResp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: tron node returned status %d", wallet.ErrChainUnavailable, httpResp.StatusCode)
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return fmt.Errorf("tron node returned status %d for %s", httpResp.StatusCode, path)
+	}
+
+	if err := json.NewDecoder(httpResp.Body).Decode(resp); err != nil {
+		return fmt.Errorf("failed to decode %s response: %v", path, err)
+	}
+	return nil
+}
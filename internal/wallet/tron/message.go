@@ -0,0 +1,58 @@
+package tron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// tronMessageHash 按TIP-191计算消息哈希，与TronWeb的signMessageV2一致
+func tronMessageHash(message []byte) []byte {
+	msg := fmt.Sprintf("\x19TRON Signed Message:\n%d%s", len(message), message)
+	return crypto.Keccak256([]byte(msg))
+}
+
+// SignMessage 按TIP-191签名消息，返回65字节签名，v为27/28
+func (w *TronWallet) SignMessage(ctx context.Context, walletID string, message []byte) ([]byte, error) {
+	privateKey, _, err := w.getPrivateKey(walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := crypto.Sign(tronMessageHash(message), privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign message: %v", err)
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
+
+// VerifyMessage 校验TIP-191消息签名
+func (w *TronWallet) VerifyMessage(ctx context.Context, expectedSigner string, message []byte, signature []byte) (*wallet.SignatureVerification, error) {
+	if len(signature) != crypto.SignatureLength {
+		return nil, errors.New("invalid signature length")
+	}
+
+	sig := make([]byte, crypto.SignatureLength)
+	copy(sig, signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pubKey, err := crypto.SigToPub(tronMessageHash(message), sig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover public key: %v", err)
+	}
+
+	recovered := AddressFromPublicKey(*pubKey).String()
+	return &wallet.SignatureVerification{
+		RecoveredAddress: recovered,
+		ExpectedSigner:   expectedSigner,
+		Valid:            recovered == expectedSigner,
+		Method:           wallet.VerifyMethodECRecover,
+	}, nil
+}
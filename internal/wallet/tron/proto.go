@@ -0,0 +1,215 @@
+package tron
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// 钱包会签名的合约类型，对应protocol.Transaction.Contract.ContractType
+const (
+	transferContractType     = 1
+	triggerSmartContractType = 31
+)
+
+// trc20TransferSelector transfer(address,uint256)的函数选择器
+var trc20TransferSelector = crypto.Keccak256([]byte("transfer(address,uint256)"))[:4]
+
+// protoField protobuf消息的一个字段，varint类型的值在varint中，length-delimited类型的值在bytes中
+type protoField struct {
+	num    protowire.Number
+	varint uint64
+	bytes  []byte
+}
+
+// parseProto 按顺序解析protobuf消息的顶层字段，fixed32/fixed64字段只校验不保留
+func parseProto(b []byte) ([]protoField, error) {
+	var fields []protoField
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		field := protoField{num: num}
+		switch typ {
+		case protowire.VarintType:
+			field.varint, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			field.bytes, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// protoAddress 解析protobuf中41开头的21字节地址
+func protoAddress(b []byte) (Address, error) {
+	var addr Address
+	if len(b) != len(addr)+1 || b[0] != addressPrefix {
+		return addr, errors.New("invalid address")
+	}
+	copy(addr[:], b[1:])
+	return addr, nil
+}
+
+// rawContract raw_data中的合约调用
+type rawContract struct {
+	owner     Address
+	transfers []*wallet.PayloadTransfer
+}
+
+// decodeRawData 解析protocol.Transaction.raw中唯一的合约。支持TRX转账（TransferContract）
+// 和TRC20的transfer调用（TriggerSmartContract），其他合约返回错误
+func decodeRawData(rawData []byte) (*rawContract, error) {
+	fields, err := parseProto(rawData)
+	if err != nil {
+		return nil, err
+	}
+	var contracts [][]byte
+	for _, field := range fields {
+		if field.num == 11 {
+			contracts = append(contracts, field.bytes)
+		}
+	}
+	if len(contracts) != 1 {
+		return nil, errors.New("transaction must contain exactly one contract")
+	}
+
+	// Contract{type = 1; parameter = 2 (google.protobuf.Any{type_url = 1; value = 2})}
+	fields, err = parseProto(contracts[0])
+	if err != nil {
+		return nil, err
+	}
+	var contractType uint64
+	var parameter []byte
+	for _, field := range fields {
+		switch field.num {
+		case 1:
+			contractType = field.varint
+		case 2:
+			parameter = field.bytes
+		}
+	}
+	fields, err = parseProto(parameter)
+	if err != nil {
+		return nil, err
+	}
+	var value []byte
+	for _, field := range fields {
+		if field.num == 2 {
+			value = field.bytes
+		}
+	}
+	fields, err = parseProto(value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch contractType {
+	case transferContractType:
+		return decodeTransferContract(fields)
+	case triggerSmartContractType:
+		return decodeTriggerSmartContract(fields)
+	default:
+		return nil, fmt.Errorf("unsupported contract type %d", contractType)
+	}
+}
+
+// decodeTransferContract TransferContract{owner_address = 1; to_address = 2; amount = 3}
+func decodeTransferContract(fields []protoField) (*rawContract, error) {
+	var ownerBytes, toBytes []byte
+	var amount uint64
+	for _, field := range fields {
+		switch field.num {
+		case 1:
+			ownerBytes = field.bytes
+		case 2:
+			toBytes = field.bytes
+		case 3:
+			amount = field.varint
+		}
+	}
+	owner, err := protoAddress(ownerBytes)
+	if err != nil {
+		return nil, fmt.Errorf("owner: %v", err)
+	}
+	to, err := protoAddress(toBytes)
+	if err != nil {
+		return nil, fmt.Errorf("recipient: %v", err)
+	}
+
+	return &rawContract{
+		owner: owner,
+		transfers: []*wallet.PayloadTransfer{{
+			From:  owner.String(),
+			To:    to.String(),
+			Value: new(big.Int).SetUint64(amount),
+		}},
+	}, nil
+}
+
+// decodeTriggerSmartContract TriggerSmartContract{owner_address = 1; contract_address = 2; call_value = 3;
+// data = 4; call_token_value = 5; token_id = 6}，只接受不附带TRX和TRC10的TRC20 transfer调用
+func decodeTriggerSmartContract(fields []protoField) (*rawContract, error) {
+	var ownerBytes, contractBytes, data []byte
+	var callValue, callTokenValue uint64
+	for _, field := range fields {
+		switch field.num {
+		case 1:
+			ownerBytes = field.bytes
+		case 2:
+			contractBytes = field.bytes
+		case 3:
+			callValue = field.varint
+		case 4:
+			data = field.bytes
+		case 5:
+			callTokenValue = field.varint
+		}
+	}
+	owner, err := protoAddress(ownerBytes)
+	if err != nil {
+		return nil, fmt.Errorf("owner: %v", err)
+	}
+	contract, err := protoAddress(contractBytes)
+	if err != nil {
+		return nil, fmt.Errorf("contract: %v", err)
+	}
+	if callValue != 0 || callTokenValue != 0 {
+		return nil, errors.New("contract calls carrying TRX or TRC10 are not supported")
+	}
+
+	// transfer(address,uint256)：选择器 || 地址（左侧补零，部分钱包在第12字节保留41前缀） || 金额
+	if len(data) != 4+64 || !bytes.Equal(data[:4], trc20TransferSelector) {
+		return nil, errors.New("only TRC20 transfer calls are supported")
+	}
+	var to Address
+	if !bytes.Equal(data[4:15], make([]byte, 11)) || (data[15] != 0 && data[15] != addressPrefix) {
+		return nil, errors.New("invalid TRC20 recipient")
+	}
+	copy(to[:], data[16:36])
+
+	return &rawContract{
+		owner: owner,
+		transfers: []*wallet.PayloadTransfer{{
+			From:  owner.String(),
+			To:    to.String(),
+			Value: new(big.Int).SetBytes(data[36:68]),
+			Token: contract.String(),
+		}},
+	}, nil
+}
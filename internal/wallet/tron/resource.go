@@ -0,0 +1,172 @@
+package tron
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+)
+
+// 每笔交易在带宽计算中额外计入的字节：签名（含protobuf头）和交易结果的预留空间
+const (
+	signatureBandwidth = 67
+	resultBandwidth    = 64
+)
+
+// 链参数缺失时使用的默认值，单位sun
+const (
+	defaultBandwidthPrice   = 1000
+	defaultEnergyPrice      = 420
+	defaultCreateAccountFee = 1_100_000 // 向未激活账户转TRX：系统合约1 TRX + 带宽0.1 TRX
+)
+
+// energyMargin 能量估算的余量百分比，合约执行的实际消耗可能因状态变化略高于预估
+const energyMargin = 20
+
+// ResourceEstimate 交易的带宽和能量估算，单位分别为字节、能量和sun
+type ResourceEstimate struct {
+	Bandwidth          int64 `json:"bandwidth"`
+	Energy             int64 `json:"energy"`
+	AvailableBandwidth int64 `json:"availableBandwidth"` // 免费带宽与质押带宽之和
+	AvailableEnergy    int64 `json:"availableEnergy"`
+	BandwidthPrice     int64 `json:"bandwidthPrice"` // sun/字节
+	EnergyPrice        int64 `json:"energyPrice"`    // sun/能量
+	ActivationFee      int64 `json:"activationFee"`  // 激活接收账户的费用
+	EstimatedFee       int64 `json:"estimatedFee"`   // 资源不足时需要燃烧的TRX，单位sun
+}
+
+// accountResource getaccountresource的返回值，为0的字段会被省略
+type accountResource struct {
+	FreeNetUsed  int64 `json:"freeNetUsed"`
+	FreeNetLimit int64 `json:"freeNetLimit"`
+	NetUsed      int64 `json:"NetUsed"`
+	NetLimit     int64 `json:"NetLimit"`
+	EnergyUsed   int64 `json:"EnergyUsed"`
+	EnergyLimit  int64 `json:"EnergyLimit"`
+}
+
+// chainPrices 从链参数中读取的资源价格
+type chainPrices struct {
+	bandwidth        int64
+	energy           int64
+	createAccountFee int64
+}
+
+// getChainPrices 查询带宽、能量价格和账户激活费用
+func (w *TronWallet) getChainPrices(ctx context.Context) (*chainPrices, error) {
+	var result struct {
+		ChainParameter []struct {
+			Key   string `json:"key"`
+			Value int64  `json:"value"`
+		} `json:"chainParameter"`
+	}
+	if err := w.client.Post(ctx, "/wallet/getchainparameters", map[string]interface{}{}, &result); err != nil {
+		return nil, err
+	}
+
+	prices := &chainPrices{
+		bandwidth:        defaultBandwidthPrice,
+		energy:           defaultEnergyPrice,
+		createAccountFee: defaultCreateAccountFee,
+	}
+	var systemFee, bandwidthFee int64 = -1, -1
+	for _, param := range result.ChainParameter {
+		switch param.Key {
+		case "getTransactionFee":
+			prices.bandwidth = param.Value
+		case "getEnergyFee":
+			prices.energy = param.Value
+		case "getCreateNewAccountFeeInSystemContract":
+			systemFee = param.Value
+		case "getCreateAccountFee":
+			bandwidthFee = param.Value
+		}
+	}
+	if systemFee >= 0 && bandwidthFee >= 0 {
+		prices.createAccountFee = systemFee + bandwidthFee
+	}
+	return prices, nil
+}
+
+// getAccountResource 查询账户的带宽和能量
+func (w *TronWallet) getAccountResource(ctx context.Context, address Address) (*accountResource, error) {
+	var result accountResource
+	req := map[string]interface{}{"address": address.String(), "visible": true}
+	if err := w.client.Post(ctx, "/wallet/getaccountresource", req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// constantResult triggerconstantcontract的返回值
+type constantResult struct {
+	Result struct {
+		Result  bool   `json:"result"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"result"`
+	EnergyUsed     int64    `json:"energy_used"`
+	ConstantResult []string `json:"constant_result"`
+}
+
+// triggerConstant 只读调用合约，也用于估算合约调用消耗的能量
+func (w *TronWallet) triggerConstant(ctx context.Context, owner Address, contract Address, selector string, parameter []byte) (*constantResult, error) {
+	var result constantResult
+	req := map[string]interface{}{
+		"owner_address":     owner.String(),
+		"contract_address":  contract.String(),
+		"function_selector": selector,
+		"parameter":         hex.EncodeToString(parameter),
+		"visible":           true,
+	}
+	if err := w.client.Post(ctx, "/wallet/triggerconstantcontract", req, &result); err != nil {
+		return nil, err
+	}
+	if !result.Result.Result {
+		return nil, fmt.Errorf("contract call failed: %s %s", result.Result.Code, decodeMessage(result.Result.Message))
+	}
+	return &result, nil
+}
+
+// estimateResources 按交易大小和能量消耗估算费用。带宽不足时整笔交易按字节燃烧TRX，
+// 能量不足时只燃烧缺少的部分
+func (w *TronWallet) estimateResources(ctx context.Context, owner Address, rawDataHex string, energy int64, activate bool) (*ResourceEstimate, error) {
+	prices, err := w.getChainPrices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain parameters: %w", err)
+	}
+
+	resource, err := w.getAccountResource(ctx, owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account resource: %w", err)
+	}
+
+	estimate := &ResourceEstimate{
+		Bandwidth:          int64(len(rawDataHex)/2) + signatureBandwidth + resultBandwidth,
+		Energy:             energy,
+		AvailableBandwidth: max(resource.FreeNetLimit-resource.FreeNetUsed, 0) + max(resource.NetLimit-resource.NetUsed, 0),
+		AvailableEnergy:    max(resource.EnergyLimit-resource.EnergyUsed, 0),
+		BandwidthPrice:     prices.bandwidth,
+		EnergyPrice:        prices.energy,
+	}
+
+	if estimate.Bandwidth > estimate.AvailableBandwidth {
+		estimate.EstimatedFee += estimate.Bandwidth * prices.bandwidth
+	}
+	if estimate.Energy > estimate.AvailableEnergy {
+		estimate.EstimatedFee += (estimate.Energy - estimate.AvailableEnergy) * prices.energy
+	}
+	if activate {
+		estimate.ActivationFee = prices.createAccountFee
+		estimate.EstimatedFee += prices.createAccountFee
+	}
+
+	return estimate, nil
+}
+
+// decodeMessage 节点返回的错误信息为hex编码，无法解码时原样返回
+func decodeMessage(message string) string {
+	if decoded, err := hex.DecodeString(message); err == nil {
+		return string(decoded)
+	}
+	return message
+}
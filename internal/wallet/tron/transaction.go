@@ -0,0 +1,359 @@
+package tron
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+// TRC20转账在预估失败时使用的fee_limit，单位sun
+const defaultFeeLimit = 100_000_000
+
+// nodeTransaction 全节点交易对象中签名需要的字段。签名的是raw_data_hex，
+// 交易内容以其protobuf解码结果为准，不使用JSON中的raw_data
+type nodeTransaction struct {
+	TxID       string   `json:"txID"`
+	RawDataHex string   `json:"raw_data_hex"`
+	Signature  []string `json:"signature,omitempty"`
+}
+
+// CreateTransaction 创建TRX转账交易，data非空时作为交易备注。
+// Payload为全节点返回的交易对象JSON，签名后其中的signature字段被填充
+func (w *TronWallet) CreateTransaction(ctx context.Context, from string, to string, amount *big.Int, data []byte) (*wallet.UnsignedTx, error) {
+	fromAddr, err := ParseAddress(from)
+	if err != nil {
+		return nil, err
+	}
+	toAddr, err := ParseAddress(to)
+	if err != nil {
+		return nil, err
+	}
+	if amount == nil || amount.Sign() <= 0 || !amount.IsInt64() {
+		return nil, errors.New("invalid amount")
+	}
+
+	req := map[string]interface{}{
+		"owner_address": fromAddr.String(),
+		"to_address":    toAddr.String(),
+		"amount":        amount.Int64(),
+		"visible":       true,
+	}
+	if len(data) > 0 {
+		req["extra_data"] = hex.EncodeToString(data)
+	}
+
+	var raw json.RawMessage
+	if err := w.client.Post(ctx, "/wallet/createtransaction", req, &raw); err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	nodeTx, err := parseNodeTransaction(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	// 接收账户未激活时需要额外支付激活费用
+	toAccount, err := w.getAccount(ctx, toAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recipient account: %w", err)
+	}
+
+	resources, err := w.estimateResources(ctx, fromAddr, nodeTx.RawDataHex, 0, toAccount == nil)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := w.GetBalance(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	required := new(big.Int).Add(amount, big.NewInt(resources.EstimatedFee))
+	if balance.Cmp(required) < 0 {
+		return nil, fmt.Errorf("%w: balance %s sun, need %s sun including fees", wallet.ErrInsufficientFunds, balance, required)
+	}
+
+	return &wallet.UnsignedTx{
+		ChainType: w.chainType,
+		From:      from,
+		To:        to,
+		Value:     amount,
+		Fee:       &wallet.TxFee{Amount: big.NewInt(resources.EstimatedFee)},
+		Payload:   raw,
+		Details:   resources,
+	}, nil
+}
+
+// CreateTokenTransaction 创建TRC20代币转账交易，按预估能量设置fee_limit
+func (w *TronWallet) CreateTokenTransaction(ctx context.Context, from string, to string, tokenAddress string, amount *big.Int) (*wallet.UnsignedTx, error) {
+	fromAddr, err := ParseAddress(from)
+	if err != nil {
+		return nil, err
+	}
+	toAddr, err := ParseAddress(to)
+	if err != nil {
+		return nil, err
+	}
+	contract, err := ParseAddress(tokenAddress)
+	if err != nil {
+		return nil, err
+	}
+	if amount == nil || amount.Sign() <= 0 || amount.BitLen() > 256 {
+		return nil, errors.New("invalid amount")
+	}
+
+	tokenBalance, err := w.GetTokenBalance(ctx, from, tokenAddress)
+	if err != nil {
+		return nil, err
+	}
+	if tokenBalance.Cmp(amount) < 0 {
+		return nil, fmt.Errorf("%w: token balance %s, need %s", wallet.ErrInsufficientFunds, tokenBalance, amount)
+	}
+
+	selector := "transfer(address,uint256)"
+	parameter := append(toAddr.abiWord(), padUint256(amount)...)
+
+	// 模拟执行得到能量消耗
+	simulated, err := w.triggerConstant(ctx, fromAddr, contract, selector, parameter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate energy: %w", err)
+	}
+	energy := simulated.EnergyUsed * (100 + energyMargin) / 100
+
+	prices, err := w.getChainPrices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain parameters: %w", err)
+	}
+	feeLimit := energy * prices.energy
+	if feeLimit <= 0 {
+		feeLimit = defaultFeeLimit
+	}
+
+	var result struct {
+		Result struct {
+			Result  bool   `json:"result"`
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"result"`
+		Transaction json.RawMessage `json:"transaction"`
+	}
+	req := map[string]interface{}{
+		"owner_address":     fromAddr.String(),
+		"contract_address":  contract.String(),
+		"function_selector": selector,
+		"parameter":         hex.EncodeToString(parameter),
+		"fee_limit":         feeLimit,
+		"call_value":        0,
+		"visible":           true,
+	}
+	if err := w.client.Post(ctx, "/wallet/triggersmartcontract", req, &result); err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	if !result.Result.Result {
+		return nil, fmt.Errorf("failed to create transaction: %s %s", result.Result.Code, decodeMessage(result.Result.Message))
+	}
+
+	nodeTx, err := parseNodeTransaction(result.Transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := w.estimateResources(ctx, fromAddr, nodeTx.RawDataHex, energy, false)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := w.GetBalance(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	if balance.Cmp(big.NewInt(resources.EstimatedFee)) < 0 {
+		return nil, fmt.Errorf("%w: balance %s sun, need %d sun for fees", wallet.ErrInsufficientFunds, balance, resources.EstimatedFee)
+	}
+
+	return &wallet.UnsignedTx{
+		ChainType: w.chainType,
+		From:      from,
+		To:        to,
+		Value:     amount,
+		Token:     tokenAddress,
+		Fee: &wallet.TxFee{
+			GasLimit:  uint64(energy),
+			Amount:    big.NewInt(resources.EstimatedFee),
+			MaxAmount: big.NewInt(feeLimit),
+		},
+		Payload: result.Transaction,
+		Details: resources,
+	}, nil
+}
+
+// parseNodeTransaction 解析全节点返回的交易，并校验txID为raw_data的sha256
+func parseNodeTransaction(raw json.RawMessage) (*nodeTransaction, error) {
+	var errResp struct {
+		Error string `json:"Error"`
+	}
+	if err := json.Unmarshal(raw, &errResp); err == nil && errResp.Error != "" {
+		return nil, fmt.Errorf("tron node error: %s", errResp.Error)
+	}
+
+	var tx nodeTransaction
+	if err := json.Unmarshal(raw, &tx); err != nil {
+		return nil, fmt.Errorf("invalid node transaction: %v", err)
+	}
+
+	rawData, err := hex.DecodeString(tx.RawDataHex)
+	if err != nil || len(rawData) == 0 {
+		return nil, errors.New("invalid node transaction: missing raw_data_hex")
+	}
+	txID := sha256.Sum256(rawData)
+	if !bytes.Equal(txID[:], mustDecodeHex(tx.TxID)) {
+		return nil, errors.New("invalid node transaction: txID does not match raw_data")
+	}
+	return &tx, nil
+}
+
+// SignTransaction 签名交易，交易发起方必须是该钱包
+func (w *TronWallet) SignTransaction(ctx context.Context, walletID string, tx *wallet.UnsignedTx) (*wallet.SignedTx, error) {
+	if err := tx.CheckChain(w.chainType); err != nil {
+		return nil, err
+	}
+
+	nodeTx, err := parseNodeTransaction(tx.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	contract, err := decodeRawData(mustDecodeHex(nodeTx.RawDataHex))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", wallet.ErrInvalidTransaction, err)
+	}
+
+	privateKey, keystore, err := w.getPrivateKey(walletID)
+	if err != nil {
+		return nil, err
+	}
+	if contract.owner.String() != keystore.Address {
+		return nil, errors.New("transaction owner does not match wallet")
+	}
+
+	signature, err := crypto.Sign(mustDecodeHex(nodeTx.TxID), privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %v", err)
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+
+	// 只替换signature字段，其余字段保持节点返回的原样以便广播
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(tx.Payload, &fields); err != nil {
+		return nil, fmt.Errorf("invalid node transaction: %v", err)
+	}
+	fields["signature"], _ = json.Marshal([]string{hex.EncodeToString(signature)})
+
+	signed, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize transaction: %v", err)
+	}
+
+	return tx.Signed(signed, nodeTx.TxID), nil
+}
+
+// DecodePayload 从raw_data_hex解析交易中的TRX或TRC20转账
+func (w *TronWallet) DecodePayload(payload []byte) ([]*wallet.PayloadTransfer, error) {
+	nodeTx, err := parseNodeTransaction(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", wallet.ErrInvalidTransaction, err)
+	}
+	contract, err := decodeRawData(mustDecodeHex(nodeTx.RawDataHex))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", wallet.ErrInvalidTransaction, err)
+	}
+	return contract.transfers, nil
+}
+
+// DecodeSignedPayload 已签名交易与待签名交易的格式相同，只多了signature字段
+func (w *TronWallet) DecodeSignedPayload(payload []byte) ([]*wallet.PayloadTransfer, error) {
+	return w.DecodePayload(payload)
+}
+
+// SendTransaction 广播已签名交易，返回txID
+func (w *TronWallet) SendTransaction(ctx context.Context, signedTx *wallet.SignedTx) (string, error) {
+	if err := signedTx.CheckChain(w.chainType); err != nil {
+		return "", err
+	}
+
+	nodeTx, err := parseNodeTransaction(signedTx.Payload)
+	if err != nil {
+		return "", err
+	}
+	if len(nodeTx.Signature) == 0 {
+		return "", errors.New("transaction is not signed")
+	}
+
+	var result struct {
+		Result  bool   `json:"result"`
+		TxID    string `json:"txid"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := w.client.Post(ctx, "/wallet/broadcasttransaction", json.RawMessage(signedTx.Payload), &result); err != nil {
+		return "", fmt.Errorf("failed to send transaction: %w", err)
+	}
+	if !result.Result {
+		return "", fmt.Errorf("failed to send transaction: %s %s", result.Code, decodeMessage(result.Message))
+	}
+
+	return nodeTx.TxID, nil
+}
+
+// GetTransactionStatus 获取交易状态，已打包的合约调用按执行结果区分成功与失败
+func (w *TronWallet) GetTransactionStatus(ctx context.Context, txHash string) (string, error) {
+	var info struct {
+		ID          string `json:"id"`
+		BlockNumber int64  `json:"blockNumber"`
+		Result      string `json:"result"`
+		Receipt     struct {
+			Result string `json:"result"`
+		} `json:"receipt"`
+	}
+	if err := w.client.Post(ctx, "/wallet/gettransactioninfobyid", map[string]string{"value": txHash}, &info); err != nil {
+		return "", fmt.Errorf("failed to get transaction info: %w", err)
+	}
+
+	if info.ID != "" {
+		if info.Result == "FAILED" || (info.Receipt.Result != "" && info.Receipt.Result != "SUCCESS") {
+			return string(wallet.TxFailed), nil
+		}
+		return string(wallet.TxConfirmed), nil
+	}
+
+	// 尚未打包，确认交易是否已被节点接收
+	var pending struct {
+		TxID string `json:"txID"`
+	}
+	if err := w.client.Post(ctx, "/wallet/gettransactionbyid", map[string]string{"value": txHash}, &pending); err != nil {
+		return "", fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if pending.TxID == "" {
+		return "", errors.New("transaction not found")
+	}
+	return string(wallet.TxPending), nil
+}
+
+// padUint256 将金额编码为32字节ABI参数
+func padUint256(amount *big.Int) []byte {
+	word := make([]byte, 32)
+	return amount.FillBytes(word)
+}
+
+// mustDecodeHex 解码hex，失败时返回nil
+func mustDecodeHex(s string) []byte {
+	decoded, _ := hex.DecodeString(s)
+	return decoded
+}
@@ -0,0 +1,242 @@
+package tron
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/tyler-smith/go-bip39"
+
+	"multi-chain-wallet/internal/wallet"
+	"multi-chain-wallet/internal/wallet/ethereum"
+	"multi-chain-wallet/internal/wallet/keycrypt"
+)
+
+// Tron的BIP44币种为195
+const derivationPath = "m/44'/195'/0'/0/0"
+
+// KeyStore Tron钱包密钥库
+type KeyStore struct {
+	ID          string           `json:"id"`
+	Address     string           `json:"address"`
+	PrivKeyEnc  string           `json:"privKeyEnc"`
+	MnemonicEnc string           `json:"mnemonicEnc,omitempty"`
+	ChainType   wallet.ChainType `json:"chainType"`
+	CreateTime  int64            `json:"createTime"`
+}
+
+// TronWallet Tron钱包实现，支持TRX和TRC20代币
+type TronWallet struct {
+	client        NodeClient
+	encryptionKey []byte
+	mu            sync.RWMutex
+	keyMap        map[string]*KeyStore // walletID -> keystore
+	chainType     wallet.ChainType
+}
+
+// NewTronWallet 使用指定的全节点客户端创建Tron钱包
+func NewTronWallet(chainType wallet.ChainType, client NodeClient, encryptionKey string) *TronWallet {
+	return &TronWallet{
+		client:        client,
+		encryptionKey: keycrypt.DeriveKey(encryptionKey),
+		keyMap:        make(map[string]*KeyStore),
+		chainType:     chainType,
+	}
+}
+
+// NewFactory 创建Tron链的钱包工厂，RPC地址为全节点HTTP API地址
+func NewFactory(encryptionKey string) wallet.WalletFactory {
+	return func(chain wallet.ChainInfo) (wallet.Wallet, error) {
+		if len(chain.RPCURLs) == 0 {
+			return nil, errors.New("no rpc url configured")
+		}
+		return NewTronWallet(chain.ChainType, NewHTTPNodeClient(chain.RPCURLs[0]), encryptionKey), nil
+	}
+}
+
+// saveKey 加密并保存私钥，返回钱包ID
+func (w *TronWallet) saveKey(privateKey *ecdsa.PrivateKey, mnemonic string) (string, error) {
+	privKeyEnc, err := keycrypt.Encrypt(w.encryptionKey, crypto.FromECDSA(privateKey))
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt private key: %v", err)
+	}
+
+	var mnemonicEnc string
+	if mnemonic != "" {
+		mnemonicEnc, err = keycrypt.Encrypt(w.encryptionKey, []byte(mnemonic))
+		if err != nil {
+			return "", fmt.Errorf("failed to encrypt mnemonic: %v", err)
+		}
+	}
+
+	keystore := &KeyStore{
+		ID:          uuid.New().String(),
+		Address:     AddressFromPublicKey(privateKey.PublicKey).String(),
+		PrivKeyEnc:  privKeyEnc,
+		MnemonicEnc: mnemonicEnc,
+		ChainType:   w.chainType,
+		CreateTime:  time.Now().Unix(),
+	}
+
+	w.mu.Lock()
+	w.keyMap[keystore.ID] = keystore
+	w.mu.Unlock()
+
+	return keystore.ID, nil
+}
+
+// getKeyStore 获取钱包密钥库
+func (w *TronWallet) getKeyStore(walletID string) (*KeyStore, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	keystore, exists := w.keyMap[walletID]
+	if !exists {
+		return nil, wallet.ErrWalletNotFound
+	}
+	return keystore, nil
+}
+
+// getPrivateKey 解密钱包私钥
+func (w *TronWallet) getPrivateKey(walletID string) (*ecdsa.PrivateKey, *KeyStore, error) {
+	keystore, err := w.getKeyStore(walletID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyBytes, err := keycrypt.Decrypt(w.encryptionKey, keystore.PrivKeyEnc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt private key: %v", err)
+	}
+
+	privateKey, err := crypto.ToECDSA(keyBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid private key: %v", err)
+	}
+	return privateKey, keystore, nil
+}
+
+// Create 创建新钱包
+func (w *TronWallet) Create() (string, error) {
+	entropy, err := bip39.NewEntropy(256)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate mnemonic: %v", err)
+	}
+
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate mnemonic: %v", err)
+	}
+
+	return w.ImportFromMnemonic(mnemonic)
+}
+
+// ImportFromMnemonic 从助记词导入钱包，与以太坊相同的secp256k1派生，路径为m/44'/195'/0'/0/0
+func (w *TronWallet) ImportFromMnemonic(mnemonic string) (string, error) {
+	privateKey, err := ethereum.DerivePrivateKey(mnemonic, derivationPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to derive private key: %v", err)
+	}
+	return w.saveKey(privateKey, mnemonic)
+}
+
+// ImportFromPrivateKey 从hex私钥导入钱包
+func (w *TronWallet) ImportFromPrivateKey(privateKey string) (string, error) {
+	key, err := ethereum.ParsePrivateKey(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse private key: %v", err)
+	}
+	return w.saveKey(key, "")
+}
+
+// GetAddress 获取钱包地址
+func (w *TronWallet) GetAddress(walletID string) (string, error) {
+	keystore, err := w.getKeyStore(walletID)
+	if err != nil {
+		return "", err
+	}
+	return keystore.Address, nil
+}
+
+// account getaccount的返回值，未激活的账户返回空对象
+type account struct {
+	Address string `json:"address"`
+	Balance int64  `json:"balance"`
+}
+
+// getAccount 查询账户，未激活时返回nil
+func (w *TronWallet) getAccount(ctx context.Context, address Address) (*account, error) {
+	var result account
+	req := map[string]interface{}{"address": address.String(), "visible": true}
+	if err := w.client.Post(ctx, "/wallet/getaccount", req, &result); err != nil {
+		return nil, err
+	}
+	if result.Address == "" {
+		return nil, nil
+	}
+	return &result, nil
+}
+
+// GetBalance 获取TRX余额，单位sun
+func (w *TronWallet) GetBalance(ctx context.Context, address string) (*big.Int, error) {
+	addr, err := ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	acc, err := w.getAccount(ctx, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	if acc == nil {
+		return big.NewInt(0), nil
+	}
+	return big.NewInt(acc.Balance), nil
+}
+
+// GetTokenBalance 获取TRC20代币余额
+func (w *TronWallet) GetTokenBalance(ctx context.Context, address string, tokenAddress string) (*big.Int, error) {
+	owner, err := ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	contract, err := ParseAddress(tokenAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := w.triggerConstant(ctx, owner, contract, "balanceOf(address)", owner.abiWord())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token balance: %w", err)
+	}
+	if len(result.ConstantResult) == 0 {
+		return nil, errors.New("empty result from balanceOf call")
+	}
+
+	balance, ok := new(big.Int).SetString(result.ConstantResult[0], 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid balanceOf result: %s", result.ConstantResult[0])
+	}
+	return balance, nil
+}
+
+// SignTypedData 暂不支持TIP-712
+func (w *TronWallet) SignTypedData(ctx context.Context, walletID string, typedData []byte) ([]byte, error) {
+	return nil, wallet.ErrOperationNotSupported
+}
+
+// VerifyTypedData 暂不支持TIP-712
+func (w *TronWallet) VerifyTypedData(ctx context.Context, expectedSigner string, typedData []byte, signature []byte) (*wallet.SignatureVerification, error) {
+	return nil, wallet.ErrOperationNotSupported
+}
+
+// ChainType 获取链类型
+func (w *TronWallet) ChainType() wallet.ChainType {
+	return w.chainType
+}
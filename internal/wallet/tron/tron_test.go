@@ -0,0 +1,590 @@
+package tron
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"multi-chain-wallet/internal/wallet"
+)
+
+const testChainType wallet.ChainType = "tron"
+
+// Tron合约类型，对应protocol.Transaction.Contract.ContractType
+const (
+	testTransferContract        = 1
+	testTriggerSmartContract    = 31
+	testTransferContractTypeURL = "type.googleapis.com/protocol.TransferContract"
+	testTriggerContractTypeURL  = "type.googleapis.com/protocol.TriggerSmartContract"
+)
+
+// mockNode 模拟java-tron全节点的HTTP接口，按请求路径返回预设的链状态
+type mockNode struct {
+	t        *testing.T
+	mu       sync.Mutex
+	accounts map[string]int64 // 已激活账户的TRX余额
+	resource accountResource
+	params   map[string]int64
+	energy   int64    // triggerconstantcontract返回的能量消耗
+	token    *big.Int // balanceOf返回的代币余额
+	requests map[string]map[string]interface{}
+	sent     []string
+}
+
+func newMockNode(t *testing.T) (*mockNode, *httptest.Server) {
+	node := &mockNode{
+		t:        t,
+		accounts: make(map[string]int64),
+		params: map[string]int64{
+			"getTransactionFee":                      1000,
+			"getEnergyFee":                           420,
+			"getCreateNewAccountFeeInSystemContract": 1_000_000,
+			"getCreateAccountFee":                    100_000,
+		},
+		energy:   14_650,
+		token:    big.NewInt(0),
+		requests: make(map[string]map[string]interface{}),
+	}
+	server := httptest.NewServer(http.HandlerFunc(node.serve))
+	t.Cleanup(server.Close)
+	return node, server
+}
+
+func (n *mockNode) serve(rw http.ResponseWriter, r *http.Request) {
+	var req map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.requests[r.URL.Path] = req
+
+	var resp interface{}
+	switch r.URL.Path {
+	case "/wallet/getchainparameters":
+		var params []map[string]interface{}
+		for key, value := range n.params {
+			params = append(params, map[string]interface{}{"key": key, "value": value})
+		}
+		resp = map[string]interface{}{"chainParameter": params}
+	case "/wallet/getaccountresource":
+		resp = n.resource
+	case "/wallet/getaccount":
+		address, _ := req["address"].(string)
+		balance, ok := n.accounts[address]
+		if !ok {
+			resp = map[string]interface{}{}
+		} else {
+			resp = map[string]interface{}{"address": address, "balance": balance}
+		}
+	case "/wallet/triggerconstantcontract":
+		resp = map[string]interface{}{
+			"result":          map[string]interface{}{"result": true},
+			"energy_used":     n.energy,
+			"constant_result": []string{hex.EncodeToString(padUint256(n.token))},
+		}
+	case "/wallet/createtransaction":
+		owner := n.address(req["owner_address"])
+		to := n.address(req["to_address"])
+		amount := int64(req["amount"].(float64))
+		value := appendTestBytes(nil, 1, mustDecodeHex(owner.Hex()))
+		value = appendTestBytes(value, 2, mustDecodeHex(to.Hex()))
+		value = appendTestVarint(value, 3, uint64(amount))
+		resp = testNodeTransaction(testTransferContract, testTransferContractTypeURL, value, 0, map[string]interface{}{
+			"owner_address": owner.String(),
+			"to_address":    to.String(),
+			"amount":        amount,
+		})
+	case "/wallet/triggersmartcontract":
+		owner := n.address(req["owner_address"])
+		contract := n.address(req["contract_address"])
+		parameter, _ := hex.DecodeString(req["parameter"].(string))
+		data := append(crypto.Keccak256([]byte(req["function_selector"].(string)))[:4], parameter...)
+		value := appendTestBytes(nil, 1, mustDecodeHex(owner.Hex()))
+		value = appendTestBytes(value, 2, mustDecodeHex(contract.Hex()))
+		value = appendTestBytes(value, 4, data)
+		resp = map[string]interface{}{
+			"result": map[string]interface{}{"result": true},
+			"transaction": testNodeTransaction(testTriggerSmartContract, testTriggerContractTypeURL, value, int64(req["fee_limit"].(float64)), map[string]interface{}{
+				"owner_address":    owner.String(),
+				"contract_address": contract.String(),
+				"data":             hex.EncodeToString(data),
+			}),
+		}
+	case "/wallet/broadcasttransaction":
+		txID, _ := req["txID"].(string)
+		n.sent = append(n.sent, txID)
+		resp = map[string]interface{}{"result": true, "txid": txID}
+	default:
+		http.NotFound(rw, r)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(resp)
+}
+
+func (n *mockNode) address(value interface{}) Address {
+	s, _ := value.(string)
+	addr, err := ParseAddress(s)
+	if err != nil {
+		n.t.Errorf("mock node received invalid address %q", s)
+	}
+	return addr
+}
+
+func (n *mockNode) request(path string) map[string]interface{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.requests[path]
+}
+
+// testNodeTransaction 按全节点的格式构造交易：raw_data_hex为protocol.Transaction.raw的protobuf编码，
+// txID为其sha256
+func testNodeTransaction(contractType uint64, typeURL string, value []byte, feeLimit int64, jsonValue map[string]interface{}) map[string]interface{} {
+	parameter := appendTestBytes(nil, 1, []byte(typeURL))
+	parameter = appendTestBytes(parameter, 2, value)
+	contract := appendTestVarint(nil, 1, contractType)
+	contract = appendTestBytes(contract, 2, parameter)
+
+	raw := appendTestBytes(nil, 1, []byte{0x12, 0x34})
+	raw = appendTestBytes(raw, 4, []byte{1, 2, 3, 4, 5, 6, 7, 8})
+	raw = appendTestVarint(raw, 8, 1_700_000_060_000)
+	raw = appendTestBytes(raw, 11, contract)
+	raw = appendTestVarint(raw, 14, 1_700_000_000_000)
+	if feeLimit > 0 {
+		raw = appendTestVarint(raw, 18, uint64(feeLimit))
+	}
+
+	txID := sha256.Sum256(raw)
+	return map[string]interface{}{
+		"visible":      true,
+		"txID":         hex.EncodeToString(txID[:]),
+		"raw_data_hex": hex.EncodeToString(raw),
+		"raw_data": map[string]interface{}{
+			"contract": []map[string]interface{}{{
+				"parameter": map[string]interface{}{"value": jsonValue, "type_url": typeURL},
+			}},
+		},
+	}
+}
+
+func appendTestBytes(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendTestVarint(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+// newTestWallet 创建连接mock节点的钱包，并导入一个随机私钥
+func newTestWallet(t *testing.T) (*TronWallet, *mockNode, string, Address) {
+	node, server := newMockNode(t)
+	w := NewTronWallet(testChainType, NewHTTPNodeClient(server.URL), "test-encryption-key")
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	walletID, err := w.ImportFromPrivateKey(hex.EncodeToString(crypto.FromECDSA(key)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w, node, walletID, AddressFromPublicKey(key.PublicKey)
+}
+
+func randomAddress(t *testing.T) Address {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return AddressFromPublicKey(key.PublicKey)
+}
+
+func TestParseAddress(t *testing.T) {
+	addr := randomAddress(t)
+
+	for _, s := range []string{addr.String(), addr.Hex()} {
+		parsed, err := ParseAddress(s)
+		if err != nil {
+			t.Fatalf("ParseAddress(%s): %v", s, err)
+		}
+		if parsed != addr {
+			t.Fatalf("ParseAddress(%s) = %s, want %s", s, parsed, addr)
+		}
+	}
+	if addr.String()[0] != 'T' {
+		t.Fatalf("address %s does not start with T", addr)
+	}
+
+	// 以太坊地址（无41前缀）不是合法的Tron地址
+	if _, err := ParseAddress("0x" + hex.EncodeToString(addr[:])); err == nil {
+		t.Fatal("expected error for ethereum address")
+	}
+}
+
+func TestCreateTokenTransactionEncodesTRC20Transfer(t *testing.T) {
+	w, node, _, from := newTestWallet(t)
+	to := randomAddress(t)
+	token := randomAddress(t)
+	amount := big.NewInt(1_234_567)
+
+	node.accounts[from.String()] = 50_000_000
+	node.token = big.NewInt(10_000_000)
+
+	tx, err := w.CreateTokenTransaction(context.Background(), from.String(), to.String(), token.String(), amount)
+	if err != nil {
+		t.Fatalf("CreateTokenTransaction: %v", err)
+	}
+
+	req := node.request("/wallet/triggersmartcontract")
+	if req == nil {
+		t.Fatal("triggersmartcontract was not called")
+	}
+	if req["function_selector"] != "transfer(address,uint256)" {
+		t.Fatalf("function_selector = %v", req["function_selector"])
+	}
+	if req["contract_address"] != token.String() || req["owner_address"] != from.String() {
+		t.Fatalf("unexpected addresses: owner %v contract %v", req["owner_address"], req["contract_address"])
+	}
+
+	// transfer的参数：地址左补零到32字节（不含41前缀），金额为uint256
+	wantParameter := "000000000000000000000000" + hex.EncodeToString(to[:]) +
+		"000000000000000000000000000000000000000000000000000000000012d687"
+	if req["parameter"] != wantParameter {
+		t.Fatalf("parameter = %v, want %s", req["parameter"], wantParameter)
+	}
+
+	// fee_limit按预估能量加20%余量乘以能量价格
+	energy := node.energy * (100 + energyMargin) / 100
+	if got := int64(req["fee_limit"].(float64)); got != energy*420 {
+		t.Fatalf("fee_limit = %d, want %d", got, energy*420)
+	}
+
+	if tx.Token != token.String() || tx.To != to.String() || tx.Value.Cmp(amount) != 0 {
+		t.Fatalf("unexpected transaction: %+v", tx)
+	}
+	if tx.Fee.GasLimit != uint64(energy) || tx.Fee.MaxAmount.Int64() != energy*420 {
+		t.Fatalf("unexpected fee: %+v", tx.Fee)
+	}
+
+	// 账户没有质押资源，带宽和能量都需燃烧TRX
+	resources := tx.Details.(*ResourceEstimate)
+	var nodeTx nodeTransaction
+	if err := json.Unmarshal(tx.Payload, &nodeTx); err != nil {
+		t.Fatal(err)
+	}
+	wantBandwidth := int64(len(nodeTx.RawDataHex)/2) + signatureBandwidth + resultBandwidth
+	if resources.Bandwidth != wantBandwidth || resources.Energy != energy {
+		t.Fatalf("unexpected resources: %+v", resources)
+	}
+	if want := wantBandwidth*1000 + energy*420; resources.EstimatedFee != want || tx.Fee.Amount.Int64() != want {
+		t.Fatalf("estimated fee = %d, want %d", resources.EstimatedFee, want)
+	}
+}
+
+func TestCreateTokenTransactionInsufficientFunds(t *testing.T) {
+	w, node, _, from := newTestWallet(t)
+	to := randomAddress(t)
+	token := randomAddress(t)
+
+	// 代币余额不足
+	node.accounts[from.String()] = 50_000_000
+	node.token = big.NewInt(100)
+	_, err := w.CreateTokenTransaction(context.Background(), from.String(), to.String(), token.String(), big.NewInt(101))
+	if !errors.Is(err, wallet.ErrInsufficientFunds) {
+		t.Fatalf("expected ErrInsufficientFunds for token balance, got %v", err)
+	}
+
+	// 代币足够但TRX不足以燃烧能量
+	node.token = big.NewInt(1000)
+	node.accounts[from.String()] = 1000
+	_, err = w.CreateTokenTransaction(context.Background(), from.String(), to.String(), token.String(), big.NewInt(101))
+	if !errors.Is(err, wallet.ErrInsufficientFunds) {
+		t.Fatalf("expected ErrInsufficientFunds for fees, got %v", err)
+	}
+}
+
+func TestEstimateResources(t *testing.T) {
+	const rawDataHex = "0a0212342208010203040506070840e0c6b5d1b231"
+	bandwidth := int64(len(rawDataHex)/2) + signatureBandwidth + resultBandwidth
+
+	tests := []struct {
+		name     string
+		resource accountResource
+		energy   int64
+		activate bool
+		wantFee  int64
+	}{
+		{
+			name:     "free bandwidth covers transfer",
+			resource: accountResource{FreeNetLimit: 600},
+			wantFee:  0,
+		},
+		{
+			name:     "partially used free bandwidth is insufficient",
+			resource: accountResource{FreeNetLimit: 600, FreeNetUsed: 600 - bandwidth + 1},
+			wantFee:  bandwidth * 1000,
+		},
+		{
+			name:     "staked bandwidth adds to free bandwidth",
+			resource: accountResource{FreeNetLimit: 100, NetLimit: 100},
+			wantFee:  0,
+		},
+		{
+			name:     "only missing energy is burned",
+			resource: accountResource{FreeNetLimit: 600, EnergyLimit: 10_000, EnergyUsed: 2_000},
+			energy:   20_000,
+			wantFee:  (20_000 - 8_000) * 420,
+		},
+		{
+			name:     "activation fee for new account",
+			resource: accountResource{FreeNetLimit: 600},
+			activate: true,
+			wantFee:  1_100_000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, node, _, owner := newTestWallet(t)
+			node.resource = tt.resource
+
+			estimate, err := w.estimateResources(context.Background(), owner, rawDataHex, tt.energy, tt.activate)
+			if err != nil {
+				t.Fatalf("estimateResources: %v", err)
+			}
+			if estimate.Bandwidth != bandwidth {
+				t.Fatalf("bandwidth = %d, want %d", estimate.Bandwidth, bandwidth)
+			}
+			if estimate.EstimatedFee != tt.wantFee {
+				t.Fatalf("estimated fee = %d, want %d", estimate.EstimatedFee, tt.wantFee)
+			}
+			if req := node.request("/wallet/getaccountresource"); req["address"] != owner.String() {
+				t.Fatalf("getaccountresource address = %v", req["address"])
+			}
+		})
+	}
+}
+
+func TestEstimateResourcesDefaultPrices(t *testing.T) {
+	w, node, _, owner := newTestWallet(t)
+	node.params = map[string]int64{}
+
+	estimate, err := w.estimateResources(context.Background(), owner, "00", 1, true)
+	if err != nil {
+		t.Fatalf("estimateResources: %v", err)
+	}
+	if estimate.BandwidthPrice != defaultBandwidthPrice || estimate.EnergyPrice != defaultEnergyPrice ||
+		estimate.ActivationFee != defaultCreateAccountFee {
+		t.Fatalf("unexpected default prices: %+v", estimate)
+	}
+}
+
+func TestCreateTransactionActivatesRecipient(t *testing.T) {
+	w, node, _, from := newTestWallet(t)
+	to := randomAddress(t)
+	node.accounts[from.String()] = 10_000_000
+	node.resource = accountResource{FreeNetLimit: 600}
+
+	tx, err := w.CreateTransaction(context.Background(), from.String(), to.String(), big.NewInt(1_000_000), nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	if tx.Fee.Amount.Int64() != 1_100_000 {
+		t.Fatalf("fee = %s, want activation fee", tx.Fee.Amount)
+	}
+
+	// 接收方已激活时不收激活费用
+	node.accounts[to.String()] = 1
+	tx, err = w.CreateTransaction(context.Background(), from.String(), to.String(), big.NewInt(1_000_000), nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	if tx.Fee.Amount.Sign() != 0 {
+		t.Fatalf("fee = %s, want 0", tx.Fee.Amount)
+	}
+
+	// 余额不足以支付金额和激活费用
+	delete(node.accounts, to.String())
+	node.accounts[from.String()] = 1_500_000
+	_, err = w.CreateTransaction(context.Background(), from.String(), to.String(), big.NewInt(1_000_000), nil)
+	if !errors.Is(err, wallet.ErrInsufficientFunds) {
+		t.Fatalf("expected ErrInsufficientFunds, got %v", err)
+	}
+}
+
+func TestSignAndSendTransaction(t *testing.T) {
+	w, node, walletID, from := newTestWallet(t)
+	to := randomAddress(t)
+	node.accounts[from.String()] = 10_000_000
+	node.accounts[to.String()] = 1
+	node.resource = accountResource{FreeNetLimit: 600}
+
+	tx, err := w.CreateTransaction(context.Background(), from.String(), to.String(), big.NewInt(1_000_000), nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+
+	signed, err := w.SignTransaction(context.Background(), walletID, tx)
+	if err != nil {
+		t.Fatalf("SignTransaction: %v", err)
+	}
+
+	// 签名为对txID的65字节签名，v为27/28
+	nodeTx, err := parseNodeTransaction(signed.Payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodeTx.Signature) != 1 {
+		t.Fatalf("expected one signature, got %d", len(nodeTx.Signature))
+	}
+	signature := mustDecodeHex(nodeTx.Signature[0])
+	if len(signature) != 65 || (signature[64] != 27 && signature[64] != 28) {
+		t.Fatalf("invalid signature %x", signature)
+	}
+	signature[64] -= 27
+	pub, err := crypto.SigToPub(mustDecodeHex(nodeTx.TxID), signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if AddressFromPublicKey(*pub) != from {
+		t.Fatal("signature does not recover to the wallet address")
+	}
+
+	txID, err := w.SendTransaction(context.Background(), signed)
+	if err != nil {
+		t.Fatalf("SendTransaction: %v", err)
+	}
+	if txID != nodeTx.TxID || len(node.sent) != 1 || node.sent[0] != txID {
+		t.Fatalf("unexpected broadcast: txID %s sent %v", txID, node.sent)
+	}
+}
+
+func TestSignTransactionRejectsOtherOwner(t *testing.T) {
+	w, node, walletID, _ := newTestWallet(t)
+	other := randomAddress(t)
+	to := randomAddress(t)
+	node.accounts[other.String()] = 10_000_000
+	node.accounts[to.String()] = 1
+
+	tx, err := w.CreateTransaction(context.Background(), other.String(), to.String(), big.NewInt(1_000_000), nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	if _, err := w.SignTransaction(context.Background(), walletID, tx); err == nil {
+		t.Fatal("expected error signing a transaction owned by another address")
+	}
+}
+
+// 节点返回的JSON raw_data与实际签名的raw_data_hex不一致时，以raw_data_hex为准
+func TestSignTransactionChecksRawDataOwner(t *testing.T) {
+	w, _, walletID, from := newTestWallet(t)
+	other := randomAddress(t)
+	to := randomAddress(t)
+
+	value := appendTestBytes(nil, 1, mustDecodeHex(other.Hex()))
+	value = appendTestBytes(value, 2, mustDecodeHex(to.Hex()))
+	value = appendTestVarint(value, 3, 1_000_000)
+	payload, _ := json.Marshal(testNodeTransaction(testTransferContract, testTransferContractTypeURL, value, 0, map[string]interface{}{
+		"owner_address": from.String(),
+		"to_address":    to.String(),
+		"amount":        1_000_000,
+	}))
+
+	tx := &wallet.UnsignedTx{ChainType: testChainType, From: from.String(), To: to.String(), Value: big.NewInt(1_000_000), Payload: payload}
+	if _, err := w.SignTransaction(context.Background(), walletID, tx); err == nil {
+		t.Fatal("expected error signing raw data owned by another address")
+	}
+}
+
+func TestDecodePayload(t *testing.T) {
+	w, node, _, from := newTestWallet(t)
+	to := randomAddress(t)
+	token := randomAddress(t)
+	node.accounts[from.String()] = 50_000_000
+	node.accounts[to.String()] = 1
+	node.token = big.NewInt(10_000_000)
+
+	trxTx, err := w.CreateTransaction(context.Background(), from.String(), to.String(), big.NewInt(1_000_000), nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	tokenTx, err := w.CreateTokenTransaction(context.Background(), from.String(), to.String(), token.String(), big.NewInt(1_234_567))
+	if err != nil {
+		t.Fatalf("CreateTokenTransaction: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		tx    *wallet.UnsignedTx
+		token string
+		value int64
+	}{
+		{"TRX", trxTx, "", 1_000_000},
+		{"TRC20", tokenTx, token.String(), 1_234_567},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 调用方填写的字段与Payload不一致时，解析结果仍以Payload为准
+			tt.tx.To = randomAddress(t).String()
+			tt.tx.Value = big.NewInt(1)
+			tt.tx.Token = ""
+
+			transfers, err := w.DecodePayload(tt.tx.Payload)
+			if err != nil {
+				t.Fatalf("DecodePayload: %v", err)
+			}
+			if len(transfers) != 1 {
+				t.Fatalf("expected one transfer, got %d", len(transfers))
+			}
+			got := transfers[0]
+			if got.From != from.String() || got.To != to.String() || got.Token != tt.token || got.Value.Int64() != tt.value {
+				t.Fatalf("unexpected transfer: %+v", got)
+			}
+		})
+	}
+}
+
+func TestDecodePayloadRejectsOtherCalls(t *testing.T) {
+	w, _, _, from := newTestWallet(t)
+	contract := randomAddress(t)
+
+	// approve(address,uint256)不是转账，无法按收款方和金额校验
+	data := append(crypto.Keccak256([]byte("approve(address,uint256)"))[:4], make([]byte, 64)...)
+	value := appendTestBytes(nil, 1, mustDecodeHex(from.Hex()))
+	value = appendTestBytes(value, 2, mustDecodeHex(contract.Hex()))
+	value = appendTestBytes(value, 4, data)
+	payload, _ := json.Marshal(testNodeTransaction(testTriggerSmartContract, testTriggerContractTypeURL, value, 1, map[string]interface{}{}))
+
+	if _, err := w.DecodePayload(payload); !errors.Is(err, wallet.ErrInvalidTransaction) {
+		t.Fatalf("expected ErrInvalidTransaction, got %v", err)
+	}
+}
+
+func TestNodeUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	w := NewTronWallet(testChainType, NewHTTPNodeClient(server.URL), "test-encryption-key")
+	_, err := w.GetBalance(context.Background(), randomAddress(t).String())
+	if !errors.Is(err, wallet.ErrChainUnavailable) {
+		t.Fatalf("expected ErrChainUnavailable, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package wallet
 
 import (
 	"context"
+	"encoding/json"
 	"math/big"
 )
 
@@ -37,13 +38,25 @@ type Wallet interface {
 	GetTokenBalance(ctx context.Context, address string, tokenAddress string) (*big.Int, error)
 
 	// 创建交易
-	CreateTransaction(ctx context.Context, from string, to string, amount *big.Int, data []byte) ([]byte, error)
+	CreateTransaction(ctx context.Context, from string, to string, amount *big.Int, data []byte) (*UnsignedTx, error)
 
 	// 签名交易
-	SignTransaction(ctx context.Context, walletID string, tx []byte) ([]byte, error)
+	SignTransaction(ctx context.Context, walletID string, tx *UnsignedTx) (*SignedTx, error)
 
-	// 发送交易
-	SendTransaction(ctx context.Context, signedTx []byte) (string, error)
+	// 发送交易，返回交易哈希
+	SendTransaction(ctx context.Context, signedTx *SignedTx) (string, error)
+
+	// 签名任意消息（EIP-191 personal_sign）
+	SignMessage(ctx context.Context, walletID string, message []byte) ([]byte, error)
+
+	// 签名结构化数据（EIP-712 v4），typedData为JSON
+	SignTypedData(ctx context.Context, walletID string, typedData []byte) ([]byte, error)
+
+	// 校验消息签名
+	VerifyMessage(ctx context.Context, expectedSigner string, message []byte, signature []byte) (*SignatureVerification, error)
+
+	// 校验结构化数据签名
+	VerifyTypedData(ctx context.Context, expectedSigner string, typedData []byte, signature []byte) (*SignatureVerification, error)
 
 	// 获取交易状态
 	GetTransactionStatus(ctx context.Context, txHash string) (string, error)
@@ -52,14 +65,322 @@ type Wallet interface {
 	ChainType() ChainType
 }
 
+// BatchBalanceReader 支持批量查询余额的钱包
+type BatchBalanceReader interface {
+	// 一次查询多个地址的原生代币和代币余额
+	GetBalances(ctx context.Context, addresses []string, tokenAddresses []string) ([]*AddressBalances, error)
+}
+
+// AddressBalances 单个地址的余额，查询失败的余额为nil
+type AddressBalances struct {
+	Address string              `json:"address"`
+	Native  *big.Int            `json:"native"`
+	Tokens  map[string]*big.Int `json:"tokens"`
+}
+
+// TokenTransferWallet 支持构建代币转账交易的钱包
+type TokenTransferWallet interface {
+	// 创建代币转账交易，amount为代币最小单位，返回值可直接传给SignTransaction
+	CreateTokenTransaction(ctx context.Context, from string, to string, tokenAddress string, amount *big.Int) (*UnsignedTx, error)
+}
+
+// RemoteSignerWallet 支持远程签名的钱包，私钥由外部签名服务（Clef兼容）托管
+type RemoteSignerWallet interface {
+	// 注册远程签名钱包，signerURL为签名服务的HTTP地址或IPC路径，返回钱包ID
+	ImportRemoteSigner(ctx context.Context, signerURL string, address string) (string, error)
+}
+
+// ThresholdWallet 支持门限签名的钱包，私钥以t-of-n分片保存在多个签名节点上，任何单一机器都不持有完整私钥
+type ThresholdWallet interface {
+	// 在签名节点上生成门限密钥并创建钱包，返回钱包ID和密钥ID
+	CreateThresholdWallet(ctx context.Context, nodeURLs []string, threshold int) (string, string, error)
+}
+
+// RawTransactionWallet 支持解码外部签名的原始交易的钱包（EVM链）
+type RawTransactionWallet interface {
+	// 解码RLP编码的交易，已签名时从签名恢复发送方
+	DecodeRawTransaction(rawTx []byte) (*DecodedTx, error)
+}
+
+// TransactionLookupWallet 支持按哈希查询链上交易的钱包（EVM链）
+type TransactionLookupWallet interface {
+	// 获取已广播的交易并解码，发送方从签名恢复
+	GetTransaction(ctx context.Context, txHash string) (*DecodedTx, error)
+}
+
+// DecodedTx 解码后的原始交易
+type DecodedTx struct {
+	Hash    string   `json:"hash"`
+	Type    uint8    `json:"type"`              // 0为legacy，2为EIP-1559
+	ChainID *big.Int `json:"chainId,omitempty"` // 未签名的legacy交易为空
+	Signed  bool     `json:"signed"`
+	From    string   `json:"from,omitempty"` // 从签名恢复的发送方，未签名时为空
+	To      string   `json:"to,omitempty"`   // 合约创建交易为空
+	Nonce   uint64   `json:"nonce"`
+	Value   *big.Int `json:"value"`
+	Fee     *TxFee   `json:"fee"`
+	Data    string   `json:"data,omitempty"`   // 0x开头的calldata
+	Method  string   `json:"method,omitempty"` // calldata前4字节的函数选择器
+}
+
+// PayloadWallet 支持解析待签名Payload的钱包（非EVM链）。签名只使用Payload，
+// 策略和风控因此以解析出的转账为准，而不是调用方填写的To、Value和Token
+type PayloadWallet interface {
+	// 解析Payload中的转账，包含无法识别的合约、指令或消息时返回ErrInvalidTransaction
+	DecodePayload(payload []byte) ([]*PayloadTransfer, error)
+
+	// 解析SignTransaction返回的已签名Payload中的转账
+	DecodeSignedPayload(payload []byte) ([]*PayloadTransfer, error)
+}
+
+// PayloadTransfer Payload中的一笔转账，Token为空表示原生代币
+type PayloadTransfer struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Value *big.Int `json:"value"`
+	Token string   `json:"token,omitempty"`
+}
+
+// UTXOWallet 基于UTXO模型的钱包（比特币）
+type UTXOWallet interface {
+	// 列出地址的未花费输出，Reserved表示已被待发送交易占用
+	ListUTXOs(ctx context.Context, address string) ([]*UTXO, error)
+}
+
+// UTXO 未花费的交易输出，金额单位为聪
+type UTXO struct {
+	TxID         string `json:"txid"`
+	Vout         uint32 `json:"vout"`
+	Value        int64  `json:"value"`
+	Address      string `json:"address"`
+	ScriptPubKey string `json:"scriptPubKey"`
+	Height       int64  `json:"height"`
+	Reserved     bool   `json:"reserved"`
+}
+
+// SafeWallet 支持Safe多签合约的钱包（EVM链）
+type SafeWallet interface {
+	// 查询Safe的所有者、门限和当前nonce
+	GetSafeInfo(ctx context.Context, safeAddress string) (*SafeInfo, error)
+
+	// 构建通过SafeProxyFactory部署Safe的交易，返回交易和部署后的Safe地址
+	BuildSafeDeployment(ctx context.Context, from string, owners []string, threshold int, saltNonce *big.Int) (*UnsignedTx, string, error)
+
+	// 构造SafeTx的EIP-712签名数据，返回typedData JSON和safeTxHash
+	BuildSafeTxTypedData(safeTx *SafeTransaction) ([]byte, string, error)
+
+	// 构建由from提交的execTransaction交易，signatures为所有者地址 -> 65字节签名
+	BuildSafeExecTransaction(ctx context.Context, from string, safeTx *SafeTransaction, signatures map[string][]byte) (*UnsignedTx, error)
+}
+
+// SafeInfo Safe多签合约的链上状态
+type SafeInfo struct {
+	Address   string   `json:"address"`
+	Owners    []string `json:"owners"`
+	Threshold int      `json:"threshold"`
+	Nonce     uint64   `json:"nonce"`
+	Version   string   `json:"version"`
+}
+
+// SafeTransaction 由Safe执行的交易（SafeTx），所有者对其EIP-712哈希签名
+type SafeTransaction struct {
+	Safe           string   `json:"safe"`
+	To             string   `json:"to"`
+	Value          *big.Int `json:"value"`
+	Data           string   `json:"data"`      // 0x开头的calldata
+	Operation      uint8    `json:"operation"` // 0为CALL，1为DELEGATECALL
+	SafeTxGas      *big.Int `json:"safeTxGas"`
+	BaseGas        *big.Int `json:"baseGas"`
+	GasPrice       *big.Int `json:"gasPrice"` // 非0时Safe向refundReceiver退还gas费用
+	GasToken       string   `json:"gasToken"`
+	RefundReceiver string   `json:"refundReceiver"`
+	Nonce          uint64   `json:"nonce"`
+}
+
+// SmartAccountWallet 支持ERC-4337智能账户的钱包（EVM链），智能账户由托管钱包作为所有者，
+// UserOperation提交到链配置的bundler
+type SmartAccountWallet interface {
+	// 查询所有者在工厂合约下的智能账户，未部署时返回反事实地址，salt区分同一所有者的多个账户
+	GetSmartAccount(ctx context.Context, owner string, salt *big.Int) (*SmartAccount, error)
+
+	// 构建执行calls的UserOperation并通过bundler估算gas，账户未部署时附带工厂部署数据，返回的UserOperation未签名
+	BuildUserOperation(ctx context.Context, owner string, salt *big.Int, calls []SmartAccountCall, paymaster *Paymaster) (*UserOperation, error)
+
+	// 计算UserOperation在EntryPoint上的userOpHash
+	UserOperationHash(op *UserOperation) (string, error)
+
+	// 解析UserOperation的callData中智能账户要执行的调用
+	DecodeUserOperationCalls(op *UserOperation) ([]SmartAccountCall, error)
+
+	// 使用所有者钱包签名UserOperation，返回填入签名的副本
+	SignUserOperation(ctx context.Context, walletID string, op *UserOperation) (*UserOperation, error)
+
+	// 提交已签名的UserOperation到bundler，返回userOpHash
+	SendUserOperation(ctx context.Context, op *UserOperation) (string, error)
+
+	// 查询UserOperation的执行结果，尚未打包上链时返回nil
+	GetUserOperationReceipt(ctx context.Context, userOpHash string) (*UserOperationReceipt, error)
+}
+
+// SmartAccount ERC-4337智能账户
+type SmartAccount struct {
+	Address    string   `json:"address"`
+	Owner      string   `json:"owner"`
+	Salt       *big.Int `json:"salt"`
+	Factory    string   `json:"factory"`
+	EntryPoint string   `json:"entryPoint"`
+	Deployed   bool     `json:"deployed"`
+	Nonce      *big.Int `json:"nonce"` // EntryPoint中key为0的nonce
+}
+
+// SmartAccountCall 智能账户执行的单个调用
+type SmartAccountCall struct {
+	To    string   `json:"to"`
+	Value *big.Int `json:"value"`
+	Data  string   `json:"data"` // 0x开头的calldata
+}
+
+// Paymaster 代付gas的paymaster，gas限制为空时由bundler估算
+type Paymaster struct {
+	Address              string   `json:"address"`
+	Data                 string   `json:"data"` // 0x开头的paymasterData，通常包含paymaster服务的签名
+	VerificationGasLimit *big.Int `json:"verificationGasLimit,omitempty"`
+	PostOpGasLimit       *big.Int `json:"postOpGasLimit,omitempty"`
+}
+
+// UserOperation EntryPoint v0.7的UserOperation（未打包形式，与bundler RPC一致）
+type UserOperation struct {
+	Sender                        string   `json:"sender"`
+	Nonce                         *big.Int `json:"nonce"`
+	Factory                       string   `json:"factory,omitempty"`     // 账户未部署时的工厂合约
+	FactoryData                   string   `json:"factoryData,omitempty"` // 0x开头
+	CallData                      string   `json:"callData"`              // 0x开头
+	CallGasLimit                  *big.Int `json:"callGasLimit"`
+	VerificationGasLimit          *big.Int `json:"verificationGasLimit"`
+	PreVerificationGas            *big.Int `json:"preVerificationGas"`
+	MaxFeePerGas                  *big.Int `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas          *big.Int `json:"maxPriorityFeePerGas"`
+	Paymaster                     string   `json:"paymaster,omitempty"`
+	PaymasterVerificationGasLimit *big.Int `json:"paymasterVerificationGasLimit,omitempty"`
+	PaymasterPostOpGasLimit       *big.Int `json:"paymasterPostOpGasLimit,omitempty"`
+	PaymasterData                 string   `json:"paymasterData,omitempty"` // 0x开头
+	Signature                     string   `json:"signature"`               // 0x开头，未签名时为空
+}
+
+// UserOperationReceipt UserOperation的执行结果
+type UserOperationReceipt struct {
+	UserOpHash    string   `json:"userOpHash"`
+	Sender        string   `json:"sender"`
+	Nonce         *big.Int `json:"nonce"`
+	Paymaster     string   `json:"paymaster,omitempty"`
+	Success       bool     `json:"success"`
+	Reason        string   `json:"reason,omitempty"` // 执行失败时的revert数据
+	ActualGasCost *big.Int `json:"actualGasCost"`
+	ActualGasUsed *big.Int `json:"actualGasUsed"`
+	TxHash        string   `json:"txHash"` // 包含该UserOperation的handleOps交易
+	BlockNumber   uint64   `json:"blockNumber"`
+}
+
+// ForwarderWallet 支持EIP-2771元交易的钱包（EVM链），用户签名ForwardRequest，由中继钱包通过可信转发合约
+// （OpenZeppelin ERC2771Forwarder）代为提交并支付gas
+type ForwarderWallet interface {
+	// 构造ForwardRequest的EIP-712签名数据，nonce为空时从转发合约读取，gas为0时估算，返回补全后的请求、typedData JSON和请求哈希
+	BuildForwardRequestTypedData(ctx context.Context, forwarder string, req *ForwardRequest) (*ForwardRequest, []byte, string, error)
+
+	// 校验请求签名、有效期以及目标合约是否信任该转发合约，返回请求哈希
+	VerifyForwardRequest(ctx context.Context, forwarder string, req *ForwardRequest, signature []byte) (string, error)
+
+	// 构建由relayer提交的execute交易
+	BuildForwardExecution(ctx context.Context, relayer string, forwarder string, req *ForwardRequest, signature []byte) (*UnsignedTx, error)
+
+	// 查询已上链交易实际支付的手续费，尚未上链时返回nil
+	GetTransactionFee(ctx context.Context, txHash string) (*big.Int, error)
+}
+
+// ForwardRequest EIP-2771转发请求，目标合约通过calldata末尾附加的20字节获得原始发送方from
+type ForwardRequest struct {
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+	Value    *big.Int `json:"value"`
+	Gas      uint64   `json:"gas"` // 目标调用的gas上限
+	Nonce    *big.Int `json:"nonce"`
+	Deadline uint64   `json:"deadline"` // 过期时间（Unix秒）
+	Data     string   `json:"data"`     // 0x开头的calldata
+}
+
+// PermitWallet支持EIP-2612/Permit2链下签名授权的钱包（EVM链）
+type PermitWallet interface {
+	// 检测代币是否支持EIP-2612，并查询对Permit2的授权额度
+	GetPermitSupport(ctx context.Context, tokenAddress string, owner string) (*PermitSupport, error)
+
+	// 构造EIP-2612 Permit签名数据
+	BuildPermitTypedData(ctx context.Context, tokenAddress string, owner string, spender string, value *big.Int, deadline *big.Int) ([]byte, error)
+
+	// 构造Permit2 PermitSingle/PermitBatch签名数据，details多于一个时为PermitBatch
+	BuildPermit2TypedData(ctx context.Context, owner string, spender string, details []Permit2Details, sigDeadline *big.Int) ([]byte, error)
+}
+
+// SwapRouterWallet 支持通过Uniswap Universal Router兑换的钱包（EVM链）
+type SwapRouterWallet interface {
+	// 构造Universal Router的execute调用：带Permit2签名时先执行PERMIT2_PERMIT，再执行V3_SWAP_EXACT_IN
+	BuildRouterSwap(ctx context.Context, from string, router string, swap *RouterSwap) (*UnsignedTx, error)
+}
+
+// RouterSwap Universal Router上的V3精确输入兑换
+type RouterSwap struct {
+	Path             []string // 代币路径
+	FeeTiers         []uint32 // 各跳池的费率档位（百万分之一），比Path少一个
+	AmountIn         *big.Int
+	AmountOutMin     *big.Int
+	Deadline         *big.Int
+	Permit2TypedData []byte // 已签名的Permit2 PermitSingle，为空时使用已有的Permit2授权
+	Permit2Signature []byte
+}
+
+// PermitSupport 代币链下授权支持情况
+type PermitSupport struct {
+	Token            string `json:"token"`
+	EIP2612          bool   `json:"eip2612"`
+	Name             string `json:"name,omitempty"`
+	Version          string `json:"version,omitempty"`
+	Nonce            string `json:"nonce,omitempty"`
+	Permit2Allowance string `json:"permit2Allowance"` // 代币对Permit2合约的授权额度
+}
+
+// Permit2Details Permit2中单个代币的授权参数
+type Permit2Details struct {
+	Token      string   `json:"token"`
+	Amount     *big.Int `json:"amount"`
+	Expiration uint64   `json:"expiration"` // 授权过期时间（Unix秒）
+}
+
 // WalletInfo 钱包信息
 type WalletInfo struct {
-	ID          string    `json:"id"`
-	Address     string    `json:"address"`
-	PrivKeyEnc  string    `json:"privKeyEnc"`
-	MnemonicEnc string    `json:"mnemonicEnc,omitempty"`
-	ChainType   ChainType `json:"chainType"`
-	CreateTime  int64     `json:"createTime"`
+	ID           string    `json:"id"`
+	Address      string    `json:"address"`
+	PrivKeyEnc   string    `json:"privKeyEnc"`
+	MnemonicEnc  string    `json:"mnemonicEnc,omitempty"`
+	SignerURL    string    `json:"signerUrl,omitempty"`    // 远程签名服务地址
+	MPCKeyID     string    `json:"mpcKeyId,omitempty"`     // 门限密钥ID
+	MPCNodes     []string  `json:"mpcNodes,omitempty"`     // 持有门限密钥分片的签名节点
+	MPCThreshold int       `json:"mpcThreshold,omitempty"` // 签名所需的最少节点数
+	ChainType    ChainType `json:"chainType"`
+	CreateTime   int64     `json:"createTime"`
+}
+
+// 签名校验方式
+const (
+	VerifyMethodECRecover = "ecrecover"
+	VerifyMethodERC1271   = "erc1271"
+	VerifyMethodEd25519   = "ed25519"
+)
+
+// SignatureVerification 签名校验结果
+type SignatureVerification struct {
+	RecoveredAddress string `json:"recoveredAddress,omitempty"`
+	ExpectedSigner   string `json:"expectedSigner,omitempty"`
+	Valid            bool   `json:"valid"`
+	Method           string `json:"method"`
 }
 
 // TransactionStatus 交易状态
@@ -85,15 +406,17 @@ type TransactionInfo struct {
 
 // Transaction 交易记录
 type Transaction struct {
-	ID         string            `json:"id"`
-	WalletID   string            `json:"walletId"`
-	TxHash     string            `json:"txHash"`
-	From       string            `json:"from"`
-	To         string            `json:"to"`
-	Amount     string            `json:"amount"`
-	Data       []byte            `json:"data,omitempty"`
-	Status     TransactionStatus `json:"status"`
-	BlockNum   uint64            `json:"blockNum,omitempty"`
-	ChainType  ChainType         `json:"chainType"`
-	CreateTime int64             `json:"createTime"`
+	ID              string            `json:"id"`
+	WalletID        string            `json:"walletId"`
+	TxHash          string            `json:"txHash"`
+	From            string            `json:"from"`
+	To              string            `json:"to"`
+	Amount          string            `json:"amount"`
+	Data            []byte            `json:"data,omitempty"`
+	Status          TransactionStatus `json:"status"`
+	BlockNum        uint64            `json:"blockNum,omitempty"`
+	ChainType       ChainType         `json:"chainType"`
+	CreateTime      int64             `json:"createTime"`
+	ScreeningStatus string            `json:"screeningStatus,omitempty"` // 地址筛查结论：clear、flagged或blocked
+	Screening       json.RawMessage   `json:"screening,omitempty"`       // 地址筛查结果
 }